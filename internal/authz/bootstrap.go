@@ -26,6 +26,10 @@ func BuiltinRoleSeeds() []RoleSeed {
 			Policies: []Policy{
 				{Object: "/admin/products", Action: "*"},
 				{Object: "/admin/products/:id", Action: "*"},
+				{Object: "/admin/products/:id/delivery-template/preview", Action: "POST"},
+				{Object: "/admin/products/:id/files", Action: "*"},
+				{Object: "/admin/products/:id/files/:file_id", Action: "DELETE"},
+				{Object: "/admin/products/:id/revisions/:revision_id/restore", Action: "POST"},
 				{Object: "/admin/categories", Action: "*"},
 				{Object: "/admin/categories/:id", Action: "*"},
 				{Object: "/admin/posts", Action: "*"},
@@ -34,8 +38,17 @@ func BuiltinRoleSeeds() []RoleSeed {
 				{Object: "/admin/banners/:id", Action: "*"},
 				{Object: "/admin/coupons", Action: "*"},
 				{Object: "/admin/coupons/:id", Action: "*"},
+				{Object: "/admin/coupon-batches", Action: "*"},
+				{Object: "/admin/coupon-batches/:id/status", Action: "PATCH"},
+				{Object: "/admin/coupon-batches/:id/export", Action: "POST"},
 				{Object: "/admin/promotions", Action: "*"},
 				{Object: "/admin/promotions/:id", Action: "*"},
+				{Object: "/admin/member-levels", Action: "*"},
+				{Object: "/admin/member-levels/:id", Action: "*"},
+				{Object: "/admin/member-levels/:id/prices", Action: "PUT"},
+				{Object: "/admin/reviews", Action: "GET"},
+				{Object: "/admin/reviews/:id", Action: "PATCH"},
+				{Object: "/admin/reviews/:id/reply", Action: "PUT"},
 				{Object: "/admin/card-secrets", Action: "*"},
 				{Object: "/admin/card-secrets/:id", Action: "*"},
 				{Object: "/admin/card-secrets/batch", Action: "POST"},
@@ -65,7 +78,11 @@ func BuiltinRoleSeeds() []RoleSeed {
 				{Object: "/admin/orders", Action: "GET"},
 				{Object: "/admin/orders/:id", Action: "GET"},
 				{Object: "/admin/orders/:id", Action: "PATCH"},
+				{Object: "/admin/orders/:id/messages", Action: "*"},
+				{Object: "/admin/orders/:id/downloads", Action: "GET"},
+				{Object: "/admin/order-messages/threads", Action: "GET"},
 				{Object: "/admin/fulfillments", Action: "POST"},
+				{Object: "/admin/orders/:id/supplier-retry", Action: "POST"},
 				{Object: "/admin/users", Action: "GET"},
 				{Object: "/admin/users/:id", Action: "GET"},
 				{Object: "/admin/user-login-logs", Action: "GET"},
@@ -0,0 +1,327 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dujiao-next/internal/logger"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// 前台目录缓存范围，每个范围独立维护版本号，失效时整体递增版本
+const (
+	CatalogScopeProducts   = "products"
+	CatalogScopeCategories = "categories"
+	CatalogScopeBanners    = "banners"
+	CatalogScopeConfig     = "config"
+)
+
+const catalogLocalCapacity = 2048
+
+// catalogTableScopes 数据表写入后需要失效的目录缓存范围
+var catalogTableScopes = map[string][]string{
+	"products":         {CatalogScopeProducts},
+	"product_skus":     {CatalogScopeProducts},
+	"card_secrets":     {CatalogScopeProducts},
+	"promotions":       {CatalogScopeProducts},
+	"product_reviews":  {CatalogScopeProducts},
+	"categories":       {CatalogScopeCategories, CatalogScopeProducts},
+	"banners":          {CatalogScopeBanners},
+	"settings":         {CatalogScopeConfig},
+	"payment_channels": {CatalogScopeConfig},
+}
+
+var catalogLocal = newCatalogLRU(catalogLocalCapacity)
+
+// GetCatalogJSON 读取目录缓存，Redis 未启用时使用进程内 LRU
+func GetCatalogJSON(ctx context.Context, scope, key string, dest interface{}) (bool, error) {
+	if !Enabled() {
+		payload, ok := catalogLocal.get(catalogLocal.entryKey(scope, key), time.Now())
+		if !ok {
+			return false, nil
+		}
+		if err := json.Unmarshal(payload, dest); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	version, err := catalogRedisVersion(ctx, scope)
+	if err != nil {
+		return false, err
+	}
+	return GetJSON(ctx, catalogEntryKey(scope, version, key), dest)
+}
+
+// SetCatalogJSON 写入目录缓存
+func SetCatalogJSON(ctx context.Context, scope, key string, value interface{}, ttl time.Duration) error {
+	if !Enabled() {
+		payload, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		catalogLocal.set(catalogLocal.entryKey(scope, key), payload, time.Now().Add(ttl))
+		return nil
+	}
+	version, err := catalogRedisVersion(ctx, scope)
+	if err != nil {
+		return err
+	}
+	return SetJSON(ctx, catalogEntryKey(scope, version, key), value, ttl)
+}
+
+// InvalidateCatalog 递增缓存范围版本，旧版本条目随 TTL 自然过期
+func InvalidateCatalog(ctx context.Context, scopes ...string) error {
+	for _, scope := range scopes {
+		catalogLocal.bump(scope)
+	}
+	if !Enabled() {
+		return nil
+	}
+	for _, scope := range scopes {
+		if err := redisClient.Incr(ctx, buildKey(catalogVersionKey(scope))).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterCatalogInvalidation 注册 GORM 写入回调，商品、SKU、库存、活动、Banner、设置等表变更时发出目录缓存失效事件。
+// 连接池被包装为 catalogConnPool：任意事务（含单条语句的默认事务）内的写入只登记到事务上，提交成功后统一失效，回滚则丢弃；
+// 事务外直接执行的语句已自动提交，写入后立即失效
+func RegisterCatalogInvalidation(db *gorm.DB) error {
+	if db == nil {
+		return nil
+	}
+	if _, ok := db.ConnPool.(*catalogConnPool); !ok {
+		pool := &catalogConnPool{ConnPool: db.ConnPool}
+		db.ConnPool = pool
+		if db.Statement != nil {
+			db.Statement.ConnPool = pool
+		}
+	}
+	if err := db.Callback().Create().Before("gorm:commit_or_rollback_transaction").Register("cache:catalog_invalidate_create", invalidateCatalogByStatement); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:commit_or_rollback_transaction").Register("cache:catalog_invalidate_update", invalidateCatalogByStatement); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:commit_or_rollback_transaction").Register("cache:catalog_invalidate_delete", invalidateCatalogByStatement); err != nil {
+		return err
+	}
+	return db.Callback().Raw().After("gorm:raw").Register("cache:catalog_invalidate_raw", invalidateCatalogByStatement)
+}
+
+// catalogConnPool 包装数据库连接池，开启的事务均为 catalogTx
+type catalogConnPool struct {
+	gorm.ConnPool
+}
+
+// BeginTx 开启事务并挂载待失效范围集合
+func (p *catalogConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	beginner, ok := p.ConnPool.(gorm.TxBeginner)
+	if !ok {
+		return nil, gorm.ErrInvalidTransaction
+	}
+	tx, err := beginner.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &catalogTx{Tx: tx, pool: p, ctx: ctx, scopes: make(map[string]struct{})}, nil
+}
+
+// GetDBConn 返回底层 *sql.DB，供 gorm.DB.DB() 使用
+func (p *catalogConnPool) GetDBConn() (*sql.DB, error) {
+	if sqlDB, ok := p.ConnPool.(*sql.DB); ok {
+		return sqlDB, nil
+	}
+	if connector, ok := p.ConnPool.(gorm.GetDBConnector); ok {
+		return connector.GetDBConn()
+	}
+	return nil, gorm.ErrInvalidDB
+}
+
+// catalogTx 事务内登记的待失效范围，提交成功后统一失效
+type catalogTx struct {
+	*sql.Tx
+	pool   *catalogConnPool
+	ctx    context.Context
+	mu     sync.Mutex
+	scopes map[string]struct{}
+}
+
+// Commit 提交事务后失效登记的目录范围；回滚（继承自 *sql.Tx）时直接丢弃
+func (t *catalogTx) Commit() error {
+	if err := t.Tx.Commit(); err != nil {
+		return err
+	}
+	if scopes := t.list(); len(scopes) > 0 {
+		if err := InvalidateCatalog(context.WithoutCancel(t.ctx), scopes...); err != nil {
+			logger.Warnw("cache_catalog_invalidate_failed", "scopes", scopes, "error", err)
+		}
+	}
+	return nil
+}
+
+// GetDBConn 返回底层 *sql.DB，供事务内调用 gorm.DB.DB() 使用
+func (t *catalogTx) GetDBConn() (*sql.DB, error) {
+	return t.pool.GetDBConn()
+}
+
+func (t *catalogTx) add(scopes ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, scope := range scopes {
+		t.scopes[scope] = struct{}{}
+	}
+}
+
+func (t *catalogTx) list() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	scopes := make([]string, 0, len(t.scopes))
+	for scope := range t.scopes {
+		scopes = append(scopes, scope)
+	}
+	return scopes
+}
+
+func invalidateCatalogByStatement(tx *gorm.DB) {
+	if tx == nil || tx.Error != nil || tx.Statement == nil || tx.RowsAffected == 0 {
+		return
+	}
+	table := tx.Statement.Table
+	if table == "" {
+		table = catalogRawStatementTable(tx.Statement.SQL.String())
+	}
+	scopes := catalogTableScopes[table]
+	if len(scopes) == 0 {
+		return
+	}
+	if pending, ok := tx.Statement.ConnPool.(*catalogTx); ok {
+		pending.add(scopes...)
+		return
+	}
+	ctx := tx.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := InvalidateCatalog(ctx, scopes...); err != nil {
+		logger.Warnw("cache_catalog_invalidate_failed", "table", table, "error", err)
+	}
+}
+
+// catalogRawStatementPattern 解析原生 INSERT/UPDATE/DELETE 语句的目标表
+var catalogRawStatementPattern = regexp.MustCompile("(?i)^\\s*(?:insert\\s+into|update|delete\\s+from)\\s+[`\"]?([a-z0-9_]+)")
+
+func catalogRawStatementTable(sql string) string {
+	matches := catalogRawStatementPattern.FindStringSubmatch(sql)
+	if len(matches) < 2 {
+		return ""
+	}
+	return strings.ToLower(matches[1])
+}
+
+func catalogRedisVersion(ctx context.Context, scope string) (int64, error) {
+	version, err := redisClient.Get(ctx, buildKey(catalogVersionKey(scope))).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return version, err
+}
+
+func catalogVersionKey(scope string) string {
+	return fmt.Sprintf("catalog:%s:version", strings.TrimSpace(scope))
+}
+
+func catalogEntryKey(scope string, version int64, key string) string {
+	return fmt.Sprintf("catalog:%s:%d:%s", strings.TrimSpace(scope), version, strings.TrimSpace(key))
+}
+
+// catalogLRU 进程内 LRU，Redis 未启用时作为目录缓存后端
+type catalogLRU struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+	versions map[string]int64
+}
+
+type catalogLRUEntry struct {
+	key       string
+	payload   []byte
+	expiresAt time.Time
+}
+
+func newCatalogLRU(capacity int) *catalogLRU {
+	return &catalogLRU{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+		versions: make(map[string]int64),
+	}
+}
+
+func (l *catalogLRU) entryKey(scope, key string) string {
+	l.mu.Lock()
+	version := l.versions[scope]
+	l.mu.Unlock()
+	return catalogEntryKey(scope, version, key)
+}
+
+func (l *catalogLRU) get(key string, now time.Time) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	elem, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*catalogLRUEntry)
+	if now.After(entry.expiresAt) {
+		l.order.Remove(elem)
+		delete(l.items, key)
+		return nil, false
+	}
+	l.order.MoveToFront(elem)
+	return entry.payload, true
+}
+
+func (l *catalogLRU) set(key string, payload []byte, expiresAt time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if elem, ok := l.items[key]; ok {
+		entry := elem.Value.(*catalogLRUEntry)
+		entry.payload = payload
+		entry.expiresAt = expiresAt
+		l.order.MoveToFront(elem)
+		return
+	}
+	l.items[key] = l.order.PushFront(&catalogLRUEntry{key: key, payload: payload, expiresAt: expiresAt})
+	for l.order.Len() > l.capacity {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.items, oldest.Value.(*catalogLRUEntry).key)
+	}
+}
+
+// bump 递增范围版本并清理该范围下的旧条目
+func (l *catalogLRU) bump(scope string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.versions[scope]++
+	prefix := fmt.Sprintf("catalog:%s:", strings.TrimSpace(scope))
+	for key, elem := range l.items {
+		if strings.HasPrefix(key, prefix) {
+			l.order.Remove(elem)
+			delete(l.items, key)
+		}
+	}
+}
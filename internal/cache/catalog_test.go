@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dujiao-next/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestCatalogLocalFallbackInvalidation(t *testing.T) {
+	ctx := context.Background()
+	if Enabled() {
+		t.Skip("redis enabled")
+	}
+	if err := SetCatalogJSON(ctx, CatalogScopeBanners, "home_hero:10", []string{"a"}, time.Minute); err != nil {
+		t.Fatalf("set catalog failed: %v", err)
+	}
+	var cached []string
+	hit, err := GetCatalogJSON(ctx, CatalogScopeBanners, "home_hero:10", &cached)
+	if err != nil || !hit || len(cached) != 1 {
+		t.Fatalf("expected cache hit, got hit=%v err=%v value=%v", hit, err, cached)
+	}
+
+	if err := InvalidateCatalog(ctx, CatalogScopeProducts); err != nil {
+		t.Fatalf("invalidate other scope failed: %v", err)
+	}
+	if hit, _ := GetCatalogJSON(ctx, CatalogScopeBanners, "home_hero:10", &cached); !hit {
+		t.Fatalf("invalidating another scope should keep banner cache")
+	}
+	if err := InvalidateCatalog(ctx, CatalogScopeBanners); err != nil {
+		t.Fatalf("invalidate failed: %v", err)
+	}
+	if hit, _ := GetCatalogJSON(ctx, CatalogScopeBanners, "home_hero:10", &cached); hit {
+		t.Fatalf("expected cache miss after invalidation")
+	}
+
+	if err := SetCatalogJSON(ctx, CatalogScopeBanners, "expired", "x", -time.Second); err != nil {
+		t.Fatalf("set expired catalog failed: %v", err)
+	}
+	var value string
+	if hit, _ := GetCatalogJSON(ctx, CatalogScopeBanners, "expired", &value); hit {
+		t.Fatalf("expected expired entry to miss")
+	}
+}
+
+func TestCatalogLRUEvictsOldest(t *testing.T) {
+	lru := newCatalogLRU(2)
+	expiresAt := time.Now().Add(time.Minute)
+	lru.set("a", []byte("1"), expiresAt)
+	lru.set("b", []byte("2"), expiresAt)
+	if _, ok := lru.get("a", time.Now()); !ok {
+		t.Fatalf("expected a to be cached")
+	}
+	lru.set("c", []byte("3"), expiresAt)
+	if _, ok := lru.get("b", time.Now()); ok {
+		t.Fatalf("expected least recently used entry b to be evicted")
+	}
+	if _, ok := lru.get("a", time.Now()); !ok {
+		t.Fatalf("expected recently used entry a to remain")
+	}
+}
+
+func TestCatalogInvalidationOnWrite(t *testing.T) {
+	dsn := fmt.Sprintf("file:catalog_cache_test_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Banner{}, &models.User{}); err != nil {
+		t.Fatalf("auto migrate failed: %v", err)
+	}
+	if err := RegisterCatalogInvalidation(db); err != nil {
+		t.Fatalf("register invalidation failed: %v", err)
+	}
+
+	ctx := context.Background()
+	var cached string
+	_ = SetCatalogJSON(ctx, CatalogScopeBanners, "list", "cached", time.Minute)
+	if err := db.Create(&models.User{Email: "a@example.com", PasswordHash: "x"}).Error; err != nil {
+		t.Fatalf("create user failed: %v", err)
+	}
+	if hit, _ := GetCatalogJSON(ctx, CatalogScopeBanners, "list", &cached); !hit {
+		t.Fatalf("unrelated table write should not invalidate catalog")
+	}
+
+	if err := db.Create(&models.Banner{Name: "hero", Position: "home_hero"}).Error; err != nil {
+		t.Fatalf("create banner failed: %v", err)
+	}
+	if hit, _ := GetCatalogJSON(ctx, CatalogScopeBanners, "list", &cached); hit {
+		t.Fatalf("banner write should invalidate banner catalog")
+	}
+}
+
+func TestCatalogInvalidationDeferredUntilCommit(t *testing.T) {
+	dsn := fmt.Sprintf("file:catalog_cache_tx_test_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Banner{}); err != nil {
+		t.Fatalf("auto migrate failed: %v", err)
+	}
+	if err := RegisterCatalogInvalidation(db); err != nil {
+		t.Fatalf("register invalidation failed: %v", err)
+	}
+
+	ctx := context.Background()
+	var cached string
+	_ = SetCatalogJSON(ctx, CatalogScopeBanners, "tx", "cached", time.Minute)
+	rollbackErr := errors.New("rollback")
+	// 普通 db.Transaction 即可推迟失效，无需专用的事务包装
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&models.Banner{Name: "rolled back", Position: "home_hero"}).Error; err != nil {
+			return err
+		}
+		return rollbackErr
+	})
+	if !errors.Is(err, rollbackErr) {
+		t.Fatalf("expected rollback error, got %v", err)
+	}
+	if hit, _ := GetCatalogJSON(ctx, CatalogScopeBanners, "tx", &cached); !hit {
+		t.Fatalf("rolled back transaction should not invalidate catalog")
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&models.Banner{Name: "hero", Position: "home_hero"}).Error; err != nil {
+			return err
+		}
+		if err := tx.Transaction(func(nested *gorm.DB) error {
+			return nested.Exec("UPDATE banners SET sort_order = ? WHERE position = ?", 1, "home_hero").Error
+		}); err != nil {
+			return err
+		}
+		if hit, _ := GetCatalogJSON(ctx, CatalogScopeBanners, "tx", &cached); !hit {
+			t.Fatalf("uncommitted write should not invalidate catalog")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("transaction failed: %v", err)
+	}
+	if hit, _ := GetCatalogJSON(ctx, CatalogScopeBanners, "tx", &cached); hit {
+		t.Fatalf("committed write should invalidate catalog")
+	}
+
+	_ = SetCatalogJSON(ctx, CatalogScopeBanners, "raw", "cached", time.Minute)
+	if err := db.Exec("UPDATE banners SET name = ? WHERE position = ?", "renamed", "home_hero").Error; err != nil {
+		t.Fatalf("raw update failed: %v", err)
+	}
+	if hit, _ := GetCatalogJSON(ctx, CatalogScopeBanners, "raw", &cached); hit {
+		t.Fatalf("raw write should invalidate catalog")
+	}
+	if sqlDB, err := db.DB(); err != nil || sqlDB == nil {
+		t.Fatalf("expected wrapped connection pool to expose *sql.DB, got %v", err)
+	}
+}
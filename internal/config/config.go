@@ -28,6 +28,11 @@ type Config struct {
 	Email        EmailConfig        `mapstructure:"email"`
 	Order        OrderConfig        `mapstructure:"order"`
 	Captcha      CaptchaConfig      `mapstructure:"captcha"`
+	Receipt      ReceiptConfig      `mapstructure:"receipt"`
+	Download     DownloadConfig     `mapstructure:"download"`
+	License      LicenseConfig      `mapstructure:"license"`
+	StockNotify  StockNotifyConfig  `mapstructure:"stock_notify"`
+	SEO          SEOConfig          `mapstructure:"seo"`
 }
 
 // ServerConfig 服务器配置
@@ -122,6 +127,39 @@ type OrderConfig struct {
 	PaymentExpireMinutes int `mapstructure:"payment_expire_minutes"`
 }
 
+// ReceiptConfig 收据/发票配置
+type ReceiptConfig struct {
+	FontPath string `mapstructure:"font_path"` // TTF 字体路径（需包含中文字形），未配置时不可开启收据
+}
+
+// DownloadConfig 文件交付下载配置
+type DownloadConfig struct {
+	StorageDir        string   `mapstructure:"storage_dir"`        // 交付文件存储目录（不对外静态暴露）
+	MaxSize           int64    `mapstructure:"max_size"`           // 单个交付文件大小上限（字节）
+	AllowedExtensions []string `mapstructure:"allowed_extensions"` // 允许的扩展名，为空不限制
+	SigningSecret     string   `mapstructure:"signing_secret"`     // 下载链接签名密钥（建议独立配置），为空时从 user_jwt.secret 派生子密钥
+	LinkTTLMinutes    int      `mapstructure:"link_ttl_minutes"`   // 下载链接有效期
+	MaxDownloads      int      `mapstructure:"max_downloads"`      // 每个订单每个文件的下载次数上限，0 不限制
+}
+
+// StockNotifyConfig 到货通知配置
+type StockNotifyConfig struct {
+	BatchSize            int `mapstructure:"batch_size"`             // 每批发送的订阅数量
+	BatchIntervalSeconds int `mapstructure:"batch_interval_seconds"` // 批次之间的间隔（秒）
+}
+
+// SEOConfig 站点地图与 RSS 配置
+type SEOConfig struct {
+	SiteURL        string `mapstructure:"site_url"`         // 前台站点地址（SEO 与到货通知链接共用），为空时页面按请求 Host 推断
+	SitemapMaxURLs int    `mapstructure:"sitemap_max_urls"` // 单个 sitemap 的 URL 上限，超出后拆分为 sitemap 索引
+	RSSLimit       int    `mapstructure:"rss_limit"`        // RSS 输出的文章数量
+}
+
+// LicenseConfig 签名授权码配置
+type LicenseConfig struct {
+	SigningKey string `mapstructure:"signing_key"` // Ed25519 私钥种子 base64（32字节），为空时不可签发授权码
+}
+
 // EmailConfig 邮件服务配置
 type EmailConfig struct {
 	Enabled    bool             `mapstructure:"enabled"`
@@ -229,8 +267,18 @@ func DefaultCORSAllowedHeaders() []string {
 
 // SecurityConfig 安全配置
 type SecurityConfig struct {
-	LoginRateLimit LoginRateLimitConfig `mapstructure:"login_rate_limit"`
-	PasswordPolicy PasswordPolicyConfig `mapstructure:"password_policy"`
+	LoginRateLimit   LoginRateLimitConfig   `mapstructure:"login_rate_limit"`
+	PasswordPolicy   PasswordPolicyConfig   `mapstructure:"password_policy"`
+	SecretEncryption SecretEncryptionConfig `mapstructure:"secret_encryption"`
+}
+
+// SecretEncryptionConfig 卡密/交付内容落库加密配置
+type SecretEncryptionConfig struct {
+	ActiveKeyID             string            `mapstructure:"active_key_id"`             // 当前加密密钥ID，为空时不加密新数据
+	Keys                    map[string]string `mapstructure:"keys"`                      // 密钥ID -> base64(32字节)
+	KeyFile                 string            `mapstructure:"key_file"`                  // 密钥文件，每行 key_id:base64_key
+	RotationIntervalSeconds int               `mapstructure:"rotation_interval_seconds"` // 轮换与卡密哈希回填任务间隔
+	RotationBatchSize       int               `mapstructure:"rotation_batch_size"`       // 每轮重加密/回填条数
 }
 
 // LoginRateLimitConfig 登录限流配置
@@ -331,6 +379,10 @@ func Load() *Config {
 	viper.SetDefault("security.password_policy.require_lower", true)
 	viper.SetDefault("security.password_policy.require_number", true)
 	viper.SetDefault("security.password_policy.require_special", false)
+	viper.SetDefault("security.secret_encryption.active_key_id", "")
+	viper.SetDefault("security.secret_encryption.key_file", "")
+	viper.SetDefault("security.secret_encryption.rotation_interval_seconds", 300)
+	viper.SetDefault("security.secret_encryption.rotation_batch_size", 200)
 	viper.SetDefault("email.enabled", false)
 	viper.SetDefault("email.host", "")
 	viper.SetDefault("email.port", 587)
@@ -345,6 +397,18 @@ func Load() *Config {
 	viper.SetDefault("email.verify_code.max_attempts", 5)
 	viper.SetDefault("email.verify_code.length", 6)
 	viper.SetDefault("order.payment_expire_minutes", 15)
+	viper.SetDefault("receipt.font_path", "")
+	viper.SetDefault("download.storage_dir", "./storage/files")
+	viper.SetDefault("download.max_size", 524288000)
+	viper.SetDefault("download.signing_secret", "")
+	viper.SetDefault("download.link_ttl_minutes", 30)
+	viper.SetDefault("download.max_downloads", 5)
+	viper.SetDefault("license.signing_key", "")
+	viper.SetDefault("stock_notify.batch_size", 50)
+	viper.SetDefault("stock_notify.batch_interval_seconds", 60)
+	viper.SetDefault("seo.site_url", "")
+	viper.SetDefault("seo.sitemap_max_urls", 5000)
+	viper.SetDefault("seo.rss_limit", 50)
 	viper.SetDefault("captcha.provider", "none")
 	viper.SetDefault("captcha.scenes.login", false)
 	viper.SetDefault("captcha.scenes.register_send_code", false)
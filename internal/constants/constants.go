@@ -15,7 +15,10 @@ const (
 const (
 	FulfillmentTypeAuto        = "auto"
 	FulfillmentTypeManual      = "manual"
+	FulfillmentTypeAPI         = "api"
+	FulfillmentTypeFile        = "file"
 	FulfillmentStatusPending   = "pending"
+	FulfillmentStatusPartial   = "partial"
 	FulfillmentStatusDelivered = "delivered"
 )
 
@@ -159,6 +162,13 @@ const (
 	ProductPurchaseMember = "member"
 )
 
+// 商品可见性常量
+const (
+	ProductVisibilityPublic    = "public"    // 公开：出现在列表、搜索与站点地图
+	ProductVisibilityUnlisted  = "unlisted"  // 不公开：仅可通过直达链接访问
+	ProductVisibilityProtected = "protected" // 访问码保护：需提供访问码才能查看与下单
+)
+
 // 商品库存状态常量
 const (
 	ProductStockStatusUnlimited  = "unlimited"
@@ -187,7 +197,10 @@ const (
 
 // 适用范围常量
 const (
-	ScopeTypeProduct = "product"
+	ScopeTypeProduct  = "product"
+	ScopeTypeCategory = "category"
+	ScopeTypeSKU      = "sku"
+	ScopeTypeAll      = "all"
 )
 
 // 用户状态常量
@@ -196,6 +209,13 @@ const (
 	UserStatusDisabled = "disabled"
 )
 
+// 订单沟通消息发送方常量
+const (
+	OrderMessageSenderUser  = "user"
+	OrderMessageSenderGuest = "guest"
+	OrderMessageSenderAdmin = "admin"
+)
+
 // 第三方登录提供方常量
 const (
 	UserOAuthProviderTelegram = "telegram"
@@ -260,6 +280,8 @@ const (
 	NotificationEventWalletRechargeSuccess    = "wallet_recharge_success"
 	NotificationEventOrderPaidSuccess         = "order_paid_success"
 	NotificationEventManualFulfillmentPending = "manual_fulfillment_pending"
+	NotificationEventOrderMessageReceived     = "order_message_received"
+	NotificationEventSupplierFulfillFailed    = "supplier_fulfill_failed"
 	NotificationEventExceptionAlert           = "exception_alert"
 	NotificationEventExceptionAlertCheck      = "exception_alert_check"
 )
@@ -280,6 +302,9 @@ const (
 	TaskOrderTimeoutCancel   = "order:timeout_cancel"
 	TaskWalletRechargeExpire = "wallet_recharge:timeout_expire"
 	TaskNotificationDispatch = "notification:dispatch"
+	TaskOrderMessageNotify   = "order:message_notify"
+	TaskOrderAPIFulfill      = "order:api_fulfill"
+	TaskStockArrivalNotify   = "stock:arrival_notify"
 )
 
 // 缓存默认配置常量
@@ -297,6 +322,7 @@ const (
 	SettingKeyDashboardConfig          = "dashboard_config"
 	SettingKeyNotificationCenterConfig = "notification_center_config"
 	SettingKeyAffiliateConfig          = "affiliate_config"
+	SettingKeyReceiptConfig            = "receipt_config"
 	SettingFieldSiteCurrency           = "currency"
 	SettingFieldPaymentExpireMinutes   = "payment_expire_minutes"
 )
@@ -328,12 +354,30 @@ const (
 const (
 	CardSecretSourceManual = "manual"
 	CardSecretSourceCSV    = "csv"
+	CardSecretSourceXLSX   = "xlsx"
+	CardSecretSourceJSONL  = "jsonl"
+	CardSecretSourceTXT    = "txt"
+	CardSecretSourceGen    = "generator"
+)
+
+// 卡密生成器类型常量
+const (
+	KeyGeneratorPattern = "pattern"
+	KeyGeneratorLicense = "license"
+)
+
+// 卡密/交付内容明文查看权限（非路由权限，需单独授予角色）
+const (
+	AdminPermissionSecretPlaintextObject = "/admin/secrets/plaintext"
+	AdminPermissionSecretPlaintextAction = "VIEW"
 )
 
 // 导出格式常量
 const (
-	ExportFormatCSV = "csv"
-	ExportFormatTXT = "txt"
+	ExportFormatCSV  = "csv"
+	ExportFormatTXT  = "txt"
+	ExportFormatJSON = "json"
+	ExportFormatXLSX = "xlsx"
 )
 
 // Banner 位置常量
@@ -347,3 +391,31 @@ const (
 	BannerLinkTypeInternal = "internal"
 	BannerLinkTypeExternal = "external"
 )
+
+// 商品评价状态常量
+const (
+	ReviewStatusPending   = "pending"
+	ReviewStatusPublished = "published"
+	ReviewStatusHidden    = "hidden"
+)
+
+// 商品定时上下架状态
+const (
+	ProductPublishStateInactive  = "inactive"
+	ProductPublishStateScheduled = "scheduled"
+	ProductPublishStateLive      = "live"
+	ProductPublishStateExpired   = "expired"
+)
+
+// 到货通知订阅渠道
+const (
+	StockSubscriptionChannelEmail    = "email"
+	StockSubscriptionChannelTelegram = "telegram"
+)
+
+// 到货通知订阅状态
+const (
+	StockSubscriptionStatusPending      = "pending"
+	StockSubscriptionStatusNotified     = "notified"
+	StockSubscriptionStatusUnsubscribed = "unsubscribed"
+)
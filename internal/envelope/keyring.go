@@ -0,0 +1,275 @@
+package envelope
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	keySize       = 32
+	formatVersion = "v1"
+)
+
+var (
+	// ErrKeyNotFound 密钥不存在
+	ErrKeyNotFound = errors.New("envelope key not found")
+	// ErrCiphertextInvalid 密文格式错误
+	ErrCiphertextInvalid = errors.New("envelope ciphertext invalid")
+)
+
+// Keyring 信封加密密钥环：每条数据使用随机数据密钥（DEK）加密，DEK 再由主密钥（KEK）包裹
+type Keyring struct {
+	activeKeyID string
+	keys        map[string][]byte
+}
+
+// NewKeyring 创建密钥环，activeKeyID 为空时仅支持解密
+func NewKeyring(activeKeyID string, keys map[string][]byte) (*Keyring, error) {
+	ring := &Keyring{
+		activeKeyID: strings.TrimSpace(activeKeyID),
+		keys:        make(map[string][]byte, len(keys)),
+	}
+	for id, key := range keys {
+		normalizedID := strings.TrimSpace(id)
+		if normalizedID == "" || strings.ContainsAny(normalizedID, ": \t") {
+			return nil, fmt.Errorf("invalid key id %q", id)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("key %s must be %d bytes", normalizedID, keySize)
+		}
+		ring.keys[normalizedID] = append([]byte(nil), key...)
+	}
+	if ring.activeKeyID != "" {
+		if _, ok := ring.keys[ring.activeKeyID]; !ok {
+			return nil, fmt.Errorf("%w: active key %s", ErrKeyNotFound, ring.activeKeyID)
+		}
+	}
+	return ring, nil
+}
+
+// LoadKeyring 从配置内联密钥与密钥文件加载密钥环
+// 密钥文件每行格式为 key_id:base64_key，# 开头为注释
+func LoadKeyring(activeKeyID string, inlineKeys map[string]string, keyFile string) (*Keyring, error) {
+	keys := make(map[string][]byte)
+	for id, encoded := range inlineKeys {
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("decode key %s failed: %w", id, err)
+		}
+		keys[strings.TrimSpace(id)] = key
+	}
+	if path := strings.TrimSpace(keyFile); path != "" {
+		fileKeys, err := readKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for id, key := range fileKeys {
+			keys[id] = key
+		}
+	}
+	return NewKeyring(activeKeyID, keys)
+}
+
+// Enabled 是否启用加密写入
+func (k *Keyring) Enabled() bool {
+	return k != nil && k.activeKeyID != ""
+}
+
+// ActiveKeyID 当前用于加密的密钥 ID
+func (k *Keyring) ActiveKeyID() string {
+	if k == nil {
+		return ""
+	}
+	return k.activeKeyID
+}
+
+// Encrypt 使用当前密钥加密，返回密文与密钥 ID；未启用时原样返回
+func (k *Keyring) Encrypt(plaintext string) (string, string, error) {
+	if !k.Enabled() {
+		return plaintext, "", nil
+	}
+	kek := k.keys[k.activeKeyID]
+	dek := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, dek); err != nil {
+		return "", "", err
+	}
+	aad := []byte(k.activeKeyID)
+	wrapped, err := seal(kek, dek, aad)
+	if err != nil {
+		return "", "", err
+	}
+	body, err := seal(dek, []byte(plaintext), aad)
+	if err != nil {
+		return "", "", err
+	}
+	encoding := base64.RawURLEncoding
+	ciphertext := formatVersion + "." + encoding.EncodeToString(wrapped) + "." + encoding.EncodeToString(body)
+	return ciphertext, k.activeKeyID, nil
+}
+
+// Decrypt 按密钥 ID 解密；keyID 为空视为明文
+func (k *Keyring) Decrypt(ciphertext, keyID string) (string, error) {
+	keyID = strings.TrimSpace(keyID)
+	if keyID == "" {
+		return ciphertext, nil
+	}
+	if k == nil {
+		return "", fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+	}
+	kek, ok := k.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+	}
+	parts := strings.Split(ciphertext, ".")
+	if len(parts) != 3 || parts[0] != formatVersion {
+		return "", ErrCiphertextInvalid
+	}
+	encoding := base64.RawURLEncoding
+	wrapped, err := encoding.DecodeString(parts[1])
+	if err != nil {
+		return "", ErrCiphertextInvalid
+	}
+	body, err := encoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrCiphertextInvalid
+	}
+	aad := []byte(keyID)
+	dek, err := open(kek, wrapped, aad)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dek, body, aad)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Hash 使用当前密钥按 label 派生的子密钥计算 HMAC-SHA256，返回十六进制摘要与密钥 ID；未启用时返回空
+// 派生子密钥与加密用途隔离，不同 label 之间的摘要互不相同
+func (k *Keyring) Hash(label, data string) (string, string) {
+	if !k.Enabled() {
+		return "", ""
+	}
+	mac := hmac.New(sha256.New, deriveSubKey(k.keys[k.activeKeyID], label))
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil)), k.activeKeyID
+}
+
+// HashAll 使用密钥环内每把密钥计算 Hash，当前密钥在前；用于密钥轮换后按旧密钥计算的摘要仍可查到
+func (k *Keyring) HashAll(label, data string) []string {
+	if k == nil || len(k.keys) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		if id != k.activeKeyID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	if k.Enabled() {
+		ids = append([]string{k.activeKeyID}, ids...)
+	}
+	hashes := make([]string, 0, len(ids))
+	for _, id := range ids {
+		mac := hmac.New(sha256.New, deriveSubKey(k.keys[id], label))
+		mac.Write([]byte(data))
+		hashes = append(hashes, hex.EncodeToString(mac.Sum(nil)))
+	}
+	return hashes
+}
+
+func deriveSubKey(kek []byte, label string) []byte {
+	mac := hmac.New(sha256.New, kek)
+	mac.Write([]byte("dujiao-next/envelope/" + label))
+	return mac.Sum(nil)
+}
+
+func seal(key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(key, sealed, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrCiphertextInvalid
+	}
+	nonce, data := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, data, aad)
+	if err != nil {
+		return nil, ErrCiphertextInvalid
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	trimmed := strings.TrimSpace(encoded)
+	if key, err := base64.StdEncoding.DecodeString(trimmed); err == nil {
+		return key, nil
+	}
+	return base64.RawStdEncoding.DecodeString(trimmed)
+}
+
+func readKeyFile(path string) (map[string][]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open key file failed: %w", err)
+	}
+	defer file.Close()
+
+	keys := make(map[string][]byte)
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, encoded, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("key file line %d: expected key_id:base64_key", lineNo)
+		}
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key file line %d: %w", lineNo, err)
+		}
+		keys[strings.TrimSpace(id)] = key
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read key file failed: %w", err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,104 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, keySize)
+}
+
+func TestKeyringEncryptDecryptRoundTrip(t *testing.T) {
+	ring, err := NewKeyring("k1", map[string][]byte{"k1": testKey(1)})
+	if err != nil {
+		t.Fatalf("new keyring failed: %v", err)
+	}
+	ciphertext, keyID, err := ring.Encrypt("CARD-0001")
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+	if keyID != "k1" || ciphertext == "CARD-0001" {
+		t.Fatalf("unexpected ciphertext=%s key=%s", ciphertext, keyID)
+	}
+	again, _, _ := ring.Encrypt("CARD-0001")
+	if again == ciphertext {
+		t.Fatalf("expected random nonce per encryption")
+	}
+	plaintext, err := ring.Decrypt(ciphertext, keyID)
+	if err != nil || plaintext != "CARD-0001" {
+		t.Fatalf("decrypt failed: %v %s", err, plaintext)
+	}
+	if _, err := ring.Decrypt(ciphertext, "missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestKeyringRotationKeepsOldKeysReadable(t *testing.T) {
+	oldRing, _ := NewKeyring("k1", map[string][]byte{"k1": testKey(1)})
+	ciphertext, keyID, _ := oldRing.Encrypt("secret")
+
+	newRing, err := NewKeyring("k2", map[string][]byte{"k1": testKey(1), "k2": testKey(2)})
+	if err != nil {
+		t.Fatalf("new keyring failed: %v", err)
+	}
+	plaintext, err := newRing.Decrypt(ciphertext, keyID)
+	if err != nil || plaintext != "secret" {
+		t.Fatalf("decrypt with old key failed: %v", err)
+	}
+	if _, err := newRing.Decrypt(ciphertext, "k2"); !errors.Is(err, ErrCiphertextInvalid) {
+		t.Fatalf("expected key id to be bound to ciphertext, got %v", err)
+	}
+}
+
+func TestKeyringHashIsKeyedAndLabelScoped(t *testing.T) {
+	ring, _ := NewKeyring("k1", map[string][]byte{"k1": testKey(1), "k2": testKey(2)})
+	hash, keyID := ring.Hash("card_secret_hash", "CARD-0001")
+	if keyID != "k1" || len(hash) != 64 {
+		t.Fatalf("unexpected hash=%s key=%s", hash, keyID)
+	}
+	if again, _ := ring.Hash("card_secret_hash", "CARD-0001"); again != hash {
+		t.Fatalf("expected deterministic hash")
+	}
+	if other, _ := ring.Hash("other_label", "CARD-0001"); other == hash {
+		t.Fatalf("expected label to scope the derived key")
+	}
+	rotated, _ := NewKeyring("k2", map[string][]byte{"k1": testKey(1), "k2": testKey(2)})
+	if other, otherKeyID := rotated.Hash("card_secret_hash", "CARD-0001"); other == hash || otherKeyID != "k2" {
+		t.Fatalf("expected hash to follow active key, got %s %s", other, otherKeyID)
+	}
+	if all := rotated.HashAll("card_secret_hash", "CARD-0001"); len(all) != 2 || all[1] != hash {
+		t.Fatalf("expected lookup hashes to include the previous key, got %v", all)
+	}
+	disabled, _ := NewKeyring("", map[string][]byte{"k1": testKey(1)})
+	if empty, emptyKeyID := disabled.Hash("card_secret_hash", "CARD-0001"); empty != "" || emptyKeyID != "" {
+		t.Fatalf("expected disabled keyring to return empty hash")
+	}
+}
+
+func TestLoadKeyringFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	content := "# master keys\nfile-1:" + base64.StdEncoding.EncodeToString(testKey(3)) + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write key file failed: %v", err)
+	}
+	ring, err := LoadKeyring("file-1", map[string]string{
+		"inline-1": base64.StdEncoding.EncodeToString(testKey(4)),
+	}, path)
+	if err != nil {
+		t.Fatalf("load keyring failed: %v", err)
+	}
+	if !ring.Enabled() || ring.ActiveKeyID() != "file-1" {
+		t.Fatalf("unexpected active key %s", ring.ActiveKeyID())
+	}
+	if _, err := LoadKeyring("absent", nil, path); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound for absent active key, got %v", err)
+	}
+	if _, err := NewKeyring("", map[string][]byte{"short": []byte("x")}); err == nil {
+		t.Fatalf("expected short key to be rejected")
+	}
+}
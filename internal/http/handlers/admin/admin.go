@@ -4,8 +4,8 @@ import (
 	"errors"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/dujiao-next/internal/cache"
 	"github.com/dujiao-next/internal/constants"
 	"github.com/dujiao-next/internal/http/response"
 	"github.com/dujiao-next/internal/i18n"
@@ -98,6 +98,17 @@ func (h *Handler) GetAdminCategories(c *gin.Context) {
 	response.Success(c, categories)
 }
 
+// GetAdminCategoryTree 获取分类树 (Admin)
+func (h *Handler) GetAdminCategoryTree(c *gin.Context) {
+	tree, err := h.CategoryService.Tree()
+	if err != nil {
+		respondError(c, response.CodeInternal, "error.category_fetch_failed", err)
+		return
+	}
+
+	response.Success(c, tree)
+}
+
 // LoginRequest 登录请求
 type LoginRequest struct {
 	Username       string                `json:"username" binding:"required"`
@@ -213,50 +224,100 @@ type ProductSKURequest struct {
 	ID               uint                   `json:"id"`
 	SKUCode          string                 `json:"sku_code" binding:"required"`
 	SpecValuesJSON   map[string]interface{} `json:"spec_values"`
+	DeliveryTemplate map[string]interface{} `json:"delivery_template"`
 	PriceAmount      float64                `json:"price_amount" binding:"required"`
+	PriceTiers       []PriceTierRequest     `json:"price_tiers"`
 	ManualStockTotal int                    `json:"manual_stock_total"`
 	IsActive         *bool                  `json:"is_active"`
+	PublishAt        string                 `json:"publish_at"`
+	UnpublishAt      string                 `json:"unpublish_at"`
 	SortOrder        int                    `json:"sort_order"`
 }
 
+// PriceTierRequest SKU 数量阶梯价
+type PriceTierRequest struct {
+	MinQuantity int     `json:"min_quantity"`
+	PriceAmount float64 `json:"price_amount"`
+}
+
 // CreateProductRequest 创建商品请求
 type CreateProductRequest struct {
-	CategoryID         uint                   `json:"category_id" binding:"required"`
-	Slug               string                 `json:"slug" binding:"required"`
-	SeoMetaJSON        map[string]interface{} `json:"seo_meta"`
-	TitleJSON          map[string]interface{} `json:"title" binding:"required"`
-	DescriptionJSON    map[string]interface{} `json:"description"`
-	ContentJSON        map[string]interface{} `json:"content"`
-	ManualFormSchema   map[string]interface{} `json:"manual_form_schema"`
-	PriceAmount        float64                `json:"price_amount" binding:"required"`
-	Images             []string               `json:"images"`
-	Tags               []string               `json:"tags"`
-	PurchaseType       string                 `json:"purchase_type"`
-	FulfillmentType    string                 `json:"fulfillment_type"`
-	ManualStockTotal   *int                   `json:"manual_stock_total"`
-	SKUs               []ProductSKURequest    `json:"skus"`
-	IsAffiliateEnabled *bool                  `json:"is_affiliate_enabled"`
-	IsActive           *bool                  `json:"is_active"`
-	SortOrder          int                    `json:"sort_order"`
-}
-
-func toProductSKUInputs(items []ProductSKURequest) []service.ProductSKUInput {
+	CategoryID          uint                   `json:"category_id" binding:"required"`
+	Slug                string                 `json:"slug" binding:"required"`
+	SeoMetaJSON         map[string]interface{} `json:"seo_meta"`
+	TitleJSON           map[string]interface{} `json:"title" binding:"required"`
+	DescriptionJSON     map[string]interface{} `json:"description"`
+	ContentJSON         map[string]interface{} `json:"content"`
+	ManualFormSchema    map[string]interface{} `json:"manual_form_schema"`
+	SecretFieldSchema   map[string]interface{} `json:"secret_field_schema"`
+	KeyGenerator        map[string]interface{} `json:"key_generator"`
+	DeliveryTemplate    map[string]interface{} `json:"delivery_template"`
+	SpecSchema          map[string]interface{} `json:"spec_schema"`
+	PriceAmount         float64                `json:"price_amount" binding:"required"`
+	Images              []string               `json:"images"`
+	Tags                []string               `json:"tags"`
+	PurchaseType        string                 `json:"purchase_type"`
+	Visibility          string                 `json:"visibility"`
+	AccessCode          string                 `json:"access_code"`
+	FulfillmentType     string                 `json:"fulfillment_type"`
+	SupplierID          *uint                  `json:"supplier_id"`
+	SupplierProductCode string                 `json:"supplier_product_code"`
+	ManualStockTotal    *int                   `json:"manual_stock_total"`
+	SKUs                []ProductSKURequest    `json:"skus"`
+	RevealRequired      *bool                  `json:"reveal_required"`
+	RevealMaxViews      int                    `json:"reveal_max_views"`
+	IsAffiliateEnabled  *bool                  `json:"is_affiliate_enabled"`
+	IsActive            *bool                  `json:"is_active"`
+	PublishAt           string                 `json:"publish_at"`
+	UnpublishAt         string                 `json:"unpublish_at"`
+	SortOrder           int                    `json:"sort_order"`
+}
+
+func toProductSKUInputs(items []ProductSKURequest) ([]service.ProductSKUInput, error) {
 	if len(items) == 0 {
-		return nil
+		return nil, nil
 	}
 	result := make([]service.ProductSKUInput, 0, len(items))
 	for _, item := range items {
+		publishAt, unpublishAt, err := parsePublishWindow(item.PublishAt, item.UnpublishAt)
+		if err != nil {
+			return nil, err
+		}
+		var priceTiers []service.PriceTierInput
+		for _, tier := range item.PriceTiers {
+			priceTiers = append(priceTiers, service.PriceTierInput{
+				MinQuantity: tier.MinQuantity,
+				PriceAmount: decimal.NewFromFloat(tier.PriceAmount),
+			})
+		}
 		result = append(result, service.ProductSKUInput{
 			ID:               item.ID,
 			SKUCode:          item.SKUCode,
 			SpecValuesJSON:   item.SpecValuesJSON,
+			DeliveryTmplJSON: item.DeliveryTemplate,
 			PriceAmount:      decimal.NewFromFloat(item.PriceAmount),
+			PriceTiers:       priceTiers,
 			ManualStockTotal: item.ManualStockTotal,
 			IsActive:         item.IsActive,
+			PublishAt:        publishAt,
+			UnpublishAt:      unpublishAt,
 			SortOrder:        item.SortOrder,
 		})
 	}
-	return result
+	return result, nil
+}
+
+// parsePublishWindow 解析定时上下架时间（RFC3339，空值表示不限制）
+func parsePublishWindow(publishRaw, unpublishRaw string) (*time.Time, *time.Time, error) {
+	publishAt, err := parseTimeNullable(strings.TrimSpace(publishRaw))
+	if err != nil {
+		return nil, nil, err
+	}
+	unpublishAt, err := parseTimeNullable(strings.TrimSpace(unpublishRaw))
+	if err != nil {
+		return nil, nil, err
+	}
+	return publishAt, unpublishAt, nil
 }
 
 // CreateProduct 创建商品
@@ -266,6 +327,16 @@ func (h *Handler) CreateProduct(c *gin.Context) {
 		respondError(c, response.CodeBadRequest, "error.bad_request", err)
 		return
 	}
+	publishAt, unpublishAt, err := parsePublishWindow(req.PublishAt, req.UnpublishAt)
+	if err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	skuInputs, err := toProductSKUInputs(req.SKUs)
+	if err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
 
 	product, err := h.ProductService.Create(service.CreateProductInput{
 		CategoryID:           req.CategoryID,
@@ -275,15 +346,27 @@ func (h *Handler) CreateProduct(c *gin.Context) {
 		DescriptionJSON:      req.DescriptionJSON,
 		ContentJSON:          req.ContentJSON,
 		ManualFormSchemaJSON: req.ManualFormSchema,
+		SecretSchemaJSON:     req.SecretFieldSchema,
+		KeyGeneratorJSON:     req.KeyGenerator,
+		DeliveryTmplJSON:     req.DeliveryTemplate,
+		SpecSchemaJSON:       req.SpecSchema,
 		PriceAmount:          decimal.NewFromFloat(req.PriceAmount),
 		Images:               req.Images,
 		Tags:                 req.Tags,
 		PurchaseType:         req.PurchaseType,
+		Visibility:           req.Visibility,
+		AccessCode:           req.AccessCode,
 		FulfillmentType:      req.FulfillmentType,
+		SupplierID:           req.SupplierID,
+		SupplierProductCode:  req.SupplierProductCode,
 		ManualStockTotal:     req.ManualStockTotal,
-		SKUs:                 toProductSKUInputs(req.SKUs),
+		SKUs:                 skuInputs,
+		RevealRequired:       req.RevealRequired,
+		RevealMaxViews:       req.RevealMaxViews,
 		IsAffiliateEnabled:   req.IsAffiliateEnabled,
 		IsActive:             req.IsActive,
+		PublishAt:            publishAt,
+		UnpublishAt:          unpublishAt,
 		SortOrder:            req.SortOrder,
 	})
 	if err != nil {
@@ -299,22 +382,62 @@ func (h *Handler) CreateProduct(c *gin.Context) {
 			respondError(c, response.CodeBadRequest, "error.product_purchase_invalid", nil)
 			return
 		}
+		if errors.Is(err, service.ErrProductVisibilityInvalid) {
+			respondError(c, response.CodeBadRequest, "error.product_visibility_invalid", nil)
+			return
+		}
 		if errors.Is(err, service.ErrFulfillmentInvalid) {
 			respondError(c, response.CodeBadRequest, "error.fulfillment_invalid", nil)
 			return
 		}
+		if errors.Is(err, service.ErrSupplierInvalid) {
+			respondError(c, response.CodeBadRequest, "error.supplier_invalid", nil)
+			return
+		}
 		if errors.Is(err, service.ErrManualFormSchemaInvalid) {
 			respondError(c, response.CodeBadRequest, "error.manual_form_schema_invalid", nil)
 			return
 		}
+		if errors.Is(err, service.ErrCardSecretSchemaInvalid) {
+			respondError(c, response.CodeBadRequest, "error.card_secret_schema_invalid", nil)
+			return
+		}
+		if errors.Is(err, service.ErrKeyGeneratorInvalid) {
+			respondError(c, response.CodeBadRequest, "error.key_generator_invalid", nil)
+			return
+		}
+		if errors.Is(err, service.ErrSecretRevealConfigInvalid) {
+			respondError(c, response.CodeBadRequest, "error.secret_reveal_config_invalid", nil)
+			return
+		}
+		if errors.Is(err, service.ErrDeliveryTemplateInvalid) {
+			respondError(c, response.CodeBadRequest, "error.delivery_template_invalid", nil)
+			return
+		}
 		if errors.Is(err, service.ErrManualStockInvalid) {
 			respondError(c, response.CodeBadRequest, "error.manual_stock_invalid", nil)
 			return
 		}
+		if errors.Is(err, service.ErrProductSpecSchemaInvalid) {
+			respondError(c, response.CodeBadRequest, "error.product_spec_schema_invalid", nil)
+			return
+		}
+		if errors.Is(err, service.ErrProductSKUSpecInvalid) {
+			respondError(c, response.CodeBadRequest, "error.product_sku_spec_invalid", nil)
+			return
+		}
+		if errors.Is(err, service.ErrProductPriceTierInvalid) {
+			respondError(c, response.CodeBadRequest, "error.product_price_tier_invalid", nil)
+			return
+		}
 		if errors.Is(err, service.ErrProductSKUInvalid) {
 			respondError(c, response.CodeBadRequest, "error.bad_request", nil)
 			return
 		}
+		if errors.Is(err, service.ErrProductScheduleInvalid) {
+			respondError(c, response.CodeBadRequest, "error.product_schedule_invalid", nil)
+			return
+		}
 		respondError(c, response.CodeInternal, "error.product_create_failed", err)
 		return
 	}
@@ -331,6 +454,16 @@ func (h *Handler) UpdateProduct(c *gin.Context) {
 		respondError(c, response.CodeBadRequest, "error.bad_request", err)
 		return
 	}
+	publishAt, unpublishAt, err := parsePublishWindow(req.PublishAt, req.UnpublishAt)
+	if err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	skuInputs, err := toProductSKUInputs(req.SKUs)
+	if err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
 
 	product, err := h.ProductService.Update(id, service.CreateProductInput{
 		CategoryID:           req.CategoryID,
@@ -340,16 +473,29 @@ func (h *Handler) UpdateProduct(c *gin.Context) {
 		DescriptionJSON:      req.DescriptionJSON,
 		ContentJSON:          req.ContentJSON,
 		ManualFormSchemaJSON: req.ManualFormSchema,
+		SecretSchemaJSON:     req.SecretFieldSchema,
+		KeyGeneratorJSON:     req.KeyGenerator,
+		DeliveryTmplJSON:     req.DeliveryTemplate,
+		SpecSchemaJSON:       req.SpecSchema,
 		PriceAmount:          decimal.NewFromFloat(req.PriceAmount),
 		Images:               req.Images,
 		Tags:                 req.Tags,
 		PurchaseType:         req.PurchaseType,
+		Visibility:           req.Visibility,
+		AccessCode:           req.AccessCode,
 		FulfillmentType:      req.FulfillmentType,
+		SupplierID:           req.SupplierID,
+		SupplierProductCode:  req.SupplierProductCode,
 		ManualStockTotal:     req.ManualStockTotal,
-		SKUs:                 toProductSKUInputs(req.SKUs),
+		SKUs:                 skuInputs,
+		RevealRequired:       req.RevealRequired,
+		RevealMaxViews:       req.RevealMaxViews,
 		IsAffiliateEnabled:   req.IsAffiliateEnabled,
 		IsActive:             req.IsActive,
+		PublishAt:            publishAt,
+		UnpublishAt:          unpublishAt,
 		SortOrder:            req.SortOrder,
+		AdminID:              currentAdminID(c),
 	})
 	if err != nil {
 		if errors.Is(err, service.ErrNotFound) {
@@ -368,22 +514,62 @@ func (h *Handler) UpdateProduct(c *gin.Context) {
 			respondError(c, response.CodeBadRequest, "error.product_purchase_invalid", nil)
 			return
 		}
+		if errors.Is(err, service.ErrProductVisibilityInvalid) {
+			respondError(c, response.CodeBadRequest, "error.product_visibility_invalid", nil)
+			return
+		}
 		if errors.Is(err, service.ErrFulfillmentInvalid) {
 			respondError(c, response.CodeBadRequest, "error.fulfillment_invalid", nil)
 			return
 		}
+		if errors.Is(err, service.ErrSupplierInvalid) {
+			respondError(c, response.CodeBadRequest, "error.supplier_invalid", nil)
+			return
+		}
 		if errors.Is(err, service.ErrManualFormSchemaInvalid) {
 			respondError(c, response.CodeBadRequest, "error.manual_form_schema_invalid", nil)
 			return
 		}
+		if errors.Is(err, service.ErrCardSecretSchemaInvalid) {
+			respondError(c, response.CodeBadRequest, "error.card_secret_schema_invalid", nil)
+			return
+		}
+		if errors.Is(err, service.ErrKeyGeneratorInvalid) {
+			respondError(c, response.CodeBadRequest, "error.key_generator_invalid", nil)
+			return
+		}
+		if errors.Is(err, service.ErrSecretRevealConfigInvalid) {
+			respondError(c, response.CodeBadRequest, "error.secret_reveal_config_invalid", nil)
+			return
+		}
+		if errors.Is(err, service.ErrDeliveryTemplateInvalid) {
+			respondError(c, response.CodeBadRequest, "error.delivery_template_invalid", nil)
+			return
+		}
 		if errors.Is(err, service.ErrManualStockInvalid) {
 			respondError(c, response.CodeBadRequest, "error.manual_stock_invalid", nil)
 			return
 		}
+		if errors.Is(err, service.ErrProductSpecSchemaInvalid) {
+			respondError(c, response.CodeBadRequest, "error.product_spec_schema_invalid", nil)
+			return
+		}
+		if errors.Is(err, service.ErrProductSKUSpecInvalid) {
+			respondError(c, response.CodeBadRequest, "error.product_sku_spec_invalid", nil)
+			return
+		}
+		if errors.Is(err, service.ErrProductPriceTierInvalid) {
+			respondError(c, response.CodeBadRequest, "error.product_price_tier_invalid", nil)
+			return
+		}
 		if errors.Is(err, service.ErrProductSKUInvalid) {
 			respondError(c, response.CodeBadRequest, "error.bad_request", nil)
 			return
 		}
+		if errors.Is(err, service.ErrProductScheduleInvalid) {
+			respondError(c, response.CodeBadRequest, "error.product_schedule_invalid", nil)
+			return
+		}
 		respondError(c, response.CodeInternal, "error.product_update_failed", err)
 		return
 	}
@@ -512,6 +698,7 @@ func (h *Handler) DeletePost(c *gin.Context) {
 
 // CreateCategoryRequest 创建分类请求
 type CreateCategoryRequest struct {
+	ParentID  uint                   `json:"parent_id"`
 	Slug      string                 `json:"slug" binding:"required"`
 	NameJSON  map[string]interface{} `json:"name" binding:"required"`
 	Icon      string                 `json:"icon"`
@@ -527,6 +714,7 @@ func (h *Handler) CreateCategory(c *gin.Context) {
 	}
 
 	category, err := h.CategoryService.Create(service.CreateCategoryInput{
+		ParentID:  req.ParentID,
 		Slug:      req.Slug,
 		NameJSON:  req.NameJSON,
 		Icon:      req.Icon,
@@ -537,6 +725,10 @@ func (h *Handler) CreateCategory(c *gin.Context) {
 			respondError(c, response.CodeBadRequest, "error.slug_exists", nil)
 			return
 		}
+		if errors.Is(err, service.ErrCategoryParentInvalid) {
+			respondError(c, response.CodeBadRequest, "error.category_parent_invalid", nil)
+			return
+		}
 		respondError(c, response.CodeInternal, "error.category_create_failed", err)
 		return
 	}
@@ -555,6 +747,7 @@ func (h *Handler) UpdateCategory(c *gin.Context) {
 	}
 
 	category, err := h.CategoryService.Update(id, service.CreateCategoryInput{
+		ParentID:  req.ParentID,
 		Slug:      req.Slug,
 		NameJSON:  req.NameJSON,
 		Icon:      req.Icon,
@@ -569,6 +762,10 @@ func (h *Handler) UpdateCategory(c *gin.Context) {
 			respondError(c, response.CodeBadRequest, "error.slug_used", nil)
 			return
 		}
+		if errors.Is(err, service.ErrCategoryParentInvalid) {
+			respondError(c, response.CodeBadRequest, "error.category_parent_invalid", nil)
+			return
+		}
 		respondError(c, response.CodeInternal, "error.category_update_failed", err)
 		return
 	}
@@ -576,15 +773,33 @@ func (h *Handler) UpdateCategory(c *gin.Context) {
 	response.Success(c, category)
 }
 
-// DeleteCategory 删除分类（软删除）
+// DeleteCategory 删除分类（软删除），可通过 reassign_to 将子分类与商品转移到其他分类
 func (h *Handler) DeleteCategory(c *gin.Context) {
 	id := c.Param("id")
 
-	if err := h.CategoryService.Delete(id); err != nil {
+	var reassignTo uint
+	if raw := strings.TrimSpace(c.Query("reassign_to")); raw != "" {
+		parsed, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil || parsed == 0 {
+			respondError(c, response.CodeBadRequest, "error.bad_request", nil)
+			return
+		}
+		reassignTo = uint(parsed)
+	}
+
+	if err := h.CategoryService.Delete(id, reassignTo); err != nil {
 		if errors.Is(err, service.ErrCategoryInUse) {
 			respondError(c, response.CodeBadRequest, "error.category_in_use", nil)
 			return
 		}
+		if errors.Is(err, service.ErrCategoryHasChildren) {
+			respondError(c, response.CodeBadRequest, "error.category_has_children", nil)
+			return
+		}
+		if errors.Is(err, service.ErrCategoryReassignInvalid) {
+			respondError(c, response.CodeBadRequest, "error.category_reassign_invalid", nil)
+			return
+		}
 		if errors.Is(err, service.ErrNotFound) {
 			respondError(c, response.CodeNotFound, "error.category_not_found", nil)
 			return
@@ -596,6 +811,32 @@ func (h *Handler) DeleteCategory(c *gin.Context) {
 	response.Success(c, nil)
 }
 
+// ReorderCategoriesRequest 分类排序请求
+type ReorderCategoriesRequest struct {
+	ParentID uint   `json:"parent_id"`
+	IDs      []uint `json:"ids" binding:"required"`
+}
+
+// ReorderCategories 调整同一父分类下的分类顺序
+func (h *Handler) ReorderCategories(c *gin.Context) {
+	var req ReorderCategoriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+
+	if err := h.CategoryService.Reorder(req.ParentID, req.IDs); err != nil {
+		if errors.Is(err, service.ErrCategoryReorderInvalid) {
+			respondError(c, response.CodeBadRequest, "error.category_reorder_invalid", nil)
+			return
+		}
+		respondError(c, response.CodeInternal, "error.category_update_failed", err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
 // ====================  设置管理  ====================
 
 // GetSettings 获取设置
@@ -636,7 +877,7 @@ func (h *Handler) UpdateSettings(c *gin.Context) {
 	}
 
 	if req.Key == constants.SettingKeySiteConfig {
-		_ = cache.Del(c.Request.Context(), publicConfigCacheKey)
+		invalidatePublicConfigCache(c)
 	}
 	response.Success(c, value)
 }
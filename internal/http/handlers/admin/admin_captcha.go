@@ -3,7 +3,6 @@ package admin
 import (
 	"errors"
 
-	"github.com/dujiao-next/internal/cache"
 	"github.com/dujiao-next/internal/http/response"
 	"github.com/dujiao-next/internal/service"
 
@@ -44,7 +43,7 @@ func (h *Handler) UpdateCaptchaSettings(c *gin.Context) {
 		h.CaptchaService.SetDefaultConfig(h.Config.Captcha)
 		h.CaptchaService.InvalidateCache()
 	}
-	_ = cache.Del(c.Request.Context(), publicConfigCacheKey)
+	invalidatePublicConfigCache(c)
 
 	response.Success(c, service.MaskCaptchaSettingForAdmin(setting))
 }
@@ -17,17 +17,22 @@ import (
 
 // CreateCouponRequest 创建优惠券请求
 type CreateCouponRequest struct {
-	Code         string  `json:"code" binding:"required"`
-	Type         string  `json:"type" binding:"required"`
-	Value        float64 `json:"value" binding:"required"`
-	MinAmount    float64 `json:"min_amount"`
-	MaxDiscount  float64 `json:"max_discount"`
-	UsageLimit   int     `json:"usage_limit"`
-	PerUserLimit int     `json:"per_user_limit"`
-	ScopeRefIDs  []uint  `json:"scope_ref_ids" binding:"required"`
-	StartsAt     string  `json:"starts_at"`
-	EndsAt       string  `json:"ends_at"`
-	IsActive     *bool   `json:"is_active"`
+	Code               string  `json:"code" binding:"required"`
+	Type               string  `json:"type" binding:"required"`
+	Value              float64 `json:"value" binding:"required"`
+	MinAmount          float64 `json:"min_amount"`
+	MaxDiscount        float64 `json:"max_discount"`
+	UsageLimit         int     `json:"usage_limit"`
+	PerUserLimit       int     `json:"per_user_limit"`
+	ScopeType          string  `json:"scope_type"`
+	ScopeRefIDs        []uint  `json:"scope_ref_ids"`
+	ExcludedProductIDs []uint  `json:"excluded_product_ids"`
+	UserIDs            []uint  `json:"user_ids"`
+	MemberLevelIDs     []uint  `json:"member_level_ids"`
+	FirstOrderOnly     bool    `json:"first_order_only"`
+	StartsAt           string  `json:"starts_at"`
+	EndsAt             string  `json:"ends_at"`
+	IsActive           *bool   `json:"is_active"`
 }
 
 // CreateCoupon 创建优惠券
@@ -50,17 +55,22 @@ func (h *Handler) CreateCoupon(c *gin.Context) {
 	}
 
 	coupon, err := h.CouponAdminService.Create(service.CreateCouponInput{
-		Code:         req.Code,
-		Type:         req.Type,
-		Value:        models.NewMoneyFromDecimal(decimal.NewFromFloat(req.Value)),
-		MinAmount:    models.NewMoneyFromDecimal(decimal.NewFromFloat(req.MinAmount)),
-		MaxDiscount:  models.NewMoneyFromDecimal(decimal.NewFromFloat(req.MaxDiscount)),
-		UsageLimit:   req.UsageLimit,
-		PerUserLimit: req.PerUserLimit,
-		ScopeRefIDs:  req.ScopeRefIDs,
-		StartsAt:     startsAt,
-		EndsAt:       endsAt,
-		IsActive:     req.IsActive,
+		Code:               req.Code,
+		Type:               req.Type,
+		Value:              models.NewMoneyFromDecimal(decimal.NewFromFloat(req.Value)),
+		MinAmount:          models.NewMoneyFromDecimal(decimal.NewFromFloat(req.MinAmount)),
+		MaxDiscount:        models.NewMoneyFromDecimal(decimal.NewFromFloat(req.MaxDiscount)),
+		UsageLimit:         req.UsageLimit,
+		PerUserLimit:       req.PerUserLimit,
+		ScopeType:          req.ScopeType,
+		ScopeRefIDs:        req.ScopeRefIDs,
+		ExcludedProductIDs: req.ExcludedProductIDs,
+		UserIDs:            req.UserIDs,
+		MemberLevelIDs:     req.MemberLevelIDs,
+		FirstOrderOnly:     req.FirstOrderOnly,
+		StartsAt:           startsAt,
+		EndsAt:             endsAt,
+		IsActive:           req.IsActive,
 	})
 	if err != nil {
 		switch {
@@ -102,17 +112,22 @@ func (h *Handler) UpdateCoupon(c *gin.Context) {
 	}
 
 	coupon, err := h.CouponAdminService.Update(uint(couponID), service.UpdateCouponInput{
-		Code:         req.Code,
-		Type:         req.Type,
-		Value:        models.NewMoneyFromDecimal(decimal.NewFromFloat(req.Value)),
-		MinAmount:    models.NewMoneyFromDecimal(decimal.NewFromFloat(req.MinAmount)),
-		MaxDiscount:  models.NewMoneyFromDecimal(decimal.NewFromFloat(req.MaxDiscount)),
-		UsageLimit:   req.UsageLimit,
-		PerUserLimit: req.PerUserLimit,
-		ScopeRefIDs:  req.ScopeRefIDs,
-		StartsAt:     startsAt,
-		EndsAt:       endsAt,
-		IsActive:     req.IsActive,
+		Code:               req.Code,
+		Type:               req.Type,
+		Value:              models.NewMoneyFromDecimal(decimal.NewFromFloat(req.Value)),
+		MinAmount:          models.NewMoneyFromDecimal(decimal.NewFromFloat(req.MinAmount)),
+		MaxDiscount:        models.NewMoneyFromDecimal(decimal.NewFromFloat(req.MaxDiscount)),
+		UsageLimit:         req.UsageLimit,
+		PerUserLimit:       req.PerUserLimit,
+		ScopeType:          req.ScopeType,
+		ScopeRefIDs:        req.ScopeRefIDs,
+		ExcludedProductIDs: req.ExcludedProductIDs,
+		UserIDs:            req.UserIDs,
+		MemberLevelIDs:     req.MemberLevelIDs,
+		FirstOrderOnly:     req.FirstOrderOnly,
+		StartsAt:           startsAt,
+		EndsAt:             endsAt,
+		IsActive:           req.IsActive,
 	})
 	if err != nil {
 		switch {
@@ -188,14 +203,26 @@ func (h *Handler) GetAdminCoupons(c *gin.Context) {
 		}
 		isActive = &parsed
 	}
+	var batchID uint
+	if rawBatchID := strings.TrimSpace(c.Query("batch_id")); rawBatchID != "" {
+		parsed, err := strconv.ParseUint(rawBatchID, 10, 64)
+		if err != nil || parsed == 0 {
+			respondError(c, response.CodeBadRequest, "error.bad_request", err)
+			return
+		}
+		batchID = uint(parsed)
+	}
+	excludeBatch, _ := strconv.ParseBool(c.Query("exclude_batch"))
 
 	coupons, total, err := h.CouponAdminService.List(repository.CouponListFilter{
-		ID:         id,
-		Code:       code,
-		ScopeRefID: scopeRefID,
-		IsActive:   isActive,
-		Page:       page,
-		PageSize:   pageSize,
+		ID:           id,
+		Code:         code,
+		ScopeRefID:   scopeRefID,
+		IsActive:     isActive,
+		BatchID:      batchID,
+		ExcludeBatch: excludeBatch,
+		Page:         page,
+		PageSize:     pageSize,
 	})
 	if err != nil {
 		respondError(c, response.CodeInternal, "error.coupon_fetch_failed", err)
@@ -0,0 +1,213 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+	"github.com/dujiao-next/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+// GenerateCouponBatchRequest 生成优惠码批次请求，优惠规则字段与创建优惠券一致
+type GenerateCouponBatchRequest struct {
+	Name               string  `json:"name" binding:"required"`
+	CodePrefix         string  `json:"code_prefix"`
+	Quantity           int     `json:"quantity" binding:"required"`
+	Type               string  `json:"type" binding:"required"`
+	Value              float64 `json:"value" binding:"required"`
+	MinAmount          float64 `json:"min_amount"`
+	MaxDiscount        float64 `json:"max_discount"`
+	UsageLimit         int     `json:"usage_limit"`
+	PerUserLimit       int     `json:"per_user_limit"`
+	ScopeType          string  `json:"scope_type"`
+	ScopeRefIDs        []uint  `json:"scope_ref_ids"`
+	ExcludedProductIDs []uint  `json:"excluded_product_ids"`
+	UserIDs            []uint  `json:"user_ids"`
+	MemberLevelIDs     []uint  `json:"member_level_ids"`
+	FirstOrderOnly     bool    `json:"first_order_only"`
+	StartsAt           string  `json:"starts_at"`
+	EndsAt             string  `json:"ends_at"`
+	IsActive           *bool   `json:"is_active"`
+}
+
+// UpdateCouponBatchStatusRequest 更新批次状态请求
+type UpdateCouponBatchStatusRequest struct {
+	IsActive *bool `json:"is_active" binding:"required"`
+}
+
+// ExportCouponBatchRequest 导出批次优惠码请求
+type ExportCouponBatchRequest struct {
+	Format string `json:"format" binding:"required"`
+}
+
+// GenerateCouponBatch 管理端批量生成优惠码
+func (h *Handler) GenerateCouponBatch(c *gin.Context) {
+	adminID, ok := getAdminID(c)
+	if !ok {
+		return
+	}
+	var req GenerateCouponBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	startsAt, err := parseTimeNullable(req.StartsAt)
+	if err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	endsAt, err := parseTimeNullable(req.EndsAt)
+	if err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+
+	batch, created, err := h.CouponAdminService.GenerateBatch(service.GenerateCouponBatchInput{
+		Name:       req.Name,
+		CodePrefix: req.CodePrefix,
+		Quantity:   req.Quantity,
+		Template: service.CreateCouponInput{
+			Type:               req.Type,
+			Value:              models.NewMoneyFromDecimal(decimal.NewFromFloat(req.Value)),
+			MinAmount:          models.NewMoneyFromDecimal(decimal.NewFromFloat(req.MinAmount)),
+			MaxDiscount:        models.NewMoneyFromDecimal(decimal.NewFromFloat(req.MaxDiscount)),
+			UsageLimit:         req.UsageLimit,
+			PerUserLimit:       req.PerUserLimit,
+			ScopeType:          req.ScopeType,
+			ScopeRefIDs:        req.ScopeRefIDs,
+			ExcludedProductIDs: req.ExcludedProductIDs,
+			UserIDs:            req.UserIDs,
+			MemberLevelIDs:     req.MemberLevelIDs,
+			FirstOrderOnly:     req.FirstOrderOnly,
+			StartsAt:           startsAt,
+			EndsAt:             endsAt,
+			IsActive:           req.IsActive,
+		},
+		CreatedBy: &adminID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrCouponInvalid):
+			respondError(c, response.CodeBadRequest, "error.coupon_invalid", nil)
+		case errors.Is(err, service.ErrCouponScopeInvalid):
+			respondError(c, response.CodeBadRequest, "error.coupon_scope_invalid", nil)
+		default:
+			respondError(c, response.CodeInternal, "error.coupon_batch_create_failed", err)
+		}
+		return
+	}
+	response.Success(c, gin.H{
+		"batch":   batch,
+		"created": created,
+	})
+}
+
+// GetCouponBatches 获取优惠码批次列表（含使用统计）
+func (h *Handler) GetCouponBatches(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, pageSize = normalizePagination(page, pageSize)
+
+	var isActive *bool
+	if raw := c.Query("is_active"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			respondError(c, response.CodeBadRequest, "error.bad_request", err)
+			return
+		}
+		isActive = &parsed
+	}
+
+	batches, total, err := h.CouponAdminService.ListBatches(repository.CouponBatchListFilter{
+		BatchNo:  c.Query("batch_no"),
+		Name:     c.Query("name"),
+		IsActive: isActive,
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		respondError(c, response.CodeInternal, "error.coupon_fetch_failed", err)
+		return
+	}
+
+	pagination := response.BuildPagination(page, pageSize, total)
+	response.SuccessWithPage(c, batches, pagination)
+}
+
+// GetCouponBatch 获取优惠码批次详情（含使用统计）
+func (h *Handler) GetCouponBatch(c *gin.Context) {
+	batchID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || batchID == 0 {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	batch, err := h.CouponAdminService.GetBatch(uint(batchID))
+	if err != nil {
+		respondCouponBatchError(c, err, "error.coupon_fetch_failed")
+		return
+	}
+	response.Success(c, batch)
+}
+
+// UpdateCouponBatchStatus 启用或停用优惠码批次
+func (h *Handler) UpdateCouponBatchStatus(c *gin.Context) {
+	batchID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || batchID == 0 {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	var req UpdateCouponBatchStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	batch, err := h.CouponAdminService.UpdateBatchStatus(uint(batchID), *req.IsActive)
+	if err != nil {
+		respondCouponBatchError(c, err, "error.coupon_update_failed")
+		return
+	}
+	response.Success(c, batch)
+}
+
+// ExportCouponBatch 导出批次内优惠码
+func (h *Handler) ExportCouponBatch(c *gin.Context) {
+	batchID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || batchID == 0 {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	var req ExportCouponBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	content, contentType, err := h.CouponAdminService.ExportBatch(uint(batchID), req.Format)
+	if err != nil {
+		respondCouponBatchError(c, err, "error.coupon_fetch_failed")
+		return
+	}
+	filename := fmt.Sprintf("coupon_batch_%d_%s.%s", batchID, time.Now().Format("20060102_150405"), strings.ToLower(strings.TrimSpace(req.Format)))
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Data(http.StatusOK, contentType, content)
+}
+
+func respondCouponBatchError(c *gin.Context, err error, fallbackKey string) {
+	switch {
+	case errors.Is(err, service.ErrCouponBatchNotFound):
+		respondError(c, response.CodeNotFound, "error.coupon_batch_not_found", nil)
+	case errors.Is(err, service.ErrCouponInvalid):
+		respondError(c, response.CodeBadRequest, "error.coupon_invalid", nil)
+	default:
+		respondError(c, response.CodeInternal, fallbackKey, err)
+	}
+}
@@ -0,0 +1,222 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+// MemberLevelRequest 会员等级请求
+type MemberLevelRequest struct {
+	Code            string                 `json:"code" binding:"required"`
+	NameJSON        map[string]interface{} `json:"name" binding:"required"`
+	DiscountPercent float64                `json:"discount_percent"`
+	MinSpend        float64                `json:"min_spend"`
+	AutoUpgrade     bool                   `json:"auto_upgrade"`
+	IsDefault       bool                   `json:"is_default"`
+	IsActive        *bool                  `json:"is_active"`
+	SortOrder       int                    `json:"sort_order"`
+}
+
+// MemberLevelPriceRequest 会员等级 SKU 专属价
+type MemberLevelPriceRequest struct {
+	SKUID       uint    `json:"sku_id" binding:"required"`
+	PriceAmount float64 `json:"price_amount" binding:"required"`
+}
+
+// UpdateMemberLevelPricesRequest 覆盖会员等级专属价请求
+type UpdateMemberLevelPricesRequest struct {
+	Prices []MemberLevelPriceRequest `json:"prices"`
+}
+
+// UpdateUserMemberLevelRequest 指定用户会员等级请求（0 表示恢复自动计算）
+type UpdateUserMemberLevelRequest struct {
+	MemberLevelID uint `json:"member_level_id"`
+}
+
+func (r MemberLevelRequest) toInput() service.MemberLevelInput {
+	return service.MemberLevelInput{
+		Code:            r.Code,
+		NameJSON:        r.NameJSON,
+		DiscountPercent: decimal.NewFromFloat(r.DiscountPercent),
+		MinSpend:        decimal.NewFromFloat(r.MinSpend),
+		AutoUpgrade:     r.AutoUpgrade,
+		IsDefault:       r.IsDefault,
+		IsActive:        r.IsActive,
+		SortOrder:       r.SortOrder,
+	}
+}
+
+// GetAdminMemberLevels 获取会员等级列表
+func (h *Handler) GetAdminMemberLevels(c *gin.Context) {
+	levels, err := h.MemberLevelService.List()
+	if err != nil {
+		respondError(c, response.CodeInternal, "error.member_level_fetch_failed", err)
+		return
+	}
+	response.Success(c, levels)
+}
+
+// GetAdminMemberLevel 获取会员等级详情
+func (h *Handler) GetAdminMemberLevel(c *gin.Context) {
+	levelID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || levelID == 0 {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	level, err := h.MemberLevelService.Get(uint(levelID))
+	if err != nil {
+		if errors.Is(err, service.ErrMemberLevelNotFound) {
+			respondError(c, response.CodeNotFound, "error.member_level_not_found", nil)
+			return
+		}
+		respondError(c, response.CodeInternal, "error.member_level_fetch_failed", err)
+		return
+	}
+	response.Success(c, level)
+}
+
+// CreateMemberLevel 创建会员等级
+func (h *Handler) CreateMemberLevel(c *gin.Context) {
+	var req MemberLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	level, err := h.MemberLevelService.Create(req.toInput())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrMemberLevelInvalid):
+			respondError(c, response.CodeBadRequest, "error.member_level_invalid", nil)
+		case errors.Is(err, service.ErrMemberLevelCodeExists):
+			respondError(c, response.CodeBadRequest, "error.member_level_code_exists", nil)
+		default:
+			respondError(c, response.CodeInternal, "error.member_level_create_failed", err)
+		}
+		return
+	}
+	response.Success(c, level)
+}
+
+// UpdateMemberLevel 更新会员等级
+func (h *Handler) UpdateMemberLevel(c *gin.Context) {
+	levelID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || levelID == 0 {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	var req MemberLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	level, err := h.MemberLevelService.Update(uint(levelID), req.toInput())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrMemberLevelNotFound):
+			respondError(c, response.CodeNotFound, "error.member_level_not_found", nil)
+		case errors.Is(err, service.ErrMemberLevelInvalid):
+			respondError(c, response.CodeBadRequest, "error.member_level_invalid", nil)
+		case errors.Is(err, service.ErrMemberLevelCodeExists):
+			respondError(c, response.CodeBadRequest, "error.member_level_code_exists", nil)
+		default:
+			respondError(c, response.CodeInternal, "error.member_level_update_failed", err)
+		}
+		return
+	}
+	response.Success(c, level)
+}
+
+// DeleteMemberLevel 删除会员等级
+func (h *Handler) DeleteMemberLevel(c *gin.Context) {
+	levelID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || levelID == 0 {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	if err := h.MemberLevelService.Delete(uint(levelID)); err != nil {
+		if errors.Is(err, service.ErrMemberLevelNotFound) {
+			respondError(c, response.CodeNotFound, "error.member_level_not_found", nil)
+			return
+		}
+		respondError(c, response.CodeInternal, "error.member_level_delete_failed", err)
+		return
+	}
+	response.Success(c, gin.H{
+		"deleted": true,
+	})
+}
+
+// UpdateMemberLevelPrices 覆盖会员等级 SKU 专属价
+func (h *Handler) UpdateMemberLevelPrices(c *gin.Context) {
+	levelID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || levelID == 0 {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	var req UpdateMemberLevelPricesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	inputs := make([]service.MemberLevelPriceInput, 0, len(req.Prices))
+	for _, item := range req.Prices {
+		inputs = append(inputs, service.MemberLevelPriceInput{
+			SKUID:       item.SKUID,
+			PriceAmount: decimal.NewFromFloat(item.PriceAmount),
+		})
+	}
+	prices, err := h.MemberLevelService.SetPrices(uint(levelID), inputs)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrMemberLevelNotFound):
+			respondError(c, response.CodeNotFound, "error.member_level_not_found", nil)
+		case errors.Is(err, service.ErrMemberLevelInvalid), errors.Is(err, service.ErrProductSKUInvalid):
+			respondError(c, response.CodeBadRequest, "error.member_level_invalid", nil)
+		default:
+			respondError(c, response.CodeInternal, "error.member_level_update_failed", err)
+		}
+		return
+	}
+	response.Success(c, prices)
+}
+
+// UpdateAdminUserMemberLevel 指定用户会员等级
+func (h *Handler) UpdateAdminUserMemberLevel(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || userID == 0 {
+		respondError(c, response.CodeBadRequest, "error.user_id_invalid", nil)
+		return
+	}
+	var req UpdateUserMemberLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	user, err := h.MemberLevelService.AssignUserLevel(uint(userID), req.MemberLevelID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			respondError(c, response.CodeNotFound, "error.user_not_found", nil)
+		case errors.Is(err, service.ErrMemberLevelNotFound):
+			respondError(c, response.CodeNotFound, "error.member_level_not_found", nil)
+		default:
+			respondError(c, response.CodeInternal, "error.user_update_failed", err)
+		}
+		return
+	}
+	level, err := h.MemberLevelService.ResolveUserLevel(user)
+	if err != nil {
+		respondError(c, response.CodeInternal, "error.user_fetch_failed", err)
+		return
+	}
+	response.Success(c, gin.H{
+		"user":         user,
+		"member_level": level,
+	})
+}
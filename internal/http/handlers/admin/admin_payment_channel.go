@@ -4,7 +4,6 @@ import (
 	"errors"
 	"strconv"
 
-	"github.com/dujiao-next/internal/cache"
 	"github.com/dujiao-next/internal/http/response"
 	"github.com/dujiao-next/internal/models"
 	"github.com/dujiao-next/internal/repository"
@@ -65,7 +64,7 @@ func (h *Handler) CreatePaymentChannel(c *gin.Context) {
 		respondError(c, response.CodeInternal, "error.payment_channel_create_failed", err)
 		return
 	}
-	_ = cache.Del(c.Request.Context(), publicConfigCacheKey)
+	invalidatePublicConfigCache(c)
 
 	response.Success(c, channel)
 }
@@ -148,7 +147,7 @@ func (h *Handler) UpdatePaymentChannel(c *gin.Context) {
 		respondError(c, response.CodeInternal, "error.payment_channel_update_failed", err)
 		return
 	}
-	_ = cache.Del(c.Request.Context(), publicConfigCacheKey)
+	invalidatePublicConfigCache(c)
 
 	response.Success(c, channel)
 }
@@ -165,7 +164,7 @@ func (h *Handler) DeletePaymentChannel(c *gin.Context) {
 		respondError(c, response.CodeInternal, "error.payment_channel_delete_failed", err)
 		return
 	}
-	_ = cache.Del(c.Request.Context(), publicConfigCacheKey)
+	invalidatePublicConfigCache(c)
 
 	response.Success(c, gin.H{"deleted": true})
 }
@@ -0,0 +1,47 @@
+package admin
+
+import (
+	"errors"
+
+	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetReceiptSettings 获取收据/发票设置
+func (h *Handler) GetReceiptSettings(c *gin.Context) {
+	setting, err := h.SettingService.GetReceiptSetting()
+	if err != nil {
+		respondError(c, response.CodeInternal, "error.settings_fetch_failed", err)
+		return
+	}
+	response.Success(c, setting)
+}
+
+// UpdateReceiptSettings 更新收据/发票设置
+func (h *Handler) UpdateReceiptSettings(c *gin.Context) {
+	var req service.ReceiptSetting
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	// 未配置中文字体时不允许开启，避免向买家发出乱码收据
+	if req.Enabled {
+		if err := h.ReceiptService.CheckFont(); err != nil {
+			respondError(c, response.CodeBadRequest, "error.receipt_font_missing", nil)
+			return
+		}
+	}
+
+	setting, err := h.SettingService.UpdateReceiptSetting(req)
+	if err != nil {
+		if errors.Is(err, service.ErrReceiptConfigInvalid) {
+			respondError(c, response.CodeBadRequest, "error.bad_request", nil)
+			return
+		}
+		respondError(c, response.CodeInternal, "error.settings_save_failed", err)
+		return
+	}
+	response.Success(c, setting)
+}
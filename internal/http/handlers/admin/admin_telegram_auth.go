@@ -3,7 +3,6 @@ package admin
 import (
 	"errors"
 
-	"github.com/dujiao-next/internal/cache"
 	"github.com/dujiao-next/internal/http/response"
 	"github.com/dujiao-next/internal/service"
 
@@ -43,7 +42,7 @@ func (h *Handler) UpdateTelegramAuthSettings(c *gin.Context) {
 	if h.TelegramAuthService != nil {
 		h.TelegramAuthService.SetConfig(h.Config.TelegramAuth)
 	}
-	_ = cache.Del(c.Request.Context(), publicConfigCacheKey)
+	invalidatePublicConfigCache(c)
 
 	response.Success(c, service.MaskTelegramAuthSettingForAdmin(setting))
 }
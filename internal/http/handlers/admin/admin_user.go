@@ -55,13 +55,15 @@ type UserCouponUsageItem struct {
 // AdminUserListItem 管理端用户列表项
 type AdminUserListItem struct {
 	models.User
-	WalletBalance models.Money `json:"wallet_balance"`
+	WalletBalance models.Money        `json:"wallet_balance"`
+	MemberLevel   *models.MemberLevel `json:"member_level"`
 }
 
 // AdminUserDetail 管理端用户详情
 type AdminUserDetail struct {
 	models.User
-	WalletBalance models.Money `json:"wallet_balance"`
+	WalletBalance models.Money        `json:"wallet_balance"`
+	MemberLevel   *models.MemberLevel `json:"member_level"`
 }
 
 // GetAdminUsers 获取用户列表
@@ -122,6 +124,11 @@ func (h *Handler) GetAdminUsers(c *gin.Context) {
 		respondError(c, response.CodeInternal, "error.user_fetch_failed", err)
 		return
 	}
+	levelMap, err := h.MemberLevelService.ResolveUserLevels(users)
+	if err != nil {
+		respondError(c, response.CodeInternal, "error.user_fetch_failed", err)
+		return
+	}
 	items := make([]AdminUserListItem, 0, len(users))
 	for _, user := range users {
 		balance, ok := balanceMap[user.ID]
@@ -131,6 +138,7 @@ func (h *Handler) GetAdminUsers(c *gin.Context) {
 		items = append(items, AdminUserListItem{
 			User:          user,
 			WalletBalance: balance,
+			MemberLevel:   levelMap[user.ID],
 		})
 	}
 
@@ -160,9 +168,15 @@ func (h *Handler) GetAdminUser(c *gin.Context) {
 		respondError(c, response.CodeInternal, "error.user_fetch_failed", err)
 		return
 	}
+	level, err := h.MemberLevelService.ResolveUserLevel(user)
+	if err != nil {
+		respondError(c, response.CodeInternal, "error.user_fetch_failed", err)
+		return
+	}
 	response.Success(c, AdminUserDetail{
 		User:          *user,
 		WalletBalance: account.Balance,
+		MemberLevel:   level,
 	})
 }
 
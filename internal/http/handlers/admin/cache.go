@@ -1,5 +1,18 @@
 package admin
 
+import (
+	"github.com/dujiao-next/internal/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
 const (
 	publicConfigCacheKey = "public:config"
 )
+
+// invalidatePublicConfigCache 前台配置相关设置变更后失效缓存（含旧版缓存键）
+func invalidatePublicConfigCache(c *gin.Context) {
+	ctx := c.Request.Context()
+	_ = cache.Del(ctx, publicConfigCacheKey)
+	_ = cache.InvalidateCatalog(ctx, cache.CatalogScopeConfig)
+}
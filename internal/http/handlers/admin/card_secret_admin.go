@@ -1,6 +1,7 @@
 package admin
 
 import (
+	"encoding/json"
 	"errors"
 	"strconv"
 	"strings"
@@ -15,17 +16,20 @@ import (
 
 // CreateCardSecretBatchRequest 批量录入卡密请求
 type CreateCardSecretBatchRequest struct {
-	ProductID uint     `json:"product_id" binding:"required"`
-	SKUID     uint     `json:"sku_id"`
-	Secrets   []string `json:"secrets" binding:"required"`
-	BatchNo   string   `json:"batch_no"`
-	Note      string   `json:"note"`
+	ProductID uint                `json:"product_id" binding:"required"`
+	SKUID     uint                `json:"sku_id"`
+	Secrets   []string            `json:"secrets"`
+	Rows      []map[string]string `json:"rows"`
+	Delimiter string              `json:"delimiter"`
+	BatchNo   string              `json:"batch_no"`
+	Note      string              `json:"note"`
 }
 
 // UpdateCardSecretRequest 更新卡密请求
 type UpdateCardSecretRequest struct {
-	Secret *string `json:"secret"`
-	Status *string `json:"status"`
+	Secret *string           `json:"secret"`
+	Fields map[string]string `json:"fields"`
+	Status *string           `json:"status"`
 }
 
 // BatchUpdateCardSecretStatusRequest 批量更新卡密状态请求
@@ -61,6 +65,8 @@ func (h *Handler) CreateCardSecretBatch(c *gin.Context) {
 		ProductID: req.ProductID,
 		SKUID:     req.SKUID,
 		Secrets:   req.Secrets,
+		Rows:      req.Rows,
+		Delimiter: req.Delimiter,
 		BatchNo:   req.BatchNo,
 		Note:      req.Note,
 		Source:    constants.CardSecretSourceManual,
@@ -74,6 +80,10 @@ func (h *Handler) CreateCardSecretBatch(c *gin.Context) {
 			respondError(c, response.CodeBadRequest, "error.card_secret_invalid", nil)
 		case errors.Is(err, service.ErrCardSecretInvalid):
 			respondError(c, response.CodeBadRequest, "error.card_secret_invalid", nil)
+		case errors.Is(err, service.ErrCardSecretSchemaInvalid):
+			respondError(c, response.CodeBadRequest, "error.card_secret_schema_invalid", nil)
+		case errors.Is(err, service.ErrCardSecretDuplicate):
+			respondError(c, response.CodeBadRequest, "error.card_secret_duplicate", nil)
 		case errors.Is(err, service.ErrProductNotFound):
 			respondError(c, response.CodeNotFound, "error.product_not_found", nil)
 		case errors.Is(err, service.ErrProductFetchFailed):
@@ -87,14 +97,15 @@ func (h *Handler) CreateCardSecretBatch(c *gin.Context) {
 	}
 
 	response.Success(c, gin.H{
-		"created":  created,
-		"batch_id": batch.ID,
-		"batch_no": batch.BatchNo,
+		"created":    created,
+		"duplicates": batch.Duplicates,
+		"batch_id":   batch.ID,
+		"batch_no":   batch.BatchNo,
 	})
 }
 
-// ImportCardSecretCSV 导入 CSV 卡密
-func (h *Handler) ImportCardSecretCSV(c *gin.Context) {
+// ImportCardSecrets 从 CSV/XLSX/JSON Lines/TXT 文件导入卡密
+func (h *Handler) ImportCardSecrets(c *gin.Context) {
 	adminID, ok := getAdminID(c)
 	if !ok {
 		return
@@ -114,13 +125,23 @@ func (h *Handler) ImportCardSecretCSV(c *gin.Context) {
 		respondError(c, response.CodeBadRequest, "error.card_secret_invalid", nil)
 		return
 	}
+	var mapping map[string]string
+	if rawMapping := strings.TrimSpace(c.PostForm("mapping")); rawMapping != "" {
+		if err := json.Unmarshal([]byte(rawMapping), &mapping); err != nil {
+			respondError(c, response.CodeBadRequest, "error.card_secret_invalid", nil)
+			return
+		}
+	}
 	batchNo := strings.TrimSpace(c.PostForm("batch_no"))
 	note := strings.TrimSpace(c.PostForm("note"))
 
-	batch, created, err := h.CardSecretService.ImportCardSecretCSV(service.ImportCardSecretCSVInput{
+	batch, created, err := h.CardSecretService.ImportCardSecrets(service.ImportCardSecretsInput{
 		ProductID: uint(productID),
 		SKUID:     uint(skuID),
 		File:      file,
+		Format:    c.PostForm("format"),
+		Mapping:   mapping,
+		Delimiter: c.PostForm("delimiter"),
 		BatchNo:   batchNo,
 		Note:      note,
 		AdminID:   adminID,
@@ -133,6 +154,10 @@ func (h *Handler) ImportCardSecretCSV(c *gin.Context) {
 			respondError(c, response.CodeBadRequest, "error.card_secret_invalid", nil)
 		case errors.Is(err, service.ErrCardSecretInvalid):
 			respondError(c, response.CodeBadRequest, "error.card_secret_invalid", nil)
+		case errors.Is(err, service.ErrCardSecretSchemaInvalid):
+			respondError(c, response.CodeBadRequest, "error.card_secret_schema_invalid", nil)
+		case errors.Is(err, service.ErrCardSecretDuplicate):
+			respondError(c, response.CodeBadRequest, "error.card_secret_duplicate", nil)
 		case errors.Is(err, service.ErrProductNotFound):
 			respondError(c, response.CodeNotFound, "error.product_not_found", nil)
 		case errors.Is(err, service.ErrProductFetchFailed):
@@ -146,9 +171,10 @@ func (h *Handler) ImportCardSecretCSV(c *gin.Context) {
 	}
 
 	response.Success(c, gin.H{
-		"created":  created,
-		"batch_id": batch.ID,
-		"batch_no": batch.BatchNo,
+		"created":    created,
+		"duplicates": batch.Duplicates,
+		"batch_id":   batch.ID,
+		"batch_no":   batch.BatchNo,
 	})
 }
 
@@ -185,6 +211,7 @@ func (h *Handler) GetCardSecrets(c *gin.Context) {
 		Status:    status,
 		Page:      page,
 		PageSize:  pageSize,
+		Reveal:    h.canViewSecretPlaintext(c),
 	})
 	if err != nil {
 		switch {
@@ -226,18 +253,22 @@ func (h *Handler) UpdateCardSecret(c *gin.Context) {
 	if req.Status != nil {
 		status = *req.Status
 	}
-	if strings.TrimSpace(secret) == "" && strings.TrimSpace(status) == "" {
+	if strings.TrimSpace(secret) == "" && len(req.Fields) == 0 && strings.TrimSpace(status) == "" {
 		respondError(c, response.CodeBadRequest, "error.bad_request", nil)
 		return
 	}
 
-	item, err := h.CardSecretService.UpdateCardSecret(uint(rawID), secret, status)
+	item, err := h.CardSecretService.UpdateCardSecret(uint(rawID), secret, req.Fields, status)
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrNotFound):
 			respondError(c, response.CodeNotFound, "error.card_secret_not_found", nil)
 		case errors.Is(err, service.ErrCardSecretInvalid):
 			respondError(c, response.CodeBadRequest, "error.card_secret_invalid", nil)
+		case errors.Is(err, service.ErrCardSecretSchemaInvalid):
+			respondError(c, response.CodeBadRequest, "error.card_secret_schema_invalid", nil)
+		case errors.Is(err, service.ErrCardSecretDuplicate):
+			respondError(c, response.CodeBadRequest, "error.card_secret_duplicate", nil)
 		case errors.Is(err, service.ErrCardSecretUpdateFailed):
 			respondError(c, response.CodeInternal, "error.card_secret_update_failed", err)
 		default:
@@ -246,6 +277,9 @@ func (h *Handler) UpdateCardSecret(c *gin.Context) {
 		return
 	}
 
+	if !h.canViewSecretPlaintext(c) {
+		service.MaskCardSecret(item)
+	}
 	response.Success(c, item)
 }
 
@@ -309,7 +343,7 @@ func (h *Handler) ExportCardSecrets(c *gin.Context) {
 		return
 	}
 
-	content, contentType, err := h.CardSecretService.ExportCardSecrets(req.IDs, req.Format)
+	content, contentType, err := h.CardSecretService.ExportCardSecrets(req.IDs, req.Format, h.canViewSecretPlaintext(c))
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrCardSecretInvalid):
@@ -392,10 +426,48 @@ func (h *Handler) GetCardSecretBatches(c *gin.Context) {
 	response.SuccessWithPage(c, items, pagination)
 }
 
-// GetCardSecretTemplate 下载导入模板
+// GetCardSecretTemplate 下载导入模板，传入 product_id 时按商品卡密字段生成表头
 func (h *Handler) GetCardSecretTemplate(c *gin.Context) {
-	content := "secret\nCARD-AAA-0001\nCARD-BBB-0002\n"
+	productID, err := strconv.ParseUint(strings.TrimSpace(c.DefaultQuery("product_id", "0")), 10, 64)
+	if err != nil {
+		respondError(c, response.CodeBadRequest, "error.card_secret_invalid", nil)
+		return
+	}
+	content, err := h.CardSecretService.BuildImportTemplate(uint(productID))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrProductNotFound):
+			respondError(c, response.CodeNotFound, "error.product_not_found", nil)
+		case errors.Is(err, service.ErrCardSecretSchemaInvalid):
+			respondError(c, response.CodeBadRequest, "error.card_secret_schema_invalid", nil)
+		default:
+			respondError(c, response.CodeInternal, "error.product_fetch_failed", err)
+		}
+		return
+	}
 	c.Header("Content-Type", "text/csv; charset=utf-8")
 	c.Header("Content-Disposition", "attachment; filename=\"card-secrets-template.csv\"")
-	c.String(200, content)
+	c.Data(200, "text/csv; charset=utf-8", content)
+}
+
+// canViewSecretPlaintext 超级管理员或被授予明文查看权限的管理员可查看卡密明文
+func (h *Handler) canViewSecretPlaintext(c *gin.Context) bool {
+	if value, exists := c.Get("admin_is_super"); exists {
+		if flag, ok := value.(bool); ok && flag {
+			return true
+		}
+	}
+	adminIDRaw, exists := c.Get("admin_id")
+	if !exists || h.AuthzService == nil {
+		return false
+	}
+	adminID, ok := adminIDRaw.(uint)
+	if !ok || adminID == 0 {
+		return false
+	}
+	allowed, err := h.AuthzService.EnforceAdmin(adminID, constants.AdminPermissionSecretPlaintextObject, constants.AdminPermissionSecretPlaintextAction)
+	if err != nil {
+		return false
+	}
+	return allowed
 }
@@ -13,6 +13,7 @@ import (
 // AdminCreateFulfillmentRequest 管理端录入交付请求
 type AdminCreateFulfillmentRequest struct {
 	OrderID      uint        `json:"order_id" binding:"required"`
+	Quantity     int         `json:"quantity"`
 	Payload      string      `json:"payload"`
 	DeliveryData models.JSON `json:"delivery_data"`
 }
@@ -33,6 +34,7 @@ func (h *Handler) AdminCreateFulfillment(c *gin.Context) {
 	fulfillment, err := h.FulfillmentService.CreateManual(service.CreateManualInput{
 		OrderID:      req.OrderID,
 		AdminID:      adminID,
+		Quantity:     req.Quantity,
 		Payload:      req.Payload,
 		DeliveryData: req.DeliveryData,
 	})
@@ -40,6 +42,8 @@ func (h *Handler) AdminCreateFulfillment(c *gin.Context) {
 		switch {
 		case errors.Is(err, service.ErrFulfillmentExists):
 			respondError(c, response.CodeBadRequest, "error.fulfillment_exists", nil)
+		case errors.Is(err, service.ErrFulfillmentQuantityInvalid):
+			respondError(c, response.CodeBadRequest, "error.fulfillment_quantity_invalid", nil)
 		case errors.Is(err, service.ErrFulfillmentInvalid):
 			respondError(c, response.CodeBadRequest, "error.fulfillment_invalid", nil)
 		case errors.Is(err, service.ErrOrderStatusInvalid):
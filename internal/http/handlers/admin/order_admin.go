@@ -23,11 +23,12 @@ type AdminOrderListItem struct {
 // AdminOrderDetail 管理端订单详情返回
 type AdminOrderDetail struct {
 	models.Order
-	UserEmail       string             `json:"user_email,omitempty"`
-	UserDisplayName string             `json:"user_display_name,omitempty"`
-	CouponCode      string             `json:"coupon_code,omitempty"`
-	PromotionName   string             `json:"promotion_name,omitempty"`
-	Payments        []AdminPaymentItem `json:"payments,omitempty"`
+	UserEmail       string                     `json:"user_email,omitempty"`
+	UserDisplayName string                     `json:"user_display_name,omitempty"`
+	CouponCode      string                     `json:"coupon_code,omitempty"`
+	PromotionName   string                     `json:"promotion_name,omitempty"`
+	Payments        []AdminPaymentItem         `json:"payments,omitempty"`
+	SecretReveals   []models.OrderSecretReveal `json:"secret_reveals"`
 }
 
 // AdminListOrders 管理端订单列表
@@ -100,8 +101,12 @@ func (h *Handler) AdminListOrders(c *gin.Context) {
 		}
 	}
 
+	revealSecrets := h.canViewSecretPlaintext(c)
 	items := make([]AdminOrderListItem, 0, len(orders))
 	for _, order := range orders {
+		if !revealSecrets {
+			maskOrderFulfillmentPayloads(&order)
+		}
 		var email, displayName string
 		if user, ok := userMap[order.UserID]; ok {
 			email = user.Email
@@ -249,6 +254,15 @@ func (h *Handler) AdminGetOrder(c *gin.Context) {
 		})
 	}
 
+	reveals, err := h.SecretRevealService.ListEvidence(order)
+	if err != nil {
+		respondError(c, response.CodeInternal, "error.order_fetch_failed", err)
+		return
+	}
+
+	if !h.canViewSecretPlaintext(c) {
+		maskOrderFulfillmentPayloads(order)
+	}
 	response.Success(c, AdminOrderDetail{
 		Order:           *order,
 		UserEmail:       email,
@@ -256,6 +270,7 @@ func (h *Handler) AdminGetOrder(c *gin.Context) {
 		CouponCode:      couponCode,
 		PromotionName:   promotionName,
 		Payments:        paymentItems,
+		SecretReveals:   reveals,
 	})
 }
 
@@ -293,3 +308,19 @@ func (h *Handler) AdminUpdateOrderStatus(c *gin.Context) {
 
 	response.Success(c, order)
 }
+
+// maskOrderFulfillmentPayloads 脱敏订单及子订单的交付内容（含分批交付记录）
+func maskOrderFulfillmentPayloads(order *models.Order) {
+	if order == nil {
+		return
+	}
+	if order.Fulfillment != nil {
+		order.Fulfillment.Payload = service.MaskSecretValue(order.Fulfillment.Payload)
+		for i := range order.Fulfillment.Batches {
+			order.Fulfillment.Batches[i].Payload = service.MaskSecretValue(order.Fulfillment.Batches[i].Payload)
+		}
+	}
+	for i := range order.Children {
+		maskOrderFulfillmentPayloads(&order.Children[i])
+	}
+}
@@ -0,0 +1,51 @@
+package admin
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/provider"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMaskOrderFulfillmentPayloadsWithoutPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set("admin_id", uint(2))
+	h := &Handler{Container: &provider.Container{}}
+	if h.canViewSecretPlaintext(c) {
+		t.Fatalf("admin without permission should not view plaintext")
+	}
+
+	order := &models.Order{
+		Fulfillment: &models.Fulfillment{
+			Payload: "CODE-ABCDEFGH",
+			Batches: []models.FulfillmentBatch{{Payload: "BATCH-12345678"}},
+		},
+		Children: []models.Order{{
+			Fulfillment: &models.Fulfillment{
+				Payload: "CHILD-ABCDEFGH",
+				Batches: []models.FulfillmentBatch{{Payload: "CHILD-BATCH-9876"}, {Payload: "KEY-ONE\nKEY-TWO"}},
+			},
+		}},
+	}
+	maskOrderFulfillmentPayloads(order)
+
+	cases := []struct {
+		got  string
+		want string
+	}{
+		{order.Fulfillment.Payload, "CO*********GH"},
+		{order.Fulfillment.Batches[0].Payload, "BA**********78"},
+		{order.Children[0].Fulfillment.Payload, "CH**********GH"},
+		{order.Children[0].Fulfillment.Batches[0].Payload, "CH************76"},
+		{order.Children[0].Fulfillment.Batches[1].Payload, "KE***NE\nKE***WO"},
+	}
+	for _, tc := range cases {
+		if tc.got != tc.want {
+			t.Fatalf("expected masked payload %q, got %q", tc.want, tc.got)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package admin
+
+import (
+	"errors"
+	"mime/multipart"
+	"strconv"
+	"strings"
+
+	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminListOrderMessages 管理端查看订单沟通消息
+func (h *Handler) AdminListOrderMessages(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || orderID == 0 {
+		respondError(c, response.CodeBadRequest, "error.order_not_found", nil)
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+	page, pageSize = normalizePagination(page, pageSize)
+
+	messages, total, err := h.OrderMessageService.ListForAdmin(uint(orderID), page, pageSize)
+	if err != nil {
+		respondAdminOrderMessageError(c, err)
+		return
+	}
+	response.SuccessWithPage(c, messages, response.BuildPagination(page, pageSize, total))
+}
+
+// AdminPostOrderMessage 管理端回复订单沟通消息
+func (h *Handler) AdminPostOrderMessage(c *gin.Context) {
+	adminID, ok := getAdminID(c)
+	if !ok {
+		return
+	}
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || orderID == 0 {
+		respondError(c, response.CodeBadRequest, "error.order_not_found", nil)
+		return
+	}
+	input := service.OrderMessagePostInput{
+		Content: c.PostForm("content"),
+	}
+	if form, err := c.MultipartForm(); err == nil && form != nil {
+		input.Files = append([]*multipart.FileHeader{}, form.File["attachments"]...)
+	}
+
+	message, err := h.OrderMessageService.PostByAdmin(adminID, uint(orderID), input)
+	if err != nil {
+		respondAdminOrderMessageError(c, err)
+		return
+	}
+	response.Success(c, message)
+}
+
+// AdminListOrderMessageThreads 管理端订单沟通会话列表
+func (h *Handler) AdminListOrderMessageThreads(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, pageSize = normalizePagination(page, pageSize)
+	unreadOnly := strings.TrimSpace(c.Query("unread_only")) == "true"
+
+	threads, total, err := h.OrderMessageService.ListAdminThreads(unreadOnly, page, pageSize)
+	if err != nil {
+		respondAdminOrderMessageError(c, err)
+		return
+	}
+	response.SuccessWithPage(c, threads, response.BuildPagination(page, pageSize, total))
+}
+
+func respondAdminOrderMessageError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrOrderNotFound):
+		respondError(c, response.CodeNotFound, "error.order_not_found", nil)
+	case errors.Is(err, service.ErrOrderMessageNotAllowed):
+		respondError(c, response.CodeBadRequest, "error.order_message_not_allowed", nil)
+	case errors.Is(err, service.ErrOrderMessageClosed):
+		respondError(c, response.CodeBadRequest, "error.order_message_closed", nil)
+	case errors.Is(err, service.ErrOrderMessageInvalid):
+		respondError(c, response.CodeBadRequest, "error.order_message_invalid", nil)
+	case errors.Is(err, service.ErrOrderMessageAttachmentFailed):
+		respondError(c, response.CodeBadRequest, "error.order_message_attachment_failed", err)
+	case errors.Is(err, service.ErrOrderFetchFailed):
+		respondError(c, response.CodeInternal, "error.order_fetch_failed", err)
+	case errors.Is(err, service.ErrOrderMessageCreateFailed):
+		respondError(c, response.CodeInternal, "error.order_message_create_failed", err)
+	default:
+		respondError(c, response.CodeInternal, "error.order_message_fetch_failed", err)
+	}
+}
@@ -0,0 +1,68 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreviewDeliveryTemplateRequest 交付模板预览请求
+type PreviewDeliveryTemplateRequest struct {
+	SKUID    uint                   `json:"sku_id"`
+	OrderID  uint                   `json:"order_id"`
+	Locale   string                 `json:"locale"`
+	Template map[string]interface{} `json:"delivery_template"`
+}
+
+// PreviewDeliveryTemplate 使用示例订单或指定订单预览交付模板渲染结果
+func (h *Handler) PreviewDeliveryTemplate(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || productID == 0 {
+		respondError(c, response.CodeBadRequest, "error.product_not_found", nil)
+		return
+	}
+	var req PreviewDeliveryTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	locale := strings.TrimSpace(req.Locale)
+	if locale == "" {
+		locale = constants.LocaleZhCN
+	}
+
+	rendered, err := h.DeliveryRenderService.Preview(service.DeliveryPreviewInput{
+		ProductID: uint(productID),
+		SKUID:     req.SKUID,
+		OrderID:   req.OrderID,
+		Template:  models.JSON(req.Template),
+		Locale:    locale,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrProductNotFound):
+			respondError(c, response.CodeNotFound, "error.product_not_found", nil)
+		case errors.Is(err, service.ErrOrderNotFound):
+			respondError(c, response.CodeNotFound, "error.order_not_found", nil)
+		case errors.Is(err, service.ErrProductSKUInvalid):
+			respondError(c, response.CodeBadRequest, "error.bad_request", nil)
+		case errors.Is(err, service.ErrDeliveryTemplateInvalid):
+			respondError(c, response.CodeBadRequest, "error.delivery_template_invalid", nil)
+		case errors.Is(err, service.ErrCardSecretSchemaInvalid):
+			respondError(c, response.CodeBadRequest, "error.card_secret_schema_invalid", nil)
+		case errors.Is(err, service.ErrOrderFetchFailed):
+			respondError(c, response.CodeInternal, "error.order_fetch_failed", err)
+		default:
+			respondError(c, response.CodeInternal, "error.product_fetch_failed", err)
+		}
+		return
+	}
+	response.Success(c, gin.H{"rendered_content": rendered})
+}
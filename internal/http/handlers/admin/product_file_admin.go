@@ -0,0 +1,122 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListProductFiles 获取商品交付文件列表
+func (h *Handler) ListProductFiles(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || productID == 0 {
+		respondError(c, response.CodeBadRequest, "error.product_not_found", nil)
+		return
+	}
+	files, err := h.DownloadService.ListProductFiles(uint(productID))
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			respondError(c, response.CodeNotFound, "error.product_not_found", nil)
+			return
+		}
+		respondError(c, response.CodeInternal, "error.product_fetch_failed", err)
+		return
+	}
+	response.Success(c, files)
+}
+
+// UploadProductFile 上传商品交付文件（multipart: file, sku_id, sort_order）
+func (h *Handler) UploadProductFile(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || productID == 0 {
+		respondError(c, response.CodeBadRequest, "error.product_not_found", nil)
+		return
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		respondError(c, response.CodeBadRequest, "error.file_missing", nil)
+		return
+	}
+	var skuID uint64
+	if raw := strings.TrimSpace(c.PostForm("sku_id")); raw != "" {
+		skuID, err = strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			respondError(c, response.CodeBadRequest, "error.bad_request", nil)
+			return
+		}
+	}
+	sortOrder := 0
+	if raw := strings.TrimSpace(c.PostForm("sort_order")); raw != "" {
+		sortOrder, err = strconv.Atoi(raw)
+		if err != nil {
+			respondError(c, response.CodeBadRequest, "error.bad_request", nil)
+			return
+		}
+	}
+
+	row, err := h.DownloadService.UploadProductFile(uint(productID), uint(skuID), file, sortOrder)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrProductNotFound):
+			respondError(c, response.CodeNotFound, "error.product_not_found", nil)
+		case errors.Is(err, service.ErrProductSKUInvalid):
+			respondError(c, response.CodeBadRequest, "error.bad_request", nil)
+		case errors.Is(err, service.ErrProductFileUploadFailed):
+			respondError(c, response.CodeBadRequest, "error.product_file_upload_failed", err)
+		default:
+			respondError(c, response.CodeInternal, "error.product_file_upload_failed", err)
+		}
+		return
+	}
+	response.Success(c, row)
+}
+
+// DeleteProductFile 删除商品交付文件
+func (h *Handler) DeleteProductFile(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || productID == 0 {
+		respondError(c, response.CodeBadRequest, "error.product_not_found", nil)
+		return
+	}
+	fileID, err := strconv.ParseUint(c.Param("file_id"), 10, 64)
+	if err != nil || fileID == 0 {
+		respondError(c, response.CodeBadRequest, "error.product_file_not_found", nil)
+		return
+	}
+	if err := h.DownloadService.DeleteProductFile(uint(productID), uint(fileID)); err != nil {
+		if errors.Is(err, service.ErrProductFileNotFound) {
+			respondError(c, response.CodeNotFound, "error.product_file_not_found", nil)
+			return
+		}
+		respondError(c, response.CodeInternal, "error.product_update_failed", err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// AdminListOrderDownloads 获取订单文件下载授权与下载日志
+func (h *Handler) AdminListOrderDownloads(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || orderID == 0 {
+		respondError(c, response.CodeBadRequest, "error.order_not_found", nil)
+		return
+	}
+	downloads, logs, err := h.DownloadService.ListOrderDownloads(uint(orderID))
+	if err != nil {
+		if errors.Is(err, service.ErrOrderNotFound) {
+			respondError(c, response.CodeNotFound, "error.order_not_found", nil)
+			return
+		}
+		respondError(c, response.CodeInternal, "error.order_fetch_failed", err)
+		return
+	}
+	response.Success(c, gin.H{
+		"downloads": downloads,
+		"logs":      logs,
+	})
+}
@@ -0,0 +1,101 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/repository"
+	"github.com/dujiao-next/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateProductReviewStatusRequest 评价审核请求
+type UpdateProductReviewStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// ReplyProductReviewRequest 评价回复请求
+type ReplyProductReviewRequest struct {
+	Reply string `json:"reply"`
+}
+
+// GetAdminProductReviews 获取评价列表
+func (h *Handler) GetAdminProductReviews(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, pageSize = normalizePagination(page, pageSize)
+
+	productID, _ := strconv.ParseUint(c.Query("product_id"), 10, 64)
+	userID, _ := strconv.ParseUint(c.Query("user_id"), 10, 64)
+	rating, _ := strconv.Atoi(c.Query("rating"))
+
+	reviews, total, err := h.ProductReviewService.ListAdmin(repository.ProductReviewListFilter{
+		ProductID: uint(productID),
+		UserID:    uint(userID),
+		Status:    strings.TrimSpace(c.Query("status")),
+		Rating:    rating,
+		Page:      page,
+		PageSize:  pageSize,
+	})
+	if err != nil {
+		respondError(c, response.CodeInternal, "error.product_review_fetch_failed", err)
+		return
+	}
+
+	pagination := response.BuildPagination(page, pageSize, total)
+	response.SuccessWithPage(c, reviews, pagination)
+}
+
+// UpdateProductReviewStatus 审核评价（公开/隐藏）
+func (h *Handler) UpdateProductReviewStatus(c *gin.Context) {
+	reviewID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || reviewID == 0 {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	var req UpdateProductReviewStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	review, err := h.ProductReviewService.UpdateStatus(uint(reviewID), req.Status)
+	if err != nil {
+		respondProductReviewError(c, err)
+		return
+	}
+	response.Success(c, review)
+}
+
+// ReplyProductReview 回复评价
+func (h *Handler) ReplyProductReview(c *gin.Context) {
+	reviewID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || reviewID == 0 {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	var req ReplyProductReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	review, err := h.ProductReviewService.Reply(uint(reviewID), req.Reply)
+	if err != nil {
+		respondProductReviewError(c, err)
+		return
+	}
+	response.Success(c, review)
+}
+
+func respondProductReviewError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrProductReviewNotFound):
+		respondError(c, response.CodeNotFound, "error.product_review_not_found", nil)
+	case errors.Is(err, service.ErrProductReviewInvalid):
+		respondError(c, response.CodeBadRequest, "error.product_review_invalid", nil)
+	default:
+		respondError(c, response.CodeInternal, "error.product_review_update_failed", err)
+	}
+}
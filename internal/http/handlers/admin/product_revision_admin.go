@@ -0,0 +1,97 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListProductRevisions 获取商品修订列表
+func (h *Handler) ListProductRevisions(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || productID == 0 {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, pageSize = normalizePagination(page, pageSize)
+
+	revisions, total, err := h.ProductService.ListRevisions(uint(productID), page, pageSize)
+	if err != nil {
+		respondError(c, response.CodeInternal, "error.product_revision_fetch_failed", err)
+		return
+	}
+	pagination := response.BuildPagination(page, pageSize, total)
+	response.SuccessWithPage(c, revisions, pagination)
+}
+
+// DiffProductRevision 查看修订字段差异，against 为空时与上一修订比较
+func (h *Handler) DiffProductRevision(c *gin.Context) {
+	productID, revisionID, ok := parseProductRevisionParams(c)
+	if !ok {
+		return
+	}
+	var againstID uint64
+	if raw := c.Query("against"); raw != "" {
+		parsed, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			respondError(c, response.CodeBadRequest, "error.bad_request", err)
+			return
+		}
+		againstID = parsed
+	}
+
+	diff, err := h.ProductService.DiffRevisions(productID, revisionID, uint(againstID))
+	if err != nil {
+		if errors.Is(err, service.ErrProductRevisionNotFound) {
+			respondError(c, response.CodeNotFound, "error.product_revision_not_found", nil)
+			return
+		}
+		respondError(c, response.CodeInternal, "error.product_revision_fetch_failed", err)
+		return
+	}
+	response.Success(c, diff)
+}
+
+// RestoreProductRevision 恢复商品到指定修订
+func (h *Handler) RestoreProductRevision(c *gin.Context) {
+	productID, revisionID, ok := parseProductRevisionParams(c)
+	if !ok {
+		return
+	}
+
+	product, err := h.ProductService.RestoreRevision(productID, revisionID, currentAdminID(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrProductRevisionNotFound):
+			respondError(c, response.CodeNotFound, "error.product_revision_not_found", nil)
+		case errors.Is(err, service.ErrNotFound):
+			respondError(c, response.CodeNotFound, "error.product_not_found", nil)
+		case errors.Is(err, service.ErrSlugExists):
+			respondError(c, response.CodeBadRequest, "error.slug_used", nil)
+		default:
+			respondError(c, response.CodeInternal, "error.product_revision_restore_failed", err)
+		}
+		return
+	}
+	response.Success(c, product)
+}
+
+func parseProductRevisionParams(c *gin.Context) (uint, uint, bool) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || productID == 0 {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return 0, 0, false
+	}
+	revisionID, err := strconv.ParseUint(c.Param("revision_id"), 10, 64)
+	if err != nil || revisionID == 0 {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return 0, 0, false
+	}
+	return uint(productID), uint(revisionID), true
+}
@@ -0,0 +1,81 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportProducts 批量导出商品（json/csv/xlsx）
+func (h *Handler) ExportProducts(c *gin.Context) {
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", "json")))
+	content, contentType, err := h.ProductService.ExportProducts(service.ProductExportInput{
+		CategoryID:      c.Query("category_id"),
+		Search:          c.Query("search"),
+		FulfillmentType: c.Query("fulfillment_type"),
+		Format:          format,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrProductTransferFormatInvalid) {
+			respondError(c, response.CodeBadRequest, "error.product_transfer_format_invalid", nil)
+			return
+		}
+		respondError(c, response.CodeInternal, "error.product_fetch_failed", err)
+		return
+	}
+
+	filename := "products-" + time.Now().Format("20060102-150405") + "." + format
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	c.Data(200, contentType, content)
+}
+
+// ImportProducts 批量导入商品，默认试运行，dry_run=false 时才真正写入
+func (h *Handler) ImportProducts(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		respondError(c, response.CodeBadRequest, "error.file_missing", nil)
+		return
+	}
+	dryRun := true
+	if raw := strings.TrimSpace(c.PostForm("dry_run")); raw != "" {
+		dryRun, err = strconv.ParseBool(raw)
+		if err != nil {
+			respondError(c, response.CodeBadRequest, "error.bad_request", nil)
+			return
+		}
+	}
+	reader, err := file.Open()
+	if err != nil {
+		respondError(c, response.CodeBadRequest, "error.product_import_invalid", nil)
+		return
+	}
+	defer reader.Close()
+
+	result, err := h.ProductService.ImportProducts(service.ProductImportInput{
+		Reader:   reader,
+		Filename: file.Filename,
+		Format:   c.PostForm("format"),
+		DryRun:   dryRun,
+		AdminID:  currentAdminID(c),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrProductTransferFormatInvalid):
+			respondError(c, response.CodeBadRequest, "error.product_transfer_format_invalid", nil)
+		case errors.Is(err, service.ErrProductImportInvalid):
+			respondError(c, response.CodeBadRequest, "error.product_import_invalid", nil)
+		default:
+			respondError(c, response.CodeInternal, "error.product_import_failed", err)
+		}
+		return
+	}
+
+	response.Success(c, result)
+}
@@ -0,0 +1,160 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SupplierUpsertRequest 上游供应商创建/更新请求
+type SupplierUpsertRequest struct {
+	Name           string `json:"name" binding:"required"`
+	Endpoint       string `json:"endpoint" binding:"required"`
+	SigningSecret  string `json:"signing_secret"`
+	TimeoutSeconds int    `json:"timeout_seconds"`
+	MaxAttempts    int    `json:"max_attempts"`
+	IsActive       *bool  `json:"is_active"`
+	Remark         string `json:"remark"`
+}
+
+func (req SupplierUpsertRequest) toInput() service.SupplierInput {
+	return service.SupplierInput{
+		Name:           req.Name,
+		Endpoint:       req.Endpoint,
+		SigningSecret:  req.SigningSecret,
+		TimeoutSeconds: req.TimeoutSeconds,
+		MaxAttempts:    req.MaxAttempts,
+		IsActive:       req.IsActive,
+		Remark:         req.Remark,
+	}
+}
+
+// GetAdminSuppliers 获取上游供应商列表
+func (h *Handler) GetAdminSuppliers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, pageSize = normalizePagination(page, pageSize)
+
+	var isActive *bool
+	if raw := c.Query("is_active"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			respondError(c, response.CodeBadRequest, "error.bad_request", err)
+			return
+		}
+		isActive = &parsed
+	}
+
+	suppliers, total, err := h.SupplierService.ListAdmin(c.Query("search"), isActive, page, pageSize)
+	if err != nil {
+		respondError(c, response.CodeInternal, "error.supplier_fetch_failed", err)
+		return
+	}
+	response.SuccessWithPage(c, suppliers, response.BuildPagination(page, pageSize, total))
+}
+
+// GetAdminSupplier 获取上游供应商详情
+func (h *Handler) GetAdminSupplier(c *gin.Context) {
+	id, ok := parsePathUint(c, "id")
+	if !ok {
+		respondError(c, response.CodeBadRequest, "error.bad_request", nil)
+		return
+	}
+	supplier, err := h.SupplierService.GetByID(id)
+	if err != nil {
+		respondSupplierError(c, err, "error.supplier_fetch_failed")
+		return
+	}
+	response.Success(c, supplier)
+}
+
+// CreateSupplier 创建上游供应商
+func (h *Handler) CreateSupplier(c *gin.Context) {
+	var req SupplierUpsertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	supplier, err := h.SupplierService.Create(req.toInput())
+	if err != nil {
+		respondSupplierError(c, err, "error.supplier_save_failed")
+		return
+	}
+	response.Success(c, supplier)
+}
+
+// UpdateSupplier 更新上游供应商
+func (h *Handler) UpdateSupplier(c *gin.Context) {
+	id, ok := parsePathUint(c, "id")
+	if !ok {
+		respondError(c, response.CodeBadRequest, "error.bad_request", nil)
+		return
+	}
+	var req SupplierUpsertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	supplier, err := h.SupplierService.Update(id, req.toInput())
+	if err != nil {
+		respondSupplierError(c, err, "error.supplier_save_failed")
+		return
+	}
+	response.Success(c, supplier)
+}
+
+// DeleteSupplier 删除上游供应商
+func (h *Handler) DeleteSupplier(c *gin.Context) {
+	id, ok := parsePathUint(c, "id")
+	if !ok {
+		respondError(c, response.CodeBadRequest, "error.bad_request", nil)
+		return
+	}
+	if err := h.SupplierService.Delete(id); err != nil {
+		respondSupplierError(c, err, "error.supplier_delete_failed")
+		return
+	}
+	response.Success(c, gin.H{"deleted": true})
+}
+
+// AdminRetrySupplierFulfillment 重新调用上游接口交付订单
+func (h *Handler) AdminRetrySupplierFulfillment(c *gin.Context) {
+	orderID, ok := parsePathUint(c, "id")
+	if !ok {
+		respondError(c, response.CodeBadRequest, "error.order_not_found", nil)
+		return
+	}
+	if err := h.SupplierService.RetryDispatch(orderID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrOrderNotFound):
+			respondError(c, response.CodeNotFound, "error.order_not_found", nil)
+		case errors.Is(err, service.ErrFulfillmentNotAPI), errors.Is(err, service.ErrOrderStatusInvalid):
+			respondError(c, response.CodeBadRequest, "error.order_status_invalid", nil)
+		case errors.Is(err, service.ErrFulfillmentExists):
+			respondError(c, response.CodeBadRequest, "error.fulfillment_exists", nil)
+		case errors.Is(err, service.ErrQueueUnavailable):
+			respondError(c, response.CodeInternal, "error.queue_unavailable", err)
+		default:
+			respondError(c, response.CodeInternal, "error.order_update_failed", err)
+		}
+		return
+	}
+	response.Success(c, gin.H{"queued": true})
+}
+
+func respondSupplierError(c *gin.Context, err error, fallbackKey string) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		respondError(c, response.CodeNotFound, "error.supplier_not_found", nil)
+	case errors.Is(err, service.ErrSupplierInvalid):
+		respondError(c, response.CodeBadRequest, "error.supplier_invalid", nil)
+	case errors.Is(err, service.ErrSupplierInUse):
+		respondError(c, response.CodeBadRequest, "error.supplier_in_use", nil)
+	default:
+		respondError(c, response.CodeInternal, fallbackKey, err)
+	}
+}
@@ -1,9 +1,12 @@
 package public
 
 import (
+	"fmt"
 	"strconv"
 
+	"github.com/dujiao-next/internal/cache"
 	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,11 +22,19 @@ func (h *Handler) GetPublicBanners(c *gin.Context) {
 		limit = 50
 	}
 
+	cacheKey := fmt.Sprintf("%s:%d", position, limit)
+	var cached []models.Banner
+	if hit, err := cache.GetCatalogJSON(c.Request.Context(), cache.CatalogScopeBanners, cacheKey, &cached); err == nil && hit {
+		response.Success(c, cached)
+		return
+	}
+
 	banners, err := h.BannerService.ListPublic(position, limit)
 	if err != nil {
 		respondError(c, response.CodeInternal, "error.banner_fetch_failed", err)
 		return
 	}
 
+	_ = cache.SetCatalogJSON(c.Request.Context(), cache.CatalogScopeBanners, cacheKey, banners, publicCatalogCacheTTL)
 	response.Success(c, banners)
 }
@@ -17,6 +17,7 @@ type CartItemRequest struct {
 	SKUID           uint   `json:"sku_id"`
 	Quantity        int    `json:"quantity" binding:"required"`
 	FulfillmentType string `json:"fulfillment_type"`
+	AccessCode      string `json:"access_code"`
 }
 
 // CartProduct 购物车商品摘要
@@ -124,6 +125,7 @@ func (h *Handler) UpsertCartItem(c *gin.Context) {
 		SKUID:           req.SKUID,
 		Quantity:        req.Quantity,
 		FulfillmentType: req.FulfillmentType,
+		AccessCode:      req.AccessCode,
 	}); err != nil {
 		switch {
 		case errors.Is(err, service.ErrProductSKURequired):
@@ -134,6 +136,10 @@ func (h *Handler) UpsertCartItem(c *gin.Context) {
 			respondError(c, response.CodeBadRequest, "error.order_item_invalid", nil)
 		case errors.Is(err, service.ErrProductNotAvailable):
 			respondError(c, response.CodeBadRequest, "error.product_not_available", nil)
+		case errors.Is(err, service.ErrProductAccessCodeRequired):
+			respondError(c, response.CodeForbidden, "error.product_access_code_required", nil)
+		case errors.Is(err, service.ErrProductAccessCodeInvalid):
+			respondError(c, response.CodeForbidden, "error.product_access_code_invalid", nil)
 		case errors.Is(err, service.ErrManualStockInsufficient):
 			respondError(c, response.CodeBadRequest, "error.manual_stock_insufficient", nil)
 		case errors.Is(err, service.ErrFulfillmentInvalid):
@@ -49,6 +49,8 @@ var userOrderCommonErrorRules = []mappedHandlerError{
 	{target: service.ErrOrderCurrencyMismatch, code: response.CodeBadRequest, key: "error.order_currency_mismatch"},
 	{target: service.ErrProductPriceInvalid, code: response.CodeBadRequest, key: "error.product_price_invalid"},
 	{target: service.ErrProductNotAvailable, code: response.CodeBadRequest, key: "error.product_not_available"},
+	{target: service.ErrProductAccessCodeRequired, code: response.CodeForbidden, key: "error.product_access_code_required"},
+	{target: service.ErrProductAccessCodeInvalid, code: response.CodeForbidden, key: "error.product_access_code_invalid"},
 	{target: service.ErrCouponInvalid, code: response.CodeBadRequest, key: "error.coupon_invalid"},
 	{target: service.ErrCouponNotFound, code: response.CodeBadRequest, key: "error.coupon_not_found"},
 	{target: service.ErrCouponInactive, code: response.CodeBadRequest, key: "error.coupon_inactive"},
@@ -58,6 +60,8 @@ var userOrderCommonErrorRules = []mappedHandlerError{
 	{target: service.ErrCouponPerUserLimit, code: response.CodeBadRequest, key: "error.coupon_per_user_limit"},
 	{target: service.ErrCouponMinAmount, code: response.CodeBadRequest, key: "error.coupon_min_amount"},
 	{target: service.ErrCouponScopeInvalid, code: response.CodeBadRequest, key: "error.coupon_scope_invalid"},
+	{target: service.ErrCouponUserNotEligible, code: response.CodeBadRequest, key: "error.coupon_user_not_eligible"},
+	{target: service.ErrCouponFirstOrderOnly, code: response.CodeBadRequest, key: "error.coupon_first_order_only"},
 	{target: service.ErrPromotionInvalid, code: response.CodeBadRequest, key: "error.promotion_invalid"},
 	{target: service.ErrManualFormSchemaInvalid, code: response.CodeBadRequest, key: "error.manual_form_schema_invalid"},
 	{target: service.ErrManualFormRequiredMissing, code: response.CodeBadRequest, key: "error.manual_form_required_missing"},
@@ -78,6 +82,17 @@ var guestOrderCommonErrorRules = []mappedHandlerError{
 	{target: service.ErrInvalidEmail, code: response.CodeBadRequest, key: "error.email_invalid"},
 	{target: service.ErrProductPurchaseNotAllowed, code: response.CodeBadRequest, key: "error.product_purchase_not_allowed"},
 	{target: service.ErrGuestCouponNotAllowed, code: response.CodeBadRequest, key: "error.guest_coupon_not_allowed"},
+	{target: service.ErrCouponInvalid, code: response.CodeBadRequest, key: "error.coupon_invalid"},
+	{target: service.ErrCouponNotFound, code: response.CodeBadRequest, key: "error.coupon_not_found"},
+	{target: service.ErrCouponInactive, code: response.CodeBadRequest, key: "error.coupon_inactive"},
+	{target: service.ErrCouponNotStarted, code: response.CodeBadRequest, key: "error.coupon_not_started"},
+	{target: service.ErrCouponExpired, code: response.CodeBadRequest, key: "error.coupon_expired"},
+	{target: service.ErrCouponUsageLimit, code: response.CodeBadRequest, key: "error.coupon_usage_limit"},
+	{target: service.ErrCouponPerUserLimit, code: response.CodeBadRequest, key: "error.coupon_per_user_limit"},
+	{target: service.ErrCouponMinAmount, code: response.CodeBadRequest, key: "error.coupon_min_amount"},
+	{target: service.ErrCouponScopeInvalid, code: response.CodeBadRequest, key: "error.coupon_scope_invalid"},
+	{target: service.ErrCouponUserNotEligible, code: response.CodeBadRequest, key: "error.coupon_user_not_eligible"},
+	{target: service.ErrCouponFirstOrderOnly, code: response.CodeBadRequest, key: "error.coupon_first_order_only"},
 	{target: service.ErrInvalidOrderItem, code: response.CodeBadRequest, key: "error.order_item_invalid"},
 	{target: service.ErrInvalidOrderAmount, code: response.CodeBadRequest, key: "error.order_amount_invalid"},
 	{target: service.ErrManualStockInsufficient, code: response.CodeBadRequest, key: "error.manual_stock_insufficient"},
@@ -85,6 +100,8 @@ var guestOrderCommonErrorRules = []mappedHandlerError{
 	{target: service.ErrOrderCurrencyMismatch, code: response.CodeBadRequest, key: "error.order_currency_mismatch"},
 	{target: service.ErrProductPriceInvalid, code: response.CodeBadRequest, key: "error.product_price_invalid"},
 	{target: service.ErrProductNotAvailable, code: response.CodeBadRequest, key: "error.product_not_available"},
+	{target: service.ErrProductAccessCodeRequired, code: response.CodeForbidden, key: "error.product_access_code_required"},
+	{target: service.ErrProductAccessCodeInvalid, code: response.CodeForbidden, key: "error.product_access_code_invalid"},
 	{target: service.ErrManualFormSchemaInvalid, code: response.CodeBadRequest, key: "error.manual_form_schema_invalid"},
 	{target: service.ErrManualFormRequiredMissing, code: response.CodeBadRequest, key: "error.manual_form_required_missing"},
 	{target: service.ErrManualFormFieldInvalid, code: response.CodeBadRequest, key: "error.manual_form_field_invalid"},
@@ -97,15 +114,6 @@ var guestOrderCreateExtraErrorRules = []mappedHandlerError{
 }
 
 var guestOrderPreviewExtraErrorRules = []mappedHandlerError{
-	{target: service.ErrCouponInvalid, code: response.CodeBadRequest, key: "error.coupon_invalid"},
-	{target: service.ErrCouponNotFound, code: response.CodeBadRequest, key: "error.coupon_not_found"},
-	{target: service.ErrCouponInactive, code: response.CodeBadRequest, key: "error.coupon_inactive"},
-	{target: service.ErrCouponNotStarted, code: response.CodeBadRequest, key: "error.coupon_not_started"},
-	{target: service.ErrCouponExpired, code: response.CodeBadRequest, key: "error.coupon_expired"},
-	{target: service.ErrCouponUsageLimit, code: response.CodeBadRequest, key: "error.coupon_usage_limit"},
-	{target: service.ErrCouponPerUserLimit, code: response.CodeBadRequest, key: "error.coupon_per_user_limit"},
-	{target: service.ErrCouponMinAmount, code: response.CodeBadRequest, key: "error.coupon_min_amount"},
-	{target: service.ErrCouponScopeInvalid, code: response.CodeBadRequest, key: "error.coupon_scope_invalid"},
 	{target: service.ErrPromotionInvalid, code: response.CodeBadRequest, key: "error.promotion_invalid"},
 }
 
@@ -0,0 +1,46 @@
+package public
+
+import (
+	"errors"
+
+	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VerifyLicenseRequest 授权码校验请求
+type VerifyLicenseRequest struct {
+	License string `json:"license" binding:"required"`
+}
+
+// GetLicensePublicKey 获取授权码验签公钥，客户端可据此离线校验签名
+func (h *Handler) GetLicensePublicKey(c *gin.Context) {
+	if !h.LicenseService.Enabled() {
+		respondError(c, response.CodeNotFound, "error.key_generator_unavailable", nil)
+		return
+	}
+	response.Success(c, gin.H{
+		"algorithm":  "Ed25519",
+		"public_key": h.LicenseService.PublicKey(),
+	})
+}
+
+// VerifyLicense 在线校验授权码（签名、有效期与发放记录）
+func (h *Handler) VerifyLicense(c *gin.Context) {
+	var req VerifyLicenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	result, err := h.LicenseService.Verify(req.License)
+	if err != nil {
+		if errors.Is(err, service.ErrKeyGeneratorUnavailable) {
+			respondError(c, response.CodeNotFound, "error.key_generator_unavailable", nil)
+			return
+		}
+		respondError(c, response.CodeInternal, "error.card_secret_fetch_failed", err)
+		return
+	}
+	response.Success(c, result)
+}
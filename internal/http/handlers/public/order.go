@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/i18n"
 	"github.com/dujiao-next/internal/models"
 	"github.com/dujiao-next/internal/repository"
 	"github.com/dujiao-next/internal/service"
@@ -19,6 +20,7 @@ type OrderItemRequest struct {
 	SKUID           uint   `json:"sku_id"`
 	Quantity        int    `json:"quantity" binding:"required"`
 	FulfillmentType string `json:"fulfillment_type"`
+	AccessCode      string `json:"access_code"` // 访问码保护商品的访问码
 }
 
 // CreateOrderRequest 创建订单请求
@@ -50,6 +52,7 @@ func (h *Handler) PreviewOrder(c *gin.Context) {
 			SKUID:           item.SKUID,
 			Quantity:        item.Quantity,
 			FulfillmentType: item.FulfillmentType,
+			AccessCode:      item.AccessCode,
 		})
 	}
 
@@ -90,6 +93,7 @@ func (h *Handler) CreateOrder(c *gin.Context) {
 			SKUID:           item.SKUID,
 			Quantity:        item.Quantity,
 			FulfillmentType: item.FulfillmentType,
+			AccessCode:      item.AccessCode,
 		})
 	}
 
@@ -135,6 +139,9 @@ func (h *Handler) ListOrders(c *gin.Context) {
 		respondError(c, response.CodeInternal, "error.order_fetch_failed", err)
 		return
 	}
+	for i := range orders {
+		h.SecretRevealService.ApplyToOrder(&orders[i])
+	}
 
 	pagination := response.BuildPagination(page, pageSize, total)
 	response.SuccessWithPage(c, orders, pagination)
@@ -163,6 +170,9 @@ func (h *Handler) GetOrder(c *gin.Context) {
 		return
 	}
 
+	h.DeliveryRenderService.ApplyToOrder(order, i18n.ResolveLocale(c))
+	h.DownloadService.ApplyToOrder(order)
+	h.SecretRevealService.ApplyToOrder(order)
 	response.Success(c, order)
 }
 
@@ -189,6 +199,9 @@ func (h *Handler) GetOrderByOrderNo(c *gin.Context) {
 		return
 	}
 
+	h.DeliveryRenderService.ApplyToOrder(order, i18n.ResolveLocale(c))
+	h.DownloadService.ApplyToOrder(order)
+	h.SecretRevealService.ApplyToOrder(order)
 	response.Success(c, order)
 }
 
@@ -0,0 +1,50 @@
+package public
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DownloadOrderFile 通过签名链接下载文件交付订单的文件
+func (h *Handler) DownloadOrderFile(c *gin.Context) {
+	downloadID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || downloadID == 0 {
+		respondError(c, response.CodeBadRequest, "error.download_link_invalid", nil)
+		return
+	}
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		respondError(c, response.CodeBadRequest, "error.download_link_invalid", nil)
+		return
+	}
+
+	file, err := h.DownloadService.Download(service.DownloadInput{
+		DownloadID: uint(downloadID),
+		Expires:    expires,
+		Signature:  c.Query("sig"),
+		ClientIP:   c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrDownloadLinkInvalid):
+			respondError(c, response.CodeForbidden, "error.download_link_invalid", nil)
+		case errors.Is(err, service.ErrDownloadLinkExpired):
+			respondError(c, response.CodeForbidden, "error.download_link_expired", nil)
+		case errors.Is(err, service.ErrDownloadLimitExceeded):
+			respondError(c, response.CodeTooManyRequests, "error.download_limit_exceeded", nil)
+		case errors.Is(err, service.ErrDownloadUnavailable):
+			respondError(c, response.CodeNotFound, "error.download_unavailable", nil)
+		default:
+			respondError(c, response.CodeInternal, "error.download_unavailable", err)
+		}
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.FileAttachment(file.Path, file.Name)
+}
@@ -0,0 +1,191 @@
+package public
+
+import (
+	"errors"
+	"mime/multipart"
+	"strconv"
+	"strings"
+
+	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListOrderMessages 用户查看订单沟通消息
+func (h *Handler) ListOrderMessages(c *gin.Context) {
+	uid, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || orderID == 0 {
+		respondError(c, response.CodeBadRequest, "error.order_item_invalid", nil)
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+	page, pageSize = normalizePagination(page, pageSize)
+
+	messages, total, err := h.OrderMessageService.ListForUser(uid, uint(orderID), page, pageSize)
+	if err != nil {
+		respondOrderMessageError(c, err)
+		return
+	}
+	response.SuccessWithPage(c, messages, response.BuildPagination(page, pageSize, total))
+}
+
+// PostOrderMessage 用户发送订单沟通消息
+func (h *Handler) PostOrderMessage(c *gin.Context) {
+	uid, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || orderID == 0 {
+		respondError(c, response.CodeBadRequest, "error.order_item_invalid", nil)
+		return
+	}
+	message, err := h.OrderMessageService.PostByUser(uid, uint(orderID), parseOrderMessagePostInput(c))
+	if err != nil {
+		respondOrderMessageError(c, err)
+		return
+	}
+	response.Success(c, message)
+}
+
+// ListOrderMessageThreads 用户订单沟通会话列表（含未读数）
+func (h *Handler) ListOrderMessageThreads(c *gin.Context) {
+	uid, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, pageSize = normalizePagination(page, pageSize)
+	unreadOnly := strings.TrimSpace(c.Query("unread_only")) == "true"
+
+	threads, total, err := h.OrderMessageService.ListUserThreads(uid, unreadOnly, page, pageSize)
+	if err != nil {
+		respondOrderMessageError(c, err)
+		return
+	}
+	response.SuccessWithPage(c, threads, response.BuildPagination(page, pageSize, total))
+}
+
+// ListGuestOrderMessages 游客查看订单沟通消息
+func (h *Handler) ListGuestOrderMessages(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || orderID == 0 {
+		respondError(c, response.CodeBadRequest, "error.order_item_invalid", nil)
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+	page, pageSize = normalizePagination(page, pageSize)
+
+	email := strings.TrimSpace(c.Query("email"))
+	password := strings.TrimSpace(c.Query("order_password"))
+	messages, total, err := h.OrderMessageService.ListForGuest(email, password, uint(orderID), page, pageSize)
+	if err != nil {
+		respondOrderMessageError(c, err)
+		return
+	}
+	response.SuccessWithPage(c, messages, response.BuildPagination(page, pageSize, total))
+}
+
+// PostGuestOrderMessage 游客发送订单沟通消息
+func (h *Handler) PostGuestOrderMessage(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || orderID == 0 {
+		respondError(c, response.CodeBadRequest, "error.order_item_invalid", nil)
+		return
+	}
+	email := strings.TrimSpace(c.PostForm("email"))
+	password := strings.TrimSpace(c.PostForm("order_password"))
+	message, err := h.OrderMessageService.PostByGuest(email, password, uint(orderID), parseOrderMessagePostInput(c))
+	if err != nil {
+		respondOrderMessageError(c, err)
+		return
+	}
+	response.Success(c, message)
+}
+
+func parseOrderMessagePostInput(c *gin.Context) service.OrderMessagePostInput {
+	input := service.OrderMessagePostInput{
+		Content: c.PostForm("content"),
+	}
+	if form, err := c.MultipartForm(); err == nil && form != nil {
+		input.Files = append([]*multipart.FileHeader{}, form.File["attachments"]...)
+	}
+	return input
+}
+
+// GetOrderMessageAttachment 通过签名链接读取订单消息附件
+func (h *Handler) GetOrderMessageAttachment(c *gin.Context) {
+	messageID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || messageID == 0 {
+		respondError(c, response.CodeBadRequest, "error.download_link_invalid", nil)
+		return
+	}
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil || index < 0 {
+		respondError(c, response.CodeBadRequest, "error.download_link_invalid", nil)
+		return
+	}
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		respondError(c, response.CodeBadRequest, "error.download_link_invalid", nil)
+		return
+	}
+
+	file, err := h.OrderMessageService.ResolveAttachment(service.OrderMessageAttachmentInput{
+		MessageID: uint(messageID),
+		Index:     index,
+		Expires:   expires,
+		Signature: c.Query("sig"),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrDownloadLinkInvalid):
+			respondError(c, response.CodeForbidden, "error.download_link_invalid", nil)
+		case errors.Is(err, service.ErrDownloadLinkExpired):
+			respondError(c, response.CodeForbidden, "error.download_link_expired", nil)
+		case errors.Is(err, service.ErrDownloadUnavailable):
+			respondError(c, response.CodeNotFound, "error.download_unavailable", nil)
+		default:
+			respondError(c, response.CodeInternal, "error.order_message_fetch_failed", err)
+		}
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.FileAttachment(file.Path, file.Name)
+}
+
+func respondOrderMessageError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrGuestEmailRequired):
+		respondError(c, response.CodeBadRequest, "error.guest_email_required", nil)
+	case errors.Is(err, service.ErrGuestPasswordRequired):
+		respondError(c, response.CodeBadRequest, "error.guest_password_required", nil)
+	case errors.Is(err, service.ErrOrderNotFound):
+		respondError(c, response.CodeNotFound, "error.order_not_found", nil)
+	case errors.Is(err, service.ErrGuestOrderNotFound):
+		respondError(c, response.CodeNotFound, "error.guest_order_not_found", nil)
+	case errors.Is(err, service.ErrOrderMessageNotAllowed):
+		respondError(c, response.CodeBadRequest, "error.order_message_not_allowed", nil)
+	case errors.Is(err, service.ErrOrderMessageClosed):
+		respondError(c, response.CodeBadRequest, "error.order_message_closed", nil)
+	case errors.Is(err, service.ErrOrderMessageInvalid):
+		respondError(c, response.CodeBadRequest, "error.order_message_invalid", nil)
+	case errors.Is(err, service.ErrOrderMessageAttachmentFailed):
+		respondError(c, response.CodeBadRequest, "error.order_message_attachment_failed", err)
+	case errors.Is(err, service.ErrOrderFetchFailed):
+		respondError(c, response.CodeInternal, "error.order_fetch_failed", err)
+	case errors.Is(err, service.ErrOrderMessageCreateFailed):
+		respondError(c, response.CodeInternal, "error.order_message_create_failed", err)
+	default:
+		respondError(c, response.CodeInternal, "error.order_message_fetch_failed", err)
+	}
+}
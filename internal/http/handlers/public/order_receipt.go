@@ -0,0 +1,105 @@
+package public
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/i18n"
+	"github.com/dujiao-next/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DownloadOrderReceipt 下载用户订单收据 PDF
+func (h *Handler) DownloadOrderReceipt(c *gin.Context) {
+	uid, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || orderID == 0 {
+		respondError(c, response.CodeBadRequest, "error.order_item_invalid", nil)
+		return
+	}
+	childOrderID, ok := parseReceiptChildOrderID(c)
+	if !ok {
+		return
+	}
+
+	receipt, err := h.ReceiptService.GetUserReceipt(uid, uint(orderID), childOrderID, i18n.ResolveLocale(c))
+	if err != nil {
+		respondReceiptError(c, err)
+		return
+	}
+	writeReceiptFile(c, receipt)
+}
+
+// DownloadGuestOrderReceipt 下载游客订单收据 PDF
+func (h *Handler) DownloadGuestOrderReceipt(c *gin.Context) {
+	email := strings.TrimSpace(c.Query("email"))
+	password := strings.TrimSpace(c.Query("order_password"))
+	if email == "" {
+		respondError(c, response.CodeBadRequest, "error.guest_email_required", nil)
+		return
+	}
+	if password == "" {
+		respondError(c, response.CodeBadRequest, "error.guest_password_required", nil)
+		return
+	}
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || orderID == 0 {
+		respondError(c, response.CodeBadRequest, "error.order_item_invalid", nil)
+		return
+	}
+	childOrderID, ok := parseReceiptChildOrderID(c)
+	if !ok {
+		return
+	}
+
+	receipt, err := h.ReceiptService.GetGuestReceipt(email, password, uint(orderID), childOrderID, i18n.ResolveLocale(c))
+	if err != nil {
+		respondReceiptError(c, err)
+		return
+	}
+	writeReceiptFile(c, receipt)
+}
+
+func parseReceiptChildOrderID(c *gin.Context) (uint, bool) {
+	raw := strings.TrimSpace(c.Query("child_order_id"))
+	if raw == "" {
+		return 0, true
+	}
+	value, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		respondError(c, response.CodeBadRequest, "error.order_item_invalid", nil)
+		return 0, false
+	}
+	return uint(value), true
+}
+
+func respondReceiptError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrOrderNotFound):
+		respondError(c, response.CodeNotFound, "error.order_not_found", nil)
+	case errors.Is(err, service.ErrGuestOrderNotFound):
+		respondError(c, response.CodeNotFound, "error.guest_order_not_found", nil)
+	case errors.Is(err, service.ErrReceiptDisabled):
+		respondError(c, response.CodeBadRequest, "error.receipt_disabled", nil)
+	case errors.Is(err, service.ErrReceiptNotAvailable):
+		respondError(c, response.CodeBadRequest, "error.receipt_not_available", nil)
+	case errors.Is(err, service.ErrReceiptFontMissing):
+		respondError(c, response.CodeBadRequest, "error.receipt_font_missing", nil)
+	case errors.Is(err, service.ErrOrderFetchFailed):
+		respondError(c, response.CodeInternal, "error.order_fetch_failed", err)
+	default:
+		respondError(c, response.CodeInternal, "error.receipt_generate_failed", err)
+	}
+}
+
+func writeReceiptFile(c *gin.Context, receipt *service.ReceiptFile) {
+	c.Header("Content-Type", receipt.ContentType)
+	c.Header("Content-Disposition", "attachment; filename=\""+receipt.Filename+"\"")
+	c.Data(200, receipt.ContentType, receipt.Content)
+}
@@ -0,0 +1,100 @@
+package public
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/i18n"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RevealOrderSecret 用户主动查看订单交付内容（记录查看凭证）
+func (h *Handler) RevealOrderSecret(c *gin.Context) {
+	uid, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || orderID == 0 {
+		respondError(c, response.CodeBadRequest, "error.order_item_invalid", nil)
+		return
+	}
+	childOrderID, ok := parseReceiptChildOrderID(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.OrderService.GetOrderByUser(uint(orderID), uid)
+	if err != nil {
+		if errors.Is(err, service.ErrOrderNotFound) {
+			respondError(c, response.CodeNotFound, "error.order_not_found", nil)
+			return
+		}
+		respondError(c, response.CodeInternal, "error.order_fetch_failed", err)
+		return
+	}
+	h.revealOrderSecret(c, order, childOrderID, uid)
+}
+
+// RevealGuestOrderSecret 游客主动查看订单交付内容（记录查看凭证）
+func (h *Handler) RevealGuestOrderSecret(c *gin.Context) {
+	email := strings.TrimSpace(c.Query("email"))
+	password := strings.TrimSpace(c.Query("order_password"))
+	if email == "" {
+		respondError(c, response.CodeBadRequest, "error.guest_email_required", nil)
+		return
+	}
+	if password == "" {
+		respondError(c, response.CodeBadRequest, "error.guest_password_required", nil)
+		return
+	}
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || orderID == 0 {
+		respondError(c, response.CodeBadRequest, "error.order_item_invalid", nil)
+		return
+	}
+	childOrderID, ok := parseReceiptChildOrderID(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.OrderService.GetOrderByGuest(uint(orderID), email, password)
+	if err != nil {
+		if errors.Is(err, service.ErrGuestOrderNotFound) {
+			respondError(c, response.CodeNotFound, "error.guest_order_not_found", nil)
+			return
+		}
+		respondError(c, response.CodeInternal, "error.order_fetch_failed", err)
+		return
+	}
+	h.revealOrderSecret(c, order, childOrderID, 0)
+}
+
+func (h *Handler) revealOrderSecret(c *gin.Context, order *models.Order, childOrderID, uid uint) {
+	target, err := h.SecretRevealService.Reveal(service.RevealInput{
+		Order:        order,
+		ChildOrderID: childOrderID,
+		UserID:       uid,
+		ClientIP:     c.ClientIP(),
+		UserAgent:    c.Request.UserAgent(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSecretRevealUnavailable):
+			respondError(c, response.CodeBadRequest, "error.secret_reveal_unavailable", nil)
+		case errors.Is(err, service.ErrSecretRevealLimitExceeded):
+			respondError(c, response.CodeForbidden, "error.secret_reveal_limit_exceeded", nil)
+		default:
+			respondError(c, response.CodeInternal, "error.secret_reveal_failed", err)
+		}
+		return
+	}
+	h.DeliveryRenderService.ApplyToOrder(target, i18n.ResolveLocale(c))
+	h.DownloadService.ApplyToOrder(target)
+	response.Success(c, target.Fulfillment)
+}
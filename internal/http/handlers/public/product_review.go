@@ -0,0 +1,166 @@
+package public
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PublicProductReview 公开评价响应
+type PublicProductReview struct {
+	ID           uint        `json:"id"`
+	Rating       int         `json:"rating"`
+	Content      string      `json:"content"`
+	SKUSnapshot  models.JSON `json:"sku_snapshot"`
+	ReviewerName string      `json:"reviewer_name"`
+	AdminReply   string      `json:"admin_reply,omitempty"`
+	RepliedAt    *time.Time  `json:"replied_at,omitempty"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+// CreateProductReviewRequest 提交评价请求
+type CreateProductReviewRequest struct {
+	OrderItemID uint   `json:"order_item_id" binding:"required"`
+	Rating      int    `json:"rating" binding:"required"`
+	Content     string `json:"content"`
+}
+
+// GetProductReviews 获取商品公开评价
+func (h *Handler) GetProductReviews(c *gin.Context) {
+	product, err := h.ProductService.GetPublicBySlug(c.Param("slug"))
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			respondError(c, response.CodeNotFound, "error.product_not_found", nil)
+			return
+		}
+		respondError(c, response.CodeInternal, "error.product_fetch_failed", err)
+		return
+	}
+	if err := service.CheckProductAccess(product, c.Query("access_code")); err != nil {
+		respondProductAccessError(c, err)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, pageSize = normalizePagination(page, pageSize)
+
+	reviews, total, err := h.ProductReviewService.ListPublic(product.ID, page, pageSize)
+	if err != nil {
+		respondError(c, response.CodeInternal, "error.product_review_fetch_failed", err)
+		return
+	}
+	items := make([]PublicProductReview, 0, len(reviews))
+	for _, review := range reviews {
+		items = append(items, PublicProductReview{
+			ID:           review.ID,
+			Rating:       review.Rating,
+			Content:      review.Content,
+			SKUSnapshot:  service.PublicSKUSnapshot(review.SKUSnapshot),
+			ReviewerName: maskReviewerName(review.User),
+			AdminReply:   review.AdminReply,
+			RepliedAt:    review.RepliedAt,
+			CreatedAt:    review.CreatedAt,
+		})
+	}
+
+	pagination := response.BuildPagination(page, pageSize, total)
+	response.SuccessWithPage(c, items, pagination)
+}
+
+// CreateProductReview 买家提交评价
+func (h *Handler) CreateProductReview(c *gin.Context) {
+	uid, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	var req CreateProductReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+
+	review, err := h.ProductReviewService.Create(service.CreateProductReviewInput{
+		UserID:      uid,
+		OrderItemID: req.OrderItemID,
+		Rating:      req.Rating,
+		Content:     req.Content,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrProductReviewInvalid):
+			respondError(c, response.CodeBadRequest, "error.product_review_invalid", nil)
+		case errors.Is(err, service.ErrProductReviewNotAllowed):
+			respondError(c, response.CodeForbidden, "error.product_review_not_allowed", nil)
+		case errors.Is(err, service.ErrProductReviewExists):
+			respondError(c, response.CodeBadRequest, "error.product_review_exists", nil)
+		default:
+			respondError(c, response.CodeInternal, "error.product_review_create_failed", err)
+		}
+		return
+	}
+	response.Success(c, review)
+}
+
+// ListMyProductReviews 获取我的评价
+func (h *Handler) ListMyProductReviews(c *gin.Context) {
+	uid, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, pageSize = normalizePagination(page, pageSize)
+
+	reviews, total, err := h.ProductReviewService.ListByUser(uid, page, pageSize)
+	if err != nil {
+		respondError(c, response.CodeInternal, "error.product_review_fetch_failed", err)
+		return
+	}
+	pagination := response.BuildPagination(page, pageSize, total)
+	response.SuccessWithPage(c, reviews, pagination)
+}
+
+// applyProductRatings 填充商品评分汇总
+func (h *Handler) applyProductRatings(items []PublicProductView) error {
+	if h.ProductReviewService == nil || len(items) == 0 {
+		return nil
+	}
+	productIDs := make([]uint, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ID)
+	}
+	summaries, err := h.ProductReviewService.Summaries(productIDs)
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		summary := summaries[items[i].ID]
+		items[i].RatingAverage = summary.RatingAverage
+		items[i].ReviewCount = summary.ReviewCount
+	}
+	return nil
+}
+
+// maskReviewerName 评价者昵称脱敏，仅保留首字符
+func maskReviewerName(user *models.User) string {
+	if user == nil {
+		return "***"
+	}
+	name := strings.TrimSpace(user.DisplayName)
+	if name == "" {
+		name = strings.TrimSpace(strings.SplitN(user.Email, "@", 2)[0])
+	}
+	runes := []rune(name)
+	if len(runes) == 0 {
+		return "***"
+	}
+	return string(runes[0]) + "***"
+}
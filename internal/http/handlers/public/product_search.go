@@ -0,0 +1,106 @@
+package public
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+// PublicProductSearchResult 商品搜索响应
+type PublicProductSearchResult struct {
+	Items      []PublicProductView         `json:"items"`
+	Facets     service.ProductSearchFacets `json:"facets"`
+	Pagination response.Pagination         `json:"pagination"`
+}
+
+// SearchProducts 商品全文搜索（支持分类、标签、价格区间、库存筛选与分面统计）
+func (h *Handler) SearchProducts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, pageSize = normalizePagination(page, pageSize)
+
+	input := service.ProductSearchInput{
+		Query:    strings.TrimSpace(c.Query("q")),
+		Sort:     strings.TrimSpace(c.Query("sort")),
+		Page:     page,
+		PageSize: pageSize,
+	}
+	if raw := strings.TrimSpace(c.Query("category_id")); raw != "" {
+		categoryID, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			respondError(c, response.CodeBadRequest, "error.bad_request", nil)
+			return
+		}
+		input.CategoryID = uint(categoryID)
+	}
+	for _, tag := range strings.Split(c.Query("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			input.Tags = append(input.Tags, tag)
+		}
+	}
+	var ok bool
+	if input.MinPrice, ok = parseSearchPrice(c.Query("min_price")); !ok {
+		respondError(c, response.CodeBadRequest, "error.bad_request", nil)
+		return
+	}
+	if input.MaxPrice, ok = parseSearchPrice(c.Query("max_price")); !ok {
+		respondError(c, response.CodeBadRequest, "error.bad_request", nil)
+		return
+	}
+	if raw := strings.TrimSpace(c.Query("in_stock")); raw != "" {
+		inStock, err := strconv.ParseBool(raw)
+		if err != nil {
+			respondError(c, response.CodeBadRequest, "error.bad_request", nil)
+			return
+		}
+		input.InStock = inStock
+	}
+
+	result, err := h.ProductSearchService.Search(input)
+	if err != nil {
+		respondError(c, response.CodeInternal, "error.product_fetch_failed", err)
+		return
+	}
+
+	var promotionService *service.PromotionService
+	if h.PromotionRepo != nil {
+		promotionService = service.NewPromotionService(h.PromotionRepo)
+	}
+	if err := h.ProductService.ApplyAutoStockCounts(result.Products); err != nil {
+		respondError(c, response.CodeInternal, "error.product_fetch_failed", err)
+		return
+	}
+	items := make([]PublicProductView, 0, len(result.Products))
+	for i := range result.Products {
+		item, derr := h.decoratePublicProduct(&result.Products[i], promotionService)
+		if derr != nil {
+			respondError(c, response.CodeInternal, "error.product_fetch_failed", derr)
+			return
+		}
+		items = append(items, item)
+	}
+
+	response.Success(c, PublicProductSearchResult{
+		Items:      items,
+		Facets:     result.Facets,
+		Pagination: response.BuildPagination(page, pageSize, result.Total),
+	})
+}
+
+// parseSearchPrice 解析价格筛选参数，空值返回 nil
+func parseSearchPrice(raw string) (*decimal.Decimal, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, true
+	}
+	value, err := decimal.NewFromString(raw)
+	if err != nil || value.IsNegative() {
+		return nil, false
+	}
+	return &value, true
+}
@@ -0,0 +1,101 @@
+package public
+
+import (
+	"strings"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/logger"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/service"
+)
+
+// PublicSpecMatrix 商品规格矩阵（规格定义 + 各组合的价格与库存）
+type PublicSpecMatrix struct {
+	Specs        []service.ProductSpecDefinition `json:"specs"`
+	Combinations []PublicSpecCombination         `json:"combinations"`
+}
+
+// PublicSpecCombination 规格组合（对应一个启用中的 SKU）
+type PublicSpecCombination struct {
+	SKUID          uint              `json:"sku_id"`
+	SKUCode        string            `json:"sku_code"`
+	Values         map[string]string `json:"values"`
+	PriceAmount    models.Money      `json:"price_amount"`
+	PriceTiers     models.PriceTiers `json:"price_tiers,omitempty"`
+	StockAvailable int64             `json:"stock_available"` // -1 表示无限库存
+	StockStatus    string            `json:"stock_status"`
+	IsSoldOut      bool              `json:"is_sold_out"`
+}
+
+// buildPublicSpecMatrix 构建规格矩阵，未配置规格定义时返回 nil
+func buildPublicSpecMatrix(product *models.Product) *PublicSpecMatrix {
+	if product == nil {
+		return nil
+	}
+	specs, err := service.ParseProductSpecDefinitions(product.SpecSchemaJSON)
+	if err != nil {
+		logger.Warnw("public_product_spec_schema_invalid", "product_id", product.ID, "error", err)
+		return nil
+	}
+	if len(specs) == 0 {
+		return nil
+	}
+
+	matrix := &PublicSpecMatrix{
+		Specs:        specs,
+		Combinations: make([]PublicSpecCombination, 0, len(product.SKUs)),
+	}
+	for _, sku := range product.SKUs {
+		if !sku.IsActive {
+			continue
+		}
+		values := make(map[string]string, len(specs))
+		for _, spec := range specs {
+			if value, ok := sku.SpecValuesJSON[spec.Key].(string); ok {
+				values[spec.Key] = value
+			}
+		}
+		if len(values) != len(specs) {
+			continue
+		}
+		available := resolvePublicSKUStock(product, sku)
+		combination := PublicSpecCombination{
+			SKUID:          sku.ID,
+			SKUCode:        sku.SKUCode,
+			Values:         values,
+			PriceAmount:    sku.PriceAmount,
+			PriceTiers:     sku.PriceTiers,
+			StockAvailable: available,
+		}
+		switch {
+		case available == constants.ManualStockUnlimited:
+			combination.StockStatus = constants.ProductStockStatusUnlimited
+		case available <= 0:
+			combination.StockStatus = constants.ProductStockStatusOutOfStock
+			combination.IsSoldOut = true
+		case available <= publicLowStockLimit:
+			combination.StockStatus = constants.ProductStockStatusLowStock
+		default:
+			combination.StockStatus = constants.ProductStockStatusInStock
+		}
+		matrix.Combinations = append(matrix.Combinations, combination)
+	}
+	return matrix
+}
+
+// resolvePublicSKUStock 单个 SKU 的可售数量，-1 表示无限库存
+func resolvePublicSKUStock(product *models.Product, sku models.ProductSKU) int64 {
+	fulfillmentType := strings.TrimSpace(product.FulfillmentType)
+	switch {
+	case fulfillmentType == constants.FulfillmentTypeAPI || fulfillmentType == constants.FulfillmentTypeFile || service.HasKeyGenerator(product):
+		return constants.ManualStockUnlimited
+	case fulfillmentType == constants.FulfillmentTypeAuto:
+		return sku.AutoStockAvailable
+	case sku.ManualStockTotal == constants.ManualStockUnlimited:
+		return constants.ManualStockUnlimited
+	case sku.ManualStockTotal < 0:
+		return 0
+	default:
+		return int64(sku.ManualStockTotal)
+	}
+}
@@ -2,6 +2,7 @@ package public
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -18,22 +19,32 @@ import (
 )
 
 const (
-	publicConfigCacheKey = "public:config"
-	publicConfigCacheTTL = 60 * time.Second
-	publicLowStockLimit  = 5
+	publicConfigCacheKey  = "public"
+	publicCatalogCacheTTL = 60 * time.Second
+	publicLowStockLimit   = 5
 )
 
 // PublicProductView 公共商品响应结构
 type PublicProductView struct {
 	models.Product
-	PromotionID          *uint         `json:"promotion_id,omitempty"`
-	PromotionName        string        `json:"promotion_name,omitempty"`
-	PromotionType        string        `json:"promotion_type,omitempty"`
-	PromotionPriceAmount *models.Money `json:"promotion_price_amount,omitempty"`
-	ManualStockAvailable int           `json:"manual_stock_available"`
-	AutoStockAvailable   int64         `json:"auto_stock_available"`
-	StockStatus          string        `json:"stock_status"`
-	IsSoldOut            bool          `json:"is_sold_out"`
+	PromotionID          *uint                  `json:"promotion_id,omitempty"`
+	PromotionName        string                 `json:"promotion_name,omitempty"`
+	PromotionType        string                 `json:"promotion_type,omitempty"`
+	PromotionPriceAmount *models.Money          `json:"promotion_price_amount,omitempty"`
+	ManualStockAvailable int                    `json:"manual_stock_available"`
+	AutoStockAvailable   int64                  `json:"auto_stock_available"`
+	StockStatus          string                 `json:"stock_status"`
+	IsSoldOut            bool                   `json:"is_sold_out"`
+	SpecMatrix           *PublicSpecMatrix      `json:"spec_matrix,omitempty"`
+	RatingAverage        float64                `json:"rating_average"`
+	ReviewCount          int64                  `json:"review_count"`
+	StructuredData       map[string]interface{} `json:"structured_data,omitempty"` // 详情页 JSON-LD（schema.org Product）
+}
+
+// publicProductListCache 商品列表缓存结构
+type publicProductListCache struct {
+	Items      []PublicProductView `json:"items"`
+	Pagination response.Pagination `json:"pagination"`
 }
 
 // GetConfig 获取全局配置
@@ -50,7 +61,7 @@ func (h *Handler) GetConfig(c *gin.Context) {
 	}
 
 	var cached map[string]interface{}
-	if hit, err := cache.GetJSON(c.Request.Context(), publicConfigCacheKey, &cached); err == nil && hit {
+	if hit, err := cache.GetCatalogJSON(c.Request.Context(), cache.CatalogScopeConfig, publicConfigCacheKey, &cached); err == nil && hit {
 		response.Success(c, cached)
 		return
 	}
@@ -110,7 +121,7 @@ func (h *Handler) GetConfig(c *gin.Context) {
 	}
 	data["affiliate"] = service.AffiliateSettingToMap(affiliateSetting)
 
-	_ = cache.SetJSON(c.Request.Context(), publicConfigCacheKey, data, publicConfigCacheTTL)
+	_ = cache.SetCatalogJSON(c.Request.Context(), cache.CatalogScopeConfig, publicConfigCacheKey, data, publicCatalogCacheTTL)
 	response.Success(c, data)
 }
 
@@ -125,6 +136,14 @@ func (h *Handler) GetProducts(c *gin.Context) {
 	categoryID := c.Query("category_id")
 	search := strings.TrimSpace(c.Query("search"))
 
+	now := time.Now()
+	cacheKey := fmt.Sprintf("list:%s:%d:%d:%s", strings.TrimSpace(categoryID), page, pageSize, search)
+	var cached publicProductListCache
+	if hit, err := cache.GetCatalogJSON(c.Request.Context(), cache.CatalogScopeProducts, cacheKey, &cached); err == nil && hit {
+		response.SuccessWithPage(c, cached.Items, cached.Pagination)
+		return
+	}
+
 	products, total, err := h.ProductService.ListPublic(categoryID, search, page, pageSize)
 	if err != nil {
 		respondError(c, response.CodeInternal, "error.product_fetch_failed", err)
@@ -150,15 +169,31 @@ func (h *Handler) GetProducts(c *gin.Context) {
 		}
 		decorated = append(decorated, item)
 	}
+	if err := h.applyProductRatings(decorated); err != nil {
+		respondError(c, response.CodeInternal, "error.product_fetch_failed", err)
+		return
+	}
 
 	// 统一响应格式
 	pagination := response.BuildPagination(page, pageSize, total)
+	if ttl := h.productCatalogCacheTTL(now); ttl > 0 {
+		_ = cache.SetCatalogJSON(c.Request.Context(), cache.CatalogScopeProducts, cacheKey, publicProductListCache{Items: decorated, Pagination: pagination}, ttl)
+	}
 	response.SuccessWithPage(c, decorated, pagination)
 }
 
 // GetProductBySlug 根据 slug 获取商品详情
 func (h *Handler) GetProductBySlug(c *gin.Context) {
 	slug := c.Param("slug")
+	locale := i18n.ResolveLocale(c)
+	now := time.Now()
+	cacheKey := "slug:" + strings.TrimSpace(slug) + ":" + locale
+	var cached PublicProductView
+	if hit, err := cache.GetCatalogJSON(c.Request.Context(), cache.CatalogScopeProducts, cacheKey, &cached); err == nil && hit {
+		h.attachProductStructuredData(c, &cached, locale)
+		response.Success(c, cached)
+		return
+	}
 
 	product, err := h.ProductService.GetPublicBySlug(slug)
 	if err != nil {
@@ -169,6 +204,11 @@ func (h *Handler) GetProductBySlug(c *gin.Context) {
 		respondError(c, response.CodeInternal, "error.product_fetch_failed", err)
 		return
 	}
+	// 访问码保护商品需校验访问码，且不进入目录缓存
+	if err := service.CheckProductAccess(product, c.Query("access_code")); err != nil {
+		respondProductAccessError(c, err)
+		return
+	}
 
 	var promotionService *service.PromotionService
 	if h.PromotionRepo != nil {
@@ -187,17 +227,48 @@ func (h *Handler) GetProductBySlug(c *gin.Context) {
 		respondError(c, response.CodeInternal, "error.product_fetch_failed", derr)
 		return
 	}
+	decorated.SpecMatrix = buildPublicSpecMatrix(product)
+	rated := []PublicProductView{decorated}
+	if err := h.applyProductRatings(rated); err != nil {
+		respondError(c, response.CodeInternal, "error.product_fetch_failed", err)
+		return
+	}
+	decorated = rated[0]
 
+	// 缓存内容不含 JSON-LD：站点地址可能取自请求头，只在响应前按请求附加
+	if product.Visibility != constants.ProductVisibilityProtected {
+		if ttl := h.productCatalogCacheTTL(now); ttl > 0 {
+			_ = cache.SetCatalogJSON(c.Request.Context(), cache.CatalogScopeProducts, cacheKey, decorated, ttl)
+		}
+	}
+	h.attachProductStructuredData(c, &decorated, locale)
 	response.Success(c, decorated)
 }
 
+// respondProductAccessError 访问码缺失或错误时返回 403
+func respondProductAccessError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrProductAccessCodeRequired):
+		respondError(c, response.CodeForbidden, "error.product_access_code_required", nil)
+	case errors.Is(err, service.ErrProductAccessCodeInvalid):
+		respondError(c, response.CodeForbidden, "error.product_access_code_invalid", nil)
+	default:
+		respondError(c, response.CodeInternal, "error.product_fetch_failed", err)
+	}
+}
+
 func (h *Handler) decoratePublicProduct(product *models.Product, promotionService *service.PromotionService) (PublicProductView, error) {
 	if product == nil {
 		return PublicProductView{}, nil
 	}
 
 	item := PublicProductView{Product: *product}
-	displayPrice := resolvePublicDisplayPrice(product)
+	// 上游供应商、卡密生成器配置与访问码仅后台可见
+	item.Product.SupplierID = nil
+	item.Product.SupplierProductCode = ""
+	item.Product.KeyGeneratorJSON = nil
+	item.Product.AccessCode = ""
+	displayPrice := resolvePublicDisplayPrice(product, time.Now())
 	item.Product.PriceAmount = displayPrice
 	h.decorateProductStock(product, &item)
 	if promotionService == nil {
@@ -229,19 +300,34 @@ func (h *Handler) decoratePublicProduct(product *models.Product, promotionServic
 	return item, nil
 }
 
-func resolvePublicDisplayPrice(product *models.Product) models.Money {
+func resolvePublicDisplayPrice(product *models.Product, now time.Time) models.Money {
 	if product == nil {
 		return models.Money{}
 	}
-	for _, sku := range product.SKUs {
-		if !sku.IsActive {
+	for i := range product.SKUs {
+		if !product.SKUs[i].IsPublishedAt(now) {
 			continue
 		}
-		return sku.PriceAmount
+		return product.SKUs[i].PriceAmount
 	}
 	return product.PriceAmount
 }
 
+// productCatalogCacheTTL 商品目录缓存有效期不跨越下一个定时上下架时间点；查询失败时不缓存
+func (h *Handler) productCatalogCacheTTL(now time.Time) time.Duration {
+	if h.ProductService == nil {
+		return publicCatalogCacheTTL
+	}
+	boundary, err := h.ProductService.NextPublishBoundary(now)
+	if err != nil {
+		return 0
+	}
+	if boundary != nil && boundary.Sub(now) < publicCatalogCacheTTL {
+		return boundary.Sub(now)
+	}
+	return publicCatalogCacheTTL
+}
+
 func (h *Handler) decorateProductStock(product *models.Product, item *PublicProductView) {
 	if product == nil || item == nil {
 		return
@@ -263,6 +349,12 @@ func (h *Handler) decorateProductStock(product *models.Product, item *PublicProd
 		fulfillmentType = constants.FulfillmentTypeManual
 	}
 
+	if fulfillmentType == constants.FulfillmentTypeAPI || fulfillmentType == constants.FulfillmentTypeFile || service.HasKeyGenerator(product) {
+		item.ManualStockAvailable = constants.ManualStockUnlimited
+		item.StockStatus = constants.ProductStockStatusUnlimited
+		return
+	}
+
 	if fulfillmentType == constants.FulfillmentTypeManual {
 		hasActiveSKU := false
 		hasUnlimitedSKU := false
@@ -382,15 +474,37 @@ func (h *Handler) GetPostBySlug(c *gin.Context) {
 
 // GetCategories 获取分类列表
 func (h *Handler) GetCategories(c *gin.Context) {
+	var cached []models.Category
+	if hit, err := cache.GetCatalogJSON(c.Request.Context(), cache.CatalogScopeCategories, "list", &cached); err == nil && hit {
+		response.Success(c, cached)
+		return
+	}
 	categories, err := h.CategoryService.List()
 	if err != nil {
 		respondError(c, response.CodeInternal, "error.category_fetch_failed", err)
 		return
 	}
 
+	_ = cache.SetCatalogJSON(c.Request.Context(), cache.CatalogScopeCategories, "list", categories, publicCatalogCacheTTL)
 	response.Success(c, categories)
 }
 
+// GetCategoryTree 获取分类树
+func (h *Handler) GetCategoryTree(c *gin.Context) {
+	var cached []models.Category
+	if hit, err := cache.GetCatalogJSON(c.Request.Context(), cache.CatalogScopeCategories, "tree", &cached); err == nil && hit {
+		response.Success(c, cached)
+		return
+	}
+	tree, err := h.CategoryService.Tree()
+	if err != nil {
+		respondError(c, response.CodeInternal, "error.category_fetch_failed", err)
+		return
+	}
+	_ = cache.SetCatalogJSON(c.Request.Context(), cache.CatalogScopeCategories, "tree", tree, publicCatalogCacheTTL)
+	response.Success(c, tree)
+}
+
 // CreateGuestOrderRequest 游客下单请求
 type CreateGuestOrderRequest struct {
 	Email               string                 `json:"email" binding:"required"`
@@ -435,6 +549,7 @@ func (h *Handler) CreateGuestOrder(c *gin.Context) {
 			SKUID:           item.SKUID,
 			Quantity:        item.Quantity,
 			FulfillmentType: item.FulfillmentType,
+			AccessCode:      item.AccessCode,
 		})
 	}
 	order, err := h.OrderService.CreateGuestOrder(service.CreateGuestOrderInput{
@@ -469,6 +584,7 @@ func (h *Handler) PreviewGuestOrder(c *gin.Context) {
 			SKUID:           item.SKUID,
 			Quantity:        item.Quantity,
 			FulfillmentType: item.FulfillmentType,
+			AccessCode:      item.AccessCode,
 		})
 	}
 	preview, err := h.OrderService.PreviewGuestOrder(service.CreateGuestOrderInput{
@@ -538,6 +654,9 @@ func (h *Handler) ListGuestOrders(c *gin.Context) {
 		respondError(c, response.CodeInternal, "error.order_fetch_failed", err)
 		return
 	}
+	for i := range orders {
+		h.SecretRevealService.ApplyToOrder(&orders[i])
+	}
 	pagination := response.BuildPagination(page, pageSize, total)
 	response.SuccessWithPage(c, orders, pagination)
 }
@@ -568,6 +687,9 @@ func (h *Handler) GetGuestOrder(c *gin.Context) {
 		respondError(c, response.CodeInternal, "error.order_fetch_failed", err)
 		return
 	}
+	h.DeliveryRenderService.ApplyToOrder(order, i18n.ResolveLocale(c))
+	h.DownloadService.ApplyToOrder(order)
+	h.SecretRevealService.ApplyToOrder(order)
 	response.Success(c, order)
 }
 
@@ -597,6 +719,9 @@ func (h *Handler) GetGuestOrderByOrderNo(c *gin.Context) {
 		respondError(c, response.CodeInternal, "error.order_fetch_failed", err)
 		return
 	}
+	h.DeliveryRenderService.ApplyToOrder(order, i18n.ResolveLocale(c))
+	h.DownloadService.ApplyToOrder(order)
+	h.SecretRevealService.ApplyToOrder(order)
 	response.Success(c, order)
 }
 
@@ -7,6 +7,7 @@ import (
 
 	"github.com/dujiao-next/internal/constants"
 	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/provider"
 	"github.com/dujiao-next/internal/repository"
 	"github.com/dujiao-next/internal/service"
 
@@ -107,3 +108,72 @@ func TestDecoratePublicProductPromotionUsesDisplayPrice(t *testing.T) {
 		t.Fatalf("expected promotion display price %s, got: %s", expectedPromotion.String(), item.PromotionPriceAmount.String())
 	}
 }
+
+func TestDecoratePublicProductDisplayPriceSkipsScheduledSKU(t *testing.T) {
+	h := &Handler{}
+	publishAt := time.Now().Add(time.Hour)
+	product := &models.Product{
+		ID:          1,
+		PriceAmount: models.NewMoneyFromDecimal(decimal.RequireFromString("59.90")),
+		SKUs: []models.ProductSKU{
+			{ID: 11, IsActive: true, PublishAt: &publishAt, PriceAmount: models.NewMoneyFromDecimal(decimal.RequireFromString("89.90"))},
+			{ID: 12, IsActive: true, PriceAmount: models.NewMoneyFromDecimal(decimal.RequireFromString("49.90"))},
+		},
+	}
+
+	item, err := h.decoratePublicProduct(product, nil)
+	if err != nil {
+		t.Fatalf("decoratePublicProduct failed: %v", err)
+	}
+	expected := decimal.RequireFromString("49.90")
+	if !item.PriceAmount.Decimal.Equal(expected) {
+		t.Fatalf("expected display price %s, got: %s", expected.String(), item.PriceAmount.String())
+	}
+}
+
+func TestProductCatalogCacheTTLStopsAtPublishBoundary(t *testing.T) {
+	dsn := fmt.Sprintf("file:public_catalog_ttl_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Product{}, &models.ProductSKU{}); err != nil {
+		t.Fatalf("auto migrate failed: %v", err)
+	}
+	h := &Handler{Container: &provider.Container{
+		ProductService: service.NewProductService(repository.NewProductRepository(db), repository.NewProductSKURepository(db), nil, nil),
+	}}
+
+	now := time.Now()
+	if ttl := h.productCatalogCacheTTL(now); ttl != publicCatalogCacheTTL {
+		t.Fatalf("expected default ttl without schedules, got %s", ttl)
+	}
+
+	product := models.Product{Slug: "scheduled", TitleJSON: models.JSON{"zh-CN": "定时商品"}, IsActive: true}
+	if err := db.Create(&product).Error; err != nil {
+		t.Fatalf("create product failed: %v", err)
+	}
+	unpublishAt := now.Add(20 * time.Second)
+	publishAt := now.Add(10 * time.Second)
+	if err := db.Create(&models.ProductSKU{ProductID: product.ID, SKUCode: "A", IsActive: true, UnpublishAt: &unpublishAt}).Error; err != nil {
+		t.Fatalf("create sku failed: %v", err)
+	}
+	inactive := models.ProductSKU{ProductID: product.ID, SKUCode: "B", PublishAt: &publishAt}
+	if err := db.Create(&inactive).Error; err != nil {
+		t.Fatalf("create sku failed: %v", err)
+	}
+	// 停用 SKU 的定时时间点不影响前台可见性
+	if err := db.Model(&inactive).Update("is_active", false).Error; err != nil {
+		t.Fatalf("deactivate sku failed: %v", err)
+	}
+	if ttl := h.productCatalogCacheTTL(now); ttl != 20*time.Second {
+		t.Fatalf("expected ttl capped at unpublish boundary, got %s", ttl)
+	}
+
+	if err := db.Model(&models.Product{}).Where("id = ?", product.ID).Update("publish_at", publishAt).Error; err != nil {
+		t.Fatalf("schedule product failed: %v", err)
+	}
+	if ttl := h.productCatalogCacheTTL(now); ttl != 10*time.Second {
+		t.Fatalf("expected ttl capped at publish boundary, got %s", ttl)
+	}
+}
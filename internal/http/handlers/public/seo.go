@@ -0,0 +1,187 @@
+package public
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/i18n"
+	"github.com/dujiao-next/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const xmlContentType = "application/xml; charset=utf-8"
+
+// GetSitemap 站点地图，URL 过多时返回 sitemap 索引
+func (h *Handler) GetSitemap(c *gin.Context) {
+	body, err := h.SEOService.BuildSitemap(h.resolveSiteURL(c))
+	if err != nil {
+		respondError(c, response.CodeInternal, "error.seo_fetch_failed", err)
+		return
+	}
+	c.Data(http.StatusOK, xmlContentType, body)
+}
+
+// GetSitemapFile 分段站点地图
+func (h *Handler) GetSitemapFile(c *gin.Context) {
+	body, err := h.SEOService.BuildSitemapFile(h.resolveSiteURL(c), c.Param("file"))
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			respondError(c, response.CodeNotFound, "error.sitemap_not_found", nil)
+			return
+		}
+		respondError(c, response.CodeInternal, "error.seo_fetch_failed", err)
+		return
+	}
+	c.Data(http.StatusOK, xmlContentType, body)
+}
+
+// GetRSS 文章 RSS 订阅源，可通过 type 参数仅输出 blog 或 notice
+func (h *Handler) GetRSS(c *gin.Context) {
+	body, err := h.SEOService.BuildRSS(h.resolveSiteURL(c), i18n.ResolveLocale(c), c.Query("type"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPostType) {
+			respondError(c, response.CodeBadRequest, "error.post_type_invalid", nil)
+			return
+		}
+		respondError(c, response.CodeInternal, "error.seo_fetch_failed", err)
+		return
+	}
+	c.Data(http.StatusOK, "application/rss+xml; charset=utf-8", body)
+}
+
+// resolveSiteURL 优先使用 seo.site_url，未配置时按请求协议与 Host 推断
+func (h *Handler) resolveSiteURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil || strings.EqualFold(strings.TrimSpace(c.GetHeader("X-Forwarded-Proto")), "https") {
+		scheme = "https"
+	}
+	host := strings.TrimSpace(c.GetHeader("X-Forwarded-Host"))
+	if host == "" {
+		host = c.Request.Host
+	}
+	fallback := scheme + "://" + host
+	if h.SEOService == nil {
+		return fallback
+	}
+	return h.SEOService.ResolveSiteURL(fallback)
+}
+
+// attachProductStructuredData 为商品详情附加 JSON-LD；结果依赖请求推断的站点地址，不得写入目录缓存
+func (h *Handler) attachProductStructuredData(c *gin.Context, item *PublicProductView, locale string) {
+	item.StructuredData = buildProductStructuredData(item, h.resolveSiteURL(c), locale, h.resolvePublicCurrency())
+}
+
+func (h *Handler) resolvePublicCurrency() string {
+	if h.SettingService == nil {
+		return constants.SiteCurrencyDefault
+	}
+	currency, err := h.SettingService.GetSiteCurrency(constants.SiteCurrencyDefault)
+	if err != nil || strings.TrimSpace(currency) == "" {
+		return constants.SiteCurrencyDefault
+	}
+	return currency
+}
+
+// buildProductStructuredData 基于价格、库存与评分生成 schema.org Product/Offer JSON-LD
+func buildProductStructuredData(item *PublicProductView, siteURL, locale, currency string) map[string]interface{} {
+	productURL := siteURL + "/products/" + url.PathEscape(item.Slug)
+	name := service.ResolveLocalizedText(item.TitleJSON, locale)
+	if name == "" {
+		name = item.Slug
+	}
+	data := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "Product",
+		"name":     name,
+		"url":      productURL,
+	}
+	if description := resolveSeoDescription(item.SeoMetaJSON, locale); description != "" {
+		data["description"] = description
+	}
+	if len(item.Images) > 0 {
+		images := make([]string, 0, len(item.Images))
+		for _, image := range item.Images {
+			image = strings.TrimSpace(image)
+			if image == "" {
+				continue
+			}
+			if strings.HasPrefix(image, "/") {
+				image = siteURL + image
+			}
+			images = append(images, image)
+		}
+		if len(images) > 0 {
+			data["image"] = images
+		}
+	}
+
+	availability := "https://schema.org/InStock"
+	if item.IsSoldOut {
+		availability = "https://schema.org/OutOfStock"
+	}
+	activeSKUs := 0
+	lowPrice, highPrice := item.PriceAmount, item.PriceAmount
+	for _, sku := range item.SKUs {
+		if !sku.IsActive {
+			continue
+		}
+		if activeSKUs == 0 || sku.PriceAmount.LessThan(lowPrice.Decimal) {
+			lowPrice = sku.PriceAmount
+		}
+		if activeSKUs == 0 || sku.PriceAmount.GreaterThan(highPrice.Decimal) {
+			highPrice = sku.PriceAmount
+		}
+		activeSKUs++
+	}
+	if activeSKUs > 1 && !lowPrice.Equal(highPrice.Decimal) {
+		data["offers"] = map[string]interface{}{
+			"@type":         "AggregateOffer",
+			"lowPrice":      lowPrice.String(),
+			"highPrice":     highPrice.String(),
+			"offerCount":    activeSKUs,
+			"priceCurrency": currency,
+			"availability":  availability,
+			"url":           productURL,
+		}
+	} else {
+		price := item.PriceAmount
+		if item.PromotionPriceAmount != nil {
+			price = *item.PromotionPriceAmount
+		}
+		data["offers"] = map[string]interface{}{
+			"@type":         "Offer",
+			"price":         price.String(),
+			"priceCurrency": currency,
+			"availability":  availability,
+			"url":           productURL,
+		}
+	}
+
+	if item.ReviewCount > 0 {
+		data["aggregateRating"] = map[string]interface{}{
+			"@type":       "AggregateRating",
+			"ratingValue": item.RatingAverage,
+			"reviewCount": item.ReviewCount,
+		}
+	}
+	return data
+}
+
+// resolveSeoDescription 读取 seo_meta.description，兼容多语言对象与纯文本
+func resolveSeoDescription(seoMeta map[string]interface{}, locale string) string {
+	if seoMeta == nil {
+		return ""
+	}
+	switch value := seoMeta["description"].(type) {
+	case string:
+		return strings.TrimSpace(value)
+	case map[string]interface{}:
+		return service.ResolveLocalizedText(value, locale)
+	}
+	return ""
+}
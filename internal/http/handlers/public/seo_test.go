@@ -0,0 +1,62 @@
+package public
+
+import (
+	"testing"
+
+	"github.com/dujiao-next/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestBuildProductStructuredData(t *testing.T) {
+	promotionPrice := models.NewMoneyFromDecimal(decimal.RequireFromString("8.50"))
+	item := &PublicProductView{
+		Product: models.Product{
+			Slug:        "gift-card",
+			TitleJSON:   models.JSON{"zh-CN": "礼品卡", "en-US": "Gift Card"},
+			SeoMetaJSON: models.JSON{"description": map[string]interface{}{"en-US": "Digital gift card"}},
+			Images:      models.StringArray{"/uploads/a.png", "https://cdn.example.com/b.png"},
+			PriceAmount: models.NewMoneyFromDecimal(decimal.RequireFromString("10")),
+		},
+		PromotionPriceAmount: &promotionPrice,
+		RatingAverage:        4.5,
+		ReviewCount:          2,
+	}
+
+	data := buildProductStructuredData(item, "https://shop.example.com", "en-US", "USD")
+	if data["name"] != "Gift Card" || data["description"] != "Digital gift card" {
+		t.Fatalf("unexpected localized fields: %v", data)
+	}
+	images, _ := data["image"].([]string)
+	if len(images) != 2 || images[0] != "https://shop.example.com/uploads/a.png" {
+		t.Fatalf("unexpected images: %v", data["image"])
+	}
+	offer, _ := data["offers"].(map[string]interface{})
+	if offer["@type"] != "Offer" || offer["price"] != "8.50" || offer["priceCurrency"] != "USD" || offer["availability"] != "https://schema.org/InStock" {
+		t.Fatalf("unexpected offer: %v", offer)
+	}
+	rating, _ := data["aggregateRating"].(map[string]interface{})
+	if rating["reviewCount"] != int64(2) {
+		t.Fatalf("unexpected rating: %v", rating)
+	}
+
+	item.PromotionPriceAmount = nil
+	item.IsSoldOut = true
+	item.ReviewCount = 0
+	item.SKUs = []models.ProductSKU{
+		{IsActive: true, PriceAmount: models.NewMoneyFromDecimal(decimal.RequireFromString("12"))},
+		{IsActive: true, PriceAmount: models.NewMoneyFromDecimal(decimal.RequireFromString("5"))},
+		{IsActive: false, PriceAmount: models.NewMoneyFromDecimal(decimal.RequireFromString("1"))},
+	}
+	data = buildProductStructuredData(item, "https://shop.example.com", "zh-CN", "CNY")
+	offer, _ = data["offers"].(map[string]interface{})
+	if offer["@type"] != "AggregateOffer" || offer["lowPrice"] != "5.00" || offer["highPrice"] != "12.00" || offer["offerCount"] != 2 {
+		t.Fatalf("unexpected aggregate offer: %v", offer)
+	}
+	if offer["availability"] != "https://schema.org/OutOfStock" {
+		t.Fatalf("expected sold out availability, got %v", offer["availability"])
+	}
+	if _, ok := data["aggregateRating"]; ok {
+		t.Fatalf("rating should be omitted without reviews")
+	}
+}
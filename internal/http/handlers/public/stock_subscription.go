@@ -0,0 +1,168 @@
+package public
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dujiao-next/internal/http/response"
+	"github.com/dujiao-next/internal/i18n"
+	"github.com/dujiao-next/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// StockSubscriptionRequest 到货通知订阅请求
+type StockSubscriptionRequest struct {
+	ProductID  uint   `json:"product_id" binding:"required"`
+	SKUID      uint   `json:"sku_id" binding:"required"`
+	Channel    string `json:"channel" binding:"required"`
+	Target     string `json:"target"` // 邮箱或 Telegram chat id，登录用户可留空使用账号邮箱/绑定的 Telegram
+	AccessCode string `json:"access_code"`
+}
+
+// CreateStockSubscription 用户订阅到货通知
+func (h *Handler) CreateStockSubscription(c *gin.Context) {
+	uid, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	h.createStockSubscription(c, uid)
+}
+
+// CreateGuestStockSubscription 游客订阅到货通知
+func (h *Handler) CreateGuestStockSubscription(c *gin.Context) {
+	h.createStockSubscription(c, 0)
+}
+
+// StockUnsubscribeRequest 退订请求（表单或 JSON）
+type StockUnsubscribeRequest struct {
+	Token string `form:"token" json:"token" binding:"required"`
+}
+
+// stockUnsubscribePage 退订确认/结果页，通知中的链接只打开确认页，提交表单后才退订
+var stockUnsubscribePage = template.Must(template.New("stock_unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="{{.Locale}}">
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><meta name="robots" content="noindex"><title>{{.Title}}</title></head>
+<body style="font-family:sans-serif;max-width:480px;margin:64px auto;padding:0 16px;text-align:center">
+<h1 style="font-size:20px">{{.Title}}</h1>
+<p>{{.Message}}</p>
+{{if .Token}}<form method="post" action="{{.Action}}">
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">{{.Button}}</button>
+</form>{{end}}
+</body>
+</html>`))
+
+type stockUnsubscribePageData struct {
+	Locale  string
+	Title   string
+	Message string
+	Button  string
+	Action  string
+	Token   string
+}
+
+// ConfirmStockUnsubscribe 退订确认页（GET 不修改订阅状态）
+func (h *Handler) ConfirmStockUnsubscribe(c *gin.Context) {
+	locale := i18n.ResolveLocale(c)
+	token := strings.TrimSpace(c.Query("token"))
+	if _, err := h.StockSubscriptionService.GetByToken(token); err != nil {
+		if errors.Is(err, service.ErrStockSubscriptionNotFound) {
+			renderStockUnsubscribePage(c, http.StatusNotFound, locale, "page.stock_unsubscribe.invalid", "")
+			return
+		}
+		renderStockUnsubscribePage(c, http.StatusInternalServerError, locale, "page.stock_unsubscribe.failed", "")
+		return
+	}
+	renderStockUnsubscribePage(c, http.StatusOK, locale, "page.stock_unsubscribe.confirm", token)
+}
+
+// UnsubscribeStockSubscription 退订到货通知；确认页表单提交时返回结果页，其余请求返回 JSON
+func (h *Handler) UnsubscribeStockSubscription(c *gin.Context) {
+	locale := i18n.ResolveLocale(c)
+	fromPage := c.ContentType() == binding.MIMEPOSTForm
+	var req StockUnsubscribeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		if fromPage {
+			renderStockUnsubscribePage(c, http.StatusBadRequest, locale, "page.stock_unsubscribe.invalid", "")
+			return
+		}
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+	if err := h.StockSubscriptionService.Unsubscribe(req.Token); err != nil {
+		notFound := errors.Is(err, service.ErrStockSubscriptionNotFound)
+		switch {
+		case fromPage && notFound:
+			renderStockUnsubscribePage(c, http.StatusNotFound, locale, "page.stock_unsubscribe.invalid", "")
+		case fromPage:
+			renderStockUnsubscribePage(c, http.StatusInternalServerError, locale, "page.stock_unsubscribe.failed", "")
+		case notFound:
+			respondError(c, response.CodeNotFound, "error.stock_subscription_not_found", nil)
+		default:
+			respondError(c, response.CodeInternal, "error.stock_subscription_update_failed", err)
+		}
+		return
+	}
+	if fromPage {
+		renderStockUnsubscribePage(c, http.StatusOK, locale, "page.stock_unsubscribe.done", "")
+		return
+	}
+	response.Success(c, nil)
+}
+
+func renderStockUnsubscribePage(c *gin.Context, status int, locale, messageKey, token string) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+	if err := stockUnsubscribePage.Execute(c.Writer, stockUnsubscribePageData{
+		Locale:  locale,
+		Title:   i18n.T(locale, "page.stock_unsubscribe.title"),
+		Message: i18n.T(locale, messageKey),
+		Button:  i18n.T(locale, "page.stock_unsubscribe.button"),
+		Action:  c.Request.URL.Path + "?lang=" + url.QueryEscape(locale),
+		Token:   token,
+	}); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+func (h *Handler) createStockSubscription(c *gin.Context, userID uint) {
+	var req StockSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, response.CodeBadRequest, "error.bad_request", err)
+		return
+	}
+
+	subscription, err := h.StockSubscriptionService.Subscribe(service.StockSubscribeInput{
+		ProductID:  req.ProductID,
+		SKUID:      req.SKUID,
+		UserID:     userID,
+		Channel:    req.Channel,
+		Target:     req.Target,
+		Locale:     i18n.ResolveLocale(c),
+		AccessCode: req.AccessCode,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrStockSubscriptionInvalid):
+			respondError(c, response.CodeBadRequest, "error.stock_subscription_invalid", nil)
+		case errors.Is(err, service.ErrStockSubscriptionNotSoldOut):
+			respondError(c, response.CodeBadRequest, "error.stock_subscription_not_sold_out", nil)
+		case errors.Is(err, service.ErrProductNotAvailable):
+			respondError(c, response.CodeBadRequest, "error.product_not_available", nil)
+		case errors.Is(err, service.ErrProductAccessCodeRequired), errors.Is(err, service.ErrProductAccessCodeInvalid):
+			respondProductAccessError(c, err)
+		case errors.Is(err, service.ErrProductSKUInvalid):
+			respondError(c, response.CodeBadRequest, "error.order_item_invalid", nil)
+		default:
+			respondError(c, response.CodeInternal, "error.stock_subscription_update_failed", err)
+		}
+		return
+	}
+	response.Success(c, subscription)
+}
@@ -0,0 +1,91 @@
+package public
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dujiao-next/internal/config"
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/provider"
+	"github.com/dujiao-next/internal/repository"
+	"github.com/dujiao-next/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestStockUnsubscribeRequiresConfirmation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dsn := fmt.Sprintf("file:stock_unsubscribe_handler_test_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.AutoMigrate(&models.StockSubscription{}); err != nil {
+		t.Fatalf("auto migrate failed: %v", err)
+	}
+	subscription := &models.StockSubscription{
+		ProductID: 1,
+		SKUID:     1,
+		Channel:   constants.StockSubscriptionChannelEmail,
+		Target:    "a@example.com",
+		Token:     "unsubscribe-token",
+		Status:    constants.StockSubscriptionStatusPending,
+	}
+	if err := db.Create(subscription).Error; err != nil {
+		t.Fatalf("create subscription failed: %v", err)
+	}
+	h := &Handler{Container: &provider.Container{
+		StockSubscriptionService: service.NewStockSubscriptionService(
+			repository.NewStockSubscriptionRepository(db),
+			nil, nil, nil, nil, nil, nil,
+			config.TelegramAuthConfig{},
+			nil,
+			config.StockNotifyConfig{},
+			"https://shop.example.com",
+		),
+	}}
+	router := gin.New()
+	router.GET("/unsubscribe", h.ConfirmStockUnsubscribe)
+	router.POST("/unsubscribe", h.UnsubscribeStockSubscription)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unsubscribe?token=unsubscribe-token&lang=en-US", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `method="post"`) {
+		t.Fatalf("expected confirmation page, got %d %s", w.Code, w.Body.String())
+	}
+	assertStockSubscriptionStatus(t, db, subscription.ID, constants.StockSubscriptionStatusPending)
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/unsubscribe?lang=en-US", strings.NewReader(url.Values{"token": {"unsubscribe-token"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "You have been unsubscribed") {
+		t.Fatalf("expected unsubscribe result page, got %d %s", w.Code, w.Body.String())
+	}
+	assertStockSubscriptionStatus(t, db, subscription.ID, constants.StockSubscriptionStatusUnsubscribed)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unsubscribe?token=missing", nil))
+	if w.Code != http.StatusNotFound || strings.Contains(w.Body.String(), "<form") {
+		t.Fatalf("expected invalid link page without form, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func assertStockSubscriptionStatus(t *testing.T, db *gorm.DB, id uint, expected string) {
+	t.Helper()
+	var subscription models.StockSubscription
+	if err := db.First(&subscription, id).Error; err != nil {
+		t.Fatalf("load subscription failed: %v", err)
+	}
+	if subscription.Status != expected {
+		t.Fatalf("expected status %s, got %s", expected, subscription.Status)
+	}
+}
@@ -518,6 +518,10 @@ func (h *Handler) userProfileResponse(user *models.User) (gin.H, error) {
 	if err != nil {
 		return nil, err
 	}
+	memberLevel, err := h.MemberLevelService.ResolveUserLevel(user)
+	if err != nil {
+		return nil, err
+	}
 	return gin.H{
 		"id":                   user.ID,
 		"email":                user.Email,
@@ -526,6 +530,8 @@ func (h *Handler) userProfileResponse(user *models.User) (gin.H, error) {
 		"locale":               user.Locale,
 		"email_change_mode":    emailMode,
 		"password_change_mode": passwordMode,
+		"member_level":         memberLevel,
+		"total_spent":          user.TotalSpent,
 	}, nil
 }
 
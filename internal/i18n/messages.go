@@ -87,6 +87,9 @@ var messages = map[string]map[string]string{
 		"error.admin_delete_protected":             "默认超级管理员不允许删除",
 		"error.product_price_invalid":              "商品价格或币种不合法",
 		"error.product_purchase_invalid":           "商品购买身份不合法",
+		"error.product_visibility_invalid":         "商品可见性或访问码不合法",
+		"error.product_access_code_required":       "该商品需要访问码",
+		"error.product_access_code_invalid":        "商品访问码错误",
 		"error.product_purchase_not_allowed":       "当前商品仅限会员购买",
 		"error.user_fetch_failed":                  "获取用户信息失败",
 		"error.user_login_log_fetch_failed":        "获取登录日志失败",
@@ -95,6 +98,8 @@ var messages = map[string]map[string]string{
 		"error.product_fetch_failed":               "获取商品失败",
 		"error.product_not_found":                  "商品不存在",
 		"error.post_fetch_failed":                  "获取文章失败",
+		"error.sitemap_not_found":                  "站点地图不存在",
+		"error.seo_fetch_failed":                   "生成站点地图或订阅源失败",
 		"error.post_not_found":                     "文章不存在",
 		"error.banner_fetch_failed":                "获取 Banner 失败",
 		"error.banner_not_found":                   "Banner 不存在",
@@ -136,7 +141,7 @@ var messages = map[string]map[string]string{
 		"error.guest_email_required":               "游客邮箱不能为空",
 		"error.guest_password_required":            "订单密码不能为空",
 		"error.guest_order_not_found":              "未找到匹配的游客订单",
-		"error.guest_coupon_not_allowed":           "游客订单暂不支持优惠券",
+		"error.guest_coupon_not_allowed":           "该优惠券仅限登录用户使用",
 		"error.product_not_available":              "商品不可用或已下架",
 		"error.coupon_invalid":                     "优惠券不合法",
 		"error.coupon_not_found":                   "优惠券不存在",
@@ -147,11 +152,15 @@ var messages = map[string]map[string]string{
 		"error.coupon_per_user_limit":              "已达到优惠券使用上限",
 		"error.coupon_min_amount":                  "未满足优惠券使用门槛",
 		"error.coupon_scope_invalid":               "优惠券不适用于该商品",
+		"error.coupon_user_not_eligible":           "当前账号不满足优惠券使用条件",
+		"error.coupon_first_order_only":            "仅限首单使用",
 		"error.promotion_invalid":                  "活动价规则不合法",
 		"error.coupon_create_failed":               "创建优惠券失败",
 		"error.coupon_fetch_failed":                "获取优惠券失败",
 		"error.coupon_update_failed":               "更新优惠券失败",
 		"error.coupon_delete_failed":               "删除优惠券失败",
+		"error.coupon_batch_not_found":             "优惠码批次不存在",
+		"error.coupon_batch_create_failed":         "生成优惠码批次失败",
 		"error.promotion_not_found":                "活动价不存在",
 		"error.promotion_create_failed":            "创建活动价失败",
 		"error.promotion_fetch_failed":             "获取活动价失败",
@@ -210,6 +219,99 @@ var messages = map[string]map[string]string{
 		"error.gift_card_delete_failed":            "删除礼品卡失败",
 		"error.gift_card_redeem_failed":            "兑换礼品卡失败",
 		"error.queue_unavailable":                  "队列服务不可用，请稍后重试",
+		"error.receipt_disabled":                   "收据功能未开启",
+		"error.receipt_not_available":              "订单未支付，暂无法开具收据",
+		"error.receipt_generate_failed":            "生成收据失败",
+		"error.receipt_font_missing":               "收据字体未配置，请先在配置文件中设置 receipt.font_path（需支持中文的 TTF 字体）",
+		"receipt.title":                            "收据",
+		"receipt.invoice_no":                       "发票号",
+		"receipt.issued_at":                        "开具时间",
+		"receipt.order_no":                         "订单号",
+		"receipt.paid_at":                          "支付时间",
+		"receipt.bill_to":                          "购买人",
+		"receipt.tax_id":                           "税号",
+		"receipt.item":                             "商品",
+		"receipt.quantity":                         "数量",
+		"receipt.unit_price":                       "单价",
+		"receipt.amount":                           "金额",
+		"receipt.subtotal":                         "小计",
+		"receipt.promotion_discount":               "活动优惠",
+		"receipt.coupon_discount":                  "优惠券抵扣",
+		"receipt.total":                            "实付金额",
+		"receipt.refunded":                         "已退款",
+		"receipt.payment_method":                   "支付方式",
+		"receipt.payment_wallet":                   "余额",
+		"error.order_message_not_allowed":          "该订单不支持沟通消息",
+		"error.order_message_closed":               "订单已结束，无法继续发送消息",
+		"error.order_message_invalid":              "消息内容无效",
+		"error.order_message_attachment_failed":    "附件上传失败",
+		"error.order_message_create_failed":        "发送消息失败",
+		"error.order_message_fetch_failed":         "获取消息失败",
+		"email.order_message.subject":              "订单 %s 有新的回复",
+		"email.order_message.body":                 "订单号：%s\n客服回复：\n%s\n\n请登录网站查看完整对话。",
+		"email.stock_arrival.subject":              "%s 已到货",
+		"email.stock_arrival.body":                 "您订阅的商品 %s 已补货。\n立即购买：%s\n\n如不再需要此类通知，可点击退订：%s",
+		"page.stock_unsubscribe.title":             "退订到货通知",
+		"page.stock_unsubscribe.confirm":           "确认后将不再接收该商品的到货通知。",
+		"page.stock_unsubscribe.button":            "确认退订",
+		"page.stock_unsubscribe.done":              "已退订，您将不再收到该商品的到货通知。",
+		"page.stock_unsubscribe.invalid":           "退订链接无效或已失效。",
+		"page.stock_unsubscribe.failed":            "退订失败，请稍后重试。",
+		"error.supplier_invalid":                   "供应商配置无效",
+		"error.supplier_not_found":                 "供应商不存在",
+		"error.supplier_in_use":                    "仍有商品绑定该供应商，无法删除",
+		"error.supplier_fetch_failed":              "获取供应商失败",
+		"error.supplier_save_failed":               "保存供应商失败",
+		"error.supplier_delete_failed":             "删除供应商失败",
+		"error.card_secret_schema_invalid":         "卡密字段配置不合法",
+		"error.card_secret_duplicate":              "卡密已存在",
+		"error.delivery_template_invalid":          "交付模板不合法",
+		"error.fulfillment_quantity_invalid":       "交付数量不合法或超过剩余待交付数量",
+		"error.product_file_missing":               "文件交付商品尚未上传交付文件",
+		"error.product_file_not_found":             "交付文件不存在",
+		"error.product_file_upload_failed":         "交付文件上传失败",
+		"error.download_link_invalid":              "下载链接无效",
+		"error.download_link_expired":              "下载链接已过期，请刷新订单页面重新获取",
+		"error.download_limit_exceeded":            "下载次数已用完",
+		"error.download_unavailable":               "文件暂不可下载",
+		"error.key_generator_invalid":              "卡密生成器配置不合法",
+		"error.key_generator_unavailable":          "授权码签名密钥未配置",
+		"error.secret_reveal_config_invalid":       "查看次数上限配置无效",
+		"error.secret_reveal_unavailable":          "该订单暂无可查看的交付内容",
+		"error.secret_reveal_limit_exceeded":       "交付内容查看次数已用完，请联系客服",
+		"error.secret_reveal_failed":               "查看交付内容失败",
+		"error.product_spec_schema_invalid":        "规格定义无效",
+		"error.product_sku_spec_invalid":           "SKU 规格值与规格定义不符或组合重复",
+		"error.category_has_children":              "该分类下有子分类，无法删除",
+		"error.category_parent_invalid":            "父分类不存在、形成循环或层级过深",
+		"error.category_reassign_invalid":          "转移目标分类不合法",
+		"error.category_reorder_invalid":           "分类排序参数不合法",
+		"error.product_transfer_format_invalid":    "导入导出格式不支持，仅支持 json/csv/xlsx",
+		"error.product_import_invalid":             "导入文件内容不合法",
+		"error.product_import_failed":              "商品导入失败",
+		"error.product_price_tier_invalid":         "阶梯价配置不合法：起购数量需大于 1 且递增，单价不得高于上一档",
+		"error.member_level_invalid":               "会员等级配置不合法",
+		"error.member_level_not_found":             "会员等级不存在",
+		"error.member_level_code_exists":           "会员等级编码已存在",
+		"error.member_level_fetch_failed":          "获取会员等级失败",
+		"error.member_level_create_failed":         "创建会员等级失败",
+		"error.member_level_update_failed":         "更新会员等级失败",
+		"error.member_level_delete_failed":         "删除会员等级失败",
+		"error.product_review_invalid":             "评价内容不合法",
+		"error.product_review_not_found":           "评价不存在",
+		"error.product_review_not_allowed":         "仅已完成订单的买家可以评价",
+		"error.product_review_exists":              "该订单商品已评价",
+		"error.product_review_fetch_failed":        "获取评价失败",
+		"error.product_review_create_failed":       "提交评价失败",
+		"error.product_review_update_failed":       "更新评价失败",
+		"error.product_schedule_invalid":           "定时下架时间必须晚于定时上架时间",
+		"error.product_revision_not_found":         "商品修订不存在",
+		"error.product_revision_fetch_failed":      "获取商品修订失败",
+		"error.product_revision_restore_failed":    "恢复商品修订失败",
+		"error.stock_subscription_invalid":         "到货通知订阅参数不合法",
+		"error.stock_subscription_not_sold_out":    "该规格当前有库存，无需订阅到货通知",
+		"error.stock_subscription_not_found":       "到货通知订阅不存在",
+		"error.stock_subscription_update_failed":   "到货通知订阅操作失败",
 		"order.status.pending_payment":             "待支付",
 		"order.status.paid":                        "已支付",
 		"order.status.fulfilling":                  "处理中",
@@ -297,6 +399,9 @@ var messages = map[string]map[string]string{
 		"error.admin_delete_protected":             "預設超級管理員不允許刪除",
 		"error.product_price_invalid":              "商品價格或幣種不合法",
 		"error.product_purchase_invalid":           "商品購買身份不合法",
+		"error.product_visibility_invalid":         "商品可見性或訪問碼不合法",
+		"error.product_access_code_required":       "該商品需要訪問碼",
+		"error.product_access_code_invalid":        "商品訪問碼錯誤",
 		"error.product_purchase_not_allowed":       "當前商品僅限會員購買",
 		"error.user_fetch_failed":                  "獲取用戶信息失敗",
 		"error.user_login_log_fetch_failed":        "獲取登入日誌失敗",
@@ -305,6 +410,8 @@ var messages = map[string]map[string]string{
 		"error.product_fetch_failed":               "獲取商品失敗",
 		"error.product_not_found":                  "商品不存在",
 		"error.post_fetch_failed":                  "獲取文章失敗",
+		"error.sitemap_not_found":                  "網站地圖不存在",
+		"error.seo_fetch_failed":                   "生成網站地圖或訂閱源失敗",
 		"error.post_not_found":                     "文章不存在",
 		"error.banner_fetch_failed":                "獲取 Banner 失敗",
 		"error.banner_not_found":                   "Banner 不存在",
@@ -346,7 +453,7 @@ var messages = map[string]map[string]string{
 		"error.guest_email_required":               "遊客郵箱不能為空",
 		"error.guest_password_required":            "訂單密碼不能為空",
 		"error.guest_order_not_found":              "未找到匹配的遊客訂單",
-		"error.guest_coupon_not_allowed":           "遊客訂單暫不支持優惠券",
+		"error.guest_coupon_not_allowed":           "該優惠券僅限登入使用者使用",
 		"error.product_not_available":              "商品不可用或已下架",
 		"error.coupon_invalid":                     "優惠券不合法",
 		"error.coupon_not_found":                   "優惠券不存在",
@@ -357,11 +464,15 @@ var messages = map[string]map[string]string{
 		"error.coupon_per_user_limit":              "已達到優惠券使用上限",
 		"error.coupon_min_amount":                  "未滿足優惠券使用門檻",
 		"error.coupon_scope_invalid":               "優惠券不適用於該商品",
+		"error.coupon_user_not_eligible":           "當前帳號不滿足優惠券使用條件",
+		"error.coupon_first_order_only":            "僅限首單使用",
 		"error.promotion_invalid":                  "活動價規則不合法",
 		"error.coupon_create_failed":               "建立優惠券失敗",
 		"error.coupon_fetch_failed":                "獲取優惠券失敗",
 		"error.coupon_update_failed":               "更新優惠券失敗",
 		"error.coupon_delete_failed":               "刪除優惠券失敗",
+		"error.coupon_batch_not_found":             "優惠碼批次不存在",
+		"error.coupon_batch_create_failed":         "生成優惠碼批次失敗",
 		"error.promotion_not_found":                "活動價不存在",
 		"error.promotion_create_failed":            "建立活動價失敗",
 		"error.promotion_fetch_failed":             "獲取活動價失敗",
@@ -420,6 +531,99 @@ var messages = map[string]map[string]string{
 		"error.gift_card_delete_failed":            "刪除禮品卡失敗",
 		"error.gift_card_redeem_failed":            "兌換禮品卡失敗",
 		"error.queue_unavailable":                  "隊列服務不可用，請稍後重試",
+		"error.receipt_disabled":                   "收據功能未開啟",
+		"error.receipt_not_available":              "訂單未支付，暫無法開具收據",
+		"error.receipt_generate_failed":            "生成收據失敗",
+		"error.receipt_font_missing":               "收據字型未設定，請先在設定檔中設定 receipt.font_path（需支援中文的 TTF 字型）",
+		"receipt.title":                            "收據",
+		"receipt.invoice_no":                       "發票號",
+		"receipt.issued_at":                        "開具時間",
+		"receipt.order_no":                         "訂單號",
+		"receipt.paid_at":                          "支付時間",
+		"receipt.bill_to":                          "購買人",
+		"receipt.tax_id":                           "稅號",
+		"receipt.item":                             "商品",
+		"receipt.quantity":                         "數量",
+		"receipt.unit_price":                       "單價",
+		"receipt.amount":                           "金額",
+		"receipt.subtotal":                         "小計",
+		"receipt.promotion_discount":               "活動優惠",
+		"receipt.coupon_discount":                  "優惠券抵扣",
+		"receipt.total":                            "實付金額",
+		"receipt.refunded":                         "已退款",
+		"receipt.payment_method":                   "支付方式",
+		"receipt.payment_wallet":                   "餘額",
+		"error.order_message_not_allowed":          "該訂單不支援溝通訊息",
+		"error.order_message_closed":               "訂單已結束，無法繼續發送訊息",
+		"error.order_message_invalid":              "訊息內容無效",
+		"error.order_message_attachment_failed":    "附件上傳失敗",
+		"error.order_message_create_failed":        "發送訊息失敗",
+		"error.order_message_fetch_failed":         "取得訊息失敗",
+		"email.order_message.subject":              "訂單 %s 有新的回覆",
+		"email.order_message.body":                 "訂單號：%s\n客服回覆：\n%s\n\n請登入網站查看完整對話。",
+		"email.stock_arrival.subject":              "%s 已到貨",
+		"email.stock_arrival.body":                 "您訂閱的商品 %s 已補貨。\n立即購買：%s\n\n如不再需要此類通知，可點擊退訂：%s",
+		"page.stock_unsubscribe.title":             "退訂到貨通知",
+		"page.stock_unsubscribe.confirm":           "確認後將不再接收該商品的到貨通知。",
+		"page.stock_unsubscribe.button":            "確認退訂",
+		"page.stock_unsubscribe.done":              "已退訂，您將不再收到該商品的到貨通知。",
+		"page.stock_unsubscribe.invalid":           "退訂連結無效或已失效。",
+		"page.stock_unsubscribe.failed":            "退訂失敗，請稍後重試。",
+		"error.supplier_invalid":                   "供應商設定無效",
+		"error.supplier_not_found":                 "供應商不存在",
+		"error.supplier_in_use":                    "仍有商品綁定該供應商，無法刪除",
+		"error.supplier_fetch_failed":              "取得供應商失敗",
+		"error.supplier_save_failed":               "儲存供應商失敗",
+		"error.supplier_delete_failed":             "刪除供應商失敗",
+		"error.card_secret_schema_invalid":         "卡密欄位設定不合法",
+		"error.card_secret_duplicate":              "卡密已存在",
+		"error.delivery_template_invalid":          "交付模板不合法",
+		"error.fulfillment_quantity_invalid":       "交付數量不合法或超過剩餘待交付數量",
+		"error.product_file_missing":               "檔案交付商品尚未上傳交付檔案",
+		"error.product_file_not_found":             "交付檔案不存在",
+		"error.product_file_upload_failed":         "交付檔案上傳失敗",
+		"error.download_link_invalid":              "下載連結無效",
+		"error.download_link_expired":              "下載連結已過期，請重新整理訂單頁面重新取得",
+		"error.download_limit_exceeded":            "下載次數已用完",
+		"error.download_unavailable":               "檔案暫不可下載",
+		"error.key_generator_invalid":              "卡密產生器設定不合法",
+		"error.key_generator_unavailable":          "授權碼簽章金鑰未設定",
+		"error.secret_reveal_config_invalid":       "查看次數上限設定無效",
+		"error.secret_reveal_unavailable":          "該訂單暫無可查看的交付內容",
+		"error.secret_reveal_limit_exceeded":       "交付內容查看次數已用完，請聯繫客服",
+		"error.secret_reveal_failed":               "查看交付內容失敗",
+		"error.product_spec_schema_invalid":        "規格定義無效",
+		"error.product_sku_spec_invalid":           "SKU 規格值與規格定義不符或組合重複",
+		"error.category_has_children":              "該分類下有子分類，無法刪除",
+		"error.category_parent_invalid":            "父分類不存在、形成循環或層級過深",
+		"error.category_reassign_invalid":          "轉移目標分類不合法",
+		"error.category_reorder_invalid":           "分類排序參數不合法",
+		"error.product_transfer_format_invalid":    "匯入匯出格式不支援，僅支援 json/csv/xlsx",
+		"error.product_import_invalid":             "匯入檔案內容不合法",
+		"error.product_import_failed":              "商品匯入失敗",
+		"error.product_price_tier_invalid":         "階梯價設定不合法：起購數量需大於 1 且遞增，單價不得高於上一檔",
+		"error.member_level_invalid":               "會員等級設定不合法",
+		"error.member_level_not_found":             "會員等級不存在",
+		"error.member_level_code_exists":           "會員等級編碼已存在",
+		"error.member_level_fetch_failed":          "取得會員等級失敗",
+		"error.member_level_create_failed":         "建立會員等級失敗",
+		"error.member_level_update_failed":         "更新會員等級失敗",
+		"error.member_level_delete_failed":         "刪除會員等級失敗",
+		"error.product_review_invalid":             "評價內容不合法",
+		"error.product_review_not_found":           "評價不存在",
+		"error.product_review_not_allowed":         "僅已完成訂單的買家可以評價",
+		"error.product_review_exists":              "該訂單商品已評價",
+		"error.product_review_fetch_failed":        "取得評價失敗",
+		"error.product_review_create_failed":       "提交評價失敗",
+		"error.product_review_update_failed":       "更新評價失敗",
+		"error.product_schedule_invalid":           "定時下架時間必須晚於定時上架時間",
+		"error.product_revision_not_found":         "商品修訂不存在",
+		"error.product_revision_fetch_failed":      "取得商品修訂失敗",
+		"error.product_revision_restore_failed":    "還原商品修訂失敗",
+		"error.stock_subscription_invalid":         "到貨通知訂閱參數不合法",
+		"error.stock_subscription_not_sold_out":    "該規格目前有庫存，無需訂閱到貨通知",
+		"error.stock_subscription_not_found":       "到貨通知訂閱不存在",
+		"error.stock_subscription_update_failed":   "到貨通知訂閱操作失敗",
 		"order.status.pending_payment":             "待支付",
 		"order.status.paid":                        "已支付",
 		"order.status.fulfilling":                  "處理中",
@@ -507,6 +711,9 @@ var messages = map[string]map[string]string{
 		"error.admin_delete_protected":             "Default super admin cannot be deleted",
 		"error.product_price_invalid":              "Invalid product price or currency",
 		"error.product_purchase_invalid":           "Invalid product purchase type",
+		"error.product_visibility_invalid":         "Invalid product visibility or access code",
+		"error.product_access_code_required":       "An access code is required for this product",
+		"error.product_access_code_invalid":        "Invalid product access code",
 		"error.product_purchase_not_allowed":       "This product requires member purchase",
 		"error.user_fetch_failed":                  "Failed to fetch user",
 		"error.user_login_log_fetch_failed":        "Failed to fetch login logs",
@@ -515,6 +722,8 @@ var messages = map[string]map[string]string{
 		"error.product_fetch_failed":               "Failed to fetch products",
 		"error.product_not_found":                  "Product not found",
 		"error.post_fetch_failed":                  "Failed to fetch posts",
+		"error.sitemap_not_found":                  "Sitemap not found",
+		"error.seo_fetch_failed":                   "Failed to generate sitemap or feed",
 		"error.post_not_found":                     "Post not found",
 		"error.banner_fetch_failed":                "Failed to fetch banners",
 		"error.banner_not_found":                   "Banner not found",
@@ -556,7 +765,7 @@ var messages = map[string]map[string]string{
 		"error.guest_email_required":               "Guest email is required",
 		"error.guest_password_required":            "Order password is required",
 		"error.guest_order_not_found":              "Guest order not found",
-		"error.guest_coupon_not_allowed":           "Guest orders do not support coupons yet",
+		"error.guest_coupon_not_allowed":           "This coupon is only available to signed-in users",
 		"error.product_not_available":              "Product is not available",
 		"error.coupon_invalid":                     "Invalid coupon",
 		"error.coupon_not_found":                   "Coupon not found",
@@ -567,11 +776,15 @@ var messages = map[string]map[string]string{
 		"error.coupon_per_user_limit":              "Coupon usage limit per user reached",
 		"error.coupon_min_amount":                  "Coupon minimum amount not met",
 		"error.coupon_scope_invalid":               "Coupon is not applicable to this product",
+		"error.coupon_user_not_eligible":           "Your account is not eligible for this coupon",
+		"error.coupon_first_order_only":            "This coupon is only valid for your first order",
 		"error.promotion_invalid":                  "Invalid promotion rule",
 		"error.coupon_create_failed":               "Failed to create coupon",
 		"error.coupon_fetch_failed":                "Failed to fetch coupons",
 		"error.coupon_update_failed":               "Failed to update coupon",
 		"error.coupon_delete_failed":               "Failed to delete coupon",
+		"error.coupon_batch_not_found":             "Coupon batch not found",
+		"error.coupon_batch_create_failed":         "Failed to generate coupon batch",
 		"error.promotion_not_found":                "Promotion not found",
 		"error.promotion_create_failed":            "Failed to create promotion",
 		"error.promotion_fetch_failed":             "Failed to fetch promotions",
@@ -630,6 +843,99 @@ var messages = map[string]map[string]string{
 		"error.gift_card_delete_failed":            "Failed to delete gift card",
 		"error.gift_card_redeem_failed":            "Failed to redeem gift card",
 		"error.queue_unavailable":                  "Queue service unavailable, please try again later",
+		"error.receipt_disabled":                   "Receipts are not enabled",
+		"error.receipt_not_available":              "Receipt is available after the order is paid",
+		"error.receipt_generate_failed":            "Failed to generate receipt",
+		"error.receipt_font_missing":               "Receipt font is not configured. Set receipt.font_path to a TTF font that covers CJK characters",
+		"receipt.title":                            "Receipt",
+		"receipt.invoice_no":                       "Invoice No",
+		"receipt.issued_at":                        "Issued At",
+		"receipt.order_no":                         "Order No",
+		"receipt.paid_at":                          "Paid At",
+		"receipt.bill_to":                          "Bill To",
+		"receipt.tax_id":                           "Tax ID",
+		"receipt.item":                             "Item",
+		"receipt.quantity":                         "Qty",
+		"receipt.unit_price":                       "Unit Price",
+		"receipt.amount":                           "Amount",
+		"receipt.subtotal":                         "Subtotal",
+		"receipt.promotion_discount":               "Promotion Discount",
+		"receipt.coupon_discount":                  "Coupon Discount",
+		"receipt.total":                            "Total Paid",
+		"receipt.refunded":                         "Refunded",
+		"receipt.payment_method":                   "Payment Method",
+		"receipt.payment_wallet":                   "Wallet Balance",
+		"error.order_message_not_allowed":          "Messages are not available for this order",
+		"error.order_message_closed":               "The order is closed and no longer accepts messages",
+		"error.order_message_invalid":              "Invalid message",
+		"error.order_message_attachment_failed":    "Failed to upload attachment",
+		"error.order_message_create_failed":        "Failed to send message",
+		"error.order_message_fetch_failed":         "Failed to fetch messages",
+		"email.order_message.subject":              "New reply on order %s",
+		"email.order_message.body":                 "Order No: %s\nReply from support:\n%s\n\nPlease sign in to view the full conversation.",
+		"email.stock_arrival.subject":              "%s is back in stock",
+		"email.stock_arrival.body":                 "The product you subscribed to, %s, is back in stock.\nBuy now: %s\n\nTo stop receiving this notification, unsubscribe here: %s",
+		"page.stock_unsubscribe.title":             "Unsubscribe from restock notifications",
+		"page.stock_unsubscribe.confirm":           "You will no longer receive restock notifications for this product.",
+		"page.stock_unsubscribe.button":            "Unsubscribe",
+		"page.stock_unsubscribe.done":              "You have been unsubscribed from restock notifications for this product.",
+		"page.stock_unsubscribe.invalid":           "This unsubscribe link is invalid or has expired.",
+		"page.stock_unsubscribe.failed":            "Failed to unsubscribe. Please try again later.",
+		"error.supplier_invalid":                   "Invalid supplier configuration",
+		"error.supplier_not_found":                 "Supplier not found",
+		"error.supplier_in_use":                    "Supplier is still bound to products",
+		"error.supplier_fetch_failed":              "Failed to fetch suppliers",
+		"error.supplier_save_failed":               "Failed to save supplier",
+		"error.supplier_delete_failed":             "Failed to delete supplier",
+		"error.card_secret_schema_invalid":         "Invalid card secret field schema",
+		"error.card_secret_duplicate":              "Card secret already exists",
+		"error.delivery_template_invalid":          "Invalid delivery template",
+		"error.fulfillment_quantity_invalid":       "Delivery quantity is invalid or exceeds the remaining quantity",
+		"error.product_file_missing":               "No delivery file has been uploaded for this file product",
+		"error.product_file_not_found":             "Delivery file not found",
+		"error.product_file_upload_failed":         "Failed to upload delivery file",
+		"error.download_link_invalid":              "Invalid download link",
+		"error.download_link_expired":              "Download link expired, please reload the order page to get a new one",
+		"error.download_limit_exceeded":            "Download limit reached",
+		"error.download_unavailable":               "File is not available for download",
+		"error.key_generator_invalid":              "Invalid key generator configuration",
+		"error.key_generator_unavailable":          "License signing key is not configured",
+		"error.secret_reveal_config_invalid":       "Invalid reveal view limit",
+		"error.secret_reveal_unavailable":          "No delivered content to reveal for this order",
+		"error.secret_reveal_limit_exceeded":       "Reveal limit reached, please contact support",
+		"error.secret_reveal_failed":               "Failed to reveal delivered content",
+		"error.product_spec_schema_invalid":        "Invalid specification definitions",
+		"error.product_sku_spec_invalid":           "SKU spec values do not match the definitions or duplicate another SKU",
+		"error.category_has_children":              "Category has subcategories and cannot be deleted",
+		"error.category_parent_invalid":            "Parent category is missing, circular or too deep",
+		"error.category_reassign_invalid":          "Invalid reassign target category",
+		"error.category_reorder_invalid":           "Invalid category order",
+		"error.product_transfer_format_invalid":    "Unsupported format, only json/csv/xlsx are allowed",
+		"error.product_import_invalid":             "Invalid import file",
+		"error.product_import_failed":              "Failed to import products",
+		"error.product_price_tier_invalid":         "Invalid price tiers: minimum quantities must be above 1 and increasing, and prices must not exceed the previous tier",
+		"error.member_level_invalid":               "Invalid member level settings",
+		"error.member_level_not_found":             "Member level not found",
+		"error.member_level_code_exists":           "Member level code already exists",
+		"error.member_level_fetch_failed":          "Failed to fetch member levels",
+		"error.member_level_create_failed":         "Failed to create member level",
+		"error.member_level_update_failed":         "Failed to update member level",
+		"error.member_level_delete_failed":         "Failed to delete member level",
+		"error.product_review_invalid":             "Invalid review",
+		"error.product_review_not_found":           "Review not found",
+		"error.product_review_not_allowed":         "Only buyers with a completed order can review this product",
+		"error.product_review_exists":              "This order item has already been reviewed",
+		"error.product_review_fetch_failed":        "Failed to fetch reviews",
+		"error.product_review_create_failed":       "Failed to submit review",
+		"error.product_review_update_failed":       "Failed to update review",
+		"error.product_schedule_invalid":           "Unpublish time must be later than publish time",
+		"error.product_revision_not_found":         "Product revision not found",
+		"error.product_revision_fetch_failed":      "Failed to fetch product revisions",
+		"error.product_revision_restore_failed":    "Failed to restore product revision",
+		"error.stock_subscription_invalid":         "Invalid back-in-stock subscription",
+		"error.stock_subscription_not_sold_out":    "This option is in stock, no need to subscribe",
+		"error.stock_subscription_not_found":       "Back-in-stock subscription not found",
+		"error.stock_subscription_update_failed":   "Failed to update back-in-stock subscription",
 		"order.status.pending_payment":             "Pending Payment",
 		"order.status.paid":                        "Paid",
 		"order.status.fulfilling":                  "Processing",
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"encoding/json"
+	"errors"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -14,23 +17,139 @@ const (
 
 // CardSecret 卡密库存表
 type CardSecret struct {
-	ID         uint           `gorm:"primarykey" json:"id"`                                 // 主键
-	ProductID  uint           `gorm:"index;not null" json:"product_id"`                     // 商品ID
-	SKUID      uint           `gorm:"column:sku_id;index;not null;default:0" json:"sku_id"` // SKU ID
-	BatchID    *uint          `gorm:"index" json:"batch_id,omitempty"`                      // 批次ID
-	Secret     string         `gorm:"type:text;not null" json:"secret"`                     // 卡密内容
-	Status     string         `gorm:"index;not null" json:"status"`                         // 状态（available/used）
-	OrderID    *uint          `gorm:"index" json:"order_id,omitempty"`                      // 关联订单ID
-	ReservedAt *time.Time     `gorm:"index" json:"reserved_at"`                             // 占用时间
-	UsedAt     *time.Time     `gorm:"index" json:"used_at"`                                 // 使用时间
-	CreatedAt  time.Time      `gorm:"index" json:"created_at"`                              // 创建时间
-	UpdatedAt  time.Time      `gorm:"index" json:"updated_at"`                              // 更新时间
-	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`                                       // 软删除时间
+	ID          uint           `gorm:"primarykey" json:"id"`                                 // 主键
+	ProductID   uint           `gorm:"index;not null" json:"product_id"`                     // 商品ID
+	SKUID       uint           `gorm:"column:sku_id;index;not null;default:0" json:"sku_id"` // SKU ID
+	BatchID     *uint          `gorm:"index" json:"batch_id,omitempty"`                      // 批次ID
+	Secret      string         `gorm:"type:text;not null" json:"secret"`                     // 卡密内容（落库时加密）
+	SecretKeyID string         `gorm:"size:64;index;not null;default:''" json:"-"`           // 加密密钥ID（空表示明文）
+	SecretHash  string         `gorm:"size:64;index;not null;default:''" json:"-"`           // 卡密内容哈希（同商品去重）
+	HashKeyID   string         `gorm:"size:64;index;not null;default:''" json:"-"`           // 内容哈希密钥ID（空表示未加密时的 sha256）
+	FieldsData  string         `gorm:"type:text" json:"-"`                                   // 结构化字段（落库时加密）
+	Fields      JSON           `gorm:"-" json:"fields,omitempty"`                            // 结构化字段明文（按商品卡密字段 schema）
+	Status      string         `gorm:"index;not null" json:"status"`                         // 状态（available/used）
+	OrderID     *uint          `gorm:"index" json:"order_id,omitempty"`                      // 关联订单ID
+	ReservedAt  *time.Time     `gorm:"index" json:"reserved_at"`                             // 占用时间
+	UsedAt      *time.Time     `gorm:"index" json:"used_at"`                                 // 使用时间
+	CreatedAt   time.Time      `gorm:"index" json:"created_at"`                              // 创建时间
+	UpdatedAt   time.Time      `gorm:"index" json:"updated_at"`                              // 更新时间
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`                                       // 软删除时间
 
 	Batch *CardSecretBatch `gorm:"foreignKey:BatchID" json:"batch,omitempty"` // 批次信息
+
+	plainSecret string
+	plainFields string
 }
 
 // TableName 指定表名
 func (CardSecret) TableName() string {
 	return "card_secrets"
 }
+
+// BeforeSave 写库前计算内容哈希并加密卡密与结构化字段
+func (c *CardSecret) BeforeSave(tx *gorm.DB) error {
+	if c.Secret == "" {
+		return nil
+	}
+	fieldsData, err := encodeCardSecretFields(c.Fields)
+	if err != nil {
+		return err
+	}
+	c.RefreshSecretHash()
+	ciphertext, keyID, err := encryptField(c.Secret)
+	if err != nil {
+		return err
+	}
+	encryptedFields, fieldsKeyID, err := encryptField(fieldsData)
+	if err != nil {
+		return err
+	}
+	if fieldsData != "" && fieldsKeyID != keyID {
+		return errCardSecretKeyMismatch
+	}
+	c.plainSecret = c.Secret
+	c.plainFields = fieldsData
+	c.Secret = ciphertext
+	c.SecretKeyID = keyID
+	c.FieldsData = encryptedFields
+	return nil
+}
+
+// AfterSave 写库后恢复内存中的明文
+func (c *CardSecret) AfterSave(tx *gorm.DB) error {
+	if c.plainSecret != "" {
+		c.Secret = c.plainSecret
+		c.plainSecret = ""
+	}
+	if c.plainFields != "" {
+		c.FieldsData = c.plainFields
+		c.plainFields = ""
+	}
+	return nil
+}
+
+// AfterFind 读库后解密卡密与结构化字段
+func (c *CardSecret) AfterFind(tx *gorm.DB) error {
+	plaintext, err := decryptField(c.Secret, c.SecretKeyID)
+	if err != nil {
+		return err
+	}
+	c.Secret = plaintext
+	if c.FieldsData == "" {
+		return nil
+	}
+	fieldsData, err := decryptField(c.FieldsData, c.SecretKeyID)
+	if err != nil {
+		return err
+	}
+	fields := JSON{}
+	if err := json.Unmarshal([]byte(fieldsData), &fields); err != nil {
+		return err
+	}
+	c.FieldsData = fieldsData
+	c.Fields = fields
+	return nil
+}
+
+var errCardSecretKeyMismatch = errors.New("card secret fields encrypted with different key")
+
+const cardSecretHashLabel = "card_secret_hash"
+
+// CardSecretContentHash 计算卡密内容哈希，结构化卡密按字段计算，避免分隔符差异造成漏判
+// 启用加密时为当前密钥派生的 HMAC，防止拿到数据库即可离线比对卡密；密钥轮换后由回填任务重算历史哈希
+func CardSecretContentHash(secret string, fields JSON) string {
+	hash, _ := cardSecretContentHash(secret, fields)
+	return hash
+}
+
+// CardSecretLookupHashes 返回卡密内容在任一密钥（含未加密 sha256）下的哈希，供按内容查找与查重使用
+func CardSecretLookupHashes(secret string, fields JSON) []string {
+	return lookupHashes(cardSecretHashLabel, cardSecretHashSource(secret, fields))
+}
+
+// RefreshSecretHash 按当前密钥重算内容哈希，供历史数据回填使用
+func (c *CardSecret) RefreshSecretHash() {
+	c.SecretHash, c.HashKeyID = cardSecretContentHash(c.Secret, c.Fields)
+}
+
+func cardSecretContentHash(secret string, fields JSON) (string, string) {
+	return hashField(cardSecretHashLabel, cardSecretHashSource(secret, fields))
+}
+
+func cardSecretHashSource(secret string, fields JSON) string {
+	if encoded, err := encodeCardSecretFields(fields); err == nil && encoded != "" {
+		return encoded
+	}
+	return strings.TrimSpace(secret)
+}
+
+func encodeCardSecretFields(fields JSON) (string, error) {
+	if len(fields) == 0 {
+		return "", nil
+	}
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
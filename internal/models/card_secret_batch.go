@@ -12,8 +12,9 @@ type CardSecretBatch struct {
 	ProductID  uint           `gorm:"index;not null" json:"product_id"`                     // 商品ID
 	SKUID      uint           `gorm:"column:sku_id;index;not null;default:0" json:"sku_id"` // SKU ID
 	BatchNo    string         `gorm:"uniqueIndex;not null" json:"batch_no"`                 // 批次号
-	Source     string         `gorm:"not null" json:"source"`                               // 来源（manual/csv）
+	Source     string         `gorm:"not null" json:"source"`                               // 来源（manual/csv/xlsx/jsonl/txt/generator）
 	TotalCount int            `gorm:"not null" json:"total_count"`                          // 总数量
+	Duplicates int            `gorm:"not null;default:0" json:"duplicate_count"`            // 录入时跳过的重复卡密数量
 	Note       string         `gorm:"type:text" json:"note"`                                // 备注
 	CreatedBy  *uint          `gorm:"index" json:"created_by,omitempty"`                    // 创建管理员ID
 	CreatedAt  time.Time      `gorm:"index" json:"created_at"`                              // 创建时间
@@ -8,23 +8,29 @@ import (
 
 // Coupon 优惠券
 type Coupon struct {
-	ID           uint           `gorm:"primarykey" json:"id"`                                      // 主键
-	Code         string         `gorm:"uniqueIndex;not null" json:"code"`                          // 优惠码
-	Type         string         `gorm:"not null" json:"type"`                                      // 类型（fixed/percent）
-	Value        Money          `gorm:"type:decimal(20,2);not null" json:"value"`                  // 数值（固定金额或百分比）
-	MinAmount    Money          `gorm:"type:decimal(20,2);not null;default:0" json:"min_amount"`   // 使用门槛
-	MaxDiscount  Money          `gorm:"type:decimal(20,2);not null;default:0" json:"max_discount"` // 最大优惠金额
-	UsageLimit   int            `gorm:"not null;default:0" json:"usage_limit"`                     // 总使用上限（0 表示不限制）
-	UsedCount    int            `gorm:"not null;default:0" json:"used_count"`                      // 已使用次数
-	PerUserLimit int            `gorm:"not null;default:0" json:"per_user_limit"`                  // 每人使用上限（0 表示不限制）
-	ScopeType    string         `gorm:"not null" json:"scope_type"`                                // 适用范围（product）
-	ScopeRefIDs  string         `gorm:"type:text" json:"scope_ref_ids"`                            // 适用商品ID集合（JSON数组）
-	StartsAt     *time.Time     `gorm:"index" json:"starts_at"`                                    // 生效时间
-	EndsAt       *time.Time     `gorm:"index" json:"ends_at"`                                      // 失效时间
-	IsActive     bool           `gorm:"not null;default:true" json:"is_active"`                    // 是否启用
-	CreatedAt    time.Time      `gorm:"index" json:"created_at"`                                   // 创建时间
-	UpdatedAt    time.Time      `gorm:"index" json:"updated_at"`                                   // 更新时间
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`                                            // 软删除时间
+	ID                 uint           `gorm:"primarykey" json:"id"`                                      // 主键
+	BatchID            *uint          `gorm:"index" json:"batch_id,omitempty"`                           // 批次ID（批量生成的优惠码）
+	Code               string         `gorm:"uniqueIndex;not null" json:"code"`                          // 优惠码
+	Type               string         `gorm:"not null" json:"type"`                                      // 类型（fixed/percent）
+	Value              Money          `gorm:"type:decimal(20,2);not null" json:"value"`                  // 数值（固定金额或百分比）
+	MinAmount          Money          `gorm:"type:decimal(20,2);not null;default:0" json:"min_amount"`   // 使用门槛
+	MaxDiscount        Money          `gorm:"type:decimal(20,2);not null;default:0" json:"max_discount"` // 最大优惠金额
+	UsageLimit         int            `gorm:"not null;default:0" json:"usage_limit"`                     // 总使用上限（0 表示不限制）
+	UsedCount          int            `gorm:"not null;default:0" json:"used_count"`                      // 已使用次数
+	PerUserLimit       int            `gorm:"not null;default:0" json:"per_user_limit"`                  // 每人使用上限（0 表示不限制）
+	ScopeType          string         `gorm:"not null" json:"scope_type"`                                // 适用范围（product/category/sku/all）
+	ScopeRefIDs        string         `gorm:"type:text" json:"scope_ref_ids"`                            // 适用商品/分类/SKU ID 集合（JSON数组，all 时为空）
+	ExcludedProductIDs string         `gorm:"type:text" json:"excluded_product_ids"`                     // 排除商品ID集合（JSON数组）
+	UserIDs            string         `gorm:"type:text" json:"user_ids"`                                 // 限定用户ID集合（JSON数组，空表示不限）
+	MemberLevelIDs     string         `gorm:"type:text" json:"member_level_ids"`                         // 限定会员等级ID集合（JSON数组，空表示不限）
+	FirstOrderOnly     bool           `gorm:"not null;default:false" json:"first_order_only"`            // 是否仅限首单
+	StartsAt           *time.Time     `gorm:"index" json:"starts_at"`                                    // 生效时间
+	EndsAt             *time.Time     `gorm:"index" json:"ends_at"`                                      // 失效时间
+	IsActive           bool           `gorm:"not null;default:true" json:"is_active"`                    // 是否启用
+	CreatedAt          time.Time      `gorm:"index" json:"created_at"`                                   // 创建时间
+	UpdatedAt          time.Time      `gorm:"index" json:"updated_at"`                                   // 更新时间
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`                                            // 软删除时间
+	Batch              *CouponBatch   `gorm:"foreignKey:BatchID" json:"batch,omitempty"`                 // 批次信息
 }
 
 // TableName 指定表名
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CouponBatch 优惠码批次（模板），批次内每个优惠码为一张独立优惠券
+type CouponBatch struct {
+	ID                 uint           `gorm:"primarykey" json:"id"`                                      // 主键
+	BatchNo            string         `gorm:"type:varchar(48);uniqueIndex;not null" json:"batch_no"`     // 批次号
+	Name               string         `gorm:"type:varchar(120);not null" json:"name"`                    // 批次名称
+	CodePrefix         string         `gorm:"type:varchar(16)" json:"code_prefix"`                       // 优惠码前缀
+	Quantity           int            `gorm:"not null;default:0" json:"quantity"`                        // 生成数量
+	Type               string         `gorm:"not null" json:"type"`                                      // 类型（fixed/percent）
+	Value              Money          `gorm:"type:decimal(20,2);not null" json:"value"`                  // 数值（固定金额或百分比）
+	MinAmount          Money          `gorm:"type:decimal(20,2);not null;default:0" json:"min_amount"`   // 使用门槛
+	MaxDiscount        Money          `gorm:"type:decimal(20,2);not null;default:0" json:"max_discount"` // 最大优惠金额
+	UsageLimit         int            `gorm:"not null;default:1" json:"usage_limit"`                     // 单码使用上限
+	PerUserLimit       int            `gorm:"not null;default:0" json:"per_user_limit"`                  // 单码每人使用上限（0 表示不限制）
+	ScopeType          string         `gorm:"not null" json:"scope_type"`                                // 适用范围（product/category/sku/all）
+	ScopeRefIDs        string         `gorm:"type:text" json:"scope_ref_ids"`                            // 适用商品/分类/SKU ID 集合（JSON数组）
+	ExcludedProductIDs string         `gorm:"type:text" json:"excluded_product_ids"`                     // 排除商品ID集合（JSON数组）
+	UserIDs            string         `gorm:"type:text" json:"user_ids"`                                 // 限定用户ID集合（JSON数组）
+	MemberLevelIDs     string         `gorm:"type:text" json:"member_level_ids"`                         // 限定会员等级ID集合（JSON数组）
+	FirstOrderOnly     bool           `gorm:"not null;default:false" json:"first_order_only"`            // 是否仅限首单
+	StartsAt           *time.Time     `gorm:"index" json:"starts_at"`                                    // 生效时间
+	EndsAt             *time.Time     `gorm:"index" json:"ends_at"`                                      // 失效时间
+	IsActive           bool           `gorm:"not null;default:true" json:"is_active"`                    // 批次是否启用（停用后批次内优惠码均不可用）
+	CreatedBy          *uint          `gorm:"index" json:"created_by,omitempty"`                         // 创建管理员ID
+	CreatedAt          time.Time      `gorm:"index" json:"created_at"`                                   // 创建时间
+	UpdatedAt          time.Time      `gorm:"index" json:"updated_at"`                                   // 更新时间
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`                                            // 软删除时间
+}
+
+// TableName 指定表名
+func (CouponBatch) TableName() string {
+	return "coupon_batches"
+}
@@ -80,6 +80,8 @@ func AutoMigrate() error {
 	if err := DB.AutoMigrate(
 		&Admin{},
 		&User{},
+		&MemberLevel{},
+		&MemberLevelPrice{},
 		&UserOAuthIdentity{},
 		&AffiliateProfile{},
 		&AffiliateClick{},
@@ -101,12 +103,26 @@ func AutoMigrate() error {
 		&GiftCard{},
 		&GiftCardBatch{},
 		&Fulfillment{},
+		&FulfillmentBatch{},
+		&ProductFile{},
+		&OrderDownload{},
+		&OrderDownloadLog{},
+		&OrderSecretReveal{},
+		&ProductSearchDocument{},
+		&OrderInvoice{},
+		&OrderMessage{},
+		&OrderMessageThread{},
+		&FulfillmentSupplier{},
 		&Coupon{},
+		&CouponBatch{},
 		&CouponUsage{},
 		&Promotion{},
 		&Category{},
 		&Product{},
 		&ProductSKU{},
+		&ProductReview{},
+		&ProductRevision{},
+		&StockSubscription{},
 		&Post{},
 		&Banner{},
 		&Setting{},
@@ -124,6 +140,9 @@ func AutoMigrate() error {
 	if err := ensureManualStockRemainingMigration(); err != nil {
 		return err
 	}
+	if err := ensureProductSearchIndex(); err != nil {
+		return err
+	}
 
 	// 移除历史遗留商品币种列，统一由站点配置提供币种。
 	if DB.Migrator().HasColumn(&Product{}, "price_currency") {
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+)
+
+// FieldCipher 敏感字段加解密接口（卡密、交付内容）
+type FieldCipher interface {
+	ActiveKeyID() string
+	Encrypt(plaintext string) (string, string, error)
+	Decrypt(ciphertext, keyID string) (string, error)
+	Hash(label, data string) (string, string)
+	HashAll(label, data string) []string
+}
+
+var errFieldCipherMissing = errors.New("field cipher not configured")
+
+var (
+	fieldCipherMu sync.RWMutex
+	fieldCipher   FieldCipher
+)
+
+// SetFieldCipher 注册敏感字段加解密实现，nil 表示关闭加密写入
+func SetFieldCipher(cipher FieldCipher) {
+	fieldCipherMu.Lock()
+	defer fieldCipherMu.Unlock()
+	fieldCipher = cipher
+}
+
+func currentFieldCipher() FieldCipher {
+	fieldCipherMu.RLock()
+	defer fieldCipherMu.RUnlock()
+	return fieldCipher
+}
+
+// encryptField 写库前加密，返回密文与密钥 ID；未配置加密时保持明文
+func encryptField(plaintext string) (string, string, error) {
+	cipher := currentFieldCipher()
+	if plaintext == "" || cipher == nil || cipher.ActiveKeyID() == "" {
+		return plaintext, "", nil
+	}
+	return cipher.Encrypt(plaintext)
+}
+
+// decryptField 读库后解密，keyID 为空视为历史明文
+func decryptField(value, keyID string) (string, error) {
+	if keyID == "" || value == "" {
+		return value, nil
+	}
+	cipher := currentFieldCipher()
+	if cipher == nil {
+		return "", errFieldCipherMissing
+	}
+	return cipher.Decrypt(value, keyID)
+}
+
+// hashField 计算内容哈希，返回哈希与密钥 ID：启用加密时使用当前密钥派生的 HMAC，未启用时为 sha256（此时内容本身即明文落库）
+func hashField(label, value string) (string, string) {
+	cipher := currentFieldCipher()
+	if cipher != nil && cipher.ActiveKeyID() != "" {
+		return cipher.Hash(label, value)
+	}
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:]), ""
+}
+
+// lookupHashes 返回内容可能存在的全部哈希：密钥环内每把密钥的 HMAC 以及未加密时的 sha256
+// 查找与查重使用，避免密钥轮换或哈希回填完成前按旧密钥写入的数据漏判
+func lookupHashes(label, value string) []string {
+	var hashes []string
+	if cipher := currentFieldCipher(); cipher != nil {
+		hashes = cipher.HashAll(label, value)
+	}
+	sum := sha256.Sum256([]byte(value))
+	return append(hashes, hex.EncodeToString(sum[:]))
+}
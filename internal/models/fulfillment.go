@@ -8,20 +8,66 @@ import (
 
 // Fulfillment 交付记录表
 type Fulfillment struct {
-	ID            uint           `gorm:"primarykey" json:"id"`                 // 主键
-	OrderID       uint           `gorm:"uniqueIndex;not null" json:"order_id"` // 订单ID
-	Type          string         `gorm:"not null" json:"type"`                 // 交付类型（auto/manual）
-	Status        string         `gorm:"not null" json:"status"`               // 交付状态（pending/delivered）
-	Payload       string         `gorm:"type:text" json:"payload"`             // 交付内容
-	LogisticsJSON JSON           `gorm:"type:json" json:"delivery_data"`       // 结构化交付信息
-	DeliveredBy   *uint          `gorm:"index" json:"delivered_by,omitempty"`  // 交付管理员ID
-	DeliveredAt   *time.Time     `gorm:"index" json:"delivered_at,omitempty"`  // 交付时间
-	CreatedAt     time.Time      `gorm:"index" json:"created_at"`              // 创建时间
-	UpdatedAt     time.Time      `gorm:"index" json:"updated_at"`              // 更新时间
-	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`                       // 软删除时间
+	ID            uint           `gorm:"primarykey" json:"id"`                       // 主键
+	OrderID       uint           `gorm:"uniqueIndex;not null" json:"order_id"`       // 订单ID
+	Type          string         `gorm:"not null" json:"type"`                       // 交付类型（auto/manual/api）
+	Status        string         `gorm:"not null" json:"status"`                     // 交付状态（pending/partial/delivered）
+	Payload       string         `gorm:"type:text" json:"payload"`                   // 交付内容（落库时加密）
+	PayloadKeyID  string         `gorm:"size:64;index;not null;default:''" json:"-"` // 加密密钥ID（空表示明文）
+	LogisticsJSON JSON           `gorm:"type:json" json:"delivery_data"`             // 结构化交付信息
+	TotalQty      int            `gorm:"default:0" json:"total_quantity"`            // 应交付数量
+	DeliveredQty  int            `gorm:"default:0" json:"delivered_quantity"`        // 已交付数量
+	Rendered      string         `gorm:"-" json:"rendered_content,omitempty"`        // 按交付模板渲染的内容（仅结构，不写入数据库）
+	RevealCount   int            `gorm:"not null;default:0" json:"reveal_count"`     // 买家查看次数
+	RevealedAt    *time.Time     `json:"revealed_at,omitempty"`                      // 买家首次查看时间
+	Hidden        bool           `gorm:"-" json:"hidden,omitempty"`                  // 内容已隐藏，需主动查看（仅结构，不写入数据库）
+	DeliveredBy   *uint          `gorm:"index" json:"delivered_by,omitempty"`        // 交付管理员ID
+	DeliveredAt   *time.Time     `gorm:"index" json:"delivered_at,omitempty"`        // 交付时间
+	CreatedAt     time.Time      `gorm:"index" json:"created_at"`                    // 创建时间
+	UpdatedAt     time.Time      `gorm:"index" json:"updated_at"`                    // 更新时间
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`                             // 软删除时间
+
+	Batches   []FulfillmentBatch `gorm:"foreignKey:FulfillmentID" json:"batches,omitempty"` // 分批交付记录
+	Downloads []OrderDownload    `gorm:"-" json:"downloads,omitempty"`                      // 文件下载授权（含签名地址，按需填充）
+
+	plainPayload string
 }
 
 // TableName 指定表名
 func (Fulfillment) TableName() string {
 	return "fulfillments"
 }
+
+// BeforeSave 写库前加密交付内容
+func (f *Fulfillment) BeforeSave(tx *gorm.DB) error {
+	if f.Payload == "" {
+		return nil
+	}
+	ciphertext, keyID, err := encryptField(f.Payload)
+	if err != nil {
+		return err
+	}
+	f.plainPayload = f.Payload
+	f.Payload = ciphertext
+	f.PayloadKeyID = keyID
+	return nil
+}
+
+// AfterSave 写库后恢复内存中的明文
+func (f *Fulfillment) AfterSave(tx *gorm.DB) error {
+	if f.plainPayload != "" {
+		f.Payload = f.plainPayload
+		f.plainPayload = ""
+	}
+	return nil
+}
+
+// AfterFind 读库后解密交付内容
+func (f *Fulfillment) AfterFind(tx *gorm.DB) error {
+	plaintext, err := decryptField(f.Payload, f.PayloadKeyID)
+	if err != nil {
+		return err
+	}
+	f.Payload = plaintext
+	return nil
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FulfillmentBatch 分批交付记录表（人工订单按数量多次交付）
+type FulfillmentBatch struct {
+	ID            uint           `gorm:"primarykey" json:"id"`                       // 主键
+	FulfillmentID uint           `gorm:"index;not null" json:"fulfillment_id"`       // 交付记录ID
+	OrderID       uint           `gorm:"index;not null" json:"order_id"`             // 订单ID
+	Quantity      int            `gorm:"not null;default:0" json:"quantity"`         // 本批交付数量
+	Payload       string         `gorm:"type:text" json:"payload"`                   // 本批交付内容（落库时加密）
+	PayloadKeyID  string         `gorm:"size:64;index;not null;default:''" json:"-"` // 加密密钥ID（空表示明文）
+	LogisticsJSON JSON           `gorm:"type:json" json:"delivery_data"`             // 本批结构化交付信息
+	DeliveredBy   *uint          `gorm:"index" json:"delivered_by,omitempty"`        // 交付管理员ID
+	DeliveredAt   time.Time      `gorm:"index" json:"delivered_at"`                  // 交付时间
+	CreatedAt     time.Time      `gorm:"index" json:"created_at"`                    // 创建时间
+	UpdatedAt     time.Time      `gorm:"index" json:"updated_at"`                    // 更新时间
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`                             // 软删除时间
+
+	plainPayload string
+}
+
+// TableName 指定表名
+func (FulfillmentBatch) TableName() string {
+	return "fulfillment_batches"
+}
+
+// BeforeSave 写库前加密本批交付内容
+func (b *FulfillmentBatch) BeforeSave(tx *gorm.DB) error {
+	if b.Payload == "" {
+		return nil
+	}
+	ciphertext, keyID, err := encryptField(b.Payload)
+	if err != nil {
+		return err
+	}
+	b.plainPayload = b.Payload
+	b.Payload = ciphertext
+	b.PayloadKeyID = keyID
+	return nil
+}
+
+// AfterSave 写库后恢复内存中的明文
+func (b *FulfillmentBatch) AfterSave(tx *gorm.DB) error {
+	if b.plainPayload != "" {
+		b.Payload = b.plainPayload
+		b.plainPayload = ""
+	}
+	return nil
+}
+
+// AfterFind 读库后解密本批交付内容
+func (b *FulfillmentBatch) AfterFind(tx *gorm.DB) error {
+	plaintext, err := decryptField(b.Payload, b.PayloadKeyID)
+	if err != nil {
+		return err
+	}
+	b.Payload = plaintext
+	return nil
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FulfillmentSupplier 上游供应商（接口交付）
+type FulfillmentSupplier struct {
+	ID             uint           `gorm:"primarykey" json:"id"`                                // 主键
+	Name           string         `gorm:"type:varchar(120);not null;index" json:"name"`        // 供应商名称
+	Endpoint       string         `gorm:"type:varchar(1000);not null" json:"endpoint"`         // 下单接口地址
+	SigningSecret  string         `gorm:"type:varchar(255);not null" json:"-"`                 // HMAC 签名密钥
+	TimeoutSeconds int            `gorm:"not null;default:10" json:"timeout_seconds"`          // 单次请求超时（秒）
+	MaxAttempts    int            `gorm:"not null;default:3" json:"max_attempts"`              // 最大尝试次数
+	IsActive       bool           `gorm:"not null;default:true;index" json:"is_active"`        // 是否启用
+	Remark         string         `gorm:"type:varchar(500);not null;default:''" json:"remark"` // 备注
+	CreatedAt      time.Time      `gorm:"index" json:"created_at"`                             // 创建时间
+	UpdatedAt      time.Time      `json:"updated_at"`                                          // 更新时间
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`                                      // 软删除时间
+
+	HasSigningSecret bool `gorm:"-" json:"has_signing_secret"` // 是否已配置签名密钥（仅响应）
+}
+
+// TableName 指定表名
+func (FulfillmentSupplier) TableName() string {
+	return "fulfillment_suppliers"
+}
+
+// AfterFind 标记是否已配置签名密钥
+func (s *FulfillmentSupplier) AfterFind(tx *gorm.DB) error {
+	s.HasSigningSecret = s.SigningSecret != ""
+	return nil
+}
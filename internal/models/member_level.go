@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemberLevel 会员等级
+type MemberLevel struct {
+	ID              uint           `gorm:"primarykey" json:"id"`                                          // 主键
+	Code            string         `gorm:"uniqueIndex;not null" json:"code"`                              // 等级编码（如 regular/vip/reseller）
+	NameJSON        JSON           `gorm:"type:json" json:"name"`                                         // 多语言名称
+	DiscountPercent Money          `gorm:"type:decimal(20,2);not null;default:0" json:"discount_percent"` // 折扣百分比（0 表示不打折）
+	MinSpend        Money          `gorm:"type:decimal(20,2);not null;default:0" json:"min_spend"`        // 自动升级所需累计消费
+	AutoUpgrade     bool           `gorm:"not null;default:false" json:"auto_upgrade"`                    // 是否参与按累计消费自动升级
+	IsDefault       bool           `gorm:"not null;default:false" json:"is_default"`                      // 是否为未分配用户的默认等级
+	IsActive        bool           `gorm:"not null;default:true" json:"is_active"`                        // 是否启用
+	SortOrder       int            `gorm:"not null;default:0" json:"sort_order"`                          // 排序
+	CreatedAt       time.Time      `gorm:"index" json:"created_at"`                                       // 创建时间
+	UpdatedAt       time.Time      `gorm:"index" json:"updated_at"`                                       // 更新时间
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`                                                // 软删除时间
+
+	Prices []MemberLevelPrice `gorm:"foreignKey:MemberLevelID" json:"prices,omitempty"` // SKU 专属价
+}
+
+// TableName 指定表名
+func (MemberLevel) TableName() string {
+	return "member_levels"
+}
+
+// MemberLevelPrice 会员等级 SKU 专属价
+type MemberLevelPrice struct {
+	ID            uint      `gorm:"primarykey" json:"id"`                                                        // 主键
+	MemberLevelID uint      `gorm:"uniqueIndex:idx_member_level_price_sku;not null" json:"member_level_id"`      // 会员等级ID
+	SKUID         uint      `gorm:"column:sku_id;uniqueIndex:idx_member_level_price_sku;not null" json:"sku_id"` // SKU ID
+	PriceAmount   Money     `gorm:"type:decimal(20,2);not null" json:"price_amount"`                             // 专属单价
+	CreatedAt     time.Time `json:"created_at"`                                                                  // 创建时间
+	UpdatedAt     time.Time `json:"updated_at"`                                                                  // 更新时间
+}
+
+// TableName 指定表名
+func (MemberLevelPrice) TableName() string {
+	return "member_level_prices"
+}
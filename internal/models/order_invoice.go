@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// OrderInvoice 订单收据/发票表
+type OrderInvoice struct {
+	ID        uint      `gorm:"primarykey" json:"id"`                   // 主键
+	OrderID   uint      `gorm:"uniqueIndex;not null" json:"order_id"`   // 订单ID
+	Sequence  int64     `gorm:"uniqueIndex;not null" json:"sequence"`   // 顺序编号
+	InvoiceNo string    `gorm:"uniqueIndex;not null" json:"invoice_no"` // 发票号
+	IssuedAt  time.Time `gorm:"index;not null" json:"issued_at"`        // 开具时间
+	CreatedAt time.Time `gorm:"index" json:"created_at"`                // 创建时间
+	UpdatedAt time.Time `gorm:"index" json:"updated_at"`                // 更新时间
+}
+
+// TableName 指定表名
+func (OrderInvoice) TableName() string {
+	return "order_invoices"
+}
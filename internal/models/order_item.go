@@ -14,6 +14,7 @@ type OrderItem struct {
 	SKUID                        uint           `gorm:"column:sku_id;index;not null;default:0" json:"sku_id"`                   // SKU ID
 	TitleJSON                    JSON           `gorm:"type:json;not null" json:"title"`                                        // 商品标题快照
 	SKUSnapshotJSON              JSON           `gorm:"type:json" json:"sku_snapshot"`                                          // SKU 快照（编码/规格）
+	PricingSnapshotJSON          JSON           `gorm:"type:json" json:"pricing_snapshot,omitempty"`                            // 定价快照（原价/阶梯起购数量/会员等级与会员价）
 	Tags                         StringArray    `gorm:"type:json" json:"tags"`                                                  // 标签快照
 	UnitPrice                    Money          `gorm:"type:decimal(20,2);not null;default:0" json:"unit_price"`                // 单价
 	Quantity                     int            `gorm:"not null" json:"quantity"`                                               // 数量
@@ -24,6 +25,9 @@ type OrderItem struct {
 	PromotionName                string         `gorm:"-" json:"promotion_name,omitempty"`                                      // 活动价名称
 	FulfillmentType              string         `gorm:"not null" json:"fulfillment_type"`                                       // 交付类型
 	ManualFormSchemaSnapshotJSON JSON           `gorm:"type:json" json:"manual_form_schema_snapshot"`                           // 人工交付表单 schema 快照
+	DeliveryTmplSnapshotJSON     JSON           `gorm:"type:json" json:"-"`                                                     // 交付内容模板快照（下单时的 SKU/商品模板）
+	RevealRequired               bool           `gorm:"not null;default:false" json:"reveal_required"`                          // 交付内容需主动查看（下单时快照）
+	RevealMaxViews               int            `gorm:"not null;default:0" json:"reveal_max_views"`                             // 最多可查看次数快照（0 不限制）
 	ManualFormSubmissionJSON     JSON           `gorm:"type:json" json:"manual_form_submission"`                                // 人工交付表单提交值
 	CreatedAt                    time.Time      `gorm:"index" json:"created_at"`                                                // 创建时间
 	UpdatedAt                    time.Time      `gorm:"index" json:"updated_at"`                                                // 更新时间
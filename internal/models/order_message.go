@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OrderMessage 订单沟通消息表
+type OrderMessage struct {
+	ID             uint           `gorm:"primarykey" json:"id"`                         // 主键
+	OrderID        uint           `gorm:"index;not null" json:"order_id"`               // 订单ID（父订单）
+	SenderType     string         `gorm:"type:varchar(20);not null" json:"sender_type"` // 发送方类型（user/guest/admin）
+	SenderID       uint           `gorm:"index;not null;default:0" json:"sender_id"`    // 发送方ID（游客为 0）
+	Content        string         `gorm:"type:text" json:"content"`                     // 消息内容
+	Attachments    StringArray    `gorm:"type:json" json:"-"`                           // 附件私有存储 key 列表（旧数据为 /uploads 公开地址）
+	AttachmentURLs []string       `gorm:"-" json:"attachments"`                         // 附件访问地址（带签名，按需填充）
+	CreatedAt      time.Time      `gorm:"index" json:"created_at"`                      // 创建时间
+	UpdatedAt      time.Time      `gorm:"index" json:"updated_at"`                      // 更新时间
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`                               // 软删除时间
+}
+
+// TableName 指定表名
+func (OrderMessage) TableName() string {
+	return "order_messages"
+}
+
+// OrderMessageThread 订单沟通会话表（未读计数）
+type OrderMessageThread struct {
+	ID               uint       `gorm:"primarykey" json:"id"`                               // 主键
+	OrderID          uint       `gorm:"uniqueIndex;not null" json:"order_id"`               // 订单ID（父订单）
+	OrderNo          string     `gorm:"index" json:"order_no"`                              // 订单编号快照
+	MessageCount     int        `gorm:"not null;default:0" json:"message_count"`            // 消息总数
+	BuyerUnreadCount int        `gorm:"not null;default:0" json:"buyer_unread_count"`       // 买家未读数
+	AdminUnreadCount int        `gorm:"index;not null;default:0" json:"admin_unread_count"` // 管理员未读数
+	LastSenderType   string     `gorm:"type:varchar(20)" json:"last_sender_type"`           // 最后发送方类型
+	LastMessageAt    *time.Time `gorm:"index" json:"last_message_at"`                       // 最后消息时间
+	CreatedAt        time.Time  `gorm:"index" json:"created_at"`                            // 创建时间
+	UpdatedAt        time.Time  `gorm:"index" json:"updated_at"`                            // 更新时间
+}
+
+// TableName 指定表名
+func (OrderMessageThread) TableName() string {
+	return "order_message_threads"
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// OrderSecretReveal 交付内容查看记录表（买家主动查看的凭证）
+type OrderSecretReveal struct {
+	ID            uint      `gorm:"primarykey" json:"id"`                    // 主键
+	OrderID       uint      `gorm:"index;not null" json:"order_id"`          // 订单ID（子订单）
+	FulfillmentID uint      `gorm:"index;not null" json:"fulfillment_id"`    // 交付记录ID
+	UserID        uint      `gorm:"index;not null;default:0" json:"user_id"` // 查看用户ID（游客为 0）
+	ViewNo        int       `gorm:"not null;default:0" json:"view_no"`       // 第几次查看
+	ClientIP      string    `gorm:"type:varchar(64)" json:"client_ip"`       // 查看IP
+	UserAgent     string    `gorm:"type:varchar(512)" json:"user_agent"`     // 查看 User-Agent
+	CreatedAt     time.Time `gorm:"index" json:"created_at"`                 // 查看时间
+}
+
+// TableName 指定表名
+func (OrderSecretReveal) TableName() string {
+	return "order_secret_reveals"
+}
@@ -19,17 +19,30 @@ type Product struct {
 	Images               StringArray    `gorm:"type:json" json:"images"`                                            // 图片数组
 	Tags                 StringArray    `gorm:"type:json" json:"tags"`                                              // 标签数组
 	PurchaseType         string         `gorm:"type:varchar(20);not null;default:'member'" json:"purchase_type"`    // 购买身份（guest/member）
-	FulfillmentType      string         `gorm:"type:varchar(20);not null;default:'manual'" json:"fulfillment_type"` // 交付类型（auto/manual）
+	Visibility           string         `gorm:"type:varchar(20);not null;default:'public';index" json:"visibility"` // 可见性（public/unlisted/protected）
+	AccessCode           string         `gorm:"size:64" json:"access_code,omitempty"`                               // 访问码（protected 可见性使用，前台响应中清除）
+	FulfillmentType      string         `gorm:"type:varchar(20);not null;default:'manual'" json:"fulfillment_type"` // 交付类型（auto/manual/api/file）
 	ManualFormSchemaJSON JSON           `gorm:"type:json" json:"manual_form_schema"`                                // 人工交付表单 schema
+	DeliveryTmplJSON     JSON           `gorm:"type:json" json:"delivery_template"`                                 // 交付内容模板（多语言正文与使用说明）
+	SecretSchemaJSON     JSON           `gorm:"type:json" json:"secret_field_schema"`                               // 卡密字段 schema（自动交付）
+	SpecSchemaJSON       JSON           `gorm:"type:json" json:"spec_schema"`                                       // 规格定义（多语言规格名与可选值，配置后 SKU 规格值按此校验）
+	KeyGeneratorJSON     JSON           `gorm:"type:json" json:"key_generator"`                                     // 卡密生成器配置（自动交付，配置后按需生成，不占库存）
+	SupplierID           *uint          `gorm:"index" json:"supplier_id,omitempty"`                                 // 上游供应商ID（接口交付）
+	SupplierProductCode  string         `gorm:"size:120" json:"supplier_product_code,omitempty"`                    // 上游商品编码
 	ManualStockTotal     int            `gorm:"not null;default:0" json:"manual_stock_total"`                       // 手动剩余库存（-1 表示无限库存，>=0 表示当前可售数量）
 	ManualStockLocked    int            `gorm:"not null;default:0" json:"manual_stock_locked"`                      // 手动库存占用量（待支付）
 	ManualStockSold      int            `gorm:"not null;default:0" json:"manual_stock_sold"`                        // 手动库存已售量（支付成功后累加）
+	RevealRequired       bool           `gorm:"not null;default:false" json:"reveal_required"`                      // 交付内容需买家主动查看（记录查看凭证）
+	RevealMaxViews       int            `gorm:"not null;default:0" json:"reveal_max_views"`                         // 最多可查看次数（0 不限制，需开启 reveal_required）
 	IsAffiliateEnabled   bool           `gorm:"not null;default:false;index" json:"is_affiliate_enabled"`           // 是否参与推广返利
 	AutoStockAvailable   int64          `gorm:"-" json:"auto_stock_available"`                                      // 自动发货库存可用量（仅结构，不写入数据库）
 	AutoStockTotal       int64          `gorm:"-" json:"auto_stock_total"`                                          // 自动发货库存总量（仅结构，不写入数据库）
 	AutoStockLocked      int64          `gorm:"-" json:"auto_stock_locked"`                                         // 自动发货库存占用量（仅结构，不写入数据库）
 	AutoStockSold        int64          `gorm:"-" json:"auto_stock_sold"`                                           // 自动发货库存已售量（仅结构，不写入数据库）
 	IsActive             bool           `gorm:"default:true;index" json:"is_active"`                                // 是否上架
+	PublishAt            *time.Time     `gorm:"index" json:"publish_at"`                                            // 定时上架时间（为空表示立即可见）
+	UnpublishAt          *time.Time     `gorm:"index" json:"unpublish_at"`                                          // 定时下架时间（为空表示不自动下架）
+	PublishState         string         `gorm:"-" json:"publish_state,omitempty"`                                   // 上架状态（仅后台展示，不写入数据库）
 	SortOrder            int            `gorm:"default:0;index" json:"sort_order"`                                  // 排序权重
 	CreatedAt            time.Time      `gorm:"index" json:"created_at"`                                            // 创建时间
 	UpdatedAt            time.Time      `json:"updated_at"`                                                         // 更新时间
@@ -44,3 +57,19 @@ type Product struct {
 func (Product) TableName() string {
 	return "products"
 }
+
+// IsPublishedAt 判断商品在指定时间是否对外可见（已上架且处于定时上下架窗口内）
+func (p *Product) IsPublishedAt(now time.Time) bool {
+	return p != nil && p.IsActive && InPublishWindow(p.PublishAt, p.UnpublishAt, now)
+}
+
+// InPublishWindow 判断时间点是否处于定时上下架窗口内，空值表示不限制
+func InPublishWindow(publishAt, unpublishAt *time.Time, now time.Time) bool {
+	if publishAt != nil && now.Before(*publishAt) {
+		return false
+	}
+	if unpublishAt != nil && !now.Before(*unpublishAt) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProductFile 商品交付文件表（文件交付类型）
+type ProductFile struct {
+	ID          uint           `gorm:"primarykey" json:"id"`                                 // 主键
+	ProductID   uint           `gorm:"index;not null" json:"product_id"`                     // 商品ID
+	SKUID       uint           `gorm:"column:sku_id;index;not null;default:0" json:"sku_id"` // SKU ID（0 表示商品所有 SKU 共用）
+	Name        string         `gorm:"type:varchar(255);not null" json:"name"`               // 原始文件名
+	StorageKey  string         `gorm:"type:varchar(255);not null" json:"-"`                  // 私有存储相对路径
+	Size        int64          `gorm:"not null;default:0" json:"size"`                       // 文件大小（字节）
+	ContentType string         `gorm:"type:varchar(120)" json:"content_type"`                // MIME 类型
+	SortOrder   int            `gorm:"not null;default:0" json:"sort_order"`                 // 排序
+	CreatedAt   time.Time      `gorm:"index" json:"created_at"`                              // 创建时间
+	UpdatedAt   time.Time      `gorm:"index" json:"updated_at"`                              // 更新时间
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`                                       // 软删除时间
+}
+
+// TableName 指定表名
+func (ProductFile) TableName() string {
+	return "product_files"
+}
+
+// OrderDownload 订单文件下载授权表（每个订单每个文件一条）
+type OrderDownload struct {
+	ID             uint       `gorm:"primarykey" json:"id"`                                         // 主键
+	OrderID        uint       `gorm:"uniqueIndex:idx_order_download_file;not null" json:"order_id"` // 订单ID（子订单）
+	ProductFileID  uint       `gorm:"uniqueIndex:idx_order_download_file;not null" json:"file_id"`  // 商品文件ID
+	FileName       string     `gorm:"type:varchar(255);not null" json:"file_name"`                  // 文件名快照
+	FileSize       int64      `gorm:"not null;default:0" json:"file_size"`                          // 文件大小快照
+	MaxDownloads   int        `gorm:"not null;default:0" json:"max_downloads"`                      // 下载次数上限（0 不限制）
+	DownloadCount  int        `gorm:"not null;default:0" json:"download_count"`                     // 已下载次数
+	LastDownloadAt *time.Time `json:"last_download_at,omitempty"`                                   // 最后下载时间
+	URL            string     `gorm:"-" json:"url,omitempty"`                                       // 签名下载地址（仅结构，不写入数据库）
+	URLExpiresAt   *time.Time `gorm:"-" json:"url_expires_at,omitempty"`                            // 下载地址过期时间
+	CreatedAt      time.Time  `gorm:"index" json:"created_at"`                                      // 创建时间
+	UpdatedAt      time.Time  `gorm:"index" json:"updated_at"`                                      // 更新时间
+}
+
+// TableName 指定表名
+func (OrderDownload) TableName() string {
+	return "order_downloads"
+}
+
+// OrderDownloadLog 订单文件下载日志表
+type OrderDownloadLog struct {
+	ID              uint      `gorm:"primarykey" json:"id"`                    // 主键
+	OrderDownloadID uint      `gorm:"index;not null" json:"order_download_id"` // 下载授权ID
+	OrderID         uint      `gorm:"index;not null" json:"order_id"`          // 订单ID
+	ProductFileID   uint      `gorm:"index;not null" json:"file_id"`           // 商品文件ID
+	ClientIP        string    `gorm:"type:varchar(64)" json:"client_ip"`       // 下载IP
+	UserAgent       string    `gorm:"type:varchar(512)" json:"user_agent"`     // 下载 User-Agent
+	CreatedAt       time.Time `gorm:"index" json:"created_at"`                 // 下载时间
+}
+
+// TableName 指定表名
+func (OrderDownloadLog) TableName() string {
+	return "order_download_logs"
+}
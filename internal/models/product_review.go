@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProductReview 商品评价（每个已完成订单项一条）
+type ProductReview struct {
+	ID          uint           `gorm:"primarykey" json:"id"`                           // 主键
+	ProductID   uint           `gorm:"index;not null" json:"product_id"`               // 商品ID
+	SKUID       uint           `gorm:"column:sku_id;not null;default:0" json:"sku_id"` // SKU ID
+	OrderID     uint           `gorm:"index;not null" json:"order_id"`                 // 订单ID（订单项所属订单）
+	OrderItemID uint           `gorm:"uniqueIndex;not null" json:"order_item_id"`      // 订单项ID
+	UserID      uint           `gorm:"index;not null" json:"user_id"`                  // 评价用户ID
+	Rating      int            `gorm:"not null" json:"rating"`                         // 评分（1-5）
+	Content     string         `gorm:"type:text" json:"content"`                       // 评价内容
+	SKUSnapshot JSON           `gorm:"type:json" json:"sku_snapshot"`                  // 购买时 SKU 快照
+	Status      string         `gorm:"index;not null;default:'pending'" json:"status"` // 状态（pending/published/hidden）
+	AdminReply  string         `gorm:"type:text" json:"admin_reply"`                   // 商家回复
+	RepliedAt   *time.Time     `json:"replied_at"`                                     // 回复时间
+	CreatedAt   time.Time      `gorm:"index" json:"created_at"`                        // 创建时间
+	UpdatedAt   time.Time      `gorm:"index" json:"updated_at"`                        // 更新时间
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`                                 // 软删除时间
+
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"` // 评价用户
+}
+
+// TableName 指定表名
+func (ProductReview) TableName() string {
+	return "product_reviews"
+}
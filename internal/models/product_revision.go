@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// ProductRevision 商品修订快照（每次后台更新商品后记录一份完整快照，含 SKU）
+type ProductRevision struct {
+	ID        uint      `gorm:"primarykey" json:"id"`             // 主键
+	ProductID uint      `gorm:"index;not null" json:"product_id"` // 商品ID
+	AdminID   uint      `gorm:"index" json:"admin_id"`            // 操作管理员ID（0 表示更新前的初始快照）
+	Snapshot  JSON      `gorm:"type:json" json:"snapshot"`        // 商品与 SKU 快照
+	CreatedAt time.Time `gorm:"index" json:"created_at"`          // 创建时间
+}
+
+// TableName 指定表名
+func (ProductRevision) TableName() string {
+	return "product_revisions"
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const productSearchBackfillBatch = 200
+
+// ProductSearchDocument 商品搜索文档表（多语言标题/描述/标签的归一化文本）
+type ProductSearchDocument struct {
+	ID        uint      `gorm:"primarykey" json:"id"`                   // 主键
+	ProductID uint      `gorm:"uniqueIndex;not null" json:"product_id"` // 商品ID
+	Content   string    `gorm:"type:text;not null" json:"content"`      // 归一化检索文本（小写）
+	UpdatedAt time.Time `gorm:"index" json:"updated_at"`                // 更新时间
+}
+
+// TableName 指定表名
+func (ProductSearchDocument) TableName() string {
+	return "product_search_documents"
+}
+
+// BuildProductSearchContent 生成商品检索文本：slug、各语言标题与描述、标签
+func BuildProductSearchContent(product *Product) string {
+	if product == nil {
+		return ""
+	}
+	parts := []string{product.Slug}
+	parts = append(parts, localizedJSONTexts(product.TitleJSON)...)
+	parts = append(parts, localizedJSONTexts(product.DescriptionJSON)...)
+	parts = append(parts, product.Tags...)
+	cleaned := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if text := strings.Join(strings.Fields(part), " "); text != "" {
+			cleaned = append(cleaned, strings.ToLower(text))
+		}
+	}
+	return strings.Join(cleaned, "\n")
+}
+
+func localizedJSONTexts(value JSON) []string {
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	texts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if text, ok := value[key].(string); ok {
+			texts = append(texts, text)
+		}
+	}
+	return texts
+}
+
+// IndexProductSearchDocument 写入商品搜索文档，SQLite 下同步刷新 FTS5 表（Postgres 由生成列维护）
+func IndexProductSearchDocument(db *gorm.DB, product *Product) error {
+	if db == nil || product == nil || product.ID == 0 {
+		return nil
+	}
+	doc := ProductSearchDocument{
+		ProductID: product.ID,
+		Content:   BuildProductSearchContent(product),
+		UpdatedAt: time.Now(),
+	}
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"content", "updated_at"}),
+	}).Create(&doc).Error; err != nil {
+		return err
+	}
+	if isPostgresDialect(db) {
+		return nil
+	}
+	if err := db.Exec("DELETE FROM product_search_fts WHERE product_id = ?", product.ID).Error; err != nil {
+		return err
+	}
+	return db.Exec("INSERT INTO product_search_fts (product_id, content) VALUES (?, ?)", product.ID, doc.Content).Error
+}
+
+// RemoveProductSearchDocument 删除商品搜索文档
+func RemoveProductSearchDocument(db *gorm.DB, productID uint) error {
+	if db == nil || productID == 0 {
+		return nil
+	}
+	if err := db.Where("product_id = ?", productID).Delete(&ProductSearchDocument{}).Error; err != nil {
+		return err
+	}
+	if isPostgresDialect(db) {
+		return nil
+	}
+	return db.Exec("DELETE FROM product_search_fts WHERE product_id = ?", productID).Error
+}
+
+func isPostgresDialect(db *gorm.DB) bool {
+	if db == nil || db.Dialector == nil {
+		return false
+	}
+	name := strings.ToLower(db.Dialector.Name())
+	return name == "postgres" || name == "postgresql"
+}
+
+// ensureProductSearchIndex 创建方言相关的全文索引结构（SQLite FTS5 / Postgres tsvector），并补齐缺失的搜索文档
+func ensureProductSearchIndex() error {
+	if isPostgresDialect(DB) {
+		if err := DB.Exec("ALTER TABLE product_search_documents ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (to_tsvector('simple', content)) STORED").Error; err != nil {
+			return err
+		}
+		if err := DB.Exec("CREATE INDEX IF NOT EXISTS idx_product_search_vector ON product_search_documents USING GIN (search_vector)").Error; err != nil {
+			return err
+		}
+	} else if err := DB.Exec("CREATE VIRTUAL TABLE IF NOT EXISTS product_search_fts USING fts5(product_id UNINDEXED, content, tokenize='trigram')").Error; err != nil {
+		return err
+	}
+	return backfillProductSearchDocuments()
+}
+
+// backfillProductSearchDocuments 为尚无搜索文档的商品补建索引（升级后首次启动）
+func backfillProductSearchDocuments() error {
+	for {
+		var products []Product
+		if err := DB.Where("id NOT IN (?)", DB.Model(&ProductSearchDocument{}).Select("product_id")).
+			Order("id asc").
+			Limit(productSearchBackfillBatch).
+			Find(&products).Error; err != nil {
+			return err
+		}
+		if len(products) == 0 {
+			return nil
+		}
+		for i := range products {
+			if err := IndexProductSearchDocument(DB, &products[i]); err != nil {
+				return fmt.Errorf("index product %d: %w", products[i].ID, err)
+			}
+		}
+	}
+}
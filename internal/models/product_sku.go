@@ -1,6 +1,8 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
 	"time"
 
 	"gorm.io/gorm"
@@ -17,7 +19,9 @@ type ProductSKU struct {
 	ProductID          uint           `gorm:"not null;index;uniqueIndex:idx_product_sku_code" json:"product_id"`                          // 商品ID
 	SKUCode            string         `gorm:"column:sku_code;type:varchar(64);not null;uniqueIndex:idx_product_sku_code" json:"sku_code"` // SKU编码（同商品内唯一）
 	SpecValuesJSON     JSON           `gorm:"type:json" json:"spec_values"`                                                               // 规格值（如颜色/版本）
+	DeliveryTmplJSON   JSON           `gorm:"type:json" json:"delivery_template"`                                                         // 交付内容模板（非空时覆盖商品模板）
 	PriceAmount        Money          `gorm:"type:decimal(20,2);not null;default:0" json:"price_amount"`                                  // SKU价格
+	PriceTiers         PriceTiers     `gorm:"type:json" json:"price_tiers"`                                                               // 数量阶梯价（按起购数量升序）
 	ManualStockTotal   int            `gorm:"not null;default:0" json:"manual_stock_total"`                                               // 手动剩余库存（-1 表示无限库存，>=0 表示当前可售数量）
 	ManualStockLocked  int            `gorm:"not null;default:0" json:"manual_stock_locked"`                                              // 手动库存占用量（待支付）
 	ManualStockSold    int            `gorm:"not null;default:0" json:"manual_stock_sold"`                                                // 手动库存已售量（支付成功后累加）
@@ -26,6 +30,8 @@ type ProductSKU struct {
 	AutoStockLocked    int64          `gorm:"-" json:"auto_stock_locked"`                                                                 // 自动发货库存占用量（仅结构，不写入数据库）
 	AutoStockSold      int64          `gorm:"-" json:"auto_stock_sold"`                                                                   // 自动发货库存已售量（仅结构，不写入数据库）
 	IsActive           bool           `gorm:"default:true;index" json:"is_active"`                                                        // 是否启用
+	PublishAt          *time.Time     `gorm:"index" json:"publish_at"`                                                                    // 定时上架时间（为空表示随商品可见）
+	UnpublishAt        *time.Time     `gorm:"index" json:"unpublish_at"`                                                                  // 定时下架时间（为空表示不自动下架）
 	SortOrder          int            `gorm:"default:0;index" json:"sort_order"`                                                          // 排序权重
 	CreatedAt          time.Time      `gorm:"index" json:"created_at"`                                                                    // 创建时间
 	UpdatedAt          time.Time      `gorm:"index" json:"updated_at"`                                                                    // 更新时间
@@ -38,3 +44,40 @@ type ProductSKU struct {
 func (ProductSKU) TableName() string {
 	return "product_skus"
 }
+
+// IsPublishedAt 判断 SKU 在指定时间是否可售（已启用且处于定时上下架窗口内）
+func (s *ProductSKU) IsPublishedAt(now time.Time) bool {
+	return s != nil && s.IsActive && InPublishWindow(s.PublishAt, s.UnpublishAt, now)
+}
+
+// PriceTier 数量阶梯价：购买数量达到 MinQuantity 时按 PriceAmount 计价
+type PriceTier struct {
+	MinQuantity int   `json:"min_quantity"`
+	PriceAmount Money `json:"price_amount"`
+}
+
+// PriceTiers 阶梯价列表
+type PriceTiers []PriceTier
+
+// Value 实现 driver.Valuer 接口
+func (t PriceTiers) Value() (driver.Value, error) {
+	if t == nil {
+		return nil, nil
+	}
+	return json.Marshal(t)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (t *PriceTiers) Scan(value interface{}) error {
+	switch typed := value.(type) {
+	case nil:
+		*t = PriceTiers{}
+		return nil
+	case []byte:
+		return json.Unmarshal(typed, t)
+	case string:
+		return json.Unmarshal([]byte(typed), t)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// StockSubscription 到货通知订阅（售罄 SKU 补货后按渠道通知一次）
+type StockSubscription struct {
+	ID         uint       `gorm:"primarykey" json:"id"`                                 // 主键
+	ProductID  uint       `gorm:"index;not null" json:"product_id"`                     // 商品ID
+	SKUID      uint       `gorm:"column:sku_id;index;not null;default:0" json:"sku_id"` // SKU ID
+	UserID     uint       `gorm:"index;not null;default:0" json:"user_id"`              // 用户ID（游客为 0）
+	Channel    string     `gorm:"type:varchar(20);not null" json:"channel"`             // 通知渠道（email/telegram）
+	Target     string     `gorm:"type:varchar(255);not null" json:"target"`             // 通知目标（邮箱或 Telegram chat id）
+	Locale     string     `gorm:"type:varchar(20)" json:"locale"`                       // 通知语言
+	Token      string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`       // 退订令牌
+	Status     string     `gorm:"index;not null;default:'pending'" json:"status"`       // 状态（pending/notified/unsubscribed）
+	NotifiedAt *time.Time `json:"notified_at"`                                          // 通知时间
+	CreatedAt  time.Time  `gorm:"index" json:"created_at"`                              // 创建时间
+	UpdatedAt  time.Time  `gorm:"index" json:"updated_at"`                              // 更新时间
+}
+
+// TableName 指定表名
+func (StockSubscription) TableName() string {
+	return "stock_subscriptions"
+}
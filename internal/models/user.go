@@ -8,20 +8,23 @@ import (
 
 // User 用户表
 type User struct {
-	ID                    uint           `gorm:"primarykey" json:"id"`              // 主键
-	Email                 string         `gorm:"uniqueIndex;not null" json:"email"` // 邮箱
-	PasswordHash          string         `gorm:"not null" json:"-"`                 // 密码哈希（不返回给前端）
-	PasswordSetupRequired bool           `gorm:"not null;default:false" json:"-"`   // 是否需要首次设置密码（Telegram 自动建号场景）
-	DisplayName           string         `gorm:"default:''" json:"display_name"`    // 昵称
-	Locale                string         `gorm:"default:'zh-CN'" json:"locale"`     // 语言偏好
-	Status                string         `gorm:"default:'active'" json:"status"`    // 账号状态
-	TokenVersion          uint64         `gorm:"not null;default:0" json:"-"`       // Token 版本（用于全量失效）
-	TokenInvalidBefore    *time.Time     `gorm:"index" json:"-"`                    // 该时间点前签发的 Token 失效
-	EmailVerifiedAt       *time.Time     `json:"email_verified_at"`                 // 邮箱验证时间
-	LastLoginAt           *time.Time     `json:"last_login_at"`                     // 最后登录时间
-	CreatedAt             time.Time      `gorm:"index" json:"created_at"`           // 创建时间
-	UpdatedAt             time.Time      `gorm:"index" json:"updated_at"`           // 更新时间
-	DeletedAt             gorm.DeletedAt `gorm:"index" json:"-"`                    // 软删除时间
+	ID                    uint           `gorm:"primarykey" json:"id"`                                     // 主键
+	Email                 string         `gorm:"uniqueIndex;not null" json:"email"`                        // 邮箱
+	PasswordHash          string         `gorm:"not null" json:"-"`                                        // 密码哈希（不返回给前端）
+	PasswordSetupRequired bool           `gorm:"not null;default:false" json:"-"`                          // 是否需要首次设置密码（Telegram 自动建号场景）
+	DisplayName           string         `gorm:"default:''" json:"display_name"`                           // 昵称
+	Locale                string         `gorm:"default:'zh-CN'" json:"locale"`                            // 语言偏好
+	Status                string         `gorm:"default:'active'" json:"status"`                           // 账号状态
+	TokenVersion          uint64         `gorm:"not null;default:0" json:"-"`                              // Token 版本（用于全量失效）
+	TokenInvalidBefore    *time.Time     `gorm:"index" json:"-"`                                           // 该时间点前签发的 Token 失效
+	EmailVerifiedAt       *time.Time     `json:"email_verified_at"`                                        // 邮箱验证时间
+	LastLoginAt           *time.Time     `json:"last_login_at"`                                            // 最后登录时间
+	MemberLevelID         uint           `gorm:"index;not null;default:0" json:"member_level_id"`          // 会员等级ID（0 表示使用默认等级）
+	MemberLevelLocked     bool           `gorm:"not null;default:false" json:"member_level_locked"`        // 管理员手动指定等级后不再自动调整
+	TotalSpent            Money          `gorm:"type:decimal(20,2);not null;default:0" json:"total_spent"` // 累计消费金额
+	CreatedAt             time.Time      `gorm:"index" json:"created_at"`                                  // 创建时间
+	UpdatedAt             time.Time      `gorm:"index" json:"updated_at"`                                  // 更新时间
+	DeletedAt             gorm.DeletedAt `gorm:"index" json:"-"`                                           // 软删除时间
 }
 
 // TableName 指定表名
@@ -1,9 +1,12 @@
 package provider
 
 import (
+	"strings"
+
 	"github.com/dujiao-next/internal/authz"
 	"github.com/dujiao-next/internal/cache"
 	"github.com/dujiao-next/internal/config"
+	"github.com/dujiao-next/internal/envelope"
 	"github.com/dujiao-next/internal/logger"
 	"github.com/dujiao-next/internal/models"
 	"github.com/dujiao-next/internal/queue"
@@ -13,8 +16,9 @@ import (
 
 // Container 依赖注入容器
 type Container struct {
-	Config      *config.Config
-	QueueClient *queue.Client
+	Config        *config.Config
+	QueueClient   *queue.Client
+	SecretKeyring *envelope.Keyring
 
 	// Repositories
 	AdminRepo             repository.AdminRepository
@@ -28,8 +32,17 @@ type Container struct {
 	CardSecretBatchRepo   repository.CardSecretBatchRepository
 	GiftCardRepo          repository.GiftCardRepository
 	FulfillmentRepo       repository.FulfillmentRepository
+	OrderInvoiceRepo      repository.OrderInvoiceRepository
+	OrderMessageRepo      repository.OrderMessageRepository
+	SupplierRepo          repository.FulfillmentSupplierRepository
+	ProductFileRepo       repository.ProductFileRepository
 	ProductRepo           repository.ProductRepository
+	ProductSearchRepo     repository.ProductSearchRepository
 	ProductSKURepo        repository.ProductSKURepository
+	MemberLevelRepo       repository.MemberLevelRepository
+	ProductReviewRepo     repository.ProductReviewRepository
+	ProductRevisionRepo   repository.ProductRevisionRepository
+	StockSubscriptionRepo repository.StockSubscriptionRepository
 	CartRepo              repository.CartRepository
 	CouponRepo            repository.CouponRepository
 	CouponUsageRepo       repository.CouponUsageRepository
@@ -45,32 +58,45 @@ type Container struct {
 	AffiliateRepo         repository.AffiliateRepository
 
 	// Services
-	AuthzService          *authz.Service
-	AuthService           *service.AuthService
-	UserAuthService       *service.UserAuthService
-	TelegramAuthService   *service.TelegramAuthService
-	EmailService          *service.EmailService
-	CaptchaService        *service.CaptchaService
-	UploadService         *service.UploadService
-	ProductService        *service.ProductService
-	PostService           *service.PostService
-	CategoryService       *service.CategoryService
-	SettingService        *service.SettingService
-	CartService           *service.CartService
-	WalletService         *service.WalletService
-	OrderService          *service.OrderService
-	FulfillmentService    *service.FulfillmentService
-	CouponAdminService    *service.CouponAdminService
-	PromotionAdminService *service.PromotionAdminService
-	BannerService         *service.BannerService
-	PaymentService        *service.PaymentService
-	CardSecretService     *service.CardSecretService
-	GiftCardService       *service.GiftCardService
-	UserLoginLogService   *service.UserLoginLogService
-	AuthzAuditService     *service.AuthzAuditService
-	DashboardService      *service.DashboardService
-	NotificationService   *service.NotificationService
-	AffiliateService      *service.AffiliateService
+	AuthzService             *authz.Service
+	AuthService              *service.AuthService
+	UserAuthService          *service.UserAuthService
+	TelegramAuthService      *service.TelegramAuthService
+	EmailService             *service.EmailService
+	CaptchaService           *service.CaptchaService
+	UploadService            *service.UploadService
+	ProductService           *service.ProductService
+	PostService              *service.PostService
+	SEOService               *service.SEOService
+	CategoryService          *service.CategoryService
+	SettingService           *service.SettingService
+	CartService              *service.CartService
+	WalletService            *service.WalletService
+	OrderService             *service.OrderService
+	FulfillmentService       *service.FulfillmentService
+	ReceiptService           *service.ReceiptService
+	DeliveryRenderService    *service.DeliveryRenderService
+	DownloadService          *service.DownloadService
+	SecretRevealService      *service.SecretRevealService
+	ProductSearchService     *service.ProductSearchService
+	MemberLevelService       *service.MemberLevelService
+	ProductReviewService     *service.ProductReviewService
+	LicenseService           *service.LicenseService
+	OrderMessageService      *service.OrderMessageService
+	SecretRotationService    *service.SecretRotationService
+	SupplierService          *service.SupplierService
+	CouponAdminService       *service.CouponAdminService
+	PromotionAdminService    *service.PromotionAdminService
+	BannerService            *service.BannerService
+	PaymentService           *service.PaymentService
+	CardSecretService        *service.CardSecretService
+	StockSubscriptionService *service.StockSubscriptionService
+	GiftCardService          *service.GiftCardService
+	UserLoginLogService      *service.UserLoginLogService
+	AuthzAuditService        *service.AuthzAuditService
+	DashboardService         *service.DashboardService
+	NotificationService      *service.NotificationService
+	AffiliateService         *service.AffiliateService
 }
 
 // NewContainer 初始化容器
@@ -79,6 +105,9 @@ func NewContainer(cfg *config.Config) *Container {
 	if err := cache.InitRedis(&cfg.Redis); err != nil {
 		logger.Warnw("provider_init_redis_failed", "error", err)
 	}
+	if err := cache.RegisterCatalogInvalidation(models.DB); err != nil {
+		logger.Warnw("provider_register_catalog_invalidation_failed", "error", err)
+	}
 
 	// 初始化队列客户端
 	var queueClient *queue.Client
@@ -96,6 +125,9 @@ func NewContainer(cfg *config.Config) *Container {
 		QueueClient: queueClient,
 	}
 
+	// 敏感字段加密需在任何读写前注册
+	c.initSecretKeyring()
+
 	// 1. 初始化 Repositories
 	c.initRepositories()
 
@@ -105,6 +137,20 @@ func NewContainer(cfg *config.Config) *Container {
 	return c
 }
 
+func (c *Container) initSecretKeyring() {
+	encryptionCfg := c.Config.Security.SecretEncryption
+	keyring, err := envelope.LoadKeyring(encryptionCfg.ActiveKeyID, encryptionCfg.Keys, encryptionCfg.KeyFile)
+	if err != nil {
+		logger.Errorw("provider_init_secret_keyring_failed", "error", err)
+		panic(err)
+	}
+	if !keyring.Enabled() {
+		logger.Warnw("provider_secret_encryption_disabled")
+	}
+	c.SecretKeyring = keyring
+	models.SetFieldCipher(keyring)
+}
+
 func (c *Container) initRepositories() {
 	db := models.DB
 	c.AdminRepo = repository.NewAdminRepository(db)
@@ -118,8 +164,17 @@ func (c *Container) initRepositories() {
 	c.CardSecretBatchRepo = repository.NewCardSecretBatchRepository(db)
 	c.GiftCardRepo = repository.NewGiftCardRepository(db)
 	c.FulfillmentRepo = repository.NewFulfillmentRepository(db)
+	c.OrderInvoiceRepo = repository.NewOrderInvoiceRepository(db)
+	c.OrderMessageRepo = repository.NewOrderMessageRepository(db)
+	c.SupplierRepo = repository.NewFulfillmentSupplierRepository(db)
+	c.ProductFileRepo = repository.NewProductFileRepository(db)
 	c.ProductRepo = repository.NewProductRepository(db)
+	c.ProductSearchRepo = repository.NewProductSearchRepository(db)
 	c.ProductSKURepo = repository.NewProductSKURepository(db)
+	c.MemberLevelRepo = repository.NewMemberLevelRepository(db)
+	c.ProductReviewRepo = repository.NewProductReviewRepository(db)
+	c.ProductRevisionRepo = repository.NewProductRevisionRepository(db)
+	c.StockSubscriptionRepo = repository.NewStockSubscriptionRepository(db)
 	c.CartRepo = repository.NewCartRepository(db)
 	c.CouponRepo = repository.NewCouponRepository(db)
 	c.CouponUsageRepo = repository.NewCouponUsageRepository(db)
@@ -176,14 +231,59 @@ func (c *Container) initServices() {
 	c.UserAuthService = service.NewUserAuthService(c.Config, c.UserRepo, c.UserOAuthIdentityRepo, c.EmailVerifyCodeRepo, c.EmailService, c.TelegramAuthService)
 	c.UploadService = service.NewUploadService(c.Config)
 	c.AffiliateService = service.NewAffiliateService(c.AffiliateRepo, c.UserRepo, c.OrderRepo, c.ProductRepo, c.SettingService)
-	c.ProductService = service.NewProductService(c.ProductRepo, c.ProductSKURepo, c.CardSecretRepo)
+	c.ProductService = service.NewProductService(c.ProductRepo, c.ProductSKURepo, c.CardSecretRepo, c.SupplierRepo)
+	c.ProductService.SetSearchRepository(c.ProductSearchRepo)
+	c.ProductService.SetRevisionRepository(c.ProductRevisionRepo)
 	c.PostService = service.NewPostService(c.PostRepo)
+	c.SEOService = service.NewSEOService(c.ProductRepo, c.PostRepo, c.SettingService, c.Config.SEO)
 	c.CategoryService = service.NewCategoryService(c.CategoryRepo)
 	c.CartService = service.NewCartService(c.CartRepo, c.ProductRepo, c.ProductSKURepo, c.PromotionRepo, c.SettingService)
 	c.WalletService = service.NewWalletService(c.WalletRepo, c.OrderRepo, c.UserRepo, c.AffiliateService)
 	c.OrderService = service.NewOrderService(c.OrderRepo, c.ProductRepo, c.ProductSKURepo, c.CardSecretRepo, c.CouponRepo, c.CouponUsageRepo, c.PromotionRepo, c.QueueClient, c.SettingService, c.WalletService, c.AffiliateService, c.Config.Order.PaymentExpireMinutes)
+	c.MemberLevelService = service.NewMemberLevelService(c.MemberLevelRepo, c.UserRepo, c.OrderRepo, c.ProductSKURepo)
+	c.CartService.SetMemberLevelService(c.MemberLevelService)
+	c.OrderService.SetMemberLevelService(c.MemberLevelService)
+	c.WalletService.SetMemberLevelService(c.MemberLevelService)
+	c.OrderService.SetCategoryRepository(c.CategoryRepo)
 	c.FulfillmentService = service.NewFulfillmentService(c.OrderRepo, c.FulfillmentRepo, c.CardSecretRepo, c.QueueClient)
+	c.FulfillmentService.SetDownloadConfig(c.Config.Download)
+	c.LicenseService = service.NewLicenseService(c.Config.License, c.CardSecretRepo, c.OrderRepo)
+	c.FulfillmentService.SetLicenseService(c.LicenseService)
+	if strings.TrimSpace(c.Config.Download.SigningSecret) == "" {
+		logger.Warnw("provider_download_signing_secret_missing")
+	}
+	c.DownloadService = service.NewDownloadService(c.Config, c.OrderRepo, c.ProductRepo, c.ProductSKURepo, c.ProductFileRepo, c.UploadService)
+	c.SecretRevealService = service.NewSecretRevealService(c.OrderRepo, c.FulfillmentRepo)
+	c.ProductSearchService = service.NewProductSearchService(c.ProductSearchRepo, c.CategoryRepo)
+	c.ProductReviewService = service.NewProductReviewService(c.ProductReviewRepo)
+	c.ReceiptService = service.NewReceiptService(c.OrderRepo, c.OrderInvoiceRepo, c.PaymentRepo, c.SettingService, c.Config.Receipt.FontPath)
+	c.SecretRotationService = service.NewSecretRotationService(c.CardSecretRepo, c.FulfillmentRepo, c.SecretKeyring)
+	c.DeliveryRenderService = service.NewDeliveryRenderService(
+		c.OrderRepo,
+		c.ProductRepo,
+		c.ProductSKURepo,
+		c.CardSecretRepo,
+		c.UserRepo,
+		c.UserOAuthIdentityRepo,
+		c.SettingService,
+		c.Config.TelegramAuth,
+	)
 	c.CardSecretService = service.NewCardSecretService(c.CardSecretRepo, c.CardSecretBatchRepo, c.ProductRepo, c.ProductSKURepo)
+	c.StockSubscriptionService = service.NewStockSubscriptionService(
+		c.StockSubscriptionRepo,
+		c.ProductRepo,
+		c.ProductService,
+		c.UserRepo,
+		c.UserOAuthIdentityRepo,
+		c.EmailService,
+		c.SettingService,
+		c.Config.TelegramAuth,
+		c.QueueClient,
+		c.Config.StockNotify,
+		c.Config.SEO.SiteURL,
+	)
+	c.ProductService.SetStockSubscriptionService(c.StockSubscriptionService)
+	c.CardSecretService.SetStockSubscriptionService(c.StockSubscriptionService)
 	c.GiftCardService = service.NewGiftCardService(c.GiftCardRepo, c.UserRepo, c.WalletService, c.SettingService)
 	c.CouponAdminService = service.NewCouponAdminService(c.CouponRepo)
 	c.PromotionAdminService = service.NewPromotionAdminService(c.PromotionRepo)
@@ -192,6 +292,27 @@ func (c *Container) initServices() {
 	c.AuthzAuditService = service.NewAuthzAuditService(c.AuthzAuditLogRepo)
 	c.DashboardService = service.NewDashboardService(c.DashboardRepo, c.SettingService)
 	c.NotificationService = service.NewNotificationService(c.SettingService, c.EmailService, c.QueueClient, c.DashboardService, c.Config.TelegramAuth)
+	c.OrderMessageService = service.NewOrderMessageService(
+		c.OrderRepo,
+		c.OrderMessageRepo,
+		c.UserRepo,
+		c.UserOAuthIdentityRepo,
+		c.UploadService,
+		c.EmailService,
+		c.NotificationService,
+		c.SettingService,
+		c.Config.TelegramAuth,
+		c.QueueClient,
+	)
+	c.OrderMessageService.SetDownloadService(c.DownloadService)
+	c.SupplierService = service.NewSupplierService(
+		c.SupplierRepo,
+		c.OrderRepo,
+		c.ProductRepo,
+		c.FulfillmentService,
+		c.NotificationService,
+		c.QueueClient,
+	)
 	c.PaymentService = service.NewPaymentService(
 		c.OrderRepo,
 		c.ProductRepo,
@@ -206,4 +327,5 @@ func (c *Container) initServices() {
 		c.AffiliateService,
 		c.NotificationService,
 	)
+	c.PaymentService.SetMemberLevelService(c.MemberLevelService)
 }
@@ -126,6 +126,48 @@ func (c *Client) EnqueueNotificationDispatch(payload NotificationDispatchPayload
 	return err
 }
 
+// EnqueueOrderMessageNotify 推送订单沟通消息通知任务
+func (c *Client) EnqueueOrderMessageNotify(payload OrderMessageNotifyPayload, opts ...asynq.Option) error {
+	if !c.Enabled() {
+		return nil
+	}
+	task, err := NewOrderMessageNotifyTask(payload)
+	if err != nil {
+		return err
+	}
+	options := append([]asynq.Option{asynq.Queue(c.defaultQueue)}, opts...)
+	_, err = c.client.Enqueue(task, options...)
+	return err
+}
+
+// EnqueueOrderAPIFulfill 推送供应商接口交付任务
+func (c *Client) EnqueueOrderAPIFulfill(payload OrderAPIFulfillPayload, opts ...asynq.Option) error {
+	if !c.Enabled() {
+		return nil
+	}
+	task, err := NewOrderAPIFulfillTask(payload)
+	if err != nil {
+		return err
+	}
+	options := append([]asynq.Option{asynq.Queue(c.defaultQueue)}, opts...)
+	_, err = c.client.Enqueue(task, options...)
+	return err
+}
+
+// EnqueueStockArrivalNotify 推送到货通知任务
+func (c *Client) EnqueueStockArrivalNotify(payload StockArrivalNotifyPayload, opts ...asynq.Option) error {
+	if !c.Enabled() {
+		return nil
+	}
+	task, err := NewStockArrivalNotifyTask(payload)
+	if err != nil {
+		return err
+	}
+	options := append([]asynq.Option{asynq.Queue(c.defaultQueue)}, opts...)
+	_, err = c.client.Enqueue(task, options...)
+	return err
+}
+
 // BuildServerConfig 生成队列服务配置
 func BuildServerConfig(cfg *config.QueueConfig) (asynq.RedisClientOpt, asynq.Config) {
 	opt := buildRedisOpt(cfg)
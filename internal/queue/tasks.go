@@ -19,6 +19,12 @@ const (
 	TaskWalletRechargeExpire = constants.TaskWalletRechargeExpire
 	// TaskNotificationDispatch 通知中心分发任务
 	TaskNotificationDispatch = constants.TaskNotificationDispatch
+	// TaskOrderMessageNotify 订单沟通消息通知任务
+	TaskOrderMessageNotify = constants.TaskOrderMessageNotify
+	// TaskOrderAPIFulfill 供应商接口交付任务
+	TaskOrderAPIFulfill = constants.TaskOrderAPIFulfill
+	// TaskStockArrivalNotify 到货通知任务
+	TaskStockArrivalNotify = constants.TaskStockArrivalNotify
 )
 
 // OrderStatusEmailPayload 订单状态邮件任务载荷
@@ -96,3 +102,45 @@ func NewNotificationDispatchTask(payload NotificationDispatchPayload) (*asynq.Ta
 	}
 	return asynq.NewTask(TaskNotificationDispatch, body), nil
 }
+
+// OrderMessageNotifyPayload 订单沟通消息通知任务载荷
+type OrderMessageNotifyPayload struct {
+	MessageID uint `json:"message_id"`
+}
+
+// NewOrderMessageNotifyTask 创建订单沟通消息通知任务
+func NewOrderMessageNotifyTask(payload OrderMessageNotifyPayload) (*asynq.Task, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TaskOrderMessageNotify, body), nil
+}
+
+// OrderAPIFulfillPayload 供应商接口交付任务载荷
+type OrderAPIFulfillPayload struct {
+	OrderID uint `json:"order_id"`
+}
+
+// NewOrderAPIFulfillTask 创建供应商接口交付任务
+func NewOrderAPIFulfillTask(payload OrderAPIFulfillPayload) (*asynq.Task, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TaskOrderAPIFulfill, body), nil
+}
+
+// StockArrivalNotifyPayload 到货通知任务载荷
+type StockArrivalNotifyPayload struct {
+	SKUID uint `json:"sku_id"`
+}
+
+// NewStockArrivalNotifyTask 创建到货通知任务
+func NewStockArrivalNotifyTask(payload StockArrivalNotifyPayload) (*asynq.Task, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TaskStockArrivalNotify, body), nil
+}
@@ -16,7 +16,9 @@ type CardSecretRepository interface {
 	ListAll(status string, page, pageSize int) ([]models.CardSecret, int64, error)
 	ListByIDs(ids []uint) ([]models.CardSecret, error)
 	ListByOrderAndStatus(orderID uint, status string) ([]models.CardSecret, error)
+	ListExistingHashes(productID uint, hashes []string) ([]string, error)
 	GetByID(id uint) (*models.CardSecret, error)
+	GetByProductAndHash(productID uint, hashes ...string) (*models.CardSecret, error)
 	Update(secret *models.CardSecret) error
 	BatchUpdateStatus(ids []uint, status string, updatedAt time.Time) (int64, error)
 	BatchDeleteByIDs(ids []uint) (int64, error)
@@ -28,6 +30,10 @@ type CardSecretRepository interface {
 	Reserve(ids []uint, orderID uint, reservedAt time.Time) (int64, error)
 	ReleaseByOrder(orderID uint) (int64, error)
 	MarkUsed(ids []uint, orderID uint, usedAt time.Time) (int64, error)
+	ListForKeyRotation(activeKeyID string, limit int) ([]models.CardSecret, error)
+	RewriteSecret(secret *models.CardSecret) error
+	ListForHashBackfill(activeKeyID string, limit int) ([]models.CardSecret, error)
+	RewriteSecretHash(secret *models.CardSecret) error
 	Transaction(fn func(tx *gorm.DB) error) error
 	WithTx(tx *gorm.DB) *GormCardSecretRepository
 }
@@ -156,6 +162,30 @@ func (r *GormCardSecretRepository) ListByOrderAndStatus(orderID uint, status str
 	return items, nil
 }
 
+// ListExistingHashes 返回商品下已存在的卡密内容哈希（不区分 SKU 与状态）
+func (r *GormCardSecretRepository) ListExistingHashes(productID uint, hashes []string) ([]string, error) {
+	result := make([]string, 0)
+	if productID == 0 || len(hashes) == 0 {
+		return result, nil
+	}
+	const chunkSize = 500
+	for start := 0; start < len(hashes); start += chunkSize {
+		end := start + chunkSize
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+		var found []string
+		if err := r.db.Model(&models.CardSecret{}).
+			Where("product_id = ? AND secret_hash IN ?", productID, hashes[start:end]).
+			Distinct().
+			Pluck("secret_hash", &found).Error; err != nil {
+			return nil, err
+		}
+		result = append(result, found...)
+	}
+	return result, nil
+}
+
 // GetByID 根据 ID 获取卡密
 func (r *GormCardSecretRepository) GetByID(id uint) (*models.CardSecret, error) {
 	var secret models.CardSecret
@@ -168,6 +198,21 @@ func (r *GormCardSecretRepository) GetByID(id uint) (*models.CardSecret, error)
 	return &secret, nil
 }
 
+// GetByProductAndHash 根据商品与内容哈希获取卡密，传入多个哈希时命中任一即可（兼容不同密钥计算的哈希）
+func (r *GormCardSecretRepository) GetByProductAndHash(productID uint, hashes ...string) (*models.CardSecret, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	var secret models.CardSecret
+	if err := r.db.Where("product_id = ? AND secret_hash IN ?", productID, hashes).Order("id desc").First(&secret).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &secret, nil
+}
+
 // Update 更新卡密
 func (r *GormCardSecretRepository) Update(secret *models.CardSecret) error {
 	return r.db.Save(secret).Error
@@ -363,3 +408,54 @@ func (r *GormCardSecretRepository) MarkUsed(ids []uint, orderID uint, usedAt tim
 		})
 	return result.RowsAffected, result.Error
 }
+
+// ListForKeyRotation 获取未使用当前密钥加密的卡密（含软删除数据）
+func (r *GormCardSecretRepository) ListForKeyRotation(activeKeyID string, limit int) ([]models.CardSecret, error) {
+	if limit <= 0 {
+		return []models.CardSecret{}, nil
+	}
+	var items []models.CardSecret
+	if err := r.db.Unscoped().
+		Where("secret_key_id <> ? AND secret <> ''", activeKeyID).
+		Order("id asc").
+		Limit(limit).
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// RewriteSecret 仅重写卡密密文、结构化字段与密钥ID，不改动状态与更新时间
+func (r *GormCardSecretRepository) RewriteSecret(secret *models.CardSecret) error {
+	if secret == nil || secret.ID == 0 {
+		return errors.New("invalid card secret")
+	}
+	return r.db.Unscoped().Model(secret).Select("secret", "secret_key_id", "secret_hash", "hash_key_id", "fields_data").Updates(secret).Error
+}
+
+// ListForHashBackfill 获取内容哈希缺失或未使用当前密钥计算的卡密（含软删除数据）
+func (r *GormCardSecretRepository) ListForHashBackfill(activeKeyID string, limit int) ([]models.CardSecret, error) {
+	if limit <= 0 {
+		return []models.CardSecret{}, nil
+	}
+	var items []models.CardSecret
+	if err := r.db.Unscoped().
+		Where("secret <> '' AND (secret_hash = '' OR hash_key_id <> ?)", activeKeyID).
+		Order("id asc").
+		Limit(limit).
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// RewriteSecretHash 仅重写内容哈希与哈希密钥ID，不触发加密钩子，不改动密文与更新时间
+func (r *GormCardSecretRepository) RewriteSecretHash(secret *models.CardSecret) error {
+	if secret == nil || secret.ID == 0 {
+		return errors.New("invalid card secret")
+	}
+	return r.db.Unscoped().Model(&models.CardSecret{}).Where("id = ?", secret.ID).UpdateColumns(map[string]interface{}{
+		"secret_hash": secret.SecretHash,
+		"hash_key_id": secret.HashKeyID,
+	}).Error
+}
@@ -17,6 +17,13 @@ type CategoryRepository interface {
 	Delete(id string) error
 	CountBySlug(slug string, excludeID *string) (int64, error)
 	CountProducts(categoryID string) (int64, error)
+	CountChildren(categoryID uint) (int64, error)
+	ListDescendantIDs(categoryID uint) ([]uint, error)
+	MoveChildren(fromID, toID uint) error
+	MoveProducts(fromID, toID uint) error
+	UpdateSortOrders(orders map[uint]int) error
+	Transaction(fn func(tx *gorm.DB) error) error
+	WithTx(tx *gorm.DB) CategoryRepository
 }
 
 // GormCategoryRepository GORM 实现
@@ -29,10 +36,26 @@ func NewCategoryRepository(db *gorm.DB) *GormCategoryRepository {
 	return &GormCategoryRepository{db: db}
 }
 
-// List 分类列表
+// WithTx 绑定事务
+func (r *GormCategoryRepository) WithTx(tx *gorm.DB) CategoryRepository {
+	if tx == nil {
+		return r
+	}
+	return &GormCategoryRepository{db: tx}
+}
+
+// Transaction 执行事务
+func (r *GormCategoryRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	if fn == nil {
+		return nil
+	}
+	return r.db.Transaction(fn)
+}
+
+// List 分类列表（同一父分类内按排序权重）
 func (r *GormCategoryRepository) List() ([]models.Category, error) {
 	var categories []models.Category
-	if err := r.db.Order("sort_order DESC, id ASC").Find(&categories).Error; err != nil {
+	if err := r.db.Order("parent_id ASC, sort_order DESC, id ASC").Find(&categories).Error; err != nil {
 		return nil, err
 	}
 	return categories, nil
@@ -86,3 +109,64 @@ func (r *GormCategoryRepository) CountProducts(categoryID string) (int64, error)
 	}
 	return count, nil
 }
+
+// CountChildren 统计直接子分类数
+func (r *GormCategoryRepository) CountChildren(categoryID uint) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.Category{}).Where("parent_id = ?", categoryID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ListDescendantIDs 获取分类自身及全部后代分类 ID
+func (r *GormCategoryRepository) ListDescendantIDs(categoryID uint) ([]uint, error) {
+	return listCategoryDescendantIDs(r.db, categoryID)
+}
+
+// MoveChildren 将直接子分类挂到新的父分类下
+func (r *GormCategoryRepository) MoveChildren(fromID, toID uint) error {
+	return r.db.Model(&models.Category{}).Where("parent_id = ?", fromID).Update("parent_id", toID).Error
+}
+
+// MoveProducts 将分类下的商品（含已删除）转移到目标分类
+func (r *GormCategoryRepository) MoveProducts(fromID, toID uint) error {
+	return r.db.Unscoped().Model(&models.Product{}).Where("category_id = ?", fromID).Update("category_id", toID).Error
+}
+
+// UpdateSortOrders 批量更新排序权重
+func (r *GormCategoryRepository) UpdateSortOrders(orders map[uint]int) error {
+	for id, sortOrder := range orders {
+		if err := r.db.Model(&models.Category{}).Where("id = ?", id).Update("sort_order", sortOrder).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listCategoryDescendantIDs 按父子关系逐层展开，返回根分类及其全部后代 ID
+func listCategoryDescendantIDs(db *gorm.DB, rootID uint) ([]uint, error) {
+	var rows []struct {
+		ID       uint
+		ParentID uint
+	}
+	if err := db.Model(&models.Category{}).Select("id, parent_id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	children := make(map[uint][]uint, len(rows))
+	for _, row := range rows {
+		children[row.ParentID] = append(children[row.ParentID], row.ID)
+	}
+	ids := []uint{rootID}
+	visited := map[uint]struct{}{rootID: {}}
+	for i := 0; i < len(ids); i++ {
+		for _, childID := range children[ids[i]] {
+			if _, ok := visited[childID]; ok {
+				continue
+			}
+			visited[childID] = struct{}{}
+			ids = append(ids, childID)
+		}
+	}
+	return ids, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/dujiao-next/internal/models"
 
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
@@ -20,6 +21,13 @@ type CouponRepository interface {
 	List(filter CouponListFilter) ([]models.Coupon, int64, error)
 	IncrementUsedCount(id uint, delta int) error
 	DecrementUsedCount(id uint, delta int) error
+	ListExistingCodes(codes []string) ([]string, error)
+	CreateBatch(batch *models.CouponBatch, coupons []models.Coupon) error
+	GetBatchByID(id uint) (*models.CouponBatch, error)
+	ListBatches(filter CouponBatchListFilter) ([]models.CouponBatch, int64, error)
+	UpdateBatchStatus(id uint, isActive bool) error
+	ListByBatchID(batchID uint) ([]models.Coupon, error)
+	ListBatchStats(batchIDs []uint) (map[uint]CouponBatchStats, error)
 	WithTx(tx *gorm.DB) *GormCouponRepository
 }
 
@@ -29,8 +37,29 @@ type CouponListFilter struct {
 	Code       string
 	ScopeRefID uint
 	IsActive   *bool
-	Page       int
-	PageSize   int
+	BatchID    uint
+	// ExcludeBatch 仅返回手动创建的优惠券，不含批量生成的优惠码
+	ExcludeBatch bool
+	Page         int
+	PageSize     int
+}
+
+// CouponBatchListFilter 优惠码批次列表筛选
+type CouponBatchListFilter struct {
+	BatchNo  string
+	Name     string
+	IsActive *bool
+	Page     int
+	PageSize int
+}
+
+// CouponBatchStats 优惠码批次使用统计
+type CouponBatchStats struct {
+	CodeCount      int64        `json:"code_count"`      // 优惠码数量
+	UsedCodeCount  int64        `json:"used_code_count"` // 已使用过的优惠码数量
+	UsageCount     int64        `json:"usage_count"`     // 累计使用次数
+	OrderCount     int64        `json:"order_count"`     // 使用订单数
+	DiscountAmount models.Money `json:"discount_amount"` // 累计优惠金额
 }
 
 // GormCouponRepository GORM 实现
@@ -66,7 +95,7 @@ func (r *GormCouponRepository) GetByID(id uint) (*models.Coupon, error) {
 // GetByCode 根据优惠码获取优惠券
 func (r *GormCouponRepository) GetByCode(code string) (*models.Coupon, error) {
 	var coupon models.Coupon
-	if err := r.db.Where("code = ?", code).First(&coupon).Error; err != nil {
+	if err := r.db.Preload("Batch").Where("code = ?", code).First(&coupon).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -130,6 +159,11 @@ func (r *GormCouponRepository) List(filter CouponListFilter) ([]models.Coupon, i
 	if filter.IsActive != nil {
 		query = query.Where("is_active = ?", *filter.IsActive)
 	}
+	if filter.BatchID > 0 {
+		query = query.Where("batch_id = ?", filter.BatchID)
+	} else if filter.ExcludeBatch {
+		query = query.Where("batch_id IS NULL")
+	}
 
 	var total int64
 	if err := query.Count(&total).Error; err != nil {
@@ -167,3 +201,153 @@ func (r *GormCouponRepository) DecrementUsedCount(id uint, delta int) error {
 		Where("used_count >= ?", delta).
 		UpdateColumn("used_count", gorm.Expr("used_count - ?", delta)).Error
 }
+
+// couponCodeQueryChunk 批量查询优惠码时单次 IN 条件数量上限
+const couponCodeQueryChunk = 500
+
+// ListExistingCodes 返回已被占用的优惠码（含已软删除）
+func (r *GormCouponRepository) ListExistingCodes(codes []string) ([]string, error) {
+	existing := make([]string, 0)
+	for start := 0; start < len(codes); start += couponCodeQueryChunk {
+		end := start + couponCodeQueryChunk
+		if end > len(codes) {
+			end = len(codes)
+		}
+		var chunk []string
+		if err := r.db.Unscoped().Model(&models.Coupon{}).
+			Where("code IN ?", codes[start:end]).
+			Pluck("code", &chunk).Error; err != nil {
+			return nil, err
+		}
+		existing = append(existing, chunk...)
+	}
+	return existing, nil
+}
+
+// CreateBatch 创建优惠码批次与批次内优惠码
+func (r *GormCouponRepository) CreateBatch(batch *models.CouponBatch, coupons []models.Coupon) error {
+	if batch == nil {
+		return errors.New("invalid coupon batch")
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		// is_active 带默认值，零值 false 不会写入，需要单独更新
+		if !batch.IsActive {
+			if err := tx.Model(batch).Update("is_active", false).Error; err != nil {
+				return err
+			}
+		}
+		if len(coupons) == 0 {
+			return nil
+		}
+		for idx := range coupons {
+			coupons[idx].BatchID = &batch.ID
+		}
+		return tx.CreateInBatches(&coupons, couponCodeQueryChunk).Error
+	})
+}
+
+// GetBatchByID 根据 ID 获取优惠码批次
+func (r *GormCouponRepository) GetBatchByID(id uint) (*models.CouponBatch, error) {
+	var batch models.CouponBatch
+	if err := r.db.First(&batch, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// ListBatches 获取优惠码批次列表
+func (r *GormCouponRepository) ListBatches(filter CouponBatchListFilter) ([]models.CouponBatch, int64, error) {
+	query := r.db.Model(&models.CouponBatch{})
+	if filter.BatchNo != "" {
+		query = query.Where("batch_no = ?", filter.BatchNo)
+	}
+	if filter.Name != "" {
+		query = query.Where("name LIKE ?", "%"+filter.Name+"%")
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = applyPagination(query, filter.Page, filter.PageSize)
+
+	var batches []models.CouponBatch
+	if err := query.Order("id desc").Find(&batches).Error; err != nil {
+		return nil, 0, err
+	}
+	return batches, total, nil
+}
+
+// UpdateBatchStatus 更新批次启用状态
+func (r *GormCouponRepository) UpdateBatchStatus(id uint, isActive bool) error {
+	return r.db.Model(&models.CouponBatch{}).Where("id = ?", id).Update("is_active", isActive).Error
+}
+
+// ListByBatchID 获取批次内全部优惠码
+func (r *GormCouponRepository) ListByBatchID(batchID uint) ([]models.Coupon, error) {
+	var coupons []models.Coupon
+	if err := r.db.Where("batch_id = ?", batchID).Order("id asc").Find(&coupons).Error; err != nil {
+		return nil, err
+	}
+	return coupons, nil
+}
+
+// ListBatchStats 按批次统计优惠码数量、使用次数与优惠金额
+func (r *GormCouponRepository) ListBatchStats(batchIDs []uint) (map[uint]CouponBatchStats, error) {
+	result := make(map[uint]CouponBatchStats, len(batchIDs))
+	if len(batchIDs) == 0 {
+		return result, nil
+	}
+
+	var codeRows []struct {
+		BatchID       uint  `gorm:"column:batch_id"`
+		CodeCount     int64 `gorm:"column:code_count"`
+		UsedCodeCount int64 `gorm:"column:used_code_count"`
+		UsageCount    int64 `gorm:"column:usage_count"`
+	}
+	if err := r.db.Model(&models.Coupon{}).
+		Select("batch_id, COUNT(*) AS code_count, COALESCE(SUM(CASE WHEN used_count > 0 THEN 1 ELSE 0 END), 0) AS used_code_count, COALESCE(SUM(used_count), 0) AS usage_count").
+		Where("batch_id IN ?", batchIDs).
+		Group("batch_id").
+		Scan(&codeRows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range codeRows {
+		item := result[row.BatchID]
+		item.CodeCount = row.CodeCount
+		item.UsedCodeCount = row.UsedCodeCount
+		item.UsageCount = row.UsageCount
+		result[row.BatchID] = item
+	}
+
+	var usageRows []struct {
+		BatchID    uint            `gorm:"column:batch_id"`
+		OrderCount int64           `gorm:"column:order_count"`
+		Total      decimal.Decimal `gorm:"column:total"`
+	}
+	if err := r.db.Model(&models.CouponUsage{}).
+		Select("coupons.batch_id AS batch_id, COUNT(DISTINCT coupon_usages.order_id) AS order_count, COALESCE(SUM(coupon_usages.discount_amount), 0) AS total").
+		Joins("JOIN coupons ON coupons.id = coupon_usages.coupon_id").
+		Where("coupons.batch_id IN ?", batchIDs).
+		Group("coupons.batch_id").
+		Scan(&usageRows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range usageRows {
+		item := result[row.BatchID]
+		item.OrderCount = row.OrderCount
+		item.DiscountAmount = models.NewMoneyFromDecimal(row.Total.Round(2))
+		result[row.BatchID] = item
+	}
+	return result, nil
+}
@@ -10,6 +10,7 @@ import (
 type CouponUsageRepository interface {
 	Create(usage *models.CouponUsage) error
 	CountByUser(couponID, userID uint) (int64, error)
+	CountByGuestEmail(couponID uint, email string) (int64, error)
 	ListByOrderID(orderID uint) ([]models.CouponUsage, error)
 	ListByUser(filter CouponUsageListFilter) ([]models.CouponUsage, int64, error)
 	DeleteByOrderID(orderID uint) error
@@ -50,6 +51,18 @@ func (r *GormCouponUsageRepository) CountByUser(couponID, userID uint) (int64, e
 	return count, nil
 }
 
+// CountByGuestEmail 按游客邮箱统计使用次数（关联订单的游客邮箱）
+func (r *GormCouponUsageRepository) CountByGuestEmail(couponID uint, email string) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.CouponUsage{}).
+		Joins("JOIN orders ON orders.id = coupon_usages.order_id").
+		Where("coupon_usages.coupon_id = ? AND coupon_usages.user_id = 0 AND orders.guest_email = ?", couponID, email).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 // ListByOrderID 获取订单使用记录
 func (r *GormCouponUsageRepository) ListByOrderID(orderID uint) ([]models.CouponUsage, error) {
 	var usages []models.CouponUsage
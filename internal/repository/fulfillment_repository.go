@@ -2,6 +2,7 @@ package repository
 
 import (
 	"errors"
+	"time"
 
 	"github.com/dujiao-next/internal/models"
 
@@ -10,8 +11,16 @@ import (
 
 // FulfillmentRepository 交付数据访问接口
 type FulfillmentRepository interface {
+	WithTx(tx *gorm.DB) *GormFulfillmentRepository
 	Create(fulfillment *models.Fulfillment) error
 	GetByOrderID(orderID uint) (*models.Fulfillment, error)
+	ListForKeyRotation(activeKeyID string, limit int) ([]models.Fulfillment, error)
+	RewritePayload(fulfillment *models.Fulfillment) error
+	ListBatchesForKeyRotation(activeKeyID string, limit int) ([]models.FulfillmentBatch, error)
+	RewriteBatchPayload(batch *models.FulfillmentBatch) error
+	ConsumeReveal(id uint, maxViews int, at time.Time) (bool, error)
+	CreateRevealLog(log *models.OrderSecretReveal) error
+	ListRevealLogsByOrders(orderIDs []uint) ([]models.OrderSecretReveal, error)
 }
 
 // GormFulfillmentRepository GORM 实现
@@ -24,6 +33,14 @@ func NewFulfillmentRepository(db *gorm.DB) *GormFulfillmentRepository {
 	return &GormFulfillmentRepository{db: db}
 }
 
+// WithTx 绑定事务
+func (r *GormFulfillmentRepository) WithTx(tx *gorm.DB) *GormFulfillmentRepository {
+	if tx == nil {
+		return r
+	}
+	return &GormFulfillmentRepository{db: tx}
+}
+
 // Create 创建交付记录
 func (r *GormFulfillmentRepository) Create(fulfillment *models.Fulfillment) error {
 	return r.db.Create(fulfillment).Error
@@ -40,3 +57,88 @@ func (r *GormFulfillmentRepository) GetByOrderID(orderID uint) (*models.Fulfillm
 	}
 	return &fulfillment, nil
 }
+
+// ListForKeyRotation 获取未使用当前密钥加密的交付记录（含软删除数据）
+func (r *GormFulfillmentRepository) ListForKeyRotation(activeKeyID string, limit int) ([]models.Fulfillment, error) {
+	if limit <= 0 {
+		return []models.Fulfillment{}, nil
+	}
+	var items []models.Fulfillment
+	if err := r.db.Unscoped().
+		Where("payload_key_id <> ? AND payload IS NOT NULL AND payload <> ''", activeKeyID).
+		Order("id asc").
+		Limit(limit).
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// RewritePayload 仅重写交付内容密文与密钥ID
+func (r *GormFulfillmentRepository) RewritePayload(fulfillment *models.Fulfillment) error {
+	if fulfillment == nil || fulfillment.ID == 0 {
+		return errors.New("invalid fulfillment")
+	}
+	return r.db.Unscoped().Model(fulfillment).Select("payload", "payload_key_id").Updates(fulfillment).Error
+}
+
+// ListBatchesForKeyRotation 获取未使用当前密钥加密的分批交付记录（含软删除数据）
+func (r *GormFulfillmentRepository) ListBatchesForKeyRotation(activeKeyID string, limit int) ([]models.FulfillmentBatch, error) {
+	if limit <= 0 {
+		return []models.FulfillmentBatch{}, nil
+	}
+	var items []models.FulfillmentBatch
+	if err := r.db.Unscoped().
+		Where("payload_key_id <> ? AND payload IS NOT NULL AND payload <> ''", activeKeyID).
+		Order("id asc").
+		Limit(limit).
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// RewriteBatchPayload 仅重写分批交付内容密文与密钥ID
+func (r *GormFulfillmentRepository) RewriteBatchPayload(batch *models.FulfillmentBatch) error {
+	if batch == nil || batch.ID == 0 {
+		return errors.New("invalid fulfillment batch")
+	}
+	return r.db.Unscoped().Model(batch).Select("payload", "payload_key_id").Updates(batch).Error
+}
+
+// ConsumeReveal 原子累加查看次数，maxViews 为 0 时不限制；超出上限返回 false
+func (r *GormFulfillmentRepository) ConsumeReveal(id uint, maxViews int, at time.Time) (bool, error) {
+	query := r.db.Model(&models.Fulfillment{}).Where("id = ?", id)
+	if maxViews > 0 {
+		query = query.Where("reveal_count < ?", maxViews)
+	}
+	result := query.Updates(map[string]interface{}{
+		"reveal_count": gorm.Expr("reveal_count + 1"),
+		"revealed_at":  gorm.Expr("COALESCE(revealed_at, ?)", at),
+		"updated_at":   at,
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CreateRevealLog 记录交付内容查看凭证
+func (r *GormFulfillmentRepository) CreateRevealLog(log *models.OrderSecretReveal) error {
+	if log == nil {
+		return errors.New("reveal log is nil")
+	}
+	return r.db.Create(log).Error
+}
+
+// ListRevealLogsByOrders 获取订单（含子订单）的查看凭证
+func (r *GormFulfillmentRepository) ListRevealLogsByOrders(orderIDs []uint) ([]models.OrderSecretReveal, error) {
+	if len(orderIDs) == 0 {
+		return []models.OrderSecretReveal{}, nil
+	}
+	var logs []models.OrderSecretReveal
+	if err := r.db.Where("order_id IN ?", orderIDs).Order("id desc").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
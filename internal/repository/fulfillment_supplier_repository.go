@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/dujiao-next/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// FulfillmentSupplierRepository 上游供应商数据访问接口
+type FulfillmentSupplierRepository interface {
+	List(filter FulfillmentSupplierListFilter) ([]models.FulfillmentSupplier, int64, error)
+	GetByID(id uint) (*models.FulfillmentSupplier, error)
+	Create(supplier *models.FulfillmentSupplier) error
+	Update(supplier *models.FulfillmentSupplier) error
+	Delete(id uint) error
+	CountProducts(id uint) (int64, error)
+	WithTx(tx *gorm.DB) FulfillmentSupplierRepository
+}
+
+// GormFulfillmentSupplierRepository GORM 实现
+type GormFulfillmentSupplierRepository struct {
+	db *gorm.DB
+}
+
+// NewFulfillmentSupplierRepository 创建上游供应商仓库
+func NewFulfillmentSupplierRepository(db *gorm.DB) *GormFulfillmentSupplierRepository {
+	return &GormFulfillmentSupplierRepository{db: db}
+}
+
+// WithTx 绑定事务
+func (r *GormFulfillmentSupplierRepository) WithTx(tx *gorm.DB) FulfillmentSupplierRepository {
+	if tx == nil {
+		return r
+	}
+	return &GormFulfillmentSupplierRepository{db: tx}
+}
+
+// List 供应商列表
+func (r *GormFulfillmentSupplierRepository) List(filter FulfillmentSupplierListFilter) ([]models.FulfillmentSupplier, int64, error) {
+	query := r.db.Model(&models.FulfillmentSupplier{})
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		like := "%" + search + "%"
+		query = query.Where("name LIKE ? OR endpoint LIKE ?", like, like)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	query = applyPagination(query, filter.Page, filter.PageSize)
+
+	var suppliers []models.FulfillmentSupplier
+	if err := query.Order("id DESC").Find(&suppliers).Error; err != nil {
+		return nil, 0, err
+	}
+	return suppliers, total, nil
+}
+
+// GetByID 根据 ID 获取供应商
+func (r *GormFulfillmentSupplierRepository) GetByID(id uint) (*models.FulfillmentSupplier, error) {
+	var supplier models.FulfillmentSupplier
+	if err := r.db.First(&supplier, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &supplier, nil
+}
+
+// Create 创建供应商
+func (r *GormFulfillmentSupplierRepository) Create(supplier *models.FulfillmentSupplier) error {
+	return r.db.Create(supplier).Error
+}
+
+// Update 更新供应商
+func (r *GormFulfillmentSupplierRepository) Update(supplier *models.FulfillmentSupplier) error {
+	return r.db.Save(supplier).Error
+}
+
+// Delete 删除供应商
+func (r *GormFulfillmentSupplierRepository) Delete(id uint) error {
+	return r.db.Delete(&models.FulfillmentSupplier{}, id).Error
+}
+
+// CountProducts 统计绑定该供应商的商品数量
+func (r *GormFulfillmentSupplierRepository) CountProducts(id uint) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.Product{}).Where("supplier_id = ?", id).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// MemberLevelRepository 会员等级数据访问接口
+type MemberLevelRepository interface {
+	GetByID(id uint) (*models.MemberLevel, error)
+	GetByCode(code string) (*models.MemberLevel, error)
+	GetDefault() (*models.MemberLevel, error)
+	List() ([]models.MemberLevel, error)
+	Create(level *models.MemberLevel) error
+	Update(level *models.MemberLevel) error
+	Delete(id uint) error
+	ClearDefault(exceptID uint) error
+	ListPrices(levelID uint) ([]models.MemberLevelPrice, error)
+	ReplacePrices(levelID uint, prices []models.MemberLevelPrice) error
+	SumUserSpend(userID uint) (decimal.Decimal, error)
+	UpdateUserMembership(userID uint, updates map[string]interface{}) error
+	ResetUsersLevel(levelID uint) error
+	Transaction(fn func(tx *gorm.DB) error) error
+	WithTx(tx *gorm.DB) MemberLevelRepository
+}
+
+// GormMemberLevelRepository GORM 实现
+type GormMemberLevelRepository struct {
+	db *gorm.DB
+}
+
+// NewMemberLevelRepository 创建会员等级仓库
+func NewMemberLevelRepository(db *gorm.DB) *GormMemberLevelRepository {
+	return &GormMemberLevelRepository{db: db}
+}
+
+// WithTx 绑定事务
+func (r *GormMemberLevelRepository) WithTx(tx *gorm.DB) MemberLevelRepository {
+	if tx == nil {
+		return r
+	}
+	return &GormMemberLevelRepository{db: tx}
+}
+
+// Transaction 执行事务
+func (r *GormMemberLevelRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	if fn == nil {
+		return nil
+	}
+	return r.db.Transaction(fn)
+}
+
+// GetByID 根据ID获取会员等级
+func (r *GormMemberLevelRepository) GetByID(id uint) (*models.MemberLevel, error) {
+	var level models.MemberLevel
+	if err := r.db.First(&level, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &level, nil
+}
+
+// GetByCode 根据编码获取会员等级
+func (r *GormMemberLevelRepository) GetByCode(code string) (*models.MemberLevel, error) {
+	var level models.MemberLevel
+	if err := r.db.Where("code = ?", code).First(&level).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &level, nil
+}
+
+// GetDefault 获取启用中的默认等级
+func (r *GormMemberLevelRepository) GetDefault() (*models.MemberLevel, error) {
+	var level models.MemberLevel
+	if err := r.db.Where("is_default = ? AND is_active = ?", true, true).Order("id asc").First(&level).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &level, nil
+}
+
+// List 获取全部会员等级
+func (r *GormMemberLevelRepository) List() ([]models.MemberLevel, error) {
+	var levels []models.MemberLevel
+	if err := r.db.Order("sort_order desc, min_spend asc, id asc").Find(&levels).Error; err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+// Create 创建会员等级
+func (r *GormMemberLevelRepository) Create(level *models.MemberLevel) error {
+	return r.db.Omit("Prices").Create(level).Error
+}
+
+// Update 更新会员等级
+func (r *GormMemberLevelRepository) Update(level *models.MemberLevel) error {
+	return r.db.Omit("Prices").Save(level).Error
+}
+
+// Delete 删除会员等级及其专属价
+func (r *GormMemberLevelRepository) Delete(id uint) error {
+	if err := r.db.Where("member_level_id = ?", id).Delete(&models.MemberLevelPrice{}).Error; err != nil {
+		return err
+	}
+	return r.db.Delete(&models.MemberLevel{}, id).Error
+}
+
+// ClearDefault 取消除指定等级外的默认标记
+func (r *GormMemberLevelRepository) ClearDefault(exceptID uint) error {
+	return r.db.Model(&models.MemberLevel{}).
+		Where("id <> ? AND is_default = ?", exceptID, true).
+		Update("is_default", false).Error
+}
+
+// ListPrices 获取等级 SKU 专属价
+func (r *GormMemberLevelRepository) ListPrices(levelID uint) ([]models.MemberLevelPrice, error) {
+	var prices []models.MemberLevelPrice
+	if err := r.db.Where("member_level_id = ?", levelID).Order("sku_id asc").Find(&prices).Error; err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// ReplacePrices 覆盖等级 SKU 专属价
+func (r *GormMemberLevelRepository) ReplacePrices(levelID uint, prices []models.MemberLevelPrice) error {
+	if err := r.db.Where("member_level_id = ?", levelID).Delete(&models.MemberLevelPrice{}).Error; err != nil {
+		return err
+	}
+	if len(prices) == 0 {
+		return nil
+	}
+	return r.db.Create(&prices).Error
+}
+
+// SumUserSpend 统计用户已支付主订单的累计实付金额（扣除主订单及其子订单的已退款金额）
+func (r *GormMemberLevelRepository) SumUserSpend(userID uint) (decimal.Decimal, error) {
+	var total models.Money
+	err := r.db.Model(&models.Order{}).
+		Select(`COALESCE(SUM(total_amount - refunded_amount - COALESCE((
+			SELECT SUM(children.refunded_amount) FROM orders AS children
+			WHERE children.parent_id = orders.id AND children.deleted_at IS NULL
+		), 0)), 0)`).
+		Where("user_id = ? AND parent_id IS NULL", userID).
+		Where("status IN ?", []string{
+			constants.OrderStatusPaid,
+			constants.OrderStatusFulfilling,
+			constants.OrderStatusPartiallyDelivered,
+			constants.OrderStatusDelivered,
+			constants.OrderStatusCompleted,
+		}).
+		Scan(&total).Error
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return total.Decimal, nil
+}
+
+// UpdateUserMembership 更新用户会员字段
+func (r *GormMemberLevelRepository) UpdateUserMembership(userID uint, updates map[string]interface{}) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Updates(updates).Error
+}
+
+// ResetUsersLevel 将使用指定等级的用户重置为默认等级
+func (r *GormMemberLevelRepository) ResetUsersLevel(levelID uint) error {
+	return r.db.Model(&models.User{}).
+		Where("member_level_id = ?", levelID).
+		Updates(map[string]interface{}{
+			"member_level_id":     0,
+			"member_level_locked": false,
+		}).Error
+}
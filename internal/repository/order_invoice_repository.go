@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/dujiao-next/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// OrderInvoiceRepository 订单收据数据访问接口
+type OrderInvoiceRepository interface {
+	GetByOrderID(orderID uint) (*models.OrderInvoice, error)
+	GetMaxSequence() (int64, error)
+	Create(invoice *models.OrderInvoice) error
+	Transaction(fn func(tx *gorm.DB) error) error
+	WithTx(tx *gorm.DB) *GormOrderInvoiceRepository
+}
+
+// GormOrderInvoiceRepository GORM 实现
+type GormOrderInvoiceRepository struct {
+	db *gorm.DB
+}
+
+// NewOrderInvoiceRepository 创建订单收据仓库
+func NewOrderInvoiceRepository(db *gorm.DB) *GormOrderInvoiceRepository {
+	return &GormOrderInvoiceRepository{db: db}
+}
+
+// WithTx 绑定事务
+func (r *GormOrderInvoiceRepository) WithTx(tx *gorm.DB) *GormOrderInvoiceRepository {
+	if tx == nil {
+		return r
+	}
+	return &GormOrderInvoiceRepository{db: tx}
+}
+
+// Transaction 执行事务
+func (r *GormOrderInvoiceRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	if fn == nil {
+		return nil
+	}
+	return r.db.Transaction(fn)
+}
+
+// GetByOrderID 按订单获取收据
+func (r *GormOrderInvoiceRepository) GetByOrderID(orderID uint) (*models.OrderInvoice, error) {
+	if orderID == 0 {
+		return nil, errors.New("invalid order id")
+	}
+	var invoice models.OrderInvoice
+	if err := r.db.Where("order_id = ?", orderID).First(&invoice).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// GetMaxSequence 获取当前最大顺序编号
+func (r *GormOrderInvoiceRepository) GetMaxSequence() (int64, error) {
+	var maxSeq *int64
+	if err := r.db.Model(&models.OrderInvoice{}).Select("MAX(sequence)").Scan(&maxSeq).Error; err != nil {
+		return 0, err
+	}
+	if maxSeq == nil {
+		return 0, nil
+	}
+	return *maxSeq, nil
+}
+
+// Create 创建收据记录
+func (r *GormOrderInvoiceRepository) Create(invoice *models.OrderInvoice) error {
+	if invoice == nil {
+		return errors.New("invoice is nil")
+	}
+	return r.db.Create(invoice).Error
+}
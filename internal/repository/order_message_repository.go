@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// OrderMessageThreadListFilter 会话列表筛选
+type OrderMessageThreadListFilter struct {
+	Page       int
+	PageSize   int
+	UnreadOnly bool
+	UserID     uint // 大于 0 时按买家视角筛选
+}
+
+// OrderMessageRepository 订单沟通消息数据访问接口
+type OrderMessageRepository interface {
+	Create(message *models.OrderMessage) error
+	GetByID(id uint) (*models.OrderMessage, error)
+	ListByOrder(orderID uint, page, pageSize int) ([]models.OrderMessage, int64, error)
+	GetThread(orderID uint) (*models.OrderMessageThread, error)
+	TouchThread(orderID uint, orderNo, senderType string, at time.Time) error
+	MarkRead(orderID uint, readerIsAdmin bool) error
+	ListThreads(filter OrderMessageThreadListFilter) ([]models.OrderMessageThread, int64, error)
+	Transaction(fn func(tx *gorm.DB) error) error
+	WithTx(tx *gorm.DB) *GormOrderMessageRepository
+}
+
+// GormOrderMessageRepository GORM 实现
+type GormOrderMessageRepository struct {
+	db *gorm.DB
+}
+
+// NewOrderMessageRepository 创建订单沟通消息仓库
+func NewOrderMessageRepository(db *gorm.DB) *GormOrderMessageRepository {
+	return &GormOrderMessageRepository{db: db}
+}
+
+// WithTx 绑定事务
+func (r *GormOrderMessageRepository) WithTx(tx *gorm.DB) *GormOrderMessageRepository {
+	if tx == nil {
+		return r
+	}
+	return &GormOrderMessageRepository{db: tx}
+}
+
+// Transaction 执行事务
+func (r *GormOrderMessageRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	if fn == nil {
+		return nil
+	}
+	return r.db.Transaction(fn)
+}
+
+// Create 创建消息
+func (r *GormOrderMessageRepository) Create(message *models.OrderMessage) error {
+	if message == nil {
+		return errors.New("message is nil")
+	}
+	return r.db.Create(message).Error
+}
+
+// GetByID 获取消息
+func (r *GormOrderMessageRepository) GetByID(id uint) (*models.OrderMessage, error) {
+	if id == 0 {
+		return nil, errors.New("invalid message id")
+	}
+	var message models.OrderMessage
+	if err := r.db.First(&message, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &message, nil
+}
+
+// ListByOrder 按订单分页获取消息（按时间正序）
+func (r *GormOrderMessageRepository) ListByOrder(orderID uint, page, pageSize int) ([]models.OrderMessage, int64, error) {
+	if orderID == 0 {
+		return nil, 0, errors.New("invalid order id")
+	}
+	query := r.db.Model(&models.OrderMessage{}).Where("order_id = ?", orderID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if pageSize > 0 {
+		offset := (page - 1) * pageSize
+		query = query.Limit(pageSize).Offset(offset)
+	}
+
+	var messages []models.OrderMessage
+	if err := query.Order("id asc").Find(&messages).Error; err != nil {
+		return nil, 0, err
+	}
+	return messages, total, nil
+}
+
+// GetThread 获取订单会话
+func (r *GormOrderMessageRepository) GetThread(orderID uint) (*models.OrderMessageThread, error) {
+	if orderID == 0 {
+		return nil, errors.New("invalid order id")
+	}
+	var thread models.OrderMessageThread
+	if err := r.db.Where("order_id = ?", orderID).First(&thread).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &thread, nil
+}
+
+// TouchThread 新消息写入后更新会话计数，对方未读数 +1
+func (r *GormOrderMessageRepository) TouchThread(orderID uint, orderNo, senderType string, at time.Time) error {
+	if orderID == 0 {
+		return errors.New("invalid order id")
+	}
+	var thread models.OrderMessageThread
+	if err := r.db.Where("order_id = ?", orderID).
+		Attrs(models.OrderMessageThread{OrderID: orderID, OrderNo: orderNo}).
+		FirstOrCreate(&thread).Error; err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{
+		"message_count":    gorm.Expr("message_count + 1"),
+		"last_sender_type": senderType,
+		"last_message_at":  at,
+		"updated_at":       at,
+	}
+	if senderType == constants.OrderMessageSenderAdmin {
+		updates["buyer_unread_count"] = gorm.Expr("buyer_unread_count + 1")
+	} else {
+		updates["admin_unread_count"] = gorm.Expr("admin_unread_count + 1")
+	}
+	return r.db.Model(&models.OrderMessageThread{}).Where("order_id = ?", orderID).Updates(updates).Error
+}
+
+// MarkRead 清零一方未读数
+func (r *GormOrderMessageRepository) MarkRead(orderID uint, readerIsAdmin bool) error {
+	if orderID == 0 {
+		return errors.New("invalid order id")
+	}
+	column := "buyer_unread_count"
+	if readerIsAdmin {
+		column = "admin_unread_count"
+	}
+	return r.db.Model(&models.OrderMessageThread{}).
+		Where("order_id = ? AND "+column+" > 0", orderID).
+		Update(column, 0).Error
+}
+
+// ListThreads 会话列表（按最后消息时间倒序）
+func (r *GormOrderMessageRepository) ListThreads(filter OrderMessageThreadListFilter) ([]models.OrderMessageThread, int64, error) {
+	query := r.db.Model(&models.OrderMessageThread{})
+	if filter.UserID > 0 {
+		query = query.Where("order_id IN (?)", r.db.Model(&models.Order{}).Select("id").Where("user_id = ?", filter.UserID))
+		if filter.UnreadOnly {
+			query = query.Where("buyer_unread_count > 0")
+		}
+	} else if filter.UnreadOnly {
+		query = query.Where("admin_unread_count > 0")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if filter.PageSize > 0 {
+		offset := (filter.Page - 1) * filter.PageSize
+		query = query.Limit(filter.PageSize).Offset(offset)
+	}
+
+	var threads []models.OrderMessageThread
+	if err := query.Order("last_message_at desc").Order("id desc").Find(&threads).Error; err != nil {
+		return nil, 0, err
+	}
+	return threads, total, nil
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"strings"
 
+	"github.com/dujiao-next/internal/constants"
 	"github.com/dujiao-next/internal/models"
 
 	"gorm.io/gorm"
@@ -22,6 +23,7 @@ type OrderRepository interface {
 	ListByUser(filter OrderListFilter) ([]models.Order, int64, error)
 	ListByGuest(email, password string, page, pageSize int) ([]models.Order, int64, error)
 	ListAdmin(filter OrderListFilter) ([]models.Order, int64, error)
+	CountFirstOrderClaims(userID uint, guestEmail string) (int64, error)
 	UpdateStatus(id uint, status string, updates map[string]interface{}) error
 	Transaction(fn func(tx *gorm.DB) error) error
 	WithTx(tx *gorm.DB) *GormOrderRepository
@@ -54,7 +56,7 @@ func (r *GormOrderRepository) Transaction(fn func(tx *gorm.DB) error) error {
 }
 
 func (r *GormOrderRepository) withChildren(query *gorm.DB) *gorm.DB {
-	return query.Preload("Children").Preload("Children.Items").Preload("Children.Fulfillment")
+	return query.Preload("Children").Preload("Children.Items").Preload("Children.Fulfillment").Preload("Children.Fulfillment.Batches")
 }
 
 // Create 创建订单与订单项
@@ -76,7 +78,7 @@ func (r *GormOrderRepository) Create(order *models.Order, items []models.OrderIt
 // GetByID 根据 ID 获取订单
 func (r *GormOrderRepository) GetByID(id uint) (*models.Order, error) {
 	var order models.Order
-	query := r.withChildren(r.db.Preload("Items").Preload("Fulfillment"))
+	query := r.withChildren(r.db.Preload("Items").Preload("Fulfillment").Preload("Fulfillment.Batches"))
 	if err := query.First(&order, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -127,7 +129,7 @@ func (r *GormOrderRepository) ResolveReceiverEmailByOrderID(orderID uint) (strin
 // GetByIDAndUser 获取用户订单详情
 func (r *GormOrderRepository) GetByIDAndUser(id uint, userID uint) (*models.Order, error) {
 	var order models.Order
-	query := r.withChildren(r.db.Preload("Items").Preload("Fulfillment"))
+	query := r.withChildren(r.db.Preload("Items").Preload("Fulfillment").Preload("Fulfillment.Batches"))
 	if err := query.Where("id = ? AND user_id = ? AND parent_id IS NULL", id, userID).First(&order).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -138,7 +140,7 @@ func (r *GormOrderRepository) GetByIDAndUser(id uint, userID uint) (*models.Orde
 }
 func (r *GormOrderRepository) GetByOrderNoAndUser(orderNo string, userID uint) (*models.Order, error) {
 	var order models.Order
-	query := r.withChildren(r.db.Preload("Items").Preload("Fulfillment"))
+	query := r.withChildren(r.db.Preload("Items").Preload("Fulfillment").Preload("Fulfillment.Batches"))
 	if err := query.Where("order_no = ? AND user_id = ? AND parent_id IS NULL", orderNo, userID).First(&order).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -151,7 +153,7 @@ func (r *GormOrderRepository) GetByOrderNoAndUser(orderNo string, userID uint) (
 // GetByIDAndGuest 获取游客订单详情
 func (r *GormOrderRepository) GetByIDAndGuest(id uint, email, password string) (*models.Order, error) {
 	var order models.Order
-	query := r.withChildren(r.db.Preload("Items").Preload("Fulfillment"))
+	query := r.withChildren(r.db.Preload("Items").Preload("Fulfillment").Preload("Fulfillment.Batches"))
 	if err := query.
 		Where("id = ? AND user_id = 0 AND guest_email = ? AND guest_password = ? AND parent_id IS NULL", id, email, password).
 		First(&order).Error; err != nil {
@@ -166,7 +168,7 @@ func (r *GormOrderRepository) GetByIDAndGuest(id uint, email, password string) (
 // GetByOrderNoAndGuest 获取游客订单详情（按订单号）
 func (r *GormOrderRepository) GetByOrderNoAndGuest(orderNo, email, password string) (*models.Order, error) {
 	var order models.Order
-	query := r.withChildren(r.db.Preload("Items").Preload("Fulfillment"))
+	query := r.withChildren(r.db.Preload("Items").Preload("Fulfillment").Preload("Fulfillment.Batches"))
 	if err := query.
 		Where("order_no = ? AND user_id = 0 AND guest_email = ? AND guest_password = ? AND parent_id IS NULL", orderNo, email, password).
 		First(&order).Error; err != nil {
@@ -266,6 +268,35 @@ func (r *GormOrderRepository) ListByUser(filter OrderListFilter) ([]models.Order
 	return orders, total, nil
 }
 
+// CountFirstOrderClaims 统计占用首单资格的主订单数量：已支付订单，以及已使用首单券但尚未支付的订单；userID 为 0 时按游客邮箱统计
+func (r *GormOrderRepository) CountFirstOrderClaims(userID uint, guestEmail string) (int64, error) {
+	query := r.db.Model(&models.Order{}).
+		Where("parent_id IS NULL").
+		Where(
+			r.db.Where("status IN ?", []string{
+				constants.OrderStatusPaid,
+				constants.OrderStatusFulfilling,
+				constants.OrderStatusPartiallyDelivered,
+				constants.OrderStatusDelivered,
+				constants.OrderStatusCompleted,
+			}).Or(
+				"status = ? AND coupon_id IN (?)",
+				constants.OrderStatusPendingPayment,
+				r.db.Model(&models.Coupon{}).Select("id").Where("first_order_only = ?", true),
+			),
+		)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	} else {
+		query = query.Where("user_id = 0 AND guest_email = ?", guestEmail)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 // ListByGuest 获取游客订单列表
 func (r *GormOrderRepository) ListByGuest(email, password string, page, pageSize int) ([]models.Order, int64, error) {
 	var total int64
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dujiao-next/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ProductFileRepository 商品交付文件与下载授权数据访问接口
+type ProductFileRepository interface {
+	Create(file *models.ProductFile) error
+	GetByID(id uint) (*models.ProductFile, error)
+	GetByIDUnscoped(id uint) (*models.ProductFile, error)
+	ListByProduct(productID uint) ([]models.ProductFile, error)
+	Delete(id uint) error
+	ListDownloadsByOrder(orderID uint) ([]models.OrderDownload, error)
+	GetDownloadByID(id uint) (*models.OrderDownload, error)
+	ConsumeDownload(id uint, at time.Time) (bool, error)
+	CreateDownloadLog(log *models.OrderDownloadLog) error
+	ListDownloadLogsByOrder(orderID uint) ([]models.OrderDownloadLog, error)
+}
+
+// GormProductFileRepository GORM 实现
+type GormProductFileRepository struct {
+	db *gorm.DB
+}
+
+// NewProductFileRepository 创建商品交付文件仓库
+func NewProductFileRepository(db *gorm.DB) *GormProductFileRepository {
+	return &GormProductFileRepository{db: db}
+}
+
+// WithTx 绑定事务
+func (r *GormProductFileRepository) WithTx(tx *gorm.DB) *GormProductFileRepository {
+	if tx == nil {
+		return r
+	}
+	return &GormProductFileRepository{db: tx}
+}
+
+// Create 创建交付文件
+func (r *GormProductFileRepository) Create(file *models.ProductFile) error {
+	if file == nil {
+		return errors.New("product file is nil")
+	}
+	return r.db.Create(file).Error
+}
+
+// GetByID 获取交付文件
+func (r *GormProductFileRepository) GetByID(id uint) (*models.ProductFile, error) {
+	var file models.ProductFile
+	if err := r.db.First(&file, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &file, nil
+}
+
+// GetByIDUnscoped 获取交付文件（含已删除，供已发放的下载授权使用）
+func (r *GormProductFileRepository) GetByIDUnscoped(id uint) (*models.ProductFile, error) {
+	var file models.ProductFile
+	if err := r.db.Unscoped().First(&file, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &file, nil
+}
+
+// ListByProduct 获取商品全部交付文件
+func (r *GormProductFileRepository) ListByProduct(productID uint) ([]models.ProductFile, error) {
+	var files []models.ProductFile
+	if err := r.db.Where("product_id = ?", productID).Order("sort_order desc, id asc").Find(&files).Error; err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// Delete 删除交付文件（软删除，已发放的下载授权仍可使用）
+func (r *GormProductFileRepository) Delete(id uint) error {
+	return r.db.Delete(&models.ProductFile{}, id).Error
+}
+
+// ListDownloadsByOrder 获取订单下载授权
+func (r *GormProductFileRepository) ListDownloadsByOrder(orderID uint) ([]models.OrderDownload, error) {
+	var downloads []models.OrderDownload
+	if err := r.db.Where("order_id = ?", orderID).Order("id asc").Find(&downloads).Error; err != nil {
+		return nil, err
+	}
+	return downloads, nil
+}
+
+// GetDownloadByID 获取下载授权
+func (r *GormProductFileRepository) GetDownloadByID(id uint) (*models.OrderDownload, error) {
+	var download models.OrderDownload
+	if err := r.db.First(&download, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &download, nil
+}
+
+// ConsumeDownload 原子扣减一次下载次数，超出上限时返回 false
+func (r *GormProductFileRepository) ConsumeDownload(id uint, at time.Time) (bool, error) {
+	result := r.db.Model(&models.OrderDownload{}).
+		Where("id = ? AND (max_downloads = 0 OR download_count < max_downloads)", id).
+		Updates(map[string]interface{}{
+			"download_count":   gorm.Expr("download_count + 1"),
+			"last_download_at": at,
+			"updated_at":       at,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CreateDownloadLog 记录下载日志
+func (r *GormProductFileRepository) CreateDownloadLog(log *models.OrderDownloadLog) error {
+	if log == nil {
+		return errors.New("download log is nil")
+	}
+	return r.db.Create(log).Error
+}
+
+// ListDownloadLogsByOrder 获取订单下载日志
+func (r *GormProductFileRepository) ListDownloadLogsByOrder(orderID uint) ([]models.OrderDownloadLog, error) {
+	var logs []models.OrderDownloadLog
+	if err := r.db.Where("order_id = ?", orderID).Order("id desc").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
@@ -3,7 +3,9 @@ package repository
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dujiao-next/internal/constants"
 	"github.com/dujiao-next/internal/models"
@@ -24,6 +26,7 @@ type ProductRepository interface {
 	ReserveManualStock(productID uint, quantity int) (int64, error)
 	ReleaseManualStock(productID uint, quantity int) (int64, error)
 	ConsumeManualStock(productID uint, quantity int) (int64, error)
+	NextPublishBoundary(after time.Time) (*time.Time, error)
 	Transaction(fn func(tx *gorm.DB) error) error
 	WithTx(tx *gorm.DB) ProductRepository
 }
@@ -63,17 +66,30 @@ func (r *GormProductRepository) List(filter ProductListFilter) ([]models.Product
 		query = query.Preload("Category")
 	}
 	if filter.OnlyActive {
-		query = query.Where("is_active = ?", true)
+		now := time.Now()
+		query = applyPublishWindow(query.Where("is_active = ?", true), now)
 		query = query.Preload("SKUs", func(db *gorm.DB) *gorm.DB {
-			return db.Where("is_active = ?", true).Order("sort_order DESC, id ASC")
+			return applyPublishWindow(db.Where("is_active = ?", true), now).Order("sort_order DESC, id ASC")
 		})
 	} else {
 		query = query.Preload("SKUs", func(db *gorm.DB) *gorm.DB {
 			return db.Order("sort_order DESC, id ASC")
 		})
 	}
+	if filter.OnlyListed {
+		query = query.Where("visibility = ?", constants.ProductVisibilityPublic)
+	}
 	if filter.CategoryID != "" {
-		query = query.Where("category_id = ?", filter.CategoryID)
+		categoryID, err := strconv.ParseUint(filter.CategoryID, 10, 64)
+		if err != nil {
+			query = query.Where("category_id = ?", filter.CategoryID)
+		} else {
+			categoryIDs, err := listCategoryDescendantIDs(r.db, uint(categoryID))
+			if err != nil {
+				return nil, 0, err
+			}
+			query = query.Where("category_id IN ?", categoryIDs)
+		}
 	}
 	if fulfillmentType := strings.TrimSpace(filter.FulfillmentType); fulfillmentType != "" {
 		query = query.Where("fulfillment_type = ?", fulfillmentType)
@@ -101,6 +117,11 @@ func (r *GormProductRepository) List(filter ProductListFilter) ([]models.Product
 	return products, total, nil
 }
 
+// applyPublishWindow 仅保留当前处于定时上下架窗口内的记录
+func applyPublishWindow(query *gorm.DB, now time.Time) *gorm.DB {
+	return query.Where("(publish_at IS NULL OR publish_at <= ?) AND (unpublish_at IS NULL OR unpublish_at > ?)", now, now)
+}
+
 func applyManualStockStatusFilter(query *gorm.DB, status string) *gorm.DB {
 	if query == nil {
 		return query
@@ -165,9 +186,10 @@ func applyManualStockStatusFilter(query *gorm.DB, status string) *gorm.DB {
 func (r *GormProductRepository) GetBySlug(slug string, onlyActive bool) (*models.Product, error) {
 	query := r.db.Preload("Category").Where("slug = ?", slug)
 	if onlyActive {
-		query = query.Where("is_active = ?", true)
+		now := time.Now()
+		query = applyPublishWindow(query.Where("is_active = ?", true), now)
 		query = query.Preload("SKUs", func(db *gorm.DB) *gorm.DB {
-			return db.Where("is_active = ?", true).Order("sort_order DESC, id ASC")
+			return applyPublishWindow(db.Where("is_active = ?", true), now).Order("sort_order DESC, id ASC")
 		})
 	} else {
 		query = query.Preload("SKUs", func(db *gorm.DB) *gorm.DB {
@@ -213,6 +235,28 @@ func (r *GormProductRepository) ListByIDs(ids []uint) ([]models.Product, error)
 	return products, nil
 }
 
+// NextPublishBoundary 返回晚于 after 的最近一个启用商品或 SKU 的定时上下架时间点，不存在时返回 nil
+func (r *GormProductRepository) NextPublishBoundary(after time.Time) (*time.Time, error) {
+	var next *time.Time
+	for _, model := range []interface{}{&models.Product{}, &models.ProductSKU{}} {
+		for _, column := range []string{"publish_at", "unpublish_at"} {
+			var times []time.Time
+			if err := r.db.Model(model).
+				Where("is_active = ? AND "+column+" > ?", true, after).
+				Order(column+" ASC").
+				Limit(1).
+				Pluck(column, &times).Error; err != nil {
+				return nil, err
+			}
+			if len(times) > 0 && (next == nil || times[0].Before(*next)) {
+				value := times[0]
+				next = &value
+			}
+		}
+	}
+	return next, nil
+}
+
 // Create 创建商品
 func (r *GormProductRepository) Create(product *models.Product) error {
 	return r.db.Create(product).Error
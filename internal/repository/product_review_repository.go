@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ProductReviewRepository 商品评价数据访问接口
+type ProductReviewRepository interface {
+	GetByID(id uint) (*models.ProductReview, error)
+	GetByOrderItemID(orderItemID uint) (*models.ProductReview, error)
+	GetReviewableItem(orderItemID, userID uint) (*models.OrderItem, error)
+	Create(review *models.ProductReview) error
+	Update(review *models.ProductReview) error
+	List(filter ProductReviewListFilter) ([]models.ProductReview, int64, error)
+	SummaryByProductIDs(productIDs []uint) ([]ProductReviewSummary, error)
+}
+
+// ProductReviewListFilter 商品评价列表筛选
+type ProductReviewListFilter struct {
+	ProductID uint
+	UserID    uint
+	Status    string
+	Rating    int
+	WithUser  bool
+	Page      int
+	PageSize  int
+}
+
+// ProductReviewSummary 商品评价汇总
+type ProductReviewSummary struct {
+	ProductID     uint
+	ReviewCount   int64
+	RatingAverage float64
+}
+
+// GormProductReviewRepository GORM 实现
+type GormProductReviewRepository struct {
+	db *gorm.DB
+}
+
+// NewProductReviewRepository 创建商品评价仓库
+func NewProductReviewRepository(db *gorm.DB) *GormProductReviewRepository {
+	return &GormProductReviewRepository{db: db}
+}
+
+// GetByID 根据ID获取评价
+func (r *GormProductReviewRepository) GetByID(id uint) (*models.ProductReview, error) {
+	var review models.ProductReview
+	if err := r.db.First(&review, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetByOrderItemID 根据订单项获取评价（含已删除记录，保证一项一评）
+func (r *GormProductReviewRepository) GetByOrderItemID(orderItemID uint) (*models.ProductReview, error) {
+	var review models.ProductReview
+	if err := r.db.Unscoped().Where("order_item_id = ?", orderItemID).First(&review).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetReviewableItem 获取用户已交付完成订单中的订单项
+func (r *GormProductReviewRepository) GetReviewableItem(orderItemID, userID uint) (*models.OrderItem, error) {
+	var item models.OrderItem
+	err := r.db.Model(&models.OrderItem{}).
+		Joins("JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL").
+		Where("order_items.id = ? AND orders.user_id = ?", orderItemID, userID).
+		Where("orders.status IN ?", []string{constants.OrderStatusDelivered, constants.OrderStatusCompleted}).
+		First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Create 创建评价
+func (r *GormProductReviewRepository) Create(review *models.ProductReview) error {
+	return r.db.Omit("User").Create(review).Error
+}
+
+// Update 更新评价
+func (r *GormProductReviewRepository) Update(review *models.ProductReview) error {
+	return r.db.Omit("User").Save(review).Error
+}
+
+// List 获取评价列表
+func (r *GormProductReviewRepository) List(filter ProductReviewListFilter) ([]models.ProductReview, int64, error) {
+	query := r.db.Model(&models.ProductReview{})
+	if filter.ProductID != 0 {
+		query = query.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Rating != 0 {
+		query = query.Where("rating = ?", filter.Rating)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = applyPagination(query, filter.Page, filter.PageSize)
+	if filter.WithUser {
+		query = query.Preload("User")
+	}
+	var reviews []models.ProductReview
+	if err := query.Order("id desc").Find(&reviews).Error; err != nil {
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+
+// SummaryByProductIDs 统计商品已公开评价的数量与平均分
+func (r *GormProductReviewRepository) SummaryByProductIDs(productIDs []uint) ([]ProductReviewSummary, error) {
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+	var rows []ProductReviewSummary
+	err := r.db.Model(&models.ProductReview{}).
+		Select("product_id, COUNT(*) AS review_count, AVG(rating) AS rating_average").
+		Where("product_id IN ? AND status = ?", productIDs, constants.ReviewStatusPublished).
+		Group("product_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/dujiao-next/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ProductRevisionRepository 商品修订数据访问接口
+type ProductRevisionRepository interface {
+	GetByID(id uint) (*models.ProductRevision, error)
+	GetPrevious(productID, beforeID uint) (*models.ProductRevision, error)
+	CountByProduct(productID uint) (int64, error)
+	ListByProduct(productID uint, page, pageSize int) ([]models.ProductRevision, int64, error)
+	Create(revision *models.ProductRevision) error
+	WithTx(tx *gorm.DB) ProductRevisionRepository
+}
+
+// GormProductRevisionRepository GORM 实现
+type GormProductRevisionRepository struct {
+	db *gorm.DB
+}
+
+// NewProductRevisionRepository 创建商品修订仓库
+func NewProductRevisionRepository(db *gorm.DB) *GormProductRevisionRepository {
+	return &GormProductRevisionRepository{db: db}
+}
+
+// WithTx 绑定事务
+func (r *GormProductRevisionRepository) WithTx(tx *gorm.DB) ProductRevisionRepository {
+	if tx == nil {
+		return r
+	}
+	return &GormProductRevisionRepository{db: tx}
+}
+
+// GetByID 根据ID获取修订
+func (r *GormProductRevisionRepository) GetByID(id uint) (*models.ProductRevision, error) {
+	var revision models.ProductRevision
+	if err := r.db.First(&revision, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// GetPrevious 获取指定修订之前的最近一条修订
+func (r *GormProductRevisionRepository) GetPrevious(productID, beforeID uint) (*models.ProductRevision, error) {
+	var revision models.ProductRevision
+	err := r.db.Where("product_id = ? AND id < ?", productID, beforeID).
+		Order("id desc").
+		First(&revision).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// CountByProduct 统计商品修订数量
+func (r *GormProductRevisionRepository) CountByProduct(productID uint) (int64, error) {
+	var total int64
+	if err := r.db.Model(&models.ProductRevision{}).Where("product_id = ?", productID).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// ListByProduct 获取商品修订列表（新到旧，不含快照内容）
+func (r *GormProductRevisionRepository) ListByProduct(productID uint, page, pageSize int) ([]models.ProductRevision, int64, error) {
+	query := r.db.Model(&models.ProductRevision{}).Where("product_id = ?", productID)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var revisions []models.ProductRevision
+	query = applyPagination(query, page, pageSize)
+	if err := query.Omit("snapshot").Order("id desc").Find(&revisions).Error; err != nil {
+		return nil, 0, err
+	}
+	return revisions, total, nil
+}
+
+// Create 创建修订
+func (r *GormProductRevisionRepository) Create(revision *models.ProductRevision) error {
+	return r.db.Create(revision).Error
+}
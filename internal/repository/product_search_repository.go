@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	productSearchMaxMatches   = 1000
+	productSearchMaxTerms     = 8
+	productSearchFTSMinLength = 3 // trigram 分词要求检索词至少 3 个字符
+	productSearchMaxCandidate = 5000
+)
+
+// ProductSearchMatch 全文检索命中（rank 越大越相关）
+type ProductSearchMatch struct {
+	ProductID uint
+	Rank      float64
+}
+
+// ProductSearchCandidate 检索候选商品（用于过滤、分面与排序）
+type ProductSearchCandidate struct {
+	ID          uint
+	CategoryID  uint
+	Tags        models.StringArray `gorm:"type:json"`
+	PriceAmount models.Money       `gorm:"type:decimal(20,2)"`
+	SortOrder   int
+	CreatedAt   time.Time
+}
+
+// ProductSearchCandidateFilter 候选商品过滤条件
+type ProductSearchCandidateFilter struct {
+	ProductIDs []uint // 非 nil 时仅在这些商品中筛选（全文检索结果）
+	InStock    bool
+}
+
+// ProductSearchRepository 商品搜索数据访问接口
+type ProductSearchRepository interface {
+	WithTx(tx *gorm.DB) *GormProductSearchRepository
+	Index(product *models.Product) error
+	Remove(productID uint) error
+	Match(query string) ([]ProductSearchMatch, error)
+	ListCandidates(filter ProductSearchCandidateFilter) ([]ProductSearchCandidate, error)
+	ListPublicByIDs(ids []uint) ([]models.Product, error)
+}
+
+// GormProductSearchRepository GORM 实现
+type GormProductSearchRepository struct {
+	db *gorm.DB
+}
+
+// NewProductSearchRepository 创建商品搜索仓库
+func NewProductSearchRepository(db *gorm.DB) *GormProductSearchRepository {
+	return &GormProductSearchRepository{db: db}
+}
+
+// WithTx 绑定事务
+func (r *GormProductSearchRepository) WithTx(tx *gorm.DB) *GormProductSearchRepository {
+	if tx == nil {
+		return r
+	}
+	return &GormProductSearchRepository{db: tx}
+}
+
+// Index 写入或刷新商品搜索文档
+func (r *GormProductSearchRepository) Index(product *models.Product) error {
+	return models.IndexProductSearchDocument(r.db, product)
+}
+
+// Remove 删除商品搜索文档
+func (r *GormProductSearchRepository) Remove(productID uint) error {
+	return models.RemoveProductSearchDocument(r.db, productID)
+}
+
+// Match 按关键词检索商品（多个词为 AND 关系），SQLite 使用 FTS5 trigram + bm25，Postgres 使用 tsvector + ts_rank；
+// 不足 3 个字符的词（如两字中文）退化为子串匹配
+func (r *GormProductSearchRepository) Match(text string) ([]ProductSearchMatch, error) {
+	terms := splitSearchTerms(text)
+	if len(terms) == 0 {
+		return []ProductSearchMatch{}, nil
+	}
+	var rows []struct {
+		ProductID uint
+		Rank      float64
+	}
+	var query *gorm.DB
+	switch dbDialectName(r.db) {
+	case "postgres", "postgresql":
+		query = r.matchPostgres(terms)
+	default:
+		query = r.matchSQLite(terms)
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	matches := make([]ProductSearchMatch, 0, len(rows))
+	for _, row := range rows {
+		matches = append(matches, ProductSearchMatch{ProductID: row.ProductID, Rank: row.Rank})
+	}
+	return matches, nil
+}
+
+func (r *GormProductSearchRepository) matchSQLite(terms []string) *gorm.DB {
+	var ftsTerms []string
+	var likeTerms []string
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) >= productSearchFTSMinLength {
+			ftsTerms = append(ftsTerms, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+		} else {
+			likeTerms = append(likeTerms, term)
+		}
+	}
+	var query *gorm.DB
+	if len(ftsTerms) > 0 {
+		query = r.db.Table("product_search_fts").
+			Select("product_id, -bm25(product_search_fts) AS rank").
+			Where("product_search_fts MATCH ?", strings.Join(ftsTerms, " ")).
+			Order("rank DESC")
+	} else {
+		query = r.db.Table("product_search_documents").Select("product_id, 0 AS rank").Order("product_id DESC")
+	}
+	for _, term := range likeTerms {
+		query = query.Where("content LIKE ?", "%"+term+"%")
+	}
+	return query.Limit(productSearchMaxMatches)
+}
+
+func (r *GormProductSearchRepository) matchPostgres(terms []string) *gorm.DB {
+	text := strings.Join(terms, " ")
+	query := r.db.Table("product_search_documents").
+		Select("product_id, ts_rank(search_vector, plainto_tsquery('simple', ?)) AS rank", text)
+	conditions := make([]string, 0, len(terms))
+	args := make([]interface{}, 0, len(terms))
+	for _, term := range terms {
+		conditions = append(conditions, "content ILIKE ?")
+		args = append(args, "%"+term+"%")
+	}
+	// tsvector 按空白分词，无法覆盖中文等连续文本，因此同时接受子串全部命中的文档
+	query = query.Where("search_vector @@ plainto_tsquery('simple', ?) OR ("+strings.Join(conditions, " AND ")+")", append([]interface{}{text}, args...)...)
+	return query.Order("rank DESC").Limit(productSearchMaxMatches)
+}
+
+func splitSearchTerms(query string) []string {
+	fields := strings.Fields(strings.ToLower(query))
+	terms := make([]string, 0, len(fields))
+	seen := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		if _, ok := seen[field]; ok {
+			continue
+		}
+		seen[field] = struct{}{}
+		terms = append(terms, field)
+		if len(terms) >= productSearchMaxTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// ListCandidates 获取上架商品的过滤字段（分类、标签、价格等）
+func (r *GormProductSearchRepository) ListCandidates(filter ProductSearchCandidateFilter) ([]ProductSearchCandidate, error) {
+	if filter.ProductIDs != nil && len(filter.ProductIDs) == 0 {
+		return []ProductSearchCandidate{}, nil
+	}
+	query := r.db.Model(&models.Product{}).
+		Select("id, category_id, tags, price_amount, sort_order, created_at").
+		Where("is_active = ? AND visibility = ?", true, constants.ProductVisibilityPublic)
+	query = applyPublishWindow(query, time.Now())
+	if filter.ProductIDs != nil {
+		query = query.Where("id IN ?", filter.ProductIDs)
+	}
+	if filter.InStock {
+		query = query.Where(r.inStockCondition())
+	}
+	var rows []ProductSearchCandidate
+	if err := query.Limit(productSearchMaxCandidate).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// inStockCondition 有货条件：接口/文件交付与卡密生成器不限库存；人工交付看剩余库存；自动交付看可用卡密
+func (r *GormProductSearchRepository) inStockCondition() *gorm.DB {
+	generatorType := jsonTextExpr(r.db, "key_generator_json", "type")
+	availableSecrets := r.db.Model(&models.CardSecret{}).
+		Select("1").
+		Where("card_secrets.product_id = products.id AND card_secrets.status = ?", models.CardSecretStatusAvailable)
+	return r.db.Where("fulfillment_type IN ?", []string{constants.FulfillmentTypeAPI, constants.FulfillmentTypeFile}).
+		Or("fulfillment_type = ? AND manual_stock_total <> 0", constants.FulfillmentTypeManual).
+		Or("fulfillment_type = ? AND (COALESCE("+generatorType+", '') <> '' OR EXISTS (?))", constants.FulfillmentTypeAuto, availableSecrets)
+}
+
+// ListPublicByIDs 按 ID 加载上架商品（含分类与启用 SKU），返回顺序不保证
+func (r *GormProductSearchRepository) ListPublicByIDs(ids []uint) ([]models.Product, error) {
+	if len(ids) == 0 {
+		return []models.Product{}, nil
+	}
+	now := time.Now()
+	var products []models.Product
+	query := r.db.Preload("Category").
+		Preload("SKUs", func(db *gorm.DB) *gorm.DB {
+			return applyPublishWindow(db.Where("is_active = ?", true), now).Order("sort_order DESC, id ASC")
+		}).
+		Where("id IN ? AND is_active = ?", ids, true)
+	if err := applyPublishWindow(query, now).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
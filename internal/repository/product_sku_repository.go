@@ -3,6 +3,7 @@ package repository
 import (
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/dujiao-next/internal/constants"
 	"github.com/dujiao-next/internal/models"
@@ -51,7 +52,7 @@ func (r *GormProductSKURepository) ListByProduct(productID uint, onlyActive bool
 	}
 	query := r.db.Model(&models.ProductSKU{}).Where("product_id = ?", productID)
 	if onlyActive {
-		query = query.Where("is_active = ?", true)
+		query = applyPublishWindow(query.Where("is_active = ?", true), time.Now())
 	}
 	var items []models.ProductSKU
 	if err := query.Order("sort_order DESC, id ASC").Find(&items).Error; err != nil {
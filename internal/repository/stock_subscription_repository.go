@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// StockSubscriptionRepository 到货通知订阅数据访问接口
+type StockSubscriptionRepository interface {
+	GetByToken(token string) (*models.StockSubscription, error)
+	GetPending(skuID uint, channel, target string) (*models.StockSubscription, error)
+	Create(subscription *models.StockSubscription) error
+	Update(subscription *models.StockSubscription) error
+	ListPendingBySKU(skuID uint, limit int) ([]models.StockSubscription, error)
+	ListPendingSKUIDs(productID uint) ([]uint, error)
+	MarkNotified(id uint, notifiedAt time.Time) (bool, error)
+}
+
+// GormStockSubscriptionRepository GORM 实现
+type GormStockSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+// NewStockSubscriptionRepository 创建到货通知订阅仓库
+func NewStockSubscriptionRepository(db *gorm.DB) *GormStockSubscriptionRepository {
+	return &GormStockSubscriptionRepository{db: db}
+}
+
+// GetByToken 根据退订令牌获取订阅
+func (r *GormStockSubscriptionRepository) GetByToken(token string) (*models.StockSubscription, error) {
+	var subscription models.StockSubscription
+	if err := r.db.Where("token = ?", token).First(&subscription).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// GetPending 获取同一 SKU、渠道、目标下待通知的订阅
+func (r *GormStockSubscriptionRepository) GetPending(skuID uint, channel, target string) (*models.StockSubscription, error) {
+	var subscription models.StockSubscription
+	err := r.db.Where("sku_id = ? AND channel = ? AND target = ? AND status = ?", skuID, channel, target, constants.StockSubscriptionStatusPending).
+		First(&subscription).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// Create 创建订阅
+func (r *GormStockSubscriptionRepository) Create(subscription *models.StockSubscription) error {
+	return r.db.Create(subscription).Error
+}
+
+// Update 更新订阅
+func (r *GormStockSubscriptionRepository) Update(subscription *models.StockSubscription) error {
+	return r.db.Save(subscription).Error
+}
+
+// ListPendingBySKU 按订阅先后获取 SKU 待通知订阅
+func (r *GormStockSubscriptionRepository) ListPendingBySKU(skuID uint, limit int) ([]models.StockSubscription, error) {
+	var subscriptions []models.StockSubscription
+	query := r.db.Where("sku_id = ? AND status = ?", skuID, constants.StockSubscriptionStatusPending).Order("id asc")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// ListPendingSKUIDs 获取商品下存在待通知订阅的 SKU
+func (r *GormStockSubscriptionRepository) ListPendingSKUIDs(productID uint) ([]uint, error) {
+	var skuIDs []uint
+	err := r.db.Model(&models.StockSubscription{}).
+		Where("product_id = ? AND status = ?", productID, constants.StockSubscriptionStatusPending).
+		Distinct().
+		Pluck("sku_id", &skuIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return skuIDs, nil
+}
+
+// MarkNotified 将待通知订阅标记为已通知，返回是否由本次调用完成标记（用于并发任务间抢占）
+func (r *GormStockSubscriptionRepository) MarkNotified(id uint, notifiedAt time.Time) (bool, error) {
+	result := r.db.Model(&models.StockSubscription{}).
+		Where("id = ? AND status = ?", id, constants.StockSubscriptionStatusPending).
+		Updates(map[string]interface{}{
+			"status":      constants.StockSubscriptionStatusNotified,
+			"notified_at": notifiedAt,
+			"updated_at":  notifiedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -15,6 +15,7 @@ type ProductListFilter struct {
 	FulfillmentType   string
 	ManualStockStatus string
 	OnlyActive        bool
+	OnlyListed        bool // 仅公开可见性商品（排除不公开与访问码保护商品）
 	WithCategory      bool
 }
 
@@ -201,3 +202,11 @@ type AffiliateProfileStatsAggregate struct {
 	AvailableCommission decimal.Decimal
 	WithdrawnCommission decimal.Decimal
 }
+
+// FulfillmentSupplierListFilter 上游供应商列表过滤条件
+type FulfillmentSupplierListFilter struct {
+	Page     int
+	PageSize int
+	Search   string
+	IsActive *bool
+}
@@ -59,6 +59,11 @@ func SetupRouter(cfg *config.Config, c *provider.Container) *gin.Engine {
 	// 静态文件服务（上传的图片）- 必须放在最前面
 	r.Static("/uploads", "./uploads")
 
+	// 站点地图与 RSS（面向搜索引擎与订阅阅读器）
+	r.GET("/sitemap.xml", publicHandler.GetSitemap)
+	r.GET("/sitemaps/:file", publicHandler.GetSitemapFile)
+	r.GET("/rss.xml", publicHandler.GetRSS)
+
 	// API 路由组
 	apiV1 := r.Group("/api/v1")
 	{
@@ -67,13 +72,22 @@ func SetupRouter(cfg *config.Config, c *provider.Container) *gin.Engine {
 		{
 			public.GET("/config", publicHandler.GetConfig)
 			public.GET("/products", publicHandler.GetProducts)
+			public.GET("/products/search", publicHandler.SearchProducts)
 			public.GET("/products/:slug", publicHandler.GetProductBySlug)
+			public.GET("/products/:slug/reviews", publicHandler.GetProductReviews)
 			public.GET("/posts", publicHandler.GetPosts)
 			public.GET("/posts/:slug", publicHandler.GetPostBySlug)
 			public.GET("/banners", publicHandler.GetPublicBanners)
 			public.GET("/categories", publicHandler.GetCategories)
+			public.GET("/categories/tree", publicHandler.GetCategoryTree)
 			public.GET("/captcha/image", publicHandler.GetImageCaptcha)
 			public.POST("/affiliate/click", publicHandler.TrackAffiliateClick)
+			public.GET("/downloads/:id", publicHandler.DownloadOrderFile)
+			public.GET("/order-message-attachments/:id/:index", publicHandler.GetOrderMessageAttachment)
+			public.GET("/licenses/public-key", publicHandler.GetLicensePublicKey)
+			public.POST("/licenses/verify", publicHandler.VerifyLicense)
+			public.GET("/stock-subscriptions/unsubscribe", publicHandler.ConfirmStockUnsubscribe)
+			public.POST("/stock-subscriptions/unsubscribe", publicHandler.UnsubscribeStockSubscription)
 		}
 
 		// 游客接口
@@ -84,9 +98,14 @@ func SetupRouter(cfg *config.Config, c *provider.Container) *gin.Engine {
 			guest.GET("/orders", publicHandler.ListGuestOrders)
 			guest.GET("/orders/:id", publicHandler.GetGuestOrder)
 			guest.GET("/orders/by-order-no/:order_no", publicHandler.GetGuestOrderByOrderNo)
+			guest.GET("/orders/:id/receipt", publicHandler.DownloadGuestOrderReceipt)
+			guest.POST("/orders/:id/reveal", publicHandler.RevealGuestOrderSecret)
+			guest.GET("/orders/:id/messages", publicHandler.ListGuestOrderMessages)
+			guest.POST("/orders/:id/messages", publicHandler.PostGuestOrderMessage)
 			guest.POST("/payments", publicHandler.CreateGuestPayment)
 			guest.POST("/payments/:id/capture", publicHandler.CaptureGuestPayment)
 			guest.GET("/payments/latest", publicHandler.GetGuestLatestPayment)
+			guest.POST("/stock-subscriptions", publicHandler.CreateGuestStockSubscription)
 		}
 
 		// 用户认证接口
@@ -120,7 +139,15 @@ func SetupRouter(cfg *config.Config, c *provider.Container) *gin.Engine {
 			user.GET("/orders", publicHandler.ListOrders)
 			user.GET("/orders/:id", publicHandler.GetOrder)
 			user.GET("/orders/by-order-no/:order_no", publicHandler.GetOrderByOrderNo)
+			user.GET("/orders/:id/receipt", publicHandler.DownloadOrderReceipt)
+			user.POST("/orders/:id/reveal", publicHandler.RevealOrderSecret)
+			user.GET("/orders/:id/messages", publicHandler.ListOrderMessages)
+			user.POST("/orders/:id/messages", publicHandler.PostOrderMessage)
+			user.GET("/order-messages/threads", publicHandler.ListOrderMessageThreads)
 			user.POST("/orders/:id/cancel", publicHandler.CancelOrder)
+			user.GET("/reviews", publicHandler.ListMyProductReviews)
+			user.POST("/reviews", publicHandler.CreateProductReview)
+			user.POST("/stock-subscriptions", publicHandler.CreateStockSubscription)
 			user.POST("/payments", publicHandler.CreatePayment)
 			user.POST("/payments/:id/capture", publicHandler.CapturePayment)
 			user.GET("/payments/latest", publicHandler.GetLatestPayment)
@@ -158,10 +185,19 @@ func SetupRouter(cfg *config.Config, c *provider.Container) *gin.Engine {
 
 				// 商品管理
 				authorized.GET("/products", adminHandler.GetAdminProducts)
+				authorized.GET("/products/export", adminHandler.ExportProducts)
+				authorized.POST("/products/import", adminHandler.ImportProducts)
 				authorized.GET("/products/:id", adminHandler.GetAdminProduct)
 				authorized.POST("/products", adminHandler.CreateProduct)
 				authorized.PUT("/products/:id", adminHandler.UpdateProduct)
 				authorized.DELETE("/products/:id", adminHandler.DeleteProduct)
+				authorized.POST("/products/:id/delivery-template/preview", adminHandler.PreviewDeliveryTemplate)
+				authorized.GET("/products/:id/files", adminHandler.ListProductFiles)
+				authorized.POST("/products/:id/files", adminHandler.UploadProductFile)
+				authorized.DELETE("/products/:id/files/:file_id", adminHandler.DeleteProductFile)
+				authorized.GET("/products/:id/revisions", adminHandler.ListProductRevisions)
+				authorized.GET("/products/:id/revisions/:revision_id/diff", adminHandler.DiffProductRevision)
+				authorized.POST("/products/:id/revisions/:revision_id/restore", adminHandler.RestoreProductRevision)
 
 				// 文章管理
 				authorized.GET("/posts", adminHandler.GetAdminPosts)
@@ -178,6 +214,8 @@ func SetupRouter(cfg *config.Config, c *provider.Container) *gin.Engine {
 
 				// 分类管理
 				authorized.GET("/categories", adminHandler.GetAdminCategories)
+				authorized.GET("/categories/tree", adminHandler.GetAdminCategoryTree)
+				authorized.PUT("/categories/reorder", adminHandler.ReorderCategories)
 				authorized.POST("/categories", adminHandler.CreateCategory)
 				authorized.PUT("/categories/:id", adminHandler.UpdateCategory)
 				authorized.DELETE("/categories/:id", adminHandler.DeleteCategory)
@@ -197,6 +235,8 @@ func SetupRouter(cfg *config.Config, c *provider.Container) *gin.Engine {
 				authorized.POST("/settings/notification-center/test", adminHandler.TestNotificationCenterSettings)
 				authorized.GET("/settings/affiliate", adminHandler.GetAffiliateSettings)
 				authorized.PUT("/settings/affiliate", adminHandler.UpdateAffiliateSettings)
+				authorized.GET("/settings/receipt", adminHandler.GetReceiptSettings)
+				authorized.PUT("/settings/receipt", adminHandler.UpdateReceiptSettings)
 				authorized.PUT("/password", adminHandler.UpdateAdminPassword) // 修改密码
 
 				// 推广返利
@@ -235,9 +275,19 @@ func SetupRouter(cfg *config.Config, c *provider.Container) *gin.Engine {
 				authorized.GET("/orders/:id", adminHandler.AdminGetOrder)
 				authorized.PATCH("/orders/:id", adminHandler.AdminUpdateOrderStatus)
 				authorized.POST("/orders/:id/refund-to-wallet", adminHandler.AdminRefundOrderToWallet)
+				authorized.POST("/orders/:id/supplier-retry", adminHandler.AdminRetrySupplierFulfillment)
+				authorized.GET("/orders/:id/downloads", adminHandler.AdminListOrderDownloads)
+				authorized.GET("/orders/:id/messages", adminHandler.AdminListOrderMessages)
+				authorized.POST("/orders/:id/messages", adminHandler.AdminPostOrderMessage)
+				authorized.GET("/order-messages/threads", adminHandler.AdminListOrderMessageThreads)
 				authorized.POST("/fulfillments", adminHandler.AdminCreateFulfillment)
+				authorized.GET("/suppliers", adminHandler.GetAdminSuppliers)
+				authorized.GET("/suppliers/:id", adminHandler.GetAdminSupplier)
+				authorized.POST("/suppliers", adminHandler.CreateSupplier)
+				authorized.PUT("/suppliers/:id", adminHandler.UpdateSupplier)
+				authorized.DELETE("/suppliers/:id", adminHandler.DeleteSupplier)
 				authorized.POST("/card-secrets/batch", adminHandler.CreateCardSecretBatch)
-				authorized.POST("/card-secrets/import", adminHandler.ImportCardSecretCSV)
+				authorized.POST("/card-secrets/import", adminHandler.ImportCardSecrets)
 				authorized.GET("/card-secrets", adminHandler.GetCardSecrets)
 				authorized.PUT("/card-secrets/:id", adminHandler.UpdateCardSecret)
 				authorized.PATCH("/card-secrets/batch-status", adminHandler.BatchUpdateCardSecretStatus)
@@ -258,11 +308,29 @@ func SetupRouter(cfg *config.Config, c *provider.Container) *gin.Engine {
 				authorized.GET("/coupons", adminHandler.GetAdminCoupons)
 				authorized.PUT("/coupons/:id", adminHandler.UpdateCoupon)
 				authorized.DELETE("/coupons/:id", adminHandler.DeleteCoupon)
+				authorized.POST("/coupon-batches", adminHandler.GenerateCouponBatch)
+				authorized.GET("/coupon-batches", adminHandler.GetCouponBatches)
+				authorized.GET("/coupon-batches/:id", adminHandler.GetCouponBatch)
+				authorized.PATCH("/coupon-batches/:id/status", adminHandler.UpdateCouponBatchStatus)
+				authorized.POST("/coupon-batches/:id/export", adminHandler.ExportCouponBatch)
 				authorized.POST("/promotions", adminHandler.CreatePromotion)
 				authorized.GET("/promotions", adminHandler.GetAdminPromotions)
 				authorized.PUT("/promotions/:id", adminHandler.UpdatePromotion)
 				authorized.DELETE("/promotions/:id", adminHandler.DeletePromotion)
 
+				// 商品评价
+				authorized.GET("/reviews", adminHandler.GetAdminProductReviews)
+				authorized.PATCH("/reviews/:id", adminHandler.UpdateProductReviewStatus)
+				authorized.PUT("/reviews/:id/reply", adminHandler.ReplyProductReview)
+
+				// 会员等级
+				authorized.GET("/member-levels", adminHandler.GetAdminMemberLevels)
+				authorized.POST("/member-levels", adminHandler.CreateMemberLevel)
+				authorized.GET("/member-levels/:id", adminHandler.GetAdminMemberLevel)
+				authorized.PUT("/member-levels/:id", adminHandler.UpdateMemberLevel)
+				authorized.DELETE("/member-levels/:id", adminHandler.DeleteMemberLevel)
+				authorized.PUT("/member-levels/:id/prices", adminHandler.UpdateMemberLevelPrices)
+
 				// 支付渠道与支付记录
 				authorized.POST("/payment-channels", adminHandler.CreatePaymentChannel)
 				authorized.GET("/payment-channels", adminHandler.GetPaymentChannels)
@@ -279,6 +347,7 @@ func SetupRouter(cfg *config.Config, c *provider.Container) *gin.Engine {
 				authorized.PUT("/users/batch-status", adminHandler.BatchUpdateUserStatus)
 				authorized.GET("/users/:id", adminHandler.GetAdminUser)
 				authorized.PUT("/users/:id", adminHandler.UpdateAdminUser)
+				authorized.PUT("/users/:id/member-level", adminHandler.UpdateAdminUserMemberLevel)
 				authorized.GET("/users/:id/coupon-usages", adminHandler.GetAdminUserCouponUsages)
 				authorized.GET("/users/:id/wallet", adminHandler.GetAdminUserWallet)
 				authorized.GET("/users/:id/wallet/transactions", adminHandler.GetAdminUserWalletTransactions)
@@ -337,6 +406,16 @@ func buildAdminPermissionCatalog(engine *gin.Engine) []adminPermissionCatalogIte
 		})
 	}
 
+	// 非路由权限：卡密/交付内容明文查看
+	secretObject := constants.AdminPermissionSecretPlaintextObject
+	secretAction := constants.AdminPermissionSecretPlaintextAction
+	items = append(items, adminPermissionCatalogItem{
+		Module:     deriveAdminPermissionModule(secretObject),
+		Method:     secretAction,
+		Object:     secretObject,
+		Permission: secretAction + ":" + secretObject,
+	})
+
 	sort.Slice(items, func(i, j int) bool {
 		if items[i].Module == items[j].Module {
 			if items[i].Object == items[j].Object {
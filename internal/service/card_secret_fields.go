@@ -0,0 +1,458 @@
+package service
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	cardSecretPlainFieldKey      = "secret"
+	cardSecretDefaultDelimiter   = "----"
+	cardSecretStructuredJoiner   = " | "
+	cardSecretImportMaxLineBytes = 1024 * 1024
+)
+
+var cardSecretFieldKeyPattern = regexp.MustCompile(`^[a-z0-9_]{1,64}$`)
+
+type cardSecretField struct {
+	Key      string
+	Label    models.JSON
+	Required bool
+}
+
+// parseCardSecretSchema 解析商品卡密字段 schema，空 schema 表示单列卡密
+func parseCardSecretSchema(schemaJSON models.JSON) ([]cardSecretField, models.JSON, error) {
+	if len(schemaJSON) == 0 {
+		return nil, models.JSON{}, nil
+	}
+	rawFields, ok := schemaJSON["fields"]
+	if !ok {
+		return nil, nil, ErrCardSecretSchemaInvalid
+	}
+	fieldList, ok := rawFields.([]interface{})
+	if !ok {
+		return nil, nil, ErrCardSecretSchemaInvalid
+	}
+	if len(fieldList) == 0 {
+		return nil, models.JSON{}, nil
+	}
+
+	fields := make([]cardSecretField, 0, len(fieldList))
+	normalizedFields := make([]models.JSON, 0, len(fieldList))
+	keys := make(map[string]struct{}, len(fieldList))
+	hasRequired := false
+	for _, rawField := range fieldList {
+		fieldMap, ok := rawField.(map[string]interface{})
+		if !ok {
+			return nil, nil, ErrCardSecretSchemaInvalid
+		}
+		key, ok := trimStringField(fieldMap, "key")
+		if !ok || !cardSecretFieldKeyPattern.MatchString(key) {
+			return nil, nil, ErrCardSecretSchemaInvalid
+		}
+		if _, exists := keys[key]; exists {
+			return nil, nil, ErrCardSecretSchemaInvalid
+		}
+		keys[key] = struct{}{}
+		label, err := parseLocaleTextMapStrict(fieldMap, "label")
+		if err != nil {
+			return nil, nil, ErrCardSecretSchemaInvalid
+		}
+		required, err := parseBoolFieldStrict(fieldMap, "required")
+		if err != nil {
+			return nil, nil, ErrCardSecretSchemaInvalid
+		}
+		if required {
+			hasRequired = true
+		}
+		fields = append(fields, cardSecretField{Key: key, Label: label, Required: required})
+		normalizedField := models.JSON{
+			"key":      key,
+			"required": required,
+		}
+		if len(label) > 0 {
+			normalizedField["label"] = label
+		}
+		normalizedFields = append(normalizedFields, normalizedField)
+	}
+	// 至少一个必填字段，保证每条卡密都有可交付内容
+	if !hasRequired {
+		return nil, nil, ErrCardSecretSchemaInvalid
+	}
+	return fields, models.JSON{"fields": normalizedFields}, nil
+}
+
+// cardSecretImportColumns 返回导入时的列定义，未配置 schema 的商品仅有 secret 一列
+func cardSecretImportColumns(fields []cardSecretField) []cardSecretField {
+	if len(fields) > 0 {
+		return fields
+	}
+	return []cardSecretField{{Key: cardSecretPlainFieldKey, Required: true}}
+}
+
+// cardSecretRecord 待录入的单条卡密
+type cardSecretRecord struct {
+	Secret string
+	Fields models.JSON
+}
+
+// buildStructuredCardSecretRecords 按 schema 校验结构化行，空行跳过，缺少必填字段时报错并给出行号
+func buildStructuredCardSecretRecords(fields []cardSecretField, rows []map[string]string) ([]cardSecretRecord, error) {
+	records := make([]cardSecretRecord, 0, len(rows))
+	for idx, row := range rows {
+		record, empty, err := buildStructuredCardSecretRecord(fields, row)
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: %v", ErrCardSecretInvalid, idx+1, err)
+		}
+		if empty {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func buildStructuredCardSecretRecord(fields []cardSecretField, row map[string]string) (cardSecretRecord, bool, error) {
+	values := make(models.JSON, len(fields))
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		value := strings.TrimSpace(strings.TrimPrefix(row[field.Key], "\ufeff"))
+		if value == "" {
+			continue
+		}
+		values[field.Key] = value
+		parts = append(parts, value)
+	}
+	if len(values) == 0 {
+		return cardSecretRecord{}, true, nil
+	}
+	for _, field := range fields {
+		if field.Required {
+			if _, ok := values[field.Key]; !ok {
+				return cardSecretRecord{}, false, fmt.Errorf("field %s is required", field.Key)
+			}
+		}
+	}
+	return cardSecretRecord{
+		Secret: strings.Join(parts, cardSecretStructuredJoiner),
+		Fields: values,
+	}, false, nil
+}
+
+func plainCardSecretRecords(secrets []string) []cardSecretRecord {
+	normalized := normalizeSecrets(secrets)
+	records := make([]cardSecretRecord, 0, len(normalized))
+	for _, secret := range normalized {
+		records = append(records, cardSecretRecord{Secret: secret})
+	}
+	return records
+}
+
+// splitDelimitedCardSecretLines 将粘贴或 TXT 文本按分隔符拆成结构化行，列顺序与 schema 一致
+func splitDelimitedCardSecretLines(lines []string, columns []cardSecretField, delimiter string) []map[string]string {
+	if delimiter == "" {
+		delimiter = cardSecretDefaultDelimiter
+	}
+	rows := make([]map[string]string, 0, len(lines))
+	for _, raw := range lines {
+		for _, line := range strings.Split(raw, "\n") {
+			line = strings.TrimSpace(strings.TrimPrefix(line, "\ufeff"))
+			if line == "" {
+				continue
+			}
+			var parts []string
+			if len(columns) == 1 {
+				parts = []string{line}
+			} else {
+				parts = strings.SplitN(line, delimiter, len(columns))
+			}
+			row := make(map[string]string, len(columns))
+			for i, part := range parts {
+				row[columns[i].Key] = strings.TrimSpace(part)
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// resolveCardSecretImportFormat 优先使用显式格式，否则按文件扩展名识别
+func resolveCardSecretImportFormat(format, filename string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(format))
+	if normalized == "" {
+		normalized = strings.TrimPrefix(strings.ToLower(filepath.Ext(strings.TrimSpace(filename))), ".")
+	}
+	switch normalized {
+	case constants.CardSecretSourceCSV, constants.CardSecretSourceXLSX, constants.CardSecretSourceTXT:
+		return normalized, nil
+	case constants.CardSecretSourceJSONL, "ndjson":
+		return constants.CardSecretSourceJSONL, nil
+	default:
+		return "", ErrCardSecretInvalid
+	}
+}
+
+// parseCardSecretImport 解析导入文件为按字段 key 组织的行
+func parseCardSecretImport(reader io.Reader, format string, columns []cardSecretField, mapping map[string]string, delimiter string) ([]map[string]string, error) {
+	switch format {
+	case constants.CardSecretSourceCSV:
+		csvReader := csv.NewReader(reader)
+		csvReader.TrimLeadingSpace = true
+		csvReader.FieldsPerRecord = -1
+		records, err := csvReader.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		return mapCardSecretTableRows(records, columns, mapping)
+	case constants.CardSecretSourceXLSX:
+		book, err := excelize.OpenReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer book.Close()
+		sheets := book.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, nil
+		}
+		records, err := book.GetRows(sheets[0])
+		if err != nil {
+			return nil, err
+		}
+		return mapCardSecretTableRows(records, columns, mapping)
+	case constants.CardSecretSourceJSONL:
+		return parseCardSecretJSONLines(reader, columns, mapping)
+	case constants.CardSecretSourceTXT:
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		return splitDelimitedCardSecretLines([]string{string(content)}, columns, delimiter), nil
+	default:
+		return nil, ErrCardSecretInvalid
+	}
+}
+
+// mapCardSecretTableRows 识别表头并按映射取列；无可识别表头时按列顺序读取且首行视为数据
+func mapCardSecretTableRows(records [][]string, columns []cardSecretField, mapping map[string]string) ([]map[string]string, error) {
+	columnIndex := make(map[string]int, len(columns))
+	headerRow := -1
+	for idx, record := range records {
+		if isBlankRecord(record) {
+			continue
+		}
+		for cellIdx, cell := range record {
+			key, err := resolveCardSecretColumnKey(cell, columns, mapping)
+			if err != nil {
+				return nil, err
+			}
+			if key == "" {
+				continue
+			}
+			if _, exists := columnIndex[key]; !exists {
+				columnIndex[key] = cellIdx
+			}
+		}
+		if len(columnIndex) > 0 {
+			headerRow = idx
+		}
+		break
+	}
+	if headerRow < 0 {
+		if len(mapping) > 0 {
+			return nil, ErrCardSecretInvalid
+		}
+		for i, column := range columns {
+			columnIndex[column.Key] = i
+		}
+	}
+
+	rows := make([]map[string]string, 0, len(records))
+	for idx, record := range records {
+		if idx <= headerRow || isBlankRecord(record) {
+			continue
+		}
+		row := make(map[string]string, len(columnIndex))
+		for key, cellIdx := range columnIndex {
+			if cellIdx < len(record) {
+				row[key] = record[cellIdx]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// resolveCardSecretColumnKey 表头优先按管理员映射匹配，其次匹配字段 key 或任意语言的字段名
+func resolveCardSecretColumnKey(header string, columns []cardSecretField, mapping map[string]string) (string, error) {
+	normalized := strings.TrimSpace(strings.TrimPrefix(header, "\ufeff"))
+	if normalized == "" {
+		return "", nil
+	}
+	for source, target := range mapping {
+		if !strings.EqualFold(strings.TrimSpace(source), normalized) {
+			continue
+		}
+		target = strings.TrimSpace(target)
+		if target == "" {
+			return "", nil
+		}
+		for _, column := range columns {
+			if column.Key == target {
+				return target, nil
+			}
+		}
+		return "", ErrCardSecretInvalid
+	}
+	for _, column := range columns {
+		if strings.EqualFold(column.Key, normalized) {
+			return column.Key, nil
+		}
+		for _, label := range column.Label {
+			if text, ok := label.(string); ok && strings.EqualFold(strings.TrimSpace(text), normalized) {
+				return column.Key, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// parseCardSecretJSONLines 每行一个 JSON 对象，键名同样支持映射；单列商品也接受 JSON 字符串行
+func parseCardSecretJSONLines(reader io.Reader, columns []cardSecretField, mapping map[string]string) ([]map[string]string, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), cardSecretImportMaxLineBytes)
+	rows := make([]map[string]string, 0)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if line == "" {
+			continue
+		}
+		if len(columns) == 1 && strings.HasPrefix(line, "\"") {
+			var value string
+			if err := json.Unmarshal([]byte(line), &value); err != nil {
+				return nil, err
+			}
+			rows = append(rows, map[string]string{columns[0].Key: value})
+			continue
+		}
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &object); err != nil {
+			return nil, err
+		}
+		row := make(map[string]string, len(columns))
+		for rawKey, rawValue := range object {
+			key, err := resolveCardSecretColumnKey(rawKey, columns, mapping)
+			if err != nil {
+				return nil, err
+			}
+			if key == "" || rawValue == nil {
+				continue
+			}
+			if text, ok := rawValue.(string); ok {
+				row[key] = text
+			} else {
+				row[key] = fmt.Sprintf("%v", rawValue)
+			}
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(strings.TrimPrefix(cell, "\ufeff")) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveCardSecretFieldLabel 按语言取字段名，缺失时回退到字段 key
+func resolveCardSecretFieldLabel(field cardSecretField, locale string) string {
+	candidates := []string{locale, constants.LocaleZhCN, constants.LocaleEnUS, constants.LocaleZhTW}
+	for _, key := range candidates {
+		if value, ok := field.Label[key]; ok {
+			if text := strings.TrimSpace(fmt.Sprintf("%v", value)); text != "" {
+				return text
+			}
+		}
+	}
+	return field.Key
+}
+
+// renderCardSecretPayload 结构化卡密渲染为表格（邮件与订单详情共用），普通卡密逐行输出
+func renderCardSecretPayload(fields []cardSecretField, secrets []models.CardSecret, locale string) string {
+	lines := make([]string, 0, len(secrets))
+	tableRows := make([][]string, 0, len(secrets))
+	for _, secret := range secrets {
+		if len(fields) == 0 || len(secret.Fields) == 0 {
+			lines = append(lines, secret.Secret)
+			continue
+		}
+		row := make([]string, 0, len(fields))
+		for _, field := range fields {
+			value := ""
+			if raw, ok := secret.Fields[field.Key]; ok && raw != nil {
+				value = fmt.Sprintf("%v", raw)
+			}
+			row = append(row, escapeCardSecretTableCell(value))
+		}
+		tableRows = append(tableRows, row)
+	}
+	if len(tableRows) == 0 {
+		return strings.Join(lines, "\n")
+	}
+
+	headers := make([]string, 0, len(fields))
+	separators := make([]string, 0, len(fields))
+	for _, field := range fields {
+		headers = append(headers, escapeCardSecretTableCell(resolveCardSecretFieldLabel(field, locale)))
+		separators = append(separators, "---")
+	}
+	table := make([]string, 0, len(tableRows)+2)
+	table = append(table, "| "+strings.Join(headers, " | ")+" |")
+	table = append(table, "| "+strings.Join(separators, " | ")+" |")
+	for _, row := range tableRows {
+		table = append(table, "| "+strings.Join(row, " | ")+" |")
+	}
+	if len(lines) == 0 {
+		return strings.Join(table, "\n")
+	}
+	return strings.Join(table, "\n") + "\n\n" + strings.Join(lines, "\n")
+}
+
+func escapeCardSecretTableCell(value string) string {
+	value = strings.ReplaceAll(value, "\r", "")
+	value = strings.ReplaceAll(value, "\n", " ")
+	return strings.ReplaceAll(value, "|", "\\|")
+}
+
+// MaskCardSecret 脱敏卡密内容与结构化字段
+func MaskCardSecret(item *models.CardSecret) {
+	if item == nil {
+		return
+	}
+	item.Secret = MaskSecretValue(item.Secret)
+	if len(item.Fields) == 0 {
+		return
+	}
+	masked := make(models.JSON, len(item.Fields))
+	for key, value := range item.Fields {
+		masked[key] = MaskSecretValue(fmt.Sprintf("%v", value))
+	}
+	item.Fields = masked
+}
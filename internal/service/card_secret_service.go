@@ -3,9 +3,9 @@ package service
 import (
 	"bytes"
 	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"math/rand"
 	"mime/multipart"
 	"strconv"
@@ -25,6 +25,7 @@ type CardSecretService struct {
 	batchRepo      repository.CardSecretBatchRepository
 	productRepo    repository.ProductRepository
 	productSKURepo repository.ProductSKURepository
+	stockSubSvc    *StockSubscriptionService
 }
 
 // NewCardSecretService 创建卡密库存服务
@@ -37,45 +38,69 @@ func NewCardSecretService(secretRepo repository.CardSecretRepository, batchRepo
 	}
 }
 
+// SetStockSubscriptionService 设置到货通知服务，录入卡密后触发补货通知
+func (s *CardSecretService) SetStockSubscriptionService(svc *StockSubscriptionService) {
+	s.stockSubSvc = svc
+}
+
 // CreateCardSecretBatchInput 批量录入卡密输入
 type CreateCardSecretBatchInput struct {
 	ProductID uint
 	SKUID     uint
 	Secrets   []string
+	Rows      []map[string]string // 结构化卡密行（商品配置了卡密字段 schema 时使用）
+	Delimiter string              // 结构化商品粘贴文本的字段分隔符
 	BatchNo   string
 	Note      string
 	Source    string
 	AdminID   uint
 }
 
-// CreateCardSecretBatch 批量录入卡密
+// CreateCardSecretBatch 批量录入卡密，同商品下已存在的卡密会被跳过并计入批次重复数
 func (s *CardSecretService) CreateCardSecretBatch(input CreateCardSecretBatchInput) (*models.CardSecretBatch, int, error) {
 	if input.ProductID == 0 {
 		return nil, 0, ErrCardSecretInvalid
 	}
-	if len(input.Secrets) == 0 {
+	if len(input.Secrets) == 0 && len(input.Rows) == 0 {
 		return nil, 0, ErrCardSecretInvalid
 	}
 
-	product, err := s.productRepo.GetByID(strings.TrimSpace(strconv.FormatUint(uint64(input.ProductID), 10)))
-	if err != nil {
-		return nil, 0, ErrProductFetchFailed
-	}
-	if product == nil {
-		return nil, 0, ErrProductNotFound
-	}
-	sku, err := s.resolveCardSecretSKU(product.ID, input.SKUID)
+	product, sku, fields, err := s.resolveCardSecretTarget(input.ProductID, input.SKUID)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	normalized := normalizeSecrets(input.Secrets)
-	if len(normalized) == 0 {
+	var records []cardSecretRecord
+	if len(fields) > 0 {
+		rows := append([]map[string]string{}, input.Rows...)
+		rows = append(rows, splitDelimitedCardSecretLines(input.Secrets, fields, input.Delimiter)...)
+		records, err = buildStructuredCardSecretRecords(fields, rows)
+		if err != nil {
+			return nil, 0, err
+		}
+	} else {
+		if len(input.Rows) > 0 {
+			return nil, 0, ErrCardSecretInvalid
+		}
+		records = plainCardSecretRecords(input.Secrets)
+	}
+	return s.createCardSecretRecords(product.ID, sku.ID, records, input)
+}
+
+func (s *CardSecretService) createCardSecretRecords(productID, skuID uint, records []cardSecretRecord, input CreateCardSecretBatchInput) (*models.CardSecretBatch, int, error) {
+	if len(records) == 0 {
 		return nil, 0, ErrCardSecretInvalid
 	}
 	if s.batchRepo == nil {
 		return nil, 0, ErrCardSecretBatchCreateFailed
 	}
+	unique, duplicates, err := s.filterDuplicateCardSecrets(productID, records)
+	if err != nil {
+		return nil, 0, ErrCardSecretCreateFailed
+	}
+	if len(unique) == 0 {
+		return nil, 0, ErrCardSecretDuplicate
+	}
 
 	batchNo := strings.TrimSpace(input.BatchNo)
 	if batchNo == "" {
@@ -88,11 +113,12 @@ func (s *CardSecretService) CreateCardSecretBatch(input CreateCardSecretBatchInp
 
 	now := time.Now()
 	batch := &models.CardSecretBatch{
-		ProductID:  input.ProductID,
-		SKUID:      sku.ID,
+		ProductID:  productID,
+		SKUID:      skuID,
 		BatchNo:    batchNo,
 		Source:     source,
-		TotalCount: len(normalized),
+		TotalCount: len(unique),
+		Duplicates: duplicates,
 		Note:       strings.TrimSpace(input.Note),
 		CreatedAt:  now,
 		UpdatedAt:  now,
@@ -107,13 +133,14 @@ func (s *CardSecretService) CreateCardSecretBatch(input CreateCardSecretBatchInp
 		if err := batchRepo.Create(batch); err != nil {
 			return ErrCardSecretBatchCreateFailed
 		}
-		items := make([]models.CardSecret, 0, len(normalized))
-		for _, secret := range normalized {
+		items := make([]models.CardSecret, 0, len(unique))
+		for _, record := range unique {
 			items = append(items, models.CardSecret{
-				ProductID: input.ProductID,
-				SKUID:     sku.ID,
+				ProductID: productID,
+				SKUID:     skuID,
 				BatchID:   &batch.ID,
-				Secret:    secret,
+				Secret:    record.Secret,
+				Fields:    record.Fields,
 				Status:    models.CardSecretStatusAvailable,
 				CreatedAt: now,
 				UpdatedAt: now,
@@ -130,24 +157,84 @@ func (s *CardSecretService) CreateCardSecretBatch(input CreateCardSecretBatchInp
 		}
 		return nil, 0, ErrCardSecretCreateFailed
 	}
+	s.stockSubSvc.TriggerRestock(productID)
 	return batch, batch.TotalCount, nil
 }
 
-// ImportCardSecretCSVInput 导入 CSV 输入
-type ImportCardSecretCSVInput struct {
+// filterDuplicateCardSecrets 按内容哈希去重：先去掉本次录入内的重复，再排除商品下已有的卡密
+// 已有卡密按密钥环内任一密钥的哈希比对，密钥轮换未完成时同样能识别重复
+func (s *CardSecretService) filterDuplicateCardSecrets(productID uint, records []cardSecretRecord) ([]cardSecretRecord, int, error) {
+	unique := make([]cardSecretRecord, 0, len(records))
+	candidates := make([][]string, 0, len(records))
+	lookup := make([]string, 0, len(records))
+	seen := make(map[string]struct{}, len(records))
+	for _, record := range records {
+		hash := models.CardSecretContentHash(record.Secret, record.Fields)
+		if _, ok := seen[hash]; ok {
+			continue
+		}
+		seen[hash] = struct{}{}
+		hashes := models.CardSecretLookupHashes(record.Secret, record.Fields)
+		unique = append(unique, record)
+		candidates = append(candidates, hashes)
+		lookup = append(lookup, hashes...)
+	}
+	existing, err := s.secretRepo.ListExistingHashes(productID, lookup)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(existing) == 0 {
+		return unique, len(records) - len(unique), nil
+	}
+	existingSet := make(map[string]struct{}, len(existing))
+	for _, hash := range existing {
+		existingSet[hash] = struct{}{}
+	}
+	filtered := make([]cardSecretRecord, 0, len(unique))
+	for i, record := range unique {
+		if containsAnyHash(existingSet, candidates[i]) {
+			continue
+		}
+		filtered = append(filtered, record)
+	}
+	return filtered, len(records) - len(filtered), nil
+}
+
+func containsAnyHash(set map[string]struct{}, hashes []string) bool {
+	for _, hash := range hashes {
+		if _, ok := set[hash]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportCardSecretsInput 文件导入卡密输入
+type ImportCardSecretsInput struct {
 	ProductID uint
 	SKUID     uint
 	File      *multipart.FileHeader
+	Format    string            // csv/xlsx/jsonl/txt，为空时按扩展名识别
+	Mapping   map[string]string // 表头（或 JSON 键）到卡密字段 key 的映射
+	Delimiter string            // TXT 字段分隔符
 	BatchNo   string
 	Note      string
 	AdminID   uint
 }
 
-// ImportCardSecretCSV 从 CSV 导入卡密
-func (s *CardSecretService) ImportCardSecretCSV(input ImportCardSecretCSVInput) (*models.CardSecretBatch, int, error) {
+// ImportCardSecrets 从 CSV/XLSX/JSON Lines/TXT 文件导入卡密
+func (s *CardSecretService) ImportCardSecrets(input ImportCardSecretsInput) (*models.CardSecretBatch, int, error) {
 	if input.ProductID == 0 || input.File == nil {
 		return nil, 0, ErrCardSecretInvalid
 	}
+	format, err := resolveCardSecretImportFormat(input.Format, input.File.Filename)
+	if err != nil {
+		return nil, 0, err
+	}
+	product, sku, fields, err := s.resolveCardSecretTarget(input.ProductID, input.SKUID)
+	if err != nil {
+		return nil, 0, err
+	}
 
 	file, err := input.File.Open()
 	if err != nil {
@@ -155,21 +242,72 @@ func (s *CardSecretService) ImportCardSecretCSV(input ImportCardSecretCSVInput)
 	}
 	defer file.Close()
 
-	secrets, err := parseCSVSecrets(file)
+	columns := cardSecretImportColumns(fields)
+	rows, err := parseCardSecretImport(file, format, columns, input.Mapping, input.Delimiter)
 	if err != nil {
-		return nil, 0, ErrCardSecretImportFailed
+		if errors.Is(err, ErrCardSecretInvalid) {
+			return nil, 0, err
+		}
+		return nil, 0, fmt.Errorf("%w: %v", ErrCardSecretImportFailed, err)
 	}
-	return s.CreateCardSecretBatch(CreateCardSecretBatchInput{
-		ProductID: input.ProductID,
-		SKUID:     input.SKUID,
-		Secrets:   secrets,
-		BatchNo:   input.BatchNo,
-		Note:      input.Note,
-		Source:    constants.CardSecretSourceCSV,
-		AdminID:   input.AdminID,
+
+	var records []cardSecretRecord
+	if len(fields) > 0 {
+		records, err = buildStructuredCardSecretRecords(fields, rows)
+		if err != nil {
+			return nil, 0, err
+		}
+	} else {
+		secrets := make([]string, 0, len(rows))
+		for _, row := range rows {
+			secrets = append(secrets, strings.TrimPrefix(row[cardSecretPlainFieldKey], "\ufeff"))
+		}
+		records = plainCardSecretRecords(secrets)
+	}
+	return s.createCardSecretRecords(product.ID, sku.ID, records, CreateCardSecretBatchInput{
+		BatchNo: input.BatchNo,
+		Note:    input.Note,
+		Source:  format,
+		AdminID: input.AdminID,
 	})
 }
 
+// BuildImportTemplate 生成 CSV 导入模板，结构化商品按 schema 输出表头
+func (s *CardSecretService) BuildImportTemplate(productID uint) ([]byte, error) {
+	columns := cardSecretImportColumns(nil)
+	if productID > 0 {
+		product, err := s.productRepo.GetByID(strconv.FormatUint(uint64(productID), 10))
+		if err != nil {
+			return nil, ErrProductFetchFailed
+		}
+		if product == nil {
+			return nil, ErrProductNotFound
+		}
+		fields, _, err := parseCardSecretSchema(product.SecretSchemaJSON)
+		if err != nil {
+			return nil, err
+		}
+		columns = cardSecretImportColumns(fields)
+	}
+	if len(columns) == 1 && columns[0].Key == cardSecretPlainFieldKey {
+		return []byte("secret\nCARD-AAA-0001\nCARD-BBB-0002\n"), nil
+	}
+	buffer := bytes.NewBuffer(nil)
+	writer := csv.NewWriter(buffer)
+	header := make([]string, 0, len(columns))
+	for _, column := range columns {
+		header = append(header, column.Key)
+	}
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
 // ListCardSecretInput 卡密列表输入
 type ListCardSecretInput struct {
 	ProductID uint
@@ -177,6 +315,7 @@ type ListCardSecretInput struct {
 	Status    string
 	Page      int
 	PageSize  int
+	Reveal    bool // 是否返回明文，否则脱敏
 }
 
 // ListCardSecrets 获取卡密列表
@@ -204,6 +343,11 @@ func (s *CardSecretService) ListCardSecrets(input ListCardSecretInput) ([]models
 	if err != nil {
 		return nil, 0, ErrCardSecretFetchFailed
 	}
+	if !input.Reveal {
+		for i := range items {
+			MaskCardSecret(&items[i])
+		}
+	}
 	return items, total, nil
 }
 
@@ -239,8 +383,8 @@ func (s *CardSecretService) BatchDeleteCardSecrets(ids []uint) (int64, error) {
 	return rows, nil
 }
 
-// ExportCardSecrets 批量导出卡密（txt/csv）
-func (s *CardSecretService) ExportCardSecrets(ids []uint, format string) ([]byte, string, error) {
+// ExportCardSecrets 批量导出卡密（txt/csv），reveal 为 false 时导出脱敏内容
+func (s *CardSecretService) ExportCardSecrets(ids []uint, format string, reveal bool) ([]byte, string, error) {
 	normalizedIDs := normalizeCardSecretIDs(ids)
 	if len(normalizedIDs) == 0 {
 		return nil, "", ErrCardSecretInvalid
@@ -259,6 +403,11 @@ func (s *CardSecretService) ExportCardSecrets(ids []uint, format string) ([]byte
 	if len(items) == 0 {
 		return nil, "", ErrNotFound
 	}
+	if !reveal {
+		for i := range items {
+			MaskCardSecret(&items[i])
+		}
+	}
 
 	if normalizedFormat == constants.ExportFormatTXT {
 		lines := make([]string, 0, len(items))
@@ -274,7 +423,7 @@ func (s *CardSecretService) ExportCardSecrets(ids []uint, format string) ([]byte
 
 	buffer := bytes.NewBuffer(nil)
 	writer := csv.NewWriter(buffer)
-	header := []string{"id", "secret", "status", "product_id", "sku_id", "order_id", "batch_id", "created_at"}
+	header := []string{"id", "secret", "fields", "status", "product_id", "sku_id", "order_id", "batch_id", "created_at"}
 	if err := writer.Write(header); err != nil {
 		return nil, "", ErrCardSecretFetchFailed
 	}
@@ -287,9 +436,18 @@ func (s *CardSecretService) ExportCardSecrets(ids []uint, format string) ([]byte
 		if item.BatchID != nil {
 			batchID = strconv.FormatUint(uint64(*item.BatchID), 10)
 		}
+		fields := ""
+		if len(item.Fields) > 0 {
+			raw, err := json.Marshal(item.Fields)
+			if err != nil {
+				return nil, "", ErrCardSecretFetchFailed
+			}
+			fields = string(raw)
+		}
 		row := []string{
 			strconv.FormatUint(uint64(item.ID), 10),
 			item.Secret,
+			fields,
 			item.Status,
 			strconv.FormatUint(uint64(item.ProductID), 10),
 			strconv.FormatUint(uint64(item.SKUID), 10),
@@ -308,8 +466,8 @@ func (s *CardSecretService) ExportCardSecrets(ids []uint, format string) ([]byte
 	return buffer.Bytes(), "text/csv; charset=utf-8", nil
 }
 
-// UpdateCardSecret 更新卡密
-func (s *CardSecretService) UpdateCardSecret(id uint, secret, status string) (*models.CardSecret, error) {
+// UpdateCardSecret 更新卡密，结构化商品通过 fields 修改字段
+func (s *CardSecretService) UpdateCardSecret(id uint, secret string, fields map[string]string, status string) (*models.CardSecret, error) {
 	if id == 0 {
 		return nil, ErrCardSecretInvalid
 	}
@@ -320,9 +478,39 @@ func (s *CardSecretService) UpdateCardSecret(id uint, secret, status string) (*m
 	if item == nil {
 		return nil, ErrNotFound
 	}
-	trimmedSecret := strings.TrimSpace(secret)
-	if trimmedSecret != "" {
-		item.Secret = trimmedSecret
+	var record *cardSecretRecord
+	if len(fields) > 0 {
+		_, _, schemaFields, err := s.resolveCardSecretTarget(item.ProductID, item.SKUID)
+		if err != nil {
+			return nil, err
+		}
+		if len(schemaFields) == 0 {
+			return nil, ErrCardSecretInvalid
+		}
+		built, empty, err := buildStructuredCardSecretRecord(schemaFields, fields)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCardSecretInvalid, err)
+		}
+		if empty {
+			return nil, ErrCardSecretInvalid
+		}
+		record = &built
+	} else if trimmedSecret := strings.TrimSpace(secret); trimmedSecret != "" {
+		record = &cardSecretRecord{Secret: trimmedSecret}
+	}
+	if record != nil {
+		hashes := models.CardSecretLookupHashes(record.Secret, record.Fields)
+		if !containsAnyHash(map[string]struct{}{item.SecretHash: {}}, hashes) {
+			existing, err := s.secretRepo.ListExistingHashes(item.ProductID, hashes)
+			if err != nil {
+				return nil, ErrCardSecretFetchFailed
+			}
+			if len(existing) > 0 {
+				return nil, ErrCardSecretDuplicate
+			}
+		}
+		item.Secret = record.Secret
+		item.Fields = record.Fields
 	}
 	trimmedStatus := strings.TrimSpace(status)
 	if trimmedStatus != "" {
@@ -394,6 +582,26 @@ func (s *CardSecretService) ListBatches(productID, skuID uint, page, pageSize in
 	return items, total, nil
 }
 
+// resolveCardSecretTarget 校验商品与 SKU 并解析商品卡密字段 schema
+func (s *CardSecretService) resolveCardSecretTarget(productID, skuID uint) (*models.Product, *models.ProductSKU, []cardSecretField, error) {
+	product, err := s.productRepo.GetByID(strconv.FormatUint(uint64(productID), 10))
+	if err != nil {
+		return nil, nil, nil, ErrProductFetchFailed
+	}
+	if product == nil {
+		return nil, nil, nil, ErrProductNotFound
+	}
+	sku, err := s.resolveCardSecretSKU(product.ID, skuID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	fields, _, err := parseCardSecretSchema(product.SecretSchemaJSON)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return product, sku, fields, nil
+}
+
 func (s *CardSecretService) resolveCardSecretSKU(productID, rawSKUID uint) (*models.ProductSKU, error) {
 	if productID == 0 || s.productSKURepo == nil {
 		return nil, ErrProductSKUInvalid
@@ -446,51 +654,6 @@ func normalizeSecrets(values []string) []string {
 	return result
 }
 
-func parseCSVSecrets(reader io.Reader) ([]string, error) {
-	csvReader := csv.NewReader(reader)
-	csvReader.TrimLeadingSpace = true
-	var (
-		secrets    []string
-		headerRead bool
-		secretIdx  = 0
-	)
-	for {
-		record, err := csvReader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-		if len(record) == 0 {
-			continue
-		}
-		if !headerRead {
-			headerRead = true
-			skipRow := false
-			for i, col := range record {
-				if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(col, "\ufeff")), "secret") {
-					secretIdx = i
-					skipRow = true
-					break
-				}
-			}
-			if skipRow {
-				continue
-			}
-		}
-		if secretIdx >= len(record) {
-			continue
-		}
-		secret := strings.TrimSpace(strings.TrimPrefix(record[secretIdx], "\ufeff"))
-		if secret == "" {
-			continue
-		}
-		secrets = append(secrets, secret)
-	}
-	return normalizeSecrets(secrets), nil
-}
-
 func generateBatchNo() string {
 	now := time.Now().Format("20060102150405")
 	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
@@ -515,3 +678,24 @@ func normalizeCardSecretIDs(ids []uint) []uint {
 	}
 	return result
 }
+
+// MaskSecretValue 脱敏卡密/交付内容，逐行保留首尾各两位
+func MaskSecretValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	lines := strings.Split(value, "\n")
+	for i, line := range lines {
+		runes := []rune(strings.TrimSpace(line))
+		if len(runes) == 0 {
+			lines[i] = ""
+			continue
+		}
+		if len(runes) <= 6 {
+			lines[i] = strings.Repeat("*", len(runes))
+			continue
+		}
+		lines[i] = string(runes[:2]) + strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-2:])
+	}
+	return strings.Join(lines, "\n")
+}
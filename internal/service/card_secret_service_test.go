@@ -1,7 +1,10 @@
 package service
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,6 +14,7 @@ import (
 
 	"github.com/glebarez/sqlite"
 	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
 	"gorm.io/gorm"
 )
 
@@ -106,3 +110,162 @@ func TestCreateCardSecretBatchFallbackToDefaultSKU(t *testing.T) {
 		}
 	}
 }
+
+func accountSecretSchema() models.JSON {
+	return models.JSON{"fields": []interface{}{
+		map[string]interface{}{"key": "username", "label": map[string]interface{}{"zh-CN": "账号", "en-US": "Username"}, "required": true},
+		map[string]interface{}{"key": "password", "label": map[string]interface{}{"zh-CN": "密码", "en-US": "Password"}, "required": true},
+		map[string]interface{}{"key": "recovery_email", "label": map[string]interface{}{"zh-CN": "辅助邮箱"}},
+	}}
+}
+
+func TestCreateCardSecretBatchStructuredSkipsDuplicates(t *testing.T) {
+	db := setupCardSecretServiceTestDB(t)
+
+	product := &models.Product{
+		CategoryID:       1,
+		Slug:             "account-product",
+		TitleJSON:        models.JSON{"zh-CN": "账号商品"},
+		PriceAmount:      models.NewMoneyFromDecimal(decimal.NewFromInt(20)),
+		FulfillmentType:  constants.FulfillmentTypeAuto,
+		SecretSchemaJSON: accountSecretSchema(),
+		IsActive:         true,
+	}
+	if err := db.Create(product).Error; err != nil {
+		t.Fatalf("create product failed: %v", err)
+	}
+	if err := db.Create(&models.ProductSKU{
+		ProductID:   product.ID,
+		SKUCode:     models.DefaultSKUCode,
+		PriceAmount: product.PriceAmount,
+		IsActive:    true,
+	}).Error; err != nil {
+		t.Fatalf("create sku failed: %v", err)
+	}
+
+	svc := NewCardSecretService(
+		repository.NewCardSecretRepository(db),
+		repository.NewCardSecretBatchRepository(db),
+		repository.NewProductRepository(db),
+		repository.NewProductSKURepository(db),
+	)
+
+	batch, created, err := svc.CreateCardSecretBatch(CreateCardSecretBatchInput{
+		ProductID: product.ID,
+		Rows: []map[string]string{
+			{"username": "alice", "password": "p1", "recovery_email": "a@example.com"},
+			{"username": "alice", "password": "p1", "recovery_email": "a@example.com"},
+		},
+		Secrets: []string{"bob----p2"},
+	})
+	if err != nil {
+		t.Fatalf("create structured batch failed: %v", err)
+	}
+	if created != 2 || batch.Duplicates != 1 {
+		t.Fatalf("want 2 created 1 duplicate, got %d created %d duplicates", created, batch.Duplicates)
+	}
+
+	batch, created, err = svc.CreateCardSecretBatch(CreateCardSecretBatchInput{
+		ProductID: product.ID,
+		Secrets:   []string{"bob----p2", "carol----p3"},
+	})
+	if err != nil {
+		t.Fatalf("create second batch failed: %v", err)
+	}
+	if created != 1 || batch.Duplicates != 1 {
+		t.Fatalf("want 1 created 1 duplicate, got %d created %d duplicates", created, batch.Duplicates)
+	}
+
+	if _, _, err := svc.CreateCardSecretBatch(CreateCardSecretBatchInput{
+		ProductID: product.ID,
+		Rows:      []map[string]string{{"username": "carol", "password": "p3"}},
+	}); !errors.Is(err, ErrCardSecretDuplicate) {
+		t.Fatalf("expected ErrCardSecretDuplicate, got %v", err)
+	}
+	if _, _, err := svc.CreateCardSecretBatch(CreateCardSecretBatchInput{
+		ProductID: product.ID,
+		Rows:      []map[string]string{{"username": "dave"}},
+	}); !errors.Is(err, ErrCardSecretInvalid) {
+		t.Fatalf("expected ErrCardSecretInvalid for missing password, got %v", err)
+	}
+
+	var stored models.CardSecret
+	if err := db.Where("product_id = ?", product.ID).Order("id asc").First(&stored).Error; err != nil {
+		t.Fatalf("query stored secret failed: %v", err)
+	}
+	if stored.Fields["username"] != "alice" || stored.Fields["recovery_email"] != "a@example.com" {
+		t.Fatalf("unexpected stored fields: %+v", stored.Fields)
+	}
+	if stored.Secret != "alice | p1 | a@example.com" {
+		t.Fatalf("unexpected stored secret: %q", stored.Secret)
+	}
+}
+
+func TestParseCardSecretImportFormats(t *testing.T) {
+	fields, _, err := parseCardSecretSchema(accountSecretSchema())
+	if err != nil {
+		t.Fatalf("parse schema failed: %v", err)
+	}
+
+	csvRows, err := parseCardSecretImport(strings.NewReader("Login,密码,Note\nalice,p1,x\n"), constants.CardSecretSourceCSV, fields, map[string]string{"login": "username"}, "")
+	if err != nil {
+		t.Fatalf("parse csv failed: %v", err)
+	}
+	if len(csvRows) != 1 || csvRows[0]["username"] != "alice" || csvRows[0]["password"] != "p1" {
+		t.Fatalf("unexpected csv rows: %+v", csvRows)
+	}
+
+	jsonRows, err := parseCardSecretImport(strings.NewReader("{\"username\":\"bob\",\"password\":\"p2\"}\n\n"), constants.CardSecretSourceJSONL, fields, nil, "")
+	if err != nil {
+		t.Fatalf("parse jsonl failed: %v", err)
+	}
+	if len(jsonRows) != 1 || jsonRows[0]["password"] != "p2" {
+		t.Fatalf("unexpected jsonl rows: %+v", jsonRows)
+	}
+
+	txtRows, err := parseCardSecretImport(strings.NewReader("carol|p3|c@example.com\n"), constants.CardSecretSourceTXT, fields, nil, "|")
+	if err != nil {
+		t.Fatalf("parse txt failed: %v", err)
+	}
+	if len(txtRows) != 1 || txtRows[0]["recovery_email"] != "c@example.com" {
+		t.Fatalf("unexpected txt rows: %+v", txtRows)
+	}
+
+	book := excelize.NewFile()
+	sheet := book.GetSheetName(0)
+	_ = book.SetSheetRow(sheet, "A1", &[]interface{}{"password", "username"})
+	_ = book.SetSheetRow(sheet, "A2", &[]interface{}{"p4", "dave"})
+	buffer := bytes.NewBuffer(nil)
+	if err := book.Write(buffer); err != nil {
+		t.Fatalf("write xlsx failed: %v", err)
+	}
+	xlsxRows, err := parseCardSecretImport(buffer, constants.CardSecretSourceXLSX, fields, nil, "")
+	if err != nil {
+		t.Fatalf("parse xlsx failed: %v", err)
+	}
+	if len(xlsxRows) != 1 || xlsxRows[0]["username"] != "dave" || xlsxRows[0]["password"] != "p4" {
+		t.Fatalf("unexpected xlsx rows: %+v", xlsxRows)
+	}
+
+	plainRows, err := parseCardSecretImport(strings.NewReader("CARD-1\nCARD-2\n"), constants.CardSecretSourceCSV, cardSecretImportColumns(nil), nil, "")
+	if err != nil {
+		t.Fatalf("parse plain csv failed: %v", err)
+	}
+	if len(plainRows) != 2 || plainRows[0][cardSecretPlainFieldKey] != "CARD-1" {
+		t.Fatalf("headerless plain csv should keep first row, got %+v", plainRows)
+	}
+}
+
+func TestRenderCardSecretPayloadTable(t *testing.T) {
+	fields, _, err := parseCardSecretSchema(accountSecretSchema())
+	if err != nil {
+		t.Fatalf("parse schema failed: %v", err)
+	}
+	got := renderCardSecretPayload(fields, []models.CardSecret{
+		{Secret: "alice | p|1", Fields: models.JSON{"username": "alice", "password": "p|1"}},
+	}, constants.LocaleEnUS)
+	want := "| Username | Password | 辅助邮箱 |\n| --- | --- | --- |\n| alice | p\\|1 |  |"
+	if got != want {
+		t.Fatalf("unexpected table:\n%s\nwant:\n%s", got, want)
+	}
+}
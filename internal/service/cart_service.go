@@ -31,6 +31,7 @@ type UpsertCartItemInput struct {
 	SKUID           uint
 	Quantity        int
 	FulfillmentType string
+	AccessCode      string // 访问码保护商品加入购物车时需提供访问码
 }
 
 // CartService 购物车服务
@@ -40,6 +41,7 @@ type CartService struct {
 	productSKURepo repository.ProductSKURepository
 	promotionRepo  repository.PromotionRepository
 	settingService *SettingService
+	memberLevelSvc *MemberLevelService
 }
 
 // NewCartService 创建购物车服务
@@ -53,6 +55,11 @@ func NewCartService(cartRepo repository.CartRepository, productRepo repository.P
 	}
 }
 
+// SetMemberLevelService 设置会员等级服务，购物车按用户等级展示会员价
+func (s *CartService) SetMemberLevelService(memberLevelSvc *MemberLevelService) {
+	s.memberLevelSvc = memberLevelSvc
+}
+
 // ListByUser 获取用户购物车
 func (s *CartService) ListByUser(userID uint) ([]CartItemDetail, error) {
 	if userID == 0 {
@@ -65,6 +72,13 @@ func (s *CartService) ListByUser(userID uint) ([]CartItemDetail, error) {
 	currency := s.resolveSiteCurrency()
 	details := make([]CartItemDetail, 0, len(items))
 	promotionService := NewPromotionService(s.promotionRepo)
+	var memberPricing *MemberPricing
+	if s.memberLevelSvc != nil {
+		memberPricing, err = s.memberLevelSvc.ResolvePricing(userID)
+		if err != nil {
+			return nil, err
+		}
+	}
 	for _, item := range items {
 		product := item.Product
 		if product == nil || product.ID == 0 {
@@ -74,7 +88,7 @@ func (s *CartService) ListByUser(userID uint) ([]CartItemDetail, error) {
 			}
 			product = p
 		}
-		if product == nil || !product.IsActive {
+		if !product.IsPublishedAt(time.Now()) {
 			_ = s.cartRepo.DeleteByUserProductSKU(userID, item.ProductID, item.SKUID)
 			continue
 		}
@@ -89,7 +103,7 @@ func (s *CartService) ListByUser(userID uint) ([]CartItemDetail, error) {
 			sku = resolvedSKU
 		}
 
-		if sku == nil || !sku.IsActive {
+		if !sku.IsPublishedAt(time.Now()) {
 			_ = s.cartRepo.DeleteByUserProductSKU(userID, item.ProductID, item.SKUID)
 			continue
 		}
@@ -100,15 +114,9 @@ func (s *CartService) ListByUser(userID uint) ([]CartItemDetail, error) {
 			continue
 		}
 
-		priceCarrier := *product
-		priceCarrier.PriceAmount = sku.PriceAmount
-		unitPrice := sku.PriceAmount
-		if promotionService != nil {
-			_, discounted, err := promotionService.ApplyPromotion(&priceCarrier, item.Quantity)
-			if err != nil {
-				return nil, err
-			}
-			unitPrice = discounted
+		originalPrice, unitPrice, _, err := resolveSKUUnitPrice(promotionService, memberPricing, product, sku, item.Quantity)
+		if err != nil {
+			return nil, err
 		}
 
 		fulfillmentType := strings.TrimSpace(product.FulfillmentType)
@@ -122,7 +130,7 @@ func (s *CartService) ListByUser(userID uint) ([]CartItemDetail, error) {
 			Quantity:        item.Quantity,
 			FulfillmentType: fulfillmentType,
 			UnitPrice:       unitPrice,
-			OriginalPrice:   sku.PriceAmount,
+			OriginalPrice:   originalPrice,
 			Currency:        currency,
 			Product:         product,
 			SKU:             sku,
@@ -140,9 +148,12 @@ func (s *CartService) UpsertItem(input UpsertCartItemInput) error {
 	if err != nil {
 		return err
 	}
-	if product == nil || !product.IsActive {
+	if !product.IsPublishedAt(time.Now()) {
 		return ErrProductNotAvailable
 	}
+	if err := CheckProductAccess(product, input.AccessCode); err != nil {
+		return err
+	}
 	sku, err := s.resolveOrderSKU(product, input.SKUID)
 	if err != nil {
 		return err
@@ -152,7 +163,7 @@ func (s *CartService) UpsertItem(input UpsertCartItemInput) error {
 	if fulfillmentType == "" {
 		fulfillmentType = constants.FulfillmentTypeManual
 	}
-	if fulfillmentType != constants.FulfillmentTypeManual && fulfillmentType != constants.FulfillmentTypeAuto {
+	if !isSupportedFulfillmentType(fulfillmentType) {
 		return ErrFulfillmentInvalid
 	}
 	if fulfillmentType == constants.FulfillmentTypeManual &&
@@ -206,7 +217,7 @@ func (s *CartService) resolveOrderSKU(product *models.Product, rawSKUID uint) (*
 		if err != nil {
 			return nil, err
 		}
-		if sku == nil || sku.ProductID != product.ID || !sku.IsActive {
+		if sku == nil || sku.ProductID != product.ID || !sku.IsPublishedAt(time.Now()) {
 			return nil, ErrProductSKUInvalid
 		}
 		return sku, nil
@@ -3,8 +3,13 @@ package service
 import (
 	"github.com/dujiao-next/internal/models"
 	"github.com/dujiao-next/internal/repository"
+
+	"gorm.io/gorm"
 )
 
+// categoryMaxDepth 分类最大层级
+const categoryMaxDepth = 5
+
 // CategoryService 分类业务服务
 type CategoryService struct {
 	repo repository.CategoryRepository
@@ -17,6 +22,7 @@ func NewCategoryService(repo repository.CategoryRepository) *CategoryService {
 
 // CreateCategoryInput 创建/更新分类输入
 type CreateCategoryInput struct {
+	ParentID  uint
 	Slug      string
 	NameJSON  map[string]interface{}
 	Icon      string
@@ -28,6 +34,15 @@ func (s *CategoryService) List() ([]models.Category, error) {
 	return s.repo.List()
 }
 
+// Tree 获取分类树（同级按排序权重）
+func (s *CategoryService) Tree() ([]models.Category, error) {
+	categories, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	return buildCategoryTree(categories), nil
+}
+
 // Create 创建分类
 func (s *CategoryService) Create(input CreateCategoryInput) (*models.Category, error) {
 	count, err := s.repo.CountBySlug(input.Slug, nil)
@@ -37,8 +52,12 @@ func (s *CategoryService) Create(input CreateCategoryInput) (*models.Category, e
 	if count > 0 {
 		return nil, ErrSlugExists
 	}
+	if err := s.validateParent(0, input.ParentID); err != nil {
+		return nil, err
+	}
 
 	category := models.Category{
+		ParentID:  input.ParentID,
 		Slug:      input.Slug,
 		NameJSON:  models.JSON(input.NameJSON),
 		Icon:      input.Icon,
@@ -67,7 +86,13 @@ func (s *CategoryService) Update(id string, input CreateCategoryInput) (*models.
 	if count > 0 {
 		return nil, ErrSlugExists
 	}
+	if input.ParentID != category.ParentID {
+		if err := s.validateParent(category.ID, input.ParentID); err != nil {
+			return nil, err
+		}
+	}
 
+	category.ParentID = input.ParentID
 	category.Slug = input.Slug
 	category.NameJSON = models.JSON(input.NameJSON)
 	category.Icon = input.Icon
@@ -79,8 +104,8 @@ func (s *CategoryService) Update(id string, input CreateCategoryInput) (*models.
 	return category, nil
 }
 
-// Delete 删除分类
-func (s *CategoryService) Delete(id string) error {
+// Delete 删除分类；存在子分类或商品时需指定 reassignTo，将其转移到目标分类后再删除
+func (s *CategoryService) Delete(id string, reassignTo uint) error {
 	category, err := s.repo.GetByID(id)
 	if err != nil {
 		return err
@@ -89,12 +114,203 @@ func (s *CategoryService) Delete(id string) error {
 		return ErrNotFound
 	}
 
-	count, err := s.repo.CountProducts(id)
+	childCount, err := s.repo.CountChildren(category.ID)
 	if err != nil {
 		return err
 	}
-	if count > 0 {
-		return ErrCategoryInUse
+	productCount, err := s.repo.CountProducts(id)
+	if err != nil {
+		return err
+	}
+	if reassignTo == 0 {
+		if childCount > 0 {
+			return ErrCategoryHasChildren
+		}
+		if productCount > 0 {
+			return ErrCategoryInUse
+		}
+		return s.repo.Delete(id)
+	}
+
+	if err := s.validateReassignTarget(category.ID, reassignTo); err != nil {
+		return err
+	}
+	return s.repo.Transaction(func(tx *gorm.DB) error {
+		repo := s.repo.WithTx(tx)
+		if err := repo.MoveChildren(category.ID, reassignTo); err != nil {
+			return err
+		}
+		if err := repo.MoveProducts(category.ID, reassignTo); err != nil {
+			return err
+		}
+		return repo.Delete(id)
+	})
+}
+
+// Reorder 调整同一父分类下子分类的顺序，ids 按展示顺序排列
+func (s *CategoryService) Reorder(parentID uint, ids []uint) error {
+	if len(ids) == 0 {
+		return ErrCategoryReorderInvalid
+	}
+	categories, err := s.repo.List()
+	if err != nil {
+		return err
+	}
+	siblings := make(map[uint]struct{})
+	for _, category := range categories {
+		if category.ParentID == parentID {
+			siblings[category.ID] = struct{}{}
+		}
+	}
+	orders := make(map[uint]int, len(ids))
+	for i, id := range ids {
+		if _, ok := siblings[id]; !ok {
+			return ErrCategoryReorderInvalid
+		}
+		if _, dup := orders[id]; dup {
+			return ErrCategoryReorderInvalid
+		}
+		// 列表按 sort_order DESC 展示，靠前的分类权重更高
+		orders[id] = len(ids) - i
+	}
+	return s.repo.Transaction(func(tx *gorm.DB) error {
+		return s.repo.WithTx(tx).UpdateSortOrders(orders)
+	})
+}
+
+// validateParent 校验父分类存在、不形成环且层级不超过上限
+func (s *CategoryService) validateParent(categoryID, parentID uint) error {
+	if parentID == 0 {
+		return nil
+	}
+	if parentID == categoryID {
+		return ErrCategoryParentInvalid
+	}
+	categories, err := s.repo.List()
+	if err != nil {
+		return err
+	}
+	index := indexCategories(categories)
+	if _, ok := index[parentID]; !ok {
+		return ErrCategoryParentInvalid
+	}
+	if categoryID != 0 && categoryIsDescendant(index, parentID, categoryID) {
+		return ErrCategoryParentInvalid
+	}
+	height := 1
+	if categoryID != 0 {
+		height = categorySubtreeHeight(categories, categoryID)
+	}
+	if categoryDepth(index, parentID)+height > categoryMaxDepth {
+		return ErrCategoryParentInvalid
+	}
+	return nil
+}
+
+// validateReassignTarget 校验转移目标：存在、不是被删分类或其后代，且子分类挂入后不超出层级上限
+func (s *CategoryService) validateReassignTarget(categoryID, targetID uint) error {
+	if targetID == categoryID {
+		return ErrCategoryReassignInvalid
+	}
+	categories, err := s.repo.List()
+	if err != nil {
+		return err
+	}
+	index := indexCategories(categories)
+	if _, ok := index[targetID]; !ok {
+		return ErrCategoryReassignInvalid
+	}
+	if categoryIsDescendant(index, targetID, categoryID) {
+		return ErrCategoryReassignInvalid
+	}
+	// 被删分类的子树整体上移到目标分类下
+	if childHeight := categorySubtreeHeight(categories, categoryID) - 1; childHeight > 0 &&
+		categoryDepth(index, targetID)+childHeight > categoryMaxDepth {
+		return ErrCategoryReassignInvalid
+	}
+	return nil
+}
+
+func indexCategories(categories []models.Category) map[uint]models.Category {
+	index := make(map[uint]models.Category, len(categories))
+	for _, category := range categories {
+		index[category.ID] = category
+	}
+	return index
+}
+
+// categoryIsDescendant 判断 id 是否为 ancestorID 的后代
+func categoryIsDescendant(index map[uint]models.Category, id, ancestorID uint) bool {
+	current, ok := index[id]
+	for steps := 0; ok && current.ParentID != 0 && steps <= len(index); steps++ {
+		if current.ParentID == ancestorID {
+			return true
+		}
+		current, ok = index[current.ParentID]
+	}
+	return false
+}
+
+// categoryDepth 分类所在层级（顶级为 1）
+func categoryDepth(index map[uint]models.Category, id uint) int {
+	depth := 0
+	current, ok := index[id]
+	for ok && depth <= len(index) {
+		depth++
+		if current.ParentID == 0 {
+			break
+		}
+		current, ok = index[current.ParentID]
+	}
+	return depth
+}
+
+// categorySubtreeHeight 以 id 为根的子树高度（仅自身为 1）
+func categorySubtreeHeight(categories []models.Category, id uint) int {
+	children := make(map[uint][]uint, len(categories))
+	for _, category := range categories {
+		children[category.ParentID] = append(children[category.ParentID], category.ID)
+	}
+	height := 0
+	level := []uint{id}
+	visited := map[uint]struct{}{id: {}}
+	for len(level) > 0 {
+		height++
+		var next []uint
+		for _, parentID := range level {
+			for _, childID := range children[parentID] {
+				if _, ok := visited[childID]; ok {
+					continue
+				}
+				visited[childID] = struct{}{}
+				next = append(next, childID)
+			}
+		}
+		level = next
+	}
+	return height
+}
+
+// buildCategoryTree 将扁平分类列表组装为树，父分类不存在时作为顶级分类展示
+func buildCategoryTree(categories []models.Category) []models.Category {
+	index := indexCategories(categories)
+	children := make(map[uint][]models.Category, len(categories))
+	roots := make([]models.Category, 0)
+	for _, category := range categories {
+		if _, ok := index[category.ParentID]; category.ParentID == 0 || !ok || category.ParentID == category.ID {
+			roots = append(roots, category)
+			continue
+		}
+		children[category.ParentID] = append(children[category.ParentID], category)
+	}
+	var attach func(nodes []models.Category, depth int) []models.Category
+	attach = func(nodes []models.Category, depth int) []models.Category {
+		for i := range nodes {
+			if depth < len(categories) {
+				nodes[i].Children = attach(children[nodes[i].ID], depth+1)
+			}
+		}
+		return nodes
 	}
-	return s.repo.Delete(id)
+	return attach(roots, 0)
 }
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/glebarez/sqlite"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+func setupCategoryServiceTest(t *testing.T) (*gorm.DB, *CategoryService) {
+	t.Helper()
+	dsn := fmt.Sprintf("file:category_service_test_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Category{}, &models.Product{}, &models.ProductSKU{}); err != nil {
+		t.Fatalf("auto migrate failed: %v", err)
+	}
+	return db, NewCategoryService(repository.NewCategoryRepository(db))
+}
+
+func mustCreateCategory(t *testing.T, svc *CategoryService, slug string, parentID uint) *models.Category {
+	t.Helper()
+	category, err := svc.Create(CreateCategoryInput{ParentID: parentID, Slug: slug, NameJSON: map[string]interface{}{"zh-CN": slug}})
+	if err != nil {
+		t.Fatalf("create category %s failed: %v", slug, err)
+	}
+	return category
+}
+
+func TestCategoryTreeAndParentValidation(t *testing.T) {
+	_, svc := setupCategoryServiceTest(t)
+	games := mustCreateCategory(t, svc, "games", 0)
+	steam := mustCreateCategory(t, svc, "steam", games.ID)
+	steamCN := mustCreateCategory(t, svc, "steam-cn", steam.ID)
+	steamUS := mustCreateCategory(t, svc, "steam-us", steam.ID)
+	mustCreateCategory(t, svc, "software", 0)
+
+	if err := svc.Reorder(steam.ID, []uint{steamUS.ID, steamCN.ID}); err != nil {
+		t.Fatalf("reorder failed: %v", err)
+	}
+	if err := svc.Reorder(steam.ID, []uint{games.ID}); !errors.Is(err, ErrCategoryReorderInvalid) {
+		t.Fatalf("expected reorder invalid for non-sibling, got %v", err)
+	}
+
+	tree, err := svc.Tree()
+	if err != nil {
+		t.Fatalf("tree failed: %v", err)
+	}
+	if len(tree) != 2 {
+		t.Fatalf("expected 2 root categories, got %d", len(tree))
+	}
+	var gamesNode *models.Category
+	for i := range tree {
+		if tree[i].ID == games.ID {
+			gamesNode = &tree[i]
+		}
+	}
+	if gamesNode == nil || len(gamesNode.Children) != 1 || len(gamesNode.Children[0].Children) != 2 {
+		t.Fatalf("unexpected tree shape: %+v", tree)
+	}
+	if gamesNode.Children[0].Children[0].ID != steamUS.ID {
+		t.Fatalf("expected reordered child first, got %+v", gamesNode.Children[0].Children)
+	}
+
+	gamesID := strconv.FormatUint(uint64(games.ID), 10)
+	if _, err := svc.Update(gamesID, CreateCategoryInput{ParentID: steamCN.ID, Slug: "games", NameJSON: map[string]interface{}{"zh-CN": "games"}}); !errors.Is(err, ErrCategoryParentInvalid) {
+		t.Fatalf("expected cycle rejected, got %v", err)
+	}
+	if _, err := svc.Create(CreateCategoryInput{ParentID: 9999, Slug: "orphan", NameJSON: map[string]interface{}{"zh-CN": "orphan"}}); !errors.Is(err, ErrCategoryParentInvalid) {
+		t.Fatalf("expected missing parent rejected, got %v", err)
+	}
+
+	parentID := steamCN.ID
+	for i := 0; i < 2; i++ {
+		parentID = mustCreateCategory(t, svc, fmt.Sprintf("deep-%d", i), parentID).ID
+	}
+	if _, err := svc.Create(CreateCategoryInput{ParentID: parentID, Slug: "too-deep", NameJSON: map[string]interface{}{"zh-CN": "deep"}}); !errors.Is(err, ErrCategoryParentInvalid) {
+		t.Fatalf("expected depth limit rejected, got %v", err)
+	}
+}
+
+func TestCategoryDeleteRulesAndDescendantListing(t *testing.T) {
+	db, svc := setupCategoryServiceTest(t)
+	games := mustCreateCategory(t, svc, "games", 0)
+	steam := mustCreateCategory(t, svc, "steam", games.ID)
+	steamCN := mustCreateCategory(t, svc, "steam-cn", steam.ID)
+	other := mustCreateCategory(t, svc, "other", 0)
+
+	for i, categoryID := range []uint{games.ID, steamCN.ID} {
+		if err := db.Create(&models.Product{
+			CategoryID:      categoryID,
+			Slug:            fmt.Sprintf("product-%d", i),
+			TitleJSON:       models.JSON{"zh-CN": "商品"},
+			PriceAmount:     models.NewMoneyFromDecimal(decimal.NewFromInt(10)),
+			PurchaseType:    constants.ProductPurchaseMember,
+			FulfillmentType: constants.FulfillmentTypeManual,
+			IsActive:        true,
+		}).Error; err != nil {
+			t.Fatalf("create product failed: %v", err)
+		}
+	}
+
+	productRepo := repository.NewProductRepository(db)
+	products, total, err := productRepo.List(repository.ProductListFilter{
+		CategoryID: strconv.FormatUint(uint64(games.ID), 10),
+		OnlyActive: true,
+		Page:       1,
+		PageSize:   20,
+	})
+	if err != nil {
+		t.Fatalf("list products failed: %v", err)
+	}
+	if total != 2 || len(products) != 2 {
+		t.Fatalf("expected products from descendant categories, got total=%d", total)
+	}
+
+	steamID := strconv.FormatUint(uint64(steam.ID), 10)
+	if err := svc.Delete(steamID, 0); !errors.Is(err, ErrCategoryHasChildren) {
+		t.Fatalf("expected delete blocked by children, got %v", err)
+	}
+	steamCNID := strconv.FormatUint(uint64(steamCN.ID), 10)
+	if err := svc.Delete(steamCNID, 0); !errors.Is(err, ErrCategoryInUse) {
+		t.Fatalf("expected delete blocked by products, got %v", err)
+	}
+	if err := svc.Delete(steamID, steamCN.ID); !errors.Is(err, ErrCategoryReassignInvalid) {
+		t.Fatalf("expected reassign to descendant rejected, got %v", err)
+	}
+
+	if err := svc.Delete(steamID, other.ID); err != nil {
+		t.Fatalf("delete with reassign failed: %v", err)
+	}
+	var moved models.Category
+	if err := db.First(&moved, steamCN.ID).Error; err != nil {
+		t.Fatalf("reload child failed: %v", err)
+	}
+	if moved.ParentID != other.ID {
+		t.Fatalf("expected child moved to reassign target, got parent %d", moved.ParentID)
+	}
+
+	if err := svc.Delete(steamCNID, other.ID); err != nil {
+		t.Fatalf("delete leaf with reassign failed: %v", err)
+	}
+	var count int64
+	if err := db.Model(&models.Product{}).Where("category_id = ?", other.ID).Count(&count).Error; err != nil {
+		t.Fatalf("count products failed: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected product reassigned to target, got %d", count)
+	}
+}
@@ -24,32 +24,42 @@ func NewCouponAdminService(repo repository.CouponRepository) *CouponAdminService
 
 // CreateCouponInput 创建优惠券输入
 type CreateCouponInput struct {
-	Code         string
-	Type         string
-	Value        models.Money
-	MinAmount    models.Money
-	MaxDiscount  models.Money
-	UsageLimit   int
-	PerUserLimit int
-	ScopeRefIDs  []uint
-	StartsAt     *time.Time
-	EndsAt       *time.Time
-	IsActive     *bool
+	Code               string
+	Type               string
+	Value              models.Money
+	MinAmount          models.Money
+	MaxDiscount        models.Money
+	UsageLimit         int
+	PerUserLimit       int
+	ScopeType          string // product/category/sku/all，空值按 product 处理
+	ScopeRefIDs        []uint
+	ExcludedProductIDs []uint
+	UserIDs            []uint
+	MemberLevelIDs     []uint
+	FirstOrderOnly     bool
+	StartsAt           *time.Time
+	EndsAt             *time.Time
+	IsActive           *bool
 }
 
 // UpdateCouponInput 更新优惠券输入
 type UpdateCouponInput struct {
-	Code         string
-	Type         string
-	Value        models.Money
-	MinAmount    models.Money
-	MaxDiscount  models.Money
-	UsageLimit   int
-	PerUserLimit int
-	ScopeRefIDs  []uint
-	StartsAt     *time.Time
-	EndsAt       *time.Time
-	IsActive     *bool
+	Code               string
+	Type               string
+	Value              models.Money
+	MinAmount          models.Money
+	MaxDiscount        models.Money
+	UsageLimit         int
+	PerUserLimit       int
+	ScopeType          string // product/category/sku/all，空值按 product 处理
+	ScopeRefIDs        []uint
+	ExcludedProductIDs []uint
+	UserIDs            []uint
+	MemberLevelIDs     []uint
+	FirstOrderOnly     bool
+	StartsAt           *time.Time
+	EndsAt             *time.Time
+	IsActive           *bool
 }
 
 // Create 创建优惠券
@@ -58,15 +68,9 @@ func (s *CouponAdminService) Create(input CreateCouponInput) (*models.Coupon, er
 	if code == "" {
 		return nil, ErrCouponInvalid
 	}
-	couponType := strings.ToLower(strings.TrimSpace(input.Type))
-	if couponType != constants.CouponTypeFixed && couponType != constants.CouponTypePercent {
-		return nil, ErrCouponInvalid
-	}
-	if input.Value.Decimal.LessThanOrEqual(decimal.Zero) {
-		return nil, ErrCouponInvalid
-	}
-	if couponType == constants.CouponTypePercent && input.Value.Decimal.GreaterThan(decimal.NewFromInt(100)) {
-		return nil, ErrCouponInvalid
+	couponType, err := validateCouponTerms(input.Type, input.Value, input.StartsAt, input.EndsAt)
+	if err != nil {
+		return nil, err
 	}
 
 	exist, err := s.repo.GetByCode(code)
@@ -77,34 +81,34 @@ func (s *CouponAdminService) Create(input CreateCouponInput) (*models.Coupon, er
 		return nil, ErrCouponInvalid
 	}
 
-	scopeRefIDs, err := encodeScopeRefIDs(input.ScopeRefIDs)
+	rules, err := encodeCouponRules(input.ScopeType, input.ScopeRefIDs, input.ExcludedProductIDs, input.UserIDs, input.MemberLevelIDs)
 	if err != nil {
 		return nil, err
 	}
 
-	if input.StartsAt != nil && input.EndsAt != nil && input.EndsAt.Before(*input.StartsAt) {
-		return nil, ErrCouponInvalid
-	}
-
 	isActive := true
 	if input.IsActive != nil {
 		isActive = *input.IsActive
 	}
 
 	coupon := &models.Coupon{
-		Code:         code,
-		Type:         couponType,
-		Value:        input.Value,
-		MinAmount:    input.MinAmount,
-		MaxDiscount:  input.MaxDiscount,
-		UsageLimit:   input.UsageLimit,
-		UsedCount:    0,
-		PerUserLimit: input.PerUserLimit,
-		ScopeType:    constants.ScopeTypeProduct,
-		ScopeRefIDs:  scopeRefIDs,
-		StartsAt:     input.StartsAt,
-		EndsAt:       input.EndsAt,
-		IsActive:     isActive,
+		Code:               code,
+		Type:               couponType,
+		Value:              input.Value,
+		MinAmount:          input.MinAmount,
+		MaxDiscount:        input.MaxDiscount,
+		UsageLimit:         input.UsageLimit,
+		UsedCount:          0,
+		PerUserLimit:       input.PerUserLimit,
+		ScopeType:          rules.scopeType,
+		ScopeRefIDs:        rules.scopeRefIDs,
+		ExcludedProductIDs: rules.excludedProductIDs,
+		UserIDs:            rules.userIDs,
+		MemberLevelIDs:     rules.memberLevelIDs,
+		FirstOrderOnly:     input.FirstOrderOnly,
+		StartsAt:           input.StartsAt,
+		EndsAt:             input.EndsAt,
+		IsActive:           isActive,
 	}
 
 	if err := s.repo.Create(coupon); err != nil {
@@ -130,15 +134,9 @@ func (s *CouponAdminService) Update(id uint, input UpdateCouponInput) (*models.C
 	if code == "" {
 		return nil, ErrCouponInvalid
 	}
-	couponType := strings.ToLower(strings.TrimSpace(input.Type))
-	if couponType != constants.CouponTypeFixed && couponType != constants.CouponTypePercent {
-		return nil, ErrCouponInvalid
-	}
-	if input.Value.Decimal.LessThanOrEqual(decimal.Zero) {
-		return nil, ErrCouponInvalid
-	}
-	if couponType == constants.CouponTypePercent && input.Value.Decimal.GreaterThan(decimal.NewFromInt(100)) {
-		return nil, ErrCouponInvalid
+	couponType, err := validateCouponTerms(input.Type, input.Value, input.StartsAt, input.EndsAt)
+	if err != nil {
+		return nil, err
 	}
 
 	if code != existing.Code {
@@ -151,14 +149,10 @@ func (s *CouponAdminService) Update(id uint, input UpdateCouponInput) (*models.C
 		}
 	}
 
-	scopeRefIDs, err := encodeScopeRefIDs(input.ScopeRefIDs)
+	rules, err := encodeCouponRules(input.ScopeType, input.ScopeRefIDs, input.ExcludedProductIDs, input.UserIDs, input.MemberLevelIDs)
 	if err != nil {
 		return nil, err
 	}
-	if input.StartsAt != nil && input.EndsAt != nil && input.EndsAt.Before(*input.StartsAt) {
-		return nil, ErrCouponInvalid
-	}
-
 	isActive := existing.IsActive
 	if input.IsActive != nil {
 		isActive = *input.IsActive
@@ -171,8 +165,12 @@ func (s *CouponAdminService) Update(id uint, input UpdateCouponInput) (*models.C
 	existing.MaxDiscount = input.MaxDiscount
 	existing.UsageLimit = input.UsageLimit
 	existing.PerUserLimit = input.PerUserLimit
-	existing.ScopeType = constants.ScopeTypeProduct
-	existing.ScopeRefIDs = scopeRefIDs
+	existing.ScopeType = rules.scopeType
+	existing.ScopeRefIDs = rules.scopeRefIDs
+	existing.ExcludedProductIDs = rules.excludedProductIDs
+	existing.UserIDs = rules.userIDs
+	existing.MemberLevelIDs = rules.memberLevelIDs
+	existing.FirstOrderOnly = input.FirstOrderOnly
 	existing.StartsAt = input.StartsAt
 	existing.EndsAt = input.EndsAt
 	existing.IsActive = isActive
@@ -206,6 +204,62 @@ func (s *CouponAdminService) List(filter repository.CouponListFilter) ([]models.
 	return s.repo.List(filter)
 }
 
+// validateCouponTerms 校验优惠类型、数值与有效期，返回规范化后的类型
+func validateCouponTerms(rawType string, value models.Money, startsAt, endsAt *time.Time) (string, error) {
+	couponType := strings.ToLower(strings.TrimSpace(rawType))
+	if couponType != constants.CouponTypeFixed && couponType != constants.CouponTypePercent {
+		return "", ErrCouponInvalid
+	}
+	if value.Decimal.LessThanOrEqual(decimal.Zero) {
+		return "", ErrCouponInvalid
+	}
+	if couponType == constants.CouponTypePercent && value.Decimal.GreaterThan(decimal.NewFromInt(100)) {
+		return "", ErrCouponInvalid
+	}
+	if startsAt != nil && endsAt != nil && endsAt.Before(*startsAt) {
+		return "", ErrCouponInvalid
+	}
+	return couponType, nil
+}
+
+// couponRules 编码后的优惠券范围与使用者限制
+type couponRules struct {
+	scopeType          string
+	scopeRefIDs        string
+	excludedProductIDs string
+	userIDs            string
+	memberLevelIDs     string
+}
+
+func encodeCouponRules(rawScopeType string, scopeRefIDs, excludedProductIDs, userIDs, memberLevelIDs []uint) (*couponRules, error) {
+	scopeType := strings.ToLower(strings.TrimSpace(rawScopeType))
+	if scopeType == "" {
+		scopeType = constants.ScopeTypeProduct
+	}
+	if !isCouponScopeType(scopeType) {
+		return nil, ErrCouponScopeInvalid
+	}
+	rules := &couponRules{scopeType: scopeType}
+	if scopeType != constants.ScopeTypeAll {
+		encoded, err := encodeScopeRefIDs(scopeRefIDs)
+		if err != nil {
+			return nil, err
+		}
+		rules.scopeRefIDs = encoded
+	}
+	var err error
+	if rules.excludedProductIDs, err = encodeOptionalIDs(excludedProductIDs); err != nil {
+		return nil, err
+	}
+	if rules.userIDs, err = encodeOptionalIDs(userIDs); err != nil {
+		return nil, err
+	}
+	if rules.memberLevelIDs, err = encodeOptionalIDs(memberLevelIDs); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
 func encodeScopeRefIDs(ids []uint) (string, error) {
 	if len(ids) == 0 {
 		return "", ErrCouponScopeInvalid
@@ -216,3 +270,15 @@ func encodeScopeRefIDs(ids []uint) (string, error) {
 	}
 	return string(payload), nil
 }
+
+// encodeOptionalIDs 可选 ID 集合，空集合存为空字符串表示不限制
+func encodeOptionalIDs(ids []uint) (string, error) {
+	if len(ids) == 0 {
+		return "", nil
+	}
+	payload, err := json.Marshal(ids)
+	if err != nil {
+		return "", ErrCouponInvalid
+	}
+	return string(payload), nil
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	crand "crypto/rand"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+)
+
+const (
+	couponBatchPrefix        = "CPB"
+	couponBatchMaxQuantity   = 10000
+	couponCodeRandomLength   = 10
+	couponCodeGenerateTries  = 5
+	couponCodePrefixMaxLen   = 16
+	couponCodeAlphabet       = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	couponCodeAlphabetLength = len(couponCodeAlphabet)
+)
+
+var couponCodePrefixPattern = regexp.MustCompile(`^[A-Z0-9-]*$`)
+
+// GenerateCouponBatchInput 生成优惠码批次输入，Template 中的 Code 不生效
+type GenerateCouponBatchInput struct {
+	Name       string
+	CodePrefix string
+	Quantity   int
+	Template   CreateCouponInput
+	CreatedBy  *uint
+}
+
+// CouponBatchDetail 优惠码批次及使用统计
+type CouponBatchDetail struct {
+	models.CouponBatch
+	Stats repository.CouponBatchStats `json:"stats"`
+}
+
+// GenerateBatch 按模板批量生成唯一优惠码；单码使用上限未设置时默认为 1（一次性优惠码）
+func (s *CouponAdminService) GenerateBatch(input GenerateCouponBatchInput) (*models.CouponBatch, int, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, 0, ErrCouponInvalid
+	}
+	if input.Quantity <= 0 || input.Quantity > couponBatchMaxQuantity {
+		return nil, 0, ErrCouponInvalid
+	}
+	prefix := strings.ToUpper(strings.TrimSpace(input.CodePrefix))
+	if len(prefix) > couponCodePrefixMaxLen || !couponCodePrefixPattern.MatchString(prefix) {
+		return nil, 0, ErrCouponInvalid
+	}
+	template := input.Template
+	couponType, err := validateCouponTerms(template.Type, template.Value, template.StartsAt, template.EndsAt)
+	if err != nil {
+		return nil, 0, err
+	}
+	rules, err := encodeCouponRules(template.ScopeType, template.ScopeRefIDs, template.ExcludedProductIDs, template.UserIDs, template.MemberLevelIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	usageLimit := template.UsageLimit
+	if usageLimit <= 0 {
+		usageLimit = 1
+	}
+	isActive := true
+	if template.IsActive != nil {
+		isActive = *template.IsActive
+	}
+
+	codes, err := s.generateUniqueCouponCodes(prefix, input.Quantity)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	batch := &models.CouponBatch{
+		BatchNo:            generateCouponBatchNo(now),
+		Name:               name,
+		CodePrefix:         prefix,
+		Quantity:           input.Quantity,
+		Type:               couponType,
+		Value:              template.Value,
+		MinAmount:          template.MinAmount,
+		MaxDiscount:        template.MaxDiscount,
+		UsageLimit:         usageLimit,
+		PerUserLimit:       template.PerUserLimit,
+		ScopeType:          rules.scopeType,
+		ScopeRefIDs:        rules.scopeRefIDs,
+		ExcludedProductIDs: rules.excludedProductIDs,
+		UserIDs:            rules.userIDs,
+		MemberLevelIDs:     rules.memberLevelIDs,
+		FirstOrderOnly:     template.FirstOrderOnly,
+		StartsAt:           template.StartsAt,
+		EndsAt:             template.EndsAt,
+		IsActive:           isActive,
+		CreatedBy:          input.CreatedBy,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	coupons := make([]models.Coupon, 0, len(codes))
+	for _, code := range codes {
+		coupons = append(coupons, models.Coupon{
+			Code:               code,
+			Type:               batch.Type,
+			Value:              batch.Value,
+			MinAmount:          batch.MinAmount,
+			MaxDiscount:        batch.MaxDiscount,
+			UsageLimit:         batch.UsageLimit,
+			PerUserLimit:       batch.PerUserLimit,
+			ScopeType:          batch.ScopeType,
+			ScopeRefIDs:        batch.ScopeRefIDs,
+			ExcludedProductIDs: batch.ExcludedProductIDs,
+			UserIDs:            batch.UserIDs,
+			MemberLevelIDs:     batch.MemberLevelIDs,
+			FirstOrderOnly:     batch.FirstOrderOnly,
+			StartsAt:           batch.StartsAt,
+			EndsAt:             batch.EndsAt,
+			IsActive:           true,
+			CreatedAt:          now,
+			UpdatedAt:          now,
+		})
+	}
+	if err := s.repo.CreateBatch(batch, coupons); err != nil {
+		return nil, 0, ErrCouponBatchCreateFailed
+	}
+	return batch, len(coupons), nil
+}
+
+// ListBatches 获取优惠码批次列表及使用统计
+func (s *CouponAdminService) ListBatches(filter repository.CouponBatchListFilter) ([]CouponBatchDetail, int64, error) {
+	filter.BatchNo = strings.ToUpper(strings.TrimSpace(filter.BatchNo))
+	filter.Name = strings.TrimSpace(filter.Name)
+	batches, total, err := s.repo.ListBatches(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	ids := make([]uint, 0, len(batches))
+	for _, batch := range batches {
+		ids = append(ids, batch.ID)
+	}
+	stats, err := s.repo.ListBatchStats(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	items := make([]CouponBatchDetail, 0, len(batches))
+	for _, batch := range batches {
+		items = append(items, CouponBatchDetail{CouponBatch: batch, Stats: stats[batch.ID]})
+	}
+	return items, total, nil
+}
+
+// GetBatch 获取优惠码批次详情及使用统计
+func (s *CouponAdminService) GetBatch(id uint) (*CouponBatchDetail, error) {
+	batch, err := s.getBatch(id)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.repo.ListBatchStats([]uint{batch.ID})
+	if err != nil {
+		return nil, err
+	}
+	return &CouponBatchDetail{CouponBatch: *batch, Stats: stats[batch.ID]}, nil
+}
+
+// UpdateBatchStatus 启用或停用整个批次，不改动批次内优惠码自身的启用状态
+func (s *CouponAdminService) UpdateBatchStatus(id uint, isActive bool) (*models.CouponBatch, error) {
+	batch, err := s.getBatch(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateBatchStatus(batch.ID, isActive); err != nil {
+		return nil, ErrCouponUpdateFailed
+	}
+	batch.IsActive = isActive
+	return batch, nil
+}
+
+// ExportBatch 导出批次内全部优惠码（csv/txt）
+func (s *CouponAdminService) ExportBatch(id uint, format string) ([]byte, string, error) {
+	normalizedFormat := strings.TrimSpace(strings.ToLower(format))
+	if normalizedFormat != constants.ExportFormatCSV && normalizedFormat != constants.ExportFormatTXT {
+		return nil, "", ErrCouponInvalid
+	}
+	batch, err := s.getBatch(id)
+	if err != nil {
+		return nil, "", err
+	}
+	coupons, err := s.repo.ListByBatchID(batch.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if normalizedFormat == constants.ExportFormatTXT {
+		lines := make([]string, 0, len(coupons))
+		for _, coupon := range coupons {
+			lines = append(lines, coupon.Code)
+		}
+		return []byte(strings.Join(lines, "\n")), "text/plain; charset=utf-8", nil
+	}
+
+	builder := &strings.Builder{}
+	writer := csv.NewWriter(builder)
+	if err := writer.Write([]string{
+		"id",
+		"batch_no",
+		"code",
+		"type",
+		"value",
+		"usage_limit",
+		"used_count",
+		"is_active",
+		"starts_at",
+		"ends_at",
+		"created_at",
+	}); err != nil {
+		return nil, "", err
+	}
+	for _, coupon := range coupons {
+		record := []string{
+			strconv.FormatUint(uint64(coupon.ID), 10),
+			batch.BatchNo,
+			coupon.Code,
+			coupon.Type,
+			coupon.Value.String(),
+			strconv.Itoa(coupon.UsageLimit),
+			strconv.Itoa(coupon.UsedCount),
+			strconv.FormatBool(coupon.IsActive && batch.IsActive),
+			formatNullableTime(coupon.StartsAt),
+			formatNullableTime(coupon.EndsAt),
+			coupon.CreatedAt.Format(time.RFC3339),
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, "", err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, "", err
+	}
+	return []byte(builder.String()), "text/csv; charset=utf-8", nil
+}
+
+func (s *CouponAdminService) getBatch(id uint) (*models.CouponBatch, error) {
+	if id == 0 {
+		return nil, ErrCouponInvalid
+	}
+	batch, err := s.repo.GetBatchByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if batch == nil {
+		return nil, ErrCouponBatchNotFound
+	}
+	return batch, nil
+}
+
+// generateUniqueCouponCodes 生成批次内不重复且未被占用的优惠码，冲突时重新生成
+func (s *CouponAdminService) generateUniqueCouponCodes(prefix string, quantity int) ([]string, error) {
+	codes := make([]string, 0, quantity)
+	seen := make(map[string]struct{}, quantity)
+	for attempt := 0; attempt < couponCodeGenerateTries && len(codes) < quantity; attempt++ {
+		candidates := make([]string, 0, quantity-len(codes))
+		for len(codes)+len(candidates) < quantity {
+			code, err := randomCouponCode(prefix)
+			if err != nil {
+				return nil, ErrCouponBatchCreateFailed
+			}
+			if _, ok := seen[code]; ok {
+				continue
+			}
+			seen[code] = struct{}{}
+			candidates = append(candidates, code)
+		}
+		existing, err := s.repo.ListExistingCodes(candidates)
+		if err != nil {
+			return nil, err
+		}
+		taken := make(map[string]struct{}, len(existing))
+		for _, code := range existing {
+			taken[code] = struct{}{}
+		}
+		for _, code := range candidates {
+			if _, ok := taken[code]; !ok {
+				codes = append(codes, code)
+			}
+		}
+	}
+	if len(codes) < quantity {
+		return nil, ErrCouponBatchCreateFailed
+	}
+	return codes, nil
+}
+
+// randomCouponCode 生成优惠码，字符集去除易混淆的 0/O/1/I
+func randomCouponCode(prefix string) (string, error) {
+	buf := make([]byte, couponCodeRandomLength)
+	if _, err := crand.Read(buf); err != nil {
+		return "", err
+	}
+	builder := strings.Builder{}
+	builder.Grow(len(prefix) + couponCodeRandomLength)
+	builder.WriteString(prefix)
+	for _, b := range buf {
+		builder.WriteByte(couponCodeAlphabet[int(b)%couponCodeAlphabetLength])
+	}
+	return builder.String(), nil
+}
+
+func generateCouponBatchNo(now time.Time) string {
+	return strings.ToUpper(fmt.Sprintf("%s%s%s", couponBatchPrefix, now.Format("20060102150405"), randomHex(4)))
+}
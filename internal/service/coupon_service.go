@@ -14,8 +14,10 @@ import (
 
 // CouponService 优惠券服务
 type CouponService struct {
-	couponRepo repository.CouponRepository
-	usageRepo  repository.CouponUsageRepository
+	couponRepo   repository.CouponRepository
+	usageRepo    repository.CouponUsageRepository
+	orderRepo    repository.OrderRepository
+	categoryRepo repository.CategoryRepository
 }
 
 // NewCouponService 创建优惠券服务
@@ -26,8 +28,60 @@ func NewCouponService(couponRepo repository.CouponRepository, usageRepo reposito
 	}
 }
 
+// SetOrderRepository 设置订单仓库（首单校验）
+func (s *CouponService) SetOrderRepository(orderRepo repository.OrderRepository) {
+	s.orderRepo = orderRepo
+}
+
+// SetCategoryRepository 设置分类仓库（分类范围包含子分类）
+func (s *CouponService) SetCategoryRepository(categoryRepo repository.CategoryRepository) {
+	s.categoryRepo = categoryRepo
+}
+
+// CouponBuyer 优惠券使用者，游客以邮箱识别
+type CouponBuyer struct {
+	UserID        uint
+	GuestEmail    string
+	MemberLevelID uint
+}
+
+// CouponItem 参与优惠券计算的订单行
+type CouponItem struct {
+	ProductID  uint
+	SKUID      uint
+	CategoryID uint
+	TotalPrice models.Money
+}
+
+// couponScope 解析后的优惠券适用范围
+type couponScope struct {
+	scopeType          string
+	refIDs             map[uint]struct{}
+	excludedProductIDs map[uint]struct{}
+}
+
+// matches 判断订单行是否在适用范围内
+func (scope *couponScope) matches(productID, skuID, categoryID uint) bool {
+	if _, excluded := scope.excludedProductIDs[productID]; excluded {
+		return false
+	}
+	switch scope.scopeType {
+	case constants.ScopeTypeAll:
+		return true
+	case constants.ScopeTypeCategory:
+		_, ok := scope.refIDs[categoryID]
+		return ok
+	case constants.ScopeTypeSKU:
+		_, ok := scope.refIDs[skuID]
+		return ok
+	default:
+		_, ok := scope.refIDs[productID]
+		return ok
+	}
+}
+
 // ApplyCoupon 计算优惠券折扣金额
-func (s *CouponService) ApplyCoupon(subtotal models.Money, code string, userID uint, items []models.OrderItem) (models.Money, *models.Coupon, error) {
+func (s *CouponService) ApplyCoupon(subtotal models.Money, code string, buyer CouponBuyer, items []CouponItem) (models.Money, *models.Coupon, error) {
 	trimmed := strings.TrimSpace(code)
 	if trimmed == "" {
 		return models.Money{}, nil, ErrCouponInvalid
@@ -40,7 +94,7 @@ func (s *CouponService) ApplyCoupon(subtotal models.Money, code string, userID u
 	if coupon == nil {
 		return models.Money{}, nil, ErrCouponNotFound
 	}
-	if !coupon.IsActive {
+	if !coupon.IsActive || (coupon.Batch != nil && !coupon.Batch.IsActive) {
 		return models.Money{}, coupon, ErrCouponInactive
 	}
 
@@ -56,14 +110,9 @@ func (s *CouponService) ApplyCoupon(subtotal models.Money, code string, userID u
 		return models.Money{}, coupon, ErrCouponUsageLimit
 	}
 
-	if coupon.PerUserLimit > 0 && userID != 0 {
-		count, err := s.usageRepo.CountByUser(coupon.ID, userID)
-		if err != nil {
-			return models.Money{}, coupon, err
-		}
-		if int(count) >= coupon.PerUserLimit {
-			return models.Money{}, coupon, ErrCouponPerUserLimit
-		}
+	buyer.GuestEmail = strings.ToLower(strings.TrimSpace(buyer.GuestEmail))
+	if err := s.checkBuyer(coupon, buyer); err != nil {
+		return models.Money{}, coupon, err
 	}
 
 	eligibleSubtotal, err := s.resolveEligibleSubtotal(coupon, items)
@@ -91,22 +140,110 @@ func (s *CouponService) ApplyCoupon(subtotal models.Money, code string, userID u
 	return discount, coupon, nil
 }
 
-func (s *CouponService) resolveEligibleSubtotal(coupon *models.Coupon, items []models.OrderItem) (models.Money, error) {
-	if strings.ToLower(strings.TrimSpace(coupon.ScopeType)) != constants.ScopeTypeProduct {
-		return models.Money{}, ErrCouponScopeInvalid
+// checkBuyer 校验限定用户、会员等级、首单与每人限用；限定用户或等级的券不支持游客
+func (s *CouponService) checkBuyer(coupon *models.Coupon, buyer CouponBuyer) error {
+	userIDs, err := decodeScopeIDs(coupon.UserIDs)
+	if err != nil {
+		return ErrCouponInvalid
+	}
+	levelIDs, err := decodeScopeIDs(coupon.MemberLevelIDs)
+	if err != nil {
+		return ErrCouponInvalid
+	}
+	if len(userIDs) > 0 || len(levelIDs) > 0 {
+		if buyer.UserID == 0 {
+			return ErrGuestCouponNotAllowed
+		}
+		if _, ok := userIDs[buyer.UserID]; len(userIDs) > 0 && !ok {
+			return ErrCouponUserNotEligible
+		}
+		if _, ok := levelIDs[buyer.MemberLevelID]; len(levelIDs) > 0 && !ok {
+			return ErrCouponUserNotEligible
+		}
+	}
+
+	if buyer.UserID == 0 && buyer.GuestEmail == "" && (coupon.FirstOrderOnly || coupon.PerUserLimit > 0) {
+		return ErrGuestEmailRequired
+	}
+
+	if coupon.FirstOrderOnly && s.orderRepo != nil {
+		count, err := s.orderRepo.CountFirstOrderClaims(buyer.UserID, buyer.GuestEmail)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrCouponFirstOrderOnly
+		}
+	}
+
+	if coupon.PerUserLimit > 0 {
+		var count int64
+		if buyer.UserID != 0 {
+			count, err = s.usageRepo.CountByUser(coupon.ID, buyer.UserID)
+		} else {
+			count, err = s.usageRepo.CountByGuestEmail(coupon.ID, buyer.GuestEmail)
+		}
+		if err != nil {
+			return err
+		}
+		if int(count) >= coupon.PerUserLimit {
+			return ErrCouponPerUserLimit
+		}
+	}
+	return nil
+}
+
+// resolveScope 解析适用范围，分类范围展开为自身及全部子分类
+func (s *CouponService) resolveScope(coupon *models.Coupon) (*couponScope, error) {
+	scopeType := strings.ToLower(strings.TrimSpace(coupon.ScopeType))
+	if scopeType == "" {
+		scopeType = constants.ScopeTypeProduct
+	}
+	excluded, err := decodeScopeIDs(coupon.ExcludedProductIDs)
+	if err != nil {
+		return nil, ErrCouponScopeInvalid
+	}
+	scope := &couponScope{scopeType: scopeType, excludedProductIDs: excluded}
+	if scopeType == constants.ScopeTypeAll {
+		return scope, nil
+	}
+	if !isCouponScopeType(scopeType) {
+		return nil, ErrCouponScopeInvalid
 	}
 
 	ids, err := decodeScopeIDs(coupon.ScopeRefIDs)
 	if err != nil {
-		return models.Money{}, ErrCouponScopeInvalid
+		return nil, ErrCouponScopeInvalid
 	}
 	if len(ids) == 0 {
-		return models.Money{}, ErrCouponScopeInvalid
+		return nil, ErrCouponScopeInvalid
+	}
+	if scopeType == constants.ScopeTypeCategory && s.categoryRepo != nil {
+		expanded := make(map[uint]struct{}, len(ids))
+		for id := range ids {
+			descendants, err := s.categoryRepo.ListDescendantIDs(id)
+			if err != nil {
+				return nil, err
+			}
+			for _, descendantID := range descendants {
+				expanded[descendantID] = struct{}{}
+			}
+		}
+		ids = expanded
+	}
+	scope.refIDs = ids
+	return scope, nil
+}
+
+func (s *CouponService) resolveEligibleSubtotal(coupon *models.Coupon, items []CouponItem) (models.Money, error) {
+	scope, err := s.resolveScope(coupon)
+	if err != nil {
+		return models.Money{}, err
 	}
 
 	eligible := decimal.Zero
 	for _, item := range items {
-		if _, ok := ids[item.ProductID]; ok {
+		if scope.matches(item.ProductID, item.SKUID, item.CategoryID) {
 			eligible = eligible.Add(item.TotalPrice.Decimal)
 		}
 	}
@@ -154,3 +291,11 @@ func decodeScopeIDs(raw string) (map[uint]struct{}, error) {
 	}
 	return result, nil
 }
+
+func isCouponScopeType(scopeType string) bool {
+	switch scopeType {
+	case constants.ScopeTypeProduct, constants.ScopeTypeCategory, constants.ScopeTypeSKU, constants.ScopeTypeAll:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/glebarez/sqlite"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+func setupCouponServiceTest(t *testing.T) (*gorm.DB, *CouponService, *CouponAdminService) {
+	t.Helper()
+	dsn := fmt.Sprintf("file:coupon_service_test_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Coupon{}, &models.CouponBatch{}, &models.CouponUsage{}, &models.Order{}, &models.Category{}); err != nil {
+		t.Fatalf("auto migrate failed: %v", err)
+	}
+	couponRepo := repository.NewCouponRepository(db)
+	svc := NewCouponService(couponRepo, repository.NewCouponUsageRepository(db))
+	svc.SetOrderRepository(repository.NewOrderRepository(db))
+	svc.SetCategoryRepository(repository.NewCategoryRepository(db))
+	return db, svc, NewCouponAdminService(couponRepo)
+}
+
+func TestCouponScopes(t *testing.T) {
+	db, svc, adminSvc := setupCouponServiceTest(t)
+	games := &models.Category{Slug: "games", NameJSON: models.JSON{"zh-CN": "games"}}
+	if err := db.Create(games).Error; err != nil {
+		t.Fatalf("create category failed: %v", err)
+	}
+	steam := &models.Category{ParentID: games.ID, Slug: "steam", NameJSON: models.JSON{"zh-CN": "steam"}}
+	if err := db.Create(steam).Error; err != nil {
+		t.Fatalf("create category failed: %v", err)
+	}
+
+	items := []CouponItem{
+		{ProductID: 1, SKUID: 11, CategoryID: steam.ID, TotalPrice: models.NewMoneyFromDecimal(decimal.NewFromInt(60))},
+		{ProductID: 2, SKUID: 21, CategoryID: 99, TotalPrice: models.NewMoneyFromDecimal(decimal.NewFromInt(40))},
+	}
+	subtotal := models.NewMoneyFromDecimal(decimal.NewFromInt(100))
+	create := func(input CreateCouponInput) {
+		input.Type = constants.CouponTypePercent
+		input.Value = models.NewMoneyFromDecimal(decimal.NewFromInt(10))
+		if _, err := adminSvc.Create(input); err != nil {
+			t.Fatalf("create coupon %s failed: %v", input.Code, err)
+		}
+	}
+	create(CreateCouponInput{Code: "CAT", ScopeType: constants.ScopeTypeCategory, ScopeRefIDs: []uint{games.ID}})
+	create(CreateCouponInput{Code: "SKU", ScopeType: constants.ScopeTypeSKU, ScopeRefIDs: []uint{21}})
+	create(CreateCouponInput{Code: "ALL", ScopeType: constants.ScopeTypeAll, ExcludedProductIDs: []uint{2}})
+	create(CreateCouponInput{Code: "LEGACY", ScopeRefIDs: []uint{2}})
+
+	cases := map[string]string{"CAT": "6.00", "SKU": "4.00", "ALL": "6.00", "LEGACY": "4.00"}
+	for code, expected := range cases {
+		discount, _, err := svc.ApplyCoupon(subtotal, code, CouponBuyer{UserID: 1}, items)
+		if err != nil {
+			t.Fatalf("apply %s failed: %v", code, err)
+		}
+		if discount.String() != expected {
+			t.Fatalf("apply %s expected %s, got %s", code, expected, discount.String())
+		}
+	}
+
+	if _, err := adminSvc.Create(CreateCouponInput{
+		Code:      "BAD",
+		Type:      constants.CouponTypeFixed,
+		Value:     models.NewMoneyFromDecimal(decimal.NewFromInt(1)),
+		ScopeType: constants.ScopeTypeCategory,
+	}); !errors.Is(err, ErrCouponScopeInvalid) {
+		t.Fatalf("expected category scope without ids to be rejected, got %v", err)
+	}
+	if _, err := adminSvc.Create(CreateCouponInput{
+		Code:      "BAD",
+		Type:      constants.CouponTypeFixed,
+		Value:     models.NewMoneyFromDecimal(decimal.NewFromInt(1)),
+		ScopeType: "brand",
+	}); !errors.Is(err, ErrCouponScopeInvalid) {
+		t.Fatalf("expected unknown scope type to be rejected, got %v", err)
+	}
+}
+
+func TestCouponBuyerRestrictions(t *testing.T) {
+	db, svc, adminSvc := setupCouponServiceTest(t)
+	items := []CouponItem{{ProductID: 1, SKUID: 11, TotalPrice: models.NewMoneyFromDecimal(decimal.NewFromInt(50))}}
+	subtotal := models.NewMoneyFromDecimal(decimal.NewFromInt(50))
+	create := func(input CreateCouponInput) {
+		input.Type = constants.CouponTypeFixed
+		input.Value = models.NewMoneyFromDecimal(decimal.NewFromInt(5))
+		input.ScopeType = constants.ScopeTypeAll
+		if _, err := adminSvc.Create(input); err != nil {
+			t.Fatalf("create coupon %s failed: %v", input.Code, err)
+		}
+	}
+	create(CreateCouponInput{Code: "USERS", UserIDs: []uint{7}})
+	create(CreateCouponInput{Code: "LEVELS", MemberLevelIDs: []uint{3}})
+	create(CreateCouponInput{Code: "FIRST", FirstOrderOnly: true})
+	create(CreateCouponInput{Code: "ONCE", PerUserLimit: 1})
+
+	if _, _, err := svc.ApplyCoupon(subtotal, "USERS", CouponBuyer{UserID: 7}, items); err != nil {
+		t.Fatalf("expected listed user to apply coupon, got %v", err)
+	}
+	if _, _, err := svc.ApplyCoupon(subtotal, "USERS", CouponBuyer{UserID: 8}, items); !errors.Is(err, ErrCouponUserNotEligible) {
+		t.Fatalf("expected unlisted user to be rejected, got %v", err)
+	}
+	if _, _, err := svc.ApplyCoupon(subtotal, "USERS", CouponBuyer{GuestEmail: "guest@example.com"}, items); !errors.Is(err, ErrGuestCouponNotAllowed) {
+		t.Fatalf("expected guest to be rejected for user-restricted coupon, got %v", err)
+	}
+	if _, _, err := svc.ApplyCoupon(subtotal, "LEVELS", CouponBuyer{UserID: 8, MemberLevelID: 3}, items); err != nil {
+		t.Fatalf("expected member level to apply coupon, got %v", err)
+	}
+	if _, _, err := svc.ApplyCoupon(subtotal, "LEVELS", CouponBuyer{UserID: 8, MemberLevelID: 1}, items); !errors.Is(err, ErrCouponUserNotEligible) {
+		t.Fatalf("expected other member level to be rejected, got %v", err)
+	}
+
+	paidOrders := []models.Order{
+		{OrderNo: "O1", UserID: 7, Status: constants.OrderStatusCompleted, Currency: "CNY"},
+		{OrderNo: "O2", GuestEmail: "old@example.com", Status: constants.OrderStatusPaid, Currency: "CNY"},
+		{OrderNo: "O3", UserID: 8, Status: constants.OrderStatusCanceled, Currency: "CNY"},
+	}
+	if err := db.Create(&paidOrders).Error; err != nil {
+		t.Fatalf("create orders failed: %v", err)
+	}
+	if _, _, err := svc.ApplyCoupon(subtotal, "FIRST", CouponBuyer{UserID: 7}, items); !errors.Is(err, ErrCouponFirstOrderOnly) {
+		t.Fatalf("expected returning user to be rejected, got %v", err)
+	}
+	if _, _, err := svc.ApplyCoupon(subtotal, "FIRST", CouponBuyer{UserID: 8}, items); err != nil {
+		t.Fatalf("expected user with only canceled orders to apply first-order coupon, got %v", err)
+	}
+	if _, _, err := svc.ApplyCoupon(subtotal, "FIRST", CouponBuyer{GuestEmail: " OLD@example.com "}, items); !errors.Is(err, ErrCouponFirstOrderOnly) {
+		t.Fatalf("expected returning guest to be rejected, got %v", err)
+	}
+	if _, _, err := svc.ApplyCoupon(subtotal, "FIRST", CouponBuyer{GuestEmail: "new@example.com"}, items); err != nil {
+		t.Fatalf("expected new guest to apply first-order coupon, got %v", err)
+	}
+
+	first, err := svc.couponRepo.GetByCode("FIRST")
+	if err != nil || first == nil {
+		t.Fatalf("load first-order coupon failed: %v", err)
+	}
+	pendingOrders := []models.Order{
+		{OrderNo: "O4", UserID: 9, Status: constants.OrderStatusPendingPayment, Currency: "CNY"},
+		{OrderNo: "O5", UserID: 10, Status: constants.OrderStatusPendingPayment, CouponID: &first.ID, Currency: "CNY"},
+	}
+	if err := db.Create(&pendingOrders).Error; err != nil {
+		t.Fatalf("create pending orders failed: %v", err)
+	}
+	if _, _, err := svc.ApplyCoupon(subtotal, "FIRST", CouponBuyer{UserID: 9}, items); err != nil {
+		t.Fatalf("expected pending order without coupon to keep first-order eligibility, got %v", err)
+	}
+	if _, _, err := svc.ApplyCoupon(subtotal, "FIRST", CouponBuyer{UserID: 10}, items); !errors.Is(err, ErrCouponFirstOrderOnly) {
+		t.Fatalf("expected pending first-order coupon order to block another claim, got %v", err)
+	}
+
+	_, once, err := svc.ApplyCoupon(subtotal, "ONCE", CouponBuyer{GuestEmail: "old@example.com"}, items)
+	if err != nil {
+		t.Fatalf("expected guest to apply per-user coupon, got %v", err)
+	}
+	if err := db.Create(&models.CouponUsage{CouponID: once.ID, OrderID: paidOrders[1].ID}).Error; err != nil {
+		t.Fatalf("create coupon usage failed: %v", err)
+	}
+	if _, _, err := svc.ApplyCoupon(subtotal, "ONCE", CouponBuyer{GuestEmail: "old@example.com"}, items); !errors.Is(err, ErrCouponPerUserLimit) {
+		t.Fatalf("expected guest per-user limit by email, got %v", err)
+	}
+	if _, _, err := svc.ApplyCoupon(subtotal, "ONCE", CouponBuyer{GuestEmail: "new@example.com"}, items); err != nil {
+		t.Fatalf("expected another guest email to apply coupon, got %v", err)
+	}
+}
+
+func TestCouponBatchGenerateStatsAndExport(t *testing.T) {
+	db, svc, adminSvc := setupCouponServiceTest(t)
+	batch, created, err := adminSvc.GenerateBatch(GenerateCouponBatchInput{
+		Name:       "spring",
+		CodePrefix: "spr-",
+		Quantity:   50,
+		Template: CreateCouponInput{
+			Type:      constants.CouponTypeFixed,
+			Value:     models.NewMoneyFromDecimal(decimal.NewFromInt(5)),
+			ScopeType: constants.ScopeTypeAll,
+		},
+	})
+	if err != nil {
+		t.Fatalf("generate batch failed: %v", err)
+	}
+	if created != 50 || batch.UsageLimit != 1 || !batch.IsActive {
+		t.Fatalf("unexpected batch: created=%d batch=%+v", created, batch)
+	}
+
+	coupons, total, err := adminSvc.List(repository.CouponListFilter{BatchID: batch.ID, Page: 1, PageSize: 100})
+	if err != nil || total != 50 {
+		t.Fatalf("list batch coupons failed: total=%d err=%v", total, err)
+	}
+	seen := make(map[string]struct{}, len(coupons))
+	for _, coupon := range coupons {
+		if !strings.HasPrefix(coupon.Code, "SPR-") || len(coupon.Code) != len("SPR-")+couponCodeRandomLength {
+			t.Fatalf("unexpected generated code %q", coupon.Code)
+		}
+		if _, ok := seen[coupon.Code]; ok {
+			t.Fatalf("duplicated code %q", coupon.Code)
+		}
+		seen[coupon.Code] = struct{}{}
+	}
+	if _, standalone, err := adminSvc.List(repository.CouponListFilter{ExcludeBatch: true, Page: 1, PageSize: 10}); err != nil || standalone != 0 {
+		t.Fatalf("expected batch codes to be excluded, total=%d err=%v", standalone, err)
+	}
+
+	items := []CouponItem{{ProductID: 1, SKUID: 1, TotalPrice: models.NewMoneyFromDecimal(decimal.NewFromInt(20))}}
+	subtotal := models.NewMoneyFromDecimal(decimal.NewFromInt(20))
+	code := coupons[0].Code
+	discount, coupon, err := svc.ApplyCoupon(subtotal, code, CouponBuyer{UserID: 1}, items)
+	if err != nil || discount.String() != "5.00" {
+		t.Fatalf("apply batch code failed: discount=%s err=%v", discount.String(), err)
+	}
+	if err := db.Create(&models.CouponUsage{CouponID: coupon.ID, UserID: 1, OrderID: 9, DiscountAmount: discount}).Error; err != nil {
+		t.Fatalf("create usage failed: %v", err)
+	}
+	if err := repository.NewCouponRepository(db).IncrementUsedCount(coupon.ID, 1); err != nil {
+		t.Fatalf("increment used count failed: %v", err)
+	}
+	if _, _, err := svc.ApplyCoupon(subtotal, code, CouponBuyer{UserID: 2}, items); !errors.Is(err, ErrCouponUsageLimit) {
+		t.Fatalf("expected single-use code to be exhausted, got %v", err)
+	}
+
+	detail, err := adminSvc.GetBatch(batch.ID)
+	if err != nil {
+		t.Fatalf("get batch failed: %v", err)
+	}
+	if detail.Stats.CodeCount != 50 || detail.Stats.UsedCodeCount != 1 || detail.Stats.UsageCount != 1 || detail.Stats.OrderCount != 1 || detail.Stats.DiscountAmount.String() != "5.00" {
+		t.Fatalf("unexpected batch stats: %+v", detail.Stats)
+	}
+
+	if _, err := adminSvc.UpdateBatchStatus(batch.ID, false); err != nil {
+		t.Fatalf("disable batch failed: %v", err)
+	}
+	if _, _, err := svc.ApplyCoupon(subtotal, coupons[1].Code, CouponBuyer{UserID: 1}, items); !errors.Is(err, ErrCouponInactive) {
+		t.Fatalf("expected disabled batch code to be inactive, got %v", err)
+	}
+	if _, err := adminSvc.UpdateBatchStatus(batch.ID+100, true); !errors.Is(err, ErrCouponBatchNotFound) {
+		t.Fatalf("expected missing batch error, got %v", err)
+	}
+
+	content, contentType, err := adminSvc.ExportBatch(batch.ID, constants.ExportFormatCSV)
+	if err != nil || !strings.HasPrefix(contentType, "text/csv") {
+		t.Fatalf("export batch failed: type=%s err=%v", contentType, err)
+	}
+	records, err := csv.NewReader(strings.NewReader(string(content))).ReadAll()
+	if err != nil {
+		t.Fatalf("parse export failed: %v", err)
+	}
+	if len(records) != 51 || records[0][2] != "code" || records[1][1] != batch.BatchNo || records[1][7] != "false" {
+		t.Fatalf("unexpected export rows: %d first=%v", len(records), records[1])
+	}
+
+	if _, _, err := adminSvc.GenerateBatch(GenerateCouponBatchInput{
+		Name:     "too-many",
+		Quantity: couponBatchMaxQuantity + 1,
+		Template: CreateCouponInput{Type: constants.CouponTypeFixed, Value: models.NewMoneyFromDecimal(decimal.NewFromInt(1)), ScopeType: constants.ScopeTypeAll},
+	}); !errors.Is(err, ErrCouponInvalid) {
+		t.Fatalf("expected oversized batch to be rejected, got %v", err)
+	}
+}
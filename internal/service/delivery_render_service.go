@@ -0,0 +1,458 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dujiao-next/internal/config"
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/logger"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+)
+
+const (
+	deliveryTemplateContentKey      = "content"
+	deliveryTemplateInstructionsKey = "instructions"
+	deliveryTemplateMaxRunes        = 20000
+	deliveryTemplateSecretPrefix    = "secret."
+	deliveryTemplateDataPrefix      = "data."
+)
+
+var deliveryPlaceholderPattern = regexp.MustCompile(`\{\{\s*([a-z0-9_.]+)\s*\}\}`)
+
+// deliveryTemplatePlaceholders 模板支持的固定占位符，另支持 secret.<字段key> 与 data.<字段key>
+var deliveryTemplatePlaceholders = map[string]struct{}{
+	"order_no":     {},
+	"buyer":        {},
+	"product":      {},
+	"sku_code":     {},
+	"sku_spec":     {},
+	"quantity":     {},
+	"delivered_at": {},
+	"content":      {},
+	"instructions": {},
+}
+
+// DeliveryRenderService 交付内容模板渲染服务（邮件、订单详情、Telegram 共用）
+type DeliveryRenderService struct {
+	orderRepo      repository.OrderRepository
+	productRepo    repository.ProductRepository
+	productSKURepo repository.ProductSKURepository
+	secretRepo     repository.CardSecretRepository
+	userRepo       repository.UserRepository
+	identityRepo   repository.UserOAuthIdentityRepository
+	telegramSender *TelegramNotifyService
+}
+
+// NewDeliveryRenderService 创建交付内容模板渲染服务
+func NewDeliveryRenderService(
+	orderRepo repository.OrderRepository,
+	productRepo repository.ProductRepository,
+	productSKURepo repository.ProductSKURepository,
+	secretRepo repository.CardSecretRepository,
+	userRepo repository.UserRepository,
+	identityRepo repository.UserOAuthIdentityRepository,
+	settingService *SettingService,
+	defaultTelegramCfg config.TelegramAuthConfig,
+) *DeliveryRenderService {
+	return &DeliveryRenderService{
+		orderRepo:      orderRepo,
+		productRepo:    productRepo,
+		productSKURepo: productSKURepo,
+		secretRepo:     secretRepo,
+		userRepo:       userRepo,
+		identityRepo:   identityRepo,
+		telegramSender: NewTelegramNotifyService(settingService, defaultTelegramCfg),
+	}
+}
+
+// normalizeDeliveryTemplate 校验交付模板：{"content": {locale: text}, "instructions": {locale: text}}
+func normalizeDeliveryTemplate(raw models.JSON) (models.JSON, error) {
+	if len(raw) == 0 {
+		return models.JSON{}, nil
+	}
+	normalized := models.JSON{}
+	for key, value := range raw {
+		if key != deliveryTemplateContentKey && key != deliveryTemplateInstructionsKey {
+			return nil, ErrDeliveryTemplateInvalid
+		}
+		texts, err := parseLocaleTextMapStrict(raw, key)
+		if err != nil || value == nil {
+			return nil, ErrDeliveryTemplateInvalid
+		}
+		for _, text := range texts {
+			content := fmt.Sprintf("%v", text)
+			if utf8.RuneCountInString(content) > deliveryTemplateMaxRunes {
+				return nil, ErrDeliveryTemplateInvalid
+			}
+			if err := validateDeliveryPlaceholders(content); err != nil {
+				return nil, err
+			}
+		}
+		if len(texts) > 0 {
+			normalized[key] = texts
+		}
+	}
+	if _, ok := normalized[deliveryTemplateContentKey]; !ok && len(normalized) > 0 {
+		return nil, ErrDeliveryTemplateInvalid
+	}
+	return normalized, nil
+}
+
+func validateDeliveryPlaceholders(content string) error {
+	for _, match := range deliveryPlaceholderPattern.FindAllStringSubmatch(content, -1) {
+		name := match[1]
+		if _, ok := deliveryTemplatePlaceholders[name]; ok {
+			continue
+		}
+		if key, ok := strings.CutPrefix(name, deliveryTemplateSecretPrefix); ok && cardSecretFieldKeyPattern.MatchString(key) {
+			continue
+		}
+		if key, ok := strings.CutPrefix(name, deliveryTemplateDataPrefix); ok && manualFormFieldKeyPattern.MatchString(key) {
+			continue
+		}
+		return ErrDeliveryTemplateInvalid
+	}
+	return nil
+}
+
+// resolveDeliveryTemplateSnapshot 下单时确定交付模板：SKU 模板优先，否则使用商品模板
+func resolveDeliveryTemplateSnapshot(product *models.Product, sku *models.ProductSKU) models.JSON {
+	if sku != nil && len(sku.DeliveryTmplJSON) > 0 {
+		return sku.DeliveryTmplJSON
+	}
+	if product != nil && len(product.DeliveryTmplJSON) > 0 {
+		return product.DeliveryTmplJSON
+	}
+	return models.JSON{}
+}
+
+// deliveryRenderContext 渲染一次交付内容所需的数据
+type deliveryRenderContext struct {
+	OrderNo      string
+	Buyer        string
+	Product      string
+	SKUCode      string
+	SKUSpec      string
+	Quantity     int
+	DeliveredAt  *time.Time
+	Content      string
+	DeliveryData models.JSON
+	SecretFields []models.JSON
+}
+
+// renderDeliveryTemplate 按语言选取模板并替换占位符，模板缺失时返回空字符串
+func renderDeliveryTemplate(template models.JSON, locale string, ctx deliveryRenderContext) string {
+	content := resolveDeliveryTemplateText(template, deliveryTemplateContentKey, locale)
+	if content == "" {
+		return ""
+	}
+	instructions := resolveDeliveryTemplateText(template, deliveryTemplateInstructionsKey, locale)
+	rendered := deliveryPlaceholderPattern.ReplaceAllStringFunc(content, func(match string) string {
+		name := deliveryPlaceholderPattern.FindStringSubmatch(match)[1]
+		switch name {
+		case "order_no":
+			return ctx.OrderNo
+		case "buyer":
+			return ctx.Buyer
+		case "product":
+			return ctx.Product
+		case "sku_code":
+			return ctx.SKUCode
+		case "sku_spec":
+			return ctx.SKUSpec
+		case "quantity":
+			return strconv.Itoa(ctx.Quantity)
+		case "delivered_at":
+			if ctx.DeliveredAt == nil {
+				return ""
+			}
+			return ctx.DeliveredAt.Format("2006-01-02 15:04:05")
+		case "content":
+			return ctx.Content
+		case "instructions":
+			return instructions
+		}
+		if key, ok := strings.CutPrefix(name, deliveryTemplateSecretPrefix); ok {
+			values := make([]string, 0, len(ctx.SecretFields))
+			for _, fields := range ctx.SecretFields {
+				if value, exists := fields[key]; exists && value != nil {
+					values = append(values, fmt.Sprintf("%v", value))
+				}
+			}
+			return strings.Join(values, "\n")
+		}
+		if key, ok := strings.CutPrefix(name, deliveryTemplateDataPrefix); ok {
+			if value, exists := ctx.DeliveryData[key]; exists && value != nil {
+				return fmt.Sprintf("%v", value)
+			}
+			return ""
+		}
+		return match
+	})
+	return strings.TrimSpace(rendered)
+}
+
+func resolveDeliveryTemplateText(template models.JSON, key, locale string) string {
+	raw, ok := template[key]
+	if !ok {
+		return ""
+	}
+	texts, ok := raw.(map[string]interface{})
+	if !ok {
+		if typed, isJSON := raw.(models.JSON); isJSON {
+			texts = typed
+		} else {
+			return ""
+		}
+	}
+	candidates := []string{locale, constants.LocaleZhCN, constants.LocaleEnUS, constants.LocaleZhTW}
+	for _, candidate := range candidates {
+		if value, exists := texts[candidate]; exists {
+			if text := strings.TrimSpace(fmt.Sprintf("%v", value)); text != "" {
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+func formatSKUSpecValues(snapshot models.JSON) string {
+	raw, ok := snapshot["spec_values"]
+	if !ok || raw == nil {
+		return ""
+	}
+	values, ok := raw.(map[string]interface{})
+	if !ok {
+		if typed, isJSON := raw.(models.JSON); isJSON {
+			values = typed
+		} else {
+			return ""
+		}
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s: %v", key, values[key]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ApplyToOrder 为已交付（含部分交付）的订单及子订单填充 Fulfillment.Rendered，未配置模板时保持为空
+func (s *DeliveryRenderService) ApplyToOrder(order *models.Order, locale string) {
+	if s == nil || order == nil {
+		return
+	}
+	buyer := ""
+	buyerResolved := false
+	resolveBuyer := func() string {
+		if !buyerResolved {
+			buyer = s.resolveBuyer(order)
+			buyerResolved = true
+		}
+		return buyer
+	}
+	s.applyToSingleOrder(order, locale, resolveBuyer)
+	for i := range order.Children {
+		s.applyToSingleOrder(&order.Children[i], locale, resolveBuyer)
+	}
+}
+
+func (s *DeliveryRenderService) applyToSingleOrder(order *models.Order, locale string, resolveBuyer func() string) {
+	if order.Fulfillment == nil {
+		return
+	}
+	if order.Fulfillment.Status != constants.FulfillmentStatusDelivered && order.Fulfillment.Status != constants.FulfillmentStatusPartial {
+		return
+	}
+	// 仅单商品订单（子订单）可将交付内容对应到具体模板
+	if len(order.Items) != 1 || len(order.Items[0].DeliveryTmplSnapshotJSON) == 0 {
+		return
+	}
+	item := order.Items[0]
+	template := item.DeliveryTmplSnapshotJSON
+	ctx := deliveryRenderContext{
+		OrderNo:      order.OrderNo,
+		Product:      resolveReceiptItemTitle(item, locale),
+		SKUCode:      resolveReceiptItemSKUCode(item),
+		SKUSpec:      formatSKUSpecValues(item.SKUSnapshotJSON),
+		Quantity:     item.Quantity,
+		DeliveredAt:  order.Fulfillment.DeliveredAt,
+		Content:      order.Fulfillment.Payload,
+		DeliveryData: order.Fulfillment.LogisticsJSON,
+	}
+	if deliveryTemplateUses(template, "buyer") {
+		ctx.Buyer = resolveBuyer()
+	}
+	if deliveryTemplateUses(template, deliveryTemplateSecretPrefix) && s.secretRepo != nil {
+		secrets, err := s.secretRepo.ListByOrderAndStatus(order.ID, models.CardSecretStatusUsed)
+		if err != nil {
+			logger.Warnw("delivery_render_fetch_secrets_failed", "order_id", order.ID, "error", err)
+		}
+		for _, secret := range secrets {
+			if len(secret.Fields) > 0 {
+				ctx.SecretFields = append(ctx.SecretFields, secret.Fields)
+			}
+		}
+	}
+	order.Fulfillment.Rendered = renderDeliveryTemplate(template, locale, ctx)
+}
+
+// deliveryTemplateUses 判断模板是否引用了某个占位符（或占位符前缀），用于避免无谓的查询
+func deliveryTemplateUses(template models.JSON, name string) bool {
+	raw, ok := template[deliveryTemplateContentKey]
+	if !ok {
+		return false
+	}
+	texts, ok := raw.(map[string]interface{})
+	if !ok {
+		if typed, isJSON := raw.(models.JSON); isJSON {
+			texts = typed
+		} else {
+			return false
+		}
+	}
+	for _, value := range texts {
+		for _, match := range deliveryPlaceholderPattern.FindAllStringSubmatch(fmt.Sprintf("%v", value), -1) {
+			if match[1] == name || (strings.HasSuffix(name, ".") && strings.HasPrefix(match[1], name)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *DeliveryRenderService) resolveBuyer(order *models.Order) string {
+	if order.UserID == 0 || s.userRepo == nil {
+		return strings.TrimSpace(order.GuestEmail)
+	}
+	user, err := s.userRepo.GetByID(order.UserID)
+	if err != nil || user == nil {
+		return ""
+	}
+	if email := strings.TrimSpace(user.Email); email != "" && !isTelegramPlaceholderEmail(email) {
+		return email
+	}
+	return strings.TrimSpace(user.DisplayName)
+}
+
+// DeliveryPreviewInput 后台交付模板预览输入
+type DeliveryPreviewInput struct {
+	ProductID uint
+	SKUID     uint
+	OrderID   uint        // 指定时使用真实订单数据，否则使用示例数据
+	Template  models.JSON // 未保存的模板，为空时使用已保存的 SKU/商品模板
+	Locale    string
+}
+
+// Preview 使用示例订单（或指定订单）渲染交付模板
+func (s *DeliveryRenderService) Preview(input DeliveryPreviewInput) (string, error) {
+	if input.ProductID == 0 {
+		return "", ErrDeliveryTemplateInvalid
+	}
+	product, err := s.productRepo.GetByID(strconv.FormatUint(uint64(input.ProductID), 10))
+	if err != nil {
+		return "", ErrProductFetchFailed
+	}
+	if product == nil {
+		return "", ErrProductNotFound
+	}
+	var sku *models.ProductSKU
+	if input.SKUID > 0 {
+		sku, err = s.productSKURepo.GetByID(input.SKUID)
+		if err != nil {
+			return "", ErrProductFetchFailed
+		}
+		if sku == nil || sku.ProductID != product.ID {
+			return "", ErrProductSKUInvalid
+		}
+	}
+	template := resolveDeliveryTemplateSnapshot(product, sku)
+	if len(input.Template) > 0 {
+		template, err = normalizeDeliveryTemplate(input.Template)
+		if err != nil {
+			return "", err
+		}
+	}
+	if len(template) == 0 {
+		return "", ErrDeliveryTemplateInvalid
+	}
+
+	if input.OrderID > 0 {
+		order, err := s.orderRepo.GetByID(input.OrderID)
+		if err != nil {
+			return "", ErrOrderFetchFailed
+		}
+		if order == nil || order.Fulfillment == nil || len(order.Items) != 1 || order.Items[0].ProductID != product.ID {
+			return "", ErrOrderNotFound
+		}
+		order.Items[0].DeliveryTmplSnapshotJSON = template
+		s.applyToSingleOrder(order, input.Locale, func() string { return s.resolveBuyer(order) })
+		return order.Fulfillment.Rendered, nil
+	}
+
+	now := time.Now()
+	item := models.OrderItem{ProductID: product.ID, TitleJSON: product.TitleJSON, Quantity: 1}
+	if sku != nil {
+		item.SKUSnapshotJSON = models.JSON{"sku_code": sku.SKUCode, "spec_values": sku.SpecValuesJSON}
+	}
+	ctx := deliveryRenderContext{
+		OrderNo:     "DJ" + now.Format("20060102150405") + "0001",
+		Buyer:       "buyer@example.com",
+		Product:     resolveReceiptItemTitle(item, input.Locale),
+		SKUCode:     resolveReceiptItemSKUCode(item),
+		SKUSpec:     formatSKUSpecValues(item.SKUSnapshotJSON),
+		Quantity:    1,
+		DeliveredAt: &now,
+		Content:     "CARD-XXXX-0001",
+	}
+	fields, _, err := parseCardSecretSchema(product.SecretSchemaJSON)
+	if err != nil {
+		return "", err
+	}
+	if len(fields) > 0 {
+		sample := models.JSON{}
+		for _, field := range fields {
+			sample[field.Key] = field.Key + "-sample"
+		}
+		ctx.SecretFields = []models.JSON{sample}
+		ctx.Content = renderCardSecretPayload(fields, []models.CardSecret{{Secret: "sample", Fields: sample}}, input.Locale)
+	}
+	return renderDeliveryTemplate(template, input.Locale, ctx), nil
+}
+
+// NotifyTelegram 向绑定了 Telegram 的会员推送交付内容，失败只记录日志
+func (s *DeliveryRenderService) NotifyTelegram(ctx context.Context, order *models.Order, input OrderStatusEmailInput, locale string) {
+	if s == nil || order == nil || order.UserID == 0 || s.identityRepo == nil || s.telegramSender == nil {
+		return
+	}
+	if !isDeliveryNotifyStatus(input.Status) {
+		return
+	}
+	if strings.TrimSpace(input.FulfillmentInfo) == "" {
+		return
+	}
+	identity, err := s.identityRepo.GetByUserProvider(order.UserID, constants.UserOAuthProviderTelegram)
+	if err != nil {
+		logger.Warnw("delivery_telegram_fetch_identity_failed", "order_id", order.ID, "error", err)
+		return
+	}
+	if identity == nil || strings.TrimSpace(identity.ProviderUserID) == "" {
+		return
+	}
+	subject, body := buildOrderStatusContent(input, locale)
+	if err := s.telegramSender.SendMessage(ctx, identity.ProviderUserID, composeTelegramMessage(subject, body)); err != nil {
+		logger.Warnw("delivery_telegram_send_failed", "order_id", order.ID, "error", err)
+	}
+}
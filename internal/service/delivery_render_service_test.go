@@ -0,0 +1,157 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dujiao-next/internal/config"
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestNormalizeDeliveryTemplate(t *testing.T) {
+	normalized, err := normalizeDeliveryTemplate(models.JSON{
+		"content": map[string]interface{}{
+			"zh-CN": " 订单 {{ order_no }}：{{secret.account}} ",
+			"en-US": "",
+		},
+		"instructions": map[string]interface{}{"zh-CN": "请及时修改密码"},
+	})
+	if err != nil {
+		t.Fatalf("normalize template failed: %v", err)
+	}
+	content, ok := normalized["content"].(models.JSON)
+	if !ok || len(content) != 1 || content["zh-CN"] != "订单 {{ order_no }}：{{secret.account}}" {
+		t.Fatalf("unexpected normalized content: %#v", normalized["content"])
+	}
+
+	invalid := []models.JSON{
+		{"content": map[string]interface{}{"zh-CN": "{{unknown}}"}},
+		{"content": map[string]interface{}{"zh-CN": "{{secret.}}"}},
+		{"instructions": map[string]interface{}{"zh-CN": "只有说明"}},
+		{"footer": map[string]interface{}{"zh-CN": "x"}},
+		{"content": "plain"},
+	}
+	for _, raw := range invalid {
+		if _, err := normalizeDeliveryTemplate(raw); !errors.Is(err, ErrDeliveryTemplateInvalid) {
+			t.Fatalf("expected invalid template for %#v, got %v", raw, err)
+		}
+	}
+}
+
+func TestDeliveryRenderServiceApplyToOrder(t *testing.T) {
+	db := setupCardSecretServiceTestDB(t)
+	if err := db.AutoMigrate(&models.User{}); err != nil {
+		t.Fatalf("auto migrate user failed: %v", err)
+	}
+
+	secret := &models.CardSecret{
+		ProductID: 1,
+		SKUID:     1,
+		Secret:    "u1 | p1",
+		Fields:    models.JSON{"account": "u1", "password": "p1"},
+		Status:    models.CardSecretStatusUsed,
+	}
+	orderID := uint(10)
+	secret.OrderID = &orderID
+	if err := db.Create(secret).Error; err != nil {
+		t.Fatalf("create secret failed: %v", err)
+	}
+
+	deliveredAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	order := &models.Order{
+		ID:         orderID,
+		OrderNo:    "DJ0001",
+		GuestEmail: "guest@example.com",
+		Items: []models.OrderItem{{
+			ProductID:       1,
+			TitleJSON:       models.JSON{"zh-CN": "游戏账号", "en-US": "Game Account"},
+			SKUSnapshotJSON: models.JSON{"sku_code": "VIP", "spec_values": map[string]interface{}{"region": "US"}},
+			Quantity:        1,
+			DeliveryTmplSnapshotJSON: models.JSON{
+				"content": map[string]interface{}{
+					"zh-CN": "{{buyer}} 购买的 {{product}}（{{sku_code}} / {{sku_spec}}）\n账号：{{secret.account}}\n{{instructions}}",
+				},
+				"instructions": map[string]interface{}{"en-US": "Change the password after login"},
+			},
+		}},
+		Fulfillment: &models.Fulfillment{
+			Status:      constants.FulfillmentStatusDelivered,
+			Payload:     "u1 | p1",
+			DeliveredAt: &deliveredAt,
+		},
+	}
+
+	svc := NewDeliveryRenderService(nil, nil, nil, repository.NewCardSecretRepository(db), repository.NewUserRepository(db), nil, nil, config.TelegramAuthConfig{})
+	svc.ApplyToOrder(order, constants.LocaleEnUS)
+
+	want := "guest@example.com 购买的 Game Account（VIP / region: US）\n账号：u1\nChange the password after login"
+	if order.Fulfillment.Rendered != want {
+		t.Fatalf("unexpected rendered content:\n%s", order.Fulfillment.Rendered)
+	}
+
+	pending := &models.Order{
+		Items:       order.Items,
+		Fulfillment: &models.Fulfillment{Status: constants.FulfillmentStatusPending, Payload: "x"},
+	}
+	svc.ApplyToOrder(pending, constants.LocaleEnUS)
+	if pending.Fulfillment.Rendered != "" {
+		t.Fatalf("pending fulfillment should not be rendered")
+	}
+}
+
+func TestDeliveryRenderServicePreviewWithSampleData(t *testing.T) {
+	db := setupCardSecretServiceTestDB(t)
+
+	product := &models.Product{
+		CategoryID:      1,
+		Slug:            "delivery-preview-product",
+		TitleJSON:       models.JSON{"zh-CN": "会员兑换码"},
+		PriceAmount:     models.NewMoneyFromDecimal(decimal.NewFromInt(10)),
+		PurchaseType:    constants.ProductPurchaseMember,
+		FulfillmentType: constants.FulfillmentTypeAuto,
+		SecretSchemaJSON: models.JSON{"fields": []interface{}{
+			map[string]interface{}{"key": "code", "label": map[string]interface{}{"zh-CN": "兑换码"}, "required": true},
+		}},
+		DeliveryTmplJSON: models.JSON{"content": map[string]interface{}{"zh-CN": "已保存模板 {{product}}"}},
+		IsActive:         true,
+	}
+	if err := db.Create(product).Error; err != nil {
+		t.Fatalf("create product failed: %v", err)
+	}
+
+	svc := NewDeliveryRenderService(nil, repository.NewProductRepository(db), repository.NewProductSKURepository(db), nil, nil, nil, nil, config.TelegramAuthConfig{})
+	rendered, err := svc.Preview(DeliveryPreviewInput{ProductID: product.ID, Locale: constants.LocaleZhCN})
+	if err != nil {
+		t.Fatalf("preview saved template failed: %v", err)
+	}
+	if rendered != "已保存模板 会员兑换码" {
+		t.Fatalf("unexpected saved template preview: %s", rendered)
+	}
+
+	rendered, err = svc.Preview(DeliveryPreviewInput{
+		ProductID: product.ID,
+		Locale:    constants.LocaleZhCN,
+		Template: models.JSON{"content": map[string]interface{}{
+			"zh-CN": "兑换码：{{secret.code}}\n{{content}}",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("preview draft template failed: %v", err)
+	}
+	if !strings.HasPrefix(rendered, "兑换码：code-sample") || !strings.Contains(rendered, "| 兑换码 |") {
+		t.Fatalf("unexpected draft template preview: %s", rendered)
+	}
+
+	if _, err := svc.Preview(DeliveryPreviewInput{
+		ProductID: product.ID,
+		Template:  models.JSON{"content": map[string]interface{}{"zh-CN": "{{nope}}"}},
+	}); !errors.Is(err, ErrDeliveryTemplateInvalid) {
+		t.Fatalf("expected invalid template error, got %v", err)
+	}
+}
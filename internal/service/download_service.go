@@ -0,0 +1,345 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"mime/multipart"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dujiao-next/internal/config"
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/logger"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+)
+
+const (
+	defaultDownloadLinkTTL  = 30 * time.Minute
+	downloadSigningKeyLabel = "dujiao-next/download-link"
+)
+
+// DownloadService 文件交付与签名下载服务
+type DownloadService struct {
+	cfg             *config.Config
+	orderRepo       repository.OrderRepository
+	productRepo     repository.ProductRepository
+	productSKURepo  repository.ProductSKURepository
+	productFileRepo repository.ProductFileRepository
+	uploadService   *UploadService
+}
+
+// NewDownloadService 创建文件交付下载服务
+func NewDownloadService(cfg *config.Config, orderRepo repository.OrderRepository, productRepo repository.ProductRepository, productSKURepo repository.ProductSKURepository, productFileRepo repository.ProductFileRepository, uploadService *UploadService) *DownloadService {
+	return &DownloadService{
+		cfg:             cfg,
+		orderRepo:       orderRepo,
+		productRepo:     productRepo,
+		productSKURepo:  productSKURepo,
+		productFileRepo: productFileRepo,
+		uploadService:   uploadService,
+	}
+}
+
+// ListProductFiles 获取商品交付文件
+func (s *DownloadService) ListProductFiles(productID uint) ([]models.ProductFile, error) {
+	product, err := s.productRepo.GetByID(strconv.FormatUint(uint64(productID), 10))
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+	return s.productFileRepo.ListByProduct(productID)
+}
+
+// UploadProductFile 上传商品交付文件，skuID 为 0 时商品所有 SKU 共用
+func (s *DownloadService) UploadProductFile(productID, skuID uint, file *multipart.FileHeader, sortOrder int) (*models.ProductFile, error) {
+	if file == nil {
+		return nil, ErrProductFileUploadFailed
+	}
+	product, err := s.productRepo.GetByID(strconv.FormatUint(uint64(productID), 10))
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+	if skuID > 0 {
+		sku, err := s.productSKURepo.GetByID(skuID)
+		if err != nil {
+			return nil, err
+		}
+		if sku == nil || sku.ProductID != product.ID {
+			return nil, ErrProductSKUInvalid
+		}
+	}
+
+	saved, err := s.uploadService.SavePrivateFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProductFileUploadFailed, err)
+	}
+	row := &models.ProductFile{
+		ProductID:   product.ID,
+		SKUID:       skuID,
+		Name:        saved.Name,
+		StorageKey:  saved.StorageKey,
+		Size:        saved.Size,
+		ContentType: saved.ContentType,
+		SortOrder:   sortOrder,
+	}
+	if err := s.productFileRepo.Create(row); err != nil {
+		_ = s.uploadService.RemovePrivateFile(saved.StorageKey)
+		return nil, err
+	}
+	return row, nil
+}
+
+// DeleteProductFile 删除商品交付文件；磁盘文件保留，已发放的下载授权仍可下载
+func (s *DownloadService) DeleteProductFile(productID, fileID uint) error {
+	file, err := s.productFileRepo.GetByID(fileID)
+	if err != nil {
+		return err
+	}
+	if file == nil || file.ProductID != productID {
+		return ErrProductFileNotFound
+	}
+	return s.productFileRepo.Delete(fileID)
+}
+
+// ListOrderDownloads 获取订单下载授权与下载日志（后台查看）
+func (s *DownloadService) ListOrderDownloads(orderID uint) ([]models.OrderDownload, []models.OrderDownloadLog, error) {
+	orderIDs, err := s.resolveOrderIDs(orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	downloads := make([]models.OrderDownload, 0)
+	logs := make([]models.OrderDownloadLog, 0)
+	for _, id := range orderIDs {
+		rows, err := s.productFileRepo.ListDownloadsByOrder(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		downloads = append(downloads, rows...)
+		logRows, err := s.productFileRepo.ListDownloadLogsByOrder(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		logs = append(logs, logRows...)
+	}
+	return downloads, logs, nil
+}
+
+// resolveOrderIDs 父订单展开为子订单
+func (s *DownloadService) resolveOrderIDs(orderID uint) ([]uint, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, ErrOrderFetchFailed
+	}
+	if order == nil {
+		return nil, ErrOrderNotFound
+	}
+	ids := []uint{order.ID}
+	for _, child := range order.Children {
+		ids = append(ids, child.ID)
+	}
+	return ids, nil
+}
+
+// ApplyToOrder 为文件交付订单（含子订单）填充带签名的下载地址
+func (s *DownloadService) ApplyToOrder(order *models.Order) {
+	if s == nil || order == nil {
+		return
+	}
+	s.applyDownloads(order)
+	for i := range order.Children {
+		s.applyDownloads(&order.Children[i])
+	}
+}
+
+func (s *DownloadService) applyDownloads(order *models.Order) {
+	if order.Fulfillment == nil || order.Fulfillment.Type != constants.FulfillmentTypeFile {
+		return
+	}
+	if !isDownloadableOrderStatus(order.Status) {
+		return
+	}
+	downloads, err := s.productFileRepo.ListDownloadsByOrder(order.ID)
+	if err != nil {
+		logger.Warnw("download_list_grants_failed", "order_id", order.ID, "error", err)
+		return
+	}
+	expiresAt := time.Now().Add(s.linkTTL())
+	for i := range downloads {
+		exp := expiresAt
+		downloads[i].URL = s.SignURL(downloads[i].ID, exp)
+		downloads[i].URLExpiresAt = &exp
+	}
+	order.Fulfillment.Downloads = downloads
+}
+
+// SignURL 生成下载授权的签名地址（相对路径，由前端拼接站点域名）
+func (s *DownloadService) SignURL(downloadID uint, expiresAt time.Time) string {
+	expires := expiresAt.Unix()
+	return fmt.Sprintf("/api/v1/public/downloads/%d?expires=%d&sig=%s", downloadID, expires, s.sign(downloadID, expires))
+}
+
+// SignOrderMessageAttachmentURL 生成订单消息附件的签名地址，有效期与下载链接一致
+func (s *DownloadService) SignOrderMessageAttachmentURL(messageID uint, index int) string {
+	expires := time.Now().Add(s.linkTTL()).Unix()
+	return fmt.Sprintf("/api/v1/public/order-message-attachments/%d/%d?expires=%d&sig=%s", messageID, index, expires, s.signOrderMessageAttachment(messageID, index, expires))
+}
+
+// VerifyOrderMessageAttachment 校验订单消息附件签名与有效期
+func (s *DownloadService) VerifyOrderMessageAttachment(messageID uint, index int, expires int64, signature string) error {
+	if messageID == 0 || index < 0 || expires <= 0 || strings.TrimSpace(signature) == "" {
+		return ErrDownloadLinkInvalid
+	}
+	expected := s.signOrderMessageAttachment(messageID, index, expires)
+	if !hmac.Equal([]byte(expected), []byte(strings.TrimSpace(signature))) {
+		return ErrDownloadLinkInvalid
+	}
+	if time.Now().Unix() > expires {
+		return ErrDownloadLinkExpired
+	}
+	return nil
+}
+
+func (s *DownloadService) sign(downloadID uint, expires int64) string {
+	return s.signPayload(fmt.Sprintf("%d:%d", downloadID, expires))
+}
+
+func (s *DownloadService) signOrderMessageAttachment(messageID uint, index int, expires int64) string {
+	return s.signPayload(fmt.Sprintf("order_message:%d:%d:%d", messageID, index, expires))
+}
+
+func (s *DownloadService) signPayload(payload string) string {
+	mac := hmac.New(sha256.New, s.signingKey())
+	_, _ = mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// signingKey 优先使用独立配置的 download.signing_secret；未配置时从 user_jwt.secret 按用途标签派生子密钥，
+// 避免下载签名与用户登录令牌直接共用同一把密钥
+func (s *DownloadService) signingKey() []byte {
+	if secret := strings.TrimSpace(s.cfg.Download.SigningSecret); secret != "" {
+		return []byte(secret)
+	}
+	mac := hmac.New(sha256.New, []byte(s.cfg.UserJWT.SecretKey))
+	_, _ = mac.Write([]byte(downloadSigningKeyLabel))
+	return mac.Sum(nil)
+}
+
+func (s *DownloadService) linkTTL() time.Duration {
+	if s.cfg.Download.LinkTTLMinutes > 0 {
+		return time.Duration(s.cfg.Download.LinkTTLMinutes) * time.Minute
+	}
+	return defaultDownloadLinkTTL
+}
+
+// DownloadInput 下载请求
+type DownloadInput struct {
+	DownloadID uint
+	Expires    int64
+	Signature  string
+	ClientIP   string
+	UserAgent  string
+}
+
+// DownloadFile 待输出的下载文件
+type DownloadFile struct {
+	Path string
+	Name string
+}
+
+// Download 校验签名与次数后返回文件路径，并记录下载日志
+func (s *DownloadService) Download(input DownloadInput) (*DownloadFile, error) {
+	if input.DownloadID == 0 || input.Expires <= 0 || strings.TrimSpace(input.Signature) == "" {
+		return nil, ErrDownloadLinkInvalid
+	}
+	expected := s.sign(input.DownloadID, input.Expires)
+	if !hmac.Equal([]byte(expected), []byte(strings.TrimSpace(input.Signature))) {
+		return nil, ErrDownloadLinkInvalid
+	}
+	now := time.Now()
+	if now.Unix() > input.Expires {
+		return nil, ErrDownloadLinkExpired
+	}
+
+	download, err := s.productFileRepo.GetDownloadByID(input.DownloadID)
+	if err != nil {
+		return nil, err
+	}
+	if download == nil {
+		return nil, ErrDownloadLinkInvalid
+	}
+	order, err := s.orderRepo.GetByID(download.OrderID)
+	if err != nil {
+		return nil, ErrOrderFetchFailed
+	}
+	if order == nil || !isDownloadableOrderStatus(order.Status) {
+		return nil, ErrDownloadUnavailable
+	}
+	file, err := s.productFileRepo.GetByIDUnscoped(download.ProductFileID)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return nil, ErrDownloadUnavailable
+	}
+	path, err := s.uploadService.ResolvePrivatePath(file.StorageKey)
+	if err != nil {
+		return nil, ErrDownloadUnavailable
+	}
+	if _, err := os.Stat(path); err != nil {
+		logger.Warnw("download_file_stat_failed", "download_id", download.ID, "file_id", file.ID, "error", err)
+		return nil, ErrDownloadUnavailable
+	}
+
+	ok, err := s.productFileRepo.ConsumeDownload(download.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrDownloadLimitExceeded
+	}
+	if err := s.productFileRepo.CreateDownloadLog(&models.OrderDownloadLog{
+		OrderDownloadID: download.ID,
+		OrderID:         download.OrderID,
+		ProductFileID:   download.ProductFileID,
+		ClientIP:        truncateDownloadLogField(input.ClientIP, 64),
+		UserAgent:       truncateDownloadLogField(input.UserAgent, 512),
+		CreatedAt:       now,
+	}); err != nil {
+		logger.Warnw("download_log_create_failed", "download_id", download.ID, "error", err)
+	}
+
+	name := download.FileName
+	if name == "" {
+		name = file.Name
+	}
+	return &DownloadFile{Path: path, Name: name}, nil
+}
+
+// isDownloadableOrderStatus 仅已交付/已完成订单可下载，退款或取消后链接失效
+func isDownloadableOrderStatus(status string) bool {
+	switch status {
+	case constants.OrderStatusDelivered, constants.OrderStatusCompleted:
+		return true
+	default:
+		return false
+	}
+}
+
+func truncateDownloadLogField(value string, limit int) string {
+	value = strings.TrimSpace(value)
+	runes := []rune(value)
+	if len(runes) > limit {
+		return string(runes[:limit])
+	}
+	return value
+}
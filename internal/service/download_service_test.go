@@ -0,0 +1,181 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dujiao-next/internal/config"
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestFileFulfillmentAndSignedDownload(t *testing.T) {
+	db := setupFulfillmentServiceTestDB(t)
+	if err := db.AutoMigrate(&models.ProductFile{}, &models.OrderDownload{}, &models.OrderDownloadLog{}); err != nil {
+		t.Fatalf("auto migrate download tables failed: %v", err)
+	}
+	storageDir := t.TempDir()
+	cfg := &config.Config{
+		UserJWT:  config.JWTConfig{SecretKey: "user-jwt-secret"},
+		Download: config.DownloadConfig{StorageDir: storageDir, LinkTTLMinutes: 10, MaxDownloads: 1},
+	}
+	if err := os.MkdirAll(filepath.Join(storageDir, "2026", "01"), 0755); err != nil {
+		t.Fatalf("mkdir storage failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(storageDir, "2026", "01", "shared.zip"), []byte("shared"), 0644); err != nil {
+		t.Fatalf("write file failed: %v", err)
+	}
+
+	files := []models.ProductFile{
+		{ProductID: 200, SKUID: 0, Name: "manual.pdf", StorageKey: "2026/01/shared.zip", Size: 6},
+		{ProductID: 200, SKUID: 2001, Name: "app-pro.zip", StorageKey: "2026/01/shared.zip", Size: 6},
+		{ProductID: 200, SKUID: 2002, Name: "app-lite.zip", StorageKey: "2026/01/shared.zip", Size: 6},
+	}
+	if err := db.Create(&files).Error; err != nil {
+		t.Fatalf("create product files failed: %v", err)
+	}
+
+	now := time.Now()
+	order := &models.Order{
+		OrderNo:                 "FILE-001",
+		UserID:                  1,
+		Status:                  constants.OrderStatusPaid,
+		Currency:                "CNY",
+		OriginalAmount:          models.NewMoneyFromDecimal(decimal.NewFromInt(10)),
+		DiscountAmount:          models.NewMoneyFromDecimal(decimal.Zero),
+		PromotionDiscountAmount: models.NewMoneyFromDecimal(decimal.Zero),
+		TotalAmount:             models.NewMoneyFromDecimal(decimal.NewFromInt(10)),
+		WalletPaidAmount:        models.NewMoneyFromDecimal(decimal.Zero),
+		OnlinePaidAmount:        models.NewMoneyFromDecimal(decimal.NewFromInt(10)),
+		RefundedAmount:          models.NewMoneyFromDecimal(decimal.Zero),
+		CreatedAt:               now,
+		UpdatedAt:               now,
+	}
+	if err := db.Create(order).Error; err != nil {
+		t.Fatalf("create order failed: %v", err)
+	}
+	if err := db.Create(&models.OrderItem{
+		OrderID:         order.ID,
+		ProductID:       200,
+		SKUID:           2001,
+		TitleJSON:       models.JSON{"zh-CN": "软件授权"},
+		UnitPrice:       models.NewMoneyFromDecimal(decimal.NewFromInt(10)),
+		Quantity:        1,
+		TotalPrice:      models.NewMoneyFromDecimal(decimal.NewFromInt(10)),
+		FulfillmentType: constants.FulfillmentTypeFile,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}).Error; err != nil {
+		t.Fatalf("create order item failed: %v", err)
+	}
+
+	orderRepo := repository.NewOrderRepository(db)
+	fulfillmentSvc := NewFulfillmentService(orderRepo, repository.NewFulfillmentRepository(db), repository.NewCardSecretRepository(db), nil)
+	fulfillmentSvc.SetDownloadConfig(cfg.Download)
+	fulfillment, err := fulfillmentSvc.CreateAuto(order.ID)
+	if err != nil {
+		t.Fatalf("create file fulfillment failed: %v", err)
+	}
+	if fulfillment.Type != constants.FulfillmentTypeFile || fulfillment.Payload != "manual.pdf\napp-pro.zip" {
+		t.Fatalf("unexpected file fulfillment: %+v", fulfillment)
+	}
+
+	svc := NewDownloadService(cfg, orderRepo, nil, nil, repository.NewProductFileRepository(db), NewUploadService(cfg))
+	full, err := orderRepo.GetByID(order.ID)
+	if err != nil || full == nil {
+		t.Fatalf("reload order failed: %v", err)
+	}
+	if full.Status != constants.OrderStatusCompleted {
+		t.Fatalf("order status want completed got %s", full.Status)
+	}
+	svc.ApplyToOrder(full)
+	if len(full.Fulfillment.Downloads) != 2 {
+		t.Fatalf("expected 2 download grants, got %d", len(full.Fulfillment.Downloads))
+	}
+	grant := full.Fulfillment.Downloads[0]
+	parsed, err := url.Parse(grant.URL)
+	if err != nil || !strings.HasPrefix(parsed.Path, "/api/v1/public/downloads/") {
+		t.Fatalf("unexpected signed url: %s", grant.URL)
+	}
+	expires, _ := strconv.ParseInt(parsed.Query().Get("expires"), 10, 64)
+	input := DownloadInput{
+		DownloadID: grant.ID,
+		Expires:    expires,
+		Signature:  parsed.Query().Get("sig"),
+		ClientIP:   "203.0.113.9",
+		UserAgent:  "curl/8.0",
+	}
+
+	tampered := input
+	tampered.Expires = expires + 3600
+	if _, err := svc.Download(tampered); !errors.Is(err, ErrDownloadLinkInvalid) {
+		t.Fatalf("tampered link should be rejected, got %v", err)
+	}
+	expired := input
+	expired.Expires = now.Add(-time.Minute).Unix()
+	expired.Signature = svc.sign(grant.ID, expired.Expires)
+	if _, err := svc.Download(expired); !errors.Is(err, ErrDownloadLinkExpired) {
+		t.Fatalf("expired link should be rejected, got %v", err)
+	}
+
+	file, err := svc.Download(input)
+	if err != nil {
+		t.Fatalf("download failed: %v", err)
+	}
+	if file.Name != grant.FileName || !strings.HasPrefix(file.Path, storageDir) {
+		t.Fatalf("unexpected download file: %+v", file)
+	}
+	if _, err := svc.Download(input); !errors.Is(err, ErrDownloadLimitExceeded) {
+		t.Fatalf("second download should exceed limit, got %v", err)
+	}
+
+	var logs []models.OrderDownloadLog
+	if err := db.Find(&logs).Error; err != nil {
+		t.Fatalf("query download logs failed: %v", err)
+	}
+	if len(logs) != 1 || logs[0].ClientIP != "203.0.113.9" || logs[0].UserAgent != "curl/8.0" {
+		t.Fatalf("unexpected download logs: %+v", logs)
+	}
+}
+
+func TestResolvePrivatePathRejectsTraversal(t *testing.T) {
+	svc := NewUploadService(&config.Config{Download: config.DownloadConfig{StorageDir: t.TempDir()}})
+	for _, key := range []string{"../secret", "/etc/passwd", "2026/../../x", ""} {
+		if _, err := svc.ResolvePrivatePath(key); err == nil {
+			t.Fatalf("expected traversal key %q to be rejected", key)
+		}
+	}
+	if _, err := svc.ResolvePrivatePath("2026/01/a.zip"); err != nil {
+		t.Fatalf("valid key rejected: %v", err)
+	}
+}
+
+func TestDownloadSigningKeyIsolatedFromJWTSecret(t *testing.T) {
+	jwtSecret := "user-jwt-secret"
+	derived := NewDownloadService(&config.Config{UserJWT: config.JWTConfig{SecretKey: jwtSecret}}, nil, nil, nil, nil, nil)
+	mac := hmac.New(sha256.New, []byte(jwtSecret))
+	_, _ = mac.Write([]byte("1:100"))
+	if derived.sign(1, 100) == base64.RawURLEncoding.EncodeToString(mac.Sum(nil)) {
+		t.Fatalf("expected download signature not to be keyed by the raw jwt secret")
+	}
+
+	dedicated := NewDownloadService(&config.Config{
+		UserJWT:  config.JWTConfig{SecretKey: jwtSecret},
+		Download: config.DownloadConfig{SigningSecret: "download-secret"},
+	}, nil, nil, nil, nil, nil)
+	if dedicated.sign(1, 100) == derived.sign(1, 100) {
+		t.Fatalf("expected dedicated signing secret to take precedence")
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"bytes"
 	"crypto/tls"
+	"encoding/base64"
 	"fmt"
 	"mime"
 	"net/mail"
@@ -13,6 +14,8 @@ import (
 	"github.com/dujiao-next/internal/constants"
 	"github.com/dujiao-next/internal/i18n"
 	"github.com/dujiao-next/internal/models"
+
+	"github.com/google/uuid"
 )
 
 // EmailService 邮件发送服务
@@ -49,10 +52,17 @@ type OrderStatusEmailInput struct {
 	IsGuest         bool
 }
 
+// EmailAttachment 邮件附件
+type EmailAttachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
 // SendOrderStatusEmail 发送订单状态通知
-func (s *EmailService) SendOrderStatusEmail(toEmail string, input OrderStatusEmailInput, locale string) error {
+func (s *EmailService) SendOrderStatusEmail(toEmail string, input OrderStatusEmailInput, locale string, attachments ...EmailAttachment) error {
 	subject, body := buildOrderStatusContent(input, locale)
-	return s.sendTextEmail(toEmail, subject, body)
+	return s.sendEmail(toEmail, subject, body, attachments)
 }
 
 // SendCustomEmail 发送测试邮件或自定义邮件
@@ -69,6 +79,10 @@ func (s *EmailService) SendCustomEmail(toEmail, subject, body string) error {
 }
 
 func (s *EmailService) sendTextEmail(toEmail, subject, body string) error {
+	return s.sendEmail(toEmail, subject, body, nil)
+}
+
+func (s *EmailService) sendEmail(toEmail, subject, body string, attachments []EmailAttachment) error {
 	if s.cfg == nil || !s.cfg.Enabled {
 		return ErrEmailServiceDisabled
 	}
@@ -81,6 +95,9 @@ func (s *EmailService) sendTextEmail(toEmail, subject, body string) error {
 
 	from := buildFromAddress(s.cfg.From, s.cfg.FromName)
 	msg := buildEmailMessage(from, toEmail, subject, body)
+	if len(attachments) > 0 {
+		msg = buildMultipartEmailMessage(from, toEmail, subject, body, attachments)
+	}
 
 	addr := fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port)
 	var auth smtp.Auth
@@ -165,7 +182,7 @@ func buildOrderStatusContent(input OrderStatusEmailInput, locale string) (string
 	payload := strings.TrimSpace(input.FulfillmentInfo)
 	status := strings.ToLower(strings.TrimSpace(input.Status))
 	switch status {
-	case constants.OrderStatusDelivered, constants.OrderStatusCompleted:
+	case constants.OrderStatusDelivered, constants.OrderStatusPartiallyDelivered, constants.OrderStatusCompleted:
 		if payload != "" {
 			body := i18n.Sprintf(normalized, "email.order_status.body_delivered", input.OrderNo, statusLabel, amount, currency, payload)
 			return subject, appendGuestTip(normalized, input, body)
@@ -228,6 +245,46 @@ func buildEmailMessage(from, to, subject, body string) string {
 	return buf.String()
 }
 
+func buildMultipartEmailMessage(from, to, subject, body string, attachments []EmailAttachment) string {
+	boundary := "dj-" + strings.ReplaceAll(uuid.New().String(), "-", "")
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("From: %s\r\n", from))
+	buf.WriteString(fmt.Sprintf("To: %s\r\n", to))
+	buf.WriteString(fmt.Sprintf("Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", subject)))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString(fmt.Sprintf("Content-Type: multipart/mixed; boundary=%q\r\n", boundary))
+	buf.WriteString("\r\n")
+
+	buf.WriteString("--" + boundary + "\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(body)
+	buf.WriteString("\r\n")
+
+	for _, attachment := range attachments {
+		contentType := strings.TrimSpace(attachment.ContentType)
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		filename := mime.QEncoding.Encode("UTF-8", attachment.Filename)
+		buf.WriteString("--" + boundary + "\r\n")
+		buf.WriteString(fmt.Sprintf("Content-Type: %s; name=%q\r\n", contentType, filename))
+		buf.WriteString("Content-Transfer-Encoding: base64\r\n")
+		buf.WriteString(fmt.Sprintf("Content-Disposition: attachment; filename=%q\r\n", filename))
+		buf.WriteString("\r\n")
+		encoded := base64.StdEncoding.EncodeToString(attachment.Content)
+		for len(encoded) > 76 {
+			buf.WriteString(encoded[:76] + "\r\n")
+			encoded = encoded[76:]
+		}
+		if encoded != "" {
+			buf.WriteString(encoded + "\r\n")
+		}
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+	return buf.String()
+}
+
 func sendMailWithSSL(addr string, auth smtp.Auth, host, from string, to []string, msg []byte) error {
 	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: host})
 	if err != nil {
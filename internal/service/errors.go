@@ -50,14 +50,21 @@ var (
 	ErrCouponPerUserLimit              = errors.New("coupon per user limit")
 	ErrCouponMinAmount                 = errors.New("coupon min amount")
 	ErrCouponScopeInvalid              = errors.New("coupon scope invalid")
+	ErrCouponUserNotEligible           = errors.New("coupon user not eligible")
+	ErrCouponFirstOrderOnly            = errors.New("coupon first order only")
 	ErrCouponUpdateFailed              = errors.New("coupon update failed")
 	ErrCouponDeleteFailed              = errors.New("coupon delete failed")
+	ErrCouponBatchNotFound             = errors.New("coupon batch not found")
+	ErrCouponBatchCreateFailed         = errors.New("coupon batch create failed")
 	ErrPromotionInvalid                = errors.New("promotion invalid")
 	ErrPromotionNotFound               = errors.New("promotion not found")
 	ErrPromotionUpdateFailed           = errors.New("promotion update failed")
 	ErrPromotionDeleteFailed           = errors.New("promotion delete failed")
 	ErrProductPriceInvalid             = errors.New("product price invalid")
 	ErrProductPurchaseInvalid          = errors.New("product purchase invalid")
+	ErrProductVisibilityInvalid        = errors.New("product visibility invalid")
+	ErrProductAccessCodeRequired       = errors.New("product access code required")
+	ErrProductAccessCodeInvalid        = errors.New("product access code invalid")
 	ErrManualStockInvalid              = errors.New("manual stock invalid")
 	ErrManualStockInsufficient         = errors.New("manual stock insufficient")
 	ErrManualFormSchemaInvalid         = errors.New("manual form schema invalid")
@@ -86,6 +93,7 @@ var (
 	ErrGuestCouponNotAllowed           = errors.New("guest coupon not allowed")
 	ErrFulfillmentInvalid              = errors.New("fulfillment invalid")
 	ErrFulfillmentExists               = errors.New("fulfillment exists")
+	ErrFulfillmentQuantityInvalid      = errors.New("fulfillment quantity invalid")
 	ErrFulfillmentCreateFailed         = errors.New("fulfillment create failed")
 	ErrPaymentInvalid                  = errors.New("payment invalid")
 	ErrPaymentNotFound                 = errors.New("payment not found")
@@ -121,6 +129,9 @@ var (
 	ErrCardSecretBatchFetchFailed      = errors.New("card secret batch fetch failed")
 	ErrCardSecretImportFailed          = errors.New("card secret import failed")
 	ErrCardSecretStatsFailed           = errors.New("card secret stats failed")
+	ErrCardSecretSchemaInvalid         = errors.New("card secret field schema invalid")
+	ErrCardSecretDuplicate             = errors.New("card secret duplicate")
+	ErrDeliveryTemplateInvalid         = errors.New("delivery template invalid")
 	ErrGiftCardInvalid                 = errors.New("gift card invalid")
 	ErrGiftCardNotFound                = errors.New("gift card not found")
 	ErrGiftCardExpired                 = errors.New("gift card expired")
@@ -146,4 +157,53 @@ var (
 	ErrNotificationConfigInvalid       = errors.New("notification config invalid")
 	ErrNotificationSendFailed          = errors.New("notification send failed")
 	ErrNotificationEventInvalid        = errors.New("notification event invalid")
+	ErrReceiptConfigInvalid            = errors.New("receipt config invalid")
+	ErrReceiptDisabled                 = errors.New("receipt disabled")
+	ErrReceiptNotAvailable             = errors.New("receipt not available")
+	ErrReceiptGenerateFailed           = errors.New("receipt generate failed")
+	ErrReceiptFontMissing              = errors.New("receipt font not configured")
+	ErrOrderMessageNotAllowed          = errors.New("order message not allowed")
+	ErrOrderMessageClosed              = errors.New("order message closed")
+	ErrOrderMessageInvalid             = errors.New("order message invalid")
+	ErrOrderMessageAttachmentFailed    = errors.New("order message attachment failed")
+	ErrOrderMessageCreateFailed        = errors.New("order message create failed")
+	ErrOrderMessageFetchFailed         = errors.New("order message fetch failed")
+	ErrFulfillmentNotAPI               = errors.New("fulfillment not api")
+	ErrSupplierInvalid                 = errors.New("supplier invalid")
+	ErrSupplierInUse                   = errors.New("supplier in use")
+	ErrSupplierUnavailable             = errors.New("supplier unavailable")
+	ErrSupplierRequestFailed           = errors.New("supplier request failed")
+	ErrProductFileMissing              = errors.New("product file missing")
+	ErrProductFileNotFound             = errors.New("product file not found")
+	ErrProductFileUploadFailed         = errors.New("product file upload failed")
+	ErrDownloadLinkInvalid             = errors.New("download link invalid")
+	ErrDownloadLinkExpired             = errors.New("download link expired")
+	ErrDownloadLimitExceeded           = errors.New("download limit exceeded")
+	ErrDownloadUnavailable             = errors.New("download unavailable")
+	ErrKeyGeneratorInvalid             = errors.New("key generator invalid")
+	ErrKeyGeneratorUnavailable         = errors.New("key generator unavailable")
+	ErrSecretRevealConfigInvalid       = errors.New("secret reveal config invalid")
+	ErrSecretRevealUnavailable         = errors.New("secret reveal unavailable")
+	ErrSecretRevealLimitExceeded       = errors.New("secret reveal limit exceeded")
+	ErrProductSpecSchemaInvalid        = errors.New("product spec schema invalid")
+	ErrProductSKUSpecInvalid           = errors.New("product sku spec invalid")
+	ErrCategoryHasChildren             = errors.New("category has children")
+	ErrCategoryParentInvalid           = errors.New("category parent invalid")
+	ErrCategoryReassignInvalid         = errors.New("category reassign target invalid")
+	ErrCategoryReorderInvalid          = errors.New("category reorder invalid")
+	ErrProductTransferFormatInvalid    = errors.New("product transfer format invalid")
+	ErrProductImportInvalid            = errors.New("product import invalid")
+	ErrProductPriceTierInvalid         = errors.New("product price tier invalid")
+	ErrMemberLevelInvalid              = errors.New("member level invalid")
+	ErrMemberLevelNotFound             = errors.New("member level not found")
+	ErrMemberLevelCodeExists           = errors.New("member level code exists")
+	ErrProductReviewInvalid            = errors.New("product review invalid")
+	ErrProductReviewNotFound           = errors.New("product review not found")
+	ErrProductReviewNotAllowed         = errors.New("product review not allowed")
+	ErrProductReviewExists             = errors.New("product review exists")
+	ErrProductScheduleInvalid          = errors.New("product schedule invalid")
+	ErrProductRevisionNotFound         = errors.New("product revision not found")
+	ErrStockSubscriptionInvalid        = errors.New("stock subscription invalid")
+	ErrStockSubscriptionNotSoldOut     = errors.New("stock subscription sku not sold out")
+	ErrStockSubscriptionNotFound       = errors.New("stock subscription not found")
 )
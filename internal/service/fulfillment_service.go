@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/dujiao-next/internal/config"
 	"github.com/dujiao-next/internal/constants"
 	"github.com/dujiao-next/internal/logger"
 	"github.com/dujiao-next/internal/models"
@@ -22,6 +23,8 @@ type FulfillmentService struct {
 	fulfillmentRepo repository.FulfillmentRepository
 	secretRepo      repository.CardSecretRepository
 	queueClient     *queue.Client
+	downloadCfg     config.DownloadConfig
+	licenseService  *LicenseService
 }
 
 // NewFulfillmentService 创建交付服务
@@ -34,20 +37,34 @@ func NewFulfillmentService(orderRepo repository.OrderRepository, fulfillmentRepo
 	}
 }
 
+// SetDownloadConfig 设置文件交付下载配置
+func (s *FulfillmentService) SetDownloadConfig(cfg config.DownloadConfig) {
+	s.downloadCfg = cfg
+}
+
+// SetLicenseService 设置授权码签发服务（生成器为 license 类型时使用）
+func (s *FulfillmentService) SetLicenseService(licenseService *LicenseService) {
+	s.licenseService = licenseService
+}
+
 // CreateManualInput 创建人工交付输入
 type CreateManualInput struct {
 	OrderID      uint
 	AdminID      uint
+	Quantity     int // 本批交付数量，0 表示交付剩余全部
 	Payload      string
 	DeliveryData models.JSON
 	DeliveredAt  *time.Time
 }
 
-// CreateManual 创建人工交付
+// CreateManual 创建人工交付，多数量订单可分批交付，交付完最后一批后订单转为已交付
 func (s *FulfillmentService) CreateManual(input CreateManualInput) (*models.Fulfillment, error) {
 	if input.OrderID == 0 || input.AdminID == 0 {
 		return nil, ErrFulfillmentInvalid
 	}
+	if input.Quantity < 0 {
+		return nil, ErrFulfillmentQuantityInvalid
+	}
 	payload := strings.TrimSpace(input.Payload)
 	deliveryData := normalizeManualDeliveryData(input.DeliveryData)
 	if payload == "" && len(deliveryData) == 0 {
@@ -68,9 +85,12 @@ func (s *FulfillmentService) CreateManual(input CreateManualInput) (*models.Fulf
 	if order.ParentID == nil && len(order.Children) > 0 {
 		return nil, ErrFulfillmentInvalid
 	}
-	if order.Status != constants.OrderStatusPaid && order.Status != constants.OrderStatusFulfilling {
+	switch order.Status {
+	case constants.OrderStatusPaid, constants.OrderStatusFulfilling, constants.OrderStatusPartiallyDelivered:
+	default:
 		return nil, ErrOrderStatusInvalid
 	}
+	totalQty := orderDeliveryQuantity(order)
 
 	now := time.Now()
 	deliveredAt := input.DeliveredAt
@@ -78,83 +98,131 @@ func (s *FulfillmentService) CreateManual(input CreateManualInput) (*models.Fulf
 		deliveredAt = &now
 	}
 
-	var created *models.Fulfillment
+	var saved *models.Fulfillment
+	targetStatus := constants.OrderStatusDelivered
 	err = s.orderRepo.Transaction(func(tx *gorm.DB) error {
-		var existing models.Fulfillment
-		if err := tx.Where("order_id = ?", input.OrderID).First(&existing).Error; err == nil {
+		var fulfillment models.Fulfillment
+		found := true
+		if err := tx.Where("order_id = ?", input.OrderID).First(&fulfillment).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			found = false
+		}
+		if found && fulfillment.Status != constants.FulfillmentStatusPartial {
 			return ErrFulfillmentExists
-		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-			return err
 		}
 
-		fulfillment := &models.Fulfillment{
+		deliveredQty := fulfillment.DeliveredQty
+		remaining := totalQty - deliveredQty
+		quantity := input.Quantity
+		if quantity == 0 {
+			quantity = remaining
+		}
+		if quantity <= 0 || quantity > remaining {
+			return ErrFulfillmentQuantityInvalid
+		}
+		fulfillmentStatus := constants.FulfillmentStatusDelivered
+		if deliveredQty+quantity < totalQty {
+			fulfillmentStatus = constants.FulfillmentStatusPartial
+			targetStatus = constants.OrderStatusPartiallyDelivered
+		}
+
+		if !found {
+			fulfillment = models.Fulfillment{
+				OrderID:       input.OrderID,
+				Type:          ftype,
+				Status:        fulfillmentStatus,
+				Payload:       payload,
+				LogisticsJSON: deliveryData,
+				TotalQty:      totalQty,
+				DeliveredQty:  quantity,
+				DeliveredBy:   &input.AdminID,
+				DeliveredAt:   deliveredAt,
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			}
+			if err := tx.Create(&fulfillment).Error; err != nil {
+				return ErrFulfillmentCreateFailed
+			}
+		} else {
+			fulfillment.Status = fulfillmentStatus
+			fulfillment.Payload = strings.TrimSpace(fulfillment.Payload + "\n" + payload)
+			if len(deliveryData) > 0 {
+				fulfillment.LogisticsJSON = deliveryData
+			}
+			fulfillment.TotalQty = totalQty
+			fulfillment.DeliveredQty = deliveredQty + quantity
+			fulfillment.DeliveredBy = &input.AdminID
+			fulfillment.DeliveredAt = deliveredAt
+			fulfillment.UpdatedAt = now
+			// 以已交付数量做乐观锁，避免并发录入超量交付
+			result := tx.Model(&fulfillment).
+				Where("delivered_qty = ?", deliveredQty).
+				Select("status", "payload", "payload_key_id", "logistics_json", "total_qty", "delivered_qty", "delivered_by", "delivered_at", "updated_at").
+				Updates(&fulfillment)
+			if result.Error != nil {
+				return ErrFulfillmentCreateFailed
+			}
+			if result.RowsAffected == 0 {
+				return ErrFulfillmentQuantityInvalid
+			}
+		}
+
+		batch := &models.FulfillmentBatch{
+			FulfillmentID: fulfillment.ID,
 			OrderID:       input.OrderID,
-			Type:          ftype,
-			Status:        constants.FulfillmentStatusDelivered,
+			Quantity:      quantity,
 			Payload:       payload,
 			LogisticsJSON: deliveryData,
 			DeliveredBy:   &input.AdminID,
-			DeliveredAt:   deliveredAt,
+			DeliveredAt:   *deliveredAt,
 			CreatedAt:     now,
 			UpdatedAt:     now,
 		}
-
-		if err := tx.Create(fulfillment).Error; err != nil {
+		if err := tx.Create(batch).Error; err != nil {
 			return ErrFulfillmentCreateFailed
 		}
+		if err := tx.Where("fulfillment_id = ?", fulfillment.ID).Order("id asc").Find(&fulfillment.Batches).Error; err != nil {
+			return err
+		}
+
 		if err := tx.Model(&models.Order{}).Where("id = ?", order.ID).Updates(map[string]interface{}{
-			"status":     constants.OrderStatusDelivered,
+			"status":     targetStatus,
 			"updated_at": now,
 		}).Error; err != nil {
 			return ErrOrderUpdateFailed
 		}
-		created = fulfillment
+		saved = &fulfillment
 		return nil
 	})
 	if err != nil {
-		if errors.Is(err, ErrFulfillmentExists) {
+		switch {
+		case errors.Is(err, ErrFulfillmentExists):
 			return nil, ErrFulfillmentExists
-		}
-		if errors.Is(err, ErrOrderUpdateFailed) {
+		case errors.Is(err, ErrFulfillmentQuantityInvalid):
+			return nil, ErrFulfillmentQuantityInvalid
+		case errors.Is(err, ErrOrderUpdateFailed):
 			return nil, ErrOrderUpdateFailed
 		}
 		return nil, ErrFulfillmentCreateFailed
 	}
-	if s.queueClient != nil {
-		if order.ParentID != nil {
-			status, syncErr := syncParentStatus(s.orderRepo, *order.ParentID, now)
-			if syncErr != nil {
-				logger.Warnw("fulfillment_sync_parent_status_failed",
-					"order_id", order.ID,
-					"parent_order_id", *order.ParentID,
-					"target_status", constants.OrderStatusDelivered,
-					"error", syncErr,
-				)
-			} else {
-				if status == "" {
-					status = constants.OrderStatusDelivered
-				}
-				if _, err := enqueueOrderStatusEmailTaskIfEligible(s.orderRepo, s.queueClient, *order.ParentID, status); err != nil {
-					logger.Warnw("fulfillment_enqueue_status_email_failed",
-						"order_id", order.ID,
-						"target_order_id", *order.ParentID,
-						"status", status,
-						"error", err,
-					)
-				}
-			}
-		} else {
-			if _, err := enqueueOrderStatusEmailTaskIfEligible(s.orderRepo, s.queueClient, input.OrderID, constants.OrderStatusDelivered); err != nil {
-				logger.Warnw("fulfillment_enqueue_status_email_failed",
-					"order_id", order.ID,
-					"target_order_id", input.OrderID,
-					"status", constants.OrderStatusDelivered,
-					"error", err,
-				)
-			}
+	s.afterDelivered(order, targetStatus, now)
+	return saved, nil
+}
+
+// orderDeliveryQuantity 订单应交付的总件数
+func orderDeliveryQuantity(order *models.Order) int {
+	total := 0
+	for _, item := range order.Items {
+		if item.Quantity > 0 {
+			total += item.Quantity
 		}
 	}
-	return created, nil
+	if total <= 0 {
+		return 1
+	}
+	return total
 }
 
 // CreateAuto 自动交付
@@ -179,6 +247,9 @@ func (s *FulfillmentService) CreateAuto(orderID uint) (*models.Fulfillment, erro
 	if len(order.Items) == 0 {
 		return nil, ErrFulfillmentInvalid
 	}
+	if isFileFulfillOrder(order) {
+		return s.createFile(order)
+	}
 
 	for _, item := range order.Items {
 		if strings.TrimSpace(item.FulfillmentType) != constants.FulfillmentTypeAuto {
@@ -210,10 +281,19 @@ func (s *FulfillmentService) CreateAuto(orderID uint) (*models.Fulfillment, erro
 			reservedByKey[key] = append(reservedByKey[key], reserved)
 		}
 		var secrets []models.CardSecret
+		groups := make([][]models.CardSecret, 0, len(order.Items))
 		for _, item := range order.Items {
 			if item.ProductID == 0 || item.Quantity <= 0 {
 				return ErrFulfillmentInvalid
 			}
+			generated, err := s.generateCardSecrets(tx, order, item, now)
+			if err != nil {
+				return err
+			}
+			if generated != nil {
+				groups = append(groups, generated)
+				continue
+			}
 			key := buildOrderItemKey(item.ProductID, item.SKUID)
 			cachedReserved := reservedByKey[key]
 			selected := make([]models.CardSecret, 0, item.Quantity)
@@ -242,13 +322,12 @@ func (s *FulfillmentService) CreateAuto(orderID uint) (*models.Fulfillment, erro
 				return ErrCardSecretInsufficient
 			}
 			secrets = append(secrets, selected...)
+			groups = append(groups, selected)
 		}
 
 		ids := make([]uint, 0, len(secrets))
-		secretLines := make([]string, 0, len(secrets))
 		for _, secret := range secrets {
 			ids = append(ids, secret.ID)
-			secretLines = append(secretLines, secret.Secret)
 		}
 
 		affected, err := secretRepo.MarkUsed(ids, orderID, now)
@@ -259,15 +338,20 @@ func (s *FulfillmentService) CreateAuto(orderID uint) (*models.Fulfillment, erro
 			return ErrCardSecretInsufficient
 		}
 
-		payload := strings.Join(secretLines, "\n")
+		payload, err := buildCardSecretDeliveryPayload(tx, order, groups)
+		if err != nil {
+			return err
+		}
 		fulfillment = &models.Fulfillment{
-			OrderID:     orderID,
-			Type:        constants.FulfillmentTypeAuto,
-			Status:      constants.FulfillmentStatusDelivered,
-			Payload:     payload,
-			DeliveredAt: &now,
-			CreatedAt:   now,
-			UpdatedAt:   now,
+			OrderID:      orderID,
+			Type:         constants.FulfillmentTypeAuto,
+			Status:       constants.FulfillmentStatusDelivered,
+			Payload:      payload,
+			TotalQty:     orderDeliveryQuantity(order),
+			DeliveredQty: orderDeliveryQuantity(order),
+			DeliveredAt:  &now,
+			CreatedAt:    now,
+			UpdatedAt:    now,
 		}
 		if err := tx.Create(fulfillment).Error; err != nil {
 			return ErrFulfillmentCreateFailed
@@ -290,47 +374,391 @@ func (s *FulfillmentService) CreateAuto(orderID uint) (*models.Fulfillment, erro
 			return nil, ErrOrderUpdateFailed
 		case errors.Is(err, ErrFulfillmentNotAuto):
 			return nil, ErrFulfillmentNotAuto
+		case errors.Is(err, ErrKeyGeneratorUnavailable):
+			return nil, ErrKeyGeneratorUnavailable
 		default:
 			return nil, ErrFulfillmentCreateFailed
 		}
 	}
-	if s.queueClient != nil {
-		if order.ParentID != nil {
-			status, syncErr := syncParentStatus(s.orderRepo, *order.ParentID, now)
-			if syncErr != nil {
-				logger.Warnw("fulfillment_sync_parent_status_failed",
-					"order_id", order.ID,
-					"parent_order_id", *order.ParentID,
-					"target_status", constants.OrderStatusCompleted,
-					"error", syncErr,
-				)
-			} else {
-				if status == "" {
-					status = constants.OrderStatusCompleted
+	s.afterDelivered(order, constants.OrderStatusCompleted, now)
+	return fulfillment, nil
+}
+
+// generateCardSecrets 商品配置了生成器时按需生成卡密，以已使用状态落库留档；未配置时返回 nil
+func (s *FulfillmentService) generateCardSecrets(tx *gorm.DB, order *models.Order, item models.OrderItem, now time.Time) ([]models.CardSecret, error) {
+	var product models.Product
+	if err := tx.Select("id", "fulfillment_type", "key_generator_json").First(&product, item.ProductID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	cfg, err := parseKeyGenerator(product.KeyGeneratorJSON)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, nil
+	}
+	if cfg.Type == constants.KeyGeneratorLicense && !s.licenseService.Enabled() {
+		return nil, ErrKeyGeneratorUnavailable
+	}
+
+	batch := &models.CardSecretBatch{
+		ProductID:  item.ProductID,
+		SKUID:      item.SKUID,
+		BatchNo:    fmt.Sprintf("GEN-%s-%d", order.OrderNo, item.SKUID),
+		Source:     constants.CardSecretSourceGen,
+		TotalCount: item.Quantity,
+		Note:       order.OrderNo,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := tx.Create(batch).Error; err != nil {
+		return nil, err
+	}
+	orderID := order.ID
+	rows := make([]models.CardSecret, 0, item.Quantity)
+	for len(rows) < item.Quantity {
+		value, err := s.generateUniqueKey(tx, cfg, order, item, now)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, models.CardSecret{
+			ProductID: item.ProductID,
+			SKUID:     item.SKUID,
+			BatchID:   &batch.ID,
+			Secret:    value,
+			Status:    models.CardSecretStatusUsed,
+			OrderID:   &orderID,
+			UsedAt:    &now,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// generateUniqueKey 生成同商品下不重复的卡密，重试有限次数
+func (s *FulfillmentService) generateUniqueKey(tx *gorm.DB, cfg *keyGeneratorConfig, order *models.Order, item models.OrderItem, now time.Time) (string, error) {
+	for attempt := 0; attempt < keyGenerateMaxAttempts; attempt++ {
+		var value string
+		var err error
+		if cfg.Type == constants.KeyGeneratorLicense {
+			value, err = s.licenseService.Issue(order, item, cfg.ValidDays, now)
+		} else {
+			value, err = generatePatternKey(cfg.Pattern)
+		}
+		if err != nil {
+			return "", err
+		}
+		var count int64
+		if err := tx.Model(&models.CardSecret{}).
+			Where("product_id = ? AND secret_hash IN ?", item.ProductID, models.CardSecretLookupHashes(value, nil)).
+			Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return value, nil
+		}
+	}
+	return "", ErrCardSecretInsufficient
+}
+
+// createFile 文件交付：为订单内每个商品文件发放下载授权，买家凭签名链接下载
+func (s *FulfillmentService) createFile(order *models.Order) (*models.Fulfillment, error) {
+	now := time.Now()
+	var fulfillment *models.Fulfillment
+	err := s.orderRepo.Transaction(func(tx *gorm.DB) error {
+		var existing models.Fulfillment
+		if err := tx.Where("order_id = ?", order.ID).First(&existing).Error; err == nil {
+			return ErrFulfillmentExists
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		names := make([]string, 0, len(order.Items))
+		for _, item := range order.Items {
+			if item.ProductID == 0 || item.Quantity <= 0 {
+				return ErrFulfillmentInvalid
+			}
+			var files []models.ProductFile
+			if err := tx.Where("product_id = ? AND sku_id IN ?", item.ProductID, []uint{0, item.SKUID}).
+				Order("sort_order desc, id asc").
+				Find(&files).Error; err != nil {
+				return err
+			}
+			if len(files) == 0 {
+				return ErrProductFileMissing
+			}
+			for _, file := range files {
+				download := models.OrderDownload{
+					OrderID:       order.ID,
+					ProductFileID: file.ID,
+					FileName:      file.Name,
+					FileSize:      file.Size,
+					MaxDownloads:  s.downloadCfg.MaxDownloads,
+					CreatedAt:     now,
+					UpdatedAt:     now,
 				}
-				if _, err := enqueueOrderStatusEmailTaskIfEligible(s.orderRepo, s.queueClient, *order.ParentID, status); err != nil {
-					logger.Warnw("fulfillment_enqueue_status_email_failed",
-						"order_id", order.ID,
-						"target_order_id", *order.ParentID,
-						"status", status,
-						"error", err,
-					)
+				if err := tx.Create(&download).Error; err != nil {
+					return err
 				}
+				names = append(names, file.Name)
 			}
-		} else {
-			if _, err := enqueueOrderStatusEmailTaskIfEligible(s.orderRepo, s.queueClient, orderID, constants.OrderStatusCompleted); err != nil {
-				logger.Warnw("fulfillment_enqueue_status_email_failed",
-					"order_id", order.ID,
-					"target_order_id", orderID,
-					"status", constants.OrderStatusCompleted,
-					"error", err,
-				)
+		}
+
+		fulfillment = &models.Fulfillment{
+			OrderID:      order.ID,
+			Type:         constants.FulfillmentTypeFile,
+			Status:       constants.FulfillmentStatusDelivered,
+			Payload:      strings.Join(names, "\n"),
+			TotalQty:     orderDeliveryQuantity(order),
+			DeliveredQty: orderDeliveryQuantity(order),
+			DeliveredAt:  &now,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		if err := tx.Create(fulfillment).Error; err != nil {
+			return ErrFulfillmentCreateFailed
+		}
+		if err := tx.Model(&models.Order{}).Where("id = ?", order.ID).Updates(map[string]interface{}{
+			"status":     constants.OrderStatusCompleted,
+			"updated_at": now,
+		}).Error; err != nil {
+			return ErrOrderUpdateFailed
+		}
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrFulfillmentExists):
+			return nil, ErrFulfillmentExists
+		case errors.Is(err, ErrProductFileMissing):
+			return nil, ErrProductFileMissing
+		case errors.Is(err, ErrOrderUpdateFailed):
+			return nil, ErrOrderUpdateFailed
+		default:
+			return nil, ErrFulfillmentCreateFailed
+		}
+	}
+	s.afterDelivered(order, constants.OrderStatusCompleted, now)
+	return fulfillment, nil
+}
+
+// isFileFulfillOrder 订单商品均为文件交付
+func isFileFulfillOrder(order *models.Order) bool {
+	if order == nil || len(order.Items) == 0 {
+		return false
+	}
+	for _, item := range order.Items {
+		if strings.TrimSpace(item.FulfillmentType) != constants.FulfillmentTypeFile {
+			return false
+		}
+	}
+	return true
+}
+
+// buildCardSecretDeliveryPayload 生成自动交付内容：结构化卡密按商品字段渲染为表格，普通卡密保持逐行
+func buildCardSecretDeliveryPayload(tx *gorm.DB, order *models.Order, groups [][]models.CardSecret) (string, error) {
+	locale := ""
+	localeResolved := false
+	parts := make([]string, 0, len(groups))
+	hasTable := false
+	for _, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		structured := false
+		for _, secret := range group {
+			if len(secret.Fields) > 0 {
+				structured = true
+				break
+			}
+		}
+		if !structured {
+			lines := make([]string, 0, len(group))
+			for _, secret := range group {
+				lines = append(lines, secret.Secret)
 			}
+			parts = append(parts, strings.Join(lines, "\n"))
+			continue
+		}
+		var product models.Product
+		if err := tx.Select("id", "secret_schema_json").First(&product, group[0].ProductID).Error; err != nil {
+			return "", err
+		}
+		fields, _, err := parseCardSecretSchema(product.SecretSchemaJSON)
+		if err != nil {
+			return "", err
 		}
+		if !localeResolved {
+			locale = resolveOrderDeliveryLocale(tx, order)
+			localeResolved = true
+		}
+		parts = append(parts, renderCardSecretPayload(fields, group, locale))
+		hasTable = hasTable || len(fields) > 0
+	}
+	if hasTable {
+		return strings.Join(parts, "\n\n"), nil
+	}
+	return strings.Join(parts, "\n"), nil
+}
+
+// resolveOrderDeliveryLocale 交付内容语言：游客取下单语言，会员取账号语言
+func resolveOrderDeliveryLocale(tx *gorm.DB, order *models.Order) string {
+	if order == nil {
+		return ""
 	}
+	if order.UserID == 0 {
+		return strings.TrimSpace(order.GuestLocale)
+	}
+	var user models.User
+	if err := tx.Select("id", "locale").First(&user, order.UserID).Error; err != nil {
+		return ""
+	}
+	return strings.TrimSpace(user.Locale)
+}
+
+// CreateAPIInput 供应商接口交付输入
+type CreateAPIInput struct {
+	OrderID      uint
+	Payload      string
+	DeliveryData models.JSON
+}
+
+// CreateAPI 写入供应商接口返回的交付内容
+func (s *FulfillmentService) CreateAPI(input CreateAPIInput) (*models.Fulfillment, error) {
+	if input.OrderID == 0 {
+		return nil, ErrFulfillmentInvalid
+	}
+	payload := strings.TrimSpace(input.Payload)
+	deliveryData := normalizeManualDeliveryData(input.DeliveryData)
+	if payload == "" && len(deliveryData) == 0 {
+		return nil, ErrFulfillmentInvalid
+	}
+	if payload == "" {
+		payload = buildManualDeliveryPayload(deliveryData)
+	}
+
+	order, err := s.orderRepo.GetByID(input.OrderID)
+	if err != nil {
+		return nil, ErrOrderFetchFailed
+	}
+	if order == nil {
+		return nil, ErrOrderNotFound
+	}
+	if !isAPIFulfillOrder(order) {
+		return nil, ErrFulfillmentNotAPI
+	}
+	if order.Status != constants.OrderStatusPaid && order.Status != constants.OrderStatusFulfilling {
+		return nil, ErrOrderStatusInvalid
+	}
+
+	now := time.Now()
+	var fulfillment *models.Fulfillment
+	err = s.orderRepo.Transaction(func(tx *gorm.DB) error {
+		var existing models.Fulfillment
+		if err := tx.Where("order_id = ?", input.OrderID).First(&existing).Error; err == nil {
+			return ErrFulfillmentExists
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		fulfillment = &models.Fulfillment{
+			OrderID:       input.OrderID,
+			Type:          constants.FulfillmentTypeAPI,
+			Status:        constants.FulfillmentStatusDelivered,
+			Payload:       payload,
+			LogisticsJSON: deliveryData,
+			TotalQty:      orderDeliveryQuantity(order),
+			DeliveredQty:  orderDeliveryQuantity(order),
+			DeliveredAt:   &now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		if err := tx.Create(fulfillment).Error; err != nil {
+			return ErrFulfillmentCreateFailed
+		}
+		if err := tx.Model(&models.Order{}).Where("id = ?", input.OrderID).Updates(map[string]interface{}{
+			"status":     constants.OrderStatusCompleted,
+			"updated_at": now,
+		}).Error; err != nil {
+			return ErrOrderUpdateFailed
+		}
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrFulfillmentExists):
+			return nil, ErrFulfillmentExists
+		case errors.Is(err, ErrOrderUpdateFailed):
+			return nil, ErrOrderUpdateFailed
+		default:
+			return nil, ErrFulfillmentCreateFailed
+		}
+	}
+	s.afterDelivered(order, constants.OrderStatusCompleted, now)
 	return fulfillment, nil
 }
 
+// isAPIFulfillOrder 订单商品均为接口交付（父订单需拆分到子订单处理）
+func isAPIFulfillOrder(order *models.Order) bool {
+	if order == nil || len(order.Items) == 0 {
+		return false
+	}
+	if order.ParentID == nil && len(order.Children) > 0 {
+		return false
+	}
+	for _, item := range order.Items {
+		if strings.TrimSpace(item.FulfillmentType) != constants.FulfillmentTypeAPI {
+			return false
+		}
+	}
+	return true
+}
+
+// afterDelivered 交付完成后同步父订单状态并推送状态邮件
+func (s *FulfillmentService) afterDelivered(order *models.Order, targetStatus string, now time.Time) {
+	if s.queueClient == nil || order == nil {
+		return
+	}
+	if order.ParentID == nil {
+		if _, err := enqueueOrderStatusEmailTaskIfEligible(s.orderRepo, s.queueClient, order.ID, targetStatus); err != nil {
+			logger.Warnw("fulfillment_enqueue_status_email_failed",
+				"order_id", order.ID,
+				"target_order_id", order.ID,
+				"status", targetStatus,
+				"error", err,
+			)
+		}
+		return
+	}
+	status, syncErr := syncParentStatus(s.orderRepo, *order.ParentID, now)
+	if syncErr != nil {
+		logger.Warnw("fulfillment_sync_parent_status_failed",
+			"order_id", order.ID,
+			"parent_order_id", *order.ParentID,
+			"target_status", targetStatus,
+			"error", syncErr,
+		)
+		return
+	}
+	if status == "" {
+		status = targetStatus
+	}
+	if _, err := enqueueOrderStatusEmailTaskIfEligible(s.orderRepo, s.queueClient, *order.ParentID, status); err != nil {
+		logger.Warnw("fulfillment_enqueue_status_email_failed",
+			"order_id", order.ID,
+			"target_order_id", *order.ParentID,
+			"status", status,
+			"error", err,
+		)
+	}
+}
+
 func normalizeManualDeliveryData(raw models.JSON) models.JSON {
 	if len(raw) == 0 {
 		return models.JSON{}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -25,9 +26,11 @@ func setupFulfillmentServiceTestDB(t *testing.T) *gorm.DB {
 		t.Fatalf("open sqlite failed: %v", err)
 	}
 	if err := db.AutoMigrate(
+		&models.Product{},
 		&models.Order{},
 		&models.OrderItem{},
 		&models.Fulfillment{},
+		&models.FulfillmentBatch{},
 		&models.CardSecret{},
 		&models.CardSecretBatch{},
 	); err != nil {
@@ -144,3 +147,99 @@ func TestCreateAutoFulfillmentRespectsSKUBoundary(t *testing.T) {
 		t.Fatalf("order status want completed got %s", orderAfter.Status)
 	}
 }
+
+func TestCreateManualFulfillmentInBatches(t *testing.T) {
+	db := setupFulfillmentServiceTestDB(t)
+	now := time.Now()
+
+	order := &models.Order{
+		OrderNo:                 "FULFILL-MANUAL-BATCH-001",
+		UserID:                  1,
+		Status:                  constants.OrderStatusPaid,
+		Currency:                "CNY",
+		OriginalAmount:          models.NewMoneyFromDecimal(decimal.NewFromInt(30)),
+		DiscountAmount:          models.NewMoneyFromDecimal(decimal.Zero),
+		PromotionDiscountAmount: models.NewMoneyFromDecimal(decimal.Zero),
+		TotalAmount:             models.NewMoneyFromDecimal(decimal.NewFromInt(30)),
+		WalletPaidAmount:        models.NewMoneyFromDecimal(decimal.Zero),
+		OnlinePaidAmount:        models.NewMoneyFromDecimal(decimal.NewFromInt(30)),
+		RefundedAmount:          models.NewMoneyFromDecimal(decimal.Zero),
+		CreatedAt:               now,
+		UpdatedAt:               now,
+	}
+	if err := db.Create(order).Error; err != nil {
+		t.Fatalf("create order failed: %v", err)
+	}
+	orderItem := &models.OrderItem{
+		OrderID:         order.ID,
+		ProductID:       200,
+		TitleJSON:       models.JSON{"zh-CN": "人工商品"},
+		UnitPrice:       models.NewMoneyFromDecimal(decimal.NewFromInt(10)),
+		Quantity:        3,
+		TotalPrice:      models.NewMoneyFromDecimal(decimal.NewFromInt(30)),
+		FulfillmentType: constants.FulfillmentTypeManual,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := db.Create(orderItem).Error; err != nil {
+		t.Fatalf("create order item failed: %v", err)
+	}
+
+	svc := NewFulfillmentService(
+		repository.NewOrderRepository(db),
+		repository.NewFulfillmentRepository(db),
+		repository.NewCardSecretRepository(db),
+		nil,
+	)
+
+	first, err := svc.CreateManual(CreateManualInput{OrderID: order.ID, AdminID: 1, Quantity: 1, Payload: "ACCOUNT-1"})
+	if err != nil {
+		t.Fatalf("create first batch failed: %v", err)
+	}
+	if first.Status != constants.FulfillmentStatusPartial || first.DeliveredQty != 1 || first.TotalQty != 3 {
+		t.Fatalf("unexpected first batch progress: status=%s delivered=%d total=%d", first.Status, first.DeliveredQty, first.TotalQty)
+	}
+	var orderAfter models.Order
+	if err := db.First(&orderAfter, order.ID).Error; err != nil {
+		t.Fatalf("query order failed: %v", err)
+	}
+	if orderAfter.Status != constants.OrderStatusPartiallyDelivered {
+		t.Fatalf("order status want partially_delivered got %s", orderAfter.Status)
+	}
+
+	if _, err := svc.CreateManual(CreateManualInput{OrderID: order.ID, AdminID: 1, Quantity: 5, Payload: "TOO-MANY"}); !errors.Is(err, ErrFulfillmentQuantityInvalid) {
+		t.Fatalf("expected quantity invalid error, got %v", err)
+	}
+
+	last, err := svc.CreateManual(CreateManualInput{OrderID: order.ID, AdminID: 2, Payload: "ACCOUNT-2\nACCOUNT-3"})
+	if err != nil {
+		t.Fatalf("create remaining batch failed: %v", err)
+	}
+	if last.Status != constants.FulfillmentStatusDelivered || last.DeliveredQty != 3 {
+		t.Fatalf("unexpected final progress: status=%s delivered=%d", last.Status, last.DeliveredQty)
+	}
+	if last.Payload != "ACCOUNT-1\nACCOUNT-2\nACCOUNT-3" {
+		t.Fatalf("unexpected aggregated payload: %q", last.Payload)
+	}
+	if len(last.Batches) != 2 || last.Batches[0].Quantity != 1 || last.Batches[1].Quantity != 2 {
+		t.Fatalf("unexpected batches: %+v", last.Batches)
+	}
+	if err := db.First(&orderAfter, order.ID).Error; err != nil {
+		t.Fatalf("query order failed: %v", err)
+	}
+	if orderAfter.Status != constants.OrderStatusDelivered {
+		t.Fatalf("order status want delivered got %s", orderAfter.Status)
+	}
+
+	stored, err := repository.NewOrderRepository(db).GetByID(order.ID)
+	if err != nil || stored == nil || stored.Fulfillment == nil {
+		t.Fatalf("reload order failed: %v", err)
+	}
+	if stored.Fulfillment.Payload != last.Payload || len(stored.Fulfillment.Batches) != 2 {
+		t.Fatalf("unexpected stored fulfillment: %+v", stored.Fulfillment)
+	}
+
+	if _, err := svc.CreateManual(CreateManualInput{OrderID: order.ID, AdminID: 1, Payload: "EXTRA"}); !errors.Is(err, ErrOrderStatusInvalid) {
+		t.Fatalf("expected order status invalid after full delivery, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+)
+
+const (
+	keyPatternMaxLength     = 128
+	keyPatternMinRandomRune = 6
+	keyLicenseMaxValidDays  = 36500
+	keyGenerateMaxAttempts  = 5
+)
+
+// 去掉易混淆的 0/O/1/I
+const (
+	keyPatternAlnum   = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	keyPatternLetters = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	keyPatternDigits  = "0123456789"
+)
+
+// keyGeneratorConfig 商品卡密生成器配置
+type keyGeneratorConfig struct {
+	Type      string
+	Pattern   string
+	ValidDays int
+}
+
+// HasKeyGenerator 商品是否配置了卡密生成器（按需生成，不受库存限制）
+func HasKeyGenerator(product *models.Product) bool {
+	if product == nil || strings.TrimSpace(product.FulfillmentType) != constants.FulfillmentTypeAuto {
+		return false
+	}
+	cfg, err := parseKeyGenerator(product.KeyGeneratorJSON)
+	return err == nil && cfg != nil
+}
+
+// normalizeKeyGenerator 校验并规范化生成器配置，空配置表示使用导入库存
+func normalizeKeyGenerator(raw models.JSON) (models.JSON, error) {
+	cfg, err := parseKeyGenerator(raw)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return models.JSON{}, nil
+	}
+	if cfg.Type == constants.KeyGeneratorPattern {
+		return models.JSON{"type": cfg.Type, "pattern": cfg.Pattern}, nil
+	}
+	return models.JSON{"type": cfg.Type, "valid_days": cfg.ValidDays}, nil
+}
+
+// parseKeyGenerator 解析生成器配置：
+// pattern 类型中 X 为字母数字、A 为字母、9 为数字，反斜杠转义，其余字符原样保留；
+// license 类型按 valid_days 计算有效期，0 表示永久。
+func parseKeyGenerator(raw models.JSON) (*keyGeneratorConfig, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	genType := strings.ToLower(strings.TrimSpace(toStringValue(raw["type"])))
+	switch genType {
+	case "":
+		return nil, nil
+	case constants.KeyGeneratorPattern:
+		pattern := strings.TrimSpace(toStringValue(raw["pattern"]))
+		if pattern == "" || len(pattern) > keyPatternMaxLength {
+			return nil, ErrKeyGeneratorInvalid
+		}
+		if countPatternRandomRunes(pattern) < keyPatternMinRandomRune {
+			return nil, ErrKeyGeneratorInvalid
+		}
+		return &keyGeneratorConfig{Type: genType, Pattern: pattern}, nil
+	case constants.KeyGeneratorLicense:
+		days, ok := parseKeyGeneratorInt(raw["valid_days"])
+		if !ok || days < 0 || days > keyLicenseMaxValidDays {
+			return nil, ErrKeyGeneratorInvalid
+		}
+		return &keyGeneratorConfig{Type: genType, ValidDays: days}, nil
+	default:
+		return nil, ErrKeyGeneratorInvalid
+	}
+}
+
+func parseKeyGeneratorInt(raw interface{}) (int, bool) {
+	switch v := raw.(type) {
+	case nil:
+		return 0, true
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		if v != float64(int(v)) {
+			return 0, false
+		}
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
+
+func countPatternRandomRunes(pattern string) int {
+	count := 0
+	escaped := false
+	for _, r := range pattern {
+		if escaped {
+			escaped = false
+			continue
+		}
+		switch r {
+		case '\\':
+			escaped = true
+		case 'X', 'A', '9':
+			count++
+		}
+	}
+	return count
+}
+
+// generatePatternKey 按模式生成随机卡密
+func generatePatternKey(pattern string) (string, error) {
+	var b strings.Builder
+	escaped := false
+	for _, r := range pattern {
+		if escaped {
+			b.WriteRune(r)
+			escaped = false
+			continue
+		}
+		var charset string
+		switch r {
+		case '\\':
+			escaped = true
+			continue
+		case 'X':
+			charset = keyPatternAlnum
+		case 'A':
+			charset = keyPatternLetters
+		case '9':
+			charset = keyPatternDigits
+		default:
+			b.WriteRune(r)
+			continue
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(charset[n.Int64()])
+	}
+	return b.String(), nil
+}
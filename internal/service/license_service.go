@@ -0,0 +1,155 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/dujiao-next/internal/config"
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/logger"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+)
+
+// 授权码校验结果原因
+const (
+	LicenseReasonMalformed = "malformed"
+	LicenseReasonSignature = "invalid_signature"
+	LicenseReasonExpired   = "expired"
+	LicenseReasonNotFound  = "not_found"
+	LicenseReasonRevoked   = "revoked"
+)
+
+// LicenseClaims 授权码声明
+type LicenseClaims struct {
+	LicenseID string `json:"lid"`
+	OrderNo   string `json:"ord"`
+	ProductID uint   `json:"pid"`
+	SKUID     uint   `json:"sku,omitempty"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp,omitempty"` // 0 表示永久有效
+}
+
+// LicenseVerifyResult 授权码校验结果
+type LicenseVerifyResult struct {
+	Valid  bool           `json:"valid"`
+	Reason string         `json:"reason,omitempty"`
+	Claims *LicenseClaims `json:"claims,omitempty"`
+}
+
+// LicenseService 签名授权码服务（Ed25519）
+type LicenseService struct {
+	privateKey ed25519.PrivateKey
+	secretRepo repository.CardSecretRepository
+	orderRepo  repository.OrderRepository
+}
+
+// NewLicenseService 创建签名授权码服务，未配置或配置无效的密钥时不可签发
+func NewLicenseService(cfg config.LicenseConfig, secretRepo repository.CardSecretRepository, orderRepo repository.OrderRepository) *LicenseService {
+	svc := &LicenseService{secretRepo: secretRepo, orderRepo: orderRepo}
+	raw := strings.TrimSpace(cfg.SigningKey)
+	if raw == "" {
+		return svc
+	}
+	seed, err := base64.StdEncoding.DecodeString(raw)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		logger.Warnw("license_signing_key_invalid", "error", err, "length", len(seed))
+		return svc
+	}
+	svc.privateKey = ed25519.NewKeyFromSeed(seed)
+	return svc
+}
+
+// Enabled 是否可签发授权码
+func (s *LicenseService) Enabled() bool {
+	return s != nil && len(s.privateKey) == ed25519.PrivateKeySize
+}
+
+// PublicKey 返回 base64 编码的验签公钥，供客户端离线校验
+func (s *LicenseService) PublicKey() string {
+	if !s.Enabled() {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(s.privateKey.Public().(ed25519.PublicKey))
+}
+
+// Issue 签发授权码，格式为 base64url(claims).base64url(signature)
+func (s *LicenseService) Issue(order *models.Order, item models.OrderItem, validDays int, now time.Time) (string, error) {
+	if !s.Enabled() {
+		return "", ErrKeyGeneratorUnavailable
+	}
+	claims := LicenseClaims{
+		LicenseID: randomHex(8),
+		ProductID: item.ProductID,
+		SKUID:     item.SKUID,
+		IssuedAt:  now.Unix(),
+	}
+	if order != nil {
+		claims.OrderNo = order.OrderNo
+	}
+	if validDays > 0 {
+		claims.ExpiresAt = now.AddDate(0, 0, validDays).Unix()
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signature := ed25519.Sign(s.privateKey, payload)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Verify 校验授权码签名、有效期，并确认发放记录仍有效
+func (s *LicenseService) Verify(license string) (*LicenseVerifyResult, error) {
+	if !s.Enabled() {
+		return nil, ErrKeyGeneratorUnavailable
+	}
+	license = strings.TrimSpace(license)
+	parts := strings.Split(license, ".")
+	if len(parts) != 2 {
+		return &LicenseVerifyResult{Reason: LicenseReasonMalformed}, nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return &LicenseVerifyResult{Reason: LicenseReasonMalformed}, nil
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return &LicenseVerifyResult{Reason: LicenseReasonMalformed}, nil
+	}
+	if !ed25519.Verify(s.privateKey.Public().(ed25519.PublicKey), payload, signature) {
+		return &LicenseVerifyResult{Reason: LicenseReasonSignature}, nil
+	}
+	var claims LicenseClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.ProductID == 0 {
+		return &LicenseVerifyResult{Reason: LicenseReasonMalformed}, nil
+	}
+	result := &LicenseVerifyResult{Claims: &claims}
+	if claims.ExpiresAt > 0 && time.Now().Unix() > claims.ExpiresAt {
+		result.Reason = LicenseReasonExpired
+		return result, nil
+	}
+
+	secret, err := s.secretRepo.GetByProductAndHash(claims.ProductID, models.CardSecretLookupHashes(license, nil)...)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil {
+		result.Reason = LicenseReasonNotFound
+		return result, nil
+	}
+	if secret.OrderID != nil && s.orderRepo != nil {
+		order, err := s.orderRepo.GetByID(*secret.OrderID)
+		if err != nil {
+			return nil, err
+		}
+		if order == nil || order.Status == constants.OrderStatusCanceled {
+			result.Reason = LicenseReasonRevoked
+			return result, nil
+		}
+	}
+	result.Valid = true
+	return result, nil
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dujiao-next/internal/config"
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestNormalizeKeyGenerator(t *testing.T) {
+	normalized, err := normalizeKeyGenerator(models.JSON{"type": " Pattern ", "pattern": "PRO-XXXX-\\X99-AAAA"})
+	if err != nil {
+		t.Fatalf("normalize pattern generator failed: %v", err)
+	}
+	if normalized["type"] != constants.KeyGeneratorPattern || normalized["pattern"] != "PRO-XXXX-\\X99-AAAA" {
+		t.Fatalf("unexpected normalized generator: %#v", normalized)
+	}
+	key, err := generatePatternKey("PRO-XXXX-\\X99-AAAA")
+	if err != nil {
+		t.Fatalf("generate pattern key failed: %v", err)
+	}
+	if !regexp.MustCompile(`^PRO-[A-Z2-9]{4}-X[0-9]{2}-[A-Z]{4}$`).MatchString(key) {
+		t.Fatalf("generated key does not match pattern: %s", key)
+	}
+
+	if empty, err := normalizeKeyGenerator(nil); err != nil || len(empty) != 0 {
+		t.Fatalf("empty generator should be allowed, got %#v %v", empty, err)
+	}
+	invalid := []models.JSON{
+		{"type": "pattern", "pattern": "XXXX"},
+		{"type": "pattern"},
+		{"type": "license", "valid_days": -1},
+		{"type": "license", "valid_days": "30"},
+		{"type": "uuid"},
+	}
+	for _, raw := range invalid {
+		if _, err := normalizeKeyGenerator(raw); !errors.Is(err, ErrKeyGeneratorInvalid) {
+			t.Fatalf("expected invalid generator for %#v, got %v", raw, err)
+		}
+	}
+}
+
+func TestCreateAutoWithLicenseGenerator(t *testing.T) {
+	db := setupFulfillmentServiceTestDB(t)
+	product := &models.Product{
+		CategoryID:       1,
+		Slug:             "license-product",
+		TitleJSON:        models.JSON{"zh-CN": "桌面软件"},
+		PriceAmount:      models.NewMoneyFromDecimal(decimal.NewFromInt(99)),
+		PurchaseType:     constants.ProductPurchaseMember,
+		FulfillmentType:  constants.FulfillmentTypeAuto,
+		KeyGeneratorJSON: models.JSON{"type": "license", "valid_days": 30},
+		IsActive:         true,
+	}
+	if err := db.Create(product).Error; err != nil {
+		t.Fatalf("create product failed: %v", err)
+	}
+	now := time.Now()
+	order := &models.Order{
+		OrderNo:                 "LIC-001",
+		UserID:                  1,
+		Status:                  constants.OrderStatusPaid,
+		Currency:                "CNY",
+		OriginalAmount:          models.NewMoneyFromDecimal(decimal.NewFromInt(198)),
+		DiscountAmount:          models.NewMoneyFromDecimal(decimal.Zero),
+		PromotionDiscountAmount: models.NewMoneyFromDecimal(decimal.Zero),
+		TotalAmount:             models.NewMoneyFromDecimal(decimal.NewFromInt(198)),
+		WalletPaidAmount:        models.NewMoneyFromDecimal(decimal.Zero),
+		OnlinePaidAmount:        models.NewMoneyFromDecimal(decimal.NewFromInt(198)),
+		RefundedAmount:          models.NewMoneyFromDecimal(decimal.Zero),
+		CreatedAt:               now,
+		UpdatedAt:               now,
+	}
+	if err := db.Create(order).Error; err != nil {
+		t.Fatalf("create order failed: %v", err)
+	}
+	if err := db.Create(&models.OrderItem{
+		OrderID:         order.ID,
+		ProductID:       product.ID,
+		SKUID:           7,
+		TitleJSON:       models.JSON{"zh-CN": "桌面软件"},
+		UnitPrice:       models.NewMoneyFromDecimal(decimal.NewFromInt(99)),
+		Quantity:        2,
+		TotalPrice:      models.NewMoneyFromDecimal(decimal.NewFromInt(198)),
+		FulfillmentType: constants.FulfillmentTypeAuto,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}).Error; err != nil {
+		t.Fatalf("create order item failed: %v", err)
+	}
+
+	orderRepo := repository.NewOrderRepository(db)
+	secretRepo := repository.NewCardSecretRepository(db)
+	svc := NewFulfillmentService(orderRepo, repository.NewFulfillmentRepository(db), secretRepo, nil)
+	if _, err := svc.CreateAuto(order.ID); !errors.Is(err, ErrKeyGeneratorUnavailable) {
+		t.Fatalf("expected unavailable without signing key, got %v", err)
+	}
+
+	seed := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+	licenseSvc := NewLicenseService(config.LicenseConfig{SigningKey: seed}, secretRepo, orderRepo)
+	svc.SetLicenseService(licenseSvc)
+	t.Cleanup(func() { models.SetFieldCipher(nil) })
+	models.SetFieldCipher(newSecretRotationTestKeyring(t, "k1"))
+	fulfillment, err := svc.CreateAuto(order.ID)
+	if err != nil {
+		t.Fatalf("create auto with license generator failed: %v", err)
+	}
+	licenses := strings.Split(fulfillment.Payload, "\n")
+	if len(licenses) != 2 || licenses[0] == licenses[1] {
+		t.Fatalf("expected 2 distinct licenses, got %q", fulfillment.Payload)
+	}
+
+	var stored []models.CardSecret
+	if err := db.Where("order_id = ?", order.ID).Find(&stored).Error; err != nil {
+		t.Fatalf("query generated secrets failed: %v", err)
+	}
+	if len(stored) != 2 || stored[0].Status != models.CardSecretStatusUsed || stored[0].BatchID == nil {
+		t.Fatalf("generated secrets should be stored as used for audit: %+v", stored)
+	}
+
+	// 密钥轮换后、轮换任务重算哈希前，旧密钥下签发的授权码仍可校验
+	models.SetFieldCipher(newSecretRotationTestKeyring(t, "k2"))
+	var staleKeys int64
+	db.Model(&models.CardSecret{}).Where("hash_key_id = ?", "k1").Count(&staleKeys)
+	if staleKeys != 2 {
+		t.Fatalf("expected licenses hashed with k1 before rotation, got %d", staleKeys)
+	}
+	result, err := licenseSvc.Verify(licenses[0])
+	if err != nil {
+		t.Fatalf("verify license failed: %v", err)
+	}
+	if !result.Valid || result.Claims.OrderNo != "LIC-001" || result.Claims.SKUID != 7 || result.Claims.ExpiresAt == 0 {
+		t.Fatalf("unexpected verify result: %+v", result)
+	}
+
+	tampered := "x" + licenses[0]
+	if result, _ := licenseSvc.Verify(tampered); result.Valid || result.Reason == "" {
+		t.Fatalf("tampered license should be rejected: %+v", result)
+	}
+
+	if err := db.Model(&models.Order{}).Where("id = ?", order.ID).Update("status", constants.OrderStatusCanceled).Error; err != nil {
+		t.Fatalf("cancel order failed: %v", err)
+	}
+	if result, _ := licenseSvc.Verify(licenses[1]); result.Valid || result.Reason != LicenseReasonRevoked {
+		t.Fatalf("license of canceled order should be revoked: %+v", result)
+	}
+}
@@ -0,0 +1,422 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// MemberLevelService 会员等级服务
+type MemberLevelService struct {
+	repo           repository.MemberLevelRepository
+	userRepo       repository.UserRepository
+	orderRepo      repository.OrderRepository
+	productSKURepo repository.ProductSKURepository
+}
+
+// NewMemberLevelService 创建会员等级服务
+func NewMemberLevelService(repo repository.MemberLevelRepository, userRepo repository.UserRepository, orderRepo repository.OrderRepository, productSKURepo repository.ProductSKURepository) *MemberLevelService {
+	return &MemberLevelService{
+		repo:           repo,
+		userRepo:       userRepo,
+		orderRepo:      orderRepo,
+		productSKURepo: productSKURepo,
+	}
+}
+
+// MemberLevelInput 会员等级输入
+type MemberLevelInput struct {
+	Code            string
+	NameJSON        map[string]interface{}
+	DiscountPercent decimal.Decimal
+	MinSpend        decimal.Decimal
+	AutoUpgrade     bool
+	IsDefault       bool
+	IsActive        *bool
+	SortOrder       int
+}
+
+// MemberLevelPriceInput 会员等级 SKU 专属价输入
+type MemberLevelPriceInput struct {
+	SKUID       uint
+	PriceAmount decimal.Decimal
+}
+
+// MemberPricing 用户当前等级的定价规则
+type MemberPricing struct {
+	Level  *models.MemberLevel
+	prices map[uint]models.Money
+}
+
+// apply 计算会员价：SKU 专属价优先，其次按等级折扣；仅在低于传入价格时生效
+func (p *MemberPricing) apply(skuID uint, price models.Money) (models.Money, bool) {
+	if p == nil || p.Level == nil {
+		return price, false
+	}
+	if explicit, ok := p.prices[skuID]; ok {
+		if explicit.Decimal.GreaterThan(decimal.Zero) && explicit.Decimal.LessThan(price.Decimal) {
+			return explicit, true
+		}
+		return price, false
+	}
+	percent := p.Level.DiscountPercent.Decimal
+	if percent.LessThanOrEqual(decimal.Zero) {
+		return price, false
+	}
+	hundred := decimal.NewFromInt(100)
+	discounted := price.Decimal.Mul(hundred.Sub(percent)).Div(hundred).Round(2)
+	if discounted.LessThanOrEqual(decimal.Zero) || !discounted.LessThan(price.Decimal) {
+		return price, false
+	}
+	return models.NewMoneyFromDecimal(discounted), true
+}
+
+// List 获取会员等级列表
+func (s *MemberLevelService) List() ([]models.MemberLevel, error) {
+	return s.repo.List()
+}
+
+// Get 获取会员等级详情（含 SKU 专属价）
+func (s *MemberLevelService) Get(id uint) (*models.MemberLevel, error) {
+	level, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if level == nil {
+		return nil, ErrMemberLevelNotFound
+	}
+	prices, err := s.repo.ListPrices(level.ID)
+	if err != nil {
+		return nil, err
+	}
+	level.Prices = prices
+	return level, nil
+}
+
+// Create 创建会员等级
+func (s *MemberLevelService) Create(input MemberLevelInput) (*models.MemberLevel, error) {
+	level := &models.MemberLevel{IsActive: true}
+	if err := s.applyInput(level, input); err != nil {
+		return nil, err
+	}
+	err := s.repo.Transaction(func(tx *gorm.DB) error {
+		repo := s.repo.WithTx(tx)
+		if err := repo.Create(level); err != nil {
+			return err
+		}
+		if level.IsDefault {
+			return repo.ClearDefault(level.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return level, nil
+}
+
+// Update 更新会员等级
+func (s *MemberLevelService) Update(id uint, input MemberLevelInput) (*models.MemberLevel, error) {
+	level, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if level == nil {
+		return nil, ErrMemberLevelNotFound
+	}
+	if err := s.applyInput(level, input); err != nil {
+		return nil, err
+	}
+	err = s.repo.Transaction(func(tx *gorm.DB) error {
+		repo := s.repo.WithTx(tx)
+		if err := repo.Update(level); err != nil {
+			return err
+		}
+		if level.IsDefault {
+			return repo.ClearDefault(level.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return level, nil
+}
+
+// Delete 删除会员等级，原等级用户回落到默认等级
+func (s *MemberLevelService) Delete(id uint) error {
+	level, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if level == nil {
+		return ErrMemberLevelNotFound
+	}
+	return s.repo.Transaction(func(tx *gorm.DB) error {
+		repo := s.repo.WithTx(tx)
+		if err := repo.ResetUsersLevel(id); err != nil {
+			return err
+		}
+		return repo.Delete(id)
+	})
+}
+
+// SetPrices 覆盖等级 SKU 专属价
+func (s *MemberLevelService) SetPrices(id uint, inputs []MemberLevelPriceInput) ([]models.MemberLevelPrice, error) {
+	level, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if level == nil {
+		return nil, ErrMemberLevelNotFound
+	}
+
+	prices := make([]models.MemberLevelPrice, 0, len(inputs))
+	seen := make(map[uint]struct{}, len(inputs))
+	for _, input := range inputs {
+		price := input.PriceAmount.Round(2)
+		if input.SKUID == 0 || price.LessThanOrEqual(decimal.Zero) {
+			return nil, ErrMemberLevelInvalid
+		}
+		if _, ok := seen[input.SKUID]; ok {
+			return nil, ErrMemberLevelInvalid
+		}
+		seen[input.SKUID] = struct{}{}
+		sku, err := s.productSKURepo.GetByID(input.SKUID)
+		if err != nil {
+			return nil, err
+		}
+		if sku == nil {
+			return nil, ErrProductSKUInvalid
+		}
+		prices = append(prices, models.MemberLevelPrice{
+			MemberLevelID: level.ID,
+			SKUID:         input.SKUID,
+			PriceAmount:   models.NewMoneyFromDecimal(price),
+		})
+	}
+
+	err = s.repo.Transaction(func(tx *gorm.DB) error {
+		return s.repo.WithTx(tx).ReplacePrices(level.ID, prices)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListPrices(level.ID)
+}
+
+// ResolveUserLevel 返回用户当前生效的等级（未分配或等级停用时回落到默认等级）
+func (s *MemberLevelService) ResolveUserLevel(user *models.User) (*models.MemberLevel, error) {
+	if user == nil {
+		return nil, nil
+	}
+	if user.MemberLevelID != 0 {
+		level, err := s.repo.GetByID(user.MemberLevelID)
+		if err != nil {
+			return nil, err
+		}
+		if level != nil && level.IsActive {
+			return level, nil
+		}
+	}
+	return s.repo.GetDefault()
+}
+
+// ResolveUserLevels 批量解析用户生效等级，返回以用户ID为键的映射（无等级的用户不在结果中）
+func (s *MemberLevelService) ResolveUserLevels(users []models.User) (map[uint]*models.MemberLevel, error) {
+	result := make(map[uint]*models.MemberLevel, len(users))
+	if len(users) == 0 {
+		return result, nil
+	}
+	levels, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	var defaultLevel *models.MemberLevel
+	levelMap := make(map[uint]*models.MemberLevel, len(levels))
+	for i := range levels {
+		level := &levels[i]
+		if !level.IsActive {
+			continue
+		}
+		levelMap[level.ID] = level
+		if level.IsDefault && defaultLevel == nil {
+			defaultLevel = level
+		}
+	}
+	for _, user := range users {
+		if level, ok := levelMap[user.MemberLevelID]; ok {
+			result[user.ID] = level
+		} else if defaultLevel != nil {
+			result[user.ID] = defaultLevel
+		}
+	}
+	return result, nil
+}
+
+// ResolvePricing 获取用户下单时使用的会员定价，游客或无等级时返回 nil
+func (s *MemberLevelService) ResolvePricing(userID uint) (*MemberPricing, error) {
+	if userID == 0 {
+		return nil, nil
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	level, err := s.ResolveUserLevel(user)
+	if err != nil || level == nil {
+		return nil, err
+	}
+	prices, err := s.repo.ListPrices(level.ID)
+	if err != nil {
+		return nil, err
+	}
+	pricing := &MemberPricing{Level: level, prices: make(map[uint]models.Money, len(prices))}
+	for _, price := range prices {
+		pricing.prices[price.SKUID] = price.PriceAmount
+	}
+	return pricing, nil
+}
+
+// AssignUserLevel 管理员指定用户等级；levelID 为 0 时取消指定并按累计消费重新计算
+func (s *MemberLevelService) AssignUserLevel(userID, levelID uint) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrNotFound
+	}
+	if levelID == 0 {
+		if err := s.repo.UpdateUserMembership(user.ID, map[string]interface{}{
+			"member_level_locked": false,
+			"updated_at":          time.Now(),
+		}); err != nil {
+			return nil, err
+		}
+		return s.RefreshUserLevel(user.ID)
+	}
+
+	level, err := s.repo.GetByID(levelID)
+	if err != nil {
+		return nil, err
+	}
+	if level == nil || !level.IsActive {
+		return nil, ErrMemberLevelNotFound
+	}
+	if err := s.repo.UpdateUserMembership(user.ID, map[string]interface{}{
+		"member_level_id":     level.ID,
+		"member_level_locked": true,
+		"updated_at":          time.Now(),
+	}); err != nil {
+		return nil, err
+	}
+	user.MemberLevelID = level.ID
+	user.MemberLevelLocked = true
+	return user, nil
+}
+
+// RefreshUserLevel 重新统计累计消费，未被手动锁定的用户按消费门槛自动调整等级
+func (s *MemberLevelService) RefreshUserLevel(userID uint) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrNotFound
+	}
+	spent, err := s.repo.SumUserSpend(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{
+		"total_spent": models.NewMoneyFromDecimal(spent),
+	}
+	user.TotalSpent = models.NewMoneyFromDecimal(spent)
+	if !user.MemberLevelLocked {
+		levels, err := s.repo.List()
+		if err != nil {
+			return nil, err
+		}
+		levelID := pickAutoMemberLevel(levels, spent)
+		updates["member_level_id"] = levelID
+		user.MemberLevelID = levelID
+	}
+	if err := s.repo.UpdateUserMembership(user.ID, updates); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// HandleOrderPaid 订单支付成功后刷新下单用户的累计消费与等级
+func (s *MemberLevelService) HandleOrderPaid(orderID uint) error {
+	if orderID == 0 {
+		return nil
+	}
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return err
+	}
+	if order == nil || order.UserID == 0 {
+		return nil
+	}
+	_, err = s.RefreshUserLevel(order.UserID)
+	return err
+}
+
+func (s *MemberLevelService) applyInput(level *models.MemberLevel, input MemberLevelInput) error {
+	code := strings.ToLower(strings.TrimSpace(input.Code))
+	if code == "" || len(code) > 64 {
+		return ErrMemberLevelInvalid
+	}
+	discount := input.DiscountPercent.Round(2)
+	if discount.LessThan(decimal.Zero) || !discount.LessThan(decimal.NewFromInt(100)) {
+		return ErrMemberLevelInvalid
+	}
+	minSpend := input.MinSpend.Round(2)
+	if minSpend.LessThan(decimal.Zero) {
+		return ErrMemberLevelInvalid
+	}
+	existing, err := s.repo.GetByCode(code)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != level.ID {
+		return ErrMemberLevelCodeExists
+	}
+
+	level.Code = code
+	level.NameJSON = models.JSON(input.NameJSON)
+	level.DiscountPercent = models.NewMoneyFromDecimal(discount)
+	level.MinSpend = models.NewMoneyFromDecimal(minSpend)
+	level.AutoUpgrade = input.AutoUpgrade
+	level.IsDefault = input.IsDefault
+	level.SortOrder = input.SortOrder
+	if input.IsActive != nil {
+		level.IsActive = *input.IsActive
+	}
+	return nil
+}
+
+// pickAutoMemberLevel 在参与自动升级的启用等级中选出消费门槛最高且已达到的等级
+func pickAutoMemberLevel(levels []models.MemberLevel, spent decimal.Decimal) uint {
+	var picked *models.MemberLevel
+	for i := range levels {
+		level := &levels[i]
+		if !level.IsActive || !level.AutoUpgrade || level.MinSpend.Decimal.GreaterThan(spent) {
+			continue
+		}
+		if picked == nil || level.MinSpend.Decimal.GreaterThan(picked.MinSpend.Decimal) {
+			picked = level
+		}
+	}
+	if picked == nil {
+		return 0
+	}
+	return picked.ID
+}
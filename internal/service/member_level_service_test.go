@@ -0,0 +1,235 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/glebarez/sqlite"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+func setupMemberLevelTest(t *testing.T) (*gorm.DB, *MemberLevelService) {
+	t.Helper()
+	dsn := fmt.Sprintf("file:member_level_service_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{DisableForeignKeyConstraintWhenMigrating: true})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.MemberLevel{}, &models.MemberLevelPrice{}, &models.Order{}, &models.OrderItem{}, &models.Fulfillment{}, &models.Product{}, &models.ProductSKU{}, &models.Promotion{}); err != nil {
+		t.Fatalf("auto migrate failed: %v", err)
+	}
+	svc := NewMemberLevelService(repository.NewMemberLevelRepository(db), repository.NewUserRepository(db), repository.NewOrderRepository(db), repository.NewProductSKURepository(db))
+	return db, svc
+}
+
+func TestMemberLevelAutoUpgradeByCumulativeSpend(t *testing.T) {
+	db, svc := setupMemberLevelTest(t)
+
+	regular, err := svc.Create(MemberLevelInput{Code: "Regular", NameJSON: map[string]interface{}{"zh-CN": "普通会员"}, IsDefault: true})
+	if err != nil {
+		t.Fatalf("create regular failed: %v", err)
+	}
+	vip, err := svc.Create(MemberLevelInput{Code: "vip", DiscountPercent: decimal.NewFromInt(10), MinSpend: decimal.NewFromInt(100), AutoUpgrade: true})
+	if err != nil {
+		t.Fatalf("create vip failed: %v", err)
+	}
+	reseller, err := svc.Create(MemberLevelInput{Code: "reseller", DiscountPercent: decimal.NewFromInt(20)})
+	if err != nil {
+		t.Fatalf("create reseller failed: %v", err)
+	}
+	if _, err := svc.Create(MemberLevelInput{Code: "VIP"}); err != ErrMemberLevelCodeExists {
+		t.Fatalf("expected ErrMemberLevelCodeExists, got %v", err)
+	}
+
+	user := models.User{Email: "member@example.com", PasswordHash: "x"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("create user failed: %v", err)
+	}
+	level, err := svc.ResolveUserLevel(&user)
+	if err != nil || level == nil || level.ID != regular.ID {
+		t.Fatalf("expected default level, got %+v err=%v", level, err)
+	}
+
+	orders := []models.Order{
+		{OrderNo: "M-1", UserID: user.ID, Status: constants.OrderStatusCompleted, TotalAmount: models.NewMoneyFromDecimal(decimal.NewFromInt(60))},
+		{OrderNo: "M-2", UserID: user.ID, Status: constants.OrderStatusPaid, TotalAmount: models.NewMoneyFromDecimal(decimal.NewFromInt(50))},
+		{OrderNo: "M-3", UserID: user.ID, Status: constants.OrderStatusCanceled, TotalAmount: models.NewMoneyFromDecimal(decimal.NewFromInt(500))},
+	}
+	if err := db.Create(&orders).Error; err != nil {
+		t.Fatalf("create orders failed: %v", err)
+	}
+	if err := svc.HandleOrderPaid(orders[1].ID); err != nil {
+		t.Fatalf("handle order paid failed: %v", err)
+	}
+	var refreshed models.User
+	if err := db.First(&refreshed, user.ID).Error; err != nil {
+		t.Fatalf("reload user failed: %v", err)
+	}
+	if refreshed.MemberLevelID != vip.ID || refreshed.TotalSpent.String() != "110.00" {
+		t.Fatalf("expected vip with spend 110.00, got level=%d spend=%s", refreshed.MemberLevelID, refreshed.TotalSpent.String())
+	}
+
+	if _, err := svc.AssignUserLevel(user.ID, reseller.ID); err != nil {
+		t.Fatalf("assign level failed: %v", err)
+	}
+	if _, err := svc.RefreshUserLevel(user.ID); err != nil {
+		t.Fatalf("refresh level failed: %v", err)
+	}
+	if err := db.First(&refreshed, user.ID).Error; err != nil {
+		t.Fatalf("reload user failed: %v", err)
+	}
+	if refreshed.MemberLevelID != reseller.ID || !refreshed.MemberLevelLocked {
+		t.Fatalf("manual level should survive refresh, got level=%d locked=%v", refreshed.MemberLevelID, refreshed.MemberLevelLocked)
+	}
+
+	if _, err := svc.AssignUserLevel(user.ID, 0); err != nil {
+		t.Fatalf("unassign level failed: %v", err)
+	}
+	if err := db.First(&refreshed, user.ID).Error; err != nil {
+		t.Fatalf("reload user failed: %v", err)
+	}
+	if refreshed.MemberLevelID != vip.ID || refreshed.MemberLevelLocked {
+		t.Fatalf("expected auto level after unassign, got level=%d locked=%v", refreshed.MemberLevelID, refreshed.MemberLevelLocked)
+	}
+}
+
+func TestBuildOrderResultAppliesMemberLevelPricing(t *testing.T) {
+	db, svc := setupMemberLevelTest(t)
+
+	product := models.Product{
+		CategoryID:      1,
+		Slug:            "member-priced",
+		TitleJSON:       models.JSON{"zh-CN": "会员价商品"},
+		PriceAmount:     models.NewMoneyFromDecimal(decimal.NewFromInt(100)),
+		PurchaseType:    constants.ProductPurchaseMember,
+		FulfillmentType: constants.FulfillmentTypeManual,
+		IsActive:        true,
+	}
+	if err := db.Create(&product).Error; err != nil {
+		t.Fatalf("create product failed: %v", err)
+	}
+	skus := []models.ProductSKU{
+		{ProductID: product.ID, SKUCode: "A", PriceAmount: models.NewMoneyFromDecimal(decimal.NewFromInt(100)), ManualStockTotal: constants.ManualStockUnlimited, IsActive: true},
+		{ProductID: product.ID, SKUCode: "B", PriceAmount: models.NewMoneyFromDecimal(decimal.NewFromInt(50)), ManualStockTotal: constants.ManualStockUnlimited, IsActive: true},
+	}
+	if err := db.Create(&skus).Error; err != nil {
+		t.Fatalf("create skus failed: %v", err)
+	}
+
+	vip, err := svc.Create(MemberLevelInput{Code: "vip", DiscountPercent: decimal.NewFromInt(10)})
+	if err != nil {
+		t.Fatalf("create vip failed: %v", err)
+	}
+	if _, err := svc.SetPrices(vip.ID, []MemberLevelPriceInput{{SKUID: skus[1].ID, PriceAmount: decimal.NewFromInt(40)}}); err != nil {
+		t.Fatalf("set prices failed: %v", err)
+	}
+	member := models.User{Email: "vip@example.com", PasswordHash: "x", MemberLevelID: vip.ID, MemberLevelLocked: true}
+	guest := models.User{Email: "plain@example.com", PasswordHash: "x"}
+	if err := db.Create(&member).Error; err != nil {
+		t.Fatalf("create member failed: %v", err)
+	}
+	if err := db.Create(&guest).Error; err != nil {
+		t.Fatalf("create user failed: %v", err)
+	}
+
+	orderSvc := NewOrderService(nil, repository.NewProductRepository(db), repository.NewProductSKURepository(db), nil, nil, nil, repository.NewPromotionRepository(db), nil, nil, nil, nil, 15)
+	orderSvc.SetMemberLevelService(svc)
+	items := []CreateOrderItem{
+		{ProductID: product.ID, SKUID: skus[0].ID, Quantity: 1},
+		{ProductID: product.ID, SKUID: skus[1].ID, Quantity: 2},
+	}
+
+	result, err := orderSvc.buildOrderResult(orderCreateParams{UserID: member.ID, Items: items})
+	if err != nil {
+		t.Fatalf("buildOrderResult failed: %v", err)
+	}
+	if result.OriginalAmount.StringFixed(2) != "170.00" || result.TotalAmount.StringFixed(2) != "170.00" {
+		t.Fatalf("unexpected member amounts original=%s total=%s", result.OriginalAmount.StringFixed(2), result.TotalAmount.StringFixed(2))
+	}
+	if result.OrderItems[0].PricingSnapshotJSON["member_level"] != "vip" {
+		t.Fatalf("expected member level in pricing snapshot, got %+v", result.OrderItems[0].PricingSnapshotJSON)
+	}
+	if _, ok := result.OrderItems[0].SKUSnapshotJSON["member_price"]; ok {
+		t.Fatalf("expected sku snapshot without member price, got %+v", result.OrderItems[0].SKUSnapshotJSON)
+	}
+
+	result, err = orderSvc.buildOrderResult(orderCreateParams{UserID: guest.ID, Items: items})
+	if err != nil {
+		t.Fatalf("buildOrderResult failed: %v", err)
+	}
+	if result.TotalAmount.StringFixed(2) != "200.00" {
+		t.Fatalf("expected list price for user without level, got %s", result.TotalAmount.StringFixed(2))
+	}
+}
+
+func TestMemberLevelDowngradesAfterRefund(t *testing.T) {
+	db, svc := setupMemberLevelTest(t)
+	if err := db.AutoMigrate(&models.WalletAccount{}, &models.WalletTransaction{}); err != nil {
+		t.Fatalf("auto migrate wallet failed: %v", err)
+	}
+
+	regular, err := svc.Create(MemberLevelInput{Code: "regular", IsDefault: true})
+	if err != nil {
+		t.Fatalf("create regular failed: %v", err)
+	}
+	vip, err := svc.Create(MemberLevelInput{Code: "vip", MinSpend: decimal.NewFromInt(100), AutoUpgrade: true})
+	if err != nil {
+		t.Fatalf("create vip failed: %v", err)
+	}
+	user := models.User{Email: "refund@example.com", PasswordHash: "x"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("create user failed: %v", err)
+	}
+
+	paidAt := time.Now()
+	parent := models.Order{OrderNo: "R-1", UserID: user.ID, Status: constants.OrderStatusCompleted, TotalAmount: models.NewMoneyFromDecimal(decimal.NewFromInt(120)), PaidAt: &paidAt}
+	if err := db.Create(&parent).Error; err != nil {
+		t.Fatalf("create order failed: %v", err)
+	}
+	child := models.Order{OrderNo: "R-1-1", ParentID: &parent.ID, UserID: user.ID, Status: constants.OrderStatusCompleted, TotalAmount: models.NewMoneyFromDecimal(decimal.NewFromInt(20)), PaidAt: &paidAt}
+	if err := db.Create(&child).Error; err != nil {
+		t.Fatalf("create child order failed: %v", err)
+	}
+	if err := svc.HandleOrderPaid(parent.ID); err != nil {
+		t.Fatalf("handle order paid failed: %v", err)
+	}
+	var refreshed models.User
+	if err := db.First(&refreshed, user.ID).Error; err != nil {
+		t.Fatalf("reload user failed: %v", err)
+	}
+	if refreshed.MemberLevelID != vip.ID {
+		t.Fatalf("expected vip before refund, got level=%d", refreshed.MemberLevelID)
+	}
+
+	walletSvc := NewWalletService(repository.NewWalletRepository(db), repository.NewOrderRepository(db), repository.NewUserRepository(db), nil)
+	walletSvc.SetMemberLevelService(svc)
+	if _, _, err := walletSvc.AdminRefundToWallet(AdminRefundToWalletInput{OrderID: child.ID, Amount: models.NewMoneyFromDecimal(decimal.NewFromInt(20))}); err != nil {
+		t.Fatalf("refund child order failed: %v", err)
+	}
+	if err := db.First(&refreshed, user.ID).Error; err != nil {
+		t.Fatalf("reload user failed: %v", err)
+	}
+	if refreshed.MemberLevelID != vip.ID || refreshed.TotalSpent.String() != "100.00" {
+		t.Fatalf("expected vip with spend 100.00 after child refund, got level=%d spend=%s", refreshed.MemberLevelID, refreshed.TotalSpent.String())
+	}
+
+	if _, _, err := walletSvc.AdminRefundToWallet(AdminRefundToWalletInput{OrderID: parent.ID, Amount: models.NewMoneyFromDecimal(decimal.NewFromInt(30))}); err != nil {
+		t.Fatalf("refund order failed: %v", err)
+	}
+	if err := db.First(&refreshed, user.ID).Error; err != nil {
+		t.Fatalf("reload user failed: %v", err)
+	}
+	if refreshed.MemberLevelID == vip.ID || refreshed.TotalSpent.String() != "70.00" {
+		t.Fatalf("expected downgrade with spend 70.00, got level=%d spend=%s", refreshed.MemberLevelID, refreshed.TotalSpent.String())
+	}
+	level, err := svc.ResolveUserLevel(&refreshed)
+	if err != nil || level == nil || level.ID != regular.ID {
+		t.Fatalf("expected default level after refund, got %+v err=%v", level, err)
+	}
+}
@@ -35,6 +35,8 @@ type NotificationSceneSetting struct {
 	WalletRechargeSuccess    bool `json:"wallet_recharge_success"`
 	OrderPaidSuccess         bool `json:"order_paid_success"`
 	ManualFulfillmentPending bool `json:"manual_fulfillment_pending"`
+	OrderMessageReceived     bool `json:"order_message_received"`
+	SupplierFulfillFailed    bool `json:"supplier_fulfill_failed"`
 	ExceptionAlert           bool `json:"exception_alert"`
 }
 
@@ -56,6 +58,8 @@ type NotificationTemplatesSetting struct {
 	WalletRechargeSuccess    NotificationSceneTemplate `json:"wallet_recharge_success"`
 	OrderPaidSuccess         NotificationSceneTemplate `json:"order_paid_success"`
 	ManualFulfillmentPending NotificationSceneTemplate `json:"manual_fulfillment_pending"`
+	OrderMessageReceived     NotificationSceneTemplate `json:"order_message_received"`
+	SupplierFulfillFailed    NotificationSceneTemplate `json:"supplier_fulfill_failed"`
 	ExceptionAlert           NotificationSceneTemplate `json:"exception_alert"`
 }
 
@@ -94,6 +98,8 @@ type NotificationScenePatch struct {
 	WalletRechargeSuccess    *bool `json:"wallet_recharge_success"`
 	OrderPaidSuccess         *bool `json:"order_paid_success"`
 	ManualFulfillmentPending *bool `json:"manual_fulfillment_pending"`
+	OrderMessageReceived     *bool `json:"order_message_received"`
+	SupplierFulfillFailed    *bool `json:"supplier_fulfill_failed"`
 	ExceptionAlert           *bool `json:"exception_alert"`
 }
 
@@ -102,6 +108,8 @@ type NotificationTemplatesPatch struct {
 	WalletRechargeSuccess    *NotificationSceneTemplatePatch `json:"wallet_recharge_success"`
 	OrderPaidSuccess         *NotificationSceneTemplatePatch `json:"order_paid_success"`
 	ManualFulfillmentPending *NotificationSceneTemplatePatch `json:"manual_fulfillment_pending"`
+	OrderMessageReceived     *NotificationSceneTemplatePatch `json:"order_message_received"`
+	SupplierFulfillFailed    *NotificationSceneTemplatePatch `json:"supplier_fulfill_failed"`
 	ExceptionAlert           *NotificationSceneTemplatePatch `json:"exception_alert"`
 }
 
@@ -136,6 +144,8 @@ func NotificationCenterDefaultSetting() NotificationCenterSetting {
 			WalletRechargeSuccess:    true,
 			OrderPaidSuccess:         true,
 			ManualFulfillmentPending: true,
+			OrderMessageReceived:     true,
+			SupplierFulfillFailed:    true,
 			ExceptionAlert:           true,
 		},
 		Templates: NotificationTemplatesSetting{
@@ -181,6 +191,34 @@ func NotificationCenterDefaultSetting() NotificationCenterSetting {
 					Body:  "Order No: {{order_no}}\nUser ID: {{user_id}}\nOrder Status: {{order_status}}\nPlease process manual fulfillment.",
 				},
 			},
+			OrderMessageReceived: NotificationSceneTemplate{
+				ZHCN: NotificationLocalizedTemplate{
+					Title: "订单买家留言提醒",
+					Body:  "订单号：{{order_no}}\n用户ID：{{user_id}}\n游客邮箱：{{guest_email}}\n留言内容：{{message}}",
+				},
+				ZHTW: NotificationLocalizedTemplate{
+					Title: "訂單買家留言提醒",
+					Body:  "訂單號：{{order_no}}\n用戶ID：{{user_id}}\n遊客郵箱：{{guest_email}}\n留言內容：{{message}}",
+				},
+				ENUS: NotificationLocalizedTemplate{
+					Title: "New Buyer Message",
+					Body:  "Order No: {{order_no}}\nUser ID: {{user_id}}\nGuest Email: {{guest_email}}\nMessage: {{message}}",
+				},
+			},
+			SupplierFulfillFailed: NotificationSceneTemplate{
+				ZHCN: NotificationLocalizedTemplate{
+					Title: "供应商接口交付失败",
+					Body:  "订单号：{{order_no}}\n供应商：{{supplier_name}}\n失败原因：{{reason}}\n订单已转为人工处理，请及时跟进。",
+				},
+				ZHTW: NotificationLocalizedTemplate{
+					Title: "供應商介面交付失敗",
+					Body:  "訂單號：{{order_no}}\n供應商：{{supplier_name}}\n失敗原因：{{reason}}\n訂單已轉為人工處理，請及時跟進。",
+				},
+				ENUS: NotificationLocalizedTemplate{
+					Title: "Supplier Fulfillment Failed",
+					Body:  "Order No: {{order_no}}\nSupplier: {{supplier_name}}\nReason: {{reason}}\nThe order has been moved to manual handling.",
+				},
+			},
 			ExceptionAlert: NotificationSceneTemplate{
 				ZHCN: NotificationLocalizedTemplate{
 					Title: "系统异常告警",
@@ -263,12 +301,16 @@ func NotificationCenterSettingToMap(setting NotificationCenterSetting) map[strin
 			"wallet_recharge_success":    normalized.Scenes.WalletRechargeSuccess,
 			"order_paid_success":         normalized.Scenes.OrderPaidSuccess,
 			"manual_fulfillment_pending": normalized.Scenes.ManualFulfillmentPending,
+			"order_message_received":     normalized.Scenes.OrderMessageReceived,
+			"supplier_fulfill_failed":    normalized.Scenes.SupplierFulfillFailed,
 			"exception_alert":            normalized.Scenes.ExceptionAlert,
 		},
 		"templates": map[string]interface{}{
 			"wallet_recharge_success":    notificationSceneTemplateToMap(normalized.Templates.WalletRechargeSuccess),
 			"order_paid_success":         notificationSceneTemplateToMap(normalized.Templates.OrderPaidSuccess),
 			"manual_fulfillment_pending": notificationSceneTemplateToMap(normalized.Templates.ManualFulfillmentPending),
+			"order_message_received":     notificationSceneTemplateToMap(normalized.Templates.OrderMessageReceived),
+			"supplier_fulfill_failed":    notificationSceneTemplateToMap(normalized.Templates.SupplierFulfillFailed),
 			"exception_alert":            notificationSceneTemplateToMap(normalized.Templates.ExceptionAlert),
 		},
 		"dedupe_ttl_seconds": normalized.DedupeTTLSeconds,
@@ -337,6 +379,12 @@ func (s *SettingService) PatchNotificationCenterSetting(patch NotificationCenter
 		if patch.Scenes.ManualFulfillmentPending != nil {
 			next.Scenes.ManualFulfillmentPending = *patch.Scenes.ManualFulfillmentPending
 		}
+		if patch.Scenes.OrderMessageReceived != nil {
+			next.Scenes.OrderMessageReceived = *patch.Scenes.OrderMessageReceived
+		}
+		if patch.Scenes.SupplierFulfillFailed != nil {
+			next.Scenes.SupplierFulfillFailed = *patch.Scenes.SupplierFulfillFailed
+		}
 		if patch.Scenes.ExceptionAlert != nil {
 			next.Scenes.ExceptionAlert = *patch.Scenes.ExceptionAlert
 		}
@@ -351,6 +399,12 @@ func (s *SettingService) PatchNotificationCenterSetting(patch NotificationCenter
 		if patch.Templates.ManualFulfillmentPending != nil {
 			applyNotificationSceneTemplatePatch(&next.Templates.ManualFulfillmentPending, patch.Templates.ManualFulfillmentPending)
 		}
+		if patch.Templates.OrderMessageReceived != nil {
+			applyNotificationSceneTemplatePatch(&next.Templates.OrderMessageReceived, patch.Templates.OrderMessageReceived)
+		}
+		if patch.Templates.SupplierFulfillFailed != nil {
+			applyNotificationSceneTemplatePatch(&next.Templates.SupplierFulfillFailed, patch.Templates.SupplierFulfillFailed)
+		}
 		if patch.Templates.ExceptionAlert != nil {
 			applyNotificationSceneTemplatePatch(&next.Templates.ExceptionAlert, patch.Templates.ExceptionAlert)
 		}
@@ -375,6 +429,10 @@ func (s NotificationSceneSetting) IsSceneEnabled(eventType string) bool {
 		return s.OrderPaidSuccess
 	case constants.NotificationEventManualFulfillmentPending:
 		return s.ManualFulfillmentPending
+	case constants.NotificationEventOrderMessageReceived:
+		return s.OrderMessageReceived
+	case constants.NotificationEventSupplierFulfillFailed:
+		return s.SupplierFulfillFailed
 	case constants.NotificationEventExceptionAlert, constants.NotificationEventExceptionAlertCheck:
 		return s.ExceptionAlert
 	default:
@@ -391,6 +449,10 @@ func (s NotificationTemplatesSetting) TemplateByEvent(eventType string) Notifica
 		return s.OrderPaidSuccess
 	case constants.NotificationEventManualFulfillmentPending:
 		return s.ManualFulfillmentPending
+	case constants.NotificationEventOrderMessageReceived:
+		return s.OrderMessageReceived
+	case constants.NotificationEventSupplierFulfillFailed:
+		return s.SupplierFulfillFailed
 	case constants.NotificationEventExceptionAlert, constants.NotificationEventExceptionAlertCheck:
 		return s.ExceptionAlert
 	default:
@@ -435,6 +497,8 @@ func notificationCenterSettingFromJSON(raw models.JSON, fallback NotificationCen
 		next.Scenes.WalletRechargeSuccess = readBool(scenesMap, "wallet_recharge_success", next.Scenes.WalletRechargeSuccess)
 		next.Scenes.OrderPaidSuccess = readBool(scenesMap, "order_paid_success", next.Scenes.OrderPaidSuccess)
 		next.Scenes.ManualFulfillmentPending = readBool(scenesMap, "manual_fulfillment_pending", next.Scenes.ManualFulfillmentPending)
+		next.Scenes.OrderMessageReceived = readBool(scenesMap, "order_message_received", next.Scenes.OrderMessageReceived)
+		next.Scenes.SupplierFulfillFailed = readBool(scenesMap, "supplier_fulfill_failed", next.Scenes.SupplierFulfillFailed)
 		next.Scenes.ExceptionAlert = readBool(scenesMap, "exception_alert", next.Scenes.ExceptionAlert)
 	}
 
@@ -448,6 +512,12 @@ func notificationCenterSettingFromJSON(raw models.JSON, fallback NotificationCen
 		if sceneMap := toStringAnyMap(templatesMap["manual_fulfillment_pending"]); sceneMap != nil {
 			next.Templates.ManualFulfillmentPending = notificationSceneTemplateFromMap(sceneMap, next.Templates.ManualFulfillmentPending)
 		}
+		if sceneMap := toStringAnyMap(templatesMap["order_message_received"]); sceneMap != nil {
+			next.Templates.OrderMessageReceived = notificationSceneTemplateFromMap(sceneMap, next.Templates.OrderMessageReceived)
+		}
+		if sceneMap := toStringAnyMap(templatesMap["supplier_fulfill_failed"]); sceneMap != nil {
+			next.Templates.SupplierFulfillFailed = notificationSceneTemplateFromMap(sceneMap, next.Templates.SupplierFulfillFailed)
+		}
 		if sceneMap := toStringAnyMap(templatesMap["exception_alert"]); sceneMap != nil {
 			next.Templates.ExceptionAlert = notificationSceneTemplateFromMap(sceneMap, next.Templates.ExceptionAlert)
 		}
@@ -502,6 +572,8 @@ func normalizeNotificationTemplates(templates NotificationTemplatesSetting) Noti
 	templates.WalletRechargeSuccess = normalizeNotificationSceneTemplate(templates.WalletRechargeSuccess)
 	templates.OrderPaidSuccess = normalizeNotificationSceneTemplate(templates.OrderPaidSuccess)
 	templates.ManualFulfillmentPending = normalizeNotificationSceneTemplate(templates.ManualFulfillmentPending)
+	templates.OrderMessageReceived = normalizeNotificationSceneTemplate(templates.OrderMessageReceived)
+	templates.SupplierFulfillFailed = normalizeNotificationSceneTemplate(templates.SupplierFulfillFailed)
 	templates.ExceptionAlert = normalizeNotificationSceneTemplate(templates.ExceptionAlert)
 	return templates
 }
@@ -272,6 +272,8 @@ func isNotificationEventSupported(eventType string) bool {
 	case constants.NotificationEventWalletRechargeSuccess,
 		constants.NotificationEventOrderPaidSuccess,
 		constants.NotificationEventManualFulfillmentPending,
+		constants.NotificationEventOrderMessageReceived,
+		constants.NotificationEventSupplierFulfillFailed,
 		constants.NotificationEventExceptionAlert,
 		constants.NotificationEventExceptionAlertCheck:
 		return true
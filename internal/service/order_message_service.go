@@ -0,0 +1,501 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dujiao-next/internal/config"
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/i18n"
+	"github.com/dujiao-next/internal/logger"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/queue"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+const (
+	orderMessageContentMaxRune     = 2000
+	orderMessageAttachmentMaxCount = 5
+	orderMessagePreviewMaxRune     = 200
+	orderMessageAttachmentPrefix   = "order_message"
+)
+
+// OrderMessagePostInput 发送订单沟通消息参数
+type OrderMessagePostInput struct {
+	Content string
+	Files   []*multipart.FileHeader
+}
+
+// OrderMessageAttachmentInput 读取订单消息附件参数（签名链接）
+type OrderMessageAttachmentInput struct {
+	MessageID uint
+	Index     int
+	Expires   int64
+	Signature string
+}
+
+// OrderMessageService 订单沟通消息服务
+type OrderMessageService struct {
+	orderRepo       repository.OrderRepository
+	messageRepo     repository.OrderMessageRepository
+	userRepo        repository.UserRepository
+	identityRepo    repository.UserOAuthIdentityRepository
+	uploadService   *UploadService
+	downloadService *DownloadService
+	emailService    *EmailService
+	notificationSvc *NotificationService
+	telegramSender  *TelegramNotifyService
+	queueClient     *queue.Client
+}
+
+// NewOrderMessageService 创建订单沟通消息服务
+func NewOrderMessageService(
+	orderRepo repository.OrderRepository,
+	messageRepo repository.OrderMessageRepository,
+	userRepo repository.UserRepository,
+	identityRepo repository.UserOAuthIdentityRepository,
+	uploadService *UploadService,
+	emailService *EmailService,
+	notificationSvc *NotificationService,
+	settingService *SettingService,
+	defaultTelegramCfg config.TelegramAuthConfig,
+	queueClient *queue.Client,
+) *OrderMessageService {
+	return &OrderMessageService{
+		orderRepo:       orderRepo,
+		messageRepo:     messageRepo,
+		userRepo:        userRepo,
+		identityRepo:    identityRepo,
+		uploadService:   uploadService,
+		emailService:    emailService,
+		notificationSvc: notificationSvc,
+		telegramSender:  NewTelegramNotifyService(settingService, defaultTelegramCfg),
+		queueClient:     queueClient,
+	}
+}
+
+// SetDownloadService 设置签名链接服务（附件访问地址依赖）
+func (s *OrderMessageService) SetDownloadService(downloadService *DownloadService) {
+	s.downloadService = downloadService
+}
+
+// ListForUser 用户查看订单消息（同时清零买家未读）
+func (s *OrderMessageService) ListForUser(userID, orderID uint, page, pageSize int) ([]models.OrderMessage, int64, error) {
+	order, err := s.orderRepo.GetByIDAndUser(orderID, userID)
+	if err != nil {
+		return nil, 0, ErrOrderFetchFailed
+	}
+	if order == nil {
+		return nil, 0, ErrOrderNotFound
+	}
+	return s.listAndMarkRead(order, false, page, pageSize)
+}
+
+// PostByUser 用户发送订单消息
+func (s *OrderMessageService) PostByUser(userID, orderID uint, input OrderMessagePostInput) (*models.OrderMessage, error) {
+	order, err := s.orderRepo.GetByIDAndUser(orderID, userID)
+	if err != nil {
+		return nil, ErrOrderFetchFailed
+	}
+	if order == nil {
+		return nil, ErrOrderNotFound
+	}
+	return s.post(order, constants.OrderMessageSenderUser, userID, input)
+}
+
+// ListForGuest 游客查看订单消息
+func (s *OrderMessageService) ListForGuest(email, password string, orderID uint, page, pageSize int) ([]models.OrderMessage, int64, error) {
+	order, err := s.resolveGuestOrder(email, password, orderID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.listAndMarkRead(order, false, page, pageSize)
+}
+
+// PostByGuest 游客发送订单消息
+func (s *OrderMessageService) PostByGuest(email, password string, orderID uint, input OrderMessagePostInput) (*models.OrderMessage, error) {
+	order, err := s.resolveGuestOrder(email, password, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.post(order, constants.OrderMessageSenderGuest, 0, input)
+}
+
+// ListForAdmin 管理员查看订单消息（同时清零管理员未读）
+func (s *OrderMessageService) ListForAdmin(orderID uint, page, pageSize int) ([]models.OrderMessage, int64, error) {
+	order, err := s.resolveRootOrder(orderID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.listAndMarkRead(order, true, page, pageSize)
+}
+
+// PostByAdmin 管理员回复订单消息
+func (s *OrderMessageService) PostByAdmin(adminID, orderID uint, input OrderMessagePostInput) (*models.OrderMessage, error) {
+	order, err := s.resolveRootOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.post(order, constants.OrderMessageSenderAdmin, adminID, input)
+}
+
+// ListUserThreads 用户会话列表（含未读数）
+func (s *OrderMessageService) ListUserThreads(userID uint, unreadOnly bool, page, pageSize int) ([]models.OrderMessageThread, int64, error) {
+	if userID == 0 {
+		return nil, 0, ErrOrderNotFound
+	}
+	threads, total, err := s.messageRepo.ListThreads(repository.OrderMessageThreadListFilter{
+		Page:       page,
+		PageSize:   pageSize,
+		UnreadOnly: unreadOnly,
+		UserID:     userID,
+	})
+	if err != nil {
+		return nil, 0, ErrOrderMessageFetchFailed
+	}
+	return threads, total, nil
+}
+
+// ListAdminThreads 管理端会话列表（含未读数）
+func (s *OrderMessageService) ListAdminThreads(unreadOnly bool, page, pageSize int) ([]models.OrderMessageThread, int64, error) {
+	threads, total, err := s.messageRepo.ListThreads(repository.OrderMessageThreadListFilter{
+		Page:       page,
+		PageSize:   pageSize,
+		UnreadOnly: unreadOnly,
+	})
+	if err != nil {
+		return nil, 0, ErrOrderMessageFetchFailed
+	}
+	return threads, total, nil
+}
+
+// NotifyBuyer 向买家推送管理员回复（邮件 + Telegram），由异步任务调用
+func (s *OrderMessageService) NotifyBuyer(ctx context.Context, messageID uint) error {
+	message, err := s.messageRepo.GetByID(messageID)
+	if err != nil {
+		return err
+	}
+	if message == nil || message.SenderType != constants.OrderMessageSenderAdmin {
+		return nil
+	}
+	order, err := s.orderRepo.GetByID(message.OrderID)
+	if err != nil {
+		return err
+	}
+	if order == nil {
+		return nil
+	}
+
+	receiverEmail := strings.TrimSpace(order.GuestEmail)
+	locale := strings.TrimSpace(order.GuestLocale)
+	if order.UserID != 0 {
+		user, err := s.userRepo.GetByID(order.UserID)
+		if err != nil {
+			return err
+		}
+		if user != nil {
+			receiverEmail = strings.TrimSpace(user.Email)
+			locale = strings.TrimSpace(user.Locale)
+		}
+	}
+	locale = normalizeLocale(locale)
+	subject := i18n.Sprintf(locale, "email.order_message.subject", order.OrderNo)
+	body := i18n.Sprintf(locale, "email.order_message.body", order.OrderNo, buildOrderMessagePreview(message))
+
+	var firstErr error
+	if receiverEmail != "" && !isTelegramPlaceholderEmail(receiverEmail) && s.emailService != nil {
+		if err := s.emailService.SendCustomEmail(receiverEmail, subject, body); err != nil {
+			logger.Warnw("order_message_notify_email_failed", "order_id", order.ID, "message_id", message.ID, "error", err)
+			firstErr = err
+		}
+	}
+	if order.UserID != 0 && s.identityRepo != nil && s.telegramSender != nil {
+		identity, err := s.identityRepo.GetByUserProvider(order.UserID, constants.UserOAuthProviderTelegram)
+		if err != nil {
+			logger.Warnw("order_message_notify_fetch_identity_failed", "order_id", order.ID, "error", err)
+		} else if identity != nil && strings.TrimSpace(identity.ProviderUserID) != "" {
+			if err := s.telegramSender.SendMessage(ctx, identity.ProviderUserID, composeTelegramMessage(subject, body)); err != nil {
+				logger.Warnw("order_message_notify_telegram_failed", "order_id", order.ID, "message_id", message.ID, "error", err)
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+		}
+	}
+	return firstErr
+}
+
+func (s *OrderMessageService) resolveGuestOrder(email, password string, orderID uint) (*models.Order, error) {
+	email = strings.TrimSpace(email)
+	password = strings.TrimSpace(password)
+	if email == "" {
+		return nil, ErrGuestEmailRequired
+	}
+	if password == "" {
+		return nil, ErrGuestPasswordRequired
+	}
+	order, err := s.orderRepo.GetByIDAndGuest(orderID, email, password)
+	if err != nil {
+		return nil, ErrOrderFetchFailed
+	}
+	if order == nil {
+		return nil, ErrGuestOrderNotFound
+	}
+	return order, nil
+}
+
+// resolveRootOrder 管理端可传入子订单，统一归到父订单会话
+func (s *OrderMessageService) resolveRootOrder(orderID uint) (*models.Order, error) {
+	if orderID == 0 {
+		return nil, ErrOrderNotFound
+	}
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, ErrOrderFetchFailed
+	}
+	if order == nil {
+		return nil, ErrOrderNotFound
+	}
+	if order.ParentID != nil && *order.ParentID > 0 {
+		parent, err := s.orderRepo.GetByID(*order.ParentID)
+		if err != nil {
+			return nil, ErrOrderFetchFailed
+		}
+		if parent == nil {
+			return nil, ErrOrderNotFound
+		}
+		return parent, nil
+	}
+	return order, nil
+}
+
+func (s *OrderMessageService) listAndMarkRead(order *models.Order, readerIsAdmin bool, page, pageSize int) ([]models.OrderMessage, int64, error) {
+	if !orderHasManualItem(order) {
+		return nil, 0, ErrOrderMessageNotAllowed
+	}
+	messages, total, err := s.messageRepo.ListByOrder(order.ID, page, pageSize)
+	if err != nil {
+		return nil, 0, ErrOrderMessageFetchFailed
+	}
+	if err := s.messageRepo.MarkRead(order.ID, readerIsAdmin); err != nil {
+		logger.Warnw("order_message_mark_read_failed", "order_id", order.ID, "error", err)
+	}
+	for i := range messages {
+		s.applyAttachmentURLs(&messages[i])
+	}
+	return messages, total, nil
+}
+
+func (s *OrderMessageService) post(order *models.Order, senderType string, senderID uint, input OrderMessagePostInput) (*models.OrderMessage, error) {
+	if !orderHasManualItem(order) {
+		return nil, ErrOrderMessageNotAllowed
+	}
+	if isOrderMessageClosed(order) {
+		return nil, ErrOrderMessageClosed
+	}
+	content := strings.TrimSpace(input.Content)
+	if len([]rune(content)) > orderMessageContentMaxRune {
+		return nil, ErrOrderMessageInvalid
+	}
+	if len(input.Files) > orderMessageAttachmentMaxCount {
+		return nil, ErrOrderMessageInvalid
+	}
+	if content == "" && len(input.Files) == 0 {
+		return nil, ErrOrderMessageInvalid
+	}
+
+	attachments, err := s.saveAttachments(input.Files)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	message := &models.OrderMessage{
+		OrderID:     order.ID,
+		SenderType:  senderType,
+		SenderID:    senderID,
+		Content:     content,
+		Attachments: attachments,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.messageRepo.Transaction(func(tx *gorm.DB) error {
+		repo := s.messageRepo.WithTx(tx)
+		if err := repo.Create(message); err != nil {
+			return err
+		}
+		return repo.TouchThread(order.ID, order.OrderNo, senderType, now)
+	}); err != nil {
+		s.removeAttachments(attachments)
+		return nil, ErrOrderMessageCreateFailed
+	}
+
+	s.enqueueNotification(order, message)
+	s.applyAttachmentURLs(message)
+	return message, nil
+}
+
+// ResolveAttachment 校验签名链接后返回附件的私有存储路径
+func (s *OrderMessageService) ResolveAttachment(input OrderMessageAttachmentInput) (*DownloadFile, error) {
+	if s.downloadService == nil || s.uploadService == nil {
+		return nil, ErrDownloadUnavailable
+	}
+	if err := s.downloadService.VerifyOrderMessageAttachment(input.MessageID, input.Index, input.Expires, input.Signature); err != nil {
+		return nil, err
+	}
+	message, err := s.messageRepo.GetByID(input.MessageID)
+	if err != nil {
+		return nil, ErrOrderMessageFetchFailed
+	}
+	if message == nil || input.Index >= len(message.Attachments) {
+		return nil, ErrDownloadUnavailable
+	}
+	key := strings.TrimSpace(message.Attachments[input.Index])
+	if key == "" {
+		return nil, ErrDownloadUnavailable
+	}
+	path, err := s.uploadService.ResolvePrivatePath(key)
+	if err != nil {
+		return nil, ErrDownloadUnavailable
+	}
+	if _, err := os.Stat(path); err != nil {
+		logger.Warnw("order_message_attachment_stat_failed", "message_id", message.ID, "index", input.Index, "error", err)
+		return nil, ErrDownloadUnavailable
+	}
+	return &DownloadFile{Path: path, Name: filepath.Base(path)}, nil
+}
+
+// saveAttachments 附件保存到私有存储，任一失败时清理已保存的文件
+func (s *OrderMessageService) saveAttachments(files []*multipart.FileHeader) (models.StringArray, error) {
+	attachments := make(models.StringArray, 0, len(files))
+	for _, file := range files {
+		if file == nil {
+			continue
+		}
+		if s.uploadService == nil {
+			return nil, ErrOrderMessageAttachmentFailed
+		}
+		saved, err := s.uploadService.SavePrivateUpload(file, orderMessageAttachmentPrefix)
+		if err != nil {
+			s.removeAttachments(attachments)
+			return nil, fmt.Errorf("%w: %v", ErrOrderMessageAttachmentFailed, err)
+		}
+		attachments = append(attachments, saved.StorageKey)
+	}
+	return attachments, nil
+}
+
+func (s *OrderMessageService) removeAttachments(keys models.StringArray) {
+	if s.uploadService == nil {
+		return
+	}
+	for _, key := range keys {
+		if err := s.uploadService.RemovePrivateFile(key); err != nil {
+			logger.Warnw("order_message_attachment_cleanup_failed", "key", key, "error", err)
+		}
+	}
+}
+
+// applyAttachmentURLs 为附件生成短期签名地址
+func (s *OrderMessageService) applyAttachmentURLs(message *models.OrderMessage) {
+	if message == nil {
+		return
+	}
+	urls := make([]string, 0, len(message.Attachments))
+	for i, key := range message.Attachments {
+		key = strings.TrimSpace(key)
+		switch {
+		case key == "":
+			continue
+		case s.downloadService != nil:
+			urls = append(urls, s.downloadService.SignOrderMessageAttachmentURL(message.ID, i))
+		}
+	}
+	message.AttachmentURLs = urls
+}
+
+func (s *OrderMessageService) enqueueNotification(order *models.Order, message *models.OrderMessage) {
+	if message.SenderType == constants.OrderMessageSenderAdmin {
+		if s.queueClient == nil {
+			return
+		}
+		if err := s.queueClient.EnqueueOrderMessageNotify(queue.OrderMessageNotifyPayload{MessageID: message.ID}, asynq.MaxRetry(3)); err != nil {
+			logger.Warnw("order_message_enqueue_notify_failed", "order_id", order.ID, "message_id", message.ID, "error", err)
+		}
+		return
+	}
+	if s.notificationSvc == nil {
+		return
+	}
+	if err := s.notificationSvc.Enqueue(NotificationEnqueueInput{
+		EventType: constants.NotificationEventOrderMessageReceived,
+		BizType:   constants.NotificationBizTypeOrder,
+		BizID:     order.ID,
+		Locale:    strings.TrimSpace(order.GuestLocale),
+		Force:     true,
+		Data: models.JSON{
+			"order_id":    fmt.Sprintf("%d", order.ID),
+			"order_no":    strings.TrimSpace(order.OrderNo),
+			"user_id":     fmt.Sprintf("%d", order.UserID),
+			"guest_email": strings.TrimSpace(order.GuestEmail),
+			"message":     buildOrderMessagePreview(message),
+		},
+	}); err != nil {
+		logger.Warnw("order_message_enqueue_admin_notify_failed", "order_id", order.ID, "message_id", message.ID, "error", err)
+	}
+}
+
+// orderHasManualItem 仅包含人工交付商品的订单开放沟通
+func orderHasManualItem(order *models.Order) bool {
+	if order == nil {
+		return false
+	}
+	for _, item := range order.Items {
+		if strings.TrimSpace(item.FulfillmentType) == constants.FulfillmentTypeManual {
+			return true
+		}
+	}
+	for _, child := range order.Children {
+		for _, item := range child.Items {
+			if strings.TrimSpace(item.FulfillmentType) == constants.FulfillmentTypeManual {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isOrderMessageClosed(order *models.Order) bool {
+	switch strings.TrimSpace(order.Status) {
+	case constants.OrderStatusCompleted, constants.OrderStatusCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
+func buildOrderMessagePreview(message *models.OrderMessage) string {
+	if message == nil {
+		return ""
+	}
+	content := strings.TrimSpace(message.Content)
+	runes := []rune(content)
+	if len(runes) > orderMessagePreviewMaxRune {
+		content = string(runes[:orderMessagePreviewMaxRune]) + "..."
+	}
+	if len(message.Attachments) > 0 {
+		if content != "" {
+			content += "\n"
+		}
+		content += fmt.Sprintf("[attachments: %d]", len(message.Attachments))
+	}
+	return content
+}
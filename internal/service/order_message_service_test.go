@@ -0,0 +1,247 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dujiao-next/internal/config"
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/glebarez/sqlite"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+func setupOrderMessageServiceTest(t *testing.T) (*gorm.DB, *OrderMessageService, repository.OrderMessageRepository) {
+	t.Helper()
+	dsn := fmt.Sprintf("file:order_message_service_test_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&models.Order{},
+		&models.OrderItem{},
+		&models.Fulfillment{},
+		&models.OrderMessage{},
+		&models.OrderMessageThread{},
+	); err != nil {
+		t.Fatalf("auto migrate failed: %v", err)
+	}
+	models.DB = db
+	messageRepo := repository.NewOrderMessageRepository(db)
+	svc := &OrderMessageService{
+		orderRepo:   repository.NewOrderRepository(db),
+		messageRepo: messageRepo,
+	}
+	return db, svc, messageRepo
+}
+
+func createOrderMessageTestOrder(t *testing.T, db *gorm.DB, orderNo, fulfillmentType string) *models.Order {
+	t.Helper()
+	amount := models.NewMoneyFromDecimal(decimal.NewFromInt(10))
+	parent := &models.Order{
+		OrderNo:        orderNo,
+		UserID:         1,
+		Status:         constants.OrderStatusPaid,
+		Currency:       "CNY",
+		OriginalAmount: amount,
+		TotalAmount:    amount,
+	}
+	if err := db.Create(parent).Error; err != nil {
+		t.Fatalf("create parent order failed: %v", err)
+	}
+	child := &models.Order{
+		OrderNo:        orderNo + "-01",
+		ParentID:       &parent.ID,
+		UserID:         1,
+		Status:         constants.OrderStatusPaid,
+		Currency:       "CNY",
+		OriginalAmount: amount,
+		TotalAmount:    amount,
+	}
+	if err := db.Create(child).Error; err != nil {
+		t.Fatalf("create child order failed: %v", err)
+	}
+	item := &models.OrderItem{
+		OrderID:         child.ID,
+		ProductID:       1,
+		TitleJSON:       models.JSON{"zh-CN": "人工商品"},
+		UnitPrice:       amount,
+		Quantity:        1,
+		TotalPrice:      amount,
+		FulfillmentType: fulfillmentType,
+	}
+	if err := db.Create(item).Error; err != nil {
+		t.Fatalf("create order item failed: %v", err)
+	}
+	return parent
+}
+
+func TestOrderMessageServiceUnreadCounters(t *testing.T) {
+	db, svc, messageRepo := setupOrderMessageServiceTest(t)
+	order := createOrderMessageTestOrder(t, db, "DJ-MSG-001", constants.FulfillmentTypeManual)
+
+	if _, err := svc.PostByUser(1, order.ID, OrderMessagePostInput{Content: "请问什么时候发货？"}); err != nil {
+		t.Fatalf("post by user failed: %v", err)
+	}
+	thread, err := messageRepo.GetThread(order.ID)
+	if err != nil || thread == nil {
+		t.Fatalf("get thread failed: %v", err)
+	}
+	if thread.AdminUnreadCount != 1 || thread.BuyerUnreadCount != 0 || thread.MessageCount != 1 {
+		t.Fatalf("unexpected counters after user post: %+v", thread)
+	}
+
+	messages, total, err := svc.ListForAdmin(order.ID, 1, 20)
+	if err != nil {
+		t.Fatalf("list for admin failed: %v", err)
+	}
+	if total != 1 || len(messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", total)
+	}
+	if _, err := svc.PostByAdmin(9, order.ID, OrderMessagePostInput{Content: "今天内处理"}); err != nil {
+		t.Fatalf("post by admin failed: %v", err)
+	}
+	thread, _ = messageRepo.GetThread(order.ID)
+	if thread.AdminUnreadCount != 0 || thread.BuyerUnreadCount != 1 || thread.MessageCount != 2 {
+		t.Fatalf("unexpected counters after admin post: %+v", thread)
+	}
+	if thread.LastSenderType != constants.OrderMessageSenderAdmin {
+		t.Fatalf("expected last sender admin, got %s", thread.LastSenderType)
+	}
+
+	threads, unreadTotal, err := svc.ListUserThreads(1, true, 1, 20)
+	if err != nil || unreadTotal != 1 || len(threads) != 1 {
+		t.Fatalf("expected 1 unread thread for user, got %d err=%v", unreadTotal, err)
+	}
+	if _, _, err := svc.ListForUser(1, order.ID, 1, 20); err != nil {
+		t.Fatalf("list for user failed: %v", err)
+	}
+	thread, _ = messageRepo.GetThread(order.ID)
+	if thread.BuyerUnreadCount != 0 {
+		t.Fatalf("expected buyer unread cleared, got %d", thread.BuyerUnreadCount)
+	}
+}
+
+func TestOrderMessageServiceRejectsClosedOrNonManual(t *testing.T) {
+	db, svc, _ := setupOrderMessageServiceTest(t)
+	autoOrder := createOrderMessageTestOrder(t, db, "DJ-MSG-002", constants.FulfillmentTypeAuto)
+	if _, err := svc.PostByUser(1, autoOrder.ID, OrderMessagePostInput{Content: "hi"}); !errors.Is(err, ErrOrderMessageNotAllowed) {
+		t.Fatalf("expected ErrOrderMessageNotAllowed, got %v", err)
+	}
+
+	order := createOrderMessageTestOrder(t, db, "DJ-MSG-003", constants.FulfillmentTypeManual)
+	if _, err := svc.PostByUser(1, order.ID, OrderMessagePostInput{Content: "   "}); !errors.Is(err, ErrOrderMessageInvalid) {
+		t.Fatalf("expected ErrOrderMessageInvalid, got %v", err)
+	}
+	if err := db.Model(&models.Order{}).Where("id = ?", order.ID).Update("status", constants.OrderStatusCompleted).Error; err != nil {
+		t.Fatalf("update order status failed: %v", err)
+	}
+	if _, err := svc.PostByUser(1, order.ID, OrderMessagePostInput{Content: "还在吗"}); !errors.Is(err, ErrOrderMessageClosed) {
+		t.Fatalf("expected ErrOrderMessageClosed, got %v", err)
+	}
+	if _, err := svc.PostByAdmin(9, order.ID, OrderMessagePostInput{Content: "已完成"}); !errors.Is(err, ErrOrderMessageClosed) {
+		t.Fatalf("expected ErrOrderMessageClosed for admin, got %v", err)
+	}
+	if _, _, err := svc.ListForUser(1, order.ID, 1, 20); err != nil {
+		t.Fatalf("history should stay readable after completion: %v", err)
+	}
+}
+
+func TestOrderMessageServicePrivateAttachments(t *testing.T) {
+	db, svc, messageRepo := setupOrderMessageServiceTest(t)
+	cfg := &config.Config{
+		Upload:   config.UploadConfig{MaxSize: 1024 * 1024},
+		Download: config.DownloadConfig{StorageDir: t.TempDir(), SigningSecret: "test-download-secret"},
+	}
+	svc.uploadService = NewUploadService(cfg)
+	svc.SetDownloadService(NewDownloadService(cfg, nil, nil, nil, nil, svc.uploadService))
+	order := createOrderMessageTestOrder(t, db, "DJ-MSG-004", constants.FulfillmentTypeManual)
+
+	message, err := svc.PostByUser(1, order.ID, OrderMessagePostInput{
+		Files: []*multipart.FileHeader{newOrderMessageTestFile(t, "note.txt", "private attachment")},
+	})
+	if err != nil {
+		t.Fatalf("post with attachment failed: %v", err)
+	}
+	if len(message.Attachments) != 1 || !strings.HasPrefix(message.Attachments[0], orderMessageAttachmentPrefix+"/") {
+		t.Fatalf("expected private storage key, got %v", message.Attachments)
+	}
+	if len(message.AttachmentURLs) != 1 || !strings.HasPrefix(message.AttachmentURLs[0], "/api/v1/public/order-message-attachments/") {
+		t.Fatalf("expected signed attachment url, got %v", message.AttachmentURLs)
+	}
+
+	parsed, err := url.Parse(message.AttachmentURLs[0])
+	if err != nil {
+		t.Fatalf("parse attachment url failed: %v", err)
+	}
+	expires, _ := strconv.ParseInt(parsed.Query().Get("expires"), 10, 64)
+	input := OrderMessageAttachmentInput{
+		MessageID: message.ID,
+		Index:     0,
+		Expires:   expires,
+		Signature: parsed.Query().Get("sig"),
+	}
+	file, err := svc.ResolveAttachment(input)
+	if err != nil {
+		t.Fatalf("resolve attachment failed: %v", err)
+	}
+	content, err := os.ReadFile(file.Path)
+	if err != nil || string(content) != "private attachment" {
+		t.Fatalf("unexpected attachment content %q err=%v", content, err)
+	}
+
+	tampered := input
+	tampered.Index = 1
+	if _, err := svc.ResolveAttachment(tampered); !errors.Is(err, ErrDownloadLinkInvalid) {
+		t.Fatalf("expected ErrDownloadLinkInvalid for tampered index, got %v", err)
+	}
+	tampered = input
+	tampered.Expires = expires + 60
+	if _, err := svc.ResolveAttachment(tampered); !errors.Is(err, ErrDownloadLinkInvalid) {
+		t.Fatalf("expected ErrDownloadLinkInvalid for tampered expires, got %v", err)
+	}
+
+	stored, err := messageRepo.GetByID(message.ID)
+	if err != nil || stored == nil {
+		t.Fatalf("get message failed: %v", err)
+	}
+	if stored.Attachments[0] != message.Attachments[0] {
+		t.Fatalf("expected stored storage key %s, got %s", message.Attachments[0], stored.Attachments[0])
+	}
+}
+
+func newOrderMessageTestFile(t *testing.T, name, content string) *multipart.FileHeader {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("files", name)
+	if err != nil {
+		t.Fatalf("create form file failed: %v", err)
+	}
+	if _, err := part.Write([]byte(content)); err != nil {
+		t.Fatalf("write form file failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close multipart writer failed: %v", err)
+	}
+	form, err := multipart.NewReader(body, writer.Boundary()).ReadForm(1024 * 1024)
+	if err != nil {
+		t.Fatalf("read multipart form failed: %v", err)
+	}
+	t.Cleanup(func() { _ = form.RemoveAll() })
+	return form.File["files"][0]
+}
@@ -33,6 +33,8 @@ type OrderService struct {
 	settingService  *SettingService
 	walletService   *WalletService
 	affiliateSvc    *AffiliateService
+	memberLevelSvc  *MemberLevelService
+	categoryRepo    repository.CategoryRepository
 	expireMinutes   int
 }
 
@@ -54,6 +56,16 @@ func NewOrderService(orderRepo repository.OrderRepository, productRepo repositor
 	}
 }
 
+// SetMemberLevelService 设置会员等级服务，下单时按用户等级计价并在支付后刷新累计消费
+func (s *OrderService) SetMemberLevelService(memberLevelSvc *MemberLevelService) {
+	s.memberLevelSvc = memberLevelSvc
+}
+
+// SetCategoryRepository 设置分类仓库（优惠券分类范围）
+func (s *OrderService) SetCategoryRepository(categoryRepo repository.CategoryRepository) {
+	s.categoryRepo = categoryRepo
+}
+
 // CreateOrderInput 创建订单输入
 type CreateOrderInput struct {
 	UserID              uint
@@ -84,6 +96,7 @@ type CreateOrderItem struct {
 	SKUID           uint
 	Quantity        int
 	FulfillmentType string
+	AccessCode      string // 访问码保护商品的访问码
 }
 
 // childOrderPlan 子订单计划数据
@@ -192,6 +205,7 @@ type OrderPreviewItem struct {
 	SKUID             uint               `json:"sku_id"`
 	TitleJSON         models.JSON        `json:"title"`
 	SKUSnapshotJSON   models.JSON        `json:"sku_snapshot"`
+	PricingSnapshot   models.JSON        `json:"pricing_snapshot,omitempty"`
 	Tags              models.StringArray `json:"tags"`
 	UnitPrice         models.Money       `json:"unit_price"`
 	Quantity          int                `json:"quantity"`
@@ -258,6 +272,7 @@ func (s *OrderService) previewOrder(input orderCreateParams) (*OrderPreview, err
 			SKUID:             item.SKUID,
 			TitleJSON:         item.TitleJSON,
 			SKUSnapshotJSON:   item.SKUSnapshotJSON,
+			PricingSnapshot:   item.PricingSnapshotJSON,
 			Tags:              item.Tags,
 			UnitPrice:         item.UnitPrice,
 			Quantity:          item.Quantity,
@@ -386,7 +401,7 @@ func (s *OrderService) createOrder(input orderCreateParams) (*models.Order, erro
 				return err
 			}
 
-			if strings.TrimSpace(plan.Item.FulfillmentType) == constants.FulfillmentTypeAuto {
+			if strings.TrimSpace(plan.Item.FulfillmentType) == constants.FulfillmentTypeAuto && !HasKeyGenerator(plan.Product) {
 				if s.cardSecretRepo == nil {
 					return ErrCardSecretInsufficient
 				}
@@ -522,6 +537,13 @@ func (s *OrderService) buildOrderResult(input orderCreateParams) (*orderBuildRes
 	var noPromotionSeen bool
 
 	promotionService := NewPromotionService(s.promotionRepo)
+	var memberPricing *MemberPricing
+	if !input.IsGuest && s.memberLevelSvc != nil {
+		memberPricing, err = s.memberLevelSvc.ResolvePricing(input.UserID)
+		if err != nil {
+			return nil, err
+		}
+	}
 	manualFormData := input.ManualFormData
 	if manualFormData == nil {
 		manualFormData = map[string]models.JSON{}
@@ -534,9 +556,12 @@ func (s *OrderService) buildOrderResult(input orderCreateParams) (*orderBuildRes
 		if err != nil {
 			return nil, err
 		}
-		if product == nil || !product.IsActive {
+		if !product.IsPublishedAt(time.Now()) {
 			return nil, ErrProductNotAvailable
 		}
+		if err := CheckProductAccess(product, item.AccessCode); err != nil {
+			return nil, err
+		}
 		purchaseType := strings.TrimSpace(product.PurchaseType)
 		if purchaseType == "" {
 			purchaseType = constants.ProductPurchaseMember
@@ -550,9 +575,7 @@ func (s *OrderService) buildOrderResult(input orderCreateParams) (*orderBuildRes
 		}
 
 		productCurrency := currency
-		priceCarrier := *product
-		priceCarrier.PriceAmount = sku.PriceAmount
-		promotion, unitPrice, err := promotionService.ApplyPromotion(&priceCarrier, item.Quantity)
+		tierPrice, unitPrice, promotion, err := resolveSKUUnitPrice(promotionService, memberPricing, product, sku, item.Quantity)
 		if err != nil {
 			return nil, err
 		}
@@ -561,7 +584,7 @@ func (s *OrderService) buildOrderResult(input orderCreateParams) (*orderBuildRes
 			return nil, ErrProductPriceInvalid
 		}
 
-		basePrice := sku.PriceAmount.Decimal.Round(2)
+		basePrice := tierPrice.Decimal.Round(2)
 		promotionDiscount := decimal.Zero
 		if promotion != nil && basePrice.GreaterThan(unitPriceAmount) {
 			promotionDiscount = basePrice.Sub(unitPriceAmount).
@@ -577,7 +600,10 @@ func (s *OrderService) buildOrderResult(input orderCreateParams) (*orderBuildRes
 		if fulfillmentType == "" {
 			fulfillmentType = constants.FulfillmentTypeManual
 		}
-		if fulfillmentType != constants.FulfillmentTypeManual && fulfillmentType != constants.FulfillmentTypeAuto {
+		if !isSupportedFulfillmentType(fulfillmentType) {
+			return nil, ErrFulfillmentInvalid
+		}
+		if fulfillmentType == constants.FulfillmentTypeAPI && product.SupplierID == nil {
 			return nil, ErrFulfillmentInvalid
 		}
 		if fulfillmentType == constants.FulfillmentTypeManual &&
@@ -613,15 +639,11 @@ func (s *OrderService) buildOrderResult(input orderCreateParams) (*orderBuildRes
 		}
 
 		orderItem := models.OrderItem{
-			ProductID: product.ID,
-			SKUID:     sku.ID,
-			TitleJSON: product.TitleJSON,
-			SKUSnapshotJSON: models.JSON{
-				"sku_id":      sku.ID,
-				"sku_code":    sku.SKUCode,
-				"spec_values": sku.SpecValuesJSON,
-				"image":       firstProductImage(product.Images),
-			},
+			ProductID:                    product.ID,
+			SKUID:                        sku.ID,
+			TitleJSON:                    product.TitleJSON,
+			SKUSnapshotJSON:              buildOrderSKUSnapshot(product, sku),
+			PricingSnapshotJSON:          buildOrderPricingSnapshot(sku, item.Quantity, memberPricing),
 			Tags:                         product.Tags,
 			UnitPrice:                    models.NewMoneyFromDecimal(unitPriceAmount),
 			Quantity:                     item.Quantity,
@@ -632,6 +654,9 @@ func (s *OrderService) buildOrderResult(input orderCreateParams) (*orderBuildRes
 			FulfillmentType:              fulfillmentType,
 			ManualFormSchemaSnapshotJSON: manualSchemaSnapshot,
 			ManualFormSubmissionJSON:     manualSubmission,
+			DeliveryTmplSnapshotJSON:     resolveDeliveryTemplateSnapshot(product, sku),
+			RevealRequired:               product.RevealRequired,
+			RevealMaxViews:               product.RevealMaxViews,
 			CreatedAt:                    now,
 			UpdatedAt:                    now,
 		}
@@ -656,19 +681,42 @@ func (s *OrderService) buildOrderResult(input orderCreateParams) (*orderBuildRes
 
 	discountAmount := decimal.Zero
 	var appliedCoupon *models.Coupon
+	var appliedScope *couponScope
 	couponCode := strings.TrimSpace(input.CouponCode)
 	if couponCode != "" {
 		couponService := NewCouponService(s.couponRepo, s.couponUsageRepo)
-		discount, coupon, err := couponService.ApplyCoupon(models.NewMoneyFromDecimal(originalAmount), couponCode, input.UserID, orderItems)
+		couponService.SetOrderRepository(s.orderRepo)
+		couponService.SetCategoryRepository(s.categoryRepo)
+		buyer := CouponBuyer{UserID: input.UserID, GuestEmail: input.GuestEmail}
+		if memberPricing != nil && memberPricing.Level != nil {
+			buyer.MemberLevelID = memberPricing.Level.ID
+		}
+		couponItems := make([]CouponItem, 0, len(plans))
+		for _, plan := range plans {
+			couponItems = append(couponItems, CouponItem{
+				ProductID:  plan.Item.ProductID,
+				SKUID:      plan.Item.SKUID,
+				CategoryID: plan.Product.CategoryID,
+				TotalPrice: models.NewMoneyFromDecimal(plan.TotalAmount),
+			})
+		}
+		discount, coupon, err := couponService.ApplyCoupon(models.NewMoneyFromDecimal(originalAmount), couponCode, buyer, couponItems)
 		if err != nil {
 			return nil, err
 		}
 		discountAmount = discount.Decimal.Round(2)
 		appliedCoupon = coupon
+		if discountAmount.GreaterThan(decimal.Zero) {
+			scope, err := couponService.resolveScope(coupon)
+			if err != nil {
+				return nil, err
+			}
+			appliedScope = scope
+		}
 	}
 
-	if appliedCoupon != nil && discountAmount.GreaterThan(decimal.Zero) {
-		if err := applyCouponDiscountToItems(plans, appliedCoupon, discountAmount); err != nil {
+	if appliedScope != nil {
+		if err := applyCouponDiscountToItems(plans, appliedScope, discountAmount); err != nil {
 			return nil, err
 		}
 		discountAmount = decimal.Zero
@@ -706,6 +754,35 @@ func (s *OrderService) buildOrderResult(input orderCreateParams) (*orderBuildRes
 	}, nil
 }
 
+// buildOrderSKUSnapshot SKU 快照（编码/规格/图片），会随评价公开展示，不得写入定价信息
+func buildOrderSKUSnapshot(product *models.Product, sku *models.ProductSKU) models.JSON {
+	return models.JSON{
+		"sku_id":      sku.ID,
+		"sku_code":    sku.SKUCode,
+		"spec_values": sku.SpecValuesJSON,
+		"image":       firstProductImage(product.Images),
+	}
+}
+
+// buildOrderPricingSnapshot 定价快照，命中阶梯价或会员价时记录原价、起购数量与会员等级，否则为空
+func buildOrderPricingSnapshot(sku *models.ProductSKU, quantity int, memberPricing *MemberPricing) models.JSON {
+	snapshot := models.JSON{}
+	tierPrice, tierQuantity := ResolveSKUTierPrice(sku, quantity)
+	if tierQuantity > 1 {
+		snapshot["list_price"] = sku.PriceAmount.String()
+		snapshot["tier_min_quantity"] = tierQuantity
+	}
+	if memberPrice, ok := memberPricing.apply(sku.ID, tierPrice); ok {
+		snapshot["list_price"] = sku.PriceAmount.String()
+		snapshot["member_level"] = memberPricing.Level.Code
+		snapshot["member_price"] = memberPrice.String()
+	}
+	if len(snapshot) == 0 {
+		return nil
+	}
+	return snapshot
+}
+
 func normalizeGuestEmail(raw string) (string, error) {
 	normalized := strings.ToLower(strings.TrimSpace(raw))
 	if normalized == "" {
@@ -770,7 +847,7 @@ func (s *OrderService) resolveOrderSKU(product *models.Product, rawSKUID uint) (
 		if err != nil {
 			return nil, err
 		}
-		if sku == nil || sku.ProductID != product.ID || !sku.IsActive {
+		if sku == nil || sku.ProductID != product.ID || !sku.IsPublishedAt(time.Now()) {
 			return nil, ErrProductSKUInvalid
 		}
 		return sku, nil
@@ -1046,6 +1123,14 @@ func (s *OrderService) UpdateOrderStatus(orderID uint, targetStatus string) (*mo
 					)
 				}
 			}
+			if s.memberLevelSvc != nil {
+				if err := s.memberLevelSvc.HandleOrderPaid(order.ID); err != nil {
+					logger.Warnw("member_level_handle_order_paid_failed",
+						"order_id", order.ID,
+						"error", err,
+					)
+				}
+			}
 			return order, nil
 		case constants.OrderStatusCompleted:
 			if !canCompleteParentOrder(order) {
@@ -1121,6 +1206,14 @@ func (s *OrderService) UpdateOrderStatus(orderID uint, targetStatus string) (*mo
 			)
 		}
 	}
+	if target == constants.OrderStatusPaid && s.memberLevelSvc != nil {
+		if err := s.memberLevelSvc.HandleOrderPaid(order.ID); err != nil {
+			logger.Warnw("member_level_handle_order_paid_failed",
+				"order_id", order.ID,
+				"error", err,
+			)
+		}
+	}
 	if target == constants.OrderStatusCanceled && s.affiliateSvc != nil {
 		if err := s.affiliateSvc.HandleOrderCanceled(order.ID, "order_canceled_by_admin"); err != nil {
 			logger.Warnw("affiliate_handle_order_canceled_failed",
@@ -1357,35 +1450,35 @@ func mergeCreateOrderItems(items []CreateOrderItem) ([]CreateOrderItem, error) {
 		key := buildOrderItemKey(item.ProductID, item.SKUID)
 		if idx, ok := indexMap[key]; ok {
 			merged[idx].Quantity += item.Quantity
+			if merged[idx].AccessCode == "" {
+				merged[idx].AccessCode = item.AccessCode
+			}
 			continue
 		}
 		indexMap[key] = len(merged)
 		merged = append(merged, CreateOrderItem{
-			ProductID: item.ProductID,
-			SKUID:     item.SKUID,
-			Quantity:  item.Quantity,
+			ProductID:  item.ProductID,
+			SKUID:      item.SKUID,
+			Quantity:   item.Quantity,
+			AccessCode: item.AccessCode,
 		})
 	}
 	return merged, nil
 }
 
 // applyCouponDiscountToItems 分摊优惠券折扣到订单项
-func applyCouponDiscountToItems(plans []childOrderPlan, coupon *models.Coupon, discountAmount decimal.Decimal) error {
-	if coupon == nil || discountAmount.LessThanOrEqual(decimal.Zero) {
+func applyCouponDiscountToItems(plans []childOrderPlan, scope *couponScope, discountAmount decimal.Decimal) error {
+	if scope == nil || discountAmount.LessThanOrEqual(decimal.Zero) {
 		return nil
 	}
-	scopeType := strings.ToLower(strings.TrimSpace(coupon.ScopeType))
-	if scopeType != constants.ScopeTypeProduct {
-		return ErrCouponScopeInvalid
-	}
-	ids, err := decodeScopeIDs(coupon.ScopeRefIDs)
-	if err != nil {
-		return ErrCouponScopeInvalid
-	}
 	eligibleIndexes := make([]int, 0, len(plans))
 	eligibleTotal := decimal.Zero
 	for i := range plans {
-		if _, ok := ids[plans[i].Item.ProductID]; !ok {
+		var categoryID uint
+		if plans[i].Product != nil {
+			categoryID = plans[i].Product.CategoryID
+		}
+		if !scope.matches(plans[i].Item.ProductID, plans[i].Item.SKUID, categoryID) {
 			continue
 		}
 		eligibleIndexes = append(eligibleIndexes, i)
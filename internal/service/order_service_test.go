@@ -61,7 +61,11 @@ func TestApplyCouponDiscountToItems(t *testing.T) {
 		ScopeType:   constants.ScopeTypeProduct,
 		ScopeRefIDs: "[1,2]",
 	}
-	if err := applyCouponDiscountToItems(plans, coupon, decimal.NewFromInt(30)); err != nil {
+	scope, err := NewCouponService(nil, nil).resolveScope(coupon)
+	if err != nil {
+		t.Fatalf("resolveScope error: %v", err)
+	}
+	if err := applyCouponDiscountToItems(plans, scope, decimal.NewFromInt(30)); err != nil {
 		t.Fatalf("applyCouponDiscountToItems error: %v", err)
 	}
 	if !plans[0].CouponDiscount.Equal(decimal.NewFromInt(20)) {
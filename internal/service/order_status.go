@@ -42,6 +42,7 @@ func calcParentStatus(children []models.Order, currentStatus string) string {
 		return currentStatus
 	}
 	var deliveredCount int
+	var partialCount int
 	var completedCount int
 	var canceledCount int
 	var paidCount int
@@ -55,6 +56,8 @@ func calcParentStatus(children []models.Order, currentStatus string) string {
 			completedCount++
 		case constants.OrderStatusDelivered:
 			deliveredCount++
+		case constants.OrderStatusPartiallyDelivered:
+			partialCount++
 		case constants.OrderStatusPaid:
 			paidCount++
 		case constants.OrderStatusFulfilling:
@@ -72,7 +75,7 @@ func calcParentStatus(children []models.Order, currentStatus string) string {
 	if deliveredCount+completedCount == len(children) {
 		return constants.OrderStatusDelivered
 	}
-	if deliveredCount+completedCount > 0 {
+	if deliveredCount+completedCount+partialCount > 0 {
 		return constants.OrderStatusPartiallyDelivered
 	}
 	if fulfillingCount > 0 {
@@ -3,6 +3,7 @@ package service
 import (
 	"strings"
 
+	"github.com/dujiao-next/internal/constants"
 	"github.com/dujiao-next/internal/queue"
 	"github.com/dujiao-next/internal/repository"
 )
@@ -29,7 +30,8 @@ func enqueueOrderStatusEmailTaskIfEligible(orderRepo repository.OrderRepository,
 		if receiverEmail == "" {
 			return true, nil
 		}
-		if isTelegramPlaceholderEmail(receiverEmail) {
+		// Telegram 占位邮箱不发邮件，但交付类状态仍需入队以便 worker 推送 Telegram 交付消息
+		if isTelegramPlaceholderEmail(receiverEmail) && !isDeliveryNotifyStatus(status) {
 			return true, nil
 		}
 	}
@@ -42,3 +44,11 @@ func enqueueOrderStatusEmailTaskIfEligible(orderRepo repository.OrderRepository,
 	}
 	return false, nil
 }
+
+func isDeliveryNotifyStatus(status string) bool {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case constants.OrderStatusDelivered, constants.OrderStatusPartiallyDelivered, constants.OrderStatusCompleted:
+		return true
+	}
+	return false
+}
@@ -109,3 +109,26 @@ func TestEnqueueOrderStatusEmailTaskIfEligibleFallbackWhenLookupFailed(t *testin
 		t.Fatalf("expected fallback enqueue when receiver lookup failed")
 	}
 }
+
+func TestEnqueueOrderStatusEmailTaskIfEligibleKeepTelegramPlaceholderDelivery(t *testing.T) {
+	queueClient, err := queue.NewClient(nil)
+	if err != nil {
+		t.Fatalf("new queue client failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = queueClient.Close()
+	})
+
+	skipped, err := enqueueOrderStatusEmailTaskIfEligible(
+		orderStatusEmailOrderRepoStub{receiver: "telegram_123@login.local"},
+		queueClient,
+		105,
+		"delivered",
+	)
+	if err != nil {
+		t.Fatalf("enqueue helper returned error: %v", err)
+	}
+	if skipped {
+		t.Fatalf("expected delivered task kept for telegram placeholder email")
+	}
+}
@@ -44,6 +44,7 @@ type PaymentService struct {
 	expireMinutes   int
 	affiliateSvc    *AffiliateService
 	notificationSvc *NotificationService
+	memberLevelSvc  *MemberLevelService
 }
 
 // NewPaymentService 创建支付服务
@@ -77,6 +78,11 @@ func NewPaymentService(
 	}
 }
 
+// SetMemberLevelService 设置会员等级服务，支付成功后刷新用户累计消费与等级
+func (s *PaymentService) SetMemberLevelService(memberLevelSvc *MemberLevelService) {
+	s.memberLevelSvc = memberLevelSvc
+}
+
 // CreatePaymentInput 创建支付请求
 type CreatePaymentInput struct {
 	OrderID    uint
@@ -1293,6 +1299,9 @@ func shouldAutoFulfill(order *models.Order) bool {
 	if order == nil || len(order.Items) == 0 {
 		return false
 	}
+	if isFileFulfillOrder(order) {
+		return true
+	}
 	for _, item := range order.Items {
 		if strings.TrimSpace(item.FulfillmentType) != constants.FulfillmentTypeAuto {
 			return false
@@ -474,6 +474,15 @@ func (s *PaymentService) enqueueOrderPaidAsync(order *models.Order, payment *mod
 			)
 		}
 	}
+	if s.memberLevelSvc != nil {
+		if err := s.memberLevelSvc.HandleOrderPaid(order.ID); err != nil {
+			log.Warnw("member_level_handle_order_paid_failed",
+				"order_id", order.ID,
+				"order_no", order.OrderNo,
+				"error", err,
+			)
+		}
+	}
 	if s.queueClient != nil {
 		if _, err := enqueueOrderStatusEmailTaskIfEligible(s.orderRepo, s.queueClient, order.ID, constants.OrderStatusPaid); err != nil {
 			log.Warnw("payment_enqueue_status_email_failed",
@@ -507,6 +516,18 @@ func (s *PaymentService) enqueueOrderPaidAsync(order *models.Order, payment *mod
 					)
 				}
 			}
+			if isAPIFulfillOrder(&child) {
+				if err := s.queueClient.EnqueueOrderAPIFulfill(queue.OrderAPIFulfillPayload{
+					OrderID: child.ID,
+				}, asynq.MaxRetry(3)); err != nil {
+					log.Warnw("payment_enqueue_api_fulfill_failed",
+						"order_id", order.ID,
+						"child_order_id", child.ID,
+						"order_no", order.OrderNo,
+						"error", err,
+					)
+				}
+			}
 		}
 		return
 	}
@@ -524,6 +545,17 @@ func (s *PaymentService) enqueueOrderPaidAsync(order *models.Order, payment *mod
 			)
 		}
 	}
+	if isAPIFulfillOrder(order) {
+		if err := s.queueClient.EnqueueOrderAPIFulfill(queue.OrderAPIFulfillPayload{
+			OrderID: order.ID,
+		}, asynq.MaxRetry(3)); err != nil {
+			log.Warnw("payment_enqueue_api_fulfill_failed",
+				"order_id", order.ID,
+				"order_no", order.OrderNo,
+				"error", err,
+			)
+		}
+	}
 }
 
 func (s *PaymentService) enqueueOrderPaidNotificationAsync(order *models.Order, payment *models.Payment, log *zap.SugaredLogger) {
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/dujiao-next/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// productPriceTierMax 单个 SKU 最多阶梯数
+const productPriceTierMax = 10
+
+// PriceTierInput 阶梯价输入
+type PriceTierInput struct {
+	MinQuantity int
+	PriceAmount decimal.Decimal
+}
+
+// normalizePriceTiers 校验阶梯价：起购数量 >= 2 且不重复，单价随数量递增而不升高且不高于 SKU 基础价
+func normalizePriceTiers(basePrice decimal.Decimal, inputs []PriceTierInput) (models.PriceTiers, error) {
+	if len(inputs) == 0 {
+		return models.PriceTiers{}, nil
+	}
+	if len(inputs) > productPriceTierMax {
+		return nil, ErrProductPriceTierInvalid
+	}
+	sorted := make([]PriceTierInput, len(inputs))
+	copy(sorted, inputs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].MinQuantity < sorted[j].MinQuantity
+	})
+
+	tiers := make(models.PriceTiers, 0, len(sorted))
+	prevQuantity := 1
+	prevPrice := basePrice.Round(2)
+	for _, input := range sorted {
+		price := input.PriceAmount.Round(2)
+		if input.MinQuantity <= prevQuantity || price.LessThanOrEqual(decimal.Zero) || price.GreaterThan(prevPrice) {
+			return nil, ErrProductPriceTierInvalid
+		}
+		tiers = append(tiers, models.PriceTier{MinQuantity: input.MinQuantity, PriceAmount: models.NewMoneyFromDecimal(price)})
+		prevQuantity = input.MinQuantity
+		prevPrice = price
+	}
+	return tiers, nil
+}
+
+// ResolveSKUTierPrice 返回 SKU 在指定购买数量下的阶梯单价及命中的起购数量（未命中阶梯时为基础价与 1）
+func ResolveSKUTierPrice(sku *models.ProductSKU, quantity int) (models.Money, int) {
+	if sku == nil {
+		return models.Money{}, 1
+	}
+	price := sku.PriceAmount
+	minQuantity := 1
+	for _, tier := range sku.PriceTiers {
+		if tier.MinQuantity > minQuantity && quantity >= tier.MinQuantity && tier.PriceAmount.Decimal.GreaterThan(decimal.Zero) {
+			price = tier.PriceAmount
+			minQuantity = tier.MinQuantity
+		}
+	}
+	return price, minQuantity
+}
+
+// resolveSKUUnitPrice 先按数量确定阶梯基础价并叠加会员价，再叠加商品活动；活动价不低于该基础价时不使用活动
+func resolveSKUUnitPrice(promotionService *PromotionService, memberPricing *MemberPricing, product *models.Product, sku *models.ProductSKU, quantity int) (models.Money, models.Money, *models.Promotion, error) {
+	tierPrice, tierQuantity := ResolveSKUTierPrice(sku, quantity)
+	basePrice, memberApplied := memberPricing.apply(sku.ID, tierPrice)
+	if promotionService == nil {
+		return basePrice, basePrice, nil, nil
+	}
+	priceCarrier := *product
+	priceCarrier.PriceAmount = basePrice
+	promotion, unitPrice, err := promotionService.ApplyPromotion(&priceCarrier, quantity)
+	if err != nil {
+		return models.Money{}, models.Money{}, nil, err
+	}
+	if promotion != nil && (tierQuantity > 1 || memberApplied) && !unitPrice.Decimal.LessThan(basePrice.Decimal) {
+		return basePrice, basePrice, nil, nil
+	}
+	return basePrice, unitPrice, promotion, nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/glebarez/sqlite"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+func TestNormalizePriceTiers(t *testing.T) {
+	base := decimal.NewFromInt(10)
+	tiers, err := normalizePriceTiers(base, []PriceTierInput{
+		{MinQuantity: 50, PriceAmount: decimal.NewFromInt(8)},
+		{MinQuantity: 10, PriceAmount: decimal.NewFromInt(9)},
+	})
+	if err != nil {
+		t.Fatalf("valid tiers rejected: %v", err)
+	}
+	if len(tiers) != 2 || tiers[0].MinQuantity != 10 || tiers[1].MinQuantity != 50 {
+		t.Fatalf("expected tiers sorted by quantity, got %+v", tiers)
+	}
+
+	invalid := [][]PriceTierInput{
+		{{MinQuantity: 1, PriceAmount: decimal.NewFromInt(9)}},
+		{{MinQuantity: 10, PriceAmount: decimal.NewFromInt(11)}},
+		{{MinQuantity: 10, PriceAmount: decimal.Zero}},
+		{{MinQuantity: 10, PriceAmount: decimal.NewFromInt(8)}, {MinQuantity: 50, PriceAmount: decimal.NewFromInt(9)}},
+		{{MinQuantity: 10, PriceAmount: decimal.NewFromInt(9)}, {MinQuantity: 10, PriceAmount: decimal.NewFromInt(8)}},
+	}
+	for i, inputs := range invalid {
+		if _, err := normalizePriceTiers(base, inputs); !errors.Is(err, ErrProductPriceTierInvalid) {
+			t.Fatalf("case %d: expected ErrProductPriceTierInvalid, got %v", i, err)
+		}
+	}
+
+	sku := &models.ProductSKU{PriceAmount: models.NewMoneyFromDecimal(base), PriceTiers: tiers}
+	for quantity, expected := range map[int]string{1: "10.00", 9: "10.00", 10: "9.00", 49: "9.00", 50: "8.00", 500: "8.00"} {
+		if price, _ := ResolveSKUTierPrice(sku, quantity); price.String() != expected {
+			t.Fatalf("quantity %d: expected %s, got %s", quantity, expected, price.String())
+		}
+	}
+}
+
+func TestBuildOrderResultAppliesPriceTiersWithPromotion(t *testing.T) {
+	dsn := fmt.Sprintf("file:order_service_price_tier_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Product{}, &models.ProductSKU{}, &models.Promotion{}); err != nil {
+		t.Fatalf("auto migrate failed: %v", err)
+	}
+
+	product := models.Product{
+		CategoryID:      1,
+		Slug:            "wholesale-codes",
+		TitleJSON:       models.JSON{"zh-CN": "批发兑换码"},
+		PriceAmount:     models.NewMoneyFromDecimal(decimal.NewFromInt(10)),
+		PurchaseType:    constants.ProductPurchaseMember,
+		FulfillmentType: constants.FulfillmentTypeManual,
+		IsActive:        true,
+	}
+	if err := db.Create(&product).Error; err != nil {
+		t.Fatalf("create product failed: %v", err)
+	}
+	sku := models.ProductSKU{
+		ProductID:        product.ID,
+		SKUCode:          models.DefaultSKUCode,
+		PriceAmount:      models.NewMoneyFromDecimal(decimal.NewFromInt(10)),
+		ManualStockTotal: constants.ManualStockUnlimited,
+		IsActive:         true,
+		PriceTiers: models.PriceTiers{
+			{MinQuantity: 10, PriceAmount: models.NewMoneyFromDecimal(decimal.NewFromInt(9))},
+			{MinQuantity: 50, PriceAmount: models.NewMoneyFromDecimal(decimal.NewFromInt(8))},
+		},
+	}
+	if err := db.Create(&sku).Error; err != nil {
+		t.Fatalf("create sku failed: %v", err)
+	}
+	if err := db.Create(&models.Promotion{
+		Name:       "special-8.50",
+		ScopeType:  constants.ScopeTypeProduct,
+		ScopeRefID: product.ID,
+		Type:       constants.PromotionTypeSpecialPrice,
+		Value:      models.NewMoneyFromDecimal(decimal.RequireFromString("8.50")),
+		MinAmount:  models.NewMoneyFromDecimal(decimal.Zero),
+		IsActive:   true,
+	}).Error; err != nil {
+		t.Fatalf("create promotion failed: %v", err)
+	}
+
+	svc := NewOrderService(nil, repository.NewProductRepository(db), repository.NewProductSKURepository(db), nil, nil, nil, repository.NewPromotionRepository(db), nil, nil, nil, nil, 15)
+	cases := []struct {
+		quantity      int
+		original      string
+		promotion     string
+		total         string
+		withPromotion bool
+	}{
+		{quantity: 2, original: "20.00", promotion: "3.00", total: "17.00", withPromotion: true},
+		{quantity: 10, original: "90.00", promotion: "5.00", total: "85.00", withPromotion: true},
+		{quantity: 50, original: "400.00", promotion: "0.00", total: "400.00", withPromotion: false},
+	}
+	for _, tc := range cases {
+		result, err := svc.buildOrderResult(orderCreateParams{
+			UserID: 1,
+			Items:  []CreateOrderItem{{ProductID: product.ID, SKUID: sku.ID, Quantity: tc.quantity}},
+		})
+		if err != nil {
+			t.Fatalf("quantity %d: buildOrderResult failed: %v", tc.quantity, err)
+		}
+		if result.OriginalAmount.StringFixed(2) != tc.original ||
+			result.PromotionDiscountAmount.StringFixed(2) != tc.promotion ||
+			result.TotalAmount.StringFixed(2) != tc.total {
+			t.Fatalf("quantity %d: unexpected amounts original=%s promotion=%s total=%s", tc.quantity,
+				result.OriginalAmount.StringFixed(2), result.PromotionDiscountAmount.StringFixed(2), result.TotalAmount.StringFixed(2))
+		}
+		if (result.OrderItems[0].PromotionID != nil) != tc.withPromotion {
+			t.Fatalf("quantity %d: unexpected promotion id %v", tc.quantity, result.OrderItems[0].PromotionID)
+		}
+	}
+}
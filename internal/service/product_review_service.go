@@ -0,0 +1,199 @@
+package service
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+)
+
+const (
+	productReviewContentMaxLen = 2000
+	productReviewReplyMaxLen   = 2000
+)
+
+// ProductReviewService 商品评价服务
+type ProductReviewService struct {
+	repo repository.ProductReviewRepository
+}
+
+// NewProductReviewService 创建商品评价服务
+func NewProductReviewService(repo repository.ProductReviewRepository) *ProductReviewService {
+	return &ProductReviewService{repo: repo}
+}
+
+// CreateProductReviewInput 买家提交评价输入
+type CreateProductReviewInput struct {
+	UserID      uint
+	OrderItemID uint
+	Rating      int
+	Content     string
+}
+
+// ProductRatingSummary 商品评分汇总
+type ProductRatingSummary struct {
+	RatingAverage float64 `json:"rating_average"`
+	ReviewCount   int64   `json:"review_count"`
+}
+
+// Create 买家对已交付完成的订单项提交评价，提交后待审核
+func (s *ProductReviewService) Create(input CreateProductReviewInput) (*models.ProductReview, error) {
+	if input.UserID == 0 || input.OrderItemID == 0 {
+		return nil, ErrProductReviewInvalid
+	}
+	if input.Rating < 1 || input.Rating > 5 {
+		return nil, ErrProductReviewInvalid
+	}
+	content := strings.TrimSpace(input.Content)
+	if utf8.RuneCountInString(content) > productReviewContentMaxLen {
+		return nil, ErrProductReviewInvalid
+	}
+
+	item, err := s.repo.GetReviewableItem(input.OrderItemID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrProductReviewNotAllowed
+	}
+	existing, err := s.repo.GetByOrderItemID(item.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrProductReviewExists
+	}
+
+	review := &models.ProductReview{
+		ProductID:   item.ProductID,
+		SKUID:       item.SKUID,
+		OrderID:     item.OrderID,
+		OrderItemID: item.ID,
+		UserID:      input.UserID,
+		Rating:      input.Rating,
+		Content:     content,
+		SKUSnapshot: PublicSKUSnapshot(item.SKUSnapshotJSON),
+		Status:      constants.ReviewStatusPending,
+	}
+	if err := s.repo.Create(review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// ListPublic 获取商品已公开的评价
+func (s *ProductReviewService) ListPublic(productID uint, page, pageSize int) ([]models.ProductReview, int64, error) {
+	return s.repo.List(repository.ProductReviewListFilter{
+		ProductID: productID,
+		Status:    constants.ReviewStatusPublished,
+		WithUser:  true,
+		Page:      page,
+		PageSize:  pageSize,
+	})
+}
+
+// ListByUser 获取用户自己的评价
+func (s *ProductReviewService) ListByUser(userID uint, page, pageSize int) ([]models.ProductReview, int64, error) {
+	if userID == 0 {
+		return nil, 0, ErrProductReviewInvalid
+	}
+	return s.repo.List(repository.ProductReviewListFilter{
+		UserID:   userID,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+// ListAdmin 后台评价列表
+func (s *ProductReviewService) ListAdmin(filter repository.ProductReviewListFilter) ([]models.ProductReview, int64, error) {
+	filter.WithUser = true
+	return s.repo.List(filter)
+}
+
+// UpdateStatus 审核评价（公开或隐藏）
+func (s *ProductReviewService) UpdateStatus(id uint, status string) (*models.ProductReview, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status != constants.ReviewStatusPublished && status != constants.ReviewStatusHidden && status != constants.ReviewStatusPending {
+		return nil, ErrProductReviewInvalid
+	}
+	review, err := s.getReview(id)
+	if err != nil {
+		return nil, err
+	}
+	review.Status = status
+	if err := s.repo.Update(review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// Reply 商家回复评价，空内容表示撤回回复
+func (s *ProductReviewService) Reply(id uint, reply string) (*models.ProductReview, error) {
+	reply = strings.TrimSpace(reply)
+	if utf8.RuneCountInString(reply) > productReviewReplyMaxLen {
+		return nil, ErrProductReviewInvalid
+	}
+	review, err := s.getReview(id)
+	if err != nil {
+		return nil, err
+	}
+	review.AdminReply = reply
+	if reply == "" {
+		review.RepliedAt = nil
+	} else {
+		now := time.Now()
+		review.RepliedAt = &now
+	}
+	if err := s.repo.Update(review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// Summaries 批量获取商品评分汇总（平均分保留一位小数）
+func (s *ProductReviewService) Summaries(productIDs []uint) (map[uint]ProductRatingSummary, error) {
+	rows, err := s.repo.SummaryByProductIDs(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[uint]ProductRatingSummary, len(rows))
+	for _, row := range rows {
+		result[row.ProductID] = ProductRatingSummary{
+			RatingAverage: math.Round(row.RatingAverage*10) / 10,
+			ReviewCount:   row.ReviewCount,
+		}
+	}
+	return result, nil
+}
+
+func (s *ProductReviewService) getReview(id uint) (*models.ProductReview, error) {
+	if id == 0 {
+		return nil, ErrProductReviewNotFound
+	}
+	review, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if review == nil {
+		return nil, ErrProductReviewNotFound
+	}
+	return review, nil
+}
+
+// PublicSKUSnapshot 评价中公开展示的 SKU 快照，仅保留规格与编码，过滤历史快照中的价格与会员信息
+func PublicSKUSnapshot(snapshot models.JSON) models.JSON {
+	if snapshot == nil {
+		return nil
+	}
+	result := models.JSON{}
+	for _, key := range []string{"sku_code", "spec_values"} {
+		if value, ok := snapshot[key]; ok {
+			result[key] = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestProductReviewVerifiedBuyerFlow(t *testing.T) {
+	dsn := fmt.Sprintf("file:product_review_service_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{DisableForeignKeyConstraintWhenMigrating: true})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Order{}, &models.OrderItem{}, &models.ProductReview{}); err != nil {
+		t.Fatalf("auto migrate failed: %v", err)
+	}
+
+	buyer := models.User{Email: "buyer@example.com", PasswordHash: "x", DisplayName: "buyer"}
+	other := models.User{Email: "other@example.com", PasswordHash: "x"}
+	if err := db.Create(&buyer).Error; err != nil {
+		t.Fatalf("create buyer failed: %v", err)
+	}
+	if err := db.Create(&other).Error; err != nil {
+		t.Fatalf("create user failed: %v", err)
+	}
+	completed := models.Order{OrderNo: "R-1", UserID: buyer.ID, Status: constants.OrderStatusCompleted}
+	pending := models.Order{OrderNo: "R-2", UserID: buyer.ID, Status: constants.OrderStatusPaid}
+	if err := db.Create(&completed).Error; err != nil {
+		t.Fatalf("create order failed: %v", err)
+	}
+	if err := db.Create(&pending).Error; err != nil {
+		t.Fatalf("create order failed: %v", err)
+	}
+	items := []models.OrderItem{
+		{OrderID: completed.ID, ProductID: 7, SKUID: 70, TitleJSON: models.JSON{"zh-CN": "A"}, Quantity: 1, FulfillmentType: constants.FulfillmentTypeManual},
+		{OrderID: pending.ID, ProductID: 7, SKUID: 70, TitleJSON: models.JSON{"zh-CN": "A"}, Quantity: 1, FulfillmentType: constants.FulfillmentTypeManual},
+	}
+	if err := db.Create(&items).Error; err != nil {
+		t.Fatalf("create order items failed: %v", err)
+	}
+
+	svc := NewProductReviewService(repository.NewProductReviewRepository(db))
+	if _, err := svc.Create(CreateProductReviewInput{UserID: buyer.ID, OrderItemID: items[0].ID, Rating: 6}); !errors.Is(err, ErrProductReviewInvalid) {
+		t.Fatalf("expected ErrProductReviewInvalid, got %v", err)
+	}
+	if _, err := svc.Create(CreateProductReviewInput{UserID: other.ID, OrderItemID: items[0].ID, Rating: 5}); !errors.Is(err, ErrProductReviewNotAllowed) {
+		t.Fatalf("expected ErrProductReviewNotAllowed for other user, got %v", err)
+	}
+	if _, err := svc.Create(CreateProductReviewInput{UserID: buyer.ID, OrderItemID: items[1].ID, Rating: 5}); !errors.Is(err, ErrProductReviewNotAllowed) {
+		t.Fatalf("expected ErrProductReviewNotAllowed for unfinished order, got %v", err)
+	}
+	review, err := svc.Create(CreateProductReviewInput{UserID: buyer.ID, OrderItemID: items[0].ID, Rating: 4, Content: " great "})
+	if err != nil {
+		t.Fatalf("create review failed: %v", err)
+	}
+	if review.Status != constants.ReviewStatusPending || review.ProductID != 7 || review.Content != "great" {
+		t.Fatalf("unexpected review: %+v", review)
+	}
+	if _, err := svc.Create(CreateProductReviewInput{UserID: buyer.ID, OrderItemID: items[0].ID, Rating: 5}); !errors.Is(err, ErrProductReviewExists) {
+		t.Fatalf("expected ErrProductReviewExists, got %v", err)
+	}
+
+	summaries, err := svc.Summaries([]uint{7})
+	if err != nil {
+		t.Fatalf("summaries failed: %v", err)
+	}
+	if summaries[7].ReviewCount != 0 {
+		t.Fatalf("pending review should not be counted, got %+v", summaries[7])
+	}
+	if _, err := svc.UpdateStatus(review.ID, constants.ReviewStatusPublished); err != nil {
+		t.Fatalf("publish review failed: %v", err)
+	}
+	if _, err := svc.Reply(review.ID, "thanks"); err != nil {
+		t.Fatalf("reply failed: %v", err)
+	}
+	summaries, err = svc.Summaries([]uint{7})
+	if err != nil {
+		t.Fatalf("summaries failed: %v", err)
+	}
+	if summaries[7].ReviewCount != 1 || summaries[7].RatingAverage != 4 {
+		t.Fatalf("unexpected summary: %+v", summaries[7])
+	}
+	reviews, total, err := svc.ListPublic(7, 1, 20)
+	if err != nil || total != 1 || reviews[0].User == nil || reviews[0].AdminReply != "thanks" {
+		t.Fatalf("unexpected public reviews total=%d err=%v", total, err)
+	}
+}
+
+func TestPublicSKUSnapshotDropsPricing(t *testing.T) {
+	snapshot := models.JSON{
+		"sku_id":       1,
+		"sku_code":     "VIP-1M",
+		"spec_values":  map[string]interface{}{"时长": "1个月"},
+		"list_price":   "20.00",
+		"member_level": "vip",
+		"member_price": "15.00",
+	}
+	got := PublicSKUSnapshot(snapshot)
+	if len(got) != 2 || got["sku_code"] != "VIP-1M" || got["spec_values"] == nil {
+		t.Fatalf("unexpected public sku snapshot: %+v", got)
+	}
+	if PublicSKUSnapshot(nil) != nil {
+		t.Fatalf("expected nil snapshot to stay nil")
+	}
+}
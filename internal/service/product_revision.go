@@ -0,0 +1,219 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/dujiao-next/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ProductRevisionChange 修订字段级差异（SKU 字段以 skus[<sku_code>].<field> 表示）
+type ProductRevisionChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// ProductRevisionDiff 两个修订之间的差异
+type ProductRevisionDiff struct {
+	RevisionID uint                    `json:"revision_id"`
+	AgainstID  uint                    `json:"against_id"` // 0 表示与空快照比较（首个修订）
+	Changes    []ProductRevisionChange `json:"changes"`
+}
+
+// ListRevisions 获取商品修订列表
+func (s *ProductService) ListRevisions(productID uint, page, pageSize int) ([]models.ProductRevision, int64, error) {
+	if s.revisionRepo == nil {
+		return []models.ProductRevision{}, 0, nil
+	}
+	return s.revisionRepo.ListByProduct(productID, page, pageSize)
+}
+
+// DiffRevisions 对比商品修订，againstID 为 0 时与该修订的上一版本比较
+func (s *ProductService) DiffRevisions(productID, revisionID, againstID uint) (*ProductRevisionDiff, error) {
+	revision, err := s.getProductRevision(productID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	var against *models.ProductRevision
+	if againstID > 0 {
+		if against, err = s.getProductRevision(productID, againstID); err != nil {
+			return nil, err
+		}
+	} else if against, err = s.revisionRepo.GetPrevious(productID, revision.ID); err != nil {
+		return nil, err
+	}
+
+	diff := &ProductRevisionDiff{RevisionID: revision.ID}
+	before := map[string]interface{}{}
+	if against != nil {
+		diff.AgainstID = against.ID
+		before = flattenRevisionSnapshot(against.Snapshot)
+	}
+	diff.Changes = diffRevisionFields(before, flattenRevisionSnapshot(revision.Snapshot))
+	return diff, nil
+}
+
+// RestoreRevision 将商品恢复到指定修订，恢复操作本身会生成一条新修订
+func (s *ProductService) RestoreRevision(productID, revisionID, adminID uint) (*models.Product, error) {
+	revision, err := s.getProductRevision(productID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	var record ProductTransferRecord
+	if err := decodeProductRevisionSnapshot(revision.Snapshot, &record); err != nil {
+		return nil, err
+	}
+	id := strconv.FormatUint(uint64(productID), 10)
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, ErrNotFound
+	}
+	input := buildProductInputFromRecord(record, existing)
+	input.AdminID = adminID
+	// 库存随订单实时变化，恢复时保留当前库存而非快照中的历史值
+	manualStockTotal := existing.ManualStockTotal
+	input.ManualStockTotal = &manualStockTotal
+	currentStock := make(map[uint]int, len(existing.SKUs))
+	for _, sku := range existing.SKUs {
+		currentStock[sku.ID] = sku.ManualStockTotal
+	}
+	for i := range input.SKUs {
+		if stock, ok := currentStock[input.SKUs[i].ID]; ok {
+			input.SKUs[i].ManualStockTotal = stock
+		}
+	}
+	return s.Update(id, input)
+}
+
+func (s *ProductService) getProductRevision(productID, revisionID uint) (*models.ProductRevision, error) {
+	if s.revisionRepo == nil || revisionID == 0 {
+		return nil, ErrProductRevisionNotFound
+	}
+	revision, err := s.revisionRepo.GetByID(revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if revision == nil || revision.ProductID != productID {
+		return nil, ErrProductRevisionNotFound
+	}
+	return revision, nil
+}
+
+// recordProductRevision 记录更新后的商品快照；商品首次产生修订时先补一条更新前的初始快照
+func (s *ProductService) recordProductRevision(tx *gorm.DB, productID uint, previous models.JSON, adminID uint) error {
+	if s.revisionRepo == nil {
+		return nil
+	}
+	revisionRepo := s.revisionRepo.WithTx(tx)
+	count, err := revisionRepo.CountByProduct(productID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		if err := revisionRepo.Create(&models.ProductRevision{ProductID: productID, Snapshot: previous}); err != nil {
+			return err
+		}
+	}
+	current, err := s.repo.WithTx(tx).GetByID(strconv.FormatUint(uint64(productID), 10))
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return ErrNotFound
+	}
+	snapshot, err := buildProductRevisionSnapshot(current)
+	if err != nil {
+		return err
+	}
+	return revisionRepo.Create(&models.ProductRevision{ProductID: productID, AdminID: adminID, Snapshot: snapshot})
+}
+
+// buildProductRevisionSnapshot 复用导出记录结构生成快照，保证恢复时与导入走同一套字段映射
+func buildProductRevisionSnapshot(product *models.Product) (models.JSON, error) {
+	raw, err := json.Marshal(buildProductTransferRecord(product))
+	if err != nil {
+		return nil, err
+	}
+	snapshot := models.JSON{}
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func decodeProductRevisionSnapshot(snapshot models.JSON, record *ProductTransferRecord) error {
+	raw, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, record)
+}
+
+// flattenRevisionSnapshot 展开快照为 字段路径 -> 值；对象逐层展开，数组整体比较，SKU 按编码展开
+func flattenRevisionSnapshot(snapshot models.JSON) map[string]interface{} {
+	fields := make(map[string]interface{})
+	for key, value := range snapshot {
+		if key != "skus" {
+			flattenRevisionValue(fields, key, value)
+			continue
+		}
+		skus, _ := value.([]interface{})
+		for _, item := range skus {
+			sku, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			prefix := fmt.Sprintf("skus[%v]", sku["sku_code"])
+			for skuKey, skuValue := range sku {
+				if skuKey == "sku_code" {
+					continue
+				}
+				flattenRevisionValue(fields, prefix+"."+skuKey, skuValue)
+			}
+		}
+	}
+	return fields
+}
+
+func flattenRevisionValue(fields map[string]interface{}, path string, value interface{}) {
+	nested, ok := value.(map[string]interface{})
+	if !ok || len(nested) == 0 {
+		fields[path] = value
+		return
+	}
+	for key, item := range nested {
+		flattenRevisionValue(fields, path+"."+key, item)
+	}
+}
+
+func diffRevisionFields(before, after map[string]interface{}) []ProductRevisionChange {
+	paths := make(map[string]struct{}, len(before)+len(after))
+	for path := range before {
+		paths[path] = struct{}{}
+	}
+	for path := range after {
+		paths[path] = struct{}{}
+	}
+	changes := make([]ProductRevisionChange, 0)
+	for path := range paths {
+		beforeValue, afterValue := before[path], after[path]
+		beforeRaw, _ := json.Marshal(beforeValue)
+		afterRaw, _ := json.Marshal(afterValue)
+		if string(beforeRaw) == string(afterRaw) {
+			continue
+		}
+		changes = append(changes, ProductRevisionChange{Field: path, Before: beforeValue, After: afterValue})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
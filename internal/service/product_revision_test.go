@@ -0,0 +1,102 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestProductRevisionDiffAndRestore(t *testing.T) {
+	db, svc := setupProductTransferTest(t)
+	if err := db.AutoMigrate(&models.ProductRevision{}); err != nil {
+		t.Fatalf("auto migrate revision failed: %v", err)
+	}
+	svc.SetRevisionRepository(repository.NewProductRevisionRepository(db))
+
+	manualStock := 5
+	input := CreateProductInput{
+		CategoryID:       1,
+		Slug:             "steam-wallet",
+		TitleJSON:        map[string]interface{}{"zh-CN": "Steam 钱包"},
+		PriceAmount:      decimal.NewFromInt(10),
+		FulfillmentType:  constants.FulfillmentTypeManual,
+		ManualStockTotal: &manualStock,
+		SKUs: []ProductSKUInput{
+			{SKUCode: "CN", PriceAmount: decimal.NewFromInt(10), ManualStockTotal: 3},
+		},
+	}
+	product, err := svc.Create(input)
+	if err != nil {
+		t.Fatalf("create product failed: %v", err)
+	}
+	id := strconv.FormatUint(uint64(product.ID), 10)
+
+	input.PriceAmount = decimal.NewFromInt(15)
+	input.SKUs[0].PriceAmount = decimal.NewFromInt(15)
+	input.AdminID = 7
+	if _, err := svc.Update(id, input); err != nil {
+		t.Fatalf("update product failed: %v", err)
+	}
+
+	revisions, total, err := svc.ListRevisions(product.ID, 1, 20)
+	if err != nil {
+		t.Fatalf("list revisions failed: %v", err)
+	}
+	if total != 2 || len(revisions) != 2 {
+		t.Fatalf("expected initial + updated revision, got %d", total)
+	}
+	latest, initial := revisions[0], revisions[1]
+	if latest.AdminID != 7 || initial.AdminID != 0 {
+		t.Fatalf("unexpected revision admin ids: latest=%d initial=%d", latest.AdminID, initial.AdminID)
+	}
+
+	diff, err := svc.DiffRevisions(product.ID, latest.ID, 0)
+	if err != nil {
+		t.Fatalf("diff revisions failed: %v", err)
+	}
+	if diff.AgainstID != initial.ID {
+		t.Fatalf("expected diff against previous revision %d, got %d", initial.ID, diff.AgainstID)
+	}
+	changed := make(map[string]bool, len(diff.Changes))
+	for _, change := range diff.Changes {
+		changed[change.Field] = true
+	}
+	if !changed["price_amount"] || !changed["skus[CN].price_amount"] {
+		t.Fatalf("expected price changes in diff, got %+v", diff.Changes)
+	}
+	if changed["slug"] {
+		t.Fatalf("unchanged field should not appear in diff: %+v", diff.Changes)
+	}
+
+	// 恢复前模拟订单扣减库存，恢复后应保留当前库存
+	if err := db.Model(&models.ProductSKU{}).Where("product_id = ? AND sku_code = ?", product.ID, "CN").
+		Update("manual_stock_total", 1).Error; err != nil {
+		t.Fatalf("update sku stock failed: %v", err)
+	}
+	restored, err := svc.RestoreRevision(product.ID, initial.ID, 9)
+	if err != nil {
+		t.Fatalf("restore revision failed: %v", err)
+	}
+	if !restored.PriceAmount.Equal(decimal.NewFromInt(10)) {
+		t.Fatalf("expected restored price 10, got %s", restored.PriceAmount)
+	}
+	var sku models.ProductSKU
+	if err := db.Where("product_id = ? AND sku_code = ?", product.ID, "CN").First(&sku).Error; err != nil {
+		t.Fatalf("load sku failed: %v", err)
+	}
+	if !sku.PriceAmount.Equal(decimal.NewFromInt(10)) || sku.ManualStockTotal != 1 {
+		t.Fatalf("unexpected restored sku: price=%s stock=%d", sku.PriceAmount, sku.ManualStockTotal)
+	}
+	if _, total, _ = svc.ListRevisions(product.ID, 1, 20); total != 3 {
+		t.Fatalf("expected restore to record a new revision, got %d", total)
+	}
+
+	if _, err := svc.RestoreRevision(product.ID+1, initial.ID, 9); err != ErrProductRevisionNotFound {
+		t.Fatalf("expected revision not found for other product, got %v", err)
+	}
+}
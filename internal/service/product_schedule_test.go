@@ -0,0 +1,130 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/glebarez/sqlite"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+func TestProductPublishWindow(t *testing.T) {
+	dsn := fmt.Sprintf("file:product_schedule_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Category{}, &models.Product{}, &models.ProductSKU{}, &models.Promotion{}); err != nil {
+		t.Fatalf("auto migrate failed: %v", err)
+	}
+
+	now := time.Now()
+	later := now.Add(time.Hour)
+	earlier := now.Add(-time.Hour)
+	newProduct := func(slug string, publishAt, unpublishAt *time.Time) models.Product {
+		product := models.Product{
+			CategoryID:      1,
+			Slug:            slug,
+			TitleJSON:       models.JSON{"zh-CN": slug},
+			PriceAmount:     models.NewMoneyFromDecimal(decimal.NewFromInt(10)),
+			PurchaseType:    constants.ProductPurchaseMember,
+			FulfillmentType: constants.FulfillmentTypeManual,
+			IsActive:        true,
+			PublishAt:       publishAt,
+			UnpublishAt:     unpublishAt,
+		}
+		if err := db.Create(&product).Error; err != nil {
+			t.Fatalf("create product failed: %v", err)
+		}
+		return product
+	}
+	newSKU := func(productID uint, code string, unpublishAt *time.Time) models.ProductSKU {
+		sku := models.ProductSKU{
+			ProductID:        productID,
+			SKUCode:          code,
+			PriceAmount:      models.NewMoneyFromDecimal(decimal.NewFromInt(10)),
+			ManualStockTotal: constants.ManualStockUnlimited,
+			IsActive:         true,
+			UnpublishAt:      unpublishAt,
+		}
+		if err := db.Create(&sku).Error; err != nil {
+			t.Fatalf("create sku failed: %v", err)
+		}
+		return sku
+	}
+
+	live := newProduct("live", &earlier, nil)
+	liveSKU := newSKU(live.ID, "A", nil)
+	expiredSKU := newSKU(live.ID, "B", &earlier)
+	scheduled := newProduct("scheduled", &later, nil)
+	scheduledSKU := newSKU(scheduled.ID, models.DefaultSKUCode, nil)
+	expired := newProduct("expired", nil, &earlier)
+	newSKU(expired.ID, models.DefaultSKUCode, nil)
+
+	productRepo := repository.NewProductRepository(db)
+	skuRepo := repository.NewProductSKURepository(db)
+	productSvc := NewProductService(productRepo, skuRepo, nil, nil)
+
+	products, total, err := productSvc.ListPublic("", "", 1, 20)
+	if err != nil {
+		t.Fatalf("list public failed: %v", err)
+	}
+	if total != 1 || products[0].ID != live.ID || len(products[0].SKUs) != 1 || products[0].SKUs[0].ID != liveSKU.ID {
+		t.Fatalf("expected only live product with live sku, total=%d products=%+v", total, products)
+	}
+	if _, err := productSvc.GetPublicBySlug("scheduled"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected scheduled product hidden, got %v", err)
+	}
+
+	adminProducts, _, err := productSvc.ListAdmin("", "", "", "", 1, 20)
+	if err != nil {
+		t.Fatalf("list admin failed: %v", err)
+	}
+	states := map[uint]string{}
+	for _, product := range adminProducts {
+		states[product.ID] = product.PublishState
+	}
+	if states[live.ID] != constants.ProductPublishStateLive ||
+		states[scheduled.ID] != constants.ProductPublishStateScheduled ||
+		states[expired.ID] != constants.ProductPublishStateExpired {
+		t.Fatalf("unexpected admin publish states: %+v", states)
+	}
+
+	orderSvc := NewOrderService(nil, productRepo, skuRepo, nil, nil, nil, repository.NewPromotionRepository(db), nil, nil, nil, nil, 15)
+	if _, err := orderSvc.buildOrderResult(orderCreateParams{
+		UserID: 1,
+		Items:  []CreateOrderItem{{ProductID: scheduled.ID, SKUID: scheduledSKU.ID, Quantity: 1}},
+	}); !errors.Is(err, ErrProductNotAvailable) {
+		t.Fatalf("expected ErrProductNotAvailable for scheduled product, got %v", err)
+	}
+	if _, err := orderSvc.buildOrderResult(orderCreateParams{
+		UserID: 1,
+		Items:  []CreateOrderItem{{ProductID: live.ID, SKUID: expiredSKU.ID, Quantity: 1}},
+	}); !errors.Is(err, ErrProductSKUInvalid) {
+		t.Fatalf("expected ErrProductSKUInvalid for expired sku, got %v", err)
+	}
+	if _, err := orderSvc.buildOrderResult(orderCreateParams{
+		UserID: 1,
+		Items:  []CreateOrderItem{{ProductID: live.ID, SKUID: liveSKU.ID, Quantity: 1}},
+	}); err != nil {
+		t.Fatalf("expected live sku orderable, got %v", err)
+	}
+
+	if _, err := productSvc.Create(CreateProductInput{
+		CategoryID:  1,
+		Slug:        "bad-window",
+		TitleJSON:   map[string]interface{}{"zh-CN": "bad"},
+		PriceAmount: decimal.NewFromInt(10),
+		PublishAt:   &later,
+		UnpublishAt: &earlier,
+	}); !errors.Is(err, ErrProductScheduleInvalid) {
+		t.Fatalf("expected ErrProductScheduleInvalid, got %v", err)
+	}
+}
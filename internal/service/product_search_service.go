@@ -0,0 +1,333 @@
+package service
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/shopspring/decimal"
+)
+
+// 商品搜索排序方式
+const (
+	ProductSearchSortRelevance = "relevance"
+	ProductSearchSortPriceAsc  = "price_asc"
+	ProductSearchSortPriceDesc = "price_desc"
+	ProductSearchSortNewest    = "newest"
+)
+
+// productPriceBucketBounds 价格分面区间下限，最后一档不设上限
+var productPriceBucketBounds = []int64{0, 10, 50, 100, 200, 500, 1000}
+
+// ProductSearchService 商品全文搜索与分面服务
+type ProductSearchService struct {
+	searchRepo   repository.ProductSearchRepository
+	categoryRepo repository.CategoryRepository
+}
+
+// NewProductSearchService 创建商品搜索服务
+func NewProductSearchService(searchRepo repository.ProductSearchRepository, categoryRepo repository.CategoryRepository) *ProductSearchService {
+	return &ProductSearchService{
+		searchRepo:   searchRepo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+// ProductSearchInput 商品搜索条件
+type ProductSearchInput struct {
+	Query      string
+	CategoryID uint     // 包含后代分类
+	Tags       []string // 命中任一标签即可
+	MinPrice   *decimal.Decimal
+	MaxPrice   *decimal.Decimal
+	InStock    bool
+	Sort       string
+	Page       int
+	PageSize   int
+}
+
+// ProductSearchResult 商品搜索结果
+type ProductSearchResult struct {
+	Products []models.Product
+	Total    int64
+	Facets   ProductSearchFacets
+}
+
+// ProductSearchFacets 搜索分面（每个维度的计数忽略该维度自身的筛选条件）
+type ProductSearchFacets struct {
+	Categories   []ProductCategoryFacet `json:"categories"`
+	Tags         []ProductTagFacet      `json:"tags"`
+	PriceBuckets []ProductPriceFacet    `json:"price_buckets"`
+}
+
+// ProductCategoryFacet 分类分面
+type ProductCategoryFacet struct {
+	CategoryID uint        `json:"category_id"`
+	Name       models.JSON `json:"name"`
+	Count      int         `json:"count"`
+}
+
+// ProductTagFacet 标签分面
+type ProductTagFacet struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// ProductPriceFacet 价格区间分面，[min, max)，max 为空表示不设上限
+type ProductPriceFacet struct {
+	Min   models.Money  `json:"min"`
+	Max   *models.Money `json:"max,omitempty"`
+	Count int           `json:"count"`
+}
+
+type productSearchHit struct {
+	candidate repository.ProductSearchCandidate
+	rank      float64
+}
+
+// Search 按关键词、分类、标签、价格区间与库存搜索上架商品，并返回分面统计
+func (s *ProductSearchService) Search(input ProductSearchInput) (*ProductSearchResult, error) {
+	query := strings.TrimSpace(input.Query)
+	candidateFilter := repository.ProductSearchCandidateFilter{InStock: input.InStock}
+	ranks := map[uint]float64{}
+	if query != "" {
+		matches, err := s.searchRepo.Match(query)
+		if err != nil {
+			return nil, err
+		}
+		candidateFilter.ProductIDs = make([]uint, 0, len(matches))
+		for _, match := range matches {
+			ranks[match.ProductID] = match.Rank
+			candidateFilter.ProductIDs = append(candidateFilter.ProductIDs, match.ProductID)
+		}
+	}
+	candidates, err := s.searchRepo.ListCandidates(candidateFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	var categoryIDs map[uint]struct{}
+	if input.CategoryID != 0 {
+		ids, err := s.categoryRepo.ListDescendantIDs(input.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		categoryIDs = make(map[uint]struct{}, len(ids))
+		for _, id := range ids {
+			categoryIDs[id] = struct{}{}
+		}
+	}
+
+	tags := make(map[string]struct{}, len(input.Tags))
+	for _, tag := range input.Tags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			tags[tag] = struct{}{}
+		}
+	}
+
+	categoryCounts := map[uint]int{}
+	tagCounts := map[string]int{}
+	tagLabels := map[string]string{}
+	priceCounts := make([]int, len(productPriceBucketBounds))
+	hits := make([]productSearchHit, 0, len(candidates))
+	for _, candidate := range candidates {
+		_, inCategory := categoryIDs[candidate.CategoryID]
+		matchCategory := input.CategoryID == 0 || inCategory
+		matchTag := len(tags) == 0 || candidateHasTag(candidate.Tags, tags)
+		matchPrice := priceInRange(candidate.PriceAmount.Decimal, input.MinPrice, input.MaxPrice)
+
+		if matchTag && matchPrice {
+			categoryCounts[candidate.CategoryID]++
+		}
+		if matchCategory && matchPrice {
+			seen := map[string]struct{}{}
+			for _, tag := range candidate.Tags {
+				key := strings.ToLower(strings.TrimSpace(tag))
+				if key == "" {
+					continue
+				}
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+				tagCounts[key]++
+				if _, ok := tagLabels[key]; !ok {
+					tagLabels[key] = strings.TrimSpace(tag)
+				}
+			}
+		}
+		if matchCategory && matchTag {
+			priceCounts[priceBucketIndex(candidate.PriceAmount.Decimal)]++
+		}
+		if matchCategory && matchTag && matchPrice {
+			hits = append(hits, productSearchHit{candidate: candidate, rank: ranks[candidate.ID]})
+		}
+	}
+
+	sortProductSearchHits(hits, resolveProductSearchSort(input.Sort, query != ""))
+	facets, err := s.buildFacets(categoryCounts, tagCounts, tagLabels, priceCounts)
+	if err != nil {
+		return nil, err
+	}
+	result := &ProductSearchResult{
+		Products: []models.Product{},
+		Total:    int64(len(hits)),
+		Facets:   facets,
+	}
+
+	page, pageSize := input.Page, input.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	start := (page - 1) * pageSize
+	if start >= len(hits) {
+		return result, nil
+	}
+	end := start + pageSize
+	if end > len(hits) {
+		end = len(hits)
+	}
+	pageIDs := make([]uint, 0, end-start)
+	for _, hit := range hits[start:end] {
+		pageIDs = append(pageIDs, hit.candidate.ID)
+	}
+	products, err := s.searchRepo.ListPublicByIDs(pageIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+	for _, id := range pageIDs {
+		if product, ok := byID[id]; ok {
+			result.Products = append(result.Products, product)
+		}
+	}
+	return result, nil
+}
+
+func (s *ProductSearchService) buildFacets(categoryCounts map[uint]int, tagCounts map[string]int, tagLabels map[string]string, priceCounts []int) (ProductSearchFacets, error) {
+	facets := ProductSearchFacets{
+		Categories:   []ProductCategoryFacet{},
+		Tags:         []ProductTagFacet{},
+		PriceBuckets: []ProductPriceFacet{},
+	}
+	if len(categoryCounts) > 0 {
+		categories, err := s.categoryRepo.List()
+		if err != nil {
+			return facets, err
+		}
+		for _, category := range categories {
+			if count := categoryCounts[category.ID]; count > 0 {
+				facets.Categories = append(facets.Categories, ProductCategoryFacet{CategoryID: category.ID, Name: category.NameJSON, Count: count})
+			}
+		}
+	}
+	for key, count := range tagCounts {
+		facets.Tags = append(facets.Tags, ProductTagFacet{Tag: tagLabels[key], Count: count})
+	}
+	sort.Slice(facets.Tags, func(i, j int) bool {
+		if facets.Tags[i].Count != facets.Tags[j].Count {
+			return facets.Tags[i].Count > facets.Tags[j].Count
+		}
+		return facets.Tags[i].Tag < facets.Tags[j].Tag
+	})
+	for i, count := range priceCounts {
+		if count == 0 {
+			continue
+		}
+		bucket := ProductPriceFacet{Min: models.NewMoneyFromDecimal(decimal.NewFromInt(productPriceBucketBounds[i])), Count: count}
+		if i+1 < len(productPriceBucketBounds) {
+			upper := models.NewMoneyFromDecimal(decimal.NewFromInt(productPriceBucketBounds[i+1]))
+			bucket.Max = &upper
+		}
+		facets.PriceBuckets = append(facets.PriceBuckets, bucket)
+	}
+	return facets, nil
+}
+
+func resolveProductSearchSort(raw string, hasQuery bool) string {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case ProductSearchSortPriceAsc:
+		return ProductSearchSortPriceAsc
+	case ProductSearchSortPriceDesc:
+		return ProductSearchSortPriceDesc
+	case ProductSearchSortNewest:
+		return ProductSearchSortNewest
+	case ProductSearchSortRelevance:
+		if hasQuery {
+			return ProductSearchSortRelevance
+		}
+	}
+	if hasQuery {
+		return ProductSearchSortRelevance
+	}
+	return ""
+}
+
+// sortProductSearchHits 排序，未指定方式时沿用商品列表的 sort_order DESC, created_at DESC
+func sortProductSearchHits(hits []productSearchHit, mode string) {
+	sort.SliceStable(hits, func(i, j int) bool {
+		a, b := hits[i], hits[j]
+		switch mode {
+		case ProductSearchSortRelevance:
+			if a.rank != b.rank {
+				return a.rank > b.rank
+			}
+		case ProductSearchSortPriceAsc:
+			if cmp := a.candidate.PriceAmount.Decimal.Cmp(b.candidate.PriceAmount.Decimal); cmp != 0 {
+				return cmp < 0
+			}
+		case ProductSearchSortPriceDesc:
+			if cmp := a.candidate.PriceAmount.Decimal.Cmp(b.candidate.PriceAmount.Decimal); cmp != 0 {
+				return cmp > 0
+			}
+		case ProductSearchSortNewest:
+			if !a.candidate.CreatedAt.Equal(b.candidate.CreatedAt) {
+				return a.candidate.CreatedAt.After(b.candidate.CreatedAt)
+			}
+		}
+		if a.candidate.SortOrder != b.candidate.SortOrder {
+			return a.candidate.SortOrder > b.candidate.SortOrder
+		}
+		if !a.candidate.CreatedAt.Equal(b.candidate.CreatedAt) {
+			return a.candidate.CreatedAt.After(b.candidate.CreatedAt)
+		}
+		return a.candidate.ID > b.candidate.ID
+	})
+}
+
+func candidateHasTag(productTags models.StringArray, wanted map[string]struct{}) bool {
+	for _, tag := range productTags {
+		if _, ok := wanted[strings.ToLower(strings.TrimSpace(tag))]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func priceInRange(price decimal.Decimal, min, max *decimal.Decimal) bool {
+	if min != nil && price.LessThan(*min) {
+		return false
+	}
+	if max != nil && price.GreaterThan(*max) {
+		return false
+	}
+	return true
+}
+
+func priceBucketIndex(price decimal.Decimal) int {
+	index := 0
+	for i, bound := range productPriceBucketBounds {
+		if price.GreaterThanOrEqual(decimal.NewFromInt(bound)) {
+			index = i
+		}
+	}
+	return index
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/glebarez/sqlite"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+func setupProductSearchServiceTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:product_search_service_test_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&models.Category{},
+		&models.Product{},
+		&models.ProductSKU{},
+		&models.CardSecret{},
+		&models.ProductSearchDocument{},
+	); err != nil {
+		t.Fatalf("auto migrate failed: %v", err)
+	}
+	if err := db.Exec("CREATE VIRTUAL TABLE IF NOT EXISTS product_search_fts USING fts5(product_id UNINDEXED, content, tokenize='trigram')").Error; err != nil {
+		t.Fatalf("create fts table failed: %v", err)
+	}
+	return db
+}
+
+func TestProductSearchRelevanceFiltersAndFacets(t *testing.T) {
+	db := setupProductSearchServiceTestDB(t)
+	games := &models.Category{Slug: "games", NameJSON: models.JSON{"zh-CN": "游戏"}}
+	software := &models.Category{Slug: "software", NameJSON: models.JSON{"zh-CN": "软件"}}
+	for _, category := range []*models.Category{games, software} {
+		if err := db.Create(category).Error; err != nil {
+			t.Fatalf("create category failed: %v", err)
+		}
+	}
+
+	searchRepo := repository.NewProductSearchRepository(db)
+	createProduct := func(slug string, categoryID uint, title, description string, price int64, tags []string, stock int) *models.Product {
+		product := &models.Product{
+			CategoryID:       categoryID,
+			Slug:             slug,
+			TitleJSON:        models.JSON{"zh-CN": title, "en-US": title},
+			DescriptionJSON:  models.JSON{"en-US": description},
+			PriceAmount:      models.NewMoneyFromDecimal(decimal.NewFromInt(price)),
+			Tags:             tags,
+			PurchaseType:     constants.ProductPurchaseMember,
+			FulfillmentType:  constants.FulfillmentTypeManual,
+			ManualStockTotal: stock,
+			IsActive:         true,
+		}
+		if err := db.Create(product).Error; err != nil {
+			t.Fatalf("create product failed: %v", err)
+		}
+		if err := searchRepo.Index(product); err != nil {
+			t.Fatalf("index product failed: %v", err)
+		}
+		return product
+	}
+	steamCard := createProduct("steam-card", games.ID, "Steam Wallet Card", "steam gift card for games", 50, []string{"steam", "gift"}, 10)
+	steamKey := createProduct("steam-key", games.ID, "Game Key", "activate on steam", 8, []string{"steam"}, 0)
+	office := createProduct("office", software.ID, "Office License", "productivity suite", 300, []string{"license"}, -1)
+
+	svc := NewProductSearchService(searchRepo, repository.NewCategoryRepository(db))
+
+	result, err := svc.Search(ProductSearchInput{Query: "steam"})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if result.Total != 2 || len(result.Products) != 2 {
+		t.Fatalf("expected 2 steam products, got total=%d len=%d", result.Total, len(result.Products))
+	}
+	if result.Products[0].ID != steamCard.ID {
+		t.Fatalf("expected title/tag match ranked first, got product %d", result.Products[0].ID)
+	}
+	if len(result.Facets.Categories) != 1 || result.Facets.Categories[0].CategoryID != games.ID || result.Facets.Categories[0].Count != 2 {
+		t.Fatalf("unexpected category facets: %+v", result.Facets.Categories)
+	}
+
+	minPrice := decimal.NewFromInt(10)
+	result, err = svc.Search(ProductSearchInput{Query: "steam", MinPrice: &minPrice})
+	if err != nil {
+		t.Fatalf("search with price failed: %v", err)
+	}
+	if result.Total != 1 || result.Products[0].ID != steamCard.ID {
+		t.Fatalf("expected only steam card above min price, got %+v", result.Products)
+	}
+	if len(result.Facets.PriceBuckets) != 2 {
+		t.Fatalf("price facets should ignore price filter, got %+v", result.Facets.PriceBuckets)
+	}
+
+	result, err = svc.Search(ProductSearchInput{InStock: true, Sort: ProductSearchSortPriceDesc})
+	if err != nil {
+		t.Fatalf("in-stock search failed: %v", err)
+	}
+	if result.Total != 2 || result.Products[0].ID != office.ID || result.Products[1].ID != steamCard.ID {
+		t.Fatalf("expected in-stock products sorted by price desc, got %+v", result.Products)
+	}
+
+	result, err = svc.Search(ProductSearchInput{Tags: []string{"License"}})
+	if err != nil {
+		t.Fatalf("tag search failed: %v", err)
+	}
+	if result.Total != 1 || result.Products[0].ID != office.ID {
+		t.Fatalf("expected tag filter to match office, got %+v", result.Products)
+	}
+	tagCounts := map[string]int{}
+	for _, facet := range result.Facets.Tags {
+		tagCounts[facet.Tag] = facet.Count
+	}
+	if tagCounts["steam"] != 2 || tagCounts["license"] != 1 {
+		t.Fatalf("tag facets should ignore tag filter, got %+v", result.Facets.Tags)
+	}
+
+	if err := searchRepo.Remove(steamKey.ID); err != nil {
+		t.Fatalf("remove document failed: %v", err)
+	}
+	result, err = svc.Search(ProductSearchInput{Query: "activate"})
+	if err != nil {
+		t.Fatalf("search after remove failed: %v", err)
+	}
+	if result.Total != 0 {
+		t.Fatalf("removed product should not match, got %+v", result.Products)
+	}
+}
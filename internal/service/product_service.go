@@ -1,10 +1,13 @@
 package service
 
 import (
+	"crypto/subtle"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/logger"
 	"github.com/dujiao-next/internal/models"
 	"github.com/dujiao-next/internal/repository"
 
@@ -12,22 +15,47 @@ import (
 	"gorm.io/gorm"
 )
 
+// productAccessCodeMaxLength 商品访问码最大长度
+const productAccessCodeMaxLength = 64
+
 // ProductService 商品业务服务
 type ProductService struct {
 	repo           repository.ProductRepository
 	productSKURepo repository.ProductSKURepository
 	cardSecretRepo repository.CardSecretRepository
+	supplierRepo   repository.FulfillmentSupplierRepository
+	searchRepo     repository.ProductSearchRepository
+	revisionRepo   repository.ProductRevisionRepository
+	stockSubSvc    *StockSubscriptionService
+	// pendingRestock 非空时（导入事务内）只登记需检查补货的商品，由事务提交后统一触发
+	pendingRestock map[uint]struct{}
 }
 
 // NewProductService 创建商品服务
-func NewProductService(repo repository.ProductRepository, productSKURepo repository.ProductSKURepository, cardSecretRepo repository.CardSecretRepository) *ProductService {
+func NewProductService(repo repository.ProductRepository, productSKURepo repository.ProductSKURepository, cardSecretRepo repository.CardSecretRepository, supplierRepo repository.FulfillmentSupplierRepository) *ProductService {
 	return &ProductService{
 		repo:           repo,
 		productSKURepo: productSKURepo,
 		cardSecretRepo: cardSecretRepo,
+		supplierRepo:   supplierRepo,
 	}
 }
 
+// SetSearchRepository 设置搜索索引仓库，商品保存时同步刷新搜索文档
+func (s *ProductService) SetSearchRepository(repo repository.ProductSearchRepository) {
+	s.searchRepo = repo
+}
+
+// SetRevisionRepository 设置商品修订仓库，后台更新商品时记录修订快照
+func (s *ProductService) SetRevisionRepository(repo repository.ProductRevisionRepository) {
+	s.revisionRepo = repo
+}
+
+// SetStockSubscriptionService 设置到货通知服务，后台更新库存后触发补货通知
+func (s *ProductService) SetStockSubscriptionService(svc *StockSubscriptionService) {
+	s.stockSubSvc = svc
+}
+
 // CreateProductInput 创建/更新商品输入
 type CreateProductInput struct {
 	CategoryID           uint
@@ -37,25 +65,42 @@ type CreateProductInput struct {
 	DescriptionJSON      map[string]interface{}
 	ContentJSON          map[string]interface{}
 	ManualFormSchemaJSON map[string]interface{}
+	SecretSchemaJSON     map[string]interface{}
+	KeyGeneratorJSON     map[string]interface{}
+	DeliveryTmplJSON     map[string]interface{}
+	SpecSchemaJSON       map[string]interface{}
 	PriceAmount          decimal.Decimal
 	Images               []string
 	Tags                 []string
 	PurchaseType         string
+	Visibility           string // 可见性，为空时创建为 public、更新时保持不变
+	AccessCode           string // 访问码，protected 可见性必填；更新时留空保持原访问码
 	FulfillmentType      string
+	SupplierID           *uint
+	SupplierProductCode  string
 	ManualStockTotal     *int
 	SKUs                 []ProductSKUInput
+	RevealRequired       *bool
+	RevealMaxViews       int
 	IsAffiliateEnabled   *bool
 	IsActive             *bool
+	PublishAt            *time.Time
+	UnpublishAt          *time.Time
 	SortOrder            int
+	AdminID              uint // 操作管理员（更新时写入修订记录）
 }
 
 type ProductSKUInput struct {
 	ID               uint
 	SKUCode          string
 	SpecValuesJSON   map[string]interface{}
+	DeliveryTmplJSON map[string]interface{}
 	PriceAmount      decimal.Decimal
+	PriceTiers       []PriceTierInput
 	ManualStockTotal int
 	IsActive         *bool
+	PublishAt        *time.Time
+	UnpublishAt      *time.Time
 	SortOrder        int
 }
 
@@ -67,6 +112,7 @@ func (s *ProductService) ListPublic(categoryID, search string, page, pageSize in
 		CategoryID:   categoryID,
 		Search:       search,
 		OnlyActive:   true,
+		OnlyListed:   true,
 		WithCategory: true,
 	}
 	return s.repo.List(filter)
@@ -84,6 +130,11 @@ func (s *ProductService) GetPublicBySlug(slug string) (*models.Product, error) {
 	return product, nil
 }
 
+// NextPublishBoundary 返回晚于 now 的最近一个定时上下架时间点，供前台目录缓存限定有效期
+func (s *ProductService) NextPublishBoundary(now time.Time) (*time.Time, error) {
+	return s.repo.NextPublishBoundary(now)
+}
+
 // ListAdmin 获取后台商品列表
 func (s *ProductService) ListAdmin(categoryID, search, fulfillmentType, manualStockStatus string, page, pageSize int) ([]models.Product, int64, error) {
 	filter := repository.ProductListFilter{
@@ -96,7 +147,15 @@ func (s *ProductService) ListAdmin(categoryID, search, fulfillmentType, manualSt
 		OnlyActive:        false,
 		WithCategory:      true,
 	}
-	return s.repo.List(filter)
+	products, total, err := s.repo.List(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	now := time.Now()
+	for i := range products {
+		products[i].PublishState = resolveProductPublishState(&products[i], now)
+	}
+	return products, total, nil
 }
 
 // GetAdminByID 获取后台商品详情
@@ -108,11 +167,15 @@ func (s *ProductService) GetAdminByID(id string) (*models.Product, error) {
 	if product == nil {
 		return nil, ErrNotFound
 	}
+	product.PublishState = resolveProductPublishState(product, time.Now())
 	return product, nil
 }
 
 // Create 创建商品
 func (s *ProductService) Create(input CreateProductInput) (*models.Product, error) {
+	if !isValidPublishWindow(input.PublishAt, input.UnpublishAt) {
+		return nil, ErrProductScheduleInvalid
+	}
 	count, err := s.repo.CountBySlug(input.Slug, nil)
 	if err != nil {
 		return nil, err
@@ -133,6 +196,10 @@ func (s *ProductService) Create(input CreateProductInput) (*models.Product, erro
 	if purchaseType == "" {
 		return nil, ErrProductPurchaseInvalid
 	}
+	visibility, accessCode, err := normalizeProductVisibility(input.Visibility, input.AccessCode, "")
+	if err != nil {
+		return nil, err
+	}
 	fulfillmentType := normalizeFulfillmentType(input.FulfillmentType)
 	if fulfillmentType == "" {
 		return nil, ErrFulfillmentInvalid
@@ -151,13 +218,21 @@ func (s *ProductService) Create(input CreateProductInput) (*models.Product, erro
 		return nil, ErrManualStockInvalid
 	}
 
+	specs, specSchema, err := parseProductSpecSchema(models.JSON(input.SpecSchemaJSON))
+	if err != nil {
+		return nil, err
+	}
+	if len(specs) > 0 && len(input.SKUs) == 0 {
+		return nil, ErrProductSKUSpecInvalid
+	}
+
 	var normalizedSKUs []normalizedProductSKU
 	if len(input.SKUs) > 0 {
 		if s.productSKURepo == nil {
 			return nil, ErrProductSKUInvalid
 		}
 		var normalizeErr error
-		normalizedSKUs, priceAmount, manualStockTotal, normalizeErr = normalizeProductSKUInputs(input.SKUs, fulfillmentType, nil)
+		normalizedSKUs, priceAmount, manualStockTotal, normalizeErr = normalizeProductSKUInputs(input.SKUs, fulfillmentType, specs, nil)
 		if normalizeErr != nil {
 			return nil, normalizeErr
 		}
@@ -171,16 +246,23 @@ func (s *ProductService) Create(input CreateProductInput) (*models.Product, erro
 		DescriptionJSON:      models.JSON(input.DescriptionJSON),
 		ContentJSON:          models.JSON(input.ContentJSON),
 		ManualFormSchemaJSON: models.JSON{},
+		SecretSchemaJSON:     models.JSON{},
+		KeyGeneratorJSON:     models.JSON{},
+		SpecSchemaJSON:       specSchema,
 		PriceAmount:          models.NewMoneyFromDecimal(priceAmount),
 		Images:               models.StringArray(input.Images),
 		Tags:                 models.StringArray(input.Tags),
 		PurchaseType:         purchaseType,
+		Visibility:           visibility,
+		AccessCode:           accessCode,
 		FulfillmentType:      fulfillmentType,
 		ManualStockTotal:     manualStockTotal,
 		ManualStockLocked:    0,
 		ManualStockSold:      0,
 		IsAffiliateEnabled:   isAffiliateEnabled,
 		IsActive:             isActive,
+		PublishAt:            input.PublishAt,
+		UnpublishAt:          input.UnpublishAt,
 		SortOrder:            input.SortOrder,
 	}
 	if fulfillmentType == constants.FulfillmentTypeManual {
@@ -190,6 +272,29 @@ func (s *ProductService) Create(input CreateProductInput) (*models.Product, erro
 		}
 		product.ManualFormSchemaJSON = normalizedSchemaJSON
 	}
+	if fulfillmentType == constants.FulfillmentTypeAuto {
+		_, normalizedSecretSchema, err := parseCardSecretSchema(models.JSON(input.SecretSchemaJSON))
+		if err != nil {
+			return nil, err
+		}
+		product.SecretSchemaJSON = normalizedSecretSchema
+		keyGenerator, err := normalizeKeyGenerator(models.JSON(input.KeyGeneratorJSON))
+		if err != nil {
+			return nil, err
+		}
+		product.KeyGeneratorJSON = keyGenerator
+	}
+	deliveryTmpl, err := normalizeDeliveryTemplate(models.JSON(input.DeliveryTmplJSON))
+	if err != nil {
+		return nil, err
+	}
+	product.DeliveryTmplJSON = deliveryTmpl
+	if err := applyProductReveal(&product, input); err != nil {
+		return nil, err
+	}
+	if err := s.applyProductSupplier(&product, input); err != nil {
+		return nil, err
+	}
 
 	if err := s.repo.Transaction(func(tx *gorm.DB) error {
 		productRepo := s.repo.WithTx(tx)
@@ -200,6 +305,9 @@ func (s *ProductService) Create(input CreateProductInput) (*models.Product, erro
 		if err := productRepo.Create(&product); err != nil {
 			return err
 		}
+		if err := s.indexProduct(tx, &product); err != nil {
+			return err
+		}
 		if len(normalizedSKUs) > 0 {
 			return applyProductSKUs(skuRepo, product.ID, normalizedSKUs)
 		}
@@ -212,6 +320,9 @@ func (s *ProductService) Create(input CreateProductInput) (*models.Product, erro
 
 // Update 更新商品
 func (s *ProductService) Update(id string, input CreateProductInput) (*models.Product, error) {
+	if !isValidPublishWindow(input.PublishAt, input.UnpublishAt) {
+		return nil, ErrProductScheduleInvalid
+	}
 	priceAmount := input.PriceAmount.Round(2)
 	if len(input.SKUs) == 0 && priceAmount.LessThanOrEqual(decimal.Zero) {
 		return nil, ErrProductPriceInvalid
@@ -223,6 +334,10 @@ func (s *ProductService) Update(id string, input CreateProductInput) (*models.Pr
 	if product == nil {
 		return nil, ErrNotFound
 	}
+	previousSnapshot, err := buildProductRevisionSnapshot(product)
+	if err != nil {
+		return nil, err
+	}
 
 	count, err := s.repo.CountBySlug(input.Slug, &id)
 	if err != nil {
@@ -239,6 +354,8 @@ func (s *ProductService) Update(id string, input CreateProductInput) (*models.Pr
 	product.DescriptionJSON = models.JSON(input.DescriptionJSON)
 	product.ContentJSON = models.JSON(input.ContentJSON)
 	product.ManualFormSchemaJSON = models.JSON{}
+	product.SecretSchemaJSON = models.JSON{}
+	product.KeyGeneratorJSON = models.JSON{}
 	product.PriceAmount = models.NewMoneyFromDecimal(priceAmount)
 	product.SortOrder = input.SortOrder
 	product.Images = models.StringArray(input.Images)
@@ -246,6 +363,8 @@ func (s *ProductService) Update(id string, input CreateProductInput) (*models.Pr
 	if input.IsActive != nil {
 		product.IsActive = *input.IsActive
 	}
+	product.PublishAt = input.PublishAt
+	product.UnpublishAt = input.UnpublishAt
 	if input.IsAffiliateEnabled != nil {
 		product.IsAffiliateEnabled = *input.IsAffiliateEnabled
 	}
@@ -258,6 +377,16 @@ func (s *ProductService) Update(id string, input CreateProductInput) (*models.Pr
 		return nil, ErrProductPurchaseInvalid
 	}
 	product.PurchaseType = purchaseType
+	rawVisibility := strings.TrimSpace(input.Visibility)
+	if rawVisibility == "" {
+		rawVisibility = product.Visibility
+	}
+	visibility, accessCode, err := normalizeProductVisibility(rawVisibility, input.AccessCode, product.AccessCode)
+	if err != nil {
+		return nil, err
+	}
+	product.Visibility = visibility
+	product.AccessCode = accessCode
 	rawFulfillmentType := strings.TrimSpace(input.FulfillmentType)
 	if rawFulfillmentType == "" {
 		rawFulfillmentType = product.FulfillmentType
@@ -274,6 +403,29 @@ func (s *ProductService) Update(id string, input CreateProductInput) (*models.Pr
 		}
 		product.ManualFormSchemaJSON = normalizedSchemaJSON
 	}
+	if fulfillmentType == constants.FulfillmentTypeAuto {
+		_, normalizedSecretSchema, err := parseCardSecretSchema(models.JSON(input.SecretSchemaJSON))
+		if err != nil {
+			return nil, err
+		}
+		product.SecretSchemaJSON = normalizedSecretSchema
+		keyGenerator, err := normalizeKeyGenerator(models.JSON(input.KeyGeneratorJSON))
+		if err != nil {
+			return nil, err
+		}
+		product.KeyGeneratorJSON = keyGenerator
+	}
+	deliveryTmpl, err := normalizeDeliveryTemplate(models.JSON(input.DeliveryTmplJSON))
+	if err != nil {
+		return nil, err
+	}
+	product.DeliveryTmplJSON = deliveryTmpl
+	if err := applyProductReveal(product, input); err != nil {
+		return nil, err
+	}
+	if err := s.applyProductSupplier(product, input); err != nil {
+		return nil, err
+	}
 
 	manualStockTotal := product.ManualStockTotal
 	if input.ManualStockTotal != nil {
@@ -283,6 +435,15 @@ func (s *ProductService) Update(id string, input CreateProductInput) (*models.Pr
 		return nil, ErrManualStockInvalid
 	}
 
+	specs, specSchema, err := parseProductSpecSchema(models.JSON(input.SpecSchemaJSON))
+	if err != nil {
+		return nil, err
+	}
+	if len(specs) > 0 && len(input.SKUs) == 0 {
+		return nil, ErrProductSKUSpecInvalid
+	}
+	product.SpecSchemaJSON = specSchema
+
 	var normalizedSKUs []normalizedProductSKU
 	if len(input.SKUs) > 0 {
 		if s.productSKURepo == nil {
@@ -297,7 +458,7 @@ func (s *ProductService) Update(id string, input CreateProductInput) (*models.Pr
 			existingSKUMap[sku.ID] = sku
 		}
 		var normalizeErr error
-		normalizedSKUs, priceAmount, manualStockTotal, normalizeErr = normalizeProductSKUInputs(input.SKUs, fulfillmentType, existingSKUMap)
+		normalizedSKUs, priceAmount, manualStockTotal, normalizeErr = normalizeProductSKUInputs(input.SKUs, fulfillmentType, specs, existingSKUMap)
 		if normalizeErr != nil {
 			return nil, normalizeErr
 		}
@@ -315,16 +476,33 @@ func (s *ProductService) Update(id string, input CreateProductInput) (*models.Pr
 		if err := productRepo.Update(product); err != nil {
 			return err
 		}
+		if err := s.indexProduct(tx, product); err != nil {
+			return err
+		}
 		if len(normalizedSKUs) > 0 {
-			return applyProductSKUs(skuRepo, product.ID, normalizedSKUs)
+			if err := applyProductSKUs(skuRepo, product.ID, normalizedSKUs); err != nil {
+				return err
+			}
+		} else if err := syncSingleProductSKU(skuRepo, product.ID, priceAmount, product.ManualStockTotal, true); err != nil {
+			return err
 		}
-		return syncSingleProductSKU(skuRepo, product.ID, priceAmount, product.ManualStockTotal, true)
+		return s.recordProductRevision(tx, product.ID, previousSnapshot, input.AdminID)
 	}); err != nil {
 		return nil, err
 	}
+	s.triggerRestock(product.ID)
 	return s.repo.GetByID(id)
 }
 
+// triggerRestock 触发到货通知检查；绑定外层事务时延迟到提交之后
+func (s *ProductService) triggerRestock(productID uint) {
+	if s.pendingRestock != nil {
+		s.pendingRestock[productID] = struct{}{}
+		return
+	}
+	s.stockSubSvc.TriggerRestock(productID)
+}
+
 func syncSingleProductSKU(skuRepo repository.ProductSKURepository, productID uint, priceAmount decimal.Decimal, manualStockTotal int, createWhenMissing bool) error {
 	if skuRepo == nil || productID == 0 {
 		return nil
@@ -356,6 +534,7 @@ func syncSingleProductSKU(skuRepo repository.ProductSKURepository, productID uin
 
 	target := skus[targetIndex]
 	target.PriceAmount = models.NewMoneyFromDecimal(priceAmount)
+	target.PriceTiers = models.PriceTiers{}
 	target.ManualStockTotal = manualStockTotal
 	target.IsActive = true
 	if strings.TrimSpace(target.SKUCode) == "" {
@@ -408,17 +587,22 @@ type normalizedProductSKU struct {
 	ID               uint
 	SKUCode          string
 	SpecValuesJSON   models.JSON
+	DeliveryTmplJSON models.JSON
 	PriceAmount      models.Money
+	PriceTiers       models.PriceTiers
 	ManualStockTotal int
 	IsActive         bool
+	PublishAt        *time.Time
+	UnpublishAt      *time.Time
 	SortOrder        int
 }
 
-func normalizeProductSKUInputs(inputs []ProductSKUInput, fulfillmentType string, existingSKUMap map[uint]models.ProductSKU) ([]normalizedProductSKU, decimal.Decimal, int, error) {
+func normalizeProductSKUInputs(inputs []ProductSKUInput, fulfillmentType string, specs []ProductSpecDefinition, existingSKUMap map[uint]models.ProductSKU) ([]normalizedProductSKU, decimal.Decimal, int, error) {
 	if len(inputs) == 0 {
 		return nil, decimal.Zero, 0, ErrProductSKUInvalid
 	}
 	seenCode := make(map[string]struct{}, len(inputs))
+	seenSpec := make(map[string]struct{}, len(inputs))
 	normalized := make([]normalizedProductSKU, 0, len(inputs))
 	hasActive := false
 	minActivePrice := decimal.Zero
@@ -459,18 +643,45 @@ func normalizeProductSKUInputs(inputs []ProductSKUInput, fulfillmentType string,
 		if input.IsActive != nil {
 			isActive = *input.IsActive
 		}
+		if !isValidPublishWindow(input.PublishAt, input.UnpublishAt) {
+			return nil, decimal.Zero, 0, ErrProductScheduleInvalid
+		}
 		specValues := models.JSON{}
 		if input.SpecValuesJSON != nil {
 			specValues = models.JSON(input.SpecValuesJSON)
 		}
+		if len(specs) > 0 {
+			normalizedSpec, specKey, err := normalizeSKUSpecValues(specs, specValues)
+			if err != nil {
+				return nil, decimal.Zero, 0, err
+			}
+			if _, exists := seenSpec[specKey]; exists {
+				return nil, decimal.Zero, 0, ErrProductSKUSpecInvalid
+			}
+			seenSpec[specKey] = struct{}{}
+			specValues = normalizedSpec
+		}
+
+		deliveryTmpl, err := normalizeDeliveryTemplate(models.JSON(input.DeliveryTmplJSON))
+		if err != nil {
+			return nil, decimal.Zero, 0, err
+		}
+		priceTiers, err := normalizePriceTiers(priceAmount, input.PriceTiers)
+		if err != nil {
+			return nil, decimal.Zero, 0, err
+		}
 
 		normalized = append(normalized, normalizedProductSKU{
 			ID:               input.ID,
 			SKUCode:          skuCode,
 			SpecValuesJSON:   specValues,
+			DeliveryTmplJSON: deliveryTmpl,
 			PriceAmount:      models.NewMoneyFromDecimal(priceAmount),
+			PriceTiers:       priceTiers,
 			ManualStockTotal: manualTotal,
 			IsActive:         isActive,
+			PublishAt:        input.PublishAt,
+			UnpublishAt:      input.UnpublishAt,
 			SortOrder:        input.SortOrder,
 		})
 
@@ -524,9 +735,13 @@ func applyProductSKUs(skuRepo repository.ProductSKURepository, productID uint, r
 			}
 			existing.SKUCode = row.SKUCode
 			existing.SpecValuesJSON = row.SpecValuesJSON
+			existing.DeliveryTmplJSON = row.DeliveryTmplJSON
 			existing.PriceAmount = row.PriceAmount
+			existing.PriceTiers = row.PriceTiers
 			existing.ManualStockTotal = row.ManualStockTotal
 			existing.IsActive = row.IsActive
+			existing.PublishAt = row.PublishAt
+			existing.UnpublishAt = row.UnpublishAt
 			existing.SortOrder = row.SortOrder
 			if err := skuRepo.Update(&existing); err != nil {
 				return err
@@ -539,9 +754,13 @@ func applyProductSKUs(skuRepo repository.ProductSKURepository, productID uint, r
 		codeKey := strings.ToLower(strings.TrimSpace(row.SKUCode))
 		if existing, ok := existingByCode[codeKey]; ok {
 			existing.SpecValuesJSON = row.SpecValuesJSON
+			existing.DeliveryTmplJSON = row.DeliveryTmplJSON
 			existing.PriceAmount = row.PriceAmount
+			existing.PriceTiers = row.PriceTiers
 			existing.ManualStockTotal = row.ManualStockTotal
 			existing.IsActive = row.IsActive
+			existing.PublishAt = row.PublishAt
+			existing.UnpublishAt = row.UnpublishAt
 			existing.SortOrder = row.SortOrder
 			if err := skuRepo.Update(&existing); err != nil {
 				return err
@@ -554,11 +773,15 @@ func applyProductSKUs(skuRepo repository.ProductSKURepository, productID uint, r
 			ProductID:         productID,
 			SKUCode:           row.SKUCode,
 			SpecValuesJSON:    row.SpecValuesJSON,
+			DeliveryTmplJSON:  row.DeliveryTmplJSON,
 			PriceAmount:       row.PriceAmount,
+			PriceTiers:        row.PriceTiers,
 			ManualStockTotal:  row.ManualStockTotal,
 			ManualStockLocked: 0,
 			ManualStockSold:   0,
 			IsActive:          row.IsActive,
+			PublishAt:         row.PublishAt,
+			UnpublishAt:       row.UnpublishAt,
 			SortOrder:         row.SortOrder,
 		}
 		if err := skuRepo.Create(&item); err != nil {
@@ -582,6 +805,28 @@ func applyProductSKUs(skuRepo repository.ProductSKURepository, productID uint, r
 	return nil
 }
 
+// isValidPublishWindow 同时设置定时上下架时，下架时间必须晚于上架时间
+func isValidPublishWindow(publishAt, unpublishAt *time.Time) bool {
+	if publishAt == nil || unpublishAt == nil {
+		return true
+	}
+	return unpublishAt.After(*publishAt)
+}
+
+// resolveProductPublishState 计算商品在后台展示的上架状态
+func resolveProductPublishState(product *models.Product, now time.Time) string {
+	switch {
+	case product == nil || !product.IsActive:
+		return constants.ProductPublishStateInactive
+	case product.PublishAt != nil && now.Before(*product.PublishAt):
+		return constants.ProductPublishStateScheduled
+	case product.UnpublishAt != nil && !now.Before(*product.UnpublishAt):
+		return constants.ProductPublishStateExpired
+	default:
+		return constants.ProductPublishStateLive
+	}
+}
+
 func normalizePurchaseType(raw string) string {
 	value := strings.ToLower(strings.TrimSpace(raw))
 	switch value {
@@ -594,6 +839,44 @@ func normalizePurchaseType(raw string) string {
 	}
 }
 
+// normalizeProductVisibility 归一化可见性与访问码：仅 protected 保留访问码，留空时沿用 existingCode
+func normalizeProductVisibility(rawVisibility, rawAccessCode, existingCode string) (string, string, error) {
+	visibility := strings.ToLower(strings.TrimSpace(rawVisibility))
+	switch visibility {
+	case "":
+		visibility = constants.ProductVisibilityPublic
+	case constants.ProductVisibilityPublic, constants.ProductVisibilityUnlisted, constants.ProductVisibilityProtected:
+	default:
+		return "", "", ErrProductVisibilityInvalid
+	}
+	if visibility != constants.ProductVisibilityProtected {
+		return visibility, "", nil
+	}
+	accessCode := strings.TrimSpace(rawAccessCode)
+	if accessCode == "" {
+		accessCode = existingCode
+	}
+	if accessCode == "" || len(accessCode) > productAccessCodeMaxLength {
+		return "", "", ErrProductVisibilityInvalid
+	}
+	return visibility, accessCode, nil
+}
+
+// CheckProductAccess 校验访问码保护商品的访问码，公开与不公开商品直接放行
+func CheckProductAccess(product *models.Product, accessCode string) error {
+	if product == nil || product.Visibility != constants.ProductVisibilityProtected {
+		return nil
+	}
+	accessCode = strings.TrimSpace(accessCode)
+	if accessCode == "" {
+		return ErrProductAccessCodeRequired
+	}
+	if subtle.ConstantTimeCompare([]byte(accessCode), []byte(product.AccessCode)) != 1 {
+		return ErrProductAccessCodeInvalid
+	}
+	return nil
+}
+
 func normalizeFulfillmentType(raw string) string {
 	value := strings.ToLower(strings.TrimSpace(raw))
 	switch value {
@@ -601,11 +884,63 @@ func normalizeFulfillmentType(raw string) string {
 		return constants.FulfillmentTypeManual
 	case constants.FulfillmentTypeAuto:
 		return constants.FulfillmentTypeAuto
+	case constants.FulfillmentTypeAPI:
+		return constants.FulfillmentTypeAPI
+	case constants.FulfillmentTypeFile:
+		return constants.FulfillmentTypeFile
 	default:
 		return ""
 	}
 }
 
+// isSupportedFulfillmentType 下单与加购允许的交付类型
+func isSupportedFulfillmentType(fulfillmentType string) bool {
+	switch fulfillmentType {
+	case constants.FulfillmentTypeManual, constants.FulfillmentTypeAuto, constants.FulfillmentTypeAPI, constants.FulfillmentTypeFile:
+		return true
+	default:
+		return false
+	}
+}
+
+// applyProductReveal 设置交付内容查看模式，未开启时忽略查看次数上限
+func applyProductReveal(product *models.Product, input CreateProductInput) error {
+	if input.RevealRequired != nil {
+		product.RevealRequired = *input.RevealRequired
+	}
+	if input.RevealMaxViews < 0 {
+		return ErrSecretRevealConfigInvalid
+	}
+	product.RevealMaxViews = 0
+	if product.RevealRequired {
+		product.RevealMaxViews = input.RevealMaxViews
+	}
+	return nil
+}
+
+// applyProductSupplier 接口交付商品必须绑定启用中的供应商，其它类型清空绑定
+func (s *ProductService) applyProductSupplier(product *models.Product, input CreateProductInput) error {
+	if product.FulfillmentType != constants.FulfillmentTypeAPI {
+		product.SupplierID = nil
+		product.SupplierProductCode = ""
+		return nil
+	}
+	if input.SupplierID == nil || *input.SupplierID == 0 || s.supplierRepo == nil {
+		return ErrSupplierInvalid
+	}
+	supplier, err := s.supplierRepo.GetByID(*input.SupplierID)
+	if err != nil {
+		return err
+	}
+	if supplier == nil || !supplier.IsActive {
+		return ErrSupplierInvalid
+	}
+	supplierID := supplier.ID
+	product.SupplierID = &supplierID
+	product.SupplierProductCode = strings.TrimSpace(input.SupplierProductCode)
+	return nil
+}
+
 func normalizeManualStockStatus(raw string) string {
 	value := strings.ToLower(strings.TrimSpace(raw))
 	switch value {
@@ -627,7 +962,22 @@ func (s *ProductService) Delete(id string) error {
 	if product == nil {
 		return ErrNotFound
 	}
-	return s.repo.Delete(id)
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	if s.searchRepo != nil {
+		if err := s.searchRepo.Remove(product.ID); err != nil {
+			logger.Warnw("product_search_remove_failed", "product_id", product.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+func (s *ProductService) indexProduct(tx *gorm.DB, product *models.Product) error {
+	if s.searchRepo == nil {
+		return nil
+	}
+	return s.searchRepo.WithTx(tx).Index(product)
 }
 
 // ApplyAutoStockCounts 聚合卡密自动发货库存信息并填充到商品中
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dujiao-next/internal/models"
+)
+
+const (
+	productSpecMaxCount      = 5
+	productSpecMaxValues     = 50
+	productSpecValueMaxRunes = 64
+)
+
+var productSpecKeyPattern = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// ProductSpecValue 规格可选值
+type ProductSpecValue struct {
+	Value string      `json:"value"`
+	Label models.JSON `json:"label,omitempty"` // 多语言显示名，为空时展示 value
+}
+
+// ProductSpecDefinition 商品规格定义（如 地区：CN/US/EU）
+type ProductSpecDefinition struct {
+	Key    string             `json:"key"`
+	Name   models.JSON        `json:"name"` // 多语言规格名
+	Values []ProductSpecValue `json:"values"`
+}
+
+// ParseProductSpecDefinitions 解析商品规格定义，未配置时返回空列表
+func ParseProductSpecDefinitions(raw models.JSON) ([]ProductSpecDefinition, error) {
+	specs, _, err := parseProductSpecSchema(raw)
+	return specs, err
+}
+
+// parseProductSpecSchema 校验并规范化规格定义：{"specs":[{"key","name","values":[{"value","label"}]}]}，
+// 可选值也可直接写为字符串
+func parseProductSpecSchema(raw models.JSON) ([]ProductSpecDefinition, models.JSON, error) {
+	if len(raw) == 0 {
+		return []ProductSpecDefinition{}, models.JSON{}, nil
+	}
+	rawSpecs, ok := raw["specs"]
+	if !ok || rawSpecs == nil {
+		return []ProductSpecDefinition{}, models.JSON{}, nil
+	}
+	specList, ok := rawSpecs.([]interface{})
+	if !ok || len(specList) > productSpecMaxCount {
+		return nil, nil, ErrProductSpecSchemaInvalid
+	}
+	if len(specList) == 0 {
+		return []ProductSpecDefinition{}, models.JSON{}, nil
+	}
+
+	specs := make([]ProductSpecDefinition, 0, len(specList))
+	normalizedSpecs := make([]models.JSON, 0, len(specList))
+	keys := make(map[string]struct{}, len(specList))
+	for _, rawSpec := range specList {
+		specMap, ok := rawSpec.(map[string]interface{})
+		if !ok {
+			return nil, nil, ErrProductSpecSchemaInvalid
+		}
+		key, ok := trimStringField(specMap, "key")
+		if !ok || !productSpecKeyPattern.MatchString(key) {
+			return nil, nil, ErrProductSpecSchemaInvalid
+		}
+		if _, exists := keys[key]; exists {
+			return nil, nil, ErrProductSpecSchemaInvalid
+		}
+		keys[key] = struct{}{}
+		name, err := parseLocaleTextMapStrict(specMap, "name")
+		if err != nil {
+			return nil, nil, ErrProductSpecSchemaInvalid
+		}
+		values, err := parseProductSpecValues(specMap["values"])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		specs = append(specs, ProductSpecDefinition{Key: key, Name: name, Values: values})
+		normalizedValues := make([]models.JSON, 0, len(values))
+		for _, value := range values {
+			normalizedValues = append(normalizedValues, models.JSON{"value": value.Value, "label": value.Label})
+		}
+		normalizedSpecs = append(normalizedSpecs, models.JSON{"key": key, "name": name, "values": normalizedValues})
+	}
+	return specs, models.JSON{"specs": normalizedSpecs}, nil
+}
+
+func parseProductSpecValues(raw interface{}) ([]ProductSpecValue, error) {
+	valueList, ok := raw.([]interface{})
+	if !ok || len(valueList) == 0 || len(valueList) > productSpecMaxValues {
+		return nil, ErrProductSpecSchemaInvalid
+	}
+	values := make([]ProductSpecValue, 0, len(valueList))
+	seen := make(map[string]struct{}, len(valueList))
+	for _, rawValue := range valueList {
+		var item ProductSpecValue
+		switch typed := rawValue.(type) {
+		case string:
+			item = ProductSpecValue{Value: strings.TrimSpace(typed), Label: models.JSON{}}
+		case map[string]interface{}:
+			value, ok := trimStringField(typed, "value")
+			if !ok {
+				return nil, ErrProductSpecSchemaInvalid
+			}
+			label, err := parseLocaleTextMapStrict(typed, "label")
+			if err != nil {
+				return nil, ErrProductSpecSchemaInvalid
+			}
+			item = ProductSpecValue{Value: value, Label: label}
+		default:
+			return nil, ErrProductSpecSchemaInvalid
+		}
+		if item.Value == "" || utf8.RuneCountInString(item.Value) > productSpecValueMaxRunes {
+			return nil, ErrProductSpecSchemaInvalid
+		}
+		if _, exists := seen[item.Value]; exists {
+			return nil, ErrProductSpecSchemaInvalid
+		}
+		seen[item.Value] = struct{}{}
+		values = append(values, item)
+	}
+	return values, nil
+}
+
+// normalizeSKUSpecValues 按规格定义校验 SKU 规格值（需覆盖全部规格且取值合法），返回规范化值与组合键
+func normalizeSKUSpecValues(specs []ProductSpecDefinition, raw models.JSON) (models.JSON, string, error) {
+	if len(raw) != len(specs) {
+		return nil, "", ErrProductSKUSpecInvalid
+	}
+	normalized := make(models.JSON, len(specs))
+	parts := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		rawValue, ok := raw[spec.Key]
+		if !ok {
+			return nil, "", ErrProductSKUSpecInvalid
+		}
+		value, ok := rawValue.(string)
+		if !ok {
+			return nil, "", ErrProductSKUSpecInvalid
+		}
+		value = strings.TrimSpace(value)
+		if !spec.hasValue(value) {
+			return nil, "", ErrProductSKUSpecInvalid
+		}
+		normalized[spec.Key] = value
+		parts = append(parts, spec.Key+"="+value)
+	}
+	return normalized, strings.Join(parts, "\x1f"), nil
+}
+
+func (d ProductSpecDefinition) hasValue(value string) bool {
+	for _, item := range d.Values {
+		if item.Value == value {
+			return true
+		}
+	}
+	return false
+}
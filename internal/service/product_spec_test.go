@@ -0,0 +1,76 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestNormalizeProductSKUInputsValidatesSpecs(t *testing.T) {
+	specs, normalized, err := parseProductSpecSchema(models.JSON{"specs": []interface{}{
+		map[string]interface{}{
+			"key":    "region",
+			"name":   map[string]interface{}{"zh-CN": "地区", "en-US": "Region"},
+			"values": []interface{}{"CN", "US", map[string]interface{}{"value": "EU", "label": map[string]interface{}{"zh-CN": "欧洲"}}},
+		},
+		map[string]interface{}{
+			"key":    "duration",
+			"name":   map[string]interface{}{"zh-CN": "时长"},
+			"values": []interface{}{"1M", "12M"},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("parse spec schema failed: %v", err)
+	}
+	if len(specs) != 2 || len(specs[0].Values) != 3 || specs[0].Values[2].Label["zh-CN"] != "欧洲" {
+		t.Fatalf("unexpected specs: %+v", specs)
+	}
+	if list, ok := normalized["specs"].([]models.JSON); !ok || len(list) != 2 {
+		t.Fatalf("unexpected normalized schema: %#v", normalized)
+	}
+
+	sku := func(code string, values map[string]interface{}) ProductSKUInput {
+		return ProductSKUInput{SKUCode: code, SpecValuesJSON: values, PriceAmount: decimal.NewFromInt(10), ManualStockTotal: 5}
+	}
+	rows, _, stock, err := normalizeProductSKUInputs([]ProductSKUInput{
+		sku("CN-1M", map[string]interface{}{"region": " CN ", "duration": "1M"}),
+		sku("US-1M", map[string]interface{}{"region": "US", "duration": "1M"}),
+	}, constants.FulfillmentTypeManual, specs, nil)
+	if err != nil {
+		t.Fatalf("valid spec skus rejected: %v", err)
+	}
+	if rows[0].SpecValuesJSON["region"] != "CN" || stock != 10 {
+		t.Fatalf("unexpected normalized skus: %+v stock=%d", rows, stock)
+	}
+
+	invalid := [][]ProductSKUInput{
+		{sku("JP-1M", map[string]interface{}{"region": "JP", "duration": "1M"})},
+		{sku("CN", map[string]interface{}{"region": "CN"})},
+		{sku("CN-1M", map[string]interface{}{"region": "CN", "duration": "1M", "color": "red"})},
+		{
+			sku("CN-1M-A", map[string]interface{}{"region": "CN", "duration": "1M"}),
+			sku("CN-1M-B", map[string]interface{}{"region": "CN", "duration": "1M"}),
+		},
+	}
+	for _, inputs := range invalid {
+		if _, _, _, err := normalizeProductSKUInputs(inputs, constants.FulfillmentTypeManual, specs, nil); !errors.Is(err, ErrProductSKUSpecInvalid) {
+			t.Fatalf("expected sku spec invalid for %+v, got %v", inputs, err)
+		}
+	}
+
+	badSchemas := []models.JSON{
+		{"specs": "region"},
+		{"specs": []interface{}{map[string]interface{}{"key": "Region!", "values": []interface{}{"CN"}}}},
+		{"specs": []interface{}{map[string]interface{}{"key": "region", "values": []interface{}{"CN", "CN"}}}},
+		{"specs": []interface{}{map[string]interface{}{"key": "region", "values": []interface{}{}}}},
+	}
+	for _, raw := range badSchemas {
+		if _, _, err := parseProductSpecSchema(raw); !errors.Is(err, ErrProductSpecSchemaInvalid) {
+			t.Fatalf("expected schema invalid for %#v, got %v", raw, err)
+		}
+	}
+}
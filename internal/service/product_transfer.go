@@ -0,0 +1,943 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	productExportPageSize   = 200
+	productImportMaxRecords = 2000
+	productTransferSheet    = "products"
+)
+
+// errProductImportRollback 试运行或存在错误行时用于回滚整个导入事务
+var errProductImportRollback = errors.New("product import rollback")
+
+// productTransferColumns CSV/XLSX 列定义：每行一个 SKU，同一 slug 的多行组成一个商品，商品字段取该 slug 首行
+var productTransferColumns = []string{
+	"slug",
+	"category_id",
+	"title",
+	"description",
+	"content",
+	"seo_meta",
+	"price_amount",
+	"images",
+	"tags",
+	"purchase_type",
+	"fulfillment_type",
+	"manual_stock_total",
+	"manual_form_schema",
+	"secret_schema",
+	"key_generator",
+	"delivery_template",
+	"spec_schema",
+	"supplier_id",
+	"supplier_product_code",
+	"reveal_required",
+	"reveal_max_views",
+	"is_affiliate_enabled",
+	"is_active",
+	"publish_at",
+	"unpublish_at",
+	"sort_order",
+	"sku_code",
+	"sku_spec_values",
+	"sku_price_amount",
+	"sku_price_tiers",
+	"sku_manual_stock_total",
+	"sku_delivery_template",
+	"sku_is_active",
+	"sku_publish_at",
+	"sku_unpublish_at",
+	"sku_sort_order",
+	// 以下为后续新增的商品字段，追加在末尾以兼容旧版导出文件
+	"visibility",
+	"access_code",
+}
+
+// ProductTransferRecord 商品导入/导出记录（按 slug 匹配商品）
+type ProductTransferRecord struct {
+	Slug                string               `json:"slug"`
+	CategoryID          uint                 `json:"category_id"`
+	Title               models.JSON          `json:"title"`
+	Description         models.JSON          `json:"description"`
+	Content             models.JSON          `json:"content"`
+	SeoMeta             models.JSON          `json:"seo_meta"`
+	PriceAmount         decimal.Decimal      `json:"price_amount"`
+	Images              []string             `json:"images"`
+	Tags                []string             `json:"tags"`
+	PurchaseType        string               `json:"purchase_type"`
+	Visibility          string               `json:"visibility,omitempty"`
+	AccessCode          string               `json:"access_code,omitempty"`
+	FulfillmentType     string               `json:"fulfillment_type"`
+	ManualStockTotal    *int                 `json:"manual_stock_total,omitempty"`
+	ManualFormSchema    models.JSON          `json:"manual_form_schema"`
+	SecretSchema        models.JSON          `json:"secret_schema"`
+	KeyGenerator        models.JSON          `json:"key_generator"`
+	DeliveryTemplate    models.JSON          `json:"delivery_template"`
+	SpecSchema          models.JSON          `json:"spec_schema"`
+	SupplierID          *uint                `json:"supplier_id,omitempty"`
+	SupplierProductCode string               `json:"supplier_product_code"`
+	RevealRequired      *bool                `json:"reveal_required,omitempty"`
+	RevealMaxViews      int                  `json:"reveal_max_views"`
+	IsAffiliateEnabled  *bool                `json:"is_affiliate_enabled,omitempty"`
+	IsActive            *bool                `json:"is_active,omitempty"`
+	PublishAt           *time.Time           `json:"publish_at,omitempty"`
+	UnpublishAt         *time.Time           `json:"unpublish_at,omitempty"`
+	SortOrder           int                  `json:"sort_order"`
+	SKUs                []ProductTransferSKU `json:"skus"` // 为空表示单规格商品
+
+	row int
+}
+
+// ProductTransferSKU 商品 SKU 导入/导出记录（按 sku_code 匹配）
+type ProductTransferSKU struct {
+	SKUCode          string            `json:"sku_code"`
+	SpecValues       models.JSON       `json:"spec_values"`
+	PriceAmount      decimal.Decimal   `json:"price_amount"`
+	PriceTiers       models.PriceTiers `json:"price_tiers"`
+	ManualStockTotal int               `json:"manual_stock_total"`
+	DeliveryTemplate models.JSON       `json:"delivery_template"`
+	IsActive         *bool             `json:"is_active,omitempty"`
+	PublishAt        *time.Time        `json:"publish_at,omitempty"`
+	UnpublishAt      *time.Time        `json:"unpublish_at,omitempty"`
+	SortOrder        int               `json:"sort_order"`
+}
+
+// ProductExportInput 商品导出条件
+type ProductExportInput struct {
+	CategoryID      string
+	Search          string
+	FulfillmentType string
+	Format          string
+}
+
+// ProductImportInput 商品导入输入
+type ProductImportInput struct {
+	Reader   io.Reader
+	Filename string
+	Format   string
+	DryRun   bool
+	AdminID  uint
+}
+
+// ProductImportRowError 导入行错误（CSV/XLSX 行号含表头，JSON 为数组下标 +1）
+type ProductImportRowError struct {
+	Row   int    `json:"row"`
+	Slug  string `json:"slug"`
+	Error string `json:"error"`
+}
+
+// ProductImportResult 导入结果，存在错误行时整体回滚（Applied 为 false）
+type ProductImportResult struct {
+	DryRun  bool                    `json:"dry_run"`
+	Applied bool                    `json:"applied"`
+	Total   int                     `json:"total"`
+	Created int                     `json:"created"`
+	Updated int                     `json:"updated"`
+	Errors  []ProductImportRowError `json:"errors"`
+}
+
+// ExportProducts 导出商品（含 SKU、多语言字段、图片与交付配置），支持 json/csv/xlsx
+func (s *ProductService) ExportProducts(input ProductExportInput) ([]byte, string, error) {
+	format := strings.ToLower(strings.TrimSpace(input.Format))
+	switch format {
+	case constants.ExportFormatJSON, constants.ExportFormatCSV, constants.ExportFormatXLSX:
+	default:
+		return nil, "", ErrProductTransferFormatInvalid
+	}
+
+	records := make([]ProductTransferRecord, 0)
+	for page := 1; ; page++ {
+		products, _, err := s.repo.List(repository.ProductListFilter{
+			Page:            page,
+			PageSize:        productExportPageSize,
+			CategoryID:      strings.TrimSpace(input.CategoryID),
+			Search:          input.Search,
+			FulfillmentType: strings.TrimSpace(input.FulfillmentType),
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		for i := range products {
+			records = append(records, buildProductTransferRecord(&products[i]))
+		}
+		if len(products) < productExportPageSize {
+			break
+		}
+	}
+
+	switch format {
+	case constants.ExportFormatJSON:
+		content, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return nil, "", err
+		}
+		return content, "application/json; charset=utf-8", nil
+	case constants.ExportFormatCSV:
+		buffer := bytes.NewBuffer(nil)
+		writer := csv.NewWriter(buffer)
+		if err := writer.Write(productTransferColumns); err != nil {
+			return nil, "", err
+		}
+		for _, record := range records {
+			rows, err := flattenProductTransferRecord(record)
+			if err != nil {
+				return nil, "", err
+			}
+			if err := writer.WriteAll(rows); err != nil {
+				return nil, "", err
+			}
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return nil, "", err
+		}
+		return buffer.Bytes(), "text/csv; charset=utf-8", nil
+	default:
+		book := excelize.NewFile()
+		defer book.Close()
+		if err := book.SetSheetName(book.GetSheetName(0), productTransferSheet); err != nil {
+			return nil, "", err
+		}
+		rowNo := 1
+		writeRow := func(values []string) error {
+			cell, err := excelize.CoordinatesToCellName(1, rowNo)
+			if err != nil {
+				return err
+			}
+			row := make([]interface{}, len(values))
+			for i, value := range values {
+				row[i] = value
+			}
+			rowNo++
+			return book.SetSheetRow(productTransferSheet, cell, &row)
+		}
+		if err := writeRow(productTransferColumns); err != nil {
+			return nil, "", err
+		}
+		for _, record := range records {
+			rows, err := flattenProductTransferRecord(record)
+			if err != nil {
+				return nil, "", err
+			}
+			for _, row := range rows {
+				if err := writeRow(row); err != nil {
+					return nil, "", err
+				}
+			}
+		}
+		buffer, err := book.WriteToBuffer()
+		if err != nil {
+			return nil, "", err
+		}
+		return buffer.Bytes(), "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", nil
+	}
+}
+
+// ImportProducts 按 slug 创建或更新商品、按 sku_code 匹配 SKU；整个导入在同一事务内完成，
+// 任一行失败或试运行时全部回滚
+func (s *ProductService) ImportProducts(input ProductImportInput) (*ProductImportResult, error) {
+	if input.Reader == nil {
+		return nil, ErrProductImportInvalid
+	}
+	format, err := resolveProductImportFormat(input.Format, input.Filename)
+	if err != nil {
+		return nil, err
+	}
+	records, rowErrors, err := parseProductImport(input.Reader, format)
+	if err != nil {
+		return nil, err
+	}
+	if len(records)+len(rowErrors) == 0 || len(records) > productImportMaxRecords {
+		return nil, ErrProductImportInvalid
+	}
+
+	result := &ProductImportResult{
+		DryRun: input.DryRun,
+		Total:  len(records) + len(rowErrors),
+		Errors: rowErrors,
+	}
+	var txService *ProductService
+	txErr := s.repo.Transaction(func(tx *gorm.DB) error {
+		txService = s.withTx(tx)
+		for _, record := range records {
+			created, err := txService.importProductRecord(record, input.AdminID)
+			if err != nil {
+				result.Errors = append(result.Errors, ProductImportRowError{Row: record.row, Slug: record.Slug, Error: err.Error()})
+				continue
+			}
+			if created {
+				result.Created++
+			} else {
+				result.Updated++
+			}
+		}
+		if input.DryRun || len(result.Errors) > 0 {
+			return errProductImportRollback
+		}
+		return nil
+	})
+	if txErr != nil && !errors.Is(txErr, errProductImportRollback) {
+		return nil, txErr
+	}
+	result.Applied = txErr == nil
+	// 补货通知只在导入提交后触发，试运行与回滚不触发
+	if result.Applied {
+		for productID := range txService.pendingRestock {
+			s.stockSubSvc.TriggerRestock(productID)
+		}
+	}
+	return result, nil
+}
+
+// withTx 返回绑定事务的服务副本，Create/Update 内部事务将以保存点方式嵌套执行，补货通知登记到 pendingRestock
+func (s *ProductService) withTx(tx *gorm.DB) *ProductService {
+	txService := &ProductService{
+		repo:           s.repo.WithTx(tx),
+		cardSecretRepo: s.cardSecretRepo,
+		searchRepo:     s.searchRepo,
+		stockSubSvc:    s.stockSubSvc,
+		pendingRestock: make(map[uint]struct{}),
+	}
+	if s.revisionRepo != nil {
+		txService.revisionRepo = s.revisionRepo.WithTx(tx)
+	}
+	if s.productSKURepo != nil {
+		txService.productSKURepo = s.productSKURepo.WithTx(tx)
+	}
+	if s.supplierRepo != nil {
+		txService.supplierRepo = s.supplierRepo.WithTx(tx)
+	}
+	return txService
+}
+
+func (s *ProductService) importProductRecord(record ProductTransferRecord, adminID uint) (bool, error) {
+	if record.CategoryID == 0 {
+		return false, ErrProductImportInvalid
+	}
+	existing, err := s.repo.GetBySlug(record.Slug, false)
+	if err != nil {
+		return false, err
+	}
+	input := buildProductInputFromRecord(record, existing)
+	input.AdminID = adminID
+
+	if existing == nil {
+		_, err = s.Create(input)
+		return true, err
+	}
+	_, err = s.Update(strconv.FormatUint(uint64(existing.ID), 10), input)
+	return false, err
+}
+
+// buildProductInputFromRecord 将导入/快照记录转换为商品保存输入，SKU 按 sku_code 关联已有记录
+func buildProductInputFromRecord(record ProductTransferRecord, existing *models.Product) CreateProductInput {
+	input := CreateProductInput{
+		CategoryID:           record.CategoryID,
+		Slug:                 record.Slug,
+		SeoMetaJSON:          record.SeoMeta,
+		TitleJSON:            record.Title,
+		DescriptionJSON:      record.Description,
+		ContentJSON:          record.Content,
+		ManualFormSchemaJSON: record.ManualFormSchema,
+		SecretSchemaJSON:     record.SecretSchema,
+		KeyGeneratorJSON:     record.KeyGenerator,
+		DeliveryTmplJSON:     record.DeliveryTemplate,
+		SpecSchemaJSON:       record.SpecSchema,
+		PriceAmount:          record.PriceAmount,
+		Images:               record.Images,
+		Tags:                 record.Tags,
+		PurchaseType:         record.PurchaseType,
+		Visibility:           record.Visibility,
+		AccessCode:           record.AccessCode,
+		FulfillmentType:      record.FulfillmentType,
+		SupplierID:           record.SupplierID,
+		SupplierProductCode:  record.SupplierProductCode,
+		ManualStockTotal:     record.ManualStockTotal,
+		RevealRequired:       record.RevealRequired,
+		RevealMaxViews:       record.RevealMaxViews,
+		IsAffiliateEnabled:   record.IsAffiliateEnabled,
+		IsActive:             record.IsActive,
+		PublishAt:            record.PublishAt,
+		UnpublishAt:          record.UnpublishAt,
+		SortOrder:            record.SortOrder,
+	}
+	existingSKUIDs := map[string]uint{}
+	if existing != nil {
+		for _, sku := range existing.SKUs {
+			existingSKUIDs[strings.ToUpper(strings.TrimSpace(sku.SKUCode))] = sku.ID
+		}
+	}
+	for _, sku := range record.SKUs {
+		priceTiers := make([]PriceTierInput, 0, len(sku.PriceTiers))
+		for _, tier := range sku.PriceTiers {
+			priceTiers = append(priceTiers, PriceTierInput{MinQuantity: tier.MinQuantity, PriceAmount: tier.PriceAmount.Decimal})
+		}
+		input.SKUs = append(input.SKUs, ProductSKUInput{
+			ID:               existingSKUIDs[strings.ToUpper(strings.TrimSpace(sku.SKUCode))],
+			SKUCode:          sku.SKUCode,
+			SpecValuesJSON:   sku.SpecValues,
+			DeliveryTmplJSON: sku.DeliveryTemplate,
+			PriceAmount:      sku.PriceAmount,
+			PriceTiers:       priceTiers,
+			ManualStockTotal: sku.ManualStockTotal,
+			IsActive:         sku.IsActive,
+			PublishAt:        sku.PublishAt,
+			UnpublishAt:      sku.UnpublishAt,
+			SortOrder:        sku.SortOrder,
+		})
+	}
+	return input
+}
+
+// buildProductTransferRecord 转换为导出记录，仅含默认 SKU 的单规格商品不输出 SKU 列表
+func buildProductTransferRecord(product *models.Product) ProductTransferRecord {
+	manualStockTotal := product.ManualStockTotal
+	revealRequired := product.RevealRequired
+	isAffiliateEnabled := product.IsAffiliateEnabled
+	isActive := product.IsActive
+	record := ProductTransferRecord{
+		Slug:                product.Slug,
+		CategoryID:          product.CategoryID,
+		Title:               product.TitleJSON,
+		Description:         product.DescriptionJSON,
+		Content:             product.ContentJSON,
+		SeoMeta:             product.SeoMetaJSON,
+		PriceAmount:         product.PriceAmount.Decimal,
+		Images:              []string(product.Images),
+		Tags:                []string(product.Tags),
+		PurchaseType:        product.PurchaseType,
+		Visibility:          product.Visibility,
+		AccessCode:          product.AccessCode,
+		FulfillmentType:     product.FulfillmentType,
+		ManualStockTotal:    &manualStockTotal,
+		ManualFormSchema:    product.ManualFormSchemaJSON,
+		SecretSchema:        product.SecretSchemaJSON,
+		KeyGenerator:        product.KeyGeneratorJSON,
+		DeliveryTemplate:    product.DeliveryTmplJSON,
+		SpecSchema:          product.SpecSchemaJSON,
+		SupplierID:          product.SupplierID,
+		SupplierProductCode: product.SupplierProductCode,
+		RevealRequired:      &revealRequired,
+		RevealMaxViews:      product.RevealMaxViews,
+		IsAffiliateEnabled:  &isAffiliateEnabled,
+		IsActive:            &isActive,
+		PublishAt:           product.PublishAt,
+		UnpublishAt:         product.UnpublishAt,
+		SortOrder:           product.SortOrder,
+		SKUs:                []ProductTransferSKU{},
+	}
+	if isSingleModeProduct(product) {
+		return record
+	}
+	for _, sku := range product.SKUs {
+		skuActive := sku.IsActive
+		record.SKUs = append(record.SKUs, ProductTransferSKU{
+			SKUCode:          sku.SKUCode,
+			SpecValues:       sku.SpecValuesJSON,
+			PriceAmount:      sku.PriceAmount.Decimal,
+			PriceTiers:       sku.PriceTiers,
+			ManualStockTotal: sku.ManualStockTotal,
+			DeliveryTemplate: sku.DeliveryTmplJSON,
+			IsActive:         &skuActive,
+			PublishAt:        sku.PublishAt,
+			UnpublishAt:      sku.UnpublishAt,
+			SortOrder:        sku.SortOrder,
+		})
+	}
+	return record
+}
+
+func isSingleModeProduct(product *models.Product) bool {
+	if specs, err := ParseProductSpecDefinitions(product.SpecSchemaJSON); err != nil || len(specs) > 0 {
+		return false
+	}
+	active := 0
+	for _, sku := range product.SKUs {
+		if !sku.IsActive {
+			continue
+		}
+		active++
+		if !strings.EqualFold(strings.TrimSpace(sku.SKUCode), models.DefaultSKUCode) || len(sku.SpecValuesJSON) > 0 {
+			return false
+		}
+	}
+	return active <= 1
+}
+
+// flattenProductTransferRecord 展开为 CSV/XLSX 行：首行写商品字段，其余行仅写 slug 与 SKU 字段
+func flattenProductTransferRecord(record ProductTransferRecord) ([][]string, error) {
+	base := map[string]string{
+		"slug":                  record.Slug,
+		"category_id":           strconv.FormatUint(uint64(record.CategoryID), 10),
+		"price_amount":          record.PriceAmount.StringFixed(2),
+		"purchase_type":         record.PurchaseType,
+		"visibility":            record.Visibility,
+		"access_code":           record.AccessCode,
+		"fulfillment_type":      record.FulfillmentType,
+		"supplier_product_code": record.SupplierProductCode,
+		"reveal_max_views":      strconv.Itoa(record.RevealMaxViews),
+		"sort_order":            strconv.Itoa(record.SortOrder),
+		"reveal_required":       formatTransferBool(record.RevealRequired),
+		"is_affiliate_enabled":  formatTransferBool(record.IsAffiliateEnabled),
+		"is_active":             formatTransferBool(record.IsActive),
+		"publish_at":            formatTransferTime(record.PublishAt),
+		"unpublish_at":          formatTransferTime(record.UnpublishAt),
+	}
+	if record.ManualStockTotal != nil {
+		base["manual_stock_total"] = strconv.Itoa(*record.ManualStockTotal)
+	}
+	if record.SupplierID != nil {
+		base["supplier_id"] = strconv.FormatUint(uint64(*record.SupplierID), 10)
+	}
+	jsonColumns := map[string]interface{}{
+		"title":              record.Title,
+		"description":        record.Description,
+		"content":            record.Content,
+		"seo_meta":           record.SeoMeta,
+		"images":             record.Images,
+		"tags":               record.Tags,
+		"manual_form_schema": record.ManualFormSchema,
+		"secret_schema":      record.SecretSchema,
+		"key_generator":      record.KeyGenerator,
+		"delivery_template":  record.DeliveryTemplate,
+		"spec_schema":        record.SpecSchema,
+	}
+	for column, value := range jsonColumns {
+		text, err := formatTransferJSON(value)
+		if err != nil {
+			return nil, err
+		}
+		base[column] = text
+	}
+
+	if len(record.SKUs) == 0 {
+		return [][]string{buildTransferRow(base)}, nil
+	}
+	rows := make([][]string, 0, len(record.SKUs))
+	for i, sku := range record.SKUs {
+		values := map[string]string{"slug": record.Slug}
+		if i == 0 {
+			values = base
+		}
+		specValues, err := formatTransferJSON(sku.SpecValues)
+		if err != nil {
+			return nil, err
+		}
+		deliveryTemplate, err := formatTransferJSON(sku.DeliveryTemplate)
+		if err != nil {
+			return nil, err
+		}
+		priceTiers, err := formatTransferJSON(sku.PriceTiers)
+		if err != nil {
+			return nil, err
+		}
+		values["sku_code"] = sku.SKUCode
+		values["sku_spec_values"] = specValues
+		values["sku_price_amount"] = sku.PriceAmount.StringFixed(2)
+		values["sku_price_tiers"] = priceTiers
+		values["sku_manual_stock_total"] = strconv.Itoa(sku.ManualStockTotal)
+		values["sku_delivery_template"] = deliveryTemplate
+		values["sku_is_active"] = formatTransferBool(sku.IsActive)
+		values["sku_publish_at"] = formatTransferTime(sku.PublishAt)
+		values["sku_unpublish_at"] = formatTransferTime(sku.UnpublishAt)
+		values["sku_sort_order"] = strconv.Itoa(sku.SortOrder)
+		rows = append(rows, buildTransferRow(values))
+	}
+	return rows, nil
+}
+
+func buildTransferRow(values map[string]string) []string {
+	row := make([]string, len(productTransferColumns))
+	for i, column := range productTransferColumns {
+		row[i] = values[column]
+	}
+	return row
+}
+
+func formatTransferBool(value *bool) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatBool(*value)
+}
+
+func formatTransferTime(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.Format(time.RFC3339)
+}
+
+func formatTransferJSON(value interface{}) (string, error) {
+	switch typed := value.(type) {
+	case models.JSON:
+		if len(typed) == 0 {
+			return "", nil
+		}
+	case []string:
+		if len(typed) == 0 {
+			return "", nil
+		}
+	case models.PriceTiers:
+		if len(typed) == 0 {
+			return "", nil
+		}
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+
+func resolveProductImportFormat(format, filename string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(format))
+	if normalized == "" {
+		normalized = strings.TrimPrefix(strings.ToLower(filepath.Ext(strings.TrimSpace(filename))), ".")
+	}
+	switch normalized {
+	case constants.ExportFormatJSON, constants.ExportFormatCSV, constants.ExportFormatXLSX:
+		return normalized, nil
+	default:
+		return "", ErrProductTransferFormatInvalid
+	}
+}
+
+// parseProductImport 解析导入文件，文件级错误直接返回，单行错误收集到行错误列表
+func parseProductImport(reader io.Reader, format string) ([]ProductTransferRecord, []ProductImportRowError, error) {
+	var records []ProductTransferRecord
+	var rowErrors []ProductImportRowError
+	switch format {
+	case constants.ExportFormatJSON:
+		var items []json.RawMessage
+		if err := json.NewDecoder(reader).Decode(&items); err != nil {
+			return nil, nil, ErrProductImportInvalid
+		}
+		for i, item := range items {
+			var record ProductTransferRecord
+			if err := json.Unmarshal(item, &record); err != nil {
+				rowErrors = append(rowErrors, ProductImportRowError{Row: i + 1, Error: err.Error()})
+				continue
+			}
+			record.row = i + 1
+			records = append(records, record)
+		}
+	case constants.ExportFormatCSV, constants.ExportFormatXLSX:
+		rows, err := readProductTransferTable(reader, format)
+		if err != nil {
+			return nil, nil, ErrProductImportInvalid
+		}
+		records, rowErrors, err = parseProductTransferRows(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, ErrProductTransferFormatInvalid
+	}
+
+	valid := make([]ProductTransferRecord, 0, len(records))
+	seen := make(map[string]int, len(records))
+	for _, record := range records {
+		record.Slug = strings.TrimSpace(record.Slug)
+		if record.Slug == "" {
+			rowErrors = append(rowErrors, ProductImportRowError{Row: record.row, Error: ErrProductImportInvalid.Error()})
+			continue
+		}
+		if firstRow, ok := seen[record.Slug]; ok {
+			rowErrors = append(rowErrors, ProductImportRowError{Row: record.row, Slug: record.Slug, Error: fmt.Sprintf("duplicate slug (first at row %d)", firstRow)})
+			continue
+		}
+		seen[record.Slug] = record.row
+		valid = append(valid, record)
+	}
+	return valid, rowErrors, nil
+}
+
+func readProductTransferTable(reader io.Reader, format string) ([][]string, error) {
+	if format == constants.ExportFormatCSV {
+		csvReader := csv.NewReader(reader)
+		csvReader.FieldsPerRecord = -1
+		return csvReader.ReadAll()
+	}
+	book, err := excelize.OpenReader(reader)
+	if err != nil {
+		return nil, err
+	}
+	defer book.Close()
+	sheets := book.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, nil
+	}
+	return book.GetRows(sheets[0])
+}
+
+// parseProductTransferRows 按表头解析行，同一 slug 的后续行追加为 SKU
+func parseProductTransferRows(rows [][]string) ([]ProductTransferRecord, []ProductImportRowError, error) {
+	headerIndex := -1
+	for i, row := range rows {
+		if !isBlankRecord(row) {
+			headerIndex = i
+			break
+		}
+	}
+	if headerIndex < 0 {
+		return nil, nil, ErrProductImportInvalid
+	}
+	columns := make(map[string]int, len(rows[headerIndex]))
+	for i, cell := range rows[headerIndex] {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(cell, "\ufeff")))] = i
+	}
+	if _, ok := columns["slug"]; !ok {
+		return nil, nil, ErrProductImportInvalid
+	}
+
+	var records []ProductTransferRecord
+	var rowErrors []ProductImportRowError
+	recordIndex := make(map[string]int)
+	failedSlugs := make(map[string]struct{})
+	for i := headerIndex + 1; i < len(rows); i++ {
+		if isBlankRecord(rows[i]) {
+			continue
+		}
+		rowNo := i + 1
+		cell := func(column string) string {
+			index, ok := columns[column]
+			if !ok || index >= len(rows[i]) {
+				return ""
+			}
+			return strings.TrimSpace(rows[i][index])
+		}
+		slug := cell("slug")
+		if _, failed := failedSlugs[slug]; failed {
+			continue
+		}
+		position, exists := recordIndex[slug]
+		if !exists {
+			record, err := parseProductTransferRow(cell)
+			if err != nil {
+				rowErrors = append(rowErrors, ProductImportRowError{Row: rowNo, Slug: slug, Error: err.Error()})
+				failedSlugs[slug] = struct{}{}
+				continue
+			}
+			record.row = rowNo
+			records = append(records, record)
+			position = len(records) - 1
+			recordIndex[slug] = position
+		}
+		if cell("sku_code") == "" {
+			continue
+		}
+		sku, err := parseProductTransferSKU(cell)
+		if err != nil {
+			rowErrors = append(rowErrors, ProductImportRowError{Row: rowNo, Slug: slug, Error: err.Error()})
+			failedSlugs[slug] = struct{}{}
+			continue
+		}
+		records[position].SKUs = append(records[position].SKUs, sku)
+	}
+
+	valid := make([]ProductTransferRecord, 0, len(records))
+	for _, record := range records {
+		if _, failed := failedSlugs[record.Slug]; !failed {
+			valid = append(valid, record)
+		}
+	}
+	return valid, rowErrors, nil
+}
+
+func parseProductTransferRow(cell func(string) string) (ProductTransferRecord, error) {
+	record := ProductTransferRecord{
+		Slug:                cell("slug"),
+		PurchaseType:        cell("purchase_type"),
+		Visibility:          cell("visibility"),
+		AccessCode:          cell("access_code"),
+		FulfillmentType:     cell("fulfillment_type"),
+		SupplierProductCode: cell("supplier_product_code"),
+	}
+	var err error
+	if record.CategoryID, err = parseTransferUint(cell("category_id"), "category_id"); err != nil {
+		return record, err
+	}
+	if record.PriceAmount, err = parseTransferDecimal(cell("price_amount"), "price_amount"); err != nil {
+		return record, err
+	}
+	if record.RevealMaxViews, err = parseTransferInt(cell("reveal_max_views"), "reveal_max_views"); err != nil {
+		return record, err
+	}
+	if record.SortOrder, err = parseTransferInt(cell("sort_order"), "sort_order"); err != nil {
+		return record, err
+	}
+	if raw := cell("manual_stock_total"); raw != "" {
+		value, err := parseTransferInt(raw, "manual_stock_total")
+		if err != nil {
+			return record, err
+		}
+		record.ManualStockTotal = &value
+	}
+	if raw := cell("supplier_id"); raw != "" {
+		value, err := parseTransferUint(raw, "supplier_id")
+		if err != nil {
+			return record, err
+		}
+		record.SupplierID = &value
+	}
+	boolColumns := []struct {
+		column string
+		target **bool
+	}{
+		{"reveal_required", &record.RevealRequired},
+		{"is_affiliate_enabled", &record.IsAffiliateEnabled},
+		{"is_active", &record.IsActive},
+	}
+	for _, item := range boolColumns {
+		if *item.target, err = parseTransferBool(cell(item.column), item.column); err != nil {
+			return record, err
+		}
+	}
+	if record.PublishAt, err = parseTransferTime(cell("publish_at"), "publish_at"); err != nil {
+		return record, err
+	}
+	if record.UnpublishAt, err = parseTransferTime(cell("unpublish_at"), "unpublish_at"); err != nil {
+		return record, err
+	}
+	jsonColumns := []struct {
+		column string
+		target interface{}
+	}{
+		{"title", &record.Title},
+		{"description", &record.Description},
+		{"content", &record.Content},
+		{"seo_meta", &record.SeoMeta},
+		{"images", &record.Images},
+		{"tags", &record.Tags},
+		{"manual_form_schema", &record.ManualFormSchema},
+		{"secret_schema", &record.SecretSchema},
+		{"key_generator", &record.KeyGenerator},
+		{"delivery_template", &record.DeliveryTemplate},
+		{"spec_schema", &record.SpecSchema},
+	}
+	for _, item := range jsonColumns {
+		if err := parseTransferJSON(cell(item.column), item.column, item.target); err != nil {
+			return record, err
+		}
+	}
+	return record, nil
+}
+
+func parseProductTransferSKU(cell func(string) string) (ProductTransferSKU, error) {
+	sku := ProductTransferSKU{SKUCode: cell("sku_code")}
+	var err error
+	if sku.PriceAmount, err = parseTransferDecimal(cell("sku_price_amount"), "sku_price_amount"); err != nil {
+		return sku, err
+	}
+	if sku.ManualStockTotal, err = parseTransferInt(cell("sku_manual_stock_total"), "sku_manual_stock_total"); err != nil {
+		return sku, err
+	}
+	if sku.SortOrder, err = parseTransferInt(cell("sku_sort_order"), "sku_sort_order"); err != nil {
+		return sku, err
+	}
+	if sku.IsActive, err = parseTransferBool(cell("sku_is_active"), "sku_is_active"); err != nil {
+		return sku, err
+	}
+	if sku.PublishAt, err = parseTransferTime(cell("sku_publish_at"), "sku_publish_at"); err != nil {
+		return sku, err
+	}
+	if sku.UnpublishAt, err = parseTransferTime(cell("sku_unpublish_at"), "sku_unpublish_at"); err != nil {
+		return sku, err
+	}
+	if err := parseTransferJSON(cell("sku_spec_values"), "sku_spec_values", &sku.SpecValues); err != nil {
+		return sku, err
+	}
+	if err := parseTransferJSON(cell("sku_delivery_template"), "sku_delivery_template", &sku.DeliveryTemplate); err != nil {
+		return sku, err
+	}
+	if err := parseTransferJSON(cell("sku_price_tiers"), "sku_price_tiers", &sku.PriceTiers); err != nil {
+		return sku, err
+	}
+	return sku, nil
+}
+
+func parseTransferInt(raw, column string) (int, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %q", column, raw)
+	}
+	return value, nil
+}
+
+func parseTransferUint(raw, column string) (uint, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %q", column, raw)
+	}
+	return uint(value), nil
+}
+
+func parseTransferDecimal(raw, column string) (decimal.Decimal, error) {
+	if raw == "" {
+		return decimal.Zero, nil
+	}
+	value, err := decimal.NewFromString(raw)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid %s: %q", column, raw)
+	}
+	return value, nil
+}
+
+func parseTransferBool(raw, column string) (*bool, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %q", column, raw)
+	}
+	return &value, nil
+}
+
+func parseTransferTime(raw, column string) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %q", column, raw)
+	}
+	return &value, nil
+}
+
+func parseTransferJSON(raw, column string, target interface{}) error {
+	if raw == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(raw), target); err != nil {
+		return fmt.Errorf("invalid %s: %v", column, err)
+	}
+	return nil
+}
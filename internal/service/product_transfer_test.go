@@ -0,0 +1,180 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/glebarez/sqlite"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+func setupProductTransferTest(t *testing.T) (*gorm.DB, *ProductService) {
+	t.Helper()
+	dsn := fmt.Sprintf("file:product_transfer_test_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Category{}, &models.Product{}, &models.ProductSKU{}, &models.CardSecret{}); err != nil {
+		t.Fatalf("auto migrate failed: %v", err)
+	}
+	svc := NewProductService(
+		repository.NewProductRepository(db),
+		repository.NewProductSKURepository(db),
+		repository.NewCardSecretRepository(db),
+		repository.NewFulfillmentSupplierRepository(db),
+	)
+	return db, svc
+}
+
+func TestProductExportImportRoundTrip(t *testing.T) {
+	db, svc := setupProductTransferTest(t)
+	manualStock := 5
+	if _, err := svc.Create(CreateProductInput{
+		CategoryID:       1,
+		Slug:             "steam-wallet",
+		TitleJSON:        map[string]interface{}{"zh-CN": "Steam 钱包", "en-US": "Steam Wallet"},
+		PriceAmount:      decimal.NewFromInt(10),
+		Images:           []string{"/uploads/steam.png"},
+		Tags:             []string{"steam"},
+		FulfillmentType:  constants.FulfillmentTypeManual,
+		ManualStockTotal: &manualStock,
+		SKUs: []ProductSKUInput{
+			{SKUCode: "CN", PriceAmount: decimal.NewFromInt(10), ManualStockTotal: 3},
+			{SKUCode: "US", PriceAmount: decimal.NewFromInt(12), ManualStockTotal: 4},
+		},
+	}); err != nil {
+		t.Fatalf("create product failed: %v", err)
+	}
+	if _, err := svc.Create(CreateProductInput{
+		CategoryID:       1,
+		Slug:             "office",
+		TitleJSON:        map[string]interface{}{"zh-CN": "Office"},
+		PriceAmount:      decimal.NewFromInt(99),
+		FulfillmentType:  constants.FulfillmentTypeManual,
+		ManualStockTotal: &manualStock,
+	}); err != nil {
+		t.Fatalf("create single product failed: %v", err)
+	}
+
+	content, _, err := svc.ExportProducts(ProductExportInput{Format: constants.ExportFormatCSV})
+	if err != nil {
+		t.Fatalf("export csv failed: %v", err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	if err != nil {
+		t.Fatalf("read exported csv failed: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("expected header + 2 sku rows + 1 single product row, got %d", len(rows))
+	}
+
+	// 修改价格并新增一个商品，试运行不应落库
+	edited := strings.Replace(string(content), "12.00", "15.00", 1)
+	edited += "new-product,1,\"{\"\"zh-CN\"\":\"\"新品\"\"}\",,,,8.00,,,,manual,-1,,,,,,,,,,,,,,,,,,,\n"
+	result, err := svc.ImportProducts(ProductImportInput{Reader: strings.NewReader(edited), Filename: "products.csv", DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run import failed: %v", err)
+	}
+	if len(result.Errors) != 0 || result.Created != 1 || result.Updated != 2 || result.Applied {
+		t.Fatalf("unexpected dry run result: %+v", result)
+	}
+	var count int64
+	db.Model(&models.Product{}).Where("slug = ?", "new-product").Count(&count)
+	if count != 0 {
+		t.Fatalf("dry run should not persist products")
+	}
+
+	result, err = svc.ImportProducts(ProductImportInput{Reader: strings.NewReader(edited), Filename: "products.csv"})
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if !result.Applied || result.Created != 1 || result.Updated != 2 {
+		t.Fatalf("unexpected import result: %+v", result)
+	}
+	var skus []models.ProductSKU
+	db.Joins("JOIN products ON products.id = product_skus.product_id").
+		Where("products.slug = ?", "steam-wallet").Order("product_skus.id ASC").Find(&skus)
+	if len(skus) != 2 || !skus[1].PriceAmount.Decimal.Equal(decimal.NewFromInt(15)) {
+		t.Fatalf("expected sku matched by code and price updated, got %+v", skus)
+	}
+
+	// 任一行失败时整体回滚
+	broken := "slug,category_id,title,price_amount,fulfillment_type\n" +
+		"office,1,\"{\"\"zh-CN\"\":\"\"Office\"\"}\",1.00,manual\n" +
+		"bad-price,1,\"{\"\"zh-CN\"\":\"\"Bad\"\"}\",abc,manual\n"
+	result, err = svc.ImportProducts(ProductImportInput{Reader: strings.NewReader(broken), Format: constants.ExportFormatCSV})
+	if err != nil {
+		t.Fatalf("import with row error failed: %v", err)
+	}
+	if result.Applied || len(result.Errors) != 1 || result.Errors[0].Row != 3 {
+		t.Fatalf("expected row 3 error and rollback, got %+v", result)
+	}
+	var office models.Product
+	db.Where("slug = ?", "office").First(&office)
+	if !office.PriceAmount.Decimal.Equal(decimal.NewFromInt(99)) {
+		t.Fatalf("failed import should not update office price, got %s", office.PriceAmount.String())
+	}
+
+	jsonContent, _, err := svc.ExportProducts(ProductExportInput{Format: constants.ExportFormatJSON})
+	if err != nil {
+		t.Fatalf("export json failed: %v", err)
+	}
+	result, err = svc.ImportProducts(ProductImportInput{Reader: bytes.NewReader(jsonContent), Format: constants.ExportFormatJSON})
+	if err != nil || !result.Applied || result.Updated != 3 {
+		t.Fatalf("json round trip failed: %+v err=%v", result, err)
+	}
+	xlsxContent, _, err := svc.ExportProducts(ProductExportInput{Format: constants.ExportFormatXLSX})
+	if err != nil {
+		t.Fatalf("export xlsx failed: %v", err)
+	}
+	result, err = svc.ImportProducts(ProductImportInput{Reader: bytes.NewReader(xlsxContent), Filename: "products.xlsx", DryRun: true})
+	if err != nil || len(result.Errors) != 0 || result.Updated != 3 {
+		t.Fatalf("xlsx dry run failed: %+v err=%v", result, err)
+	}
+}
+
+func TestProductImportDefersRestockTrigger(t *testing.T) {
+	db, svc := setupProductTransferTest(t)
+	svc.SetStockSubscriptionService(&StockSubscriptionService{})
+	manualStock := 0
+	input := CreateProductInput{
+		CategoryID:       1,
+		Slug:             "sold-out-box",
+		TitleJSON:        map[string]interface{}{"zh-CN": "售罄礼盒"},
+		PriceAmount:      decimal.NewFromInt(20),
+		FulfillmentType:  constants.FulfillmentTypeManual,
+		ManualStockTotal: &manualStock,
+	}
+	product, err := svc.Create(input)
+	if err != nil {
+		t.Fatalf("create product failed: %v", err)
+	}
+
+	txService := svc.withTx(db)
+	if txService.stockSubSvc != svc.stockSubSvc {
+		t.Fatalf("expected tx service to keep stock subscription service")
+	}
+	restocked := 5
+	input.ManualStockTotal = &restocked
+	if _, err := txService.Update(fmt.Sprintf("%d", product.ID), input); err != nil {
+		t.Fatalf("update in tx service failed: %v", err)
+	}
+	if _, ok := txService.pendingRestock[product.ID]; !ok {
+		t.Fatalf("expected restock trigger to be deferred until commit, got %v", txService.pendingRestock)
+	}
+	if svc.pendingRestock != nil {
+		t.Fatalf("root service should trigger restock immediately")
+	}
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestProductVisibility(t *testing.T) {
+	db, productSvc := setupProductTransferTest(t)
+	if err := db.AutoMigrate(&models.Promotion{}); err != nil {
+		t.Fatalf("auto migrate promotion failed: %v", err)
+	}
+	unlimited := constants.ManualStockUnlimited
+
+	create := func(slug, visibility, accessCode string) uint {
+		product, err := productSvc.Create(CreateProductInput{
+			CategoryID:       1,
+			Slug:             slug,
+			TitleJSON:        map[string]interface{}{"zh-CN": slug},
+			PriceAmount:      decimal.NewFromInt(10),
+			FulfillmentType:  constants.FulfillmentTypeManual,
+			ManualStockTotal: &unlimited,
+			Visibility:       visibility,
+			AccessCode:       accessCode,
+		})
+		if err != nil {
+			t.Fatalf("create %s product failed: %v", slug, err)
+		}
+		return product.ID
+	}
+	publicID := create("open", "", "")
+	unlistedID := create("private-link", constants.ProductVisibilityUnlisted, "ignored")
+	protectedID := create("vip-deal", constants.ProductVisibilityProtected, "  VIP2026 ")
+
+	if _, err := productSvc.Create(CreateProductInput{
+		CategoryID:  1,
+		Slug:        "no-code",
+		TitleJSON:   map[string]interface{}{"zh-CN": "no-code"},
+		PriceAmount: decimal.NewFromInt(10),
+		Visibility:  constants.ProductVisibilityProtected,
+	}); !errors.Is(err, ErrProductVisibilityInvalid) {
+		t.Fatalf("expected protected product without code to be rejected, got %v", err)
+	}
+	if _, err := productSvc.Create(CreateProductInput{
+		CategoryID:  1,
+		Slug:        "bad-visibility",
+		TitleJSON:   map[string]interface{}{"zh-CN": "bad"},
+		PriceAmount: decimal.NewFromInt(10),
+		Visibility:  "secret",
+	}); !errors.Is(err, ErrProductVisibilityInvalid) {
+		t.Fatalf("expected unknown visibility to be rejected, got %v", err)
+	}
+
+	products, total, err := productSvc.ListPublic("", "", 1, 20)
+	if err != nil {
+		t.Fatalf("list public failed: %v", err)
+	}
+	if total != 1 || products[0].ID != publicID {
+		t.Fatalf("expected only public product listed, total=%d", total)
+	}
+
+	unlisted, err := productSvc.GetPublicBySlug("private-link")
+	if err != nil || unlisted.ID != unlistedID || unlisted.AccessCode != "" {
+		t.Fatalf("expected unlisted product reachable by slug without code, got %+v err=%v", unlisted, err)
+	}
+	protected, err := productSvc.GetPublicBySlug("vip-deal")
+	if err != nil {
+		t.Fatalf("get protected product failed: %v", err)
+	}
+	if err := CheckProductAccess(protected, ""); !errors.Is(err, ErrProductAccessCodeRequired) {
+		t.Fatalf("expected access code required, got %v", err)
+	}
+	if err := CheckProductAccess(protected, "vip2026"); !errors.Is(err, ErrProductAccessCodeInvalid) {
+		t.Fatalf("expected access code invalid, got %v", err)
+	}
+	if err := CheckProductAccess(protected, "VIP2026"); err != nil {
+		t.Fatalf("expected access code accepted, got %v", err)
+	}
+
+	// 更新时访问码留空保持原访问码
+	if _, err := productSvc.Update(strconv.FormatUint(uint64(protectedID), 10), CreateProductInput{
+		CategoryID:       1,
+		Slug:             "vip-deal",
+		TitleJSON:        map[string]interface{}{"zh-CN": "vip-deal"},
+		PriceAmount:      decimal.NewFromInt(12),
+		FulfillmentType:  constants.FulfillmentTypeManual,
+		ManualStockTotal: &unlimited,
+	}); err != nil {
+		t.Fatalf("update protected product failed: %v", err)
+	}
+	protected, _ = productSvc.GetPublicBySlug("vip-deal")
+	if protected.Visibility != constants.ProductVisibilityProtected || protected.AccessCode != "VIP2026" {
+		t.Fatalf("expected visibility and access code kept, got %s/%s", protected.Visibility, protected.AccessCode)
+	}
+
+	orderSvc := NewOrderService(nil, repository.NewProductRepository(db), repository.NewProductSKURepository(db), nil, nil, nil, repository.NewPromotionRepository(db), nil, nil, nil, nil, 15)
+	if _, err := orderSvc.buildOrderResult(orderCreateParams{
+		UserID: 1,
+		Items:  []CreateOrderItem{{ProductID: protectedID, Quantity: 1}},
+	}); !errors.Is(err, ErrProductAccessCodeRequired) {
+		t.Fatalf("expected order without access code rejected, got %v", err)
+	}
+	if _, err := orderSvc.buildOrderResult(orderCreateParams{
+		UserID: 1,
+		Items: []CreateOrderItem{
+			{ProductID: protectedID, Quantity: 1},
+			{ProductID: protectedID, Quantity: 1, AccessCode: "VIP2026"},
+		},
+	}); err != nil {
+		t.Fatalf("expected order with access code accepted, got %v", err)
+	}
+	if _, err := orderSvc.buildOrderResult(orderCreateParams{
+		UserID: 1,
+		Items:  []CreateOrderItem{{ProductID: unlistedID, Quantity: 1}},
+	}); err != nil {
+		t.Fatalf("expected unlisted product orderable by direct link, got %v", err)
+	}
+}
@@ -0,0 +1,460 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/i18n"
+	"github.com/dujiao-next/internal/logger"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+const (
+	receiptContentType       = "application/pdf"
+	receiptFontFamily        = "receipt"
+	receiptInvoiceRetryTimes = 3
+)
+
+// ReceiptFile 收据文件
+type ReceiptFile struct {
+	InvoiceNo   string
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// ReceiptService 订单收据/发票服务
+type ReceiptService struct {
+	orderRepo      repository.OrderRepository
+	invoiceRepo    repository.OrderInvoiceRepository
+	paymentRepo    repository.PaymentRepository
+	settingService *SettingService
+	fontPath       string
+}
+
+// NewReceiptService 创建订单收据服务
+func NewReceiptService(
+	orderRepo repository.OrderRepository,
+	invoiceRepo repository.OrderInvoiceRepository,
+	paymentRepo repository.PaymentRepository,
+	settingService *SettingService,
+	fontPath string,
+) *ReceiptService {
+	return &ReceiptService{
+		orderRepo:      orderRepo,
+		invoiceRepo:    invoiceRepo,
+		paymentRepo:    paymentRepo,
+		settingService: settingService,
+		fontPath:       strings.TrimSpace(fontPath),
+	}
+}
+
+// CheckFont 校验收据字体：内置字体不含中文字形，未配置可用的 TTF 字体时拒绝生成，避免输出乱码收据
+func (s *ReceiptService) CheckFont() error {
+	if s == nil || s.fontPath == "" {
+		return ErrReceiptFontMissing
+	}
+	info, err := os.Stat(s.fontPath)
+	if err != nil || info.IsDir() {
+		return ErrReceiptFontMissing
+	}
+	return nil
+}
+
+// GetUserReceipt 获取用户订单收据（childOrderID 为 0 时生成父订单收据）
+func (s *ReceiptService) GetUserReceipt(userID, orderID, childOrderID uint, locale string) (*ReceiptFile, error) {
+	order, err := s.orderRepo.GetByIDAndUser(orderID, userID)
+	if err != nil {
+		return nil, ErrOrderFetchFailed
+	}
+	if order == nil {
+		return nil, ErrOrderNotFound
+	}
+	return s.renderForOrder(order, childOrderID, locale)
+}
+
+// GetGuestReceipt 获取游客订单收据
+func (s *ReceiptService) GetGuestReceipt(email, password string, orderID, childOrderID uint, locale string) (*ReceiptFile, error) {
+	email = strings.TrimSpace(email)
+	password = strings.TrimSpace(password)
+	if email == "" {
+		return nil, ErrGuestEmailRequired
+	}
+	if password == "" {
+		return nil, ErrGuestPasswordRequired
+	}
+	order, err := s.orderRepo.GetByIDAndGuest(orderID, email, password)
+	if err != nil {
+		return nil, ErrOrderFetchFailed
+	}
+	if order == nil {
+		return nil, ErrGuestOrderNotFound
+	}
+	return s.renderForOrder(order, childOrderID, locale)
+}
+
+// BuildEmailAttachment 构建随交付邮件发送的收据附件，未开启时返回 nil
+func (s *ReceiptService) BuildEmailAttachment(order *models.Order, locale string) (*ReceiptFile, error) {
+	if s == nil || order == nil {
+		return nil, nil
+	}
+	setting, err := s.settingService.GetReceiptSetting()
+	if err != nil {
+		return nil, err
+	}
+	if !setting.Enabled || !setting.AttachToEmail {
+		return nil, nil
+	}
+	if err := s.CheckFont(); err != nil {
+		return nil, err
+	}
+	if !isReceiptOrderPaid(order) {
+		return nil, nil
+	}
+	return s.render(order, setting, locale)
+}
+
+func (s *ReceiptService) renderForOrder(order *models.Order, childOrderID uint, locale string) (*ReceiptFile, error) {
+	setting, err := s.settingService.GetReceiptSetting()
+	if err != nil {
+		return nil, ErrReceiptGenerateFailed
+	}
+	if !setting.Enabled {
+		return nil, ErrReceiptDisabled
+	}
+	if err := s.CheckFont(); err != nil {
+		return nil, err
+	}
+	target := order
+	if childOrderID > 0 && childOrderID != order.ID {
+		target = nil
+		for i := range order.Children {
+			if order.Children[i].ID == childOrderID {
+				target = &order.Children[i]
+				break
+			}
+		}
+		if target == nil {
+			return nil, ErrOrderNotFound
+		}
+		// 子订单的支付信息挂在父订单上
+		if target.PaidAt == nil {
+			target.PaidAt = order.PaidAt
+		}
+	}
+	if !isReceiptOrderPaid(target) {
+		return nil, ErrReceiptNotAvailable
+	}
+	return s.render(target, setting, locale)
+}
+
+func (s *ReceiptService) render(order *models.Order, setting ReceiptSetting, locale string) (*ReceiptFile, error) {
+	fillOrderItemsFromChildren(order)
+	invoice, err := s.ensureInvoice(order.ID, setting)
+	if err != nil {
+		logger.Warnw("receipt_ensure_invoice_failed", "order_id", order.ID, "error", err)
+		return nil, ErrReceiptGenerateFailed
+	}
+	paymentOrderID := order.ID
+	if order.ParentID != nil && *order.ParentID > 0 {
+		paymentOrderID = *order.ParentID
+	}
+	payments, err := s.paymentRepo.ListByOrderID(paymentOrderID)
+	if err != nil {
+		return nil, ErrReceiptGenerateFailed
+	}
+	content, err := renderReceiptPDF(receiptDocument{
+		Order:    order,
+		Invoice:  invoice,
+		Payments: payments,
+		Setting:  setting,
+		Locale:   normalizeLocale(locale),
+		FontPath: s.fontPath,
+	})
+	if err != nil {
+		logger.Warnw("receipt_render_failed", "order_id", order.ID, "error", err)
+		return nil, ErrReceiptGenerateFailed
+	}
+	return &ReceiptFile{
+		InvoiceNo:   invoice.InvoiceNo,
+		Filename:    fmt.Sprintf("receipt-%s.pdf", invoice.InvoiceNo),
+		ContentType: receiptContentType,
+		Content:     content,
+	}, nil
+}
+
+// ensureInvoice 获取或分配订单发票号，发票号按顺序递增且独立于订单号
+func (s *ReceiptService) ensureInvoice(orderID uint, setting ReceiptSetting) (*models.OrderInvoice, error) {
+	existing, err := s.invoiceRepo.GetByOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+	var lastErr error
+	for attempt := 0; attempt < receiptInvoiceRetryTimes; attempt++ {
+		var created *models.OrderInvoice
+		lastErr = s.invoiceRepo.Transaction(func(tx *gorm.DB) error {
+			repo := s.invoiceRepo.WithTx(tx)
+			maxSeq, err := repo.GetMaxSequence()
+			if err != nil {
+				return err
+			}
+			seq := maxSeq + 1
+			invoice := &models.OrderInvoice{
+				OrderID:   orderID,
+				Sequence:  seq,
+				InvoiceNo: formatInvoiceNo(setting.InvoicePrefix, setting.NumberPadding, seq),
+				IssuedAt:  time.Now(),
+			}
+			if err := repo.Create(invoice); err != nil {
+				return err
+			}
+			created = invoice
+			return nil
+		})
+		if lastErr == nil {
+			return created, nil
+		}
+		// 并发分配冲突时，可能已由其他请求为该订单创建
+		existing, err := s.invoiceRepo.GetByOrderID(orderID)
+		if err == nil && existing != nil {
+			return existing, nil
+		}
+	}
+	return nil, lastErr
+}
+
+func formatInvoiceNo(prefix string, padding int, seq int64) string {
+	if padding <= 0 {
+		padding = receiptNumberPaddingDefault
+	}
+	return fmt.Sprintf("%s%0*d", prefix, padding, seq)
+}
+
+func isReceiptOrderPaid(order *models.Order) bool {
+	if order == nil || order.PaidAt == nil {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(order.Status)) {
+	case constants.OrderStatusPaid,
+		constants.OrderStatusFulfilling,
+		constants.OrderStatusPartiallyDelivered,
+		constants.OrderStatusDelivered,
+		constants.OrderStatusCompleted:
+		return true
+	default:
+		return false
+	}
+}
+
+type receiptDocument struct {
+	Order    *models.Order
+	Invoice  *models.OrderInvoice
+	Payments []models.Payment
+	Setting  ReceiptSetting
+	Locale   string
+	FontPath string
+}
+
+func renderReceiptPDF(doc receiptDocument) ([]byte, error) {
+	pdf, err := newReceiptPDF(doc.FontPath)
+	if err != nil {
+		return nil, err
+	}
+	locale := doc.Locale
+	order := doc.Order
+	template := doc.Setting.Templates.ResolveLocaleTemplate(locale)
+	label := func(key string) string {
+		return i18n.T(locale, key)
+	}
+	currency := strings.TrimSpace(order.Currency)
+	money := func(value models.Money) string {
+		return strings.TrimSpace(value.String() + " " + currency)
+	}
+
+	pdf.AddPage()
+	title := template.Title
+	if title == "" {
+		title = i18n.T(locale, "receipt.title")
+	}
+	pdf.SetFont(receiptFontFamily, "", 18)
+	pdf.CellFormat(0, 10, title, "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+
+	pdf.SetFont(receiptFontFamily, "", 10)
+	if doc.Setting.CompanyName != "" {
+		pdf.CellFormat(0, 5, doc.Setting.CompanyName, "", 1, "L", false, 0, "")
+	}
+	if doc.Setting.CompanyAddress != "" {
+		pdf.MultiCell(0, 5, doc.Setting.CompanyAddress, "", "L", false)
+	}
+	if doc.Setting.TaxID != "" {
+		pdf.CellFormat(0, 5, label("receipt.tax_id")+": "+doc.Setting.TaxID, "", 1, "L", false, 0, "")
+	}
+	if doc.Setting.ContactEmail != "" {
+		pdf.CellFormat(0, 5, doc.Setting.ContactEmail, "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	infoRows := [][2]string{
+		{label("receipt.invoice_no"), doc.Invoice.InvoiceNo},
+		{label("receipt.issued_at"), doc.Invoice.IssuedAt.Format("2006-01-02 15:04")},
+		{label("receipt.order_no"), order.OrderNo},
+	}
+	if order.PaidAt != nil {
+		infoRows = append(infoRows, [2]string{label("receipt.paid_at"), order.PaidAt.Format("2006-01-02 15:04")})
+	}
+	if billTo := strings.TrimSpace(order.GuestEmail); billTo != "" {
+		infoRows = append(infoRows, [2]string{label("receipt.bill_to"), billTo})
+	}
+	for _, row := range infoRows {
+		pdf.CellFormat(40, 6, row[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 6, row[1], "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	colWidths := []float64{95, 20, 35, 40}
+	headers := []string{label("receipt.item"), label("receipt.quantity"), label("receipt.unit_price"), label("receipt.amount")}
+	aligns := []string{"L", "C", "R", "R"}
+	pdf.SetFillColor(240, 240, 240)
+	for i, header := range headers {
+		pdf.CellFormat(colWidths[i], 7, header, "1", 0, aligns[i], true, 0, "")
+	}
+	pdf.Ln(-1)
+	for _, item := range order.Items {
+		name := resolveReceiptItemTitle(item, locale)
+		if skuCode := resolveReceiptItemSKUCode(item); skuCode != "" {
+			name = name + " (" + skuCode + ")"
+		}
+		pdf.CellFormat(colWidths[0], 7, truncateReceiptText(name, 48), "1", 0, aligns[0], false, 0, "")
+		pdf.CellFormat(colWidths[1], 7, fmt.Sprintf("%d", item.Quantity), "1", 0, aligns[1], false, 0, "")
+		pdf.CellFormat(colWidths[2], 7, money(item.UnitPrice), "1", 0, aligns[2], false, 0, "")
+		pdf.CellFormat(colWidths[3], 7, money(item.TotalPrice), "1", 0, aligns[3], false, 0, "")
+		pdf.Ln(-1)
+	}
+	pdf.Ln(2)
+
+	summaryRows := [][2]string{{label("receipt.subtotal"), money(order.OriginalAmount)}}
+	if order.PromotionDiscountAmount.Decimal.GreaterThan(decimal.Zero) {
+		summaryRows = append(summaryRows, [2]string{label("receipt.promotion_discount"), "-" + money(order.PromotionDiscountAmount)})
+	}
+	if order.DiscountAmount.Decimal.GreaterThan(decimal.Zero) {
+		summaryRows = append(summaryRows, [2]string{label("receipt.coupon_discount"), "-" + money(order.DiscountAmount)})
+	}
+	summaryRows = append(summaryRows, [2]string{label("receipt.total"), money(order.TotalAmount)})
+	if order.RefundedAmount.Decimal.GreaterThan(decimal.Zero) {
+		summaryRows = append(summaryRows, [2]string{label("receipt.refunded"), "-" + money(order.RefundedAmount)})
+	}
+	for _, row := range summaryRows {
+		pdf.CellFormat(colWidths[0]+colWidths[1]+colWidths[2], 6, row[0], "", 0, "R", false, 0, "")
+		pdf.CellFormat(colWidths[3], 6, row[1], "", 1, "R", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	methods := resolveReceiptPaymentMethods(order, doc.Payments, locale)
+	if len(methods) > 0 {
+		pdf.CellFormat(40, 6, label("receipt.payment_method"), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 6, strings.Join(methods, ", "), "", 1, "L", false, 0, "")
+	}
+	if template.Note != "" {
+		pdf.Ln(4)
+		pdf.MultiCell(0, 5, template.Note, "", "L", false)
+	}
+	if template.Footer != "" {
+		pdf.SetY(-30)
+		pdf.SetFont(receiptFontFamily, "", 8)
+		pdf.MultiCell(0, 4, template.Footer, "", "C", false)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// newReceiptPDF 使用配置的 TTF 字体创建 PDF 文档，字体加载失败时返回错误而不回退到无中文字形的内置字体
+func newReceiptPDF(fontPath string) (*fpdf.Fpdf, error) {
+	if fontPath == "" {
+		return nil, ErrReceiptFontMissing
+	}
+	// fpdf 会把字体路径拼接到字体目录下，绝对路径无法直接加载，这里自行读取字体文件
+	fontBytes, err := os.ReadFile(fontPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrReceiptFontMissing, err)
+	}
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(receiptFontFamily, "", fontBytes)
+	if pdf.Err() {
+		return nil, fmt.Errorf("%w: %v", ErrReceiptFontMissing, pdf.Error())
+	}
+	return pdf, nil
+}
+
+func resolveReceiptItemTitle(item models.OrderItem, locale string) string {
+	candidates := []string{locale, constants.LocaleZhCN, constants.LocaleEnUS, constants.LocaleZhTW}
+	for _, key := range candidates {
+		if value, ok := item.TitleJSON[key]; ok {
+			if text := strings.TrimSpace(fmt.Sprintf("%v", value)); text != "" {
+				return text
+			}
+		}
+	}
+	return fmt.Sprintf("#%d", item.ProductID)
+}
+
+func resolveReceiptItemSKUCode(item models.OrderItem) string {
+	if item.SKUSnapshotJSON == nil {
+		return ""
+	}
+	value, ok := item.SKUSnapshotJSON["sku_code"]
+	if !ok || value == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprintf("%v", value))
+}
+
+func resolveReceiptPaymentMethods(order *models.Order, payments []models.Payment, locale string) []string {
+	methods := make([]string, 0, len(payments)+1)
+	if order.WalletPaidAmount.Decimal.GreaterThan(decimal.Zero) {
+		methods = append(methods, i18n.T(locale, "receipt.payment_wallet"))
+	}
+	seen := make(map[string]struct{})
+	for _, payment := range payments {
+		if payment.Status != constants.PaymentStatusSuccess {
+			continue
+		}
+		channel := strings.TrimSpace(payment.ChannelType)
+		if channel == "" {
+			channel = strings.TrimSpace(payment.ProviderType)
+		}
+		if channel == "" {
+			continue
+		}
+		if _, ok := seen[channel]; ok {
+			continue
+		}
+		seen[channel] = struct{}{}
+		methods = append(methods, channel)
+	}
+	return methods
+}
+
+func truncateReceiptText(text string, maxRune int) string {
+	runes := []rune(text)
+	if len(runes) <= maxRune {
+		return text
+	}
+	return string(runes[:maxRune-1]) + "…"
+}
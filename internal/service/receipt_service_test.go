@@ -0,0 +1,233 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/glebarez/sqlite"
+	"github.com/shopspring/decimal"
+	"golang.org/x/image/font/gofont/goregular"
+	"gorm.io/gorm"
+)
+
+// writeReceiptTestFont 写出测试用 TTF 字体（Go Regular），生产环境需配置包含中文字形的字体
+func writeReceiptTestFont(t *testing.T) string {
+	t.Helper()
+	fontPath := filepath.Join(t.TempDir(), "receipt.ttf")
+	if err := os.WriteFile(fontPath, goregular.TTF, 0o600); err != nil {
+		t.Fatalf("write receipt font failed: %v", err)
+	}
+	return fontPath
+}
+
+func setupReceiptServiceTest(t *testing.T) (*gorm.DB, *ReceiptService, *SettingService) {
+	t.Helper()
+	dsn := fmt.Sprintf("file:receipt_service_test_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&models.Order{},
+		&models.OrderItem{},
+		&models.Fulfillment{},
+		&models.Payment{},
+		&models.OrderInvoice{},
+		&models.Setting{},
+	); err != nil {
+		t.Fatalf("auto migrate failed: %v", err)
+	}
+	models.DB = db
+	settingSvc := NewSettingService(repository.NewSettingRepository(db))
+	svc := NewReceiptService(
+		repository.NewOrderRepository(db),
+		repository.NewOrderInvoiceRepository(db),
+		repository.NewPaymentRepository(db),
+		settingSvc,
+		writeReceiptTestFont(t),
+	)
+	return db, svc, settingSvc
+}
+
+func createReceiptTestOrder(t *testing.T, db *gorm.DB, orderNo string, paid bool) (*models.Order, *models.Order) {
+	t.Helper()
+	now := time.Now()
+	status := constants.OrderStatusPendingPayment
+	var paidAt *time.Time
+	if paid {
+		status = constants.OrderStatusDelivered
+		paidAt = &now
+	}
+	amount := models.NewMoneyFromDecimal(decimal.NewFromInt(20))
+	parent := &models.Order{
+		OrderNo:        orderNo,
+		UserID:         1,
+		Status:         status,
+		Currency:       "CNY",
+		OriginalAmount: amount,
+		TotalAmount:    amount,
+		PaidAt:         paidAt,
+	}
+	if err := db.Create(parent).Error; err != nil {
+		t.Fatalf("create parent order failed: %v", err)
+	}
+	child := &models.Order{
+		OrderNo:        orderNo + "-01",
+		ParentID:       &parent.ID,
+		UserID:         1,
+		Status:         status,
+		Currency:       "CNY",
+		OriginalAmount: amount,
+		TotalAmount:    amount,
+		PaidAt:         paidAt,
+	}
+	if err := db.Create(child).Error; err != nil {
+		t.Fatalf("create child order failed: %v", err)
+	}
+	item := &models.OrderItem{
+		OrderID:         child.ID,
+		ProductID:       1,
+		TitleJSON:       models.JSON{"zh-CN": "测试商品", "en-US": "Test Product"},
+		UnitPrice:       models.NewMoneyFromDecimal(decimal.NewFromInt(10)),
+		Quantity:        2,
+		TotalPrice:      amount,
+		FulfillmentType: constants.FulfillmentTypeAuto,
+	}
+	if err := db.Create(item).Error; err != nil {
+		t.Fatalf("create order item failed: %v", err)
+	}
+	payment := &models.Payment{
+		OrderID:         parent.ID,
+		ChannelID:       1,
+		ProviderType:    "epay",
+		ChannelType:     "alipay",
+		InteractionMode: "redirect",
+		Amount:          amount,
+		Currency:        "CNY",
+		Status:          constants.PaymentStatusSuccess,
+	}
+	if err := db.Create(payment).Error; err != nil {
+		t.Fatalf("create payment failed: %v", err)
+	}
+	return parent, child
+}
+
+func TestReceiptServiceGeneratesSequentialInvoiceNo(t *testing.T) {
+	db, svc, settingSvc := setupReceiptServiceTest(t)
+	if _, err := settingSvc.UpdateReceiptSetting(ReceiptSetting{
+		Enabled:       true,
+		CompanyName:   "D&J Studio",
+		TaxID:         "TAX-001",
+		InvoicePrefix: "R-",
+		NumberPadding: 4,
+	}); err != nil {
+		t.Fatalf("update receipt setting failed: %v", err)
+	}
+	first, firstChild := createReceiptTestOrder(t, db, "DJ-RECEIPT-001", true)
+	second, _ := createReceiptTestOrder(t, db, "DJ-RECEIPT-002", true)
+
+	receipt, err := svc.GetUserReceipt(1, first.ID, 0, "en-US")
+	if err != nil {
+		t.Fatalf("get receipt failed: %v", err)
+	}
+	if receipt.InvoiceNo != "R-0001" {
+		t.Fatalf("expected invoice no R-0001, got %s", receipt.InvoiceNo)
+	}
+	if !bytes.HasPrefix(receipt.Content, []byte("%PDF")) {
+		t.Fatalf("expected pdf content")
+	}
+
+	again, err := svc.GetUserReceipt(1, first.ID, 0, "zh-CN")
+	if err != nil {
+		t.Fatalf("get receipt again failed: %v", err)
+	}
+	if again.InvoiceNo != receipt.InvoiceNo {
+		t.Fatalf("expected invoice no to be stable, got %s", again.InvoiceNo)
+	}
+
+	childReceipt, err := svc.GetUserReceipt(1, first.ID, firstChild.ID, "en-US")
+	if err != nil {
+		t.Fatalf("get child receipt failed: %v", err)
+	}
+	if childReceipt.InvoiceNo != "R-0002" {
+		t.Fatalf("expected child invoice no R-0002, got %s", childReceipt.InvoiceNo)
+	}
+
+	secondReceipt, err := svc.GetUserReceipt(1, second.ID, 0, "en-US")
+	if err != nil {
+		t.Fatalf("get second receipt failed: %v", err)
+	}
+	if secondReceipt.InvoiceNo != "R-0003" {
+		t.Fatalf("expected invoice no R-0003, got %s", secondReceipt.InvoiceNo)
+	}
+}
+
+func TestReceiptServiceRejectsUnpaidOrDisabled(t *testing.T) {
+	db, svc, settingSvc := setupReceiptServiceTest(t)
+	unpaid, _ := createReceiptTestOrder(t, db, "DJ-RECEIPT-003", false)
+	paid, _ := createReceiptTestOrder(t, db, "DJ-RECEIPT-004", true)
+
+	if _, err := svc.GetUserReceipt(1, paid.ID, 0, "en-US"); !errors.Is(err, ErrReceiptDisabled) {
+		t.Fatalf("expected ErrReceiptDisabled, got %v", err)
+	}
+	if _, err := settingSvc.UpdateReceiptSetting(ReceiptSetting{Enabled: true, CompanyName: "D&J Studio"}); err != nil {
+		t.Fatalf("update receipt setting failed: %v", err)
+	}
+	if _, err := svc.GetUserReceipt(1, unpaid.ID, 0, "en-US"); !errors.Is(err, ErrReceiptNotAvailable) {
+		t.Fatalf("expected ErrReceiptNotAvailable, got %v", err)
+	}
+	if _, err := svc.GetUserReceipt(2, paid.ID, 0, "en-US"); !errors.Is(err, ErrOrderNotFound) {
+		t.Fatalf("expected ErrOrderNotFound, got %v", err)
+	}
+	attachment, err := svc.BuildEmailAttachment(paid, "en-US")
+	if err != nil || attachment != nil {
+		t.Fatalf("expected no attachment when attach_to_email disabled, got %v %v", attachment, err)
+	}
+}
+
+func TestReceiptServiceRequiresFontByDefault(t *testing.T) {
+	db, svc, settingSvc := setupReceiptServiceTest(t)
+	// 默认配置 receipt.font_path 为空：内置字体无中文字形，拒绝生成而不是输出乱码
+	svc.fontPath = ""
+	if _, err := settingSvc.UpdateReceiptSetting(ReceiptSetting{Enabled: true, AttachToEmail: true, CompanyName: "独角数卡"}); err != nil {
+		t.Fatalf("update receipt setting failed: %v", err)
+	}
+	paid, _ := createReceiptTestOrder(t, db, "DJ-RECEIPT-005", true)
+
+	if _, err := svc.GetUserReceipt(1, paid.ID, 0, "zh-CN"); !errors.Is(err, ErrReceiptFontMissing) {
+		t.Fatalf("expected ErrReceiptFontMissing for zh-CN receipt, got %v", err)
+	}
+	if attachment, err := svc.BuildEmailAttachment(paid, "zh-CN"); !errors.Is(err, ErrReceiptFontMissing) || attachment != nil {
+		t.Fatalf("expected email attachment to be skipped without font, got %v %v", attachment, err)
+	}
+	var invoices int64
+	db.Model(&models.OrderInvoice{}).Count(&invoices)
+	if invoices != 0 {
+		t.Fatalf("expected no invoice number consumed without font, got %d", invoices)
+	}
+
+	svc.fontPath = filepath.Join(t.TempDir(), "missing.ttf")
+	if err := svc.CheckFont(); !errors.Is(err, ErrReceiptFontMissing) {
+		t.Fatalf("expected missing font file to be rejected, got %v", err)
+	}
+
+	svc.fontPath = writeReceiptTestFont(t)
+	receipt, err := svc.GetUserReceipt(1, paid.ID, 0, "zh-CN")
+	if err != nil {
+		t.Fatalf("get zh-CN receipt with font failed: %v", err)
+	}
+	if !bytes.Contains(receipt.Content, []byte("/FontFile2")) {
+		t.Fatalf("expected configured TTF font to be embedded")
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+)
+
+const (
+	receiptInvoicePrefixDefault = "INV-"
+	receiptInvoicePrefixMaxRune = 20
+	receiptNumberPaddingDefault = 8
+	receiptNumberPaddingMin     = 1
+	receiptNumberPaddingMax     = 20
+	receiptTextMaxRune          = 200
+	receiptNoteMaxRune          = 1000
+)
+
+var receiptInvoicePrefixPattern = regexp.MustCompile(`^[A-Za-z0-9_\-/]*$`)
+
+// ReceiptLocalizedTemplate 收据单语言模板
+type ReceiptLocalizedTemplate struct {
+	Title  string `json:"title"`
+	Note   string `json:"note"`
+	Footer string `json:"footer"`
+}
+
+// ReceiptTemplateSetting 收据多语言模板
+type ReceiptTemplateSetting struct {
+	ZHCN ReceiptLocalizedTemplate `json:"zh-CN"`
+	ZHTW ReceiptLocalizedTemplate `json:"zh-TW"`
+	ENUS ReceiptLocalizedTemplate `json:"en-US"`
+}
+
+// ReceiptSetting 收据/发票配置
+type ReceiptSetting struct {
+	Enabled        bool                   `json:"enabled"`
+	AttachToEmail  bool                   `json:"attach_to_email"`
+	CompanyName    string                 `json:"company_name"`
+	CompanyAddress string                 `json:"company_address"`
+	TaxID          string                 `json:"tax_id"`
+	ContactEmail   string                 `json:"contact_email"`
+	InvoicePrefix  string                 `json:"invoice_prefix"`
+	NumberPadding  int                    `json:"number_padding"`
+	Templates      ReceiptTemplateSetting `json:"templates"`
+}
+
+// ReceiptDefaultSetting 默认收据配置
+func ReceiptDefaultSetting() ReceiptSetting {
+	return NormalizeReceiptSetting(ReceiptSetting{
+		Enabled:       false,
+		AttachToEmail: false,
+		InvoicePrefix: receiptInvoicePrefixDefault,
+		NumberPadding: receiptNumberPaddingDefault,
+	})
+}
+
+// NormalizeReceiptSetting 归一化收据配置
+func NormalizeReceiptSetting(setting ReceiptSetting) ReceiptSetting {
+	setting.CompanyName = normalizeSettingTextWithRuneLimit(setting.CompanyName, receiptTextMaxRune)
+	setting.CompanyAddress = normalizeSettingTextWithRuneLimit(setting.CompanyAddress, receiptTextMaxRune)
+	setting.TaxID = normalizeSettingTextWithRuneLimit(setting.TaxID, receiptTextMaxRune)
+	setting.ContactEmail = normalizeSettingTextWithRuneLimit(setting.ContactEmail, receiptTextMaxRune)
+	setting.InvoicePrefix = normalizeSettingTextWithRuneLimit(setting.InvoicePrefix, receiptInvoicePrefixMaxRune)
+	if setting.NumberPadding < receiptNumberPaddingMin || setting.NumberPadding > receiptNumberPaddingMax {
+		setting.NumberPadding = receiptNumberPaddingDefault
+	}
+	setting.Templates.ZHCN = normalizeReceiptLocalizedTemplate(setting.Templates.ZHCN)
+	setting.Templates.ZHTW = normalizeReceiptLocalizedTemplate(setting.Templates.ZHTW)
+	setting.Templates.ENUS = normalizeReceiptLocalizedTemplate(setting.Templates.ENUS)
+	return setting
+}
+
+// ValidateReceiptSetting 校验收据配置
+func ValidateReceiptSetting(setting ReceiptSetting) error {
+	normalized := NormalizeReceiptSetting(setting)
+	if !receiptInvoicePrefixPattern.MatchString(normalized.InvoicePrefix) {
+		return fmt.Errorf("%w: 发票号前缀仅支持字母、数字、-、_、/", ErrReceiptConfigInvalid)
+	}
+	if normalized.Enabled && normalized.CompanyName == "" {
+		return fmt.Errorf("%w: 启用收据时公司名称不能为空", ErrReceiptConfigInvalid)
+	}
+	return nil
+}
+
+// ResolveLocaleTemplate 按语言选择收据模板
+func (s ReceiptTemplateSetting) ResolveLocaleTemplate(locale string) ReceiptLocalizedTemplate {
+	switch normalizeNotificationLocale(locale) {
+	case constants.LocaleZhTW:
+		return s.ZHTW
+	case constants.LocaleEnUS:
+		return s.ENUS
+	default:
+		return s.ZHCN
+	}
+}
+
+// ReceiptSettingToMap 将收据配置转换为 settings 存储结构
+func ReceiptSettingToMap(setting ReceiptSetting) map[string]interface{} {
+	normalized := NormalizeReceiptSetting(setting)
+	return map[string]interface{}{
+		"enabled":         normalized.Enabled,
+		"attach_to_email": normalized.AttachToEmail,
+		"company_name":    normalized.CompanyName,
+		"company_address": normalized.CompanyAddress,
+		"tax_id":          normalized.TaxID,
+		"contact_email":   normalized.ContactEmail,
+		"invoice_prefix":  normalized.InvoicePrefix,
+		"number_padding":  normalized.NumberPadding,
+		"templates": map[string]interface{}{
+			constants.LocaleZhCN: receiptLocalizedTemplateToMap(normalized.Templates.ZHCN),
+			constants.LocaleZhTW: receiptLocalizedTemplateToMap(normalized.Templates.ZHTW),
+			constants.LocaleEnUS: receiptLocalizedTemplateToMap(normalized.Templates.ENUS),
+		},
+	}
+}
+
+func receiptSettingFromJSON(raw models.JSON, fallback ReceiptSetting) ReceiptSetting {
+	next := fallback
+	if raw == nil {
+		return next
+	}
+	next.Enabled = readBool(raw, "enabled", next.Enabled)
+	next.AttachToEmail = readBool(raw, "attach_to_email", next.AttachToEmail)
+	next.CompanyName = readString(raw, "company_name", next.CompanyName)
+	next.CompanyAddress = readString(raw, "company_address", next.CompanyAddress)
+	next.TaxID = readString(raw, "tax_id", next.TaxID)
+	next.ContactEmail = readString(raw, "contact_email", next.ContactEmail)
+	next.InvoicePrefix = readString(raw, "invoice_prefix", next.InvoicePrefix)
+	next.NumberPadding = readInt(raw, "number_padding", next.NumberPadding)
+	if templatesMap := toStringAnyMap(raw["templates"]); templatesMap != nil {
+		if localeMap := toStringAnyMap(templatesMap[constants.LocaleZhCN]); localeMap != nil {
+			next.Templates.ZHCN = receiptLocalizedTemplateFromMap(localeMap, next.Templates.ZHCN)
+		}
+		if localeMap := toStringAnyMap(templatesMap[constants.LocaleZhTW]); localeMap != nil {
+			next.Templates.ZHTW = receiptLocalizedTemplateFromMap(localeMap, next.Templates.ZHTW)
+		}
+		if localeMap := toStringAnyMap(templatesMap[constants.LocaleEnUS]); localeMap != nil {
+			next.Templates.ENUS = receiptLocalizedTemplateFromMap(localeMap, next.Templates.ENUS)
+		}
+	}
+	return NormalizeReceiptSetting(next)
+}
+
+func normalizeReceiptSettingMap(value map[string]interface{}) models.JSON {
+	setting := receiptSettingFromJSON(models.JSON(value), ReceiptDefaultSetting())
+	return models.JSON(ReceiptSettingToMap(setting))
+}
+
+func receiptLocalizedTemplateFromMap(raw map[string]interface{}, fallback ReceiptLocalizedTemplate) ReceiptLocalizedTemplate {
+	next := fallback
+	next.Title = readString(raw, "title", next.Title)
+	next.Note = readString(raw, "note", next.Note)
+	next.Footer = readString(raw, "footer", next.Footer)
+	return next
+}
+
+func receiptLocalizedTemplateToMap(template ReceiptLocalizedTemplate) map[string]interface{} {
+	return map[string]interface{}{
+		"title":  template.Title,
+		"note":   template.Note,
+		"footer": template.Footer,
+	}
+}
+
+func normalizeReceiptLocalizedTemplate(template ReceiptLocalizedTemplate) ReceiptLocalizedTemplate {
+	template.Title = normalizeSettingTextWithRuneLimit(template.Title, receiptTextMaxRune)
+	template.Note = normalizeSettingTextWithRuneLimit(template.Note, receiptNoteMaxRune)
+	template.Footer = normalizeSettingTextWithRuneLimit(template.Footer, receiptNoteMaxRune)
+	return template
+}
+
+// GetReceiptSetting 获取收据设置（优先 settings，空时回退默认）
+func (s *SettingService) GetReceiptSetting() (ReceiptSetting, error) {
+	fallback := ReceiptDefaultSetting()
+	if s == nil {
+		return fallback, nil
+	}
+	value, err := s.GetByKey(constants.SettingKeyReceiptConfig)
+	if err != nil {
+		return fallback, err
+	}
+	if value == nil {
+		return fallback, nil
+	}
+	return receiptSettingFromJSON(value, fallback), nil
+}
+
+// UpdateReceiptSetting 更新收据设置
+func (s *SettingService) UpdateReceiptSetting(setting ReceiptSetting) (ReceiptSetting, error) {
+	normalized := NormalizeReceiptSetting(setting)
+	if err := ValidateReceiptSetting(normalized); err != nil {
+		return ReceiptDefaultSetting(), err
+	}
+	if _, err := s.Update(constants.SettingKeyReceiptConfig, ReceiptSettingToMap(normalized)); err != nil {
+		return ReceiptDefaultSetting(), err
+	}
+	return normalized, nil
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"time"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// SecretRevealService 交付内容主动查看服务（隐藏内容、记录查看凭证、限制查看次数）
+type SecretRevealService struct {
+	orderRepo       repository.OrderRepository
+	fulfillmentRepo repository.FulfillmentRepository
+}
+
+// NewSecretRevealService 创建交付内容查看服务
+func NewSecretRevealService(orderRepo repository.OrderRepository, fulfillmentRepo repository.FulfillmentRepository) *SecretRevealService {
+	return &SecretRevealService{
+		orderRepo:       orderRepo,
+		fulfillmentRepo: fulfillmentRepo,
+	}
+}
+
+// RevealInput 查看交付内容输入
+type RevealInput struct {
+	Order        *models.Order
+	ChildOrderID uint
+	UserID       uint
+	ClientIP     string
+	UserAgent    string
+}
+
+// ApplyToOrder 隐藏需主动查看的交付内容，需在模板渲染与下载签名之后调用
+func (s *SecretRevealService) ApplyToOrder(order *models.Order) {
+	if s == nil || order == nil {
+		return
+	}
+	hideRevealRequiredFulfillment(order)
+	for i := range order.Children {
+		hideRevealRequiredFulfillment(&order.Children[i])
+	}
+}
+
+func hideRevealRequiredFulfillment(order *models.Order) {
+	if order.Fulfillment == nil || !orderRequiresReveal(order) {
+		return
+	}
+	fulfillment := order.Fulfillment
+	fulfillment.Payload = ""
+	fulfillment.Rendered = ""
+	fulfillment.LogisticsJSON = nil
+	fulfillment.Downloads = nil
+	for i := range fulfillment.Batches {
+		fulfillment.Batches[i].Payload = ""
+	}
+	fulfillment.Hidden = true
+}
+
+// Reveal 买家主动查看交付内容：累加查看次数并记录 IP 与 User-Agent，返回内容可见的订单
+func (s *SecretRevealService) Reveal(input RevealInput) (*models.Order, error) {
+	target := resolveRevealTarget(input.Order, input.ChildOrderID)
+	if target == nil || target.Fulfillment == nil {
+		return nil, ErrSecretRevealUnavailable
+	}
+	fulfillment := target.Fulfillment
+	if fulfillment.Status != constants.FulfillmentStatusDelivered && fulfillment.Status != constants.FulfillmentStatusPartial {
+		return nil, ErrSecretRevealUnavailable
+	}
+	if target.Status == constants.OrderStatusCanceled {
+		return nil, ErrSecretRevealUnavailable
+	}
+	if !orderRequiresReveal(target) {
+		return target, nil
+	}
+
+	maxViews := orderRevealMaxViews(target)
+	now := time.Now()
+	err := s.orderRepo.Transaction(func(tx *gorm.DB) error {
+		fulfillmentRepo := s.fulfillmentRepo.WithTx(tx)
+		ok, err := fulfillmentRepo.ConsumeReveal(fulfillment.ID, maxViews, now)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrSecretRevealLimitExceeded
+		}
+		return fulfillmentRepo.CreateRevealLog(&models.OrderSecretReveal{
+			OrderID:       target.ID,
+			FulfillmentID: fulfillment.ID,
+			UserID:        input.UserID,
+			ViewNo:        fulfillment.RevealCount + 1,
+			ClientIP:      truncateDownloadLogField(input.ClientIP, 64),
+			UserAgent:     truncateDownloadLogField(input.UserAgent, 512),
+			CreatedAt:     now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	fulfillment.RevealCount++
+	if fulfillment.RevealedAt == nil {
+		fulfillment.RevealedAt = &now
+	}
+	return target, nil
+}
+
+// ListEvidence 获取订单（含子订单）的查看凭证，供管理端处理争议
+func (s *SecretRevealService) ListEvidence(order *models.Order) ([]models.OrderSecretReveal, error) {
+	if order == nil {
+		return []models.OrderSecretReveal{}, nil
+	}
+	orderIDs := []uint{order.ID}
+	for _, child := range order.Children {
+		orderIDs = append(orderIDs, child.ID)
+	}
+	return s.fulfillmentRepo.ListRevealLogsByOrders(orderIDs)
+}
+
+// resolveRevealTarget 定位需查看的订单：未指定子订单时使用自身交付记录或唯一子订单
+func resolveRevealTarget(order *models.Order, childOrderID uint) *models.Order {
+	if order == nil {
+		return nil
+	}
+	if childOrderID == 0 || childOrderID == order.ID {
+		if order.Fulfillment != nil || len(order.Children) != 1 {
+			return order
+		}
+		return &order.Children[0]
+	}
+	for i := range order.Children {
+		if order.Children[i].ID == childOrderID {
+			return &order.Children[i]
+		}
+	}
+	return nil
+}
+
+func orderRequiresReveal(order *models.Order) bool {
+	for _, item := range order.Items {
+		if item.RevealRequired {
+			return true
+		}
+	}
+	return false
+}
+
+// orderRevealMaxViews 取订单项中最严格的查看次数上限，0 表示不限制
+func orderRevealMaxViews(order *models.Order) int {
+	maxViews := 0
+	for _, item := range order.Items {
+		if !item.RevealRequired || item.RevealMaxViews <= 0 {
+			continue
+		}
+		if maxViews == 0 || item.RevealMaxViews < maxViews {
+			maxViews = item.RevealMaxViews
+		}
+	}
+	return maxViews
+}
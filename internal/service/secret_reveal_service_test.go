@@ -0,0 +1,101 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestSecretRevealHidesAndLimitsViews(t *testing.T) {
+	db := setupFulfillmentServiceTestDB(t)
+	if err := db.AutoMigrate(&models.OrderSecretReveal{}); err != nil {
+		t.Fatalf("auto migrate reveal table failed: %v", err)
+	}
+	now := time.Now()
+	order := &models.Order{
+		OrderNo:                 "REVEAL-001",
+		UserID:                  1,
+		Status:                  constants.OrderStatusCompleted,
+		Currency:                "CNY",
+		OriginalAmount:          models.NewMoneyFromDecimal(decimal.NewFromInt(10)),
+		DiscountAmount:          models.NewMoneyFromDecimal(decimal.Zero),
+		PromotionDiscountAmount: models.NewMoneyFromDecimal(decimal.Zero),
+		TotalAmount:             models.NewMoneyFromDecimal(decimal.NewFromInt(10)),
+		WalletPaidAmount:        models.NewMoneyFromDecimal(decimal.Zero),
+		OnlinePaidAmount:        models.NewMoneyFromDecimal(decimal.NewFromInt(10)),
+		RefundedAmount:          models.NewMoneyFromDecimal(decimal.Zero),
+		CreatedAt:               now,
+		UpdatedAt:               now,
+	}
+	if err := db.Create(order).Error; err != nil {
+		t.Fatalf("create order failed: %v", err)
+	}
+	if err := db.Create(&models.OrderItem{
+		OrderID:         order.ID,
+		ProductID:       1,
+		TitleJSON:       models.JSON{"zh-CN": "兑换码"},
+		UnitPrice:       models.NewMoneyFromDecimal(decimal.NewFromInt(10)),
+		Quantity:        1,
+		TotalPrice:      models.NewMoneyFromDecimal(decimal.NewFromInt(10)),
+		FulfillmentType: constants.FulfillmentTypeAuto,
+		RevealRequired:  true,
+		RevealMaxViews:  2,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}).Error; err != nil {
+		t.Fatalf("create order item failed: %v", err)
+	}
+	if err := db.Create(&models.Fulfillment{
+		OrderID:       order.ID,
+		Type:          constants.FulfillmentTypeAuto,
+		Status:        constants.FulfillmentStatusDelivered,
+		Payload:       "CODE-AAAA-BBBB",
+		LogisticsJSON: models.JSON{"secret": "CODE-AAAA-BBBB"},
+		DeliveredAt:   &now,
+	}).Error; err != nil {
+		t.Fatalf("create fulfillment failed: %v", err)
+	}
+
+	orderRepo := repository.NewOrderRepository(db)
+	svc := NewSecretRevealService(orderRepo, repository.NewFulfillmentRepository(db))
+	load := func() *models.Order {
+		full, err := orderRepo.GetByID(order.ID)
+		if err != nil || full == nil || full.Fulfillment == nil {
+			t.Fatalf("reload order failed: %v", err)
+		}
+		return full
+	}
+
+	hidden := load()
+	svc.ApplyToOrder(hidden)
+	if !hidden.Fulfillment.Hidden || hidden.Fulfillment.Payload != "" || hidden.Fulfillment.LogisticsJSON != nil {
+		t.Fatalf("reveal-required payload should be hidden: %+v", hidden.Fulfillment)
+	}
+
+	for i := 1; i <= 2; i++ {
+		revealed, err := svc.Reveal(RevealInput{Order: load(), UserID: 1, ClientIP: "198.51.100.7", UserAgent: "Mozilla/5.0"})
+		if err != nil {
+			t.Fatalf("reveal #%d failed: %v", i, err)
+		}
+		if revealed.Fulfillment.Payload != "CODE-AAAA-BBBB" || revealed.Fulfillment.RevealCount != i || revealed.Fulfillment.RevealedAt == nil {
+			t.Fatalf("unexpected revealed fulfillment #%d: %+v", i, revealed.Fulfillment)
+		}
+	}
+	if _, err := svc.Reveal(RevealInput{Order: load(), UserID: 1}); !errors.Is(err, ErrSecretRevealLimitExceeded) {
+		t.Fatalf("third reveal should exceed limit, got %v", err)
+	}
+
+	evidence, err := svc.ListEvidence(load())
+	if err != nil {
+		t.Fatalf("list evidence failed: %v", err)
+	}
+	if len(evidence) != 2 || evidence[0].ViewNo != 2 || evidence[1].ClientIP != "198.51.100.7" || evidence[1].UserAgent != "Mozilla/5.0" {
+		t.Fatalf("unexpected reveal evidence: %+v", evidence)
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"github.com/dujiao-next/internal/envelope"
+	"github.com/dujiao-next/internal/logger"
+	"github.com/dujiao-next/internal/repository"
+)
+
+// SecretRotationService 卡密与交付内容密钥轮换服务
+// 读取时由模型钩子解密，回写时按当前密钥重新加密
+type SecretRotationService struct {
+	secretRepo      repository.CardSecretRepository
+	fulfillmentRepo repository.FulfillmentRepository
+	keyring         *envelope.Keyring
+}
+
+// NewSecretRotationService 创建密钥轮换服务
+func NewSecretRotationService(secretRepo repository.CardSecretRepository, fulfillmentRepo repository.FulfillmentRepository, keyring *envelope.Keyring) *SecretRotationService {
+	return &SecretRotationService{
+		secretRepo:      secretRepo,
+		fulfillmentRepo: fulfillmentRepo,
+		keyring:         keyring,
+	}
+}
+
+// Enabled 是否配置了当前加密密钥
+func (s *SecretRotationService) Enabled() bool {
+	return s != nil && s.keyring.Enabled()
+}
+
+// RotateBatch 重加密一批未使用当前密钥的数据，返回处理条数
+func (s *SecretRotationService) RotateBatch(limit int) (int, error) {
+	if !s.Enabled() || limit <= 0 {
+		return 0, nil
+	}
+	activeKeyID := s.keyring.ActiveKeyID()
+	rotated := 0
+
+	secrets, err := s.secretRepo.ListForKeyRotation(activeKeyID, limit)
+	if err != nil {
+		return rotated, err
+	}
+	for i := range secrets {
+		if err := s.secretRepo.RewriteSecret(&secrets[i]); err != nil {
+			return rotated, err
+		}
+		rotated++
+	}
+
+	remaining := limit - len(secrets)
+	if remaining <= 0 || s.fulfillmentRepo == nil {
+		return rotated, nil
+	}
+	fulfillments, err := s.fulfillmentRepo.ListForKeyRotation(activeKeyID, remaining)
+	if err != nil {
+		return rotated, err
+	}
+	for i := range fulfillments {
+		if err := s.fulfillmentRepo.RewritePayload(&fulfillments[i]); err != nil {
+			return rotated, err
+		}
+		rotated++
+	}
+
+	remaining -= len(fulfillments)
+	if remaining > 0 {
+		batches, err := s.fulfillmentRepo.ListBatchesForKeyRotation(activeKeyID, remaining)
+		if err != nil {
+			return rotated, err
+		}
+		for i := range batches {
+			if err := s.fulfillmentRepo.RewriteBatchPayload(&batches[i]); err != nil {
+				return rotated, err
+			}
+			rotated++
+		}
+	}
+	if rotated > 0 {
+		logger.Infow("secret_rotation_batch_done", "active_key_id", activeKeyID, "rotated", rotated)
+	}
+	return rotated, nil
+}
+
+// BackfillCardSecretHashes 按当前密钥重算一批卡密内容哈希（历史空哈希、旧版无密钥哈希、轮换前密钥的哈希），返回处理条数
+// 未启用加密时同样执行，为历史数据补齐 sha256 哈希
+func (s *SecretRotationService) BackfillCardSecretHashes(limit int) (int, error) {
+	if s == nil || limit <= 0 {
+		return 0, nil
+	}
+	activeKeyID := s.keyring.ActiveKeyID()
+	secrets, err := s.secretRepo.ListForHashBackfill(activeKeyID, limit)
+	if err != nil {
+		return 0, err
+	}
+	for i := range secrets {
+		secrets[i].RefreshSecretHash()
+		if err := s.secretRepo.RewriteSecretHash(&secrets[i]); err != nil {
+			return i, err
+		}
+	}
+	if len(secrets) > 0 {
+		logger.Infow("card_secret_hash_backfill_batch_done", "active_key_id", activeKeyID, "backfilled", len(secrets))
+	}
+	return len(secrets), nil
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/envelope"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/shopspring/decimal"
+)
+
+func newSecretRotationTestKeyring(t *testing.T, activeKeyID string) *envelope.Keyring {
+	t.Helper()
+	keyring, err := envelope.NewKeyring(activeKeyID, map[string][]byte{
+		"k1": bytes.Repeat([]byte{1}, 32),
+		"k2": bytes.Repeat([]byte{2}, 32),
+	})
+	if err != nil {
+		t.Fatalf("new keyring failed: %v", err)
+	}
+	return keyring
+}
+
+func TestCardSecretEncryptedAtRestAndRotated(t *testing.T) {
+	db := setupCardSecretServiceTestDB(t)
+	if err := db.AutoMigrate(&models.Fulfillment{}, &models.FulfillmentBatch{}); err != nil {
+		t.Fatalf("auto migrate failed: %v", err)
+	}
+	t.Cleanup(func() { models.SetFieldCipher(nil) })
+
+	product := &models.Product{
+		CategoryID:      1,
+		Slug:            "encrypted-card-product",
+		TitleJSON:       models.JSON{"zh-CN": "加密卡密商品"},
+		PriceAmount:     models.NewMoneyFromDecimal(decimal.NewFromInt(10)),
+		PurchaseType:    constants.ProductPurchaseMember,
+		FulfillmentType: constants.FulfillmentTypeAuto,
+		IsActive:        true,
+	}
+	if err := db.Create(product).Error; err != nil {
+		t.Fatalf("create product failed: %v", err)
+	}
+	sku := &models.ProductSKU{
+		ProductID:   product.ID,
+		SKUCode:     models.DefaultSKUCode,
+		PriceAmount: models.NewMoneyFromDecimal(decimal.NewFromInt(10)),
+		IsActive:    true,
+	}
+	if err := db.Create(sku).Error; err != nil {
+		t.Fatalf("create sku failed: %v", err)
+	}
+
+	// 历史明文数据
+	legacy := &models.Fulfillment{OrderID: 99, Type: constants.FulfillmentTypeAuto, Status: "delivered", Payload: "LEGACY-CODE"}
+	if err := db.Create(legacy).Error; err != nil {
+		t.Fatalf("create legacy fulfillment failed: %v", err)
+	}
+
+	models.SetFieldCipher(newSecretRotationTestKeyring(t, "k1"))
+	secretRepo := repository.NewCardSecretRepository(db)
+	svc := NewCardSecretService(
+		secretRepo,
+		repository.NewCardSecretBatchRepository(db),
+		repository.NewProductRepository(db),
+		repository.NewProductSKURepository(db),
+	)
+	if _, _, err := svc.CreateCardSecretBatch(CreateCardSecretBatchInput{
+		ProductID: product.ID,
+		Secrets:   []string{"CODE-AAAA-0001", "CODE-AAAA-0002"},
+	}); err != nil {
+		t.Fatalf("create card secret batch failed: %v", err)
+	}
+
+	var raw []struct {
+		Secret      string
+		SecretKeyID string
+	}
+	if err := db.Table("card_secrets").Select("secret, secret_key_id").Scan(&raw).Error; err != nil {
+		t.Fatalf("scan raw secrets failed: %v", err)
+	}
+	for _, row := range raw {
+		if row.SecretKeyID != "k1" || strings.Contains(row.Secret, "CODE-AAAA") {
+			t.Fatalf("expected encrypted secret with k1, got %+v", row)
+		}
+	}
+
+	items, _, err := svc.ListCardSecrets(ListCardSecretInput{ProductID: product.ID, Reveal: true})
+	if err != nil {
+		t.Fatalf("list card secrets failed: %v", err)
+	}
+	if len(items) != 2 || items[0].Secret != "CODE-AAAA-0001" {
+		t.Fatalf("expected decrypted secrets, got %+v", items)
+	}
+	masked, _, err := svc.ListCardSecrets(ListCardSecretInput{ProductID: product.ID})
+	if err != nil {
+		t.Fatalf("list masked card secrets failed: %v", err)
+	}
+	if masked[0].Secret != "CO**********01" {
+		t.Fatalf("expected masked secret, got %s", masked[0].Secret)
+	}
+
+	keyring := newSecretRotationTestKeyring(t, "k2")
+	models.SetFieldCipher(keyring)
+	rotation := NewSecretRotationService(secretRepo, repository.NewFulfillmentRepository(db), keyring)
+	rotated, err := rotation.RotateBatch(10)
+	if err != nil {
+		t.Fatalf("rotate batch failed: %v", err)
+	}
+	if rotated != 3 {
+		t.Fatalf("expected 3 rows rotated, got %d", rotated)
+	}
+	if again, _ := rotation.RotateBatch(10); again != 0 {
+		t.Fatalf("expected nothing left to rotate, got %d", again)
+	}
+
+	var pending int64
+	db.Table("card_secrets").Where("secret_key_id <> ?", "k2").Count(&pending)
+	if pending != 0 {
+		t.Fatalf("expected all card secrets on k2, got %d pending", pending)
+	}
+	exported, _, err := svc.ExportCardSecrets([]uint{1, 2}, constants.ExportFormatTXT, true)
+	if err != nil {
+		t.Fatalf("export card secrets failed: %v", err)
+	}
+	if string(exported) != "CODE-AAAA-0001\nCODE-AAAA-0002" {
+		t.Fatalf("unexpected export content: %q", string(exported))
+	}
+	fulfillment, err := repository.NewFulfillmentRepository(db).GetByOrderID(99)
+	if err != nil || fulfillment == nil {
+		t.Fatalf("get fulfillment failed: %v", err)
+	}
+	if fulfillment.Payload != "LEGACY-CODE" || fulfillment.PayloadKeyID != "k2" {
+		t.Fatalf("expected legacy payload encrypted with k2, got %+v", fulfillment)
+	}
+}
+
+func TestCardSecretHashBackfill(t *testing.T) {
+	db := setupCardSecretServiceTestDB(t)
+	t.Cleanup(func() { models.SetFieldCipher(nil) })
+	models.SetFieldCipher(nil)
+
+	secretRepo := repository.NewCardSecretRepository(db)
+	// 历史数据：哈希字段上线前写入，secret_hash 为空
+	if err := db.Exec("INSERT INTO card_secrets (product_id, sku_id, secret, secret_key_id, secret_hash, status, created_at, updated_at) VALUES (1, 1, 'LEGACY-0001', '', '', ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)", models.CardSecretStatusAvailable).Error; err != nil {
+		t.Fatalf("insert legacy card secret failed: %v", err)
+	}
+
+	// 未启用加密时同样回填 sha256 哈希
+	disabled := NewSecretRotationService(secretRepo, nil, nil)
+	backfilled, err := disabled.BackfillCardSecretHashes(10)
+	if err != nil || backfilled != 1 {
+		t.Fatalf("expected 1 row backfilled without encryption, got %d err=%v", backfilled, err)
+	}
+	sum := sha256.Sum256([]byte("LEGACY-0001"))
+	legacy, err := secretRepo.GetByProductAndHash(1, hex.EncodeToString(sum[:]))
+	if err != nil || legacy == nil || legacy.HashKeyID != "" {
+		t.Fatalf("expected legacy row findable by sha256 hash, got %+v err=%v", legacy, err)
+	}
+	if again, _ := disabled.BackfillCardSecretHashes(10); again != 0 {
+		t.Fatalf("expected nothing left to backfill, got %d", again)
+	}
+
+	// 启用加密后哈希改为密钥派生的 HMAC，历史哈希需按当前密钥重算
+	keyring := newSecretRotationTestKeyring(t, "k1")
+	models.SetFieldCipher(keyring)
+	keyedHash := models.CardSecretContentHash("LEGACY-0001", nil)
+	if keyedHash == hex.EncodeToString(sum[:]) {
+		t.Fatalf("expected keyed hash to differ from plain sha256")
+	}
+	if found, _ := secretRepo.GetByProductAndHash(1, keyedHash); found != nil {
+		t.Fatalf("expected stale hash to miss before backfill")
+	}
+	rotation := NewSecretRotationService(secretRepo, nil, keyring)
+	if backfilled, err := rotation.BackfillCardSecretHashes(10); err != nil || backfilled != 1 {
+		t.Fatalf("expected 1 row rehashed with k1, got %d err=%v", backfilled, err)
+	}
+	found, err := secretRepo.GetByProductAndHash(1, keyedHash)
+	if err != nil || found == nil || found.HashKeyID != "k1" || found.Secret != "LEGACY-0001" {
+		t.Fatalf("expected legacy row findable by keyed hash, got %+v err=%v", found, err)
+	}
+	var raw struct {
+		Secret      string
+		SecretKeyID string
+	}
+	if err := db.Table("card_secrets").Select("secret, secret_key_id").Where("id = ?", found.ID).Scan(&raw).Error; err != nil {
+		t.Fatalf("scan raw secret failed: %v", err)
+	}
+	if raw.Secret != "LEGACY-0001" || raw.SecretKeyID != "" {
+		t.Fatalf("expected backfill to leave ciphertext untouched, got %+v", raw)
+	}
+}
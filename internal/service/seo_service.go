@@ -0,0 +1,450 @@
+package service
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dujiao-next/internal/config"
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+)
+
+const (
+	sitemapDefaultMaxURLs = 5000
+	sitemapHardMaxURLs    = 50000 // 协议规定单个 sitemap 最多 50000 条
+	rssDefaultLimit       = 50
+	rssMaxLimit           = 200
+)
+
+// sitemap 拆分后的分段名称，对应 /sitemaps/{section}-{page}.xml
+const (
+	sitemapSectionPages    = "pages"
+	sitemapSectionProducts = "products"
+	sitemapSectionPosts    = "posts"
+)
+
+// SEOService 站点地图与 RSS 服务
+type SEOService struct {
+	productRepo    repository.ProductRepository
+	postRepo       repository.PostRepository
+	settingService *SettingService
+	cfg            config.SEOConfig
+}
+
+// NewSEOService 创建 SEO 服务
+func NewSEOService(productRepo repository.ProductRepository, postRepo repository.PostRepository, settingService *SettingService, cfg config.SEOConfig) *SEOService {
+	return &SEOService{
+		productRepo:    productRepo,
+		postRepo:       postRepo,
+		settingService: settingService,
+		cfg:            cfg,
+	}
+}
+
+type sitemapURLSet struct {
+	XMLName    xml.Name     `xml:"urlset"`
+	Xmlns      string       `xml:"xmlns,attr"`
+	XmlnsXhtml string       `xml:"xmlns:xhtml,attr"`
+	URLs       []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string             `xml:"loc"`
+	LastMod    string             `xml:"lastmod,omitempty"`
+	Alternates []sitemapAlternate `xml:"xhtml:link"`
+}
+
+type sitemapAlternate struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name         `xml:"sitemapindex"`
+	Xmlns    string           `xml:"xmlns,attr"`
+	Sitemaps []sitemapPointer `xml:"sitemap"`
+}
+
+type sitemapPointer struct {
+	Loc string `xml:"loc"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description,omitempty"`
+	Category    string  `xml:"category,omitempty"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// ResolveSiteURL 返回前台站点地址，未配置 seo.site_url 时使用请求推断的地址
+func (s *SEOService) ResolveSiteURL(fallback string) string {
+	siteURL := strings.TrimSpace(s.cfg.SiteURL)
+	if siteURL == "" {
+		siteURL = strings.TrimSpace(fallback)
+	}
+	return strings.TrimRight(siteURL, "/")
+}
+
+// BuildSitemap 生成 /sitemap.xml，URL 总数超出上限时输出指向分段 sitemap 的索引
+func (s *SEOService) BuildSitemap(siteURL string) ([]byte, error) {
+	maxURLs := s.sitemapMaxURLs()
+	productTotal, err := s.countProducts()
+	if err != nil {
+		return nil, err
+	}
+	postTotal, err := s.countPosts()
+	if err != nil {
+		return nil, err
+	}
+
+	if 1+productTotal+postTotal <= int64(maxURLs) {
+		urls := buildPageSitemapURLs(siteURL)
+		productURLs, err := s.productSitemapURLs(siteURL, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		postURLs, err := s.postSitemapURLs(siteURL, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, productURLs...)
+		urls = append(urls, postURLs...)
+		return marshalSitemapURLSet(urls)
+	}
+
+	index := sitemapIndex{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	index.Sitemaps = append(index.Sitemaps, sitemapPointer{Loc: buildSitemapFileURL(siteURL, sitemapSectionPages, 1)})
+	for page := 1; page <= sitemapPageCount(productTotal, maxURLs); page++ {
+		index.Sitemaps = append(index.Sitemaps, sitemapPointer{Loc: buildSitemapFileURL(siteURL, sitemapSectionProducts, page)})
+	}
+	for page := 1; page <= sitemapPageCount(postTotal, maxURLs); page++ {
+		index.Sitemaps = append(index.Sitemaps, sitemapPointer{Loc: buildSitemapFileURL(siteURL, sitemapSectionPosts, page)})
+	}
+	return marshalXML(index)
+}
+
+// BuildSitemapFile 生成分段 sitemap，文件名形如 products-2.xml
+func (s *SEOService) BuildSitemapFile(siteURL, name string) ([]byte, error) {
+	section, page, ok := parseSitemapFileName(name)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	maxURLs := s.sitemapMaxURLs()
+
+	var urls []sitemapURL
+	var err error
+	switch section {
+	case sitemapSectionPages:
+		if page != 1 {
+			return nil, ErrNotFound
+		}
+		urls = buildPageSitemapURLs(siteURL)
+	case sitemapSectionProducts:
+		urls, err = s.productSitemapURLs(siteURL, page, maxURLs)
+	case sitemapSectionPosts:
+		urls, err = s.postSitemapURLs(siteURL, page, maxURLs)
+	default:
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(urls) == 0 && page > 1 {
+		return nil, ErrNotFound
+	}
+	return marshalSitemapURLSet(urls)
+}
+
+// BuildRSS 生成 /rss.xml，postType 为空时输出 blog 与 notice 全部文章
+func (s *SEOService) BuildRSS(siteURL, locale, postType string) ([]byte, error) {
+	postType = strings.TrimSpace(postType)
+	if postType != "" && !isAllowedPostType(postType) {
+		return nil, ErrInvalidPostType
+	}
+	posts, _, err := s.postRepo.List(repository.PostListFilter{
+		Page:          1,
+		PageSize:      s.rssLimit(),
+		Type:          postType,
+		OnlyPublished: true,
+		OrderBy:       "published_at DESC, created_at DESC",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	title, description := s.resolveSiteTitle(locale)
+	if title == "" {
+		title = siteURL
+	}
+	channel := rssChannel{
+		Title:       title,
+		Link:        siteURL + "/",
+		Description: description,
+		Language:    strings.ToLower(strings.TrimSpace(locale)),
+		Items:       make([]rssItem, 0, len(posts)),
+	}
+	for i := range posts {
+		post := &posts[i]
+		link := buildPostURL(siteURL, post.Slug)
+		item := rssItem{
+			Title:       ResolveLocalizedText(post.TitleJSON, locale),
+			Link:        link,
+			Description: ResolveLocalizedText(post.SummaryJSON, locale),
+			Category:    post.Type,
+			GUID:        rssGUID{IsPermaLink: true, Value: link},
+		}
+		if item.Title == "" {
+			item.Title = post.Slug
+		}
+		publishedAt := resolvePostPublishedAt(post)
+		if !publishedAt.IsZero() {
+			item.PubDate = publishedAt.UTC().Format(time.RFC1123Z)
+			if channel.LastBuildDate == "" {
+				channel.LastBuildDate = item.PubDate
+			}
+		}
+		channel.Items = append(channel.Items, item)
+	}
+	return marshalXML(rssFeed{Version: "2.0", Channel: channel})
+}
+
+// ResolveLocalizedText 按语言读取多语言 JSON 文本，缺失时依次回退到 zh-CN、en-US、zh-TW
+func ResolveLocalizedText(values map[string]interface{}, locale string) string {
+	for _, key := range []string{locale, constants.LocaleZhCN, constants.LocaleEnUS, constants.LocaleZhTW} {
+		if text := localizedTextValue(values, key); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+func localizedTextValue(values map[string]interface{}, locale string) string {
+	if values == nil || locale == "" {
+		return ""
+	}
+	value, ok := values[locale]
+	if !ok || value == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprintf("%v", value))
+}
+
+func (s *SEOService) productSitemapURLs(siteURL string, page, pageSize int) ([]sitemapURL, error) {
+	products, _, err := s.productRepo.List(repository.ProductListFilter{
+		Page:       page,
+		PageSize:   pageSize,
+		OnlyActive: true,
+		OnlyListed: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	urls := make([]sitemapURL, 0, len(products))
+	for i := range products {
+		loc := buildProductURL(siteURL, products[i].Slug)
+		urls = append(urls, sitemapURL{
+			Loc:        loc,
+			LastMod:    formatSitemapTime(products[i].UpdatedAt),
+			Alternates: buildSitemapAlternates(loc, products[i].TitleJSON),
+		})
+	}
+	return urls, nil
+}
+
+func (s *SEOService) postSitemapURLs(siteURL string, page, pageSize int) ([]sitemapURL, error) {
+	posts, _, err := s.postRepo.List(repository.PostListFilter{
+		Page:          page,
+		PageSize:      pageSize,
+		OnlyPublished: true,
+		OrderBy:       "published_at DESC, created_at DESC",
+	})
+	if err != nil {
+		return nil, err
+	}
+	urls := make([]sitemapURL, 0, len(posts))
+	for i := range posts {
+		loc := buildPostURL(siteURL, posts[i].Slug)
+		urls = append(urls, sitemapURL{
+			Loc:        loc,
+			LastMod:    formatSitemapTime(resolvePostPublishedAt(&posts[i])),
+			Alternates: buildSitemapAlternates(loc, posts[i].TitleJSON),
+		})
+	}
+	return urls, nil
+}
+
+func (s *SEOService) countProducts() (int64, error) {
+	_, total, err := s.productRepo.List(repository.ProductListFilter{Page: 1, PageSize: 1, OnlyActive: true, OnlyListed: true})
+	return total, err
+}
+
+func (s *SEOService) countPosts() (int64, error) {
+	_, total, err := s.postRepo.List(repository.PostListFilter{Page: 1, PageSize: 1, OnlyPublished: true})
+	return total, err
+}
+
+// resolveSiteTitle 读取站点配置中的站点名称与 SEO 描述
+func (s *SEOService) resolveSiteTitle(locale string) (string, string) {
+	if s.settingService == nil {
+		return "", ""
+	}
+	site, err := s.settingService.GetByKey(constants.SettingKeySiteConfig)
+	if err != nil || site == nil {
+		return "", ""
+	}
+	title := ""
+	if brand, ok := site["brand"].(map[string]interface{}); ok {
+		title = strings.TrimSpace(fmt.Sprintf("%v", brand["site_name"]))
+	}
+	description := ""
+	if seo, ok := site["seo"].(map[string]interface{}); ok {
+		if title == "" {
+			titles, _ := seo["title"].(map[string]interface{})
+			title = ResolveLocalizedText(titles, locale)
+		}
+		descriptions, _ := seo["description"].(map[string]interface{})
+		description = ResolveLocalizedText(descriptions, locale)
+	}
+	return title, description
+}
+
+func (s *SEOService) sitemapMaxURLs() int {
+	maxURLs := s.cfg.SitemapMaxURLs
+	if maxURLs <= 0 {
+		return sitemapDefaultMaxURLs
+	}
+	if maxURLs > sitemapHardMaxURLs {
+		return sitemapHardMaxURLs
+	}
+	return maxURLs
+}
+
+func (s *SEOService) rssLimit() int {
+	limit := s.cfg.RSSLimit
+	if limit <= 0 {
+		return rssDefaultLimit
+	}
+	if limit > rssMaxLimit {
+		return rssMaxLimit
+	}
+	return limit
+}
+
+// buildSitemapAlternates 多语言标题中存在两种及以上语言时输出 hreflang 备用链接
+func buildSitemapAlternates(loc string, titles models.JSON) []sitemapAlternate {
+	locales := make([]string, 0, len(constants.SupportedLocales))
+	for _, locale := range constants.SupportedLocales {
+		if localizedTextValue(titles, locale) != "" {
+			locales = append(locales, locale)
+		}
+	}
+	if len(locales) < 2 {
+		return nil
+	}
+	alternates := make([]sitemapAlternate, 0, len(locales)+1)
+	for _, locale := range locales {
+		alternates = append(alternates, sitemapAlternate{
+			Rel:      "alternate",
+			Hreflang: locale,
+			Href:     loc + "?lang=" + url.QueryEscape(locale),
+		})
+	}
+	return append(alternates, sitemapAlternate{Rel: "alternate", Hreflang: "x-default", Href: loc})
+}
+
+func buildPageSitemapURLs(siteURL string) []sitemapURL {
+	return []sitemapURL{{Loc: siteURL + "/"}}
+}
+
+func buildProductURL(siteURL, slug string) string {
+	return siteURL + "/products/" + url.PathEscape(slug)
+}
+
+func buildPostURL(siteURL, slug string) string {
+	return siteURL + "/posts/" + url.PathEscape(slug)
+}
+
+func buildSitemapFileURL(siteURL, section string, page int) string {
+	return fmt.Sprintf("%s/sitemaps/%s-%d.xml", siteURL, section, page)
+}
+
+func parseSitemapFileName(name string) (string, int, bool) {
+	base, ok := strings.CutSuffix(strings.TrimSpace(name), ".xml")
+	if !ok {
+		return "", 0, false
+	}
+	idx := strings.LastIndex(base, "-")
+	if idx <= 0 {
+		return "", 0, false
+	}
+	page, err := strconv.Atoi(base[idx+1:])
+	if err != nil || page < 1 {
+		return "", 0, false
+	}
+	return base[:idx], page, true
+}
+
+func sitemapPageCount(total int64, pageSize int) int {
+	if total <= 0 || pageSize <= 0 {
+		return 0
+	}
+	return int((total + int64(pageSize) - 1) / int64(pageSize))
+}
+
+func resolvePostPublishedAt(post *models.Post) time.Time {
+	if post.PublishedAt != nil {
+		return *post.PublishedAt
+	}
+	return post.CreatedAt
+}
+
+func formatSitemapTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func marshalSitemapURLSet(urls []sitemapURL) ([]byte, error) {
+	return marshalXML(sitemapURLSet{
+		Xmlns:      "http://www.sitemaps.org/schemas/sitemap/0.9",
+		XmlnsXhtml: "http://www.w3.org/1999/xhtml",
+		URLs:       urls,
+	})
+}
+
+func marshalXML(value interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dujiao-next/internal/config"
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestSEOSitemapSplitAndRSS(t *testing.T) {
+	db, productSvc := setupProductTransferTest(t)
+	if err := db.AutoMigrate(&models.Post{}); err != nil {
+		t.Fatalf("auto migrate post failed: %v", err)
+	}
+	for _, slug := range []string{"alpha", "beta"} {
+		if _, err := productSvc.Create(CreateProductInput{
+			CategoryID:      1,
+			Slug:            slug,
+			TitleJSON:       map[string]interface{}{"zh-CN": "商品 " + slug, "en-US": "Product " + slug},
+			PriceAmount:     decimal.NewFromInt(10),
+			FulfillmentType: constants.FulfillmentTypeManual,
+		}); err != nil {
+			t.Fatalf("create product %s failed: %v", slug, err)
+		}
+	}
+	publishedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	posts := []models.Post{
+		{Slug: "hello", Type: constants.PostTypeBlog, TitleJSON: models.JSON{"zh-CN": "你好", "en-US": "Hello"}, SummaryJSON: models.JSON{"en-US": "First post"}, IsPublished: true, PublishedAt: &publishedAt},
+		{Slug: "maintenance", Type: constants.PostTypeNotice, TitleJSON: models.JSON{"zh-CN": "维护公告"}, IsPublished: true, PublishedAt: &publishedAt},
+		{Slug: "draft", Type: constants.PostTypeBlog, TitleJSON: models.JSON{"zh-CN": "草稿"}},
+	}
+	for i := range posts {
+		if err := db.Create(&posts[i]).Error; err != nil {
+			t.Fatalf("create post failed: %v", err)
+		}
+	}
+
+	single := NewSEOService(repository.NewProductRepository(db), repository.NewPostRepository(db), nil, config.SEOConfig{})
+	body, err := single.BuildSitemap("https://shop.example.com")
+	if err != nil {
+		t.Fatalf("build sitemap failed: %v", err)
+	}
+	sitemap := string(body)
+	for _, expected := range []string{
+		"<urlset",
+		"<loc>https://shop.example.com/products/alpha</loc>",
+		`<xhtml:link rel="alternate" hreflang="en-US" href="https://shop.example.com/products/alpha?lang=en-US"></xhtml:link>`,
+		`hreflang="x-default" href="https://shop.example.com/posts/hello"`,
+		"<loc>https://shop.example.com/posts/maintenance</loc>",
+	} {
+		if !strings.Contains(sitemap, expected) {
+			t.Fatalf("sitemap missing %q:\n%s", expected, sitemap)
+		}
+	}
+	if strings.Contains(sitemap, "/posts/draft") {
+		t.Fatalf("unpublished post should not be listed")
+	}
+	if strings.Contains(sitemap, "/posts/maintenance?lang=") {
+		t.Fatalf("single-locale post should not have alternates")
+	}
+
+	split := NewSEOService(repository.NewProductRepository(db), repository.NewPostRepository(db), nil, config.SEOConfig{SitemapMaxURLs: 1})
+	body, err = split.BuildSitemap("https://shop.example.com")
+	if err != nil {
+		t.Fatalf("build sitemap index failed: %v", err)
+	}
+	index := string(body)
+	for _, expected := range []string{
+		"<sitemapindex",
+		"<loc>https://shop.example.com/sitemaps/pages-1.xml</loc>",
+		"<loc>https://shop.example.com/sitemaps/products-2.xml</loc>",
+		"<loc>https://shop.example.com/sitemaps/posts-2.xml</loc>",
+	} {
+		if !strings.Contains(index, expected) {
+			t.Fatalf("sitemap index missing %q:\n%s", expected, index)
+		}
+	}
+	body, err = split.BuildSitemapFile("https://shop.example.com", "products-2.xml")
+	if err != nil {
+		t.Fatalf("build sitemap file failed: %v", err)
+	}
+	if strings.Count(string(body), "<url>") != 1 {
+		t.Fatalf("expected one url per split sitemap:\n%s", body)
+	}
+	for _, name := range []string{"products-3.xml", "pages-2.xml", "unknown-1.xml", "products.xml"} {
+		if _, err := split.BuildSitemapFile("https://shop.example.com", name); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected %s to be not found, got %v", name, err)
+		}
+	}
+
+	body, err = single.BuildRSS("https://shop.example.com", constants.LocaleEnUS, "")
+	if err != nil {
+		t.Fatalf("build rss failed: %v", err)
+	}
+	rss := string(body)
+	for _, expected := range []string{
+		`<rss version="2.0">`,
+		"<title>Hello</title>",
+		"<description>First post</description>",
+		"<title>维护公告</title>",
+		"<pubDate>Fri, 02 Jan 2026 03:04:05 +0000</pubDate>",
+	} {
+		if !strings.Contains(rss, expected) {
+			t.Fatalf("rss missing %q:\n%s", expected, rss)
+		}
+	}
+	body, err = single.BuildRSS("https://shop.example.com", constants.LocaleZhCN, constants.PostTypeNotice)
+	if err != nil {
+		t.Fatalf("build notice rss failed: %v", err)
+	}
+	if strings.Contains(string(body), "/posts/hello") {
+		t.Fatalf("notice feed should not include blog posts")
+	}
+	if _, err := single.BuildRSS("https://shop.example.com", constants.LocaleZhCN, "page"); !errors.Is(err, ErrInvalidPostType) {
+		t.Fatalf("expected invalid post type, got %v", err)
+	}
+}
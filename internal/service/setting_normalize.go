@@ -36,6 +36,8 @@ func normalizeSettingValueByKey(key string, value map[string]interface{}) models
 		return NotificationCenterSettingToMap(setting)
 	case constants.SettingKeyAffiliateConfig:
 		return normalizeAffiliateSettingMap(value)
+	case constants.SettingKeyReceiptConfig:
+		return normalizeReceiptSettingMap(value)
 	default:
 		return models.JSON(value)
 	}
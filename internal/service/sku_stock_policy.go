@@ -52,3 +52,23 @@ func shouldEnforceManualSKUStock(product *models.Product, sku *models.ProductSKU
 	}
 	return hasMultipleActiveSKUs(product)
 }
+
+// skuSellableStock SKU 当前可售数量，-1 表示无限库存；自动发货商品需先调用 ApplyAutoStockCounts
+func skuSellableStock(product *models.Product, sku *models.ProductSKU) int64 {
+	if product == nil || sku == nil {
+		return 0
+	}
+	fulfillmentType := strings.TrimSpace(product.FulfillmentType)
+	switch {
+	case fulfillmentType == constants.FulfillmentTypeAPI || fulfillmentType == constants.FulfillmentTypeFile || HasKeyGenerator(product):
+		return constants.ManualStockUnlimited
+	case fulfillmentType == constants.FulfillmentTypeAuto:
+		return sku.AutoStockAvailable
+	case sku.ManualStockTotal == constants.ManualStockUnlimited:
+		return constants.ManualStockUnlimited
+	case sku.ManualStockTotal < 0:
+		return 0
+	default:
+		return int64(sku.ManualStockTotal)
+	}
+}
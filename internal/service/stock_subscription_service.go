@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dujiao-next/internal/config"
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/i18n"
+	"github.com/dujiao-next/internal/logger"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/queue"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/hibiken/asynq"
+)
+
+const (
+	stockNotifyDefaultBatchSize     = 50
+	stockNotifyDefaultBatchInterval = 60 * time.Second
+)
+
+var stockSubscriptionTelegramChatPattern = regexp.MustCompile(`^-?[0-9]{1,20}$`)
+
+// StockSubscribeInput 到货通知订阅参数
+type StockSubscribeInput struct {
+	ProductID  uint
+	SKUID      uint
+	UserID     uint
+	Channel    string
+	Target     string
+	Locale     string
+	AccessCode string // 访问码保护商品订阅时需提供访问码
+}
+
+// StockSubscriptionService 到货通知服务
+type StockSubscriptionService struct {
+	repo           repository.StockSubscriptionRepository
+	productRepo    repository.ProductRepository
+	productService *ProductService
+	userRepo       repository.UserRepository
+	identityRepo   repository.UserOAuthIdentityRepository
+	emailService   *EmailService
+	telegramSender *TelegramNotifyService
+	queueClient    *queue.Client
+	cfg            config.StockNotifyConfig
+	siteURL        string
+}
+
+// NewStockSubscriptionService 创建到货通知服务
+func NewStockSubscriptionService(
+	repo repository.StockSubscriptionRepository,
+	productRepo repository.ProductRepository,
+	productService *ProductService,
+	userRepo repository.UserRepository,
+	identityRepo repository.UserOAuthIdentityRepository,
+	emailService *EmailService,
+	settingService *SettingService,
+	defaultTelegramCfg config.TelegramAuthConfig,
+	queueClient *queue.Client,
+	cfg config.StockNotifyConfig,
+	siteURL string,
+) *StockSubscriptionService {
+	return &StockSubscriptionService{
+		repo:           repo,
+		productRepo:    productRepo,
+		productService: productService,
+		userRepo:       userRepo,
+		identityRepo:   identityRepo,
+		emailService:   emailService,
+		telegramSender: NewTelegramNotifyService(settingService, defaultTelegramCfg),
+		queueClient:    queueClient,
+		cfg:            cfg,
+		siteURL:        strings.TrimRight(strings.TrimSpace(siteURL), "/"),
+	}
+}
+
+// Subscribe 订阅售罄 SKU 的到货通知，同一 SKU 同一目标重复订阅时返回已有订阅
+func (s *StockSubscriptionService) Subscribe(input StockSubscribeInput) (*models.StockSubscription, error) {
+	if input.ProductID == 0 || input.SKUID == 0 {
+		return nil, ErrStockSubscriptionInvalid
+	}
+	channel := strings.ToLower(strings.TrimSpace(input.Channel))
+	target, err := s.resolveSubscribeTarget(channel, strings.TrimSpace(input.Target), input.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	product, sku, err := s.loadProductSKU(input.ProductID, input.SKUID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if product == nil || !product.IsPublishedAt(now) {
+		return nil, ErrProductNotAvailable
+	}
+	if err := CheckProductAccess(product, input.AccessCode); err != nil {
+		return nil, err
+	}
+	if sku == nil || !sku.IsPublishedAt(now) {
+		return nil, ErrProductSKUInvalid
+	}
+	if skuSellableStock(product, sku) != 0 {
+		return nil, ErrStockSubscriptionNotSoldOut
+	}
+
+	existing, err := s.repo.GetPending(sku.ID, channel, target)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+	subscription := &models.StockSubscription{
+		ProductID: product.ID,
+		SKUID:     sku.ID,
+		UserID:    input.UserID,
+		Channel:   channel,
+		Target:    target,
+		Locale:    normalizeLocale(input.Locale),
+		Token:     randomHex(16),
+		Status:    constants.StockSubscriptionStatusPending,
+	}
+	if err := s.repo.Create(subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// GetByToken 按退订令牌查询订阅，用于退订确认页
+func (s *StockSubscriptionService) GetByToken(token string) (*models.StockSubscription, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, ErrStockSubscriptionNotFound
+	}
+	subscription, err := s.repo.GetByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil {
+		return nil, ErrStockSubscriptionNotFound
+	}
+	return subscription, nil
+}
+
+// Unsubscribe 通过退订令牌取消订阅
+func (s *StockSubscriptionService) Unsubscribe(token string) error {
+	subscription, err := s.GetByToken(token)
+	if err != nil {
+		return err
+	}
+	if subscription.Status == constants.StockSubscriptionStatusUnsubscribed {
+		return nil
+	}
+	subscription.Status = constants.StockSubscriptionStatusUnsubscribed
+	subscription.UpdatedAt = time.Now()
+	return s.repo.Update(subscription)
+}
+
+// TriggerRestock 商品库存变化后检查有待通知订阅的 SKU，已恢复库存的推送到货通知任务
+func (s *StockSubscriptionService) TriggerRestock(productID uint) {
+	if s == nil || s.repo == nil || productID == 0 || !s.queueClient.Enabled() {
+		return
+	}
+	skuIDs, err := s.repo.ListPendingSKUIDs(productID)
+	if err != nil {
+		logger.Warnw("stock_subscription_list_pending_failed", "product_id", productID, "error", err)
+		return
+	}
+	if len(skuIDs) == 0 {
+		return
+	}
+	product, err := s.loadProduct(productID)
+	if err != nil || product == nil {
+		if err != nil {
+			logger.Warnw("stock_subscription_fetch_product_failed", "product_id", productID, "error", err)
+		}
+		return
+	}
+	pending := make(map[uint]struct{}, len(skuIDs))
+	for _, id := range skuIDs {
+		pending[id] = struct{}{}
+	}
+	for i := range product.SKUs {
+		sku := &product.SKUs[i]
+		if _, ok := pending[sku.ID]; !ok || !sku.IsActive || skuSellableStock(product, sku) == 0 {
+			continue
+		}
+		// 同一 SKU 在一个批次间隔内只入队一次，避免连续补货触发重复任务
+		err := s.queueClient.EnqueueStockArrivalNotify(queue.StockArrivalNotifyPayload{SKUID: sku.ID},
+			asynq.MaxRetry(3),
+			asynq.Unique(s.batchInterval()),
+		)
+		if err != nil && !errors.Is(err, asynq.ErrDuplicateTask) {
+			logger.Warnw("stock_subscription_enqueue_failed", "product_id", productID, "sku_id", sku.ID, "error", err)
+		}
+	}
+}
+
+// NotifyRestock 向 SKU 的待通知订阅发送一批到货通知，剩余订阅延迟到下一批，由异步任务调用。
+// 未配置 seo.site_url 时通知中的链接无法访问，拒绝发送并保留订阅
+func (s *StockSubscriptionService) NotifyRestock(ctx context.Context, skuID uint) error {
+	if s.siteURL == "" {
+		logger.Errorw("stock_subscription_site_url_missing", "sku_id", skuID)
+		return nil
+	}
+	subscriptions, err := s.repo.ListPendingBySKU(skuID, s.batchSize())
+	if err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+	product, sku, err := s.loadProductSKU(subscriptions[0].ProductID, skuID)
+	if err != nil {
+		return err
+	}
+	// 补货后又被买空时保留订阅，等待下次补货
+	now := time.Now()
+	if product == nil || !product.IsPublishedAt(now) || sku == nil || !sku.IsPublishedAt(now) || skuSellableStock(product, sku) == 0 {
+		return nil
+	}
+
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		claimed, err := s.repo.MarkNotified(subscription.ID, now)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		if err := s.send(ctx, product, sku, subscription); err != nil {
+			logger.Warnw("stock_subscription_notify_failed",
+				"subscription_id", subscription.ID,
+				"sku_id", skuID,
+				"channel", subscription.Channel,
+				"error", err,
+			)
+		}
+	}
+
+	if len(subscriptions) >= s.batchSize() {
+		if err := s.queueClient.EnqueueStockArrivalNotify(queue.StockArrivalNotifyPayload{SKUID: skuID},
+			asynq.MaxRetry(3),
+			asynq.ProcessIn(s.batchInterval()),
+		); err != nil {
+			logger.Warnw("stock_subscription_enqueue_next_batch_failed", "sku_id", skuID, "error", err)
+		}
+	}
+	return nil
+}
+
+func (s *StockSubscriptionService) send(ctx context.Context, product *models.Product, sku *models.ProductSKU, subscription *models.StockSubscription) error {
+	locale := normalizeLocale(subscription.Locale)
+	title := resolveStockSubscriptionTitle(product, sku, locale)
+	subject := i18n.Sprintf(locale, "email.stock_arrival.subject", title)
+	body := i18n.Sprintf(locale, "email.stock_arrival.body", title, s.buildProductURL(product), s.buildUnsubscribeURL(subscription.Token, locale))
+
+	switch subscription.Channel {
+	case constants.StockSubscriptionChannelEmail:
+		if s.emailService == nil {
+			return ErrEmailServiceNotConfigured
+		}
+		return s.emailService.SendCustomEmail(subscription.Target, subject, body)
+	case constants.StockSubscriptionChannelTelegram:
+		if s.telegramSender == nil {
+			return ErrNotificationSendFailed
+		}
+		return s.telegramSender.SendMessage(ctx, subscription.Target, composeTelegramMessage(subject, body))
+	default:
+		return ErrStockSubscriptionInvalid
+	}
+}
+
+func (s *StockSubscriptionService) resolveSubscribeTarget(channel, target string, userID uint) (string, error) {
+	switch channel {
+	case constants.StockSubscriptionChannelEmail:
+		if target == "" && userID != 0 {
+			user, err := s.userRepo.GetByID(userID)
+			if err != nil {
+				return "", err
+			}
+			if user != nil && !isTelegramPlaceholderEmail(user.Email) {
+				target = user.Email
+			}
+		}
+		email, err := normalizeEmail(target)
+		if err != nil {
+			return "", ErrStockSubscriptionInvalid
+		}
+		return email, nil
+	case constants.StockSubscriptionChannelTelegram:
+		if target == "" && userID != 0 && s.identityRepo != nil {
+			identity, err := s.identityRepo.GetByUserProvider(userID, constants.UserOAuthProviderTelegram)
+			if err != nil {
+				return "", err
+			}
+			if identity != nil {
+				target = strings.TrimSpace(identity.ProviderUserID)
+			}
+		}
+		if !stockSubscriptionTelegramChatPattern.MatchString(target) {
+			return "", ErrStockSubscriptionInvalid
+		}
+		return target, nil
+	default:
+		return "", ErrStockSubscriptionInvalid
+	}
+}
+
+func (s *StockSubscriptionService) loadProduct(productID uint) (*models.Product, error) {
+	product, err := s.productRepo.GetByID(strconv.FormatUint(uint64(productID), 10))
+	if err != nil || product == nil {
+		return nil, err
+	}
+	if s.productService != nil {
+		products := []models.Product{*product}
+		if err := s.productService.ApplyAutoStockCounts(products); err != nil {
+			return nil, err
+		}
+		product = &products[0]
+	}
+	return product, nil
+}
+
+func (s *StockSubscriptionService) loadProductSKU(productID, skuID uint) (*models.Product, *models.ProductSKU, error) {
+	product, err := s.loadProduct(productID)
+	if err != nil || product == nil {
+		return nil, nil, err
+	}
+	for i := range product.SKUs {
+		if product.SKUs[i].ID == skuID {
+			return product, &product.SKUs[i], nil
+		}
+	}
+	return product, nil, nil
+}
+
+func (s *StockSubscriptionService) batchSize() int {
+	if s.cfg.BatchSize > 0 {
+		return s.cfg.BatchSize
+	}
+	return stockNotifyDefaultBatchSize
+}
+
+func (s *StockSubscriptionService) batchInterval() time.Duration {
+	if s.cfg.BatchIntervalSeconds > 0 {
+		return time.Duration(s.cfg.BatchIntervalSeconds) * time.Second
+	}
+	return stockNotifyDefaultBatchInterval
+}
+
+func (s *StockSubscriptionService) buildProductURL(product *models.Product) string {
+	return s.siteURL + "/products/" + url.PathEscape(product.Slug)
+}
+
+// buildUnsubscribeURL 退订确认页地址，打开页面不会直接退订
+func (s *StockSubscriptionService) buildUnsubscribeURL(token, locale string) string {
+	return s.siteURL + "/api/v1/public/stock-subscriptions/unsubscribe?token=" + url.QueryEscape(token) + "&lang=" + url.QueryEscape(locale)
+}
+
+// resolveStockSubscriptionTitle 商品标题按语言回退，多规格商品附带 SKU 编码
+func resolveStockSubscriptionTitle(product *models.Product, sku *models.ProductSKU, locale string) string {
+	title := ResolveLocalizedText(product.TitleJSON, locale)
+	if title == "" {
+		title = product.Slug
+	}
+	if code := strings.TrimSpace(sku.SKUCode); code != "" && !strings.EqualFold(code, models.DefaultSKUCode) {
+		title = fmt.Sprintf("%s (%s)", title, code)
+	}
+	return title
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dujiao-next/internal/config"
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+func TestStockSubscriptionLifecycle(t *testing.T) {
+	db, productSvc := setupProductTransferTest(t)
+	if err := db.AutoMigrate(&models.StockSubscription{}); err != nil {
+		t.Fatalf("auto migrate stock subscription failed: %v", err)
+	}
+	svc := NewStockSubscriptionService(
+		repository.NewStockSubscriptionRepository(db),
+		repository.NewProductRepository(db),
+		productSvc,
+		nil,
+		nil,
+		nil,
+		nil,
+		config.TelegramAuthConfig{},
+		nil,
+		config.StockNotifyConfig{BatchSize: 1},
+		"https://shop.example.com/",
+	)
+
+	manualStock := 0
+	product, err := productSvc.Create(CreateProductInput{
+		CategoryID:       1,
+		Slug:             "limited-box",
+		TitleJSON:        map[string]interface{}{"zh-CN": "限量礼盒"},
+		PriceAmount:      decimal.NewFromInt(20),
+		FulfillmentType:  constants.FulfillmentTypeManual,
+		ManualStockTotal: &manualStock,
+		SKUs: []ProductSKUInput{
+			{SKUCode: "RED", PriceAmount: decimal.NewFromInt(20), ManualStockTotal: 0},
+			{SKUCode: "BLUE", PriceAmount: decimal.NewFromInt(20), ManualStockTotal: 2},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create product failed: %v", err)
+	}
+	skuIDs := make(map[string]uint, len(product.SKUs))
+	for _, sku := range product.SKUs {
+		skuIDs[sku.SKUCode] = sku.ID
+	}
+
+	if _, err := svc.Subscribe(StockSubscribeInput{ProductID: product.ID, SKUID: skuIDs["BLUE"], Channel: "email", Target: "a@example.com"}); !errors.Is(err, ErrStockSubscriptionNotSoldOut) {
+		t.Fatalf("expected in-stock sku to be rejected, got %v", err)
+	}
+	if _, err := svc.Subscribe(StockSubscribeInput{ProductID: product.ID, SKUID: skuIDs["RED"], Channel: "email", Target: "not-an-email"}); !errors.Is(err, ErrStockSubscriptionInvalid) {
+		t.Fatalf("expected invalid email to be rejected, got %v", err)
+	}
+
+	// 与下单一致：定时上架前不可订阅，访问码保护商品需提供正确访问码
+	if err := db.Model(&models.Product{}).Where("id = ?", product.ID).Update("publish_at", time.Now().Add(time.Hour)).Error; err != nil {
+		t.Fatalf("schedule product failed: %v", err)
+	}
+	if _, err := svc.Subscribe(StockSubscribeInput{ProductID: product.ID, SKUID: skuIDs["RED"], Channel: "email", Target: "a@example.com"}); !errors.Is(err, ErrProductNotAvailable) {
+		t.Fatalf("expected unpublished product to be rejected, got %v", err)
+	}
+	if err := db.Model(&models.Product{}).Where("id = ?", product.ID).Updates(map[string]interface{}{
+		"publish_at":  nil,
+		"visibility":  constants.ProductVisibilityProtected,
+		"access_code": "secret",
+	}).Error; err != nil {
+		t.Fatalf("protect product failed: %v", err)
+	}
+	if _, err := svc.Subscribe(StockSubscribeInput{ProductID: product.ID, SKUID: skuIDs["RED"], Channel: "email", Target: "a@example.com"}); !errors.Is(err, ErrProductAccessCodeRequired) {
+		t.Fatalf("expected missing access code to be rejected, got %v", err)
+	}
+	if _, err := svc.Subscribe(StockSubscribeInput{ProductID: product.ID, SKUID: skuIDs["RED"], Channel: "email", Target: "a@example.com", AccessCode: "wrong"}); !errors.Is(err, ErrProductAccessCodeInvalid) {
+		t.Fatalf("expected wrong access code to be rejected, got %v", err)
+	}
+	protected, err := svc.Subscribe(StockSubscribeInput{ProductID: product.ID, SKUID: skuIDs["RED"], Channel: "email", Target: "c@example.com", AccessCode: "secret"})
+	if err != nil {
+		t.Fatalf("subscribe with access code failed: %v", err)
+	}
+	if err := svc.Unsubscribe(protected.Token); err != nil {
+		t.Fatalf("unsubscribe protected failed: %v", err)
+	}
+	if err := db.Model(&models.Product{}).Where("id = ?", product.ID).Updates(map[string]interface{}{
+		"visibility":  constants.ProductVisibilityPublic,
+		"access_code": "",
+	}).Error; err != nil {
+		t.Fatalf("restore product visibility failed: %v", err)
+	}
+
+	first, err := svc.Subscribe(StockSubscribeInput{ProductID: product.ID, SKUID: skuIDs["RED"], Channel: "email", Target: " A@Example.com "})
+	if err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+	again, err := svc.Subscribe(StockSubscribeInput{ProductID: product.ID, SKUID: skuIDs["RED"], Channel: "email", Target: "a@example.com"})
+	if err != nil {
+		t.Fatalf("repeat subscribe failed: %v", err)
+	}
+	if again.ID != first.ID {
+		t.Fatalf("expected repeat subscription to reuse %d, got %d", first.ID, again.ID)
+	}
+	second, err := svc.Subscribe(StockSubscribeInput{ProductID: product.ID, SKUID: skuIDs["RED"], Channel: "telegram", Target: "123456"})
+	if err != nil {
+		t.Fatalf("subscribe telegram failed: %v", err)
+	}
+	unsubscribed, err := svc.Subscribe(StockSubscribeInput{ProductID: product.ID, SKUID: skuIDs["RED"], Channel: "email", Target: "b@example.com"})
+	if err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+	if err := svc.Unsubscribe(unsubscribed.Token); err != nil {
+		t.Fatalf("unsubscribe failed: %v", err)
+	}
+	if err := svc.Unsubscribe("missing"); !errors.Is(err, ErrStockSubscriptionNotFound) {
+		t.Fatalf("expected unknown token to be rejected, got %v", err)
+	}
+
+	// 仍然售罄时不发送，订阅保持待通知
+	if err := svc.NotifyRestock(context.Background(), skuIDs["RED"]); err != nil {
+		t.Fatalf("notify sold-out sku failed: %v", err)
+	}
+	assertStockSubscriptionStatus(t, db, first.ID, constants.StockSubscriptionStatusPending)
+
+	if err := db.Model(&models.ProductSKU{}).Where("id = ?", skuIDs["RED"]).Update("manual_stock_total", 3).Error; err != nil {
+		t.Fatalf("restock sku failed: %v", err)
+	}
+	// 未配置站点地址时拒绝发送，订阅保持待通知
+	noSiteSvc := *svc
+	noSiteSvc.siteURL = ""
+	if err := noSiteSvc.NotifyRestock(context.Background(), skuIDs["RED"]); err != nil {
+		t.Fatalf("notify without site url failed: %v", err)
+	}
+	assertStockSubscriptionStatus(t, db, first.ID, constants.StockSubscriptionStatusPending)
+
+	// 批次大小为 1：首批仅通知最早的订阅
+	if err := svc.NotifyRestock(context.Background(), skuIDs["RED"]); err != nil {
+		t.Fatalf("notify restock failed: %v", err)
+	}
+	assertStockSubscriptionStatus(t, db, first.ID, constants.StockSubscriptionStatusNotified)
+	assertStockSubscriptionStatus(t, db, second.ID, constants.StockSubscriptionStatusPending)
+	assertStockSubscriptionStatus(t, db, unsubscribed.ID, constants.StockSubscriptionStatusUnsubscribed)
+
+	if err := svc.NotifyRestock(context.Background(), skuIDs["RED"]); err != nil {
+		t.Fatalf("notify next batch failed: %v", err)
+	}
+	assertStockSubscriptionStatus(t, db, second.ID, constants.StockSubscriptionStatusNotified)
+
+	if got := svc.buildUnsubscribeURL(first.Token, "en-US"); got != "https://shop.example.com/api/v1/public/stock-subscriptions/unsubscribe?token="+first.Token+"&lang=en-US" {
+		t.Fatalf("unexpected unsubscribe url: %s", got)
+	}
+	if got := svc.buildProductURL(product); got != "https://shop.example.com/products/limited-box" {
+		t.Fatalf("unexpected product url: %s", got)
+	}
+}
+
+func assertStockSubscriptionStatus(t *testing.T, db *gorm.DB, id uint, expected string) {
+	t.Helper()
+	var subscription models.StockSubscription
+	if err := db.First(&subscription, id).Error; err != nil {
+		t.Fatalf("load subscription %d failed: %v", id, err)
+	}
+	if subscription.Status != expected {
+		t.Fatalf("expected subscription %d status %s, got %s", id, expected, subscription.Status)
+	}
+}
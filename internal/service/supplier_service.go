@@ -0,0 +1,497 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/logger"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/queue"
+	"github.com/dujiao-next/internal/repository"
+)
+
+const (
+	supplierDefaultTimeoutSeconds = 10
+	supplierMaxTimeoutSeconds     = 60
+	supplierDefaultMaxAttempts    = 3
+	supplierMaxAttemptsLimit      = 10
+	supplierRetryBackoffBase      = 2 * time.Second
+	supplierRetryBackoffMax       = 30 * time.Second
+	supplierResponseBodyLimit     = 1 << 20
+
+	// SupplierHeaderTimestamp 签名时间戳请求头
+	SupplierHeaderTimestamp = "X-Dujiao-Timestamp"
+	// SupplierHeaderSignature 签名请求头
+	SupplierHeaderSignature = "X-Dujiao-Signature"
+	// SupplierHeaderIdempotencyKey 幂等键请求头（订单号）
+	SupplierHeaderIdempotencyKey = "Idempotency-Key"
+)
+
+// SupplierService 上游供应商管理与接口交付
+type SupplierService struct {
+	supplierRepo       repository.FulfillmentSupplierRepository
+	orderRepo          repository.OrderRepository
+	productRepo        repository.ProductRepository
+	fulfillmentService *FulfillmentService
+	notificationSvc    *NotificationService
+	queueClient        *queue.Client
+	httpClient         *http.Client
+	retryBackoff       time.Duration
+}
+
+// NewSupplierService 创建供应商服务
+func NewSupplierService(
+	supplierRepo repository.FulfillmentSupplierRepository,
+	orderRepo repository.OrderRepository,
+	productRepo repository.ProductRepository,
+	fulfillmentService *FulfillmentService,
+	notificationSvc *NotificationService,
+	queueClient *queue.Client,
+) *SupplierService {
+	return &SupplierService{
+		supplierRepo:       supplierRepo,
+		orderRepo:          orderRepo,
+		productRepo:        productRepo,
+		fulfillmentService: fulfillmentService,
+		notificationSvc:    notificationSvc,
+		queueClient:        queueClient,
+		httpClient:         &http.Client{},
+		retryBackoff:       supplierRetryBackoffBase,
+	}
+}
+
+// SupplierInput 创建/更新供应商输入
+type SupplierInput struct {
+	Name           string
+	Endpoint       string
+	SigningSecret  string
+	TimeoutSeconds int
+	MaxAttempts    int
+	IsActive       *bool
+	Remark         string
+}
+
+// ListAdmin 获取后台供应商列表
+func (s *SupplierService) ListAdmin(search string, isActive *bool, page, pageSize int) ([]models.FulfillmentSupplier, int64, error) {
+	return s.supplierRepo.List(repository.FulfillmentSupplierListFilter{
+		Page:     page,
+		PageSize: pageSize,
+		Search:   strings.TrimSpace(search),
+		IsActive: isActive,
+	})
+}
+
+// GetByID 获取供应商详情
+func (s *SupplierService) GetByID(id uint) (*models.FulfillmentSupplier, error) {
+	supplier, err := s.supplierRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if supplier == nil {
+		return nil, ErrNotFound
+	}
+	return supplier, nil
+}
+
+// Create 创建供应商
+func (s *SupplierService) Create(input SupplierInput) (*models.FulfillmentSupplier, error) {
+	supplier := &models.FulfillmentSupplier{IsActive: true}
+	if err := applySupplierInput(supplier, input); err != nil {
+		return nil, err
+	}
+	if supplier.SigningSecret == "" {
+		return nil, ErrSupplierInvalid
+	}
+	if err := s.supplierRepo.Create(supplier); err != nil {
+		return nil, err
+	}
+	supplier.HasSigningSecret = true
+	return supplier, nil
+}
+
+// Update 更新供应商，签名密钥留空表示保持不变
+func (s *SupplierService) Update(id uint, input SupplierInput) (*models.FulfillmentSupplier, error) {
+	supplier, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applySupplierInput(supplier, input); err != nil {
+		return nil, err
+	}
+	if err := s.supplierRepo.Update(supplier); err != nil {
+		return nil, err
+	}
+	supplier.HasSigningSecret = supplier.SigningSecret != ""
+	return supplier, nil
+}
+
+// Delete 删除供应商，仍有商品绑定时拒绝
+func (s *SupplierService) Delete(id uint) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+	count, err := s.supplierRepo.CountProducts(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrSupplierInUse
+	}
+	return s.supplierRepo.Delete(id)
+}
+
+func applySupplierInput(supplier *models.FulfillmentSupplier, input SupplierInput) error {
+	name := strings.TrimSpace(input.Name)
+	endpoint := strings.TrimSpace(input.Endpoint)
+	if name == "" || !isValidSupplierEndpoint(endpoint) {
+		return ErrSupplierInvalid
+	}
+	timeout := input.TimeoutSeconds
+	if timeout == 0 {
+		timeout = supplierDefaultTimeoutSeconds
+	}
+	attempts := input.MaxAttempts
+	if attempts == 0 {
+		attempts = supplierDefaultMaxAttempts
+	}
+	if timeout < 1 || timeout > supplierMaxTimeoutSeconds || attempts < 1 || attempts > supplierMaxAttemptsLimit {
+		return ErrSupplierInvalid
+	}
+	supplier.Name = name
+	supplier.Endpoint = endpoint
+	supplier.TimeoutSeconds = timeout
+	supplier.MaxAttempts = attempts
+	supplier.Remark = strings.TrimSpace(input.Remark)
+	if secret := strings.TrimSpace(input.SigningSecret); secret != "" {
+		supplier.SigningSecret = secret
+	}
+	if input.IsActive != nil {
+		supplier.IsActive = *input.IsActive
+	}
+	return nil
+}
+
+func isValidSupplierEndpoint(raw string) bool {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	return parsed.Scheme == "http" || parsed.Scheme == "https"
+}
+
+// SignSupplierPayload 计算供应商请求签名：hex(HMAC-SHA256(secret, timestamp + "." + body))
+func SignSupplierPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type supplierOrderItem struct {
+	ProductID           uint   `json:"product_id"`
+	SKUID               uint   `json:"sku_id"`
+	SKUCode             string `json:"sku_code,omitempty"`
+	SupplierProductCode string `json:"supplier_product_code"`
+	Quantity            int    `json:"quantity"`
+}
+
+type supplierOrderRequest struct {
+	OrderNo    string              `json:"order_no"`
+	Currency   string              `json:"currency"`
+	BuyerEmail string              `json:"buyer_email,omitempty"`
+	Items      []supplierOrderItem `json:"items"`
+}
+
+type supplierOrderResponse struct {
+	Payload      string      `json:"payload"`
+	DeliveryData models.JSON `json:"delivery_data"`
+}
+
+// supplierAttemptError 单次请求失败，retryable 表示可重试
+type supplierAttemptError struct {
+	reason    string
+	retryable bool
+}
+
+func (e *supplierAttemptError) Error() string {
+	return e.reason
+}
+
+// Dispatch 调用上游接口交付订单，重试耗尽后转人工处理并通知管理员
+func (s *SupplierService) Dispatch(ctx context.Context, orderID uint) error {
+	if orderID == 0 {
+		return ErrFulfillmentInvalid
+	}
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return ErrOrderFetchFailed
+	}
+	if order == nil {
+		return ErrOrderNotFound
+	}
+	if !isAPIFulfillOrder(order) {
+		return ErrFulfillmentNotAPI
+	}
+	if order.Fulfillment != nil {
+		return ErrFulfillmentExists
+	}
+	if order.Status != constants.OrderStatusPaid && order.Status != constants.OrderStatusFulfilling {
+		return ErrOrderStatusInvalid
+	}
+
+	supplier, body, err := s.buildSupplierRequest(order)
+	if errors.Is(err, ErrSupplierUnavailable) {
+		s.fallbackToManual(order, supplier, "supplier not configured or disabled")
+		return ErrSupplierRequestFailed
+	}
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= supplier.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			if waitErr := s.waitBackoff(ctx, attempt-1); waitErr != nil {
+				// 任务被中断，交由队列重试
+				return waitErr
+			}
+		}
+		result, callErr := s.callSupplier(ctx, supplier, order.OrderNo, body)
+		if callErr == nil {
+			_, createErr := s.fulfillmentService.CreateAPI(CreateAPIInput{
+				OrderID:      order.ID,
+				Payload:      result.Payload,
+				DeliveryData: result.DeliveryData,
+			})
+			if createErr == nil || errors.Is(createErr, ErrFulfillmentExists) {
+				return nil
+			}
+			// 上游已受理但本地写入失败，重复调用可能重复扣费，直接转人工
+			lastErr = fmt.Errorf("save fulfillment failed: %w", createErr)
+			break
+		}
+		lastErr = callErr
+		logger.Warnw("supplier_fulfill_attempt_failed",
+			"order_id", order.ID,
+			"order_no", order.OrderNo,
+			"supplier_id", supplier.ID,
+			"attempt", attempt,
+			"error", callErr,
+		)
+		var attemptErr *supplierAttemptError
+		if errors.As(callErr, &attemptErr) && !attemptErr.retryable {
+			break
+		}
+	}
+	reason := "supplier request failed"
+	if lastErr != nil {
+		reason = lastErr.Error()
+	}
+	s.fallbackToManual(order, supplier, reason)
+	return ErrSupplierRequestFailed
+}
+
+// RetryDispatch 管理员重新投递接口交付任务
+func (s *SupplierService) RetryDispatch(orderID uint) error {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return ErrOrderFetchFailed
+	}
+	if order == nil {
+		return ErrOrderNotFound
+	}
+	if !isAPIFulfillOrder(order) {
+		return ErrFulfillmentNotAPI
+	}
+	if order.Fulfillment != nil {
+		return ErrFulfillmentExists
+	}
+	if order.Status != constants.OrderStatusPaid && order.Status != constants.OrderStatusFulfilling {
+		return ErrOrderStatusInvalid
+	}
+	if s.queueClient == nil || !s.queueClient.Enabled() {
+		return ErrQueueUnavailable
+	}
+	return s.queueClient.EnqueueOrderAPIFulfill(queue.OrderAPIFulfillPayload{OrderID: order.ID})
+}
+
+func (s *SupplierService) buildSupplierRequest(order *models.Order) (*models.FulfillmentSupplier, []byte, error) {
+	var supplier *models.FulfillmentSupplier
+	items := make([]supplierOrderItem, 0, len(order.Items))
+	for _, item := range order.Items {
+		product, err := s.productRepo.GetByID(strconv.FormatUint(uint64(item.ProductID), 10))
+		if err != nil {
+			return supplier, nil, err
+		}
+		if product == nil || product.SupplierID == nil {
+			return supplier, nil, ErrSupplierUnavailable
+		}
+		if supplier == nil {
+			supplier, err = s.supplierRepo.GetByID(*product.SupplierID)
+			if err != nil {
+				return nil, nil, err
+			}
+			if supplier == nil || !supplier.IsActive || supplier.SigningSecret == "" {
+				return supplier, nil, ErrSupplierUnavailable
+			}
+		} else if supplier.ID != *product.SupplierID {
+			return supplier, nil, ErrSupplierUnavailable
+		}
+		items = append(items, supplierOrderItem{
+			ProductID:           item.ProductID,
+			SKUID:               item.SKUID,
+			SKUCode:             strings.TrimSpace(toStringValue(item.SKUSnapshotJSON["sku_code"])),
+			SupplierProductCode: product.SupplierProductCode,
+			Quantity:            item.Quantity,
+		})
+	}
+	buyerEmail := strings.TrimSpace(order.GuestEmail)
+	if buyerEmail == "" {
+		if email, err := s.orderRepo.ResolveReceiverEmailByOrderID(order.ID); err == nil {
+			buyerEmail = email
+		}
+	}
+	body, err := json.Marshal(supplierOrderRequest{
+		OrderNo:    order.OrderNo,
+		Currency:   order.Currency,
+		BuyerEmail: buyerEmail,
+		Items:      items,
+	})
+	if err != nil {
+		return supplier, nil, err
+	}
+	return supplier, body, nil
+}
+
+func (s *SupplierService) callSupplier(ctx context.Context, supplier *models.FulfillmentSupplier, orderNo string, body []byte) (*supplierOrderResponse, error) {
+	timeout := time.Duration(supplier.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = supplierDefaultTimeoutSeconds * time.Second
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, supplier.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, &supplierAttemptError{reason: err.Error()}
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SupplierHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SupplierHeaderSignature, SignSupplierPayload(supplier.SigningSecret, timestamp, body))
+	req.Header.Set(SupplierHeaderIdempotencyKey, orderNo)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, &supplierAttemptError{reason: err.Error(), retryable: true}
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, supplierResponseBodyLimit))
+	if err != nil {
+		return nil, &supplierAttemptError{reason: err.Error(), retryable: true}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		retryable := resp.StatusCode >= 500 ||
+			resp.StatusCode == http.StatusRequestTimeout ||
+			resp.StatusCode == http.StatusTooManyRequests
+		return nil, &supplierAttemptError{
+			reason:    fmt.Sprintf("supplier responded %d: %s", resp.StatusCode, truncateSupplierBody(raw)),
+			retryable: retryable,
+		}
+	}
+	var result supplierOrderResponse
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, &supplierAttemptError{reason: "invalid supplier response: " + err.Error(), retryable: true}
+	}
+	if strings.TrimSpace(result.Payload) == "" && len(result.DeliveryData) == 0 {
+		return nil, &supplierAttemptError{reason: "supplier response has no delivery content", retryable: true}
+	}
+	return &result, nil
+}
+
+func (s *SupplierService) waitBackoff(ctx context.Context, retry int) error {
+	delay := s.retryBackoff << (retry - 1)
+	if delay > supplierRetryBackoffMax || delay < 0 {
+		delay = supplierRetryBackoffMax
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// fallbackToManual 标记订单待人工交付并推送管理员告警
+func (s *SupplierService) fallbackToManual(order *models.Order, supplier *models.FulfillmentSupplier, reason string) {
+	now := time.Now()
+	if order.Status == constants.OrderStatusPaid {
+		if err := s.orderRepo.UpdateStatus(order.ID, constants.OrderStatusFulfilling, map[string]interface{}{
+			"updated_at": now,
+		}); err != nil {
+			logger.Warnw("supplier_fallback_update_status_failed", "order_id", order.ID, "error", err)
+		} else {
+			order.Status = constants.OrderStatusFulfilling
+			if order.ParentID != nil {
+				if _, err := syncParentStatus(s.orderRepo, *order.ParentID, now); err != nil {
+					logger.Warnw("supplier_fallback_sync_parent_status_failed",
+						"order_id", order.ID,
+						"parent_order_id", *order.ParentID,
+						"error", err,
+					)
+				}
+			}
+		}
+	}
+	logger.Warnw("supplier_fulfill_fallback_manual",
+		"order_id", order.ID,
+		"order_no", order.OrderNo,
+		"reason", reason,
+	)
+	if s.notificationSvc == nil {
+		return
+	}
+	supplierName := ""
+	if supplier != nil {
+		supplierName = supplier.Name
+	}
+	if err := s.notificationSvc.Enqueue(NotificationEnqueueInput{
+		EventType: constants.NotificationEventSupplierFulfillFailed,
+		BizType:   constants.NotificationBizTypeOrder,
+		BizID:     order.ID,
+		Locale:    strings.TrimSpace(order.GuestLocale),
+		Data: models.JSON{
+			"order_id":      fmt.Sprintf("%d", order.ID),
+			"order_no":      strings.TrimSpace(order.OrderNo),
+			"supplier_name": supplierName,
+			"reason":        reason,
+		},
+	}); err != nil {
+		logger.Warnw("supplier_fallback_notify_failed", "order_id", order.ID, "error", err)
+	}
+}
+
+func truncateSupplierBody(raw []byte) string {
+	text := strings.TrimSpace(string(raw))
+	runes := []rune(text)
+	if len(runes) > 200 {
+		return string(runes[:200]) + "..."
+	}
+	return text
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/models"
+	"github.com/dujiao-next/internal/repository"
+
+	"github.com/glebarez/sqlite"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+func setupSupplierServiceTest(t *testing.T, endpoint string) (*gorm.DB, *SupplierService, *models.Order) {
+	t.Helper()
+	dsn := fmt.Sprintf("file:supplier_service_test_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.Product{},
+		&models.ProductSKU{},
+		&models.Order{},
+		&models.OrderItem{},
+		&models.Fulfillment{},
+		&models.FulfillmentSupplier{},
+	); err != nil {
+		t.Fatalf("auto migrate failed: %v", err)
+	}
+	models.DB = db
+
+	supplier := &models.FulfillmentSupplier{
+		Name:           "upstream",
+		Endpoint:       endpoint,
+		SigningSecret:  "supplier-secret",
+		TimeoutSeconds: 5,
+		MaxAttempts:    3,
+		IsActive:       true,
+	}
+	if err := db.Create(supplier).Error; err != nil {
+		t.Fatalf("create supplier failed: %v", err)
+	}
+	amount := models.NewMoneyFromDecimal(decimal.NewFromInt(10))
+	product := &models.Product{
+		CategoryID:          1,
+		Slug:                "api-product",
+		TitleJSON:           models.JSON{"zh-CN": "接口商品"},
+		PriceAmount:         amount,
+		FulfillmentType:     constants.FulfillmentTypeAPI,
+		SupplierID:          &supplier.ID,
+		SupplierProductCode: "UP-100",
+		IsActive:            true,
+	}
+	if err := db.Create(product).Error; err != nil {
+		t.Fatalf("create product failed: %v", err)
+	}
+	parent := &models.Order{
+		OrderNo:        "DJ-API-001",
+		GuestEmail:     "buyer@example.com",
+		Status:         constants.OrderStatusPaid,
+		Currency:       "CNY",
+		OriginalAmount: amount,
+		TotalAmount:    amount,
+	}
+	if err := db.Create(parent).Error; err != nil {
+		t.Fatalf("create parent order failed: %v", err)
+	}
+	child := &models.Order{
+		OrderNo:        "DJ-API-001-01",
+		ParentID:       &parent.ID,
+		GuestEmail:     "buyer@example.com",
+		Status:         constants.OrderStatusPaid,
+		Currency:       "CNY",
+		OriginalAmount: amount,
+		TotalAmount:    amount,
+	}
+	if err := db.Create(child).Error; err != nil {
+		t.Fatalf("create child order failed: %v", err)
+	}
+	item := &models.OrderItem{
+		OrderID:         child.ID,
+		ProductID:       product.ID,
+		SKUID:           1,
+		TitleJSON:       product.TitleJSON,
+		SKUSnapshotJSON: models.JSON{"sku_code": models.DefaultSKUCode},
+		UnitPrice:       amount,
+		Quantity:        2,
+		TotalPrice:      amount,
+		FulfillmentType: constants.FulfillmentTypeAPI,
+	}
+	if err := db.Create(item).Error; err != nil {
+		t.Fatalf("create order item failed: %v", err)
+	}
+
+	orderRepo := repository.NewOrderRepository(db)
+	svc := NewSupplierService(
+		repository.NewFulfillmentSupplierRepository(db),
+		orderRepo,
+		repository.NewProductRepository(db),
+		NewFulfillmentService(orderRepo, repository.NewFulfillmentRepository(db), nil, nil),
+		nil,
+		nil,
+	)
+	svc.retryBackoff = time.Millisecond
+	return db, svc, child
+}
+
+func TestSupplierServiceDispatchDelivers(t *testing.T) {
+	var received supplierOrderRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(SupplierHeaderTimestamp), 10, 64)
+		if r.Header.Get(SupplierHeaderSignature) != SignSupplierPayload("supplier-secret", timestamp, body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.Unmarshal(body, &received)
+		_, _ = w.Write([]byte(`{"payload":"KEY-1\nKEY-2","delivery_data":{"note":"enjoy"}}`))
+	}))
+	defer server.Close()
+
+	db, svc, child := setupSupplierServiceTest(t, server.URL)
+	if err := svc.Dispatch(context.Background(), child.ID); err != nil {
+		t.Fatalf("dispatch failed: %v", err)
+	}
+	if received.OrderNo != child.OrderNo || len(received.Items) != 1 {
+		t.Fatalf("unexpected supplier request: %+v", received)
+	}
+	if received.Items[0].SupplierProductCode != "UP-100" || received.Items[0].Quantity != 2 {
+		t.Fatalf("unexpected supplier item: %+v", received.Items[0])
+	}
+	if received.BuyerEmail != "buyer@example.com" {
+		t.Fatalf("expected buyer email, got %s", received.BuyerEmail)
+	}
+
+	fulfillment, err := repository.NewFulfillmentRepository(db).GetByOrderID(child.ID)
+	if err != nil || fulfillment == nil {
+		t.Fatalf("get fulfillment failed: %v", err)
+	}
+	if fulfillment.Type != constants.FulfillmentTypeAPI || fulfillment.Payload != "KEY-1\nKEY-2" {
+		t.Fatalf("unexpected fulfillment: %+v", fulfillment)
+	}
+	if fulfillment.LogisticsJSON["note"] != "enjoy" {
+		t.Fatalf("expected delivery data note, got %+v", fulfillment.LogisticsJSON)
+	}
+	var order models.Order
+	db.First(&order, child.ID)
+	if order.Status != constants.OrderStatusCompleted {
+		t.Fatalf("expected child completed, got %s", order.Status)
+	}
+}
+
+func TestSupplierServiceDispatchFallsBackToManual(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	db, svc, child := setupSupplierServiceTest(t, server.URL)
+	if err := svc.Dispatch(context.Background(), child.ID); !errors.Is(err, ErrSupplierRequestFailed) {
+		t.Fatalf("expected ErrSupplierRequestFailed, got %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+	var order models.Order
+	db.First(&order, child.ID)
+	if order.Status != constants.OrderStatusFulfilling {
+		t.Fatalf("expected child fulfilling after fallback, got %s", order.Status)
+	}
+	var parent models.Order
+	db.First(&parent, *child.ParentID)
+	if parent.Status != constants.OrderStatusFulfilling {
+		t.Fatalf("expected parent fulfilling after fallback, got %s", parent.Status)
+	}
+	var count int64
+	db.Model(&models.Fulfillment{}).Where("order_id = ?", child.ID).Count(&count)
+	if count != 0 {
+		t.Fatalf("expected no fulfillment after fallback, got %d", count)
+	}
+}
+
+func TestSupplierServiceDoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"message":"unknown product"}`))
+	}))
+	defer server.Close()
+
+	_, svc, child := setupSupplierServiceTest(t, server.URL)
+	if err := svc.Dispatch(context.Background(), child.ID); !errors.Is(err, ErrSupplierRequestFailed) {
+		t.Fatalf("expected ErrSupplierRequestFailed, got %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("expected a single attempt for 4xx, got %d", calls)
+	}
+}
@@ -42,34 +42,78 @@ func NewUploadService(cfg *config.Config) *UploadService {
 
 // SaveFile 保存上传的文件
 func (s *UploadService) SaveFile(file *multipart.FileHeader, scene string) (string, error) {
+	src, ext, err := s.openValidatedUpload(file)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	normalizedScene := normalizeUploadScene(scene)
+
+	// 生成唯一文件名
+	filename := fmt.Sprintf("%s%s", uuid.New().String(), ext)
+	now := time.Now()
+	year := now.Format("2006")
+	month := now.Format("01")
+	savePath := filepath.Join("uploads", normalizedScene, year, month, filename)
+
+	// 确保上传目录存在
+	if err := os.MkdirAll(filepath.Dir(savePath), 0755); err != nil {
+		return "", err
+	}
+
+	// 保存文件
+	dst, err := os.Create(savePath)
+	if err != nil {
+		return "", err
+	}
+	defer dst.Close()
+
+	_, err = io.Copy(dst, src)
+	if err != nil {
+		return "", err
+	}
+
+	// 返回相对路径，由前端根据环境配置拼接完整 URL
+	return fmt.Sprintf("/uploads/%s/%s/%s/%s", normalizedScene, year, month, filename), nil
+}
+
+// openValidatedUpload 按 upload 配置校验大小、扩展名、类型与图片尺寸，返回已重置读取位置的文件
+func (s *UploadService) openValidatedUpload(file *multipart.FileHeader) (multipart.File, string, error) {
 	// 验证文件大小
 	if file.Size > s.cfg.Upload.MaxSize {
-		return "", fmt.Errorf("文件大小超过限制（最大 %d MB）", s.cfg.Upload.MaxSize/1024/1024)
+		return nil, "", fmt.Errorf("文件大小超过限制（最大 %d MB）", s.cfg.Upload.MaxSize/1024/1024)
 	}
 
 	// 获取文件扩展名
 	ext := strings.ToLower(filepath.Ext(file.Filename))
 	if len(s.cfg.Upload.AllowedExtensions) > 0 {
 		if ext == "" || !isAllowedExtension(ext, s.cfg.Upload.AllowedExtensions) {
-			return "", fmt.Errorf("文件扩展名不被允许: %s", ext)
+			return nil, "", fmt.Errorf("文件扩展名不被允许: %s", ext)
 		}
 	}
 
-	// 验证文件类型
 	src, err := file.Open()
 	if err != nil {
-		return "", err
+		return nil, "", err
 	}
-	defer src.Close()
+	if err := s.validateUploadContent(src); err != nil {
+		_ = src.Close()
+		return nil, "", err
+	}
+	return src, ext, nil
+}
 
+// validateUploadContent 验证文件类型与图片尺寸，完成后重置读取位置
+func (s *UploadService) validateUploadContent(src multipart.File) error {
 	// 读取文件头部识别 MIME 类型
 	buffer := make([]byte, 512)
-	_, err = src.Read(buffer)
+	_, err := src.Read(buffer)
 	if err != nil && err != io.EOF {
-		return "", err
+		return err
 	}
 	if _, err := src.Seek(0, 0); err != nil { // 重置文件读取位置
-		return "", err
+		return err
 	}
 
 	contentType := http.DetectContentType(buffer)
@@ -82,58 +126,178 @@ func (s *UploadService) SaveFile(file *multipart.FileHeader, scene string) (stri
 			}
 		}
 		if !allowed {
-			return "", fmt.Errorf("文件类型不被允许: %s", contentType)
+			return fmt.Errorf("文件类型不被允许: %s", contentType)
 		}
 	}
 
 	if strings.HasPrefix(contentType, "image/") {
 		if _, err := src.Seek(0, 0); err != nil {
-			return "", err
+			return err
 		}
 		width, height, err := decodeImageDimensions(src, contentType)
 		if err != nil {
-			return "", err
+			return err
 		}
 		if s.cfg.Upload.MaxWidth > 0 && width > s.cfg.Upload.MaxWidth {
-			return "", fmt.Errorf("图片宽度超过限制（最大 %d）", s.cfg.Upload.MaxWidth)
+			return fmt.Errorf("图片宽度超过限制（最大 %d）", s.cfg.Upload.MaxWidth)
 		}
 		if s.cfg.Upload.MaxHeight > 0 && height > s.cfg.Upload.MaxHeight {
-			return "", fmt.Errorf("图片高度超过限制（最大 %d）", s.cfg.Upload.MaxHeight)
+			return fmt.Errorf("图片高度超过限制（最大 %d）", s.cfg.Upload.MaxHeight)
 		}
 	}
 
 	if _, err := src.Seek(0, 0); err != nil {
-		return "", err
+		return err
 	}
+	return nil
+}
 
-	normalizedScene := normalizeUploadScene(scene)
+// PrivateFile 私有存储文件信息
+type PrivateFile struct {
+	StorageKey  string
+	Name        string
+	Size        int64
+	ContentType string
+}
 
-	// 生成唯一文件名
-	filename := fmt.Sprintf("%s%s", uuid.New().String(), ext)
-	now := time.Now()
-	year := now.Format("2006")
-	month := now.Format("01")
-	savePath := filepath.Join("uploads", normalizedScene, year, month, filename)
+// SavePrivateFile 保存交付文件到私有目录（不经静态路由暴露）
+func (s *UploadService) SavePrivateFile(file *multipart.FileHeader) (*PrivateFile, error) {
+	if s.cfg.Download.MaxSize > 0 && file.Size > s.cfg.Download.MaxSize {
+		return nil, fmt.Errorf("文件大小超过限制（最大 %d MB）", s.cfg.Download.MaxSize/1024/1024)
+	}
+	name := filepath.Base(strings.TrimSpace(file.Filename))
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		return nil, fmt.Errorf("文件名无效")
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	if len(s.cfg.Download.AllowedExtensions) > 0 {
+		if ext == "" || !isAllowedExtension(ext, s.cfg.Download.AllowedExtensions) {
+			return nil, fmt.Errorf("文件扩展名不被允许: %s", ext)
+		}
+	}
 
-	// 确保上传目录存在
-	if err := os.MkdirAll(filepath.Dir(savePath), 0755); err != nil {
-		return "", err
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
 	}
+	defer src.Close()
 
-	// 保存文件
-	dst, err := os.Create(savePath)
+	buffer := make([]byte, 512)
+	n, err := src.Read(buffer)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if _, err := src.Seek(0, 0); err != nil {
+		return nil, err
+	}
+	contentType := http.DetectContentType(buffer[:n])
+
+	key := newPrivateStorageKey("", ext)
+	size, err := s.writePrivateFile(key, src)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	return &PrivateFile{
+		StorageKey:  key,
+		Name:        name,
+		Size:        size,
+		ContentType: contentType,
+	}, nil
+}
+
+// SavePrivateUpload 按 upload 配置校验后保存到私有目录的 prefix 子目录，只能通过鉴权接口读取
+func (s *UploadService) SavePrivateUpload(file *multipart.FileHeader, prefix string) (*PrivateFile, error) {
+	src, ext, err := s.openValidatedUpload(file)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	buffer := make([]byte, 512)
+	n, err := src.Read(buffer)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if _, err := src.Seek(0, 0); err != nil {
+		return nil, err
+	}
+
+	key := newPrivateStorageKey(prefix, ext)
+	size, err := s.writePrivateFile(key, src)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateFile{
+		StorageKey:  key,
+		Name:        filepath.Base(strings.TrimSpace(file.Filename)),
+		Size:        size,
+		ContentType: http.DetectContentType(buffer[:n]),
+	}, nil
+}
+
+// writePrivateFile 写入私有存储，key 已存在时报错，写入失败时清理残留文件
+func (s *UploadService) writePrivateFile(key string, src io.Reader) (int64, error) {
+	savePath, err := s.ResolvePrivatePath(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(savePath), 0750); err != nil {
+		return 0, err
+	}
+	dst, err := os.OpenFile(savePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0640)
+	if err != nil {
+		return 0, err
 	}
 	defer dst.Close()
+	size, err := io.Copy(dst, src)
+	if err != nil {
+		_ = os.Remove(savePath)
+		return 0, err
+	}
+	return size, nil
+}
 
-	_, err = io.Copy(dst, src)
+// ResolvePrivatePath 将私有存储 key 解析为磁盘路径，拒绝越出存储目录
+func (s *UploadService) ResolvePrivatePath(key string) (string, error) {
+	base, err := filepath.Abs(s.privateStorageDir())
 	if err != nil {
 		return "", err
 	}
+	cleaned := filepath.Clean(filepath.FromSlash(strings.TrimSpace(key)))
+	if cleaned == "." || filepath.IsAbs(cleaned) || strings.HasPrefix(cleaned, "..") {
+		return "", fmt.Errorf("存储路径无效")
+	}
+	full := filepath.Join(base, cleaned)
+	if !strings.HasPrefix(full, base+string(filepath.Separator)) {
+		return "", fmt.Errorf("存储路径无效")
+	}
+	return full, nil
+}
 
-	// 返回相对路径，由前端根据环境配置拼接完整 URL
-	return fmt.Sprintf("/uploads/%s/%s/%s/%s", normalizedScene, year, month, filename), nil
+// RemovePrivateFile 删除私有存储文件（文件不存在时忽略）
+func (s *UploadService) RemovePrivateFile(key string) error {
+	path, err := s.ResolvePrivatePath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *UploadService) privateStorageDir() string {
+	dir := strings.TrimSpace(s.cfg.Download.StorageDir)
+	if dir == "" {
+		return filepath.Join("storage", "files")
+	}
+	return dir
+}
+
+func newPrivateStorageKey(prefix, ext string) string {
+	now := time.Now()
+	return filepath.ToSlash(filepath.Join(prefix, now.Format("2006"), now.Format("01"), uuid.New().String()+ext))
 }
 
 func normalizeUploadScene(raw string) string {
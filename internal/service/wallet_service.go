@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/dujiao-next/internal/constants"
+	"github.com/dujiao-next/internal/logger"
 	"github.com/dujiao-next/internal/models"
 	"github.com/dujiao-next/internal/repository"
 
@@ -24,6 +25,8 @@ type WalletService struct {
 	orderRepo    repository.OrderRepository
 	userRepo     repository.UserRepository
 	affiliateSvc *AffiliateService
+
+	memberLevelSvc *MemberLevelService
 }
 
 // WalletRechargeInput 用户充值输入
@@ -75,6 +78,11 @@ func NewWalletService(
 	}
 }
 
+// SetMemberLevelService 设置会员等级服务，退款后重新统计累计消费与等级
+func (s *WalletService) SetMemberLevelService(memberLevelSvc *MemberLevelService) {
+	s.memberLevelSvc = memberLevelSvc
+}
+
 // GetAccount 获取钱包账户（不存在时自动创建）
 func (s *WalletService) GetAccount(userID uint) (*models.WalletAccount, error) {
 	if userID == 0 {
@@ -268,6 +276,15 @@ func (s *WalletService) AdminRefundToWallet(input AdminRefundToWalletInput) (*mo
 	if order == nil {
 		return nil, nil, ErrOrderNotFound
 	}
+	if s.memberLevelSvc != nil {
+		if _, err := s.memberLevelSvc.RefreshUserLevel(order.UserID); err != nil {
+			logger.Warnw("member_level_refresh_after_refund_failed",
+				"order_id", order.ID,
+				"user_id", order.UserID,
+				"error", err,
+			)
+		}
+	}
 	return order, txnResult, nil
 }
 
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/dujiao-next/internal/constants"
 	"github.com/dujiao-next/internal/logger"
 	"github.com/dujiao-next/internal/models"
 	"github.com/dujiao-next/internal/provider"
@@ -36,12 +37,15 @@ func (c *Consumer) Register(mux *asynq.ServeMux) {
 	}
 	mux.HandleFunc(queue.TaskOrderStatusEmail, c.handleOrderStatusEmail)
 	mux.HandleFunc(queue.TaskOrderAutoFulfill, c.handleOrderAutoFulfill)
+	mux.HandleFunc(queue.TaskOrderAPIFulfill, c.handleOrderAPIFulfill)
 	mux.HandleFunc(queue.TaskOrderTimeoutCancel, c.handleOrderTimeoutCancel)
 	mux.HandleFunc(queue.TaskWalletRechargeExpire, c.handleWalletRechargeExpire)
 	mux.HandleFunc(queue.TaskNotificationDispatch, c.handleNotificationDispatch)
+	mux.HandleFunc(queue.TaskOrderMessageNotify, c.handleOrderMessageNotify)
+	mux.HandleFunc(queue.TaskStockArrivalNotify, c.handleStockArrivalNotify)
 }
 
-func (c *Consumer) handleOrderStatusEmail(_ context.Context, task *asynq.Task) error {
+func (c *Consumer) handleOrderStatusEmail(ctx context.Context, task *asynq.Task) error {
 	if c == nil || task == nil {
 		logger.Debugw("worker_order_status_email_skip_nil", "consumer_nil", c == nil, "task_nil", task == nil)
 		return nil
@@ -80,22 +84,12 @@ func (c *Consumer) handleOrderStatusEmail(_ context.Context, task *asynq.Task) e
 		receiverEmail = strings.TrimSpace(order.GuestEmail)
 		locale = strings.TrimSpace(order.GuestLocale)
 	}
-	if receiverEmail == "" {
-		logger.Debugw("worker_order_status_email_skip_empty_receiver", "order_id", order.ID, "order_no", order.OrderNo)
-		return nil
-	}
-	if isTelegramPlaceholderReceiver(receiverEmail) {
-		logger.Debugw("worker_order_status_email_skip_placeholder_receiver", "order_id", order.ID, "order_no", order.OrderNo)
-		return nil
-	}
-	if c.EmailService == nil {
-		logger.Warnw("worker_order_status_email_skip_email_service_nil", "order_id", order.ID, "order_no", order.OrderNo)
-		return nil
-	}
 	status := strings.TrimSpace(payload.Status)
 	if status == "" {
 		status = order.Status
 	}
+	c.DeliveryRenderService.ApplyToOrder(order, locale)
+	c.SecretRevealService.ApplyToOrder(order)
 	payloadText := buildOrderFulfillmentEmailPayload(order)
 	input := service.OrderStatusEmailInput{
 		OrderNo:         order.OrderNo,
@@ -105,7 +99,22 @@ func (c *Consumer) handleOrderStatusEmail(_ context.Context, task *asynq.Task) e
 		FulfillmentInfo: payloadText,
 		IsGuest:         order.UserID == 0,
 	}
-	if err := c.EmailService.SendOrderStatusEmail(receiverEmail, input, locale); err != nil {
+	if receiverEmail == "" {
+		logger.Debugw("worker_order_status_email_skip_empty_receiver", "order_id", order.ID, "order_no", order.OrderNo)
+		c.DeliveryRenderService.NotifyTelegram(ctx, order, input, locale)
+		return nil
+	}
+	if isTelegramPlaceholderReceiver(receiverEmail) {
+		logger.Debugw("worker_order_status_email_skip_placeholder_receiver", "order_id", order.ID, "order_no", order.OrderNo)
+		c.DeliveryRenderService.NotifyTelegram(ctx, order, input, locale)
+		return nil
+	}
+	if c.EmailService == nil {
+		logger.Warnw("worker_order_status_email_skip_email_service_nil", "order_id", order.ID, "order_no", order.OrderNo)
+		return nil
+	}
+	attachments := c.buildOrderReceiptAttachments(order, status, locale)
+	if err := c.EmailService.SendOrderStatusEmail(receiverEmail, input, locale, attachments...); err != nil {
 		logger.Warnw("worker_order_status_email_send_failed",
 			"order_id", order.ID,
 			"order_no", order.OrderNo,
@@ -115,9 +124,35 @@ func (c *Consumer) handleOrderStatusEmail(_ context.Context, task *asynq.Task) e
 		)
 		return err
 	}
+	c.DeliveryRenderService.NotifyTelegram(ctx, order, input, locale)
 	return nil
 }
 
+// buildOrderReceiptAttachments 交付邮件按配置附带收据 PDF，生成失败不影响邮件发送
+func (c *Consumer) buildOrderReceiptAttachments(order *models.Order, status, locale string) []service.EmailAttachment {
+	if c.ReceiptService == nil || order == nil {
+		return nil
+	}
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case constants.OrderStatusDelivered, constants.OrderStatusCompleted:
+	default:
+		return nil
+	}
+	receipt, err := c.ReceiptService.BuildEmailAttachment(order, locale)
+	if err != nil {
+		logger.Warnw("worker_order_status_email_build_receipt_failed", "order_id", order.ID, "order_no", order.OrderNo, "error", err)
+		return nil
+	}
+	if receipt == nil {
+		return nil
+	}
+	return []service.EmailAttachment{{
+		Filename:    receipt.Filename,
+		ContentType: receipt.ContentType,
+		Content:     receipt.Content,
+	}}
+}
+
 func (c *Consumer) handleOrderAutoFulfill(_ context.Context, task *asynq.Task) error {
 	if c == nil || task == nil {
 		logger.Debugw("worker_order_auto_fulfill_skip_nil", "consumer_nil", c == nil, "task_nil", task == nil)
@@ -147,6 +182,12 @@ func (c *Consumer) handleOrderAutoFulfill(_ context.Context, task *asynq.Task) e
 		case errors.Is(err, service.ErrOrderNotFound):
 			logger.Debugw("worker_order_auto_fulfill_skip_order_not_found", "order_id", payload.OrderID)
 			return nil
+		case errors.Is(err, service.ErrProductFileMissing):
+			logger.Warnw("worker_order_auto_fulfill_file_missing", "order_id", payload.OrderID)
+			return nil
+		case errors.Is(err, service.ErrKeyGeneratorUnavailable):
+			logger.Warnw("worker_order_auto_fulfill_key_generator_unavailable", "order_id", payload.OrderID)
+			return nil
 		default:
 			logger.Warnw("worker_order_auto_fulfill_failed", "order_id", payload.OrderID, "error", err)
 			return err
@@ -155,6 +196,44 @@ func (c *Consumer) handleOrderAutoFulfill(_ context.Context, task *asynq.Task) e
 	return nil
 }
 
+func (c *Consumer) handleOrderAPIFulfill(ctx context.Context, task *asynq.Task) error {
+	if c == nil || task == nil {
+		logger.Debugw("worker_order_api_fulfill_skip_nil", "consumer_nil", c == nil, "task_nil", task == nil)
+		return nil
+	}
+	var payload queue.OrderAPIFulfillPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		logger.Warnw("worker_order_api_fulfill_unmarshal_failed", "error", err)
+		return err
+	}
+	if payload.OrderID == 0 {
+		logger.Debugw("worker_order_api_fulfill_skip_invalid_payload", "order_id", payload.OrderID)
+		return nil
+	}
+	if c.SupplierService == nil {
+		logger.Warnw("worker_order_api_fulfill_service_unavailable", "order_id", payload.OrderID)
+		return nil
+	}
+	err := c.SupplierService.Dispatch(ctx, payload.OrderID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrFulfillmentExists),
+			errors.Is(err, service.ErrFulfillmentNotAPI),
+			errors.Is(err, service.ErrOrderStatusInvalid),
+			errors.Is(err, service.ErrOrderNotFound):
+			logger.Debugw("worker_order_api_fulfill_skip", "order_id", payload.OrderID, "reason", err.Error())
+			return nil
+		case errors.Is(err, service.ErrSupplierRequestFailed):
+			// 已转人工处理并通知管理员，不再重试
+			return nil
+		default:
+			logger.Warnw("worker_order_api_fulfill_failed", "order_id", payload.OrderID, "error", err)
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *Consumer) handleOrderTimeoutCancel(_ context.Context, task *asynq.Task) error {
 	if c == nil || task == nil {
 		logger.Debugw("worker_order_timeout_cancel_skip_nil", "consumer_nil", c == nil, "task_nil", task == nil)
@@ -273,6 +352,9 @@ func buildOrderFulfillmentEmailPayload(order *models.Order) string {
 		return ""
 	}
 	if order.Fulfillment != nil {
+		if rendered := strings.TrimSpace(order.Fulfillment.Rendered); rendered != "" {
+			return rendered
+		}
 		payload := strings.TrimSpace(order.Fulfillment.Payload)
 		if payload != "" {
 			return payload
@@ -286,7 +368,10 @@ func buildOrderFulfillmentEmailPayload(order *models.Order) string {
 		if child.Fulfillment == nil {
 			continue
 		}
-		content := strings.TrimSpace(child.Fulfillment.Payload)
+		content := strings.TrimSpace(child.Fulfillment.Rendered)
+		if content == "" {
+			content = strings.TrimSpace(child.Fulfillment.Payload)
+		}
 		if content == "" {
 			continue
 		}
@@ -294,3 +379,53 @@ func buildOrderFulfillmentEmailPayload(order *models.Order) string {
 	}
 	return strings.Join(parts, "\n\n")
 }
+
+func (c *Consumer) handleOrderMessageNotify(ctx context.Context, task *asynq.Task) error {
+	if c == nil || task == nil {
+		logger.Debugw("worker_order_message_notify_skip_nil", "consumer_nil", c == nil, "task_nil", task == nil)
+		return nil
+	}
+	if c.OrderMessageService == nil {
+		logger.Warnw("worker_order_message_notify_skip_service_nil")
+		return nil
+	}
+	var payload queue.OrderMessageNotifyPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		logger.Warnw("worker_order_message_notify_unmarshal_failed", "error", err)
+		return err
+	}
+	if payload.MessageID == 0 {
+		logger.Debugw("worker_order_message_notify_skip_invalid_payload", "message_id", payload.MessageID)
+		return nil
+	}
+	if err := c.OrderMessageService.NotifyBuyer(ctx, payload.MessageID); err != nil {
+		logger.Warnw("worker_order_message_notify_failed", "message_id", payload.MessageID, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (c *Consumer) handleStockArrivalNotify(ctx context.Context, task *asynq.Task) error {
+	if c == nil || task == nil {
+		logger.Debugw("worker_stock_arrival_notify_skip_nil", "consumer_nil", c == nil, "task_nil", task == nil)
+		return nil
+	}
+	if c.StockSubscriptionService == nil {
+		logger.Warnw("worker_stock_arrival_notify_skip_service_nil")
+		return nil
+	}
+	var payload queue.StockArrivalNotifyPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		logger.Warnw("worker_stock_arrival_notify_unmarshal_failed", "error", err)
+		return err
+	}
+	if payload.SKUID == 0 {
+		logger.Debugw("worker_stock_arrival_notify_skip_invalid_payload", "sku_id", payload.SKUID)
+		return nil
+	}
+	if err := c.StockSubscriptionService.NotifyRestock(ctx, payload.SKUID); err != nil {
+		logger.Warnw("worker_stock_arrival_notify_failed", "sku_id", payload.SKUID, "error", err)
+		return err
+	}
+	return nil
+}
@@ -13,7 +13,9 @@ import (
 )
 
 const (
-	affiliateConfirmInterval = time.Minute
+	affiliateConfirmInterval      = time.Minute
+	secretRotationDefaultInterval = 5 * time.Minute
+	secretRotationDefaultBatch    = 200
 )
 
 // Service 异步队列服务
@@ -60,6 +62,9 @@ func (s *Service) Start(ctx context.Context) error {
 	if s.consumer != nil && s.consumer.AffiliateService != nil {
 		go s.runAffiliateConfirmLoop(ctx)
 	}
+	if s.consumer != nil && s.consumer.SecretRotationService != nil {
+		go s.runSecretRotationLoop(ctx)
+	}
 	return s.server.Run(s.mux)
 }
 
@@ -95,3 +100,56 @@ func (s *Service) runAffiliateConfirmLoop(ctx context.Context) {
 		}
 	}
 }
+
+func (s *Service) runSecretRotationLoop(ctx context.Context) {
+	if s == nil || s.consumer == nil || s.consumer.SecretRotationService == nil {
+		return
+	}
+	rotation := s.consumer.SecretRotationService
+	interval := secretRotationDefaultInterval
+	batchSize := secretRotationDefaultBatch
+	if s.consumer.Config != nil {
+		rotationCfg := s.consumer.Config.Security.SecretEncryption
+		if rotationCfg.RotationIntervalSeconds > 0 {
+			interval = time.Duration(rotationCfg.RotationIntervalSeconds) * time.Second
+		}
+		if rotationCfg.RotationBatchSize > 0 {
+			batchSize = rotationCfg.RotationBatchSize
+		}
+	}
+	runOnce := func() {
+		// 单轮处理满批次时继续，直到没有待轮换数据；先重加密（同时重算哈希），再回填剩余卡密哈希
+		for rotation.Enabled() {
+			rotated, err := rotation.RotateBatch(batchSize)
+			if err != nil {
+				logger.Warnw("worker_secret_rotation_failed", "error", err)
+				return
+			}
+			if rotated < batchSize || ctx.Err() != nil {
+				break
+			}
+		}
+		for ctx.Err() == nil {
+			backfilled, err := rotation.BackfillCardSecretHashes(batchSize)
+			if err != nil {
+				logger.Warnw("worker_card_secret_hash_backfill_failed", "error", err)
+				return
+			}
+			if backfilled < batchSize {
+				return
+			}
+		}
+	}
+	runOnce()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runOnce()
+		}
+	}
+}